@@ -0,0 +1,75 @@
+// Команда clean собирает сервис выдач по Clean Architecture: таблицы db → шлюзы →
+// интеракторы → контроллер → маршрутизатор. Зависимости направлены только внутрь,
+// поэтому вся связка живёт здесь, в самом внешнем кольце.
+//
+//	clean serve [-addr :8083]   поднять HTTP API с демонстрационными данными
+//	clean check                 прогнать сценарии на подделках
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"solid/app"
+	"solid/health"
+
+	"clean/internal/adapter/controller"
+	"clean/internal/adapter/gateway"
+	"clean/internal/entity"
+	"clean/internal/framework/db"
+	"clean/internal/framework/system"
+	"clean/internal/framework/web"
+	"clean/internal/usecase"
+	"clean/internal/usecase/fake"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: clean serve [-addr :8083] | clean check")
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "serve":
+		fs := flag.NewFlagSet("serve", flag.ExitOnError)
+		addr := fs.String("addr", ":8083", "HTTP listen address")
+		fs.Parse(os.Args[2:])
+		serve(*addr)
+	case "check":
+		if err := fake.Check(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "clean: unknown command %q\n", os.Args[1])
+		os.Exit(2)
+	}
+}
+
+func serve(addr string) {
+	logger := log.Default()
+	store := gateway.NewStore(db.NewTable(), db.NewTable(), db.NewTable())
+	store.AddMember(entity.Member{ID: "ann", Name: "Ann", MaxLoans: 3})
+	store.AddCopy(entity.Copy{ID: "dune-1", Title: "Dune", Lendable: true})
+	store.AddCopy(entity.Copy{ID: "solaris-1", Title: "Solaris", Lendable: true})
+
+	clock, ids := system.Clock{}, system.IDs{}
+	c := &controller.Loans{
+		Borrow: &usecase.BorrowCopy{Members: store, Copies: store, Loans: store, Clock: clock, IDs: ids},
+		Return: &usecase.ReturnCopy{Copies: store, Loans: store, Clock: clock},
+		List:   &usecase.ListMemberLoans{Members: store, Loans: store, Clock: clock},
+		Log:    logger,
+	}
+	a := app.New(app.Config{Logger: logger})
+	mux := http.NewServeMux()
+	mux.Handle("/", web.Router(c))
+	health.New(health.Config{}).Register(mux)
+	a.HTTP("http", web.Server(addr, mux))
+	logger.Printf("clean lending listening on %s", addr)
+	if err := a.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,7 @@
+module clean
+
+go 1.23
+
+require solid v0.0.0
+
+replace solid => ../solid
@@ -0,0 +1,85 @@
+// Package controller - входной адаптер: разбирает HTTP-запрос в модель запроса
+// сценария, вызывает интерактор и отдаёт ответ презентеру.
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"clean/internal/adapter/presenter"
+	"clean/internal/usecase"
+)
+
+// Входные порты - интеракторы видны контроллеру только через эти интерфейсы.
+type (
+	Borrower interface {
+		Execute(ctx context.Context, req usecase.BorrowRequest) (usecase.BorrowResponse, error)
+	}
+	Returner interface {
+		Execute(ctx context.Context, req usecase.ReturnRequest) (usecase.ReturnResponse, error)
+	}
+	Lister interface {
+		Execute(ctx context.Context, req usecase.ListLoansRequest) (usecase.ListLoansResponse, error)
+	}
+)
+
+type Loans struct {
+	Borrow Borrower
+	Return Returner
+	List   Lister
+	Log    *log.Logger
+}
+
+// BorrowCopy обрабатывает POST /members/{id}/loans с телом {"copy_id": "..."}.
+func (c *Loans) BorrowCopy(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		CopyID string `json:"copy_id"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, presenter.ErrorView{Error: "invalid JSON: " + err.Error()})
+		return
+	}
+	resp, err := c.Borrow.Execute(r.Context(), usecase.BorrowRequest{MemberID: r.PathValue("id"), CopyID: body.CopyID})
+	if err != nil {
+		c.fail(w, err)
+		return
+	}
+	w.Header().Set("Location", "/loans/"+resp.LoanID)
+	writeJSON(w, http.StatusCreated, presenter.Borrowed(resp))
+}
+
+// ReturnCopy обрабатывает POST /loans/{id}/return.
+func (c *Loans) ReturnCopy(w http.ResponseWriter, r *http.Request) {
+	resp, err := c.Return.Execute(r.Context(), usecase.ReturnRequest{LoanID: r.PathValue("id")})
+	if err != nil {
+		c.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, presenter.Returned(resp))
+}
+
+// MemberLoans обрабатывает GET /members/{id}/loans.
+func (c *Loans) MemberLoans(w http.ResponseWriter, r *http.Request) {
+	resp, err := c.List.Execute(r.Context(), usecase.ListLoansRequest{MemberID: r.PathValue("id")})
+	if err != nil {
+		c.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, presenter.Loans(resp))
+}
+
+func (c *Loans) fail(w http.ResponseWriter, err error) {
+	status, view := presenter.Error(err)
+	if status == http.StatusInternalServerError && c.Log != nil {
+		c.Log.Printf("internal error: %v", err)
+	}
+	writeJSON(w, status, view)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,111 @@
+// Package gateway - адаптеры шлюзов usecase поверх таблиц db: переводят строки
+// в сущности и обратно и превращают ошибки драйвера в usecase.ErrNotFound.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"clean/internal/entity"
+	"clean/internal/framework/db"
+	"clean/internal/usecase"
+)
+
+type Store struct {
+	members *db.Table
+	copies  *db.Table
+	loans   *db.Table
+}
+
+var (
+	_ usecase.Members = (*Store)(nil)
+	_ usecase.Copies  = (*Store)(nil)
+	_ usecase.Loans   = (*Store)(nil)
+)
+
+func NewStore(members, copies, loans *db.Table) *Store {
+	return &Store{members: members, copies: copies, loans: loans}
+}
+
+// AddMember и AddCopy заполняют справочники; сценариев для них нет, их вызывает сборка.
+func (s *Store) AddMember(m entity.Member) {
+	s.members.Put(m.ID, db.Row{"id": m.ID, "name": m.Name, "blocked": m.Blocked, "max_loans": m.MaxLoans})
+}
+
+func (s *Store) AddCopy(c entity.Copy) {
+	s.SaveCopy(context.Background(), c)
+}
+
+func (s *Store) Member(ctx context.Context, id string) (entity.Member, error) {
+	r, err := get(s.members, "member", id)
+	if err != nil {
+		return entity.Member{}, err
+	}
+	return entity.Member{ID: r["id"].(string), Name: r["name"].(string), Blocked: r["blocked"].(bool), MaxLoans: r["max_loans"].(int)}, nil
+}
+
+func (s *Store) Copy(ctx context.Context, id string) (entity.Copy, error) {
+	r, err := get(s.copies, "copy", id)
+	if err != nil {
+		return entity.Copy{}, err
+	}
+	return entity.Copy{ID: r["id"].(string), Title: r["title"].(string), Lendable: r["lendable"].(bool), OnLoan: r["on_loan"].(bool)}, nil
+}
+
+func (s *Store) SaveCopy(ctx context.Context, c entity.Copy) error {
+	s.copies.Put(c.ID, db.Row{"id": c.ID, "title": c.Title, "lendable": c.Lendable, "on_loan": c.OnLoan})
+	return nil
+}
+
+func (s *Store) Loan(ctx context.Context, id string) (entity.Loan, error) {
+	r, err := get(s.loans, "loan", id)
+	if err != nil {
+		return entity.Loan{}, err
+	}
+	return loanFromRow(r), nil
+}
+
+func (s *Store) SaveLoan(ctx context.Context, l entity.Loan) error {
+	r := db.Row{"id": l.ID, "copy_id": l.CopyID, "member_id": l.MemberID, "loaned_at": l.LoanedAt, "due_at": l.DueAt}
+	if l.ReturnedAt != nil {
+		r["returned_at"] = *l.ReturnedAt
+	}
+	s.loans.Put(l.ID, r)
+	return nil
+}
+
+func (s *Store) OpenLoans(ctx context.Context, memberID string) ([]entity.Loan, error) {
+	rows := s.loans.Select(func(r db.Row) bool {
+		_, returned := r["returned_at"]
+		return r["member_id"] == memberID && !returned
+	})
+	out := make([]entity.Loan, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, loanFromRow(r))
+	}
+	return out, nil
+}
+
+func get(t *db.Table, kind, id string) (db.Row, error) {
+	r, err := t.Get(id)
+	if errors.Is(err, db.ErrNoRow) {
+		return nil, fmt.Errorf("%w: %s %q", usecase.ErrNotFound, kind, id)
+	}
+	return r, err
+}
+
+func loanFromRow(r db.Row) entity.Loan {
+	l := entity.Loan{
+		ID:       r["id"].(string),
+		CopyID:   r["copy_id"].(string),
+		MemberID: r["member_id"].(string),
+		LoanedAt: r["loaned_at"].(time.Time),
+		DueAt:    r["due_at"].(time.Time),
+	}
+	if t, ok := r["returned_at"].(time.Time); ok {
+		l.ReturnedAt = &t
+	}
+	return l
+}
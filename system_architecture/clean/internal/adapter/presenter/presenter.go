@@ -0,0 +1,78 @@
+// Package presenter переводит модели ответов сценариев в модели представления для JSON:
+// даты - в строки, копейки - в рубли, ошибки - в HTTP-статусы.
+package presenter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"clean/internal/entity"
+	"clean/internal/usecase"
+)
+
+type LoanView struct {
+	LoanID string `json:"loan_id"`
+	Title  string `json:"title,omitempty"`
+	CopyID string `json:"copy_id,omitempty"`
+	Due    string `json:"due"`
+	Fine   string `json:"fine,omitempty"`
+}
+
+type ReturnView struct {
+	LoanID   string `json:"loan_id"`
+	Returned string `json:"returned"`
+	Late     bool   `json:"late"`
+	Fine     string `json:"fine"`
+}
+
+type LoansView struct {
+	MemberID string     `json:"member_id"`
+	Loans    []LoanView `json:"loans"`
+	TotalDue string     `json:"total_due"`
+}
+
+type ErrorView struct {
+	Error string `json:"error"`
+}
+
+func Borrowed(r usecase.BorrowResponse) LoanView {
+	return LoanView{LoanID: r.LoanID, Title: r.Title, Due: date(r.DueAt)}
+}
+
+func Returned(r usecase.ReturnResponse) ReturnView {
+	return ReturnView{LoanID: r.LoanID, Returned: date(r.ReturnedAt), Late: r.Late, Fine: money(r.Fine)}
+}
+
+func Loans(r usecase.ListLoansResponse) LoansView {
+	v := LoansView{MemberID: r.MemberID, Loans: make([]LoanView, 0, len(r.Loans)), TotalDue: money(r.TotalDue)}
+	for _, l := range r.Loans {
+		v.Loans = append(v.Loans, LoanView{LoanID: l.LoanID, CopyID: l.CopyID, Due: date(l.DueAt), Fine: money(l.FineSoFar)})
+	}
+	return v
+}
+
+// Error возвращает статус и тело для ошибки сценария; неизвестные ошибки не раскрываются.
+func Error(err error) (int, ErrorView) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalid):
+		return http.StatusBadRequest, ErrorView{err.Error()}
+	case errors.Is(err, usecase.ErrNotFound):
+		return http.StatusNotFound, ErrorView{err.Error()}
+	case errors.Is(err, entity.ErrCopyUnavailable), errors.Is(err, entity.ErrAlreadyReturned):
+		return http.StatusConflict, ErrorView{err.Error()}
+	case errors.Is(err, entity.ErrMemberBlocked), errors.Is(err, entity.ErrLoanLimit):
+		return http.StatusForbidden, ErrorView{err.Error()}
+	default:
+		return http.StatusInternalServerError, ErrorView{"internal error"}
+	}
+}
+
+func date(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+func money(kopecks int) string {
+	return fmt.Sprintf("%d.%02d RUB", kopecks/100, kopecks%100)
+}
@@ -0,0 +1,85 @@
+// Package entity - корпоративные правила выдачи: они верны для любой библиотеки
+// и не зависят ни от сценариев, ни от хранилища, ни от HTTP. Зависимости направлены
+// только внутрь: framework → adapter → usecase → entity.
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrCopyUnavailable = errors.New("copy is not available")
+	ErrMemberBlocked   = errors.New("member is blocked")
+	ErrLoanLimit       = errors.New("member reached the loan limit")
+	ErrAlreadyReturned = errors.New("loan is already returned")
+)
+
+const (
+	LoanPeriod = 14 * 24 * time.Hour
+	// FinePerDay - пени за каждый начатый день просрочки, в копейках.
+	FinePerDay = 1000
+)
+
+type Member struct {
+	ID       string
+	Name     string
+	Blocked  bool
+	MaxLoans int
+}
+
+// CanBorrow проверяет правила читателя; open - число его открытых выдач.
+func (m Member) CanBorrow(open int) error {
+	if m.Blocked {
+		return ErrMemberBlocked
+	}
+	if open >= m.MaxLoans {
+		return ErrLoanLimit
+	}
+	return nil
+}
+
+type Copy struct {
+	ID       string
+	Title    string
+	Lendable bool
+	OnLoan   bool
+}
+
+type Loan struct {
+	ID         string
+	CopyID     string
+	MemberID   string
+	LoanedAt   time.Time
+	DueAt      time.Time
+	ReturnedAt *time.Time
+}
+
+// NewLoan оформляет выдачу экземпляра: справочные и уже выданные экземпляры не выдаются.
+func NewLoan(id string, c Copy, m Member, now time.Time) (Loan, error) {
+	if !c.Lendable || c.OnLoan {
+		return Loan{}, ErrCopyUnavailable
+	}
+	return Loan{ID: id, CopyID: c.ID, MemberID: m.ID, LoanedAt: now, DueAt: now.Add(LoanPeriod)}, nil
+}
+
+// Return закрывает выдачу и возвращает пени за просрочку.
+func (l *Loan) Return(now time.Time) (fine int, err error) {
+	if l.ReturnedAt != nil {
+		return 0, ErrAlreadyReturned
+	}
+	l.ReturnedAt = &now
+	return l.FineAt(now), nil
+}
+
+// FineAt считает пени на момент now: каждый начатый день после срока.
+func (l Loan) FineAt(now time.Time) int {
+	if !now.After(l.DueAt) {
+		return 0
+	}
+	days := int(now.Sub(l.DueAt) / (24 * time.Hour))
+	if now.Sub(l.DueAt)%(24*time.Hour) != 0 {
+		days++
+	}
+	return days * FinePerDay
+}
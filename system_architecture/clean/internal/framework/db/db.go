@@ -0,0 +1,66 @@
+// Package db - внешний слой «фреймворков и драйверов»: примитивная таблица в памяти,
+// которая хранит строки как есть и ничего не знает о выдачах. Её место мог бы
+// занять SQL-драйвер; от этого поменялся бы только адаптер gateway.
+package db
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+var ErrNoRow = errors.New("db: no row")
+
+type Row map[string]any
+
+// Table - потокобезопасная таблица строк по первичному ключу.
+type Table struct {
+	mu   sync.RWMutex
+	rows map[string]Row
+}
+
+func NewTable() *Table {
+	return &Table{rows: map[string]Row{}}
+}
+
+func (t *Table) Get(key string) (Row, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	r, ok := t.rows[key]
+	if !ok {
+		return nil, ErrNoRow
+	}
+	return clone(r), nil
+}
+
+func (t *Table) Put(key string, r Row) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows[key] = clone(r)
+}
+
+// Select возвращает строки, подходящие под where, в порядке ключей.
+func (t *Table) Select(where func(Row) bool) []Row {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	keys := make([]string, 0, len(t.rows))
+	for k := range t.rows {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out []Row
+	for _, k := range keys {
+		if r := t.rows[k]; where(r) {
+			out = append(out, clone(r))
+		}
+	}
+	return out
+}
+
+func clone(r Row) Row {
+	c := make(Row, len(r))
+	for k, v := range r {
+		c[k] = v
+	}
+	return c
+}
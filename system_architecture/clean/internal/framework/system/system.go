@@ -0,0 +1,20 @@
+// Package system - настоящие часы и генератор идентификаторов для сборки сервиса.
+package system
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+type Clock struct{}
+
+func (Clock) Now() time.Time { return time.Now().UTC() }
+
+type IDs struct{}
+
+func (IDs) NewID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,22 @@
+// Package web - внешний слой: маршрутизатор net/http и сервер. Он знает адреса
+// и методы, а что делать с запросом, решает контроллер.
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"clean/internal/adapter/controller"
+)
+
+func Router(c *controller.Loans) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /members/{id}/loans", c.BorrowCopy)
+	mux.HandleFunc("GET /members/{id}/loans", c.MemberLoans)
+	mux.HandleFunc("POST /loans/{id}/return", c.ReturnCopy)
+	return mux
+}
+
+func Server(addr string, h http.Handler) *http.Server {
+	return &http.Server{Addr: addr, Handler: h, ReadHeaderTimeout: 5 * time.Second}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"clean/internal/entity"
+)
+
+type BorrowRequest struct {
+	MemberID string
+	CopyID   string
+}
+
+type BorrowResponse struct {
+	LoanID string
+	Title  string
+	DueAt  time.Time
+}
+
+// BorrowCopy - сценарий «читатель берёт экземпляр».
+type BorrowCopy struct {
+	Members Members
+	Copies  Copies
+	Loans   Loans
+	Clock   Clock
+	IDs     IDs
+
+	mu sync.Mutex
+}
+
+func (uc *BorrowCopy) Execute(ctx context.Context, req BorrowRequest) (BorrowResponse, error) {
+	if strings.TrimSpace(req.MemberID) == "" || strings.TrimSpace(req.CopyID) == "" {
+		return BorrowResponse{}, fmt.Errorf("%w: member and copy are required", ErrInvalid)
+	}
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	m, err := uc.Members.Member(ctx, req.MemberID)
+	if err != nil {
+		return BorrowResponse{}, err
+	}
+	open, err := uc.Loans.OpenLoans(ctx, m.ID)
+	if err != nil {
+		return BorrowResponse{}, err
+	}
+	if err := m.CanBorrow(len(open)); err != nil {
+		return BorrowResponse{}, err
+	}
+	c, err := uc.Copies.Copy(ctx, req.CopyID)
+	if err != nil {
+		return BorrowResponse{}, err
+	}
+	loan, err := entity.NewLoan(uc.IDs.NewID(), c, m, uc.Clock.Now())
+	if err != nil {
+		return BorrowResponse{}, err
+	}
+	// Сначала выдача: если её не удалось сохранить, экземпляр остаётся свободным.
+	if err := uc.Loans.SaveLoan(ctx, loan); err != nil {
+		return BorrowResponse{}, err
+	}
+	c.OnLoan = true
+	if err := uc.Copies.SaveCopy(ctx, c); err != nil {
+		return BorrowResponse{}, err
+	}
+	return BorrowResponse{LoanID: loan.ID, Title: c.Title, DueAt: loan.DueAt}, nil
+}
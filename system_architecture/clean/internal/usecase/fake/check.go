@@ -0,0 +1,109 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"clean/internal/entity"
+	"clean/internal/usecase"
+)
+
+// Check прогоняет сценарии только на подделках и сообщает о первом расхождении.
+// Ни один адаптер и ни один фреймворк при этом не участвуют.
+func Check(w io.Writer) error {
+	ctx := context.Background()
+	g := NewGateways()
+	g.MembersByID["ann"] = entity.Member{ID: "ann", Name: "Ann", MaxLoans: 2}
+	g.MembersByID["bob"] = entity.Member{ID: "bob", Name: "Bob", Blocked: true, MaxLoans: 2}
+	g.CopiesByID["c1"] = entity.Copy{ID: "c1", Title: "Dune", Lendable: true}
+	g.CopiesByID["c2"] = entity.Copy{ID: "c2", Title: "Solaris", Lendable: true}
+	g.CopiesByID["c3"] = entity.Copy{ID: "c3", Title: "Hyperion", Lendable: true}
+	g.CopiesByID["ref"] = entity.Copy{ID: "ref", Title: "Encyclopedia"}
+	clock := &Clock{T: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}
+	borrow := &usecase.BorrowCopy{Members: g, Copies: g, Loans: g, Clock: clock, IDs: &IDs{}}
+	giveBack := &usecase.ReturnCopy{Copies: g, Loans: g, Clock: clock}
+	list := &usecase.ListMemberLoans{Members: g, Loans: g, Clock: clock}
+
+	step := func(name string, err error) error {
+		if err != nil {
+			return fmt.Errorf("clean: %s: %w", name, err)
+		}
+		fmt.Fprintf(w, "ok   %s\n", name)
+		return nil
+	}
+	expect := func(name string, err, want error) error {
+		if !errors.Is(err, want) {
+			return fmt.Errorf("clean: %s: got %v, want %v", name, err, want)
+		}
+		fmt.Fprintf(w, "ok   %s (%v)\n", name, err)
+		return nil
+	}
+
+	b, err := borrow.Execute(ctx, usecase.BorrowRequest{MemberID: "ann", CopyID: "c1"})
+	if err := step("borrow", err); err != nil {
+		return err
+	}
+	if b.LoanID != "loan-1" || b.Title != "Dune" || !b.DueAt.Equal(clock.T.Add(entity.LoanPeriod)) || !g.CopiesByID["c1"].OnLoan {
+		return fmt.Errorf("clean: borrow: unexpected response %+v", b)
+	}
+	checks := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"borrow the same copy", errOf(borrow.Execute(ctx, usecase.BorrowRequest{MemberID: "ann", CopyID: "c1"})), entity.ErrCopyUnavailable},
+		{"borrow a reference copy", errOf(borrow.Execute(ctx, usecase.BorrowRequest{MemberID: "ann", CopyID: "ref"})), entity.ErrCopyUnavailable},
+		{"blocked member", errOf(borrow.Execute(ctx, usecase.BorrowRequest{MemberID: "bob", CopyID: "c2"})), entity.ErrMemberBlocked},
+		{"unknown member", errOf(borrow.Execute(ctx, usecase.BorrowRequest{MemberID: "eve", CopyID: "c2"})), usecase.ErrNotFound},
+		{"empty request", errOf(borrow.Execute(ctx, usecase.BorrowRequest{})), usecase.ErrInvalid},
+	}
+	for _, c := range checks {
+		if err := expect(c.name, c.err, c.want); err != nil {
+			return err
+		}
+	}
+	if _, err := borrow.Execute(ctx, usecase.BorrowRequest{MemberID: "ann", CopyID: "c2"}); err != nil {
+		return fmt.Errorf("clean: second borrow: %w", err)
+	}
+	if err := expect("loan limit", errOf(borrow.Execute(ctx, usecase.BorrowRequest{MemberID: "ann", CopyID: "c3"})), entity.ErrLoanLimit); err != nil {
+		return err
+	}
+
+	// Через 16 дней первая выдача просрочена на двое суток.
+	clock.Advance(16 * 24 * time.Hour)
+	l, err := list.Execute(ctx, usecase.ListLoansRequest{MemberID: "ann"})
+	if err := step("list loans", err); err != nil {
+		return err
+	}
+	if len(l.Loans) != 2 || l.TotalDue != 4*entity.FinePerDay {
+		return fmt.Errorf("clean: list loans: got %d loans due %d, want 2 loans due %d", len(l.Loans), l.TotalDue, 4*entity.FinePerDay)
+	}
+	r, err := giveBack.Execute(ctx, usecase.ReturnRequest{LoanID: b.LoanID})
+	if err := step("late return", err); err != nil {
+		return err
+	}
+	if !r.Late || r.Fine != 2*entity.FinePerDay || g.CopiesByID["c1"].OnLoan {
+		return fmt.Errorf("clean: late return: unexpected response %+v", r)
+	}
+	if err := expect("return twice", errOf(giveBack.Execute(ctx, usecase.ReturnRequest{LoanID: b.LoanID})), entity.ErrAlreadyReturned); err != nil {
+		return err
+	}
+
+	// Сбой хранилища доходит до вызывающего как есть, экземпляр при этом не теряется.
+	broken := errors.New("disk full")
+	g.FailSave = broken
+	if err := expect("storage failure", errOf(borrow.Execute(ctx, usecase.BorrowRequest{MemberID: "ann", CopyID: "c3"})), broken); err != nil {
+		return err
+	}
+	if g.CopiesByID["c3"].OnLoan {
+		return fmt.Errorf("clean: storage failure: copy c3 left on loan")
+	}
+	return nil
+}
+
+func errOf[T any](_ T, err error) error {
+	return err
+}
@@ -0,0 +1,98 @@
+// Package fake - подделки шлюзов usecase для проверки сценариев без адаптеров и фреймворков:
+// карты вместо хранилища, ручные часы и предсказуемые идентификаторы.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"clean/internal/entity"
+	"clean/internal/usecase"
+)
+
+type Gateways struct {
+	MembersByID map[string]entity.Member
+	CopiesByID  map[string]entity.Copy
+	LoansByID   map[string]entity.Loan
+	// FailSave, если задана, возвращается из SaveLoan - так проверяется обработка сбоев хранилища.
+	FailSave error
+}
+
+var (
+	_ usecase.Members = (*Gateways)(nil)
+	_ usecase.Copies  = (*Gateways)(nil)
+	_ usecase.Loans   = (*Gateways)(nil)
+)
+
+func NewGateways() *Gateways {
+	return &Gateways{MembersByID: map[string]entity.Member{}, CopiesByID: map[string]entity.Copy{}, LoansByID: map[string]entity.Loan{}}
+}
+
+func (g *Gateways) Member(ctx context.Context, id string) (entity.Member, error) {
+	m, ok := g.MembersByID[id]
+	if !ok {
+		return entity.Member{}, fmt.Errorf("%w: member %q", usecase.ErrNotFound, id)
+	}
+	return m, nil
+}
+
+func (g *Gateways) Copy(ctx context.Context, id string) (entity.Copy, error) {
+	c, ok := g.CopiesByID[id]
+	if !ok {
+		return entity.Copy{}, fmt.Errorf("%w: copy %q", usecase.ErrNotFound, id)
+	}
+	return c, nil
+}
+
+func (g *Gateways) SaveCopy(ctx context.Context, c entity.Copy) error {
+	g.CopiesByID[c.ID] = c
+	return nil
+}
+
+func (g *Gateways) Loan(ctx context.Context, id string) (entity.Loan, error) {
+	l, ok := g.LoansByID[id]
+	if !ok {
+		return entity.Loan{}, fmt.Errorf("%w: loan %q", usecase.ErrNotFound, id)
+	}
+	return l, nil
+}
+
+func (g *Gateways) SaveLoan(ctx context.Context, l entity.Loan) error {
+	if g.FailSave != nil {
+		return g.FailSave
+	}
+	g.LoansByID[l.ID] = l
+	return nil
+}
+
+func (g *Gateways) OpenLoans(ctx context.Context, memberID string) ([]entity.Loan, error) {
+	var out []entity.Loan
+	for _, l := range g.LoansByID {
+		if l.MemberID == memberID && l.ReturnedAt == nil {
+			out = append(out, l)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// Clock - часы, которые двигаются только вручную.
+type Clock struct {
+	T time.Time
+}
+
+func (c *Clock) Now() time.Time { return c.T }
+
+func (c *Clock) Advance(d time.Duration) { c.T = c.T.Add(d) }
+
+// IDs выдаёт loan-1, loan-2, ...
+type IDs struct {
+	n int
+}
+
+func (s *IDs) NewID() string {
+	s.n++
+	return fmt.Sprintf("loan-%d", s.n)
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"time"
+)
+
+type ListLoansRequest struct {
+	MemberID string
+}
+
+type LoanSummary struct {
+	LoanID string
+	CopyID string
+	DueAt  time.Time
+	// FineSoFar - пени, если сдать экземпляр прямо сейчас.
+	FineSoFar int
+}
+
+type ListLoansResponse struct {
+	MemberID string
+	Loans    []LoanSummary
+	TotalDue int
+}
+
+// ListMemberLoans - сценарий «что у меня на руках и сколько я должен».
+type ListMemberLoans struct {
+	Members Members
+	Loans   Loans
+	Clock   Clock
+}
+
+func (uc *ListMemberLoans) Execute(ctx context.Context, req ListLoansRequest) (ListLoansResponse, error) {
+	m, err := uc.Members.Member(ctx, req.MemberID)
+	if err != nil {
+		return ListLoansResponse{}, err
+	}
+	open, err := uc.Loans.OpenLoans(ctx, m.ID)
+	if err != nil {
+		return ListLoansResponse{}, err
+	}
+	now := uc.Clock.Now()
+	resp := ListLoansResponse{MemberID: m.ID, Loans: make([]LoanSummary, 0, len(open))}
+	for _, l := range open {
+		fine := l.FineAt(now)
+		resp.Loans = append(resp.Loans, LoanSummary{LoanID: l.ID, CopyID: l.CopyID, DueAt: l.DueAt, FineSoFar: fine})
+		resp.TotalDue += fine
+	}
+	return resp, nil
+}
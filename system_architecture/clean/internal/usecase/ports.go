@@ -0,0 +1,40 @@
+// Package usecase - прикладные правила: по интерактору на сценарий, у каждого свои
+// модели запроса и ответа. Интеракторы знают только сущности и интерфейсы шлюзов ниже.
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"clean/internal/entity"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	ErrInvalid  = errors.New("invalid request")
+)
+
+// Шлюзы реализуются слоем адаптеров; ErrNotFound - общий признак отсутствия записи.
+type Members interface {
+	Member(ctx context.Context, id string) (entity.Member, error)
+}
+
+type Copies interface {
+	Copy(ctx context.Context, id string) (entity.Copy, error)
+	SaveCopy(ctx context.Context, c entity.Copy) error
+}
+
+type Loans interface {
+	Loan(ctx context.Context, id string) (entity.Loan, error)
+	SaveLoan(ctx context.Context, l entity.Loan) error
+	OpenLoans(ctx context.Context, memberID string) ([]entity.Loan, error)
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
+type IDs interface {
+	NewID() string
+}
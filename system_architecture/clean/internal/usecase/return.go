@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ReturnRequest struct {
+	LoanID string
+}
+
+type ReturnResponse struct {
+	LoanID     string
+	ReturnedAt time.Time
+	Late       bool
+	Fine       int
+}
+
+// ReturnCopy - сценарий «читатель сдаёт экземпляр»; просрочка оборачивается пенями.
+type ReturnCopy struct {
+	Copies Copies
+	Loans  Loans
+	Clock  Clock
+
+	mu sync.Mutex
+}
+
+func (uc *ReturnCopy) Execute(ctx context.Context, req ReturnRequest) (ReturnResponse, error) {
+	if strings.TrimSpace(req.LoanID) == "" {
+		return ReturnResponse{}, fmt.Errorf("%w: loan is required", ErrInvalid)
+	}
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	loan, err := uc.Loans.Loan(ctx, req.LoanID)
+	if err != nil {
+		return ReturnResponse{}, err
+	}
+	now := uc.Clock.Now()
+	fine, err := loan.Return(now)
+	if err != nil {
+		return ReturnResponse{}, err
+	}
+	c, err := uc.Copies.Copy(ctx, loan.CopyID)
+	if err != nil {
+		return ReturnResponse{}, err
+	}
+	c.OnLoan = false
+	if err := uc.Copies.SaveCopy(ctx, c); err != nil {
+		return ReturnResponse{}, err
+	}
+	if err := uc.Loans.SaveLoan(ctx, loan); err != nil {
+		return ReturnResponse{}, err
+	}
+	return ReturnResponse{LoanID: loan.ID, ReturnedAt: now, Late: fine > 0, Fine: fine}, nil
+}
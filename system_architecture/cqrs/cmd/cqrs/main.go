@@ -0,0 +1,67 @@
+// Команда cqrs поднимает сервис книг с раздельными сторонами записи и чтения:
+// команды → доменные события → проекция. Связка сторон живёт только здесь.
+//
+//	cqrs serve [-addr :8084]   поднять HTTP API
+//	cqrs check                 проверить согласованность в конечном счёте
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"solid/app"
+	"solid/health"
+
+	"cqrs/internal/check"
+	"cqrs/internal/command"
+	"cqrs/internal/event"
+	"cqrs/internal/httpapi"
+	"cqrs/internal/query"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: cqrs serve [-addr :8084] | cqrs check")
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "serve":
+		fs := flag.NewFlagSet("serve", flag.ExitOnError)
+		addr := fs.String("addr", ":8084", "HTTP listen address")
+		fs.Parse(os.Args[2:])
+		serve(*addr)
+	case "check":
+		if err := check.Run(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "cqrs: unknown command %q\n", os.Args[1])
+		os.Exit(2)
+	}
+}
+
+func serve(addr string) {
+	logger := log.Default()
+	bus := event.NewBus(256)
+	reads := query.NewProjection()
+	bus.Subscribe(reads.Apply)
+	api := &httpapi.API{Commands: command.NewHandlers(bus), Reads: reads, Log: logger}
+
+	// Шина закрывается после HTTP: проекция успевает применить события последних команд.
+	a := app.New(app.Config{Logger: logger})
+	a.Add("bus", app.Closer(func() error { bus.Close(); return nil }))
+	mux := http.NewServeMux()
+	mux.Handle("/", api.Routes())
+	health.New(health.Config{}).Register(mux)
+	a.HTTP("http", &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second})
+	logger.Printf("cqrs library listening on %s", addr)
+	if err := a.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
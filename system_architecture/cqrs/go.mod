@@ -0,0 +1,7 @@
+module cqrs
+
+go 1.23
+
+require solid v0.0.0
+
+replace solid => ../solid
@@ -0,0 +1,76 @@
+// Package check проверяет согласованность в конечном счёте: проекцию временно
+// задерживают, убеждаются, что чтение отстаёт от записи, затем отпускают и ждут.
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"cqrs/internal/command"
+	"cqrs/internal/event"
+	"cqrs/internal/query"
+)
+
+func Run(w io.Writer) error {
+	bus := event.NewBus(16)
+	defer bus.Close()
+	reads := query.NewProjection()
+	gate := make(chan struct{})
+	bus.Subscribe(func(e event.Event) {
+		<-gate
+		reads.Apply(e)
+	})
+	cmds := command.NewHandlers(bus)
+	ctx := context.Background()
+
+	seq, err := cmds.AddBook(ctx, command.AddBook{ID: "dune", Title: "Dune", Author: "Frank Herbert"})
+	if err != nil {
+		return err
+	}
+	if seq, err = cmds.LendBook(ctx, command.LendBook{BookID: "dune", Borrower: "ann"}); err != nil {
+		return err
+	}
+	// Запись уже знает, что книга выдана, - повторная выдача отклоняется,
+	// хотя проекция ещё не видела ни одного события.
+	if _, err := cmds.LendBook(ctx, command.LendBook{BookID: "dune", Borrower: "bob"}); !errors.Is(err, command.ErrUnavailable) {
+		return fmt.Errorf("cqrs: second lend: got %v, want %v", err, command.ErrUnavailable)
+	}
+	if _, err := reads.Book("dune"); !errors.Is(err, query.ErrNotFound) || reads.Version() != 0 {
+		return fmt.Errorf("cqrs: projection is ahead of the gate: version %d", reads.Version())
+	}
+	fmt.Fprintf(w, "write side at seq %d, read side at %d: stale read\n", seq, reads.Version())
+
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := reads.WaitFor(short, seq); !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("cqrs: wait on a blocked projection: got %v, want deadline", err)
+	}
+
+	close(gate)
+	if err := reads.WaitFor(ctx, seq); err != nil {
+		return err
+	}
+	b, err := reads.Book("dune")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "read side caught up at %d: %s by %s, lent to %s, lent %d time(s)\n", reads.Version(), b.Title, b.Author, b.LentTo, b.TimesLent)
+	if b.Available || b.LentTo != "ann" || b.TimesLent != 1 || len(reads.LentTo("ann")) != 1 {
+		return fmt.Errorf("cqrs: unexpected view %+v", b)
+	}
+
+	if seq, err = cmds.ReturnBook(ctx, command.ReturnBook{BookID: "dune"}); err != nil {
+		return err
+	}
+	if err := reads.WaitFor(ctx, seq); err != nil {
+		return err
+	}
+	if got := reads.Books(true); len(got) != 1 || len(reads.LentTo("ann")) != 0 {
+		return fmt.Errorf("cqrs: after return: available %v, ann has %v", got, reads.LentTo("ann"))
+	}
+	fmt.Fprintf(w, "after return at %d: %d available, ann holds %d\n", reads.Version(), len(reads.Books(true)), len(reads.LentTo("ann")))
+	return nil
+}
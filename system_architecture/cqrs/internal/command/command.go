@@ -0,0 +1,113 @@
+// Package command - сторона записи. Команды меняют модель записи (Book с инвариантами
+// выдачи) и публикуют события; читать отсюда списки книг нельзя - для этого есть query.
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"cqrs/internal/event"
+)
+
+var (
+	ErrInvalid     = errors.New("command: invalid command")
+	ErrNotFound    = errors.New("command: book not found")
+	ErrUnavailable = errors.New("command: book is already lent")
+	ErrNotLent     = errors.New("command: book is not lent")
+)
+
+// book - модель записи: только то, что нужно для проверки инвариантов.
+type book struct {
+	id       string
+	borrower string
+}
+
+type AddBook struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+type LendBook struct {
+	BookID   string `json:"book_id"`
+	Borrower string `json:"borrower"`
+}
+
+type ReturnBook struct {
+	BookID string `json:"book_id"`
+}
+
+type Publisher interface {
+	Publish(events ...event.Event) uint64
+}
+
+// Handlers обрабатывают команды. Каждый метод возвращает номер последнего события,
+// по которому клиент может дождаться обновления проекции.
+type Handlers struct {
+	Events Publisher
+	Now    func() time.Time
+
+	mu    sync.Mutex
+	books map[string]*book
+}
+
+func NewHandlers(events Publisher) *Handlers {
+	return &Handlers{Events: events, Now: time.Now, books: map[string]*book{}}
+}
+
+func (h *Handlers) AddBook(ctx context.Context, c AddBook) (uint64, error) {
+	if strings.TrimSpace(c.ID) == "" || strings.TrimSpace(c.Title) == "" {
+		return 0, fmt.Errorf("%w: id and title are required", ErrInvalid)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.books[c.ID]; ok {
+		return 0, fmt.Errorf("%w: book %q already exists", ErrInvalid, c.ID)
+	}
+	h.books[c.ID] = &book{id: c.ID}
+	return h.Events.Publish(event.Event{Kind: event.BookAdded, BookID: c.ID, Title: c.Title, Author: c.Author, At: h.Now()}), nil
+}
+
+func (h *Handlers) LendBook(ctx context.Context, c LendBook) (uint64, error) {
+	if strings.TrimSpace(c.Borrower) == "" {
+		return 0, fmt.Errorf("%w: borrower is required", ErrInvalid)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	b, err := h.book(c.BookID)
+	if err != nil {
+		return 0, err
+	}
+	if b.borrower != "" {
+		return 0, fmt.Errorf("%w: %q", ErrUnavailable, c.BookID)
+	}
+	b.borrower = c.Borrower
+	return h.Events.Publish(event.Event{Kind: event.BookLent, BookID: b.id, Borrower: c.Borrower, At: h.Now()}), nil
+}
+
+func (h *Handlers) ReturnBook(ctx context.Context, c ReturnBook) (uint64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	b, err := h.book(c.BookID)
+	if err != nil {
+		return 0, err
+	}
+	if b.borrower == "" {
+		return 0, fmt.Errorf("%w: %q", ErrNotLent, c.BookID)
+	}
+	borrower := b.borrower
+	b.borrower = ""
+	return h.Events.Publish(event.Event{Kind: event.BookReturned, BookID: b.id, Borrower: borrower, At: h.Now()}), nil
+}
+
+func (h *Handlers) book(id string) (*book, error) {
+	b, ok := h.books[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	return b, nil
+}
@@ -0,0 +1,84 @@
+// Package event - доменные события, которыми сторона записи сообщает о переменах,
+// и асинхронная шина, доставляющая их проекциям. Запись не ждёт чтения: проекция
+// догоняет её сама, отсюда согласованность «в конечном счёте».
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+type Kind string
+
+const (
+	BookAdded    Kind = "book_added"
+	BookLent     Kind = "book_lent"
+	BookReturned Kind = "book_returned"
+)
+
+// Event - факт о книге. Seq присваивает шина, он строго возрастает.
+type Event struct {
+	Seq      uint64
+	Kind     Kind
+	BookID   string
+	Title    string
+	Author   string
+	Borrower string
+	At       time.Time
+}
+
+type Handler func(Event)
+
+// Bus доставляет события подписчикам в порядке публикации, в отдельной горутине.
+type Bus struct {
+	// mu упорядочивает публикацию, hmu защищает подписчиков: цикл доставки не должен
+	// ждать mu, пока Publish стоит на полной очереди.
+	mu       sync.Mutex
+	hmu      sync.Mutex
+	seq      uint64
+	queue    chan Event
+	handlers []Handler
+	done     chan struct{}
+}
+
+func NewBus(buffer int) *Bus {
+	b := &Bus{queue: make(chan Event, buffer), done: make(chan struct{})}
+	go b.loop()
+	return b
+}
+
+func (b *Bus) Subscribe(h Handler) {
+	b.hmu.Lock()
+	defer b.hmu.Unlock()
+	b.handlers = append(b.handlers, h)
+}
+
+// Publish нумерует события и ставит их в очередь; возвращает номер последнего.
+func (b *Bus) Publish(events ...Event) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range events {
+		b.seq++
+		e.Seq = b.seq
+		b.queue <- e
+	}
+	return b.seq
+}
+
+// Close дожидается доставки уже опубликованных событий.
+func (b *Bus) Close() {
+	close(b.queue)
+	<-b.done
+}
+
+func (b *Bus) loop() {
+	defer close(b.done)
+	for e := range b.queue {
+		b.hmu.Lock()
+		hs := b.handlers
+		b.hmu.Unlock()
+		for _, h := range hs {
+			h(e)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package httpapi раздаёт команды и запросы по разным маршрутам. Команды отвечают 202
+// и номером события в X-Event-Seq; запрос с ?after=<seq> ждёт, пока проекция его догонит.
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cqrs/internal/command"
+	"cqrs/internal/query"
+)
+
+type API struct {
+	Commands *command.Handlers
+	Reads    *query.Projection
+	Log      *log.Logger
+	// WaitTimeout ограничивает ожидание ?after=; по умолчанию секунда.
+	WaitTimeout time.Duration
+}
+
+func (a *API) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /books", a.addBook)
+	mux.HandleFunc("POST /books/{id}/lend", a.lend)
+	mux.HandleFunc("POST /books/{id}/return", a.giveBack)
+	mux.HandleFunc("GET /books", a.listBooks)
+	mux.HandleFunc("GET /books/{id}", a.getBook)
+	mux.HandleFunc("GET /readers/{name}/books", a.readerBooks)
+	return mux
+}
+
+func (a *API) addBook(w http.ResponseWriter, r *http.Request) {
+	var c command.AddBook
+	if !decode(w, r, &c) {
+		return
+	}
+	seq, err := a.Commands.AddBook(r.Context(), c)
+	a.accepted(w, seq, err)
+}
+
+func (a *API) lend(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Borrower string `json:"borrower"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	seq, err := a.Commands.LendBook(r.Context(), command.LendBook{BookID: r.PathValue("id"), Borrower: body.Borrower})
+	a.accepted(w, seq, err)
+}
+
+func (a *API) giveBack(w http.ResponseWriter, r *http.Request) {
+	seq, err := a.Commands.ReturnBook(r.Context(), command.ReturnBook{BookID: r.PathValue("id")})
+	a.accepted(w, seq, err)
+}
+
+func (a *API) listBooks(w http.ResponseWriter, r *http.Request) {
+	if !a.sync(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"books": a.Reads.Books(r.URL.Query().Get("available") == "true"), "version": a.Reads.Version()})
+}
+
+func (a *API) getBook(w http.ResponseWriter, r *http.Request) {
+	if !a.sync(w, r) {
+		return
+	}
+	b, err := a.Reads.Book(r.PathValue("id"))
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (a *API) readerBooks(w http.ResponseWriter, r *http.Request) {
+	if !a.sync(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"books": a.Reads.LentTo(r.PathValue("name")), "version": a.Reads.Version()})
+}
+
+func (a *API) accepted(w http.ResponseWriter, seq uint64, err error) {
+	if err != nil {
+		a.fail(w, err)
+		return
+	}
+	w.Header().Set("X-Event-Seq", strconv.FormatUint(seq, 10))
+	writeJSON(w, http.StatusAccepted, map[string]uint64{"seq": seq})
+}
+
+// sync выполняет ?after=<seq>: без параметра запрос читает проекцию как есть.
+func (a *API) sync(w http.ResponseWriter, r *http.Request) bool {
+	after := r.URL.Query().Get("after")
+	if after == "" {
+		return true
+	}
+	seq, err := strconv.ParseUint(after, 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "after must be an event number"})
+		return false
+	}
+	timeout := a.WaitTimeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	if err := a.Reads.WaitFor(ctx, seq); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "projection is behind, retry later"})
+		return false
+	}
+	return true
+}
+
+func (a *API) fail(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, command.ErrInvalid):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, command.ErrNotFound), errors.Is(err, query.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, command.ErrUnavailable), errors.Is(err, command.ErrNotLent):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		if a.Log != nil {
+			a.Log.Printf("internal error: %v", err)
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+	}
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
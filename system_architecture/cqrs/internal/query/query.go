@@ -0,0 +1,131 @@
+// Package query - сторона чтения: денормализованная проекция, собранная из событий.
+// Модель чтения хранит готовые к выдаче поля (автор рядом с книгой, счётчик выдач,
+// книги читателя), поэтому запросы не делают ни соединений, ни подсчётов.
+package query
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"cqrs/internal/event"
+)
+
+var ErrNotFound = errors.New("query: book not found")
+
+// BookView - строка модели чтения.
+type BookView struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title"`
+	Author     string    `json:"author"`
+	Available  bool      `json:"available"`
+	LentTo     string    `json:"lent_to,omitempty"`
+	TimesLent  int       `json:"times_lent"`
+	LastChange time.Time `json:"last_change"`
+}
+
+// Projection применяет события к модели чтения и помнит номер последнего из них.
+type Projection struct {
+	mu       sync.RWMutex
+	cond     *sync.Cond
+	version  uint64
+	books    map[string]BookView
+	byReader map[string]map[string]bool
+}
+
+func NewProjection() *Projection {
+	p := &Projection{books: map[string]BookView{}, byReader: map[string]map[string]bool{}}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Apply - обработчик для event.Bus. Повторно доставленные события пропускаются.
+func (p *Projection) Apply(e event.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if e.Seq <= p.version {
+		return
+	}
+	b := p.books[e.BookID]
+	switch e.Kind {
+	case event.BookAdded:
+		b = BookView{ID: e.BookID, Title: e.Title, Author: e.Author, Available: true}
+	case event.BookLent:
+		b.Available, b.LentTo = false, e.Borrower
+		b.TimesLent++
+		if p.byReader[e.Borrower] == nil {
+			p.byReader[e.Borrower] = map[string]bool{}
+		}
+		p.byReader[e.Borrower][e.BookID] = true
+	case event.BookReturned:
+		b.Available, b.LentTo = true, ""
+		delete(p.byReader[e.Borrower], e.BookID)
+	}
+	b.LastChange = e.At
+	p.books[e.BookID] = b
+	p.version = e.Seq
+	p.cond.Broadcast()
+}
+
+func (p *Projection) Version() uint64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.version
+}
+
+// WaitFor ждёт, пока проекция не применит событие seq, - так клиент читает свою запись.
+func (p *Projection) WaitFor(ctx context.Context, seq uint64) error {
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.cond.Broadcast()
+	})
+	defer stop()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.version < seq {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p.cond.Wait()
+	}
+	return nil
+}
+
+func (p *Projection) Book(id string) (BookView, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	b, ok := p.books[id]
+	if !ok {
+		return BookView{}, ErrNotFound
+	}
+	return b, nil
+}
+
+// Books возвращает книги по id; onlyAvailable оставляет только свободные.
+func (p *Projection) Books(onlyAvailable bool) []BookView {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]BookView, 0, len(p.books))
+	for _, b := range p.books {
+		if !onlyAvailable || b.Available {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// LentTo возвращает книги на руках у читателя.
+func (p *Projection) LentTo(reader string) []BookView {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]BookView, 0, len(p.byReader[reader]))
+	for id := range p.byReader[reader] {
+		out = append(out, p.books[id])
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
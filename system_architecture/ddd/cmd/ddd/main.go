@@ -0,0 +1,54 @@
+// Команда ddd проверяет ограниченный контекст «Выдача»: объекты-значения, инварианты
+// агрегатов, доменную службу и репозитории, интерфейсы которых принадлежат домену.
+//
+//	ddd check                     на репозиториях в памяти
+//	ddd check -dsn postgres://... на PostgreSQL; таблицы lending_* очищаются перед прогоном
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"ddd/internal/check"
+	"ddd/internal/lending/memrepo"
+	"ddd/internal/lending/sqlrepo"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "check" {
+		fmt.Fprintln(os.Stderr, "usage: ddd check [-dsn postgres://...]")
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	dsn := fs.String("dsn", "", "PostgreSQL DSN (in-memory repositories if empty)")
+	fs.Parse(os.Args[2:])
+	if err := run(*dsn); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dsn string) error {
+	if dsn == "" {
+		return check.Run(os.Stdout, memrepo.New())
+	}
+	ctx := context.Background()
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	store := sqlrepo.New(db)
+	if err := store.Migrate(ctx); err != nil {
+		return fmt.Errorf("ddd: migrate: %w", err)
+	}
+	if err := store.Reset(ctx); err != nil {
+		return err
+	}
+	return check.Run(os.Stdout, store)
+}
@@ -0,0 +1,219 @@
+// Package check прогоняет сценарии контекста «Выдача» на любой реализации репозиториев
+// (memrepo, sqlrepo) и сообщает о первом расхождении с правилами домена.
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"ddd/internal/lending"
+	"ddd/internal/lending/app"
+)
+
+const (
+	dune  = "0-441-01359-7"
+	encyc = "978-0-306-40615-7"
+)
+
+type journal []lending.Event
+
+func (j *journal) Publish(ctx context.Context, events ...lending.Event) { *j = append(*j, events...) }
+
+// Store - репозитории и единица работы; хранилище должно быть пустым.
+type Store interface {
+	lending.CopyRepository
+	lending.MemberRepository
+	app.Transactor
+}
+
+func Run(w io.Writer, store Store) error {
+	ctx := context.Background()
+	step := func(name string, err error) error {
+		if err != nil {
+			return fmt.Errorf("ddd: %s: %w", name, err)
+		}
+		fmt.Fprintf(w, "ok   %s\n", name)
+		return nil
+	}
+	expect := func(name string, err, want error) error {
+		if !errors.Is(err, want) {
+			return fmt.Errorf("ddd: %s: got %v, want %v", name, err, want)
+		}
+		fmt.Fprintf(w, "ok   %s (%v)\n", name, err)
+		return nil
+	}
+
+	// Объекты-значения: ISBN-10 и ISBN-13 одной книги равны, неверная контрольная цифра отвергается.
+	a, err := lending.ParseISBN(dune)
+	if err != nil {
+		return err
+	}
+	b, err := lending.ParseISBN("978-0-441-01359-3")
+	if err := step("ISBN-10 equals ISBN-13 "+a.String(), errors.Join(err, eq(a == b, "different values"))); err != nil {
+		return err
+	}
+	_, err = lending.ParseISBN("0-441-01359-8")
+	if err := expect("bad ISBN checksum", err, lending.ErrInvalidISBN); err != nil {
+		return err
+	}
+	rub, usd := lending.Zero("RUB"), lending.Zero("USD")
+	_, err = rub.Add(usd)
+	if err := expect("RUB + USD", err, lending.ErrCurrencyMismatch); err != nil {
+		return err
+	}
+
+	for _, c := range []struct {
+		id        lending.CopyID
+		isbn      string
+		reference bool
+	}{{"dune-1", dune, false}, {"dune-2", dune, false}, {"encyc-1", encyc, true}} {
+		isbn, err := lending.ParseISBN(c.isbn)
+		if err != nil {
+			return err
+		}
+		cp, err := lending.NewCopy(c.id, isbn, c.reference)
+		if err != nil {
+			return err
+		}
+		if err := store.SaveCopy(ctx, cp); err != nil {
+			return err
+		}
+	}
+	for _, m := range []struct {
+		id    lending.MemberID
+		limit int
+	}{{"ann", 1}, {"bob", 3}, {"eve", 2}} {
+		member, err := lending.NewMember(m.id, string(m.id), m.limit, "RUB")
+		if err != nil {
+			return err
+		}
+		if err := store.SaveMember(ctx, member); err != nil {
+			return err
+		}
+	}
+
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	seq := 0
+	var events journal
+	svc := &app.Service{
+		Copies: store, Members: store, Tx: store,
+		Lending: lending.Lending{Policy: lending.DefaultPolicy("RUB")},
+		Now:     func() time.Time { return now },
+		NewLoanID: func() lending.LoanID {
+			seq++
+			return lending.LoanID(fmt.Sprintf("loan-%d", seq))
+		},
+		Events: &events,
+	}
+
+	loan, err := svc.Borrow(ctx, "ann", dune)
+	if err := step("ann borrows "+string(loan.Copy), err); err != nil {
+		return err
+	}
+	_, err = svc.Borrow(ctx, "ann", dune)
+	if err := expect("ann over the limit", err, lending.ErrLoanLimit); err != nil {
+		return err
+	}
+	_, err = svc.Borrow(ctx, "bob", encyc)
+	if err := expect("reference copy", err, lending.ErrNoCopyAvailable); err != nil {
+		return err
+	}
+	second, err := svc.Borrow(ctx, "bob", "9780441013593")
+	if err := step("bob borrows "+string(second.Copy)+" by ISBN-13", err); err != nil {
+		return err
+	}
+	_, err = svc.Borrow(ctx, "eve", dune)
+	if err := expect("no free copies", err, lending.ErrNoCopyAvailable); err != nil {
+		return err
+	}
+
+	// Инвариант агрегата: экземпляр не выдаётся дважды, даже напрямую через доменную службу.
+	policy := lending.Lending{Policy: lending.DefaultPolicy("RUB")}
+	taken, err := store.Copy(ctx, loan.Copy)
+	if err != nil {
+		return err
+	}
+	eve, err := store.Member(ctx, "eve")
+	if err != nil {
+		return err
+	}
+	_, err = policy.Lend(eve, taken, "loan-x", now)
+	if err := expect("lend a copy on loan", err, lending.ErrAlreadyLoaned); err != nil {
+		return err
+	}
+	if err := step("eve is unchanged after the refusal", eq(eve.OpenLoans() == 0, "eve has open loans")); err != nil {
+		return err
+	}
+
+	// Два экземпляра одного агрегата: второй Save опоздал и не может перезаписать выдачу.
+	if _, err := svc.Return(ctx, second.Copy); err != nil {
+		return err
+	}
+	first, err := store.Copy(ctx, second.Copy)
+	if err != nil {
+		return err
+	}
+	stale, err := store.Copy(ctx, second.Copy)
+	if err != nil {
+		return err
+	}
+	bob, err := store.Member(ctx, "bob")
+	if err != nil {
+		return err
+	}
+	if _, err := policy.Lend(eve, first, "loan-e", now); err != nil {
+		return err
+	}
+	if _, err := policy.Lend(bob, stale, "loan-b", now); err != nil {
+		return err
+	}
+	if err := store.SaveCopy(ctx, first); err != nil {
+		return err
+	}
+	err = store.SaveCopy(ctx, stale)
+	if err := expect("stale copy save", err, lending.ErrConflict); err != nil {
+		return err
+	}
+
+	// Просрочка на 5 дней и 1 час - 6 начатых дней по 10 рублей; долг 60 блокирует ann.
+	now = loan.DueAt.Add(5*24*time.Hour + time.Hour)
+	fine, err := svc.Return(ctx, loan.Copy)
+	if err := step("ann returns late, fine "+fine.String(), errors.Join(err, eq(fine.Minor() == 6000, "wrong fine"))); err != nil {
+		return err
+	}
+	_, err = svc.Borrow(ctx, "ann", dune)
+	if err := expect("ann is blocked", err, lending.ErrMemberBlocked); err != nil {
+		return err
+	}
+	pay, err := lending.NewMoney(6000, "RUB")
+	if err != nil {
+		return err
+	}
+	if err := svc.PayFine(ctx, "ann", pay); err != nil {
+		return err
+	}
+	again, err := svc.Borrow(ctx, "ann", dune)
+	if err := step("ann pays and borrows "+string(again.Copy), err); err != nil {
+		return err
+	}
+
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = e.EventName()
+	}
+	fmt.Fprintf(w, "events: %v\n", names)
+	if len(events) != 7 {
+		return fmt.Errorf("ddd: got %d events, want 7", len(events))
+	}
+	return nil
+}
+
+func eq(ok bool, msg string) error {
+	if ok {
+		return nil
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,137 @@
+// Package app - прикладной слой контекста «Выдача»: сценарий загружает агрегаты из
+// репозиториев, поручает решение домену и сохраняет результат в одной транзакции.
+// Правил выдачи здесь нет - только порядок шагов.
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ddd/internal/lending"
+)
+
+// Transactor выполняет fn атомарно: сохраняются либо все агрегаты сценария, либо ни один.
+type Transactor interface {
+	Atomically(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Publisher получает доменные события после того, как транзакция зафиксирована.
+type Publisher interface {
+	Publish(ctx context.Context, events ...lending.Event)
+}
+
+type Service struct {
+	Copies    lending.CopyRepository
+	Members   lending.MemberRepository
+	Tx        Transactor
+	Lending   lending.Lending
+	Now       func() time.Time
+	NewLoanID func() lending.LoanID
+	// Events, если задан, получает события агрегатов.
+	Events Publisher
+}
+
+// Borrow выдаёт читателю любой свободный экземпляр издания isbn.
+func (s *Service) Borrow(ctx context.Context, member lending.MemberID, isbn string) (lending.Loan, error) {
+	code, err := lending.ParseISBN(isbn)
+	if err != nil {
+		return lending.Loan{}, err
+	}
+	var loan lending.Loan
+	var events []lending.Event
+	err = s.Tx.Atomically(ctx, func(ctx context.Context) error {
+		m, err := s.Members.Member(ctx, member)
+		if err != nil {
+			return err
+		}
+		copies, err := s.Copies.CopiesOf(ctx, code)
+		if err != nil {
+			return err
+		}
+		c, err := lending.FirstAvailable(copies)
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, code)
+		}
+		if loan, err = s.Lending.Lend(m, c, s.NewLoanID(), s.Now()); err != nil {
+			return err
+		}
+		events, err = s.save(ctx, m, c)
+		return err
+	})
+	if err != nil {
+		return lending.Loan{}, err
+	}
+	s.publish(ctx, events)
+	return loan, nil
+}
+
+// Return принимает экземпляр и возвращает начисленные пени.
+func (s *Service) Return(ctx context.Context, copyID lending.CopyID) (lending.Money, error) {
+	var fine lending.Money
+	var events []lending.Event
+	err := s.Tx.Atomically(ctx, func(ctx context.Context) error {
+		c, err := s.Copies.Copy(ctx, copyID)
+		if err != nil {
+			return err
+		}
+		open, ok := c.Loan()
+		if !ok {
+			return fmt.Errorf("%w: %s", lending.ErrNotOnLoan, copyID)
+		}
+		m, err := s.Members.Member(ctx, open.Member)
+		if err != nil {
+			return err
+		}
+		if fine, err = s.Lending.Return(m, c, s.Now()); err != nil {
+			return err
+		}
+		events, err = s.save(ctx, m, c)
+		return err
+	})
+	if err != nil {
+		return lending.Money{}, err
+	}
+	s.publish(ctx, events)
+	return fine, nil
+}
+
+func (s *Service) PayFine(ctx context.Context, member lending.MemberID, amount lending.Money) error {
+	var events []lending.Event
+	err := s.Tx.Atomically(ctx, func(ctx context.Context) error {
+		m, err := s.Members.Member(ctx, member)
+		if err != nil {
+			return err
+		}
+		if err := m.PayFine(amount); err != nil {
+			return err
+		}
+		if err := s.Members.SaveMember(ctx, m); err != nil {
+			return err
+		}
+		events = m.PullEvents()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.publish(ctx, events)
+	return nil
+}
+
+// save сохраняет оба агрегата сценария и забирает их события.
+func (s *Service) save(ctx context.Context, m *lending.Member, c *lending.Copy) ([]lending.Event, error) {
+	if err := s.Copies.SaveCopy(ctx, c); err != nil {
+		return nil, err
+	}
+	if err := s.Members.SaveMember(ctx, m); err != nil {
+		return nil, err
+	}
+	return append(c.PullEvents(), m.PullEvents()...), nil
+}
+
+func (s *Service) publish(ctx context.Context, events []lending.Event) {
+	if s.Events != nil && len(events) > 0 {
+		s.Events.Publish(ctx, events...)
+	}
+}
@@ -0,0 +1,128 @@
+package lending
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAlreadyLoaned = errors.New("lending: copy is already on loan")
+	ErrNotLendable   = errors.New("lending: reference copies are not lent")
+	ErrNotOnLoan     = errors.New("lending: copy is not on loan")
+)
+
+type (
+	CopyID   string
+	MemberID string
+	LoanID   string
+)
+
+// Loan - сущность внутри агрегата Copy: открытая выдача экземпляра.
+// Снаружи её видно только как значение, изменить выдачу можно лишь через Copy.
+type Loan struct {
+	ID       LoanID
+	Copy     CopyID
+	Member   MemberID
+	LoanedAt time.Time
+	DueAt    time.Time
+}
+
+// Copy - агрегат «экземпляр книги». Инвариант: у экземпляра не больше одной открытой
+// выдачи. Поля закрыты, поэтому обойти Lend и Return нельзя.
+type Copy struct {
+	recorder
+	id        CopyID
+	isbn      ISBN
+	reference bool
+	loan      *Loan
+}
+
+// NewCopy регистрирует экземпляр; справочный (reference) экземпляр не выдаётся на дом.
+func NewCopy(id CopyID, isbn ISBN, reference bool) (*Copy, error) {
+	if strings.TrimSpace(string(id)) == "" {
+		return nil, fmt.Errorf("lending: copy id is required")
+	}
+	if isbn.IsZero() {
+		return nil, fmt.Errorf("%w: copy %s has no ISBN", ErrInvalidISBN, id)
+	}
+	return &Copy{id: id, isbn: isbn, reference: reference}, nil
+}
+
+func (c *Copy) ID() CopyID      { return c.id }
+func (c *Copy) ISBN() ISBN      { return c.isbn }
+func (c *Copy) Reference() bool { return c.reference }
+
+// Available - экземпляр можно выдать прямо сейчас.
+func (c *Copy) Available() bool { return !c.reference && c.loan == nil }
+
+// Loan - открытая выдача экземпляра, если она есть.
+func (c *Copy) Loan() (Loan, bool) {
+	if c.loan == nil {
+		return Loan{}, false
+	}
+	return *c.loan, true
+}
+
+// lend открывает выдачу; проверки читателя делает доменная служба Lending.
+func (c *Copy) lend(id LoanID, member MemberID, now time.Time, period time.Duration) (Loan, error) {
+	if c.reference {
+		return Loan{}, fmt.Errorf("%w: %s", ErrNotLendable, c.id)
+	}
+	if c.loan != nil {
+		return Loan{}, fmt.Errorf("%w: %s is with %s", ErrAlreadyLoaned, c.id, c.loan.Member)
+	}
+	l := Loan{ID: id, Copy: c.id, Member: member, LoanedAt: now, DueAt: now.Add(period)}
+	c.loan = &l
+	c.record(CopyLent{Copy: c.id, Member: member, Loan: id, DueAt: l.DueAt})
+	return l, nil
+}
+
+// giveBack закрывает открытую выдачу и возвращает её.
+func (c *Copy) giveBack(now time.Time) (Loan, error) {
+	if c.loan == nil {
+		return Loan{}, fmt.Errorf("%w: %s", ErrNotOnLoan, c.id)
+	}
+	l := *c.loan
+	c.loan = nil
+	c.record(CopyReturned{Copy: c.id, Member: l.Member, Loan: l.ID, Overdue: now.After(l.DueAt)})
+	return l, nil
+}
+
+// CopySnapshot - состояние агрегата для репозитория. Домен отдаёт и принимает его
+// целиком, так что хранилище не может собрать экземпляр в обход инвариантов.
+type CopySnapshot struct {
+	ID        CopyID
+	ISBN      string
+	Reference bool
+	Loan      *Loan
+	Version   int
+}
+
+func (c *Copy) Snapshot() CopySnapshot {
+	s := CopySnapshot{ID: c.id, ISBN: c.isbn.String(), Reference: c.reference, Version: c.version}
+	if c.loan != nil {
+		l := *c.loan
+		s.Loan = &l
+	}
+	return s
+}
+
+// RestoreCopy восстанавливает агрегат из снимка, заново проверяя ISBN.
+func RestoreCopy(s CopySnapshot) (*Copy, error) {
+	isbn, err := ParseISBN(s.ISBN)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewCopy(s.ID, isbn, s.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if s.Loan != nil {
+		l := *s.Loan
+		c.loan = &l
+	}
+	c.version = s.Version
+	return c, nil
+}
@@ -0,0 +1,58 @@
+package lending
+
+import "time"
+
+// Event - доменное событие: агрегат записывает его при изменении, а прикладной слой
+// забирает через PullEvents после сохранения.
+type Event interface {
+	EventName() string
+}
+
+type CopyLent struct {
+	Copy   CopyID
+	Member MemberID
+	Loan   LoanID
+	DueAt  time.Time
+}
+
+type CopyReturned struct {
+	Copy    CopyID
+	Member  MemberID
+	Loan    LoanID
+	Overdue bool
+}
+
+type FineCharged struct {
+	Member MemberID
+	Amount Money
+	Total  Money
+}
+
+type FinePaid struct {
+	Member MemberID
+	Amount Money
+	Left   Money
+}
+
+func (CopyLent) EventName() string     { return "copy_lent" }
+func (CopyReturned) EventName() string { return "copy_returned" }
+func (FineCharged) EventName() string  { return "fine_charged" }
+func (FinePaid) EventName() string     { return "fine_paid" }
+
+// recorder - общая часть агрегатов: версия для репозитория и ещё не забранные события.
+type recorder struct {
+	version int
+	events  []Event
+}
+
+func (r *recorder) record(e Event) { r.events = append(r.events, e) }
+
+// Version - версия, с которой агрегат загружен; у нового агрегата 0.
+func (r *recorder) Version() int { return r.version }
+
+// PullEvents возвращает накопленные события и очищает их.
+func (r *recorder) PullEvents() []Event {
+	out := r.events
+	r.events = nil
+	return out
+}
@@ -0,0 +1,79 @@
+package lending
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidISBN = errors.New("lending: invalid ISBN")
+
+// ISBN - объект-значение: создаётся только через ParseISBN и потому всегда корректен.
+// ISBN-10 приводится к ISBN-13, так что одна книга - одно значение.
+type ISBN struct {
+	digits string
+}
+
+// ParseISBN принимает ISBN-10 или ISBN-13 с дефисами и пробелами и проверяет контрольную цифру.
+func ParseISBN(s string) (ISBN, error) {
+	raw := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(s))
+	switch len(raw) {
+	case 10:
+		if !validISBN10(raw) {
+			return ISBN{}, fmt.Errorf("%w: %q", ErrInvalidISBN, s)
+		}
+		body := "978" + raw[:9]
+		return ISBN{digits: body + string(rune('0'+check13(body)))}, nil
+	case 13:
+		if !allDigits(raw) || int(raw[12]-'0') != check13(raw[:12]) {
+			return ISBN{}, fmt.Errorf("%w: %q", ErrInvalidISBN, s)
+		}
+		return ISBN{digits: raw}, nil
+	}
+	return ISBN{}, fmt.Errorf("%w: %q", ErrInvalidISBN, s)
+}
+
+func (i ISBN) String() string { return i.digits }
+
+func (i ISBN) IsZero() bool { return i.digits == "" }
+
+func validISBN10(s string) bool {
+	if !allDigits(s[:9]) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(s[i]-'0') * (10 - i)
+	}
+	switch c := s[9]; {
+	case c == 'X':
+		sum += 10
+	case c >= '0' && c <= '9':
+		sum += int(c - '0')
+	default:
+		return false
+	}
+	return sum%11 == 0
+}
+
+// check13 - контрольная цифра ISBN-13 для первых двенадцати цифр.
+func check13(body string) int {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(body[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+func allDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package lending
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrLoanLimit     = errors.New("lending: member reached the loan limit")
+	ErrMemberBlocked = errors.New("lending: member is blocked by unpaid fines")
+	ErrNotBorrower   = errors.New("lending: copy was lent to another member")
+)
+
+// Member - агрегат «читатель»: открытые выдачи и долг по пени. Инварианты:
+// открытых выдач не больше лимита, долг в валюте библиотеки и не отрицателен.
+type Member struct {
+	recorder
+	id       MemberID
+	name     string
+	maxLoans int
+	open     map[CopyID]LoanID
+	fines    Money
+}
+
+func NewMember(id MemberID, name string, maxLoans int, currency string) (*Member, error) {
+	if strings.TrimSpace(string(id)) == "" {
+		return nil, fmt.Errorf("lending: member id is required")
+	}
+	if maxLoans <= 0 {
+		return nil, fmt.Errorf("lending: member %s needs a positive loan limit", id)
+	}
+	fines, err := NewMoney(0, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &Member{id: id, name: name, maxLoans: maxLoans, open: map[CopyID]LoanID{}, fines: fines}, nil
+}
+
+func (m *Member) ID() MemberID   { return m.id }
+func (m *Member) Name() string   { return m.name }
+func (m *Member) Fines() Money   { return m.fines }
+func (m *Member) OpenLoans() int { return len(m.open) }
+
+// canBorrow - правила читателя, которые проверяются до изменения экземпляра.
+func (m *Member) canBorrow(p Policy) error {
+	if len(m.open) >= m.maxLoans {
+		return fmt.Errorf("%w: %s has %d of %d", ErrLoanLimit, m.id, len(m.open), m.maxLoans)
+	}
+	if !p.BlockAt.IsZero() && !m.fines.Less(p.BlockAt) {
+		return fmt.Errorf("%w: %s owes %s", ErrMemberBlocked, m.id, m.fines)
+	}
+	return nil
+}
+
+func (m *Member) borrowed(l Loan) {
+	m.open[l.Copy] = l.ID
+}
+
+func (m *Member) returned(l Loan, fine Money) error {
+	delete(m.open, l.Copy)
+	if fine.IsZero() {
+		return nil
+	}
+	total, err := m.fines.Add(fine)
+	if err != nil {
+		return err
+	}
+	m.fines = total
+	m.record(FineCharged{Member: m.id, Amount: fine, Total: total})
+	return nil
+}
+
+// PayFine гасит долг целиком или частично; заплатить больше долга нельзя.
+func (m *Member) PayFine(amount Money) error {
+	left, err := m.fines.Sub(amount)
+	if err != nil {
+		return fmt.Errorf("lending: %s pays %s: %w", m.id, amount, err)
+	}
+	m.fines = left
+	m.record(FinePaid{Member: m.id, Amount: amount, Left: left})
+	return nil
+}
+
+// MemberSnapshot - состояние агрегата для репозитория; Fines - в минимальных единицах.
+type MemberSnapshot struct {
+	ID       MemberID
+	Name     string
+	MaxLoans int
+	Open     map[CopyID]LoanID
+	Fines    int64
+	Currency string
+	Version  int
+}
+
+func (m *Member) Snapshot() MemberSnapshot {
+	open := make(map[CopyID]LoanID, len(m.open))
+	for c, l := range m.open {
+		open[c] = l
+	}
+	return MemberSnapshot{ID: m.id, Name: m.name, MaxLoans: m.maxLoans, Open: open,
+		Fines: m.fines.Minor(), Currency: m.fines.Currency(), Version: m.version}
+}
+
+func RestoreMember(s MemberSnapshot) (*Member, error) {
+	m, err := NewMember(s.ID, s.Name, s.MaxLoans, s.Currency)
+	if err != nil {
+		return nil, err
+	}
+	if m.fines, err = NewMoney(s.Fines, s.Currency); err != nil {
+		return nil, err
+	}
+	for c, l := range s.Open {
+		m.open[c] = l
+	}
+	m.version = s.Version
+	return m, nil
+}
@@ -0,0 +1,118 @@
+// Package memrepo - инфраструктура контекста «Выдача»: репозитории домена в памяти.
+// Агрегаты хранятся снимками, так что вызывающий не может изменить сохранённое
+// состояние в обход Save, а Save сверяет версию.
+package memrepo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"ddd/internal/lending"
+)
+
+type Store struct {
+	// tx сериализует транзакции; mu защищает сами карты.
+	tx      sync.Mutex
+	mu      sync.RWMutex
+	copies  map[lending.CopyID]lending.CopySnapshot
+	members map[lending.MemberID]lending.MemberSnapshot
+}
+
+var (
+	_ lending.CopyRepository   = (*Store)(nil)
+	_ lending.MemberRepository = (*Store)(nil)
+)
+
+func New() *Store {
+	return &Store{copies: map[lending.CopyID]lending.CopySnapshot{}, members: map[lending.MemberID]lending.MemberSnapshot{}}
+}
+
+// Atomically откатывает все сохранения fn, если она вернула ошибку.
+func (s *Store) Atomically(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.tx.Lock()
+	defer s.tx.Unlock()
+	s.mu.RLock()
+	copies := make(map[lending.CopyID]lending.CopySnapshot, len(s.copies))
+	for k, v := range s.copies {
+		copies[k] = v
+	}
+	members := make(map[lending.MemberID]lending.MemberSnapshot, len(s.members))
+	for k, v := range s.members {
+		members[k] = v
+	}
+	s.mu.RUnlock()
+	if err := fn(ctx); err != nil {
+		s.mu.Lock()
+		s.copies, s.members = copies, members
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *Store) Copy(ctx context.Context, id lending.CopyID) (*lending.Copy, error) {
+	s.mu.RLock()
+	snap, ok := s.copies[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: copy %s", lending.ErrNotFound, id)
+	}
+	return lending.RestoreCopy(snap)
+}
+
+func (s *Store) CopiesOf(ctx context.Context, isbn lending.ISBN) ([]*lending.Copy, error) {
+	s.mu.RLock()
+	var snaps []lending.CopySnapshot
+	for _, snap := range s.copies {
+		if snap.ISBN == isbn.String() {
+			snaps = append(snaps, snap)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].ID < snaps[j].ID })
+	out := make([]*lending.Copy, 0, len(snaps))
+	for _, snap := range snaps {
+		c, err := lending.RestoreCopy(snap)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func (s *Store) SaveCopy(ctx context.Context, c *lending.Copy) error {
+	snap := c.Snapshot()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stored, ok := s.copies[snap.ID]; (ok && stored.Version != snap.Version) || (!ok && snap.Version != 0) {
+		return fmt.Errorf("%w: copy %s", lending.ErrConflict, snap.ID)
+	}
+	snap.Version++
+	s.copies[snap.ID] = snap
+	return nil
+}
+
+func (s *Store) Member(ctx context.Context, id lending.MemberID) (*lending.Member, error) {
+	s.mu.RLock()
+	snap, ok := s.members[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: member %s", lending.ErrNotFound, id)
+	}
+	return lending.RestoreMember(snap)
+}
+
+func (s *Store) SaveMember(ctx context.Context, m *lending.Member) error {
+	snap := m.Snapshot()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stored, ok := s.members[snap.ID]; (ok && stored.Version != snap.Version) || (!ok && snap.Version != 0) {
+		return fmt.Errorf("%w: member %s", lending.ErrConflict, snap.ID)
+	}
+	snap.Version++
+	s.members[snap.ID] = snap
+	return nil
+}
@@ -0,0 +1,73 @@
+package lending
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidMoney     = errors.New("lending: invalid money")
+	ErrCurrencyMismatch = errors.New("lending: currency mismatch")
+)
+
+// Money - объект-значение: сумма в минимальных единицах (копейках, центах) и валюта.
+// Значение неизменяемо, равенство - по полям, поэтому Money сравнивают через ==.
+type Money struct {
+	minor    int64
+	currency string
+}
+
+// NewMoney проверяет валюту (три латинские буквы) и сумму (не отрицательная).
+func NewMoney(minor int64, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return Money{}, fmt.Errorf("%w: currency %q", ErrInvalidMoney, currency)
+	}
+	if minor < 0 {
+		return Money{}, fmt.Errorf("%w: negative amount %d", ErrInvalidMoney, minor)
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// Zero - нулевая сумма в валюте currency; валюта должна быть корректной.
+func Zero(currency string) Money { return mustMoney(0, currency) }
+
+func mustMoney(minor int64, currency string) Money {
+	m, err := NewMoney(minor, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m Money) Minor() int64      { return m.minor }
+func (m Money) Currency() string  { return m.currency }
+func (m Money) IsZero() bool      { return m.minor == 0 }
+func (m Money) Less(o Money) bool { return m.currency == o.currency && m.minor < o.minor }
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.currency != o.currency {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+	return Money{minor: m.minor + o.minor, currency: m.currency}, nil
+}
+
+// Sub не уходит ниже нуля: долг нельзя переплатить.
+func (m Money) Sub(o Money) (Money, error) {
+	if m.currency != o.currency {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+	if o.minor > m.minor {
+		return Money{}, fmt.Errorf("%w: %s is more than %s", ErrInvalidMoney, o, m)
+	}
+	return Money{minor: m.minor - o.minor, currency: m.currency}, nil
+}
+
+func (m Money) Times(n int) Money {
+	return Money{minor: m.minor * int64(n), currency: m.currency}
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%d.%02d %s", m.minor/100, m.minor%100, m.currency)
+}
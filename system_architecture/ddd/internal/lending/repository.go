@@ -0,0 +1,32 @@
+// Package lending - доменный слой ограниченного контекста «Выдача»: объекты-значения
+// (ISBN, Money), агрегаты Copy и Member со своими инвариантами, доменная служба Lending
+// для правил, которые затрагивают оба агрегата, и интерфейсы репозиториев.
+//
+// Интерфейсы репозиториев принадлежат домену: он говорит, что ему нужно, а слой
+// инфраструктуры (memrepo) это реализует. Пакет не импортирует ни одного соседа.
+package lending
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrNotFound = errors.New("lending: not found")
+	// ErrConflict - агрегат изменили с тех пор, как его загрузили; его нужно загрузить заново.
+	ErrConflict = errors.New("lending: concurrent modification")
+)
+
+// Репозитории хранят агрегаты целиком и сверяют версию: Save отвергает агрегат,
+// загруженный до чужого сохранения. После Save экземпляр агрегата больше не используется.
+type CopyRepository interface {
+	Copy(ctx context.Context, id CopyID) (*Copy, error)
+	// CopiesOf - все экземпляры издания.
+	CopiesOf(ctx context.Context, isbn ISBN) ([]*Copy, error)
+	SaveCopy(ctx context.Context, c *Copy) error
+}
+
+type MemberRepository interface {
+	Member(ctx context.Context, id MemberID) (*Member, error)
+	SaveMember(ctx context.Context, m *Member) error
+}
@@ -0,0 +1,89 @@
+package lending
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNoCopyAvailable = errors.New("lending: no copy available")
+
+// Policy - правила выдачи библиотеки.
+type Policy struct {
+	LoanPeriod time.Duration
+	// FinePerDay - пени за каждый начатый день просрочки.
+	FinePerDay Money
+	// BlockAt - долг, начиная с которого читателю не выдают книги; нулевой - без блокировки.
+	BlockAt Money
+}
+
+// DefaultPolicy - две недели, 10 единиц в день, блокировка с 50.
+func DefaultPolicy(currency string) Policy {
+	return Policy{
+		LoanPeriod: 14 * 24 * time.Hour,
+		FinePerDay: mustMoney(1000, currency),
+		BlockAt:    mustMoney(5000, currency),
+	}
+}
+
+// Lending - доменная служба: правила, которые не принадлежат ни экземпляру, ни читателю,
+// потому что затрагивают оба агрегата сразу. Хранилищ она не знает - только агрегаты.
+type Lending struct {
+	Policy Policy
+}
+
+// Lend выдаёт экземпляр читателю. Правила читателя проверяются до изменения экземпляра,
+// поэтому при любой ошибке оба агрегата остаются как были.
+func (s Lending) Lend(m *Member, c *Copy, id LoanID, now time.Time) (Loan, error) {
+	if err := m.canBorrow(s.Policy); err != nil {
+		return Loan{}, err
+	}
+	l, err := c.lend(id, m.id, now, s.Policy.LoanPeriod)
+	if err != nil {
+		return Loan{}, err
+	}
+	m.borrowed(l)
+	return l, nil
+}
+
+// Return принимает экземпляр у читателя и начисляет ему пени за просрочку.
+func (s Lending) Return(m *Member, c *Copy, now time.Time) (Money, error) {
+	open, ok := c.Loan()
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s", ErrNotOnLoan, c.id)
+	}
+	if open.Member != m.id {
+		return Money{}, fmt.Errorf("%w: %s holds %s", ErrNotBorrower, open.Member, c.id)
+	}
+	fine := s.Fine(open, now)
+	if _, err := c.giveBack(now); err != nil {
+		return Money{}, err
+	}
+	if err := m.returned(open, fine); err != nil {
+		return Money{}, err
+	}
+	return fine, nil
+}
+
+// Fine - пени на момент now: каждый начатый день после срока.
+func (s Lending) Fine(l Loan, now time.Time) Money {
+	if !now.After(l.DueAt) {
+		return Zero(s.Policy.FinePerDay.Currency())
+	}
+	late := now.Sub(l.DueAt)
+	days := int(late / (24 * time.Hour))
+	if late%(24*time.Hour) != 0 {
+		days++
+	}
+	return s.Policy.FinePerDay.Times(days)
+}
+
+// FirstAvailable выбирает экземпляр издания, который можно выдать.
+func FirstAvailable(copies []*Copy) (*Copy, error) {
+	for _, c := range copies {
+		if c.Available() {
+			return c, nil
+		}
+	}
+	return nil, ErrNoCopyAvailable
+}
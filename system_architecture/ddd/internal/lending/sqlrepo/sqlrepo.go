@@ -0,0 +1,239 @@
+// Package sqlrepo - инфраструктура контекста «Выдача» поверх database/sql (диалект
+// PostgreSQL): репозитории домена и единица работы uow, в которой сценарий сохраняет
+// экземпляр и читателя одной транзакцией. Версия агрегата проверяется в UPDATE ... WHERE
+// version, так что опоздавший Save получает lending.ErrConflict.
+package sqlrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"solid/uow"
+
+	"ddd/internal/lending"
+	"ddd/internal/lending/app"
+)
+
+// Schema создаёт таблицы, с которыми работает Store.
+const Schema = `
+CREATE TABLE IF NOT EXISTS lending_copies (
+	id          TEXT PRIMARY KEY,
+	isbn        TEXT NOT NULL,
+	reference   BOOLEAN NOT NULL,
+	loan_id     TEXT,
+	loan_member TEXT,
+	loaned_at   TIMESTAMPTZ,
+	due_at      TIMESTAMPTZ,
+	version     INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS lending_copies_isbn ON lending_copies (isbn);
+CREATE TABLE IF NOT EXISTS lending_members (
+	id        TEXT PRIMARY KEY,
+	name      TEXT NOT NULL,
+	max_loans INTEGER NOT NULL,
+	fines     BIGINT NOT NULL,
+	currency  TEXT NOT NULL,
+	version   INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS lending_member_loans (
+	member_id TEXT NOT NULL REFERENCES lending_members (id),
+	copy_id   TEXT NOT NULL,
+	loan_id   TEXT NOT NULL,
+	PRIMARY KEY (member_id, copy_id)
+);
+`
+
+type Store struct {
+	db  *sql.DB
+	uow *uow.UnitOfWork
+}
+
+var (
+	_ lending.CopyRepository   = (*Store)(nil)
+	_ lending.MemberRepository = (*Store)(nil)
+	_ app.Transactor           = (*Store)(nil)
+)
+
+func New(db *sql.DB) *Store {
+	return &Store{db: db, uow: uow.New(db, uow.Config{})}
+}
+
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, Schema)
+	return err
+}
+
+// Reset удаляет все экземпляры и читателей - для проверочных прогонов.
+func (s *Store) Reset(ctx context.Context) error {
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		q := uow.From(ctx, s.db)
+		for _, table := range []string{"lending_member_loans", "lending_members", "lending_copies"} {
+			if _, err := q.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+				return fmt.Errorf("sqlrepo: reset %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Atomically - единица работы сценария; после взаимной блокировки fn повторяется.
+func (s *Store) Atomically(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.uow.Do(ctx, fn)
+}
+
+const copyColumns = `id, isbn, reference, loan_id, loan_member, loaned_at, due_at, version`
+
+func (s *Store) Copy(ctx context.Context, id lending.CopyID) (*lending.Copy, error) {
+	row := uow.From(ctx, s.db).QueryRowContext(ctx, `SELECT `+copyColumns+` FROM lending_copies WHERE id = $1`, id)
+	c, err := scanCopy(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: copy %s", lending.ErrNotFound, id)
+	}
+	return c, err
+}
+
+func (s *Store) CopiesOf(ctx context.Context, isbn lending.ISBN) ([]*lending.Copy, error) {
+	rows, err := uow.From(ctx, s.db).QueryContext(ctx,
+		`SELECT `+copyColumns+` FROM lending_copies WHERE isbn = $1 ORDER BY id`, isbn.String())
+	if err != nil {
+		return nil, fmt.Errorf("sqlrepo: copies of %s: %w", isbn, err)
+	}
+	defer rows.Close()
+	var out []*lending.Copy
+	for rows.Next() {
+		c, err := scanCopy(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func scanCopy(row interface{ Scan(...any) error }) (*lending.Copy, error) {
+	var (
+		snap            lending.CopySnapshot
+		loanID, member  sql.NullString
+		loanedAt, dueAt sql.NullTime
+	)
+	if err := row.Scan(&snap.ID, &snap.ISBN, &snap.Reference, &loanID, &member, &loanedAt, &dueAt, &snap.Version); err != nil {
+		return nil, err
+	}
+	if loanID.Valid {
+		snap.Loan = &lending.Loan{
+			ID: lending.LoanID(loanID.String), Copy: snap.ID, Member: lending.MemberID(member.String),
+			LoanedAt: loanedAt.Time.UTC(), DueAt: dueAt.Time.UTC(),
+		}
+	}
+	return lending.RestoreCopy(snap)
+}
+
+func (s *Store) SaveCopy(ctx context.Context, c *lending.Copy) error {
+	snap := c.Snapshot()
+	var (
+		loanID, member  sql.NullString
+		loanedAt, dueAt sql.NullTime
+	)
+	if l := snap.Loan; l != nil {
+		loanID = sql.NullString{String: string(l.ID), Valid: true}
+		member = sql.NullString{String: string(l.Member), Valid: true}
+		loanedAt, dueAt = sql.NullTime{Time: l.LoanedAt, Valid: true}, sql.NullTime{Time: l.DueAt, Valid: true}
+	}
+	q := uow.From(ctx, s.db)
+	var res sql.Result
+	var err error
+	if snap.Version == 0 {
+		res, err = q.ExecContext(ctx, `INSERT INTO lending_copies (`+copyColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, 1) ON CONFLICT (id) DO NOTHING`,
+			snap.ID, snap.ISBN, snap.Reference, loanID, member, loanedAt, dueAt)
+	} else {
+		res, err = q.ExecContext(ctx, `UPDATE lending_copies
+			SET loan_id = $2, loan_member = $3, loaned_at = $4, due_at = $5, version = version + 1
+			WHERE id = $1 AND version = $6`,
+			snap.ID, loanID, member, loanedAt, dueAt, snap.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("sqlrepo: save copy %s: %w", snap.ID, err)
+	}
+	return checkVersion(res, "copy", string(snap.ID))
+}
+
+func (s *Store) Member(ctx context.Context, id lending.MemberID) (*lending.Member, error) {
+	q := uow.From(ctx, s.db)
+	snap := lending.MemberSnapshot{Open: map[lending.CopyID]lending.LoanID{}}
+	err := q.QueryRowContext(ctx, `SELECT id, name, max_loans, fines, currency, version FROM lending_members WHERE id = $1`, id).
+		Scan(&snap.ID, &snap.Name, &snap.MaxLoans, &snap.Fines, &snap.Currency, &snap.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: member %s", lending.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sqlrepo: member %s: %w", id, err)
+	}
+	rows, err := q.QueryContext(ctx, `SELECT copy_id, loan_id FROM lending_member_loans WHERE member_id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("sqlrepo: loans of %s: %w", id, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c lending.CopyID
+		var l lending.LoanID
+		if err := rows.Scan(&c, &l); err != nil {
+			return nil, fmt.Errorf("sqlrepo: loans of %s: %w", id, err)
+		}
+		snap.Open[c] = l
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return lending.RestoreMember(snap)
+}
+
+// SaveMember пишет строку читателя и его открытые выдачи; вне сценария - своей транзакцией.
+func (s *Store) SaveMember(ctx context.Context, m *lending.Member) error {
+	snap := m.Snapshot()
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		q := uow.From(ctx, s.db)
+		var res sql.Result
+		var err error
+		if snap.Version == 0 {
+			res, err = q.ExecContext(ctx, `INSERT INTO lending_members (id, name, max_loans, fines, currency, version)
+				VALUES ($1, $2, $3, $4, $5, 1) ON CONFLICT (id) DO NOTHING`,
+				snap.ID, snap.Name, snap.MaxLoans, snap.Fines, snap.Currency)
+		} else {
+			res, err = q.ExecContext(ctx, `UPDATE lending_members
+				SET name = $2, max_loans = $3, fines = $4, currency = $5, version = version + 1
+				WHERE id = $1 AND version = $6`,
+				snap.ID, snap.Name, snap.MaxLoans, snap.Fines, snap.Currency, snap.Version)
+		}
+		if err != nil {
+			return fmt.Errorf("sqlrepo: save member %s: %w", snap.ID, err)
+		}
+		if err := checkVersion(res, "member", string(snap.ID)); err != nil {
+			return err
+		}
+		if _, err := q.ExecContext(ctx, `DELETE FROM lending_member_loans WHERE member_id = $1`, snap.ID); err != nil {
+			return fmt.Errorf("sqlrepo: save loans of %s: %w", snap.ID, err)
+		}
+		for c, l := range snap.Open {
+			if _, err := q.ExecContext(ctx, `INSERT INTO lending_member_loans (member_id, copy_id, loan_id) VALUES ($1, $2, $3)`,
+				snap.ID, c, l); err != nil {
+				return fmt.Errorf("sqlrepo: save loans of %s: %w", snap.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// checkVersion: ни одной затронутой строки - значит, версия в базе уже другая.
+func checkVersion(res sql.Result, kind, id string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("sqlrepo: save %s %s: %w", kind, id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s %s", lending.ErrConflict, kind, id)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Команда eventsourcing проигрывает жизнь одной выдачи на хранилище событий в памяти:
+// команды, восстановление из потока и конфликт двух одновременных изменений.
+// Postgres-хранилище устроено так же; для него нужен только *sql.DB с драйвером.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"eventsourcing/internal/aggregate"
+	"eventsourcing/internal/eventstore"
+	"eventsourcing/internal/loan"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx := context.Background()
+	store := eventstore.NewMemory()
+	repo := loan.Repository(store)
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	l, err := loan.Open("loan-1", "dune", "ann", now)
+	if err != nil {
+		return err
+	}
+	if err := repo.Save(ctx, l); err != nil {
+		return err
+	}
+	for i := 0; i < loan.MaxExtensions; i++ {
+		if _, err := loan.Handle(ctx, repo, "loan-1", func(l *loan.Loan) error { return l.Extend(now) }); err != nil {
+			return err
+		}
+	}
+	if _, err := loan.Handle(ctx, repo, "loan-1", func(l *loan.Loan) error { return l.Extend(now) }); !errors.Is(err, loan.ErrTooManyExtend) {
+		return fmt.Errorf("eventsourcing: third extension: got %v, want %v", err, loan.ErrTooManyExtend)
+	}
+
+	// Две копии одного агрегата загружены одновременно; кто сохранил вторым, получает конфликт.
+	a, err := repo.Load(ctx, "loan-1")
+	if err != nil {
+		return err
+	}
+	b, err := repo.Load(ctx, "loan-1")
+	if err != nil {
+		return err
+	}
+	a.Return(now.Add(20 * 24 * time.Hour))
+	b.Return(now.Add(21 * 24 * time.Hour))
+	if err := repo.Save(ctx, a); err != nil {
+		return err
+	}
+	err = repo.Save(ctx, b)
+	if !errors.Is(err, eventstore.ErrConcurrency) {
+		return fmt.Errorf("eventsourcing: concurrent save: got %v, want %v", err, eventstore.ErrConcurrency)
+	}
+	fmt.Println("second save:", err)
+
+	got, err := repo.Load(ctx, "loan-1")
+	if err != nil {
+		return err
+	}
+	want := now.Add(3 * loan.Period)
+	if !got.Closed() || got.Extensions != 2 || !got.Due.Equal(want) || got.Version() != 4 {
+		return fmt.Errorf("eventsourcing: rehydrated %+v", got)
+	}
+	recs, err := store.Load(ctx, "loan-1", 0)
+	if err != nil {
+		return err
+	}
+	for _, r := range recs {
+		fmt.Printf("v%d %-14s %s\n", r.Version, r.Type, r.Data)
+	}
+	fmt.Printf("loan-1: %s for %s, due %s, extended %d time(s), returned %s\n",
+		got.BookID, got.Borrower, got.Due.Format(time.DateOnly), got.Extensions, got.ReturnedAt.Format(time.DateOnly))
+	if _, err := repo.Load(ctx, "loan-2"); !errors.Is(err, aggregate.ErrNotFound) {
+		return fmt.Errorf("eventsourcing: missing loan: got %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+module eventsourcing
+
+go 1.23
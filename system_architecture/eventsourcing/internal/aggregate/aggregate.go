@@ -0,0 +1,133 @@
+// Package aggregate - основа агрегатов на событиях. Состояние агрегата меняется только
+// в Apply; команда проверяет правила и записывает событие через Raise, а загрузка
+// из хранилища проигрывает прошлые события через тот же Apply.
+package aggregate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"eventsourcing/internal/eventstore"
+)
+
+var (
+	ErrNotFound    = errors.New("aggregate: not found")
+	ErrUnknownType = errors.New("aggregate: unknown event type")
+)
+
+// Event - доменное событие; EventType сохраняется рядом с JSON и выбирает тип при чтении.
+type Event interface {
+	EventType() string
+}
+
+// Root - агрегат: применяет события и встраивает Base, через которую получает base().
+type Root interface {
+	Apply(e Event)
+	base() *Base
+}
+
+// Base встраивается в агрегат и хранит id, версию и ещё не сохранённые события.
+type Base struct {
+	id      string
+	version int
+	changes []Event
+	root    Root
+}
+
+// Init связывает основу с агрегатом; вызывается из его конструктора.
+func (b *Base) Init(id string, root Root) {
+	b.id, b.root = id, root
+}
+
+func (b *Base) ID() string { return b.id }
+
+func (b *Base) base() *Base { return b }
+
+// Version - версия потока, из которой агрегат был загружен, плюс уже применённые изменения.
+func (b *Base) Version() int { return b.version + len(b.changes) }
+
+// Raise применяет новое событие и запоминает его для сохранения.
+func (b *Base) Raise(e Event) {
+	b.root.Apply(e)
+	b.changes = append(b.changes, e)
+}
+
+func (b *Base) Changes() []Event { return b.changes }
+
+// Codec декодирует события по сохранённому типу.
+type Codec map[string]func(data json.RawMessage) (Event, error)
+
+// Register добавляет тип события E в кодек. E - значение, EventType не должен зависеть от полей.
+func Register[E Event](c Codec) {
+	var zero E
+	c[zero.EventType()] = func(data json.RawMessage) (Event, error) {
+		var e E
+		err := json.Unmarshal(data, &e)
+		return e, err
+	}
+}
+
+func (c Codec) decode(r eventstore.Record) (Event, error) {
+	dec, ok := c[r.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownType, r.Type)
+	}
+	e, err := dec(r.Data)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate: decode %s v%d: %w", r.Type, r.Version, err)
+	}
+	return e, nil
+}
+
+// Repository загружает и сохраняет агрегаты одного вида.
+type Repository[A Root] struct {
+	Store eventstore.EventStore
+	Codec Codec
+	// New создаёт пустой агрегат с заданным id.
+	New func(id string) A
+}
+
+// Load восстанавливает агрегат, проигрывая его поток; пустой поток - ErrNotFound.
+func (r *Repository[A]) Load(ctx context.Context, id string) (A, error) {
+	a := r.New(id)
+	recs, err := r.Store.Load(ctx, id, 0)
+	if err != nil {
+		return a, err
+	}
+	if len(recs) == 0 {
+		return a, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	for _, rec := range recs {
+		e, err := r.Codec.decode(rec)
+		if err != nil {
+			return a, err
+		}
+		a.Apply(e)
+		a.base().version = rec.Version
+	}
+	return a, nil
+}
+
+// Save дописывает новые события с ожидаемой версией, из которой агрегат был загружен.
+func (r *Repository[A]) Save(ctx context.Context, a A) error {
+	b := a.base()
+	if len(b.changes) == 0 {
+		return nil
+	}
+	recs := make([]eventstore.Record, 0, len(b.changes))
+	for _, e := range b.changes {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("aggregate: encode %s: %w", e.EventType(), err)
+		}
+		recs = append(recs, eventstore.Record{Type: e.EventType(), Data: data})
+	}
+	v, err := r.Store.Append(ctx, b.id, b.version, recs)
+	if err != nil {
+		return err
+	}
+	b.version, b.changes = v, nil
+	return nil
+}
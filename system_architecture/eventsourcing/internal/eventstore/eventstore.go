@@ -0,0 +1,80 @@
+// Package eventstore - хранилище событий только на дописывание. Поток - история одного
+// агрегата, версия потока - число событий в нём. Дописать можно лишь к той версии,
+// которую вызывающий видел (оптимистичная блокировка), иначе ErrConcurrency.
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrConcurrency = errors.New("eventstore: stream was changed concurrently")
+	ErrInvalid     = errors.New("eventstore: invalid append")
+)
+
+// Record - сохранённое событие. Data - JSON, тип нужен для декодирования.
+type Record struct {
+	Stream     string          `json:"stream"`
+	Version    int             `json:"version"`
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data"`
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+type EventStore interface {
+	// Append дописывает события, если текущая версия потока равна expected (0 - новый поток).
+	// Версии и время присваивает хранилище; возвращается новая версия потока.
+	Append(ctx context.Context, stream string, expected int, events []Record) (int, error)
+	// Load возвращает события потока с версией больше after, по порядку.
+	Load(ctx context.Context, stream string, after int) ([]Record, error)
+}
+
+// Memory - хранилище в памяти для примеров и проверок.
+type Memory struct {
+	mu      sync.RWMutex
+	streams map[string][]Record
+	now     func() time.Time
+}
+
+var _ EventStore = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{streams: map[string][]Record{}, now: time.Now}
+}
+
+func (m *Memory) Append(ctx context.Context, stream string, expected int, events []Record) (int, error) {
+	if stream == "" || len(events) == 0 {
+		return 0, fmt.Errorf("%w: stream and events are required", ErrInvalid)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur := m.streams[stream]
+	if len(cur) != expected {
+		return len(cur), fmt.Errorf("%w: %q is at version %d, expected %d", ErrConcurrency, stream, len(cur), expected)
+	}
+	at := m.now()
+	for i, e := range events {
+		e.Stream, e.Version, e.RecordedAt = stream, expected+i+1, at
+		cur = append(cur, e)
+	}
+	m.streams[stream] = cur
+	return len(cur), nil
+}
+
+func (m *Memory) Load(ctx context.Context, stream string, after int) ([]Record, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cur := m.streams[stream]
+	if after >= len(cur) {
+		return nil, nil
+	}
+	if after < 0 {
+		after = 0
+	}
+	return append([]Record(nil), cur[after:]...), nil
+}
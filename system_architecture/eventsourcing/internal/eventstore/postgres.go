@@ -0,0 +1,102 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Schema создаёт таблицы Postgres. es_streams хранит текущую версию потока:
+// условный UPDATE по ней и есть проверка ожидаемой версии.
+const Schema = `
+CREATE TABLE IF NOT EXISTS es_streams (
+	id      TEXT PRIMARY KEY,
+	version INT  NOT NULL
+);
+CREATE TABLE IF NOT EXISTS es_events (
+	stream_id   TEXT        NOT NULL REFERENCES es_streams (id),
+	version     INT         NOT NULL,
+	type        TEXT        NOT NULL,
+	data        JSONB       NOT NULL,
+	recorded_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (stream_id, version)
+);
+`
+
+// Postgres - хранилище поверх database/sql; драйвер (например, pgx/stdlib) подключает вызывающий код.
+type Postgres struct {
+	db *sql.DB
+}
+
+var _ EventStore = (*Postgres)(nil)
+
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+func (p *Postgres) Migrate(ctx context.Context) error {
+	_, err := p.db.ExecContext(ctx, Schema)
+	return err
+}
+
+func (p *Postgres) Append(ctx context.Context, stream string, expected int, events []Record) (v int, err error) {
+	if stream == "" || len(events) == 0 {
+		return 0, fmt.Errorf("%w: stream and events are required", ErrInvalid)
+	}
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: begin: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	next := expected + len(events)
+	var res sql.Result
+	if expected == 0 {
+		res, err = tx.ExecContext(ctx, `INSERT INTO es_streams (id, version) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`, stream, next)
+	} else {
+		res, err = tx.ExecContext(ctx, `UPDATE es_streams SET version = $3 WHERE id = $1 AND version = $2`, stream, expected, next)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("eventstore: lock %q: %w", stream, err)
+	}
+	if n, rerr := res.RowsAffected(); rerr != nil || n != 1 {
+		return 0, fmt.Errorf("%w: %q, expected version %d", ErrConcurrency, stream, expected)
+	}
+	at := time.Now().UTC()
+	for i, e := range events {
+		if _, err = tx.ExecContext(ctx, `
+			INSERT INTO es_events (stream_id, version, type, data, recorded_at) VALUES ($1, $2, $3, $4, $5)`,
+			stream, expected+i+1, e.Type, []byte(e.Data), at); err != nil {
+			return 0, fmt.Errorf("eventstore: append %q: %w", stream, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("eventstore: commit %q: %w", stream, err)
+	}
+	return next, nil
+}
+
+func (p *Postgres) Load(ctx context.Context, stream string, after int) ([]Record, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT version, type, data, recorded_at FROM es_events
+		WHERE stream_id = $1 AND version > $2 ORDER BY version`, stream, after)
+	if err != nil {
+		return nil, fmt.Errorf("eventstore: load %q: %w", stream, err)
+	}
+	defer rows.Close()
+	var out []Record
+	for rows.Next() {
+		r := Record{Stream: stream}
+		var data []byte
+		if err := rows.Scan(&r.Version, &r.Type, &data, &r.RecordedAt); err != nil {
+			return nil, fmt.Errorf("eventstore: scan %q: %w", stream, err)
+		}
+		r.Data = data
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,134 @@
+// Package loan - агрегат выдачи на событиях: открыть, продлить (не больше MaxExtensions раз)
+// и вернуть. Текущее состояние нигде не хранится - его каждый раз собирают из потока.
+package loan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"eventsourcing/internal/aggregate"
+	"eventsourcing/internal/eventstore"
+)
+
+var (
+	ErrClosed        = errors.New("loan: loan is closed")
+	ErrOverdue       = errors.New("loan: overdue loan cannot be extended")
+	ErrTooManyExtend = errors.New("loan: extension limit reached")
+	ErrInvalid       = errors.New("loan: invalid loan")
+)
+
+const (
+	Period        = 14 * 24 * time.Hour
+	MaxExtensions = 2
+)
+
+type Opened struct {
+	BookID   string    `json:"book_id"`
+	Borrower string    `json:"borrower"`
+	At       time.Time `json:"at"`
+	Due      time.Time `json:"due"`
+}
+
+type Extended struct {
+	Due time.Time `json:"due"`
+}
+
+type Returned struct {
+	At time.Time `json:"at"`
+}
+
+func (Opened) EventType() string   { return "loan_opened" }
+func (Extended) EventType() string { return "loan_extended" }
+func (Returned) EventType() string { return "loan_returned" }
+
+// Codec знает все события выдачи.
+func Codec() aggregate.Codec {
+	c := aggregate.Codec{}
+	aggregate.Register[Opened](c)
+	aggregate.Register[Extended](c)
+	aggregate.Register[Returned](c)
+	return c
+}
+
+type Loan struct {
+	aggregate.Base
+
+	BookID     string
+	Borrower   string
+	Due        time.Time
+	Extensions int
+	ReturnedAt time.Time
+}
+
+var _ aggregate.Root = (*Loan)(nil)
+
+func New(id string) *Loan {
+	l := &Loan{}
+	l.Init(id, l)
+	return l
+}
+
+func (l *Loan) Apply(e aggregate.Event) {
+	switch e := e.(type) {
+	case Opened:
+		l.BookID, l.Borrower, l.Due = e.BookID, e.Borrower, e.Due
+	case Extended:
+		l.Due = e.Due
+		l.Extensions++
+	case Returned:
+		l.ReturnedAt = e.At
+	}
+}
+
+func (l *Loan) Closed() bool { return !l.ReturnedAt.IsZero() }
+
+// Open - команда для новой выдачи.
+func Open(id, bookID, borrower string, now time.Time) (*Loan, error) {
+	if id == "" || bookID == "" || borrower == "" {
+		return nil, fmt.Errorf("%w: id, book and borrower are required", ErrInvalid)
+	}
+	l := New(id)
+	l.Raise(Opened{BookID: bookID, Borrower: borrower, At: now, Due: now.Add(Period)})
+	return l, nil
+}
+
+func (l *Loan) Extend(now time.Time) error {
+	switch {
+	case l.Closed():
+		return ErrClosed
+	case now.After(l.Due):
+		return ErrOverdue
+	case l.Extensions >= MaxExtensions:
+		return ErrTooManyExtend
+	}
+	l.Raise(Extended{Due: l.Due.Add(Period)})
+	return nil
+}
+
+func (l *Loan) Return(now time.Time) error {
+	if l.Closed() {
+		return ErrClosed
+	}
+	l.Raise(Returned{At: now})
+	return nil
+}
+
+// Repository - хранилище выдач поверх любого EventStore.
+func Repository(store eventstore.EventStore) *aggregate.Repository[*Loan] {
+	return &aggregate.Repository[*Loan]{Store: store, Codec: Codec(), New: New}
+}
+
+// Handle загружает выдачу, выполняет команду и сохраняет результат одной попыткой;
+// при ErrConcurrency вызывающий перечитывает поток и решает, повторять ли.
+func Handle(ctx context.Context, repo *aggregate.Repository[*Loan], id string, cmd func(*Loan) error) (*Loan, error) {
+	l, err := repo.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd(l); err != nil {
+		return l, err
+	}
+	return l, repo.Save(ctx, l)
+}
@@ -0,0 +1,192 @@
+// Команда gateway поднимает API-шлюз перед сервисом библиотеки (cmd/libraryd)
+// и сервисом цен (system_architecture/hexagonal).
+//
+//	gateway serve [-config gateway.yaml] [-addr :8000] [-discovery static|dns|consul] [-target ...] [-keys k-shop=shop,...]
+//	gateway check   прогнать шлюз против поддельных сервисов
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"solid/app"
+	"solid/config"
+	"solid/discovery"
+	"solid/health"
+	"solid/httpmw"
+	"solid/logging"
+	"solid/metrics"
+	"solid/resilience/bulkhead"
+	"solid/resilience/ratelimit"
+	"solid/telemetry"
+
+	"gateway/internal/check"
+	"gateway/internal/gateway"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: gateway serve [flags] | gateway check")
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "serve":
+		serve(os.Args[2:])
+	case "check":
+		if err := check.Run(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "gateway: unknown command %q\n", os.Args[1])
+		os.Exit(2)
+	}
+}
+
+// settings - настройки serve. Они читаются из -config (JSON или YAML), переменных
+// GATEWAY_* и флагов; ключи и лимит меняются правкой файла без перезапуска.
+type settings struct {
+	Addr        string            `default:":8000" usage:"HTTP listen address"`
+	Discovery   string            `default:"static" validate:"oneof=static dns consul" usage:"service resolver: static, dns or consul"`
+	Target      string            `default:"library=localhost:8080;pricing=localhost:8082" usage:"static services, DNS domain or Consul address"`
+	Keys        map[string]string `default:"dev-key=dev" validate:"required" usage:"comma-separated key=caller pairs (reloadable)"`
+	Rate        float64           `default:"5" validate:"min=0" usage:"requests per second per caller (reloadable)"`
+	Burst       int               `default:"10" validate:"min=1" usage:"burst size per caller (reloadable)"`
+	Redis       string            `usage:"Redis address for a rate limit shared by all gateway replicas (in-memory if empty)"`
+	HedgeAfter  time.Duration     `default:"300ms" validate:"min=0" usage:"duplicate a slow book read to another library instance after this delay (0 disables)"`
+	MaxInflight int               `default:"64" validate:"min=1" usage:"concurrent requests per backend service"`
+	CORSOrigins []string          `usage:"comma-separated origins allowed to call the gateway from a browser, * for any (CORS disabled if empty)"`
+	Trace       string            `default:"none" validate:"oneof=none stdout" usage:"trace exporter: none or stdout"`
+	MetricsAddr string            `usage:"HTTP address for Prometheus metrics at /metrics (disabled if empty)"`
+	LogFormat   string            `default:"text" validate:"oneof=text json" usage:"log format: text or json"`
+	LogLevel    string            `default:"info" validate:"oneof=debug info warn error" usage:"default log level (reloadable)"`
+	LogLevels   map[string]string `usage:"comma-separated module=level overrides for the gateway, app and config modules (reloadable)"`
+}
+
+// fixed - настройки без перезагружаемых полей: их изменение вступает в силу только
+// после перезапуска.
+func (s settings) fixed() settings {
+	s.Keys, s.Rate, s.Burst, s.LogLevel, s.LogLevels = nil, 0, 0, "", nil
+	return s
+}
+
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	// Ошибки перезагрузки пишет журнал config, который собирается уже после Load.
+	configLog := log.Default()
+	loader, err := config.Bind[settings](fs, config.Options{
+		FileFlag:  "config",
+		EnvPrefix: "GATEWAY_",
+		OnError:   func(err error) { configLog.Printf("gateway: %v", err) },
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	poll := fs.Duration("config-poll", 5*time.Second, "how often to check the config files for changes")
+	fs.Parse(args)
+	cfg, err := loader.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	levels, err := logging.ParseLevels(cfg.LogLevel, cfg.LogLevels)
+	if err != nil {
+		log.Fatal(err)
+	}
+	root, err := logging.New(logging.Config{Format: cfg.LogFormat, Levels: levels})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Компоненты пишут через *log.Logger на уровне info; уровень модуля отсекает их целиком.
+	moduleLog := func(name string) *log.Logger {
+		return slog.NewLogLogger(logging.Module(root, name).Handler(), slog.LevelInfo)
+	}
+	logger := moduleLog("gateway")
+	configLog = moduleLog("config")
+
+	r, err := discovery.New(cfg.Discovery, cfg.Target)
+	if err != nil {
+		log.Fatal(err)
+	}
+	balancer := discovery.NewBalancer(r, discovery.BalancerConfig{})
+	tp, shutdown, err := telemetry.Setup(telemetry.Config{Service: "gateway", Exporter: cfg.Trace})
+	if err != nil {
+		log.Fatal(err)
+	}
+	a := app.New(app.Config{Logger: moduleLog("app")})
+	// Добавлен первым - останавливается последним и дописывает спаны остальных.
+	a.Add("telemetry", app.Hook{OnStop: shutdown})
+	// Шлюз готов, пока у каждого сервиса есть хоть один живой экземпляр.
+	checks := health.New(health.Config{})
+	for _, svc := range []string{"library", "pricing"} {
+		checks.Readiness(health.Check{Name: svc, Probe: func(ctx context.Context) error {
+			_, err := balancer.Pick(ctx, svc)
+			return err
+		}})
+	}
+	var rdb *redis.Client
+	if cfg.Redis != "" {
+		rdb = redis.NewClient(&redis.Options{Addr: cfg.Redis})
+		a.Add("redis", app.Closer(rdb.Close))
+		// Без Redis лимитер пропускает запросы - шлюз работает, но без общего лимита.
+		checks.Readiness(health.Check{Name: "redis", Probe: func(ctx context.Context) error { return rdb.Ping(ctx).Err() }, Optional: true})
+	}
+	newLimiter := func(cfg settings) ratelimit.Limiter {
+		if rdb != nil {
+			return ratelimit.NewRedisTokenBucket(rdb, cfg.Rate, cfg.Burst)
+		}
+		return ratelimit.NewTokenBucket(cfg.Rate, cfg.Burst)
+	}
+	limiter := ratelimit.NewSwap(newLimiter(cfg))
+	loader.OnChange(func(old, cur settings) {
+		if cur.Rate != old.Rate || cur.Burst != old.Burst {
+			limiter.Store(newLimiter(cur))
+		}
+		if err := levels.Update(cur.LogLevel, cur.LogLevels); err != nil {
+			configLog.Printf("gateway: %v", err)
+		}
+		if !reflect.DeepEqual(old.fixed(), cur.fixed()) {
+			configLog.Print("gateway: config reloaded; only keys, rate, burst and log levels apply without a restart")
+			return
+		}
+		configLog.Print("gateway: config reloaded")
+	})
+	a.Worker("config", func(ctx context.Context) error {
+		loader.Watch(ctx, *poll)
+		return ctx.Err()
+	})
+
+	var reg *metrics.Registry
+	if cfg.MetricsAddr != "" {
+		// Метрики на отдельном порту: основной открыт клиентам шлюза.
+		reg = metrics.New("gateway")
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", reg.Handler())
+		a.HTTP("metrics", &http.Server{Addr: cfg.MetricsAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second})
+	}
+	h := gateway.New(gateway.Config{
+		Balancer:   balancer,
+		KeySource:  func() map[string]string { return loader.Current().Keys },
+		Limiter:    limiter,
+		Bulkhead:   bulkhead.Config{MaxConcurrent: cfg.MaxInflight},
+		HedgeAfter: cfg.HedgeAfter,
+		Health:     checks,
+		CORS:       httpmw.CORSConfig{Origins: cfg.CORSOrigins, Methods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}, MaxAge: 10 * time.Minute},
+		Tracer:     tp,
+		Metrics:    reg,
+		Logger:     logger,
+	})
+	a.HTTP("http", &http.Server{Addr: cfg.Addr, Handler: h, ReadHeaderTimeout: 5 * time.Second})
+	logger.Printf("gateway listening on %s", cfg.Addr)
+	if err := a.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
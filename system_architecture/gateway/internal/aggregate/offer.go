@@ -0,0 +1,197 @@
+// Package aggregate - составные ответы: один запрос клиента, несколько сервисов за шлюзом.
+package aggregate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"solid/concurrency/future"
+	"solid/discovery"
+	"solid/httpmw"
+	"solid/resilience/breaker"
+	"solid/resilience/bulkhead"
+	"solid/resilience/retry"
+
+	"gateway/internal/middleware"
+)
+
+// Offer собирает карточку книги из библиотеки и расчёт цены из сервиса цен.
+// Запросы идут параллельно; без цены ответ всё равно отдаётся, с пояснением в errors.
+type Offer struct {
+	Balancer *discovery.Balancer
+	// Library и Pricing - имена сервисов в реестре.
+	Library string
+	Pricing string
+	// Breakers - выключатель на каждый сервис; сервис без выключателя вызывается всегда.
+	Breakers map[string]*breaker.Breaker
+	// Bulkheads - переборка на каждый сервис: медленный сервис цен не занимает
+	// все горутины шлюза.
+	Bulkheads map[string]*bulkhead.Bulkhead
+	Client    *http.Client
+	// Timeout - общий бюджет на оба запроса, по умолчанию 2 секунды.
+	Timeout time.Duration
+	// HedgeAfter, если больше нуля, - через сколько без ответа продублировать запрос
+	// книги в другой экземпляр библиотеки. Цена не дублируется: POST не идемпотентен.
+	HedgeAfter time.Duration
+}
+
+type offerResponse struct {
+	Book   json.RawMessage `json:"book"`
+	Quote  json.RawMessage `json:"quote"`
+	Errors []string        `json:"errors,omitempty"`
+}
+
+// ServeHTTP обслуживает GET /books/{id}/offer?price=&discount=&currency=.
+func (o *Offer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	q := r.URL.Query()
+	price, err := strconv.ParseFloat(q.Get("price"), 64)
+	if err != nil || price <= 0 {
+		middleware.Error(w, http.StatusBadRequest, "price must be a positive number")
+		return
+	}
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	// Оба запроса стартуют сразу; книга нужна для ответа в любом случае, цена - если успела.
+	book := o.async(ctx, func(ctx context.Context) (json.RawMessage, int, error) {
+		return o.book(ctx, id)
+	})
+	quote := o.async(ctx, func(ctx context.Context) (json.RawMessage, int, error) {
+		body, _ := json.Marshal(map[string]any{"sku": id, "price": price, "discount": q.Get("discount"), "currency": q.Get("currency")})
+		return o.call(ctx, http.MethodPost, o.Pricing, "/quotes", body)
+	})
+	// Запросы сами укладываются в бюджет ctx и по его истечении вернут ошибку с именем
+	// сервиса, поэтому ждём их до ухода клиента, а не до того же срока.
+	bookReply, bookErr := book.Get(r.Context())
+	quoteRaw, quoteErr := future.Map(quote, func(r reply) (json.RawMessage, error) { return r.raw, nil }).Get(r.Context())
+
+	// Без книги предлагать нечего: её ошибка - ошибка всего запроса.
+	if bookErr != nil {
+		status := http.StatusBadGateway
+		switch {
+		case bookReply.status == http.StatusNotFound:
+			status = http.StatusNotFound
+		case errors.Is(bookErr, breaker.ErrOpen), errors.Is(bookErr, bulkhead.ErrFull), errors.Is(bookErr, bulkhead.ErrTimeout):
+			// Шлюз сам не стал звать библиотеку: повторить можно чуть позже.
+			status = http.StatusServiceUnavailable
+		}
+		middleware.Error(w, status, bookErr.Error())
+		return
+	}
+	resp := offerResponse{Book: bookReply.raw, Quote: quoteRaw}
+	if quoteErr != nil {
+		resp.Quote = json.RawMessage("null")
+		resp.Errors = append(resp.Errors, quoteErr.Error())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// async выполняет запрос к сервису в своей горутине; статус ответа остаётся в reply
+// и при ошибке.
+func (o *Offer) async(ctx context.Context, fn func(ctx context.Context) (json.RawMessage, int, error)) *future.Future[reply] {
+	return future.Go(ctx, func(ctx context.Context) (reply, error) {
+		raw, status, err := fn(ctx)
+		return reply{raw: raw, status: status}, err
+	})
+}
+
+// book запрашивает книгу, при HedgeAfter - с запасной копией запроса.
+func (o *Offer) book(ctx context.Context, id string) (json.RawMessage, int, error) {
+	path := "/books/" + url.PathEscape(id)
+	if o.HedgeAfter <= 0 {
+		return o.call(ctx, http.MethodGet, o.Library, path, nil)
+	}
+	r, err := retry.Hedge(ctx, o.HedgeAfter, 2, func(ctx context.Context) (reply, error) {
+		raw, status, err := o.call(ctx, http.MethodGet, o.Library, path, nil)
+		if err != nil && status >= 400 && status < 500 {
+			// 404 - ответ по существу, копия его не изменит.
+			err = retry.Permanent(err)
+		}
+		return reply{raw: raw, status: status}, err
+	})
+	return r.raw, r.status, err
+}
+
+// call выполняет запрос к экземпляру сервиса через его переборку и выключатель, передавая
+// идентификатор запроса, и сообщает балансировщику, ответил ли экземпляр.
+func (o *Offer) call(ctx context.Context, method, service, path string, body []byte) (json.RawMessage, int, error) {
+	r, err := bulkhead.Do(ctx, o.Bulkheads[service], func() (reply, error) {
+		return breaker.Do(o.Breakers[service], func() (reply, error) {
+			ep, err := o.Balancer.Pick(ctx, service)
+			if err != nil {
+				return reply{}, err
+			}
+			raw, status, err := o.do(ctx, method, "http://"+ep.Addr+path, body)
+			r := reply{raw: raw, status: status, err: err}
+			switch {
+			case ctx.Err() != nil:
+				// Истёк бюджет запроса или клиент ушёл - экземпляр в этом не виноват.
+				return r, err
+			case err != nil && (status == 0 || status >= 500):
+				o.Balancer.Report(service, ep.Addr, err)
+				return r, err
+			}
+			// 4xx - ответ по существу: экземпляр и сервис исправны.
+			o.Balancer.Report(service, ep.Addr, nil)
+			return r, nil
+		})
+	})
+	if err == nil {
+		err = r.err
+	}
+	if err != nil {
+		return nil, r.status, fmt.Errorf("%s: %w", service, err)
+	}
+	return r.raw, r.status, nil
+}
+
+type reply struct {
+	raw    json.RawMessage
+	status int
+	err    error
+}
+
+func (o *Offer) do(ctx context.Context, method, u string, body []byte) (json.RawMessage, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set(httpmw.RequestIDHeader, httpmw.RequestIDFrom(ctx))
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("read: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, resp.StatusCode, fmt.Errorf("%s %s: status %d", method, req.URL.Path, resp.StatusCode)
+	}
+	if !json.Valid(raw) {
+		return nil, resp.StatusCode, fmt.Errorf("%s %s: invalid JSON", method, req.URL.Path)
+	}
+	return raw, resp.StatusCode, nil
+}
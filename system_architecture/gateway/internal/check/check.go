@@ -0,0 +1,456 @@
+// Package check прогоняет шлюз против поддельных сервисов библиотеки и цен на httptest.
+package check
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"solid/discovery"
+	"solid/httpmw"
+	"solid/resilience/breaker"
+	"solid/resilience/bulkhead"
+	"solid/resilience/ratelimit"
+
+	"gateway/internal/gateway"
+	"gateway/internal/middleware"
+)
+
+// backends - поддельные сервисы, которые запоминают, какие заголовки до них дошли.
+type backends struct {
+	mu      sync.Mutex
+	seen    []http.Header
+	pricing bool
+}
+
+func (b *backends) record(r *http.Request) {
+	b.mu.Lock()
+	b.seen = append(b.seen, r.Header.Clone())
+	b.mu.Unlock()
+}
+
+func (b *backends) library() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /books/{id}", func(w http.ResponseWriter, r *http.Request) {
+		b.record(r)
+		if r.PathValue("id") != "1" {
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":"1","title":"Dune","author":"Frank Herbert","author_id":"a1","year":1965,"isbn":"978-0441013593"}`)
+	})
+	return mux
+}
+
+func (b *backends) pricingHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.record(r)
+		b.mu.Lock()
+		up := b.pricing
+		b.mu.Unlock()
+		if !up {
+			http.Error(w, `{"error":"maintenance"}`, http.StatusServiceUnavailable)
+			return
+		}
+		var q struct {
+			SKU   string  `json:"sku"`
+			Price float64 `json:"price"`
+		}
+		json.NewDecoder(r.Body).Decode(&q)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":"q-1","sku":%q,"price":%g,"total":%g}`, q.SKU, q.Price, q.Price*0.9)
+	})
+}
+
+// Run проверяет ключи, лимит, CORS, проброс X-Request-ID, проксирование и составной ответ.
+func Run(w io.Writer) error {
+	b := &backends{pricing: true}
+	lib := httptest.NewServer(b.library())
+	defer lib.Close()
+	pr := httptest.NewServer(b.pricingHandler())
+	defer pr.Close()
+	services := discovery.Static{"library": {hostOf(lib)}, "pricing": {hostOf(pr)}}
+
+	gw := httptest.NewServer(gateway.New(gateway.Config{
+		Balancer: discovery.NewBalancer(services, discovery.BalancerConfig{}),
+		Keys:     map[string]string{"k-shop": "shop", "k-tiny": "tiny"},
+		Limiter:  limits{"tiny": ratelimit.NewSlidingWindow(2, time.Hour), "": ratelimit.NewTokenBucket(1000, 1000)},
+		Breaker:  breaker.Config{Window: 4, MinRequests: 3, FailureRate: 0.5, OpenTimeout: time.Minute},
+		CORS:     httpmw.CORSConfig{Origins: []string{"https://shop.example"}, Methods: []string{http.MethodGet, http.MethodPost}},
+		Logger:   log.New(io.Discard, "", 0),
+	}))
+	defer gw.Close()
+
+	get := func(path, key, reqID string) (*http.Response, string, error) {
+		req, _ := http.NewRequest(http.MethodGet, gw.URL+path, nil)
+		if key != "" {
+			req.Header.Set(middleware.APIKeyHeader, key)
+		}
+		if reqID != "" {
+			req.Header.Set(httpmw.RequestIDHeader, reqID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, strings.TrimSpace(string(body)), nil
+	}
+	expect := func(name, path, key string, want int) (*http.Response, string, error) {
+		resp, body, err := get(path, key, "")
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Fprintf(w, "%-22s %d %s\n", name, resp.StatusCode, body)
+		if resp.StatusCode != want {
+			return nil, "", fmt.Errorf("check: %s: status %d, want %d", name, resp.StatusCode, want)
+		}
+		return resp, body, nil
+	}
+
+	if _, _, err := expect("healthz", "/healthz", "", http.StatusOK); err != nil {
+		return err
+	}
+	if _, _, err := expect("no key", "/library/books/1", "", http.StatusUnauthorized); err != nil {
+		return err
+	}
+	if _, _, err := expect("proxy library", "/library/books/1", "k-shop", http.StatusOK); err != nil {
+		return err
+	}
+	if _, _, err := expect("proxy not found", "/library/books/9", "k-shop", http.StatusNotFound); err != nil {
+		return err
+	}
+	if _, _, err := expect("unknown route", "/orders/1", "k-shop", http.StatusNotFound); err != nil {
+		return err
+	}
+
+	// Предварительный запрос браузера приходит без ключа и получает ответ от самого шлюза.
+	preflight := func(origin string) (http.Header, int, error) {
+		req, _ := http.NewRequest(http.MethodOptions, gw.URL+"/library/books/1", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		req.Header.Set("Access-Control-Request-Headers", middleware.APIKeyHeader)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, 0, fmt.Errorf("preflight: %w", err)
+		}
+		resp.Body.Close()
+		return resp.Header, resp.StatusCode, nil
+	}
+	h, code, err := preflight("https://shop.example")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%-22s %d origin=%s headers=%s\n", "cors preflight", code, h.Get("Access-Control-Allow-Origin"), h.Get("Access-Control-Allow-Headers"))
+	if code != http.StatusNoContent || h.Get("Access-Control-Allow-Origin") != "https://shop.example" ||
+		!strings.Contains(h.Get("Access-Control-Allow-Headers"), middleware.APIKeyHeader) {
+		return fmt.Errorf("check: cors preflight: status %d, headers %v", code, h)
+	}
+	if h, _, err = preflight("https://evil.example"); err != nil {
+		return err
+	} else if h.Get("Access-Control-Allow-Origin") != "" {
+		return fmt.Errorf("check: cors: unknown origin allowed")
+	}
+	fmt.Fprintln(w, "cors: unknown origin gets no Access-Control-Allow-Origin")
+
+	// Идентификатор клиента доходит до обоих сервисов составного запроса и возвращается в ответе.
+	b.mu.Lock()
+	b.seen = nil
+	b.mu.Unlock()
+	resp, body, err := get("/books/1/offer?price=20&discount=regular&currency=EUR", "k-shop", "req-42")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%-22s %d %s\n", "offer", resp.StatusCode, body)
+	var offer struct {
+		Book   map[string]any `json:"book"`
+		Quote  map[string]any `json:"quote"`
+		Errors []string       `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(body), &offer); err != nil || resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("check: offer: status %d, err %v", resp.StatusCode, err)
+	}
+	if offer.Book["title"] != "Dune" || offer.Quote["total"] != 18.0 {
+		return fmt.Errorf("check: offer: unexpected body %s", body)
+	}
+	if got := resp.Header.Get(httpmw.RequestIDHeader); got != "req-42" {
+		return fmt.Errorf("check: response request id %q, want req-42", got)
+	}
+	b.mu.Lock()
+	seen := b.seen
+	b.mu.Unlock()
+	if len(seen) != 2 {
+		return fmt.Errorf("check: offer reached %d backends, want 2", len(seen))
+	}
+	for _, h := range seen {
+		if h.Get(httpmw.RequestIDHeader) != "req-42" || h.Get(middleware.APIKeyHeader) != "" {
+			return fmt.Errorf("check: backend headers id=%q key=%q", h.Get(httpmw.RequestIDHeader), h.Get(middleware.APIKeyHeader))
+		}
+	}
+	fmt.Fprintln(w, "request id forwarded to both backends, API key stripped")
+
+	// Сервис цен недоступен - книга всё равно отдаётся, ошибка в errors.
+	b.mu.Lock()
+	b.pricing = false
+	b.mu.Unlock()
+	if _, body, err = expect("offer, pricing down", "/books/1/offer?price=20", "k-shop", http.StatusOK); err != nil {
+		return err
+	}
+	if !strings.Contains(body, `"quote":null`) || !strings.Contains(body, "status 503") {
+		return fmt.Errorf("check: degraded offer: %s", body)
+	}
+	if _, _, err := expect("offer, unknown book", "/books/9/offer?price=20", "k-shop", http.StatusNotFound); err != nil {
+		return err
+	}
+	if _, _, err := expect("offer, bad price", "/books/1/offer?price=free", "k-shop", http.StatusBadRequest); err != nil {
+		return err
+	}
+
+	// Оба запроса выше получили от цен 503: вместе с первым успешным это 2 отказа из 3,
+	// выключатель разомкнут, и шлюз больше не вызывает сервис цен.
+	hits := func() int {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.seen)
+	}
+	before := hits()
+	if _, body, err = expect("offer, breaker open", "/books/1/offer?price=20", "k-shop", http.StatusOK); err != nil {
+		return err
+	}
+	if !strings.Contains(body, "circuit open") || hits() != before+1 {
+		return fmt.Errorf("check: open breaker: %s, backend calls %d, want only the library one", body, hits()-before)
+	}
+	if _, body, err = expect("proxy, breaker open", "/pricing/quotes/q-1", "k-shop", http.StatusServiceUnavailable); err != nil {
+		return err
+	}
+	if hits() != before+1 {
+		return fmt.Errorf("check: proxy reached pricing through an open breaker")
+	}
+
+	// У tiny окно на два запроса в час, третий получает 429 с Retry-After.
+	for i := 0; i < 2; i++ {
+		if _, _, err := expect("tiny within limit", "/library/books/1", "k-tiny", http.StatusOK); err != nil {
+			return err
+		}
+	}
+	resp, _, err = expect("tiny over limit", "/library/books/1", "k-tiny", http.StatusTooManyRequests)
+	if err != nil {
+		return err
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		return fmt.Errorf("check: 429 without Retry-After")
+	}
+	if err := failover(w, lib); err != nil {
+		return err
+	}
+	if err := isolation(w); err != nil {
+		return err
+	}
+	if err := hedging(w); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "ok")
+	return nil
+}
+
+// failover берёт экземпляры библиотеки из поддельного агента Consul: один живой, один
+// остановленный. После MaxFails ошибок мёртвый исключается и все запросы идут в живой.
+func failover(w io.Writer, lib *httptest.Server) error {
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadAddr := hostOf(dead)
+	dead.Close()
+
+	var passing atomic.Bool
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		passing.Store(r.URL.Query().Has("passing"))
+		if r.URL.Path != "/v1/health/service/library" {
+			io.WriteString(w, "[]")
+			return
+		}
+		var entries []map[string]any
+		for _, addr := range []string{hostOf(lib), deadAddr} {
+			host, port, _ := net.SplitHostPort(addr)
+			p, _ := strconv.Atoi(port)
+			entries = append(entries, map[string]any{
+				"Node":    map[string]any{"Address": host},
+				"Service": map[string]any{"Port": p, "Tags": []string{"v1"}},
+			})
+		}
+		json.NewEncoder(w).Encode(entries)
+	}))
+	defer agent.Close()
+
+	bal := discovery.NewBalancer(&discovery.Consul{Addr: agent.URL}, discovery.BalancerConfig{MaxFails: 2, Cooldown: time.Minute})
+	gw := httptest.NewServer(gateway.New(gateway.Config{
+		Balancer: bal,
+		Keys:     map[string]string{"k-shop": "shop"},
+		Limiter:  ratelimit.NewTokenBucket(1000, 1000),
+		Logger:   log.New(io.Discard, "", 0),
+	}))
+	defer gw.Close()
+
+	var statuses []int
+	failed := 0
+	for i := 0; i < 8; i++ {
+		req, _ := http.NewRequest(http.MethodGet, gw.URL+"/library/books/1", nil)
+		req.Header.Set(middleware.APIKeyHeader, "k-shop")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		statuses = append(statuses, resp.StatusCode)
+		if resp.StatusCode == http.StatusBadGateway {
+			failed++
+		}
+	}
+	fmt.Fprintf(w, "consul failover statuses %v, down %v\n", statuses, bal.Down("library"))
+	if !passing.Load() {
+		return fmt.Errorf("check: consul resolver did not ask for passing instances")
+	}
+	if failed != 2 || statuses[len(statuses)-1] != http.StatusOK {
+		return fmt.Errorf("check: %d requests hit the dead instance, want 2", failed)
+	}
+	if down := bal.Down("library"); len(down) != 1 || down[0] != deadAddr {
+		return fmt.Errorf("check: down instances %v, want [%s]", down, deadAddr)
+	}
+	return nil
+}
+
+// isolation зависает в библиотеке, пока её единственное место у переборки занято:
+// следующий запрос к библиотеке сразу получает 503, а сервис цен отвечает как обычно.
+func isolation(w io.Writer) error {
+	entered, release := make(chan struct{}), make(chan struct{})
+	lib := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/books/slow" {
+			close(entered)
+			<-release
+		}
+		io.WriteString(w, `{"id":"1"}`)
+	}))
+	defer lib.Close()
+	pr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":"q-1"}`)
+	}))
+	defer pr.Close()
+	gw := httptest.NewServer(gateway.New(gateway.Config{
+		Balancer: discovery.NewBalancer(discovery.Static{"library": {hostOf(lib)}, "pricing": {hostOf(pr)}}, discovery.BalancerConfig{}),
+		Keys:     map[string]string{"k-shop": "shop"},
+		Limiter:  ratelimit.NewTokenBucket(1000, 1000),
+		Bulkhead: bulkhead.Config{MaxConcurrent: 1, MaxQueue: -1},
+		Logger:   log.New(io.Discard, "", 0),
+	}))
+	defer gw.Close()
+
+	status := func(path string) (int, error) {
+		req, _ := http.NewRequest(http.MethodGet, gw.URL+path, nil)
+		req.Header.Set(middleware.APIKeyHeader, "k-shop")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+	slow := make(chan int, 1)
+	go func() {
+		code, _ := status("/library/books/slow")
+		slow <- code
+	}()
+	<-entered
+	busy, err := status("/library/books/1")
+	if err != nil {
+		return err
+	}
+	other, err := status("/pricing/quotes/q-1")
+	if err != nil {
+		return err
+	}
+	close(release)
+	slowCode := <-slow
+	fmt.Fprintf(w, "bulkhead: slow library %d, second library %d, pricing %d\n", slowCode, busy, other)
+	if busy != http.StatusServiceUnavailable || other != http.StatusOK || slowCode != http.StatusOK {
+		return fmt.Errorf("check: bulkhead statuses slow=%d busy=%d pricing=%d", slowCode, busy, other)
+	}
+	return nil
+}
+
+// hedging держит два экземпляра библиотеки, один из которых отвечает секунду. Запрос
+// книги дублируется через 50ms, поэтому ни одно предложение не ждёт медленный экземпляр.
+func hedging(w io.Writer) error {
+	book := func(delay time.Duration) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			io.WriteString(w, `{"id":"1","title":"Dune"}`)
+		}))
+	}
+	slow, fast := book(time.Second), book(0)
+	defer slow.Close()
+	defer fast.Close()
+	pr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":"q-1","total":18}`)
+	}))
+	defer pr.Close()
+	gw := httptest.NewServer(gateway.New(gateway.Config{
+		Balancer:   discovery.NewBalancer(discovery.Static{"library": {hostOf(slow), hostOf(fast)}, "pricing": {hostOf(pr)}}, discovery.BalancerConfig{}),
+		Keys:       map[string]string{"k-shop": "shop"},
+		Limiter:    ratelimit.NewTokenBucket(1000, 1000),
+		HedgeAfter: 50 * time.Millisecond,
+		Logger:     log.New(io.Discard, "", 0),
+	}))
+	defer gw.Close()
+
+	var took []time.Duration
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, gw.URL+"/books/1/offer?price=20", nil)
+		req.Header.Set(middleware.APIKeyHeader, "k-shop")
+		start := time.Now()
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		took = append(took, time.Since(start).Round(10*time.Millisecond))
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("check: hedged offer status %d", resp.StatusCode)
+		}
+	}
+	fmt.Fprintf(w, "hedged offers took %v\n", took)
+	for _, d := range took {
+		if d >= 500*time.Millisecond {
+			return fmt.Errorf("check: hedged offer took %s, the slow instance was awaited", d)
+		}
+	}
+	return nil
+}
+
+func hostOf(s *httptest.Server) string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// limits - отдельный лимитер на вызывающего, "" - для остальных.
+type limits map[string]ratelimit.Limiter
+
+func (l limits) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	if lim, ok := l[key]; ok {
+		return lim.Allow(ctx, key)
+	}
+	return l[""].Allow(ctx, key)
+}
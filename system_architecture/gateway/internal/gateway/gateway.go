@@ -0,0 +1,134 @@
+// Package gateway собирает шлюз: сквозные обработчики, составной ответ и прокси к сервисам.
+package gateway
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"solid/data"
+	"solid/discovery"
+	"solid/health"
+	"solid/httpmw"
+	"solid/metrics"
+	"solid/resilience/breaker"
+	"solid/resilience/bulkhead"
+	"solid/resilience/ratelimit"
+	"solid/telemetry"
+
+	"gateway/internal/aggregate"
+	"gateway/internal/middleware"
+	"gateway/internal/proxy"
+)
+
+type Config struct {
+	// Balancer выбирает экземпляры сервисов; Library и Pricing - их имена в реестре,
+	// по умолчанию "library" и "pricing".
+	Balancer *discovery.Balancer
+	Library  string
+	Pricing  string
+	// Keys сопоставляет API-ключ вызывающему. KeySource, если задан, заменяет Keys:
+	// ключи читаются из него на каждом запросе, и перезагрузка настроек их обновляет.
+	Keys      map[string]string
+	KeySource func() map[string]string
+	// Limiter считает лимит отдельно для каждого вызывающего.
+	Limiter ratelimit.Limiter
+	// Breaker - настройки выключателя, который шлюз заводит на каждый сервис.
+	Breaker breaker.Config
+	// Bulkhead - размеры переборки на каждый сервис.
+	Bulkhead bulkhead.Config
+	// HedgeAfter - через сколько дублировать медленное чтение книги; 0 - не дублировать.
+	HedgeAfter time.Duration
+	// Health отвечает на /livez и /readyz, открытые, как и /healthz, без ключа;
+	// nil - только /healthz.
+	Health *health.Health
+	// CORS открывает шлюз страницам с перечисленных источников; пустой Origins - CORS
+	// выключен. Заголовок API-ключа разрешается всегда.
+	CORS httpmw.CORSConfig
+	// Tracer, если задан, начинает трассу на каждый запрос и передаёт её сервисам.
+	Tracer trace.TracerProvider
+	// Metrics, если задан, считает запросы шлюза в http_server_*.
+	Metrics *metrics.Registry
+	Logger  *log.Logger
+}
+
+// New - обработчик шлюза. /healthz (и /livez, /readyz с Health) открыт всем, остальное
+// требует ключа и проходит лимит:
+//
+//	GET /books/{id}/offer   книга и цена одним ответом
+//	/library/...            сервис библиотеки
+//	/pricing/...            сервис цен
+func New(cfg Config) http.Handler {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	if cfg.Library == "" {
+		cfg.Library = "library"
+	}
+	if cfg.Pricing == "" {
+		cfg.Pricing = "pricing"
+	}
+	breakers := map[string]*breaker.Breaker{}
+	bulkheads := map[string]*bulkhead.Bulkhead{}
+	for _, svc := range []string{cfg.Library, cfg.Pricing} {
+		bc := cfg.Breaker
+		onChange := bc.OnStateChange
+		bc.OnStateChange = func(from, to breaker.State) {
+			logger.Printf("breaker %s: %s -> %s", svc, from, to)
+			if onChange != nil {
+				onChange(from, to)
+			}
+		}
+		breakers[svc] = breaker.New(bc)
+		bulkheads[svc] = bulkhead.New(cfg.Bulkhead)
+	}
+	transport := telemetry.Transport(cfg.Tracer, nil)
+	api := http.NewServeMux()
+	api.Handle("GET /books/{id}/offer", &aggregate.Offer{
+		Client:     &http.Client{Transport: transport},
+		Balancer:   cfg.Balancer,
+		Library:    cfg.Library,
+		Pricing:    cfg.Pricing,
+		Breakers:   breakers,
+		Bulkheads:  bulkheads,
+		HedgeAfter: cfg.HedgeAfter,
+	})
+	api.Handle("/", proxy.New([]proxy.Route{
+		{Prefix: "library", Service: cfg.Library, Breaker: breakers[cfg.Library], Bulkhead: bulkheads[cfg.Library]},
+		{Prefix: "pricing", Service: cfg.Pricing, Breaker: breakers[cfg.Pricing], Bulkhead: bulkheads[cfg.Pricing]},
+	}, cfg.Balancer, transport, logger))
+
+	keys := cfg.KeySource
+	if keys == nil {
+		keys = func() map[string]string { return cfg.Keys }
+	}
+	root := http.NewServeMux()
+	root.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	if cfg.Health != nil {
+		cfg.Health.Register(root)
+	}
+	root.Handle("/", httpmw.Chain(api, middleware.AuthFunc(keys), ratelimit.Middleware(cfg.Limiter, byCaller, func(r *http.Request, err error) {
+		logger.Printf("%s rate limiter: %v", httpmw.RequestIDFrom(r.Context()), err)
+	})))
+	if len(cfg.CORS.Origins) > 0 {
+		cfg.CORS.Headers = slices.Concat(cfg.CORS.Headers, []string{"Content-Type", middleware.APIKeyHeader, httpmw.RequestIDHeader})
+		cfg.CORS.Expose = slices.Concat(cfg.CORS.Expose, []string{httpmw.RequestIDHeader})
+	}
+	// CORS снаружи Auth: предварительный запрос браузера приходит без ключа.
+	mws := []httpmw.Middleware{httpmw.RequestID, httpmw.Logging(logger), httpmw.Recover(logger), httpmw.CORS(cfg.CORS), httpmw.Gzip, telemetry.HTTP(cfg.Tracer)}
+	if cfg.Metrics != nil {
+		mws = append(mws, metrics.HTTP(cfg.Metrics))
+	}
+	return httpmw.Chain(root, mws...)
+}
+
+// byCaller - ключ лимита по вызывающему, которого Auth положил в контекст.
+func byCaller(r *http.Request) string {
+	return data.CallerFrom(r.Context())
+}
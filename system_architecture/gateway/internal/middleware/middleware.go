@@ -0,0 +1,45 @@
+// Package middleware - обработчики, которые есть только у шлюза: проверка API-ключа и
+// ответ об ошибке. Идентификатор запроса, журнал, сжатие и прочее общее берутся из
+// solid/httpmw, лимит запросов - из resilience/ratelimit.
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"solid/data"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+	// CallerHeader передаётся в сервисы за шлюзом вместо ключа: ключ дальше шлюза не уходит.
+	CallerHeader = "X-Caller"
+)
+
+// Auth сопоставляет API-ключ вызывающему и передаёт дальше его имя в X-Caller.
+func Auth(keys map[string]string) func(http.Handler) http.Handler {
+	return AuthFunc(func() map[string]string { return keys })
+}
+
+// AuthFunc - Auth, который берёт ключи у keys на каждом запросе, так что их можно
+// менять без перезапуска.
+func AuthFunc(keys func() map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			caller, ok := keys()[r.Header.Get(APIKeyHeader)]
+			if !ok {
+				Error(w, http.StatusUnauthorized, "unknown API key")
+				return
+			}
+			r.Header.Del(APIKeyHeader)
+			r.Header.Set(CallerHeader, caller)
+			next.ServeHTTP(w, r.WithContext(data.WithCaller(r.Context(), caller)))
+		})
+	}
+}
+
+func Error(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,102 @@
+// Package proxy направляет запросы к сервисам по префиксу пути: /library/books/1
+// уходит в один из экземпляров сервиса библиотеки как /books/1.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+
+	"solid/discovery"
+	"solid/httpmw"
+	"solid/resilience/breaker"
+	"solid/resilience/bulkhead"
+
+	"gateway/internal/middleware"
+)
+
+// Route - префикс шлюза и имя сервиса за ним в реестре.
+type Route struct {
+	Prefix  string
+	Service string
+	// Breaker, если задан, перестаёт пропускать запросы к сервису, пока тот сбоит.
+	Breaker *breaker.Breaker
+	// Bulkhead, если задан, ограничивает число запросов к сервису в полёте, чтобы
+	// медленный сервис не занял все горутины шлюза.
+	Bulkhead *bulkhead.Bulkhead
+}
+
+type attemptKey struct{}
+
+// attempt - выбранный экземпляр и итог проксирования, который ReverseProxy
+// сообщает через ModifyResponse или ErrorHandler.
+type attempt struct {
+	ep  discovery.Endpoint
+	err error
+}
+
+// New возвращает обработчик, который проксирует запросы по первому подходящему маршруту.
+// Экземпляр выбирает Balancer; ошибки соединения и ответы 5xx засчитываются экземпляру
+// и выключателю маршрута. transport nil - http.DefaultTransport.
+func New(routes []Route, b *discovery.Balancer, transport http.RoundTripper, logger *log.Logger) http.Handler {
+	mux := http.NewServeMux()
+	for _, rt := range routes {
+		prefix := "/" + strings.Trim(rt.Prefix, "/")
+		rp := &httputil.ReverseProxy{
+			Transport: transport,
+			Rewrite: func(pr *httputil.ProxyRequest) {
+				ep := pr.In.Context().Value(attemptKey{}).(*attempt).ep
+				pr.SetXForwarded()
+				pr.Out.URL.Scheme = "http"
+				pr.Out.URL.Host = ep.Addr
+				pr.Out.Host = ""
+				pr.Out.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(pr.In.URL.Path, prefix), "/")
+				pr.Out.URL.RawPath = ""
+			},
+			ModifyResponse: func(resp *http.Response) error {
+				a := resp.Request.Context().Value(attemptKey{}).(*attempt)
+				if resp.StatusCode >= 500 {
+					a.err = fmt.Errorf("status %d", resp.StatusCode)
+				}
+				b.Report(rt.Service, a.ep.Addr, a.err)
+				return nil
+			},
+			ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				a := r.Context().Value(attemptKey{}).(*attempt)
+				a.err = err
+				// Клиент ушёл сам - экземпляр в этом не виноват.
+				if !errors.Is(err, context.Canceled) {
+					b.Report(rt.Service, a.ep.Addr, err)
+				}
+				logger.Printf("%s proxy %s via %s: %v", httpmw.RequestIDFrom(r.Context()), rt.Service, a.ep.Addr, err)
+				middleware.Error(w, http.StatusBadGateway, "upstream "+rt.Service+" unavailable")
+			},
+		}
+		mux.Handle(prefix+"/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := rt.Bulkhead.Do(r.Context(), func() error {
+				return rt.Breaker.Do(func() error {
+					ep, err := b.Pick(r.Context(), rt.Service)
+					if err != nil {
+						logger.Printf("%s resolve %s: %v", httpmw.RequestIDFrom(r.Context()), rt.Service, err)
+						middleware.Error(w, http.StatusServiceUnavailable, "no "+rt.Service+" instances")
+						return err
+					}
+					a := &attempt{ep: ep}
+					rp.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), attemptKey{}, a)))
+					return a.err
+				})
+			})
+			switch {
+			case errors.Is(err, breaker.ErrOpen):
+				middleware.Error(w, http.StatusServiceUnavailable, "upstream "+rt.Service+" circuit open")
+			case errors.Is(err, bulkhead.ErrFull), errors.Is(err, bulkhead.ErrTimeout):
+				middleware.Error(w, http.StatusServiceUnavailable, "upstream "+rt.Service+" busy")
+			}
+		}))
+	}
+	return mux
+}
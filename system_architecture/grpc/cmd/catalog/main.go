@@ -0,0 +1,94 @@
+// Команда catalog поднимает сервис каталога gRPC и подключается к сервису цен.
+// Экземпляры цен находит discovery: статический список, DNS SRV или Consul.
+//
+//	catalog -discovery static -target pricing=localhost:9091,localhost:9092 [-tokens t-shop=shop] [-pricing-token t-catalog] [-metrics-addr :9190] [-trace stdout]
+//
+// С -tokens вызовы каталога требуют metadata "authorization: Bearer <токен>";
+// -pricing-token - токен, с которым каталог сам ходит в сервис цен.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"solid/app"
+	"solid/discovery"
+	"solid/metrics"
+	"solid/resilience/retry"
+	"solid/telemetry"
+
+	catalogv1 "grpc/gen/catalog/v1"
+	pricingv1 "grpc/gen/pricing/v1"
+	"grpc/internal/catalog"
+	"grpc/internal/grpcapp"
+	"grpc/internal/grpcdiscovery"
+	"grpc/internal/grpcmw"
+)
+
+func main() {
+	addr := flag.String("addr", ":9090", "gRPC listen address")
+	kind := flag.String("discovery", "static", "service resolver: static, dns or consul")
+	target := flag.String("target", "pricing=localhost:9091", "static services, DNS domain or Consul address")
+	tokens := flag.String("tokens", "", "comma-separated token=caller pairs accepted from clients (no auth if empty)")
+	pricingToken := flag.String("pricing-token", "", "token sent to the pricing service")
+	metricsAddr := flag.String("metrics-addr", "", "HTTP address for Prometheus metrics at /metrics and call counts at /debug/calls (disabled if empty)")
+	traceExporter := flag.String("trace", telemetry.None, "trace exporter: none or stdout")
+	flag.Parse()
+
+	r, err := discovery.New(*kind, *target)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger := log.Default()
+	tp, shutdown, err := telemetry.Setup(telemetry.Config{Service: "catalog", Exporter: *traceExporter})
+	if err != nil {
+		log.Fatal(err)
+	}
+	calls := grpcmw.NewMetrics()
+	reg := metrics.New("catalog")
+	prom := grpcmw.NewPrometheus(reg)
+	// Недоступный экземпляр цен - повод повторить вызов на другом, пока позволяет дедлайн.
+	dial := append(grpcmw.DialOptions(grpcmw.ClientConfig{
+		Logger:     logger,
+		Metrics:    calls,
+		Prometheus: prom,
+		Tracer:     tp,
+		Token:      *pricingToken,
+		Retry:      &retry.Policy{MaxAttempts: 3, Backoff: retry.Exponential(50*time.Millisecond, 500*time.Millisecond)},
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpcdiscovery.NewClient("pricing", r, dial...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := grpcmw.ServerConfig{Logger: logger, Metrics: calls, Prometheus: prom, Tracer: tp}
+	if *tokens != "" {
+		if srv.Tokens, err = grpcmw.ParseTokens(*tokens); err != nil {
+			log.Fatal(err)
+		}
+	}
+	a := app.New(app.Config{Logger: logger})
+	a.Add("telemetry", app.Hook{OnStop: shutdown})
+	a.Add("pricing client", app.Closer(conn.Close))
+	s := grpc.NewServer(grpcmw.ServerOptions(srv)...)
+	catalogv1.RegisterCatalogServiceServer(s, &catalog.Server{
+		Books: map[string]*catalogv1.Book{
+			"dune":     {Id: "dune", Title: "Dune", Author: "Frank Herbert"},
+			"solaris":  {Id: "solaris", Title: "Solaris", Author: "Stanislaw Lem"},
+			"hyperion": {Id: "hyperion", Title: "Hyperion", Author: "Dan Simmons"},
+		},
+		Pricing: pricingv1.NewPricingServiceClient(conn),
+	})
+	grpcapp.Add(a, "grpc", *addr, s)
+	if *metricsAddr != "" {
+		a.HTTP("metrics", grpcapp.MetricsServer(*metricsAddr, reg, calls))
+	}
+	log.Printf("catalog listening on %s, pricing via %s discovery", *addr, *kind)
+	if err := a.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
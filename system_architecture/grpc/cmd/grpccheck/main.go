@@ -0,0 +1,30 @@
+// Команда grpccheck запускает интеграционную проверку каталога и цен на bufconn:
+// коды ошибок, пересчёт валют, передачу дедлайна в сервис цен, балансировку между
+// экземплярами цен по их здоровью и перехватчики (паника, повторы, токены, трассы).
+// Сеть, брокер и внешние процессы не нужны, ненулевой код выхода - проверка не прошла:
+//
+//	go run ./cmd/grpccheck
+//
+// То же на настоящих процессах (discovery static, два экземпляра цен) проверяется
+// руками; отражение на серверах не включено, поэтому grpcurl берёт схему из proto/:
+//
+//	go run ./cmd/pricing -addr :9091 &
+//	go run ./cmd/pricing -addr :9092 &
+//	go run ./cmd/catalog -target pricing=localhost:9091,localhost:9092 &
+//	grpcurl -plaintext -import-path proto -proto catalog/v1/catalog.proto \
+//		-d '{"id":"dune"}' localhost:9090 catalog.v1.CatalogService/GetBook
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"grpc/internal/integration"
+)
+
+func main() {
+	if err := integration.Run(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,76 @@
+// Команда pricing поднимает сервис цен gRPC вместе со стандартной проверкой здоровья,
+// по которой клиенты исключают экземпляр из балансировки.
+//
+//	pricing [-addr :9091] [-latency 0] [-tokens t-catalog=catalog] [-metrics-addr :9191] [-trace stdout]
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"solid/app"
+	"solid/metrics"
+	"solid/telemetry"
+
+	pricingv1 "grpc/gen/pricing/v1"
+	"grpc/internal/grpcapp"
+	"grpc/internal/grpcmw"
+	"grpc/internal/pricing"
+)
+
+func main() {
+	addr := flag.String("addr", ":9091", "gRPC listen address")
+	latency := flag.Duration("latency", 0, "artificial delay per request")
+	tokens := flag.String("tokens", "", "comma-separated token=caller pairs accepted from clients (no auth if empty)")
+	metricsAddr := flag.String("metrics-addr", "", "HTTP address for Prometheus metrics at /metrics and call counts at /debug/calls (disabled if empty)")
+	traceExporter := flag.String("trace", telemetry.None, "trace exporter: none or stdout")
+	flag.Parse()
+
+	logger := log.Default()
+	tp, shutdown, err := telemetry.Setup(telemetry.Config{Service: "pricing", Exporter: *traceExporter})
+	if err != nil {
+		log.Fatal(err)
+	}
+	calls := grpcmw.NewMetrics()
+	reg := metrics.New("pricing")
+	prom := grpcmw.NewPrometheus(reg)
+	srv := grpcmw.ServerConfig{Logger: logger, Metrics: calls, Prometheus: prom, Tracer: tp}
+	if *tokens != "" {
+		if srv.Tokens, err = grpcmw.ParseTokens(*tokens); err != nil {
+			log.Fatal(err)
+		}
+	}
+	s := grpc.NewServer(append(grpcmw.ServerOptions(srv), grpc.ConnectionTimeout(5*time.Second))...)
+	pricingv1.RegisterPricingServiceServer(s, &pricing.Server{
+		Prices:  map[string]int64{"dune": 1599, "solaris": 1250, "hyperion": 1899},
+		Rates:   map[string]int64{"EUR": 920, "GBP": 790},
+		Latency: *latency,
+	})
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	a := app.New(app.Config{Logger: logger})
+	// Добавлен первым - останавливается последним и дописывает спаны остальных.
+	a.Add("telemetry", app.Hook{OnStop: shutdown})
+	grpcapp.Add(a, "grpc", *addr, s)
+	if *metricsAddr != "" {
+		a.HTTP("metrics", grpcapp.MetricsServer(*metricsAddr, reg, calls))
+	}
+	// Останавливается первым: клиенты видят NOT_SERVING и уводят вызовы на другие
+	// экземпляры, пока сервер дорабатывает начатые.
+	a.Add("health", app.Hook{OnStop: func(context.Context) error {
+		hs.Shutdown()
+		return nil
+	}})
+	log.Printf("pricing listening on %s", *addr)
+	if err := a.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
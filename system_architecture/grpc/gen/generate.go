@@ -0,0 +1,5 @@
+// Package gen содержит код, сгенерированный из proto/: сообщения (protoc-gen-go) и
+// клиенты с серверами gRPC (protoc-gen-go-grpc). Руками не править - перегенерировать.
+package gen
+
+//go:generate protoc -I ../proto --go_out=. --go_opt=module=grpc/gen --go-grpc_out=. --go-grpc_opt=module=grpc/gen catalog/v1/catalog.proto pricing/v1/pricing.proto
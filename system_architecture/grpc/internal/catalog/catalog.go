@@ -0,0 +1,69 @@
+// Package catalog - сервер gRPC сервиса каталога. За ценой он ходит в сервис цен тем же
+// контекстом, что пришёл от клиента, поэтому дедлайн клиента действует на всю цепочку;
+// если клиент дедлайна не задал, каталог ставит свой - DefaultTimeout.
+package catalog
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	catalogv1 "grpc/gen/catalog/v1"
+	pricingv1 "grpc/gen/pricing/v1"
+)
+
+const DefaultTimeout = 2 * time.Second
+
+type Server struct {
+	catalogv1.UnimplementedCatalogServiceServer
+
+	Books   map[string]*catalogv1.Book
+	Pricing pricingv1.PricingServiceClient
+}
+
+var _ catalogv1.CatalogServiceServer = (*Server)(nil)
+
+func (s *Server) GetBook(ctx context.Context, req *catalogv1.GetBookRequest) (*catalogv1.GetBookResponse, error) {
+	b, ok := s.Books[req.GetId()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "catalog: book %q not found", req.GetId())
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+	p, err := s.Pricing.GetPrice(ctx, &pricingv1.GetPriceRequest{Sku: b.GetId(), Currency: req.GetCurrency()})
+	if err != nil {
+		return nil, upstream(err)
+	}
+	return &catalogv1.GetBookResponse{Book: b, Price: &catalogv1.Price{Currency: p.GetCurrency(), AmountMinor: p.GetAmountMinor()}}, nil
+}
+
+func (s *Server) ListBooks(ctx context.Context, req *catalogv1.ListBooksRequest) (*catalogv1.ListBooksResponse, error) {
+	resp := &catalogv1.ListBooksResponse{}
+	for _, b := range s.Books {
+		if req.GetAuthor() == "" || b.GetAuthor() == req.GetAuthor() {
+			resp.Books = append(resp.Books, b)
+		}
+	}
+	sort.Slice(resp.Books, func(i, j int) bool { return resp.Books[i].GetId() < resp.Books[j].GetId() })
+	return resp, nil
+}
+
+// upstream переводит ошибку сервиса цен в ответ каталога: ошибки клиента и истёкший
+// дедлайн передаются как есть, остальное - недоступность зависимости.
+func upstream(err error) error {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.InvalidArgument, codes.DeadlineExceeded, codes.Canceled:
+		return st.Err()
+	case codes.NotFound:
+		return status.Errorf(codes.FailedPrecondition, "catalog: book has no price: %s", st.Message())
+	default:
+		return status.Errorf(codes.Unavailable, "catalog: pricing unavailable: %s", st.Message())
+	}
+}
@@ -0,0 +1,43 @@
+// Package grpcapp встраивает gRPC-сервер в жизненный цикл solid/app.
+package grpcapp
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"solid/app"
+	"solid/metrics"
+)
+
+// Add добавляет сервер s на addr. Остановка - GracefulStop, которая дожидается
+// начатых вызовов; не уложилась в дедлайн - Stop обрывает их.
+func Add(a *app.App, name, addr string, s *grpc.Server) {
+	a.Listen(name, addr, s.Serve, func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, func() error {
+		s.Stop()
+		return nil
+	})
+}
+
+// MetricsServer - HTTP-сервер на addr: метрики reg для Prometheus на GET /metrics
+// и снимок вызовов calls на GET /debug/calls.
+func MetricsServer(addr string, reg *metrics.Registry, calls http.Handler) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", reg.Handler())
+	mux.Handle("GET /debug/calls", calls)
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+}
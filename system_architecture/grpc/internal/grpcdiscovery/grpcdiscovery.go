@@ -0,0 +1,111 @@
+// Package grpcdiscovery подключает discovery.Resolver к клиенту gRPC: адреса сервиса
+// берутся из реестра и периодически обновляются, запросы распределяет round_robin
+// по экземплярам, которые проходят стандартную проверку здоровья grpc.health.v1.
+package grpcdiscovery
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/health" // клиентская проверка здоровья для healthCheckConfig
+	"google.golang.org/grpc/resolver"
+
+	"solid/discovery"
+)
+
+// Scheme - схема целевого адреса: discovery:///pricing.
+const Scheme = "discovery"
+
+// ServiceConfig включает round_robin и проверку здоровья экземпляров: экземпляр,
+// ответивший NOT_SERVING, исключается из выбора, пока не вернётся в SERVING.
+const ServiceConfig = `{"loadBalancingConfig":[{"round_robin":{}}],"healthCheckConfig":{"serviceName":""}}`
+
+// Builder - resolver.Builder поверх discovery.Resolver.
+type Builder struct {
+	Resolver discovery.Resolver
+	// Interval - как часто перечитывать реестр, по умолчанию 10 секунд.
+	Interval time.Duration
+}
+
+var _ resolver.Builder = (*Builder)(nil)
+
+func (b *Builder) Scheme() string { return Scheme }
+
+func (b *Builder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	interval := b.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &watcher{service: target.Endpoint(), src: b.Resolver, cc: cc, cancel: cancel, now: make(chan struct{}, 1)}
+	r.wg.Add(1)
+	go r.run(ctx, interval)
+	return r, nil
+}
+
+type watcher struct {
+	service string
+	src     discovery.Resolver
+	cc      resolver.ClientConn
+	cancel  context.CancelFunc
+	now     chan struct{}
+	wg      sync.WaitGroup
+}
+
+func (w *watcher) run(ctx context.Context, interval time.Duration) {
+	defer w.wg.Done()
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		w.update(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-w.now:
+		}
+	}
+}
+
+func (w *watcher) update(ctx context.Context) {
+	rctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	eps, err := w.src.Resolve(rctx, w.service)
+	if err != nil {
+		if ctx.Err() == nil {
+			// При ошибке gRPC продолжает работать по последнему известному списку.
+			w.cc.ReportError(err)
+		}
+		return
+	}
+	addrs := make([]resolver.Address, len(eps))
+	for i, e := range eps {
+		addrs[i] = resolver.Address{Addr: e.Addr}
+	}
+	w.cc.UpdateState(resolver.State{Addresses: addrs})
+}
+
+// ResolveNow вызывается gRPC, когда соединения рвутся: перечитываем реестр вне очереди.
+func (w *watcher) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case w.now <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watcher) Close() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// NewClient подключается к сервису service через r; opts дополняют или
+// переопределяют настройки по умолчанию.
+func NewClient(service string, r discovery.Resolver, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	base := []grpc.DialOption{
+		grpc.WithResolvers(&Builder{Resolver: r}),
+		grpc.WithDefaultServiceConfig(ServiceConfig),
+	}
+	return grpc.NewClient(Scheme+":///"+service, append(base, opts...)...)
+}
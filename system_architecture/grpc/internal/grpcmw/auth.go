@@ -0,0 +1,132 @@
+package grpcmw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"solid/data"
+)
+
+// healthService - стандартная проверка здоровья: балансировщик токена не знает.
+const healthService = "/grpc.health.v1.Health/"
+
+// ErrUnknownToken - токен не знаком Validator.
+var ErrUnknownToken = errors.New("grpcmw: unknown token")
+
+// Validator сопоставляет токен вызывающему.
+type Validator interface {
+	Validate(ctx context.Context, token string) (caller string, err error)
+}
+
+// Tokens - Validator по фиксированной таблице токен -> вызывающий.
+type Tokens map[string]string
+
+var _ Validator = Tokens(nil)
+
+func (t Tokens) Validate(_ context.Context, token string) (string, error) {
+	caller, ok := t[token]
+	if !ok {
+		return "", ErrUnknownToken
+	}
+	return caller, nil
+}
+
+// ParseTokens разбирает таблицу токенов из флага: "t-shop=shop,t-admin=admin".
+func ParseTokens(s string) (Tokens, error) {
+	t := Tokens{}
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		token, caller, ok := strings.Cut(pair, "=")
+		token, caller = strings.TrimSpace(token), strings.TrimSpace(caller)
+		if !ok || token == "" || caller == "" {
+			return nil, fmt.Errorf("grpcmw: invalid token pair %q, want token=caller", pair)
+		}
+		t[token] = caller
+	}
+	return t, nil
+}
+
+// UnaryAuth требует metadata "authorization: Bearer <токен>" и кладёт вызывающего
+// в контекст (data.CallerFrom). Методы public пропускаются без токена.
+func UnaryAuth(v Validator, public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if isPublic(info.FullMethod, public) {
+			return next(ctx, req)
+		}
+		ctx, err := authenticate(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func StreamAuth(v Validator, public ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		if isPublic(info.FullMethod, public) {
+			return next(srv, ss)
+		}
+		ctx, err := authenticate(ss.Context(), v)
+		if err != nil {
+			return err
+		}
+		return next(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, v Validator) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization token")
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization must be a Bearer token")
+	}
+	caller, err := v.Validate(ctx, token)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+	return data.WithCaller(ctx, caller), nil
+}
+
+func isPublic(method string, public []string) bool {
+	for _, p := range public {
+		if method == p || strings.HasSuffix(p, "/") && strings.HasPrefix(method, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// serverStream подменяет контекст потока - с вызывающим внутри.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+// Token - токен клиента, который gRPC кладёт в metadata каждого вызова. Пример ходит
+// без TLS, поэтому токен не требует защищённого канала; в бою его стоит передавать
+// только поверх TLS.
+type Token string
+
+var _ credentials.PerRPCCredentials = Token("")
+
+func (t Token) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t Token) RequireTransportSecurity() bool { return false }
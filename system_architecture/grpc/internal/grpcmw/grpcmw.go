@@ -0,0 +1,217 @@
+// Package grpcmw - общие перехватчики gRPC: трассировка, журнал, метрики, проверка
+// токена, перехват паник на сервере и повтор недоступных вызовов на клиенте.
+// ServerOptions и DialOptions собирают их в цепочку в правильном порядке:
+//
+//	сервер: трасса -> журнал -> метрики -> паника -> токен -> обработчик
+//	клиент: трасса -> журнал -> метрики -> повтор -> токен в метаданных -> сеть
+//
+// Журнал и метрики снаружи, поэтому видят итоговый код - в том числе Internal после
+// паники и Unauthenticated от проверки токена.
+package grpcmw
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"solid/resilience/retry"
+)
+
+type ServerConfig struct {
+	// Logger по умолчанию - log.Default().
+	Logger *log.Logger
+	// Tracer, если задан, продолжает трассу клиента спаном на каждый вызов.
+	Tracer trace.TracerProvider
+	// Metrics, если задан, считает вызовы сервера.
+	Metrics *Metrics
+	// Prometheus, если задан, считает их же в метриках Prometheus.
+	Prometheus *Prometheus
+	// Tokens проверяет токен вызывающего; nil - вызовы без проверки.
+	Tokens Validator
+	// Public - методы, открытые без токена: полное имя "/pkg.Service/Method" или
+	// префикс сервиса "/pkg.Service/". Проверка здоровья открыта всегда.
+	Public []string
+}
+
+// ServerOptions - перехватчики сервера для grpc.NewServer.
+func ServerOptions(cfg ServerConfig) []grpc.ServerOption {
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	if cfg.Tracer != nil {
+		unary = append(unary, UnaryServerTracing(cfg.Tracer))
+		stream = append(stream, StreamServerTracing(cfg.Tracer))
+	}
+	unary = append(unary, UnaryServerLogging(cfg.Logger))
+	stream = append(stream, StreamServerLogging(cfg.Logger))
+	if cfg.Metrics != nil {
+		unary = append(unary, cfg.Metrics.UnaryServer())
+		stream = append(stream, cfg.Metrics.StreamServer())
+	}
+	if cfg.Prometheus != nil {
+		unary = append(unary, cfg.Prometheus.UnaryServer())
+		stream = append(stream, cfg.Prometheus.StreamServer())
+	}
+	unary = append(unary, UnaryRecover(cfg.Logger))
+	stream = append(stream, StreamRecover(cfg.Logger))
+	if cfg.Tokens != nil {
+		public := append([]string{healthService}, cfg.Public...)
+		unary = append(unary, UnaryAuth(cfg.Tokens, public...))
+		stream = append(stream, StreamAuth(cfg.Tokens, public...))
+	}
+	return []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...)}
+}
+
+type ClientConfig struct {
+	// Logger по умолчанию - log.Default().
+	Logger *log.Logger
+	// Tracer, если задан, передаёт трассу сервису в метаданных.
+	Tracer trace.TracerProvider
+	// Metrics, если задан, считает исходящие вызовы.
+	Metrics *Metrics
+	// Prometheus, если задан, считает их же в метриках Prometheus.
+	Prometheus *Prometheus
+	// Token передаётся сервису в metadata authorization; пустой - без токена.
+	Token string
+	// Retry, если задан, повторяет unary-вызовы, отказавшие с кодом Unavailable.
+	Retry *retry.Policy
+}
+
+// DialOptions - перехватчики клиента для grpc.NewClient.
+func DialOptions(cfg ClientConfig) []grpc.DialOption {
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	var unary []grpc.UnaryClientInterceptor
+	var stream []grpc.StreamClientInterceptor
+	if cfg.Tracer != nil {
+		unary = append(unary, UnaryClientTracing(cfg.Tracer))
+		stream = append(stream, StreamClientTracing(cfg.Tracer))
+	}
+	unary = append(unary, UnaryClientLogging(cfg.Logger))
+	stream = append(stream, StreamClientLogging(cfg.Logger))
+	if cfg.Metrics != nil {
+		unary = append(unary, cfg.Metrics.UnaryClient())
+		stream = append(stream, cfg.Metrics.StreamClient())
+	}
+	if cfg.Prometheus != nil {
+		unary = append(unary, cfg.Prometheus.UnaryClient())
+		stream = append(stream, cfg.Prometheus.StreamClient())
+	}
+	if cfg.Retry != nil {
+		unary = append(unary, UnaryRetry(*cfg.Retry, cfg.Logger))
+	}
+	opts := []grpc.DialOption{grpc.WithChainUnaryInterceptor(unary...), grpc.WithChainStreamInterceptor(stream...)}
+	if cfg.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(Token(cfg.Token)))
+	}
+	return opts
+}
+
+// UnaryServerLogging пишет строку на вызов: метод, код и время.
+func UnaryServerLogging(logger *log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+		logCall(logger, "server", info.FullMethod, err, start)
+		return resp, err
+	}
+}
+
+func StreamServerLogging(logger *log.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		start := time.Now()
+		err := next(srv, ss)
+		logCall(logger, "server", info.FullMethod, err, start)
+		return err
+	}
+}
+
+func UnaryClientLogging(logger *log.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logCall(logger, "client", method, err, start)
+		return err
+	}
+}
+
+// StreamClientLogging пишет строку, когда поток открыт или открыть его не удалось:
+// дальше поток живёт без перехватчика.
+func StreamClientLogging(logger *log.Logger) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		logCall(logger, "client stream", method, err, start)
+		return cs, err
+	}
+}
+
+func logCall(logger *log.Logger, side, method string, err error, start time.Time) {
+	st := status.Convert(err)
+	if st.Code() == codes.OK {
+		logger.Printf("grpc %s %s %s %s", side, method, st.Code(), time.Since(start).Round(time.Millisecond))
+		return
+	}
+	logger.Printf("grpc %s %s %s %s: %s", side, method, st.Code(), time.Since(start).Round(time.Millisecond), st.Message())
+}
+
+// UnaryRecover превращает панику обработчика в ответ Internal и пишет её в журнал
+// со стеком: упавший вызов не роняет сервер со всеми остальными.
+func UnaryRecover(logger *log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(logger, info.FullMethod, p)
+			}
+		}()
+		return next(ctx, req)
+	}
+}
+
+func StreamRecover(logger *log.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(logger, info.FullMethod, p)
+			}
+		}()
+		return next(srv, ss)
+	}
+}
+
+func recovered(logger *log.Logger, method string, p any) error {
+	logger.Printf("grpc panic: %s: %v\n%s", method, p, debug.Stack())
+	return status.Error(codes.Internal, "internal error")
+}
+
+// UnaryRetry повторяет вызов по политике p, если сервис ответил Unavailable: такой
+// отказ значит, что запрос до обработчика не дошёл или экземпляр уходит, и повтор
+// безопасен. Каждая попытка укладывается в дедлайн исходного вызова. Потоки не
+// повторяются - часть сообщений уже могла быть прочитана.
+func UnaryRetry(p retry.Policy, logger *log.Logger) grpc.UnaryClientInterceptor {
+	if p.Retryable == nil {
+		p.Retryable = func(err error) bool { return status.Code(err) == codes.Unavailable }
+	}
+	onRetry := p.OnRetry
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		p := p
+		p.OnRetry = func(attempt int, err error, delay time.Duration) {
+			logger.Printf("grpc retry %s: attempt %d failed (%s), next in %s", method, attempt, status.Code(err), delay.Round(time.Millisecond))
+			if onRetry != nil {
+				onRetry(attempt, err, delay)
+			}
+		}
+		return retry.Do(ctx, p, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package grpcmw
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics считает вызовы по методам отдельно для сервера и клиента: сколько, с каким
+// кодом и сколько они длились. Отдаётся снимком JSON - Metrics сам по себе
+// http.Handler, который можно повесить на порт метрик.
+type Metrics struct {
+	mu     sync.Mutex
+	server map[string]*stat
+	client map[string]*stat
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{server: map[string]*stat{}, client: map[string]*stat{}}
+}
+
+type stat struct {
+	calls int64
+	codes map[string]int64
+	total time.Duration
+	max   time.Duration
+}
+
+// MethodStats - счётчики одного метода.
+type MethodStats struct {
+	Calls int64            `json:"calls"`
+	Codes map[string]int64 `json:"codes"`
+	Mean  string           `json:"mean"`
+	Max   string           `json:"max"`
+}
+
+// Snapshot - копия счётчиков на момент вызова.
+type Snapshot struct {
+	Server map[string]MethodStats `json:"server"`
+	Client map[string]MethodStats `json:"client"`
+}
+
+func (m *Metrics) Snapshot() Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return Snapshot{Server: copyStats(m.server), Client: copyStats(m.client)}
+}
+
+func copyStats(stats map[string]*stat) map[string]MethodStats {
+	out := make(map[string]MethodStats, len(stats))
+	for method, s := range stats {
+		codes := make(map[string]int64, len(s.codes))
+		for c, n := range s.codes {
+			codes[c] = n
+		}
+		out[method] = MethodStats{
+			Calls: s.calls,
+			Codes: codes,
+			Mean:  (s.total / time.Duration(s.calls)).Round(time.Microsecond).String(),
+			Max:   s.max.Round(time.Microsecond).String(),
+		}
+	}
+	return out
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m.Snapshot())
+}
+
+func (m *Metrics) observe(stats map[string]*stat, method string, err error, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := stats[method]
+	if !ok {
+		s = &stat{codes: map[string]int64{}}
+		stats[method] = s
+	}
+	s.calls++
+	s.codes[status.Code(err).String()]++
+	s.total += d
+	s.max = max(s.max, d)
+}
+
+func (m *Metrics) UnaryServer() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+		m.observe(m.server, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+func (m *Metrics) StreamServer() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		start := time.Now()
+		err := next(srv, ss)
+		m.observe(m.server, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+// UnaryClient считает вызов целиком, со всеми повторами внутри.
+func (m *Metrics) UnaryClient() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.observe(m.client, method, err, time.Since(start))
+		return err
+	}
+}
+
+// StreamClient считает открытие потока, как и StreamClientLogging.
+func (m *Metrics) StreamClient() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		m.observe(m.client, method, err, time.Since(start))
+		return cs, err
+	}
+}
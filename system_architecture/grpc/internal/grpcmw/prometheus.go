@@ -0,0 +1,77 @@
+package grpcmw
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"solid/metrics"
+)
+
+// Prometheus считает вызовы в grpc_server_* и grpc_client_* реестра с метками
+// service, method и code - RED-метрики для дашбордов, в отличие от снимка Metrics.
+type Prometheus struct {
+	server *metrics.RED
+	client *metrics.RED
+}
+
+func NewPrometheus(r *metrics.Registry) *Prometheus {
+	return &Prometheus{
+		server: r.RED("grpc_server", "service", "method", "code"),
+		client: r.RED("grpc_client", "service", "method", "code"),
+	}
+}
+
+// serverFault - коды, которые говорят о сбое сервиса, а не о неверном запросе.
+var serverFault = map[codes.Code]bool{
+	codes.Unknown: true, codes.DeadlineExceeded: true, codes.Unimplemented: true,
+	codes.Internal: true, codes.Unavailable: true, codes.DataLoss: true,
+}
+
+func observe(red *metrics.RED, start time.Time, fullMethod string, err error) {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	code := status.Code(err)
+	red.Observe(start, serverFault[code], service, method, code.String())
+}
+
+func (p *Prometheus) UnaryServer() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := next(ctx, req)
+		observe(p.server, start, info.FullMethod, err)
+		return resp, err
+	}
+}
+
+func (p *Prometheus) StreamServer() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		start := time.Now()
+		err := next(srv, ss)
+		observe(p.server, start, info.FullMethod, err)
+		return err
+	}
+}
+
+// UnaryClient считает вызов целиком, со всеми повторами внутри.
+func (p *Prometheus) UnaryClient() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		observe(p.client, start, method, err)
+		return err
+	}
+}
+
+// StreamClient считает открытие потока.
+func (p *Prometheus) StreamClient() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		observe(p.client, start, method, err)
+		return cs, err
+	}
+}
@@ -0,0 +1,108 @@
+package grpcmw
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"solid/telemetry"
+)
+
+// metadataCarrier переносит контекст трассы в метаданных gRPC так же, как в заголовках HTTP.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) { metadata.MD(c).Set(key, value) }
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// UnaryServerTracing начинает серверный спан вызова продолжением трассы клиента.
+func UnaryServerTracing(tp trace.TracerProvider) grpc.UnaryServerInterceptor {
+	tracer := telemetry.Tracer(tp)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		ctx, span := startServer(ctx, tracer, info.FullMethod)
+		resp, err := next(ctx, req)
+		endRPC(span, err)
+		return resp, err
+	}
+}
+
+func StreamServerTracing(tp trace.TracerProvider) grpc.StreamServerInterceptor {
+	tracer := telemetry.Tracer(tp)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		ctx, span := startServer(ss.Context(), tracer, info.FullMethod)
+		err := next(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		endRPC(span, err)
+		return err
+	}
+}
+
+// UnaryClientTracing начинает клиентский спан и передаёт его контекст в метаданных.
+func UnaryClientTracing(tp trace.TracerProvider) grpc.UnaryClientInterceptor {
+	tracer := telemetry.Tracer(tp)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := startClient(ctx, tracer, method)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		endRPC(span, err)
+		return err
+	}
+}
+
+// StreamClientTracing покрывает спаном открытие потока.
+func StreamClientTracing(tp trace.TracerProvider) grpc.StreamClientInterceptor {
+	tracer := telemetry.Tracer(tp)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := startClient(ctx, tracer, method)
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		endRPC(span, err)
+		return cs, err
+	}
+}
+
+func startServer(ctx context.Context, tracer trace.Tracer, method string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = telemetry.Extract(ctx, metadataCarrier(md))
+	return tracer.Start(ctx, strings.TrimPrefix(method, "/"), trace.WithSpanKind(trace.SpanKindServer), rpcAttributes(method))
+}
+
+func startClient(ctx context.Context, tracer trace.Tracer, method string) (context.Context, trace.Span) {
+	ctx, span := tracer.Start(ctx, strings.TrimPrefix(method, "/"), trace.WithSpanKind(trace.SpanKindClient), rpcAttributes(method))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	telemetry.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// rpcAttributes раскладывает "/pkg.Service/Method" на rpc.service и rpc.method.
+func rpcAttributes(method string) trace.SpanStartEventOption {
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return trace.WithAttributes(attribute.String("rpc.system", "grpc"), attribute.String("rpc.service", service), attribute.String("rpc.method", name))
+}
+
+func endRPC(span trace.Span, err error) {
+	st := status.Convert(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(st.Code())))
+	if st.Code() != codes.OK {
+		span.SetStatus(otelcodes.Error, st.Message())
+	}
+	span.End()
+}
@@ -0,0 +1,116 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"solid/discovery"
+
+	pricingv1 "grpc/gen/pricing/v1"
+	"grpc/internal/grpcdiscovery"
+	"grpc/internal/pricing"
+)
+
+// balancing поднимает два экземпляра цен под одним именем в статическом реестре и
+// проверяет, что клиент распределяет запросы между ними, а экземпляр в NOT_SERVING
+// перестаёт получать запросы.
+func balancing(w io.Writer) error {
+	addrs := []string{"pricing-a:9091", "pricing-b:9091"}
+	listeners := map[string]*bufconn.Listener{}
+	healths := map[string]*health.Server{}
+	var mu sync.Mutex
+	hits := map[string]int{}
+	for _, addr := range addrs {
+		lis := bufconn.Listen(1 << 20)
+		count := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+			mu.Lock()
+			hits[addr]++
+			mu.Unlock()
+			return next(ctx, req)
+		}
+		s := grpc.NewServer(grpc.UnaryInterceptor(count))
+		pricingv1.RegisterPricingServiceServer(s, &pricing.Server{Prices: map[string]int64{"dune": 1599}})
+		hs := health.NewServer()
+		healthpb.RegisterHealthServer(s, hs)
+		go s.Serve(lis)
+		defer s.Stop()
+		listeners[addr], healths[addr] = lis, hs
+	}
+
+	conn, err := grpcdiscovery.NewClient("pricing", discovery.Static{"pricing": addrs},
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			lis, ok := listeners[addr]
+			if !ok {
+				return nil, fmt.Errorf("integration: no listener for %s", addr)
+			}
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	client := pricingv1.NewPricingServiceClient(conn)
+
+	calls := func(n int) (map[string]int, error) {
+		mu.Lock()
+		clear(hits)
+		mu.Unlock()
+		for i := 0; i < n; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			_, err := client.GetPrice(ctx, &pricingv1.GetPriceRequest{Sku: "dune"})
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("integration: balanced call: %w", err)
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return map[string]int{addrs[0]: hits[addrs[0]], addrs[1]: hits[addrs[1]]}, nil
+	}
+
+	// round_robin включает экземпляр по мере готовности соединения, поэтому первые
+	// запросы могут уйти в один; ждём, пока в ротации окажутся оба.
+	deadline := time.Now().Add(2 * time.Second)
+	var got map[string]int
+	for {
+		if got, err = calls(10); err != nil {
+			return err
+		}
+		if got[addrs[0]] == 5 && got[addrs[1]] == 5 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("integration: calls split %v, want 5/5", got)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	fmt.Fprintf(w, "ok   %-22s %v\n", "round robin", got)
+
+	healths[addrs[1]].SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	deadline = time.Now().Add(2 * time.Second)
+	for {
+		if got, err = calls(10); err != nil {
+			return err
+		}
+		if got[addrs[1]] == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("integration: NOT_SERVING instance still got %d calls", got[addrs[1]])
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	fmt.Fprintf(w, "ok   %-22s %v\n", "unhealthy excluded", got)
+	return nil
+}
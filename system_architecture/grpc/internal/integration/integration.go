@@ -0,0 +1,173 @@
+// Package integration поднимает оба сервиса на bufconn - соединениях в памяти без сети -
+// и проверяет их вместе так же, как их видит настоящий клиент gRPC.
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	catalogv1 "grpc/gen/catalog/v1"
+	pricingv1 "grpc/gen/pricing/v1"
+	"grpc/internal/catalog"
+	"grpc/internal/pricing"
+)
+
+// Env - пара запущенных серверов и клиент каталога.
+type Env struct {
+	Catalog catalogv1.CatalogServiceClient
+	Pricing *pricing.Server
+
+	mu        sync.Mutex
+	deadlines []time.Duration
+	closers   []func()
+}
+
+// PricingDeadlines возвращает остаток дедлайна, с которым запросы дошли до сервиса цен.
+func (e *Env) PricingDeadlines() []time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]time.Duration(nil), e.deadlines...)
+}
+
+func (e *Env) Close() {
+	for i := len(e.closers) - 1; i >= 0; i-- {
+		e.closers[i]()
+	}
+}
+
+func Start() (*Env, error) {
+	env := &Env{Pricing: &pricing.Server{
+		Prices: map[string]int64{"dune": 1599, "solaris": 1250},
+		Rates:  map[string]int64{"EUR": 920},
+	}}
+	record := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if d, ok := ctx.Deadline(); ok {
+			env.mu.Lock()
+			env.deadlines = append(env.deadlines, time.Until(d))
+			env.mu.Unlock()
+		}
+		return next(ctx, req)
+	}
+	pricingConn, err := env.serve(grpc.NewServer(grpc.UnaryInterceptor(record)), func(s *grpc.Server) {
+		pricingv1.RegisterPricingServiceServer(s, env.Pricing)
+	})
+	if err != nil {
+		return nil, err
+	}
+	catalogConn, err := env.serve(grpc.NewServer(), func(s *grpc.Server) {
+		catalogv1.RegisterCatalogServiceServer(s, &catalog.Server{
+			Books: map[string]*catalogv1.Book{
+				"dune":    {Id: "dune", Title: "Dune", Author: "Frank Herbert"},
+				"solaris": {Id: "solaris", Title: "Solaris", Author: "Stanislaw Lem"},
+				"draft":   {Id: "draft", Title: "Untitled", Author: "Frank Herbert"},
+			},
+			Pricing: pricingv1.NewPricingServiceClient(pricingConn),
+		})
+	})
+	if err != nil {
+		env.Close()
+		return nil, err
+	}
+	env.Catalog = catalogv1.NewCatalogServiceClient(catalogConn)
+	return env, nil
+}
+
+// serve запускает сервер на собственном bufconn и возвращает клиентское соединение к нему.
+func (e *Env) serve(s *grpc.Server, register func(*grpc.Server)) (*grpc.ClientConn, error) {
+	lis := bufconn.Listen(1 << 20)
+	register(s)
+	go s.Serve(lis)
+	e.closers = append(e.closers, s.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	e.closers = append(e.closers, func() { conn.Close() })
+	return conn, nil
+}
+
+// Run проверяет контракт пары сервисов и пишет по строке на сценарий.
+func Run(w io.Writer) error {
+	env, err := Start()
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+	ctx := context.Background()
+
+	call := func(name string, timeout time.Duration, req *catalogv1.GetBookRequest, want codes.Code) (*catalogv1.GetBookResponse, error) {
+		cctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		resp, err := env.Catalog.GetBook(cctx, req)
+		if got := status.Code(err); got != want {
+			return nil, fmt.Errorf("integration: %s: got %s (%v), want %s", name, got, err, want)
+		}
+		fmt.Fprintf(w, "ok   %-22s %s\n", name, status.Code(err))
+		return resp, nil
+	}
+
+	resp, err := call("get book", time.Second, &catalogv1.GetBookRequest{Id: "dune"}, codes.OK)
+	if err != nil {
+		return err
+	}
+	if resp.GetBook().GetTitle() != "Dune" || resp.GetPrice().GetAmountMinor() != 1599 || resp.GetPrice().GetCurrency() != "USD" {
+		return fmt.Errorf("integration: get book: unexpected %v", resp)
+	}
+	if resp, err = call("get book in EUR", time.Second, &catalogv1.GetBookRequest{Id: "dune", Currency: "eur"}, codes.OK); err != nil {
+		return err
+	}
+	if resp.GetPrice().GetAmountMinor() != 1471 {
+		return fmt.Errorf("integration: EUR price %d, want 1471", resp.GetPrice().GetAmountMinor())
+	}
+	if _, err := call("unknown book", time.Second, &catalogv1.GetBookRequest{Id: "nope"}, codes.NotFound); err != nil {
+		return err
+	}
+	if _, err := call("book without price", time.Second, &catalogv1.GetBookRequest{Id: "draft"}, codes.FailedPrecondition); err != nil {
+		return err
+	}
+	if _, err := call("unknown currency", time.Second, &catalogv1.GetBookRequest{Id: "dune", Currency: "XXX"}, codes.InvalidArgument); err != nil {
+		return err
+	}
+
+	// Дедлайн клиента доходит до сервиса цен через каталог и обрывает медленный ответ.
+	env.Pricing.SetLatency(200 * time.Millisecond)
+	if _, err := call("slow pricing", 50*time.Millisecond, &catalogv1.GetBookRequest{Id: "solaris"}, codes.DeadlineExceeded); err != nil {
+		return err
+	}
+	env.Pricing.SetLatency(0)
+	ds := env.PricingDeadlines()
+	if len(ds) == 0 || ds[len(ds)-1] > 50*time.Millisecond {
+		return fmt.Errorf("integration: pricing deadlines %v, want the last one under 50ms", ds)
+	}
+	for _, d := range ds[:len(ds)-1] {
+		if d > time.Second {
+			return fmt.Errorf("integration: pricing saw deadline %s beyond the client's 1s", d)
+		}
+	}
+	fmt.Fprintf(w, "ok   %-22s %d pricing calls within the client deadline\n", "deadline propagated", len(ds))
+
+	list, err := env.Catalog.ListBooks(ctx, &catalogv1.ListBooksRequest{Author: "Frank Herbert"})
+	if err != nil {
+		return err
+	}
+	if len(list.GetBooks()) != 2 {
+		return fmt.Errorf("integration: list by author: got %d books, want 2", len(list.GetBooks()))
+	}
+	fmt.Fprintf(w, "ok   %-22s %d books\n", "list by author", len(list.GetBooks()))
+	if err := balancing(w); err != nil {
+		return err
+	}
+	return interceptors(w)
+}
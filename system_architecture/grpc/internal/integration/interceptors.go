@@ -0,0 +1,173 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"solid/data"
+	"solid/resilience/retry"
+
+	pricingv1 "grpc/gen/pricing/v1"
+	"grpc/internal/grpcmw"
+)
+
+// trickyPricing паникует на SKU "panic" и первые два раза отвечает Unavailable на
+// SKU "flaky", запоминая, от чьего имени и в какой трассе пришёл вызов.
+type trickyPricing struct {
+	pricingv1.UnimplementedPricingServiceServer
+
+	mu      sync.Mutex
+	flaky   int
+	callers []string
+	trace   trace.TraceID
+}
+
+func (p *trickyPricing) GetPrice(ctx context.Context, req *pricingv1.GetPriceRequest) (*pricingv1.GetPriceResponse, error) {
+	p.mu.Lock()
+	p.callers = append(p.callers, data.CallerFrom(ctx))
+	p.trace = trace.SpanContextFromContext(ctx).TraceID()
+	p.mu.Unlock()
+	switch req.GetSku() {
+	case "panic":
+		panic("pricing table is corrupted")
+	case "flaky":
+		p.mu.Lock()
+		p.flaky++
+		n := p.flaky
+		p.mu.Unlock()
+		if n <= 2 {
+			return nil, status.Error(codes.Unavailable, "pricing: warming up")
+		}
+	}
+	return &pricingv1.GetPriceResponse{Sku: req.GetSku(), Currency: "USD", AmountMinor: 100}, nil
+}
+
+// interceptors проверяет перехватчики grpcmw: токен, перехват паники, повтор
+// Unavailable на клиенте, счётчики вызовов и перенос трассы в метаданных.
+func interceptors(w io.Writer) error {
+	logger := log.New(io.Discard, "", 0)
+	metrics := grpcmw.NewMetrics()
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(context.Background())
+	svc := &trickyPricing{}
+	s := grpc.NewServer(grpcmw.ServerOptions(grpcmw.ServerConfig{
+		Logger:  logger,
+		Metrics: metrics,
+		Tracer:  tp,
+		Tokens:  grpcmw.Tokens{"t-catalog": "catalog"},
+	})...)
+	pricingv1.RegisterPricingServiceServer(s, svc)
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+	lis := bufconn.Listen(1 << 20)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	dial := func(cfg grpcmw.ClientConfig) (*grpc.ClientConn, error) {
+		cfg.Logger = logger
+		opts := append(grpcmw.DialOptions(cfg),
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		return grpc.NewClient("passthrough:///bufconn", opts...)
+	}
+	anon, err := dial(grpcmw.ClientConfig{})
+	if err != nil {
+		return err
+	}
+	defer anon.Close()
+	authed, err := dial(grpcmw.ClientConfig{Token: "t-catalog", Metrics: metrics, Tracer: tp,
+		Retry: &retry.Policy{MaxAttempts: 3, Backoff: retry.Constant(10 * time.Millisecond)}})
+	if err != nil {
+		return err
+	}
+	defer authed.Close()
+	noRetry, err := dial(grpcmw.ClientConfig{Token: "t-catalog"})
+	if err != nil {
+		return err
+	}
+	defer noRetry.Close()
+
+	price := func(name string, conn *grpc.ClientConn, sku string, want codes.Code) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := pricingv1.NewPricingServiceClient(conn).GetPrice(ctx, &pricingv1.GetPriceRequest{Sku: sku})
+		if got := status.Code(err); got != want {
+			return fmt.Errorf("integration: %s: got %s (%v), want %s", name, got, err, want)
+		}
+		fmt.Fprintf(w, "ok   %-22s %s\n", name, status.Code(err))
+		return nil
+	}
+
+	if err := price("no token", anon, "dune", codes.Unauthenticated); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := healthpb.NewHealthClient(anon).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		return fmt.Errorf("integration: health without token: %w", err)
+	}
+	fmt.Fprintf(w, "ok   %-22s %s\n", "health without token", codes.OK)
+	if err := price("with token", authed, "dune", codes.OK); err != nil {
+		return err
+	}
+	svc.mu.Lock()
+	callers := append([]string(nil), svc.callers...)
+	svc.mu.Unlock()
+	if len(callers) != 1 || callers[0] != "catalog" {
+		return fmt.Errorf("integration: handler saw callers %v, want [catalog]", callers)
+	}
+	if err := price("panic recovered", authed, "panic", codes.Internal); err != nil {
+		return err
+	}
+	if err := price("after panic", authed, "dune", codes.OK); err != nil {
+		return err
+	}
+	if err := price("unavailable, no retry", noRetry, "flaky", codes.Unavailable); err != nil {
+		return err
+	}
+	// Второй отказ достаётся клиенту с повтором, третий вызов уже успешен.
+	if err := price("unavailable, retried", authed, "flaky", codes.OK); err != nil {
+		return err
+	}
+
+	snap := metrics.Snapshot()
+	const method = "/pricing.v1.PricingService/GetPrice"
+	client, server := snap.Client[method], snap.Server[method]
+	if client.Calls != 4 || client.Codes["Internal"] != 1 {
+		return fmt.Errorf("integration: client metrics %+v, want 4 calls with one Internal", client)
+	}
+	if server.Calls != 7 || server.Codes["Unauthenticated"] != 1 || server.Codes["Unavailable"] != 2 {
+		return fmt.Errorf("integration: server metrics %+v, want 7 calls with 1 Unauthenticated and 2 Unavailable", server)
+	}
+	fmt.Fprintf(w, "ok   %-22s client %d calls, server %d calls %v\n", "metrics", client.Calls, server.Calls, server.Codes)
+
+	root, span := tp.Tracer("integration").Start(ctx, "offer")
+	_, err = pricingv1.NewPricingServiceClient(authed).GetPrice(root, &pricingv1.GetPriceRequest{Sku: "dune"})
+	span.End()
+	if err != nil {
+		return fmt.Errorf("integration: traced call: %w", err)
+	}
+	svc.mu.Lock()
+	got := svc.trace
+	svc.mu.Unlock()
+	if want := span.SpanContext().TraceID(); got != want {
+		return fmt.Errorf("integration: handler saw trace %s, want %s", got, want)
+	}
+	fmt.Fprintf(w, "ok   %-22s %s\n", "trace propagated", got)
+	return nil
+}
@@ -0,0 +1,69 @@
+// Package pricing - сервер gRPC сервиса цен. Цены хранятся в долларах, прочие валюты
+// считаются по фиксированному курсу. Latency имитирует медленную зависимость, чтобы
+// было видно, как дедлайн вызывающего обрывает запрос.
+package pricing
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pricingv1 "grpc/gen/pricing/v1"
+)
+
+type Server struct {
+	pricingv1.UnimplementedPricingServiceServer
+
+	// Prices - цена по SKU в центах USD.
+	Prices map[string]int64
+	// Rates - сколько единиц валюты за один доллар, с точностью до тысячных (×1000).
+	Rates map[string]int64
+	// Latency задаётся до запуска; на работающем сервере её меняет SetLatency.
+	Latency time.Duration
+
+	mu sync.Mutex
+}
+
+var _ pricingv1.PricingServiceServer = (*Server)(nil)
+
+func (s *Server) GetPrice(ctx context.Context, req *pricingv1.GetPriceRequest) (*pricingv1.GetPriceResponse, error) {
+	if req.GetSku() == "" {
+		return nil, status.Error(codes.InvalidArgument, "pricing: sku is required")
+	}
+	s.mu.Lock()
+	latency := s.Latency
+	s.mu.Unlock()
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	amount, ok := s.Prices[req.GetSku()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "pricing: no price for %q", req.GetSku())
+	}
+	cur := strings.ToUpper(req.GetCurrency())
+	if cur == "" {
+		cur = "USD"
+	}
+	if cur != "USD" {
+		rate, ok := s.Rates[cur]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "pricing: unknown currency %q", cur)
+		}
+		amount = (amount*rate + 500) / 1000
+	}
+	return &pricingv1.GetPriceResponse{Sku: req.GetSku(), Currency: cur, AmountMinor: amount}, nil
+}
+
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Latency = d
+}
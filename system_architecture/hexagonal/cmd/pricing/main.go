@@ -0,0 +1,243 @@
+// Команда pricing собирает гексагон: ядро pricing и выбранные адаптеры.
+//
+//	pricing serve [-config pricing.yaml] [-addr :8082] [-data-dir dir | -dsn postgres://...] [-limit 60 -window 1m] [-redis addr]
+//	              [-flags flags.json | -flags-url http://...] [-flags-poll 30s]
+//	pricing quote -sku book-1 -price 25 -discount holiday -currency EUR [-country DE -customer c-1]
+//	pricing batch [-workers 8] < requests.jsonl
+//
+// Флаги функций читаются из -flags или -flags-url, а переменные FEATURE_* их
+// переопределяют: FEATURE_PRICING_TAX_V2=25% включает новый налог четверти покупателей.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"reflect"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/redis/go-redis/v9"
+
+	"solid/app"
+	"solid/config"
+	"solid/data"
+	"solid/featureflags"
+	"solid/health"
+	"solid/httpmw"
+	"solid/logging"
+	"solid/metrics"
+	"solid/resilience/ratelimit"
+	"solid/tasks"
+	"solid/telemetry"
+
+	"hexagonal/internal/adapters/cli"
+	"hexagonal/internal/adapters/datastore"
+	"hexagonal/internal/adapters/fixedrates"
+	"hexagonal/internal/adapters/httpapi"
+	"hexagonal/internal/adapters/memstore"
+	"hexagonal/internal/adapters/pgstore"
+	"hexagonal/internal/adapters/ratecache"
+	"hexagonal/internal/pricing"
+)
+
+var rates = fixedrates.Table{"EUR": 0.92, "GBP": 0.79, "RUB": 96.5}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "serve":
+		serve(os.Args[2:])
+	case "quote":
+		if err := cli.Quote(context.Background(), cliService(), os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "batch":
+		fs := flag.NewFlagSet("batch", flag.ExitOnError)
+		workers := fs.Int("workers", 8, "quotes computed in parallel")
+		fs.Parse(os.Args[2:])
+		if err := cli.Batch(context.Background(), cliService(), os.Stdin, os.Stdout, *workers); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+}
+
+// settings - настройки serve из -config (JSON или YAML), переменных PRICING_* и флагов.
+// Лимит запросов меняется правкой файла без перезапуска.
+type settings struct {
+	Addr      string            `default:":8082" usage:"HTTP listen address"`
+	DataDir   string            `usage:"directory for quotes (in-memory if empty)"`
+	DSN       string            `usage:"PostgreSQL DSN for quotes and their outbox (overrides -data-dir)"`
+	Limit     int               `default:"60" validate:"min=1" usage:"requests per window per caller (reloadable)"`
+	Window    time.Duration     `default:"1m" validate:"min=1ms" usage:"rate limit sliding window (reloadable)"`
+	Redis     string            `usage:"Redis address for a rate limit shared by all replicas (in-memory if empty)"`
+	Flags     string            `usage:"JSON file with feature flags"`
+	FlagsURL  string            `usage:"URL of the feature flags JSON (overrides -flags)"`
+	FlagsPoll time.Duration     `default:"30s" usage:"feature flags refresh interval"`
+	Timeout   time.Duration     `default:"10s" validate:"min=0" usage:"per-request handler deadline (disabled if 0)"`
+	RatesTTL  time.Duration     `default:"10m" validate:"min=0" usage:"how long a currency rate is cached (only concurrent quotes share a fetch if 0)"`
+	Trace     string            `default:"none" validate:"oneof=none stdout" usage:"trace exporter: none or stdout"`
+	LogFormat string            `default:"text" validate:"oneof=text json" usage:"log format: text or json"`
+	LogLevel  string            `default:"info" validate:"oneof=debug info warn error" usage:"default log level (reloadable)"`
+	LogLevels map[string]string `usage:"comma-separated module=level overrides for pricing, http, flags, app and config (reloadable)"`
+}
+
+func serve(args []string) {
+	logger := log.Default()
+	// До Load журналы модулей ещё не собраны: ошибки пишет стандартный журнал.
+	configLog := logger
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	loader, err := config.Bind[settings](fs, config.Options{
+		FileFlag:  "config",
+		EnvPrefix: "PRICING_",
+		OnError:   func(err error) { configLog.Printf("pricing: %v", err) },
+	})
+	if err != nil {
+		logger.Fatal(err)
+	}
+	poll := fs.Duration("config-poll", 5*time.Second, "how often to check the config files for changes")
+	fs.Parse(args)
+	cfg, err := loader.Load()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	levels, err := logging.ParseLevels(cfg.LogLevel, cfg.LogLevels)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	root, err := logging.New(logging.Config{Format: cfg.LogFormat, Levels: levels})
+	if err != nil {
+		logger.Fatal(err)
+	}
+	moduleLog := func(name string) *log.Logger {
+		return slog.NewLogLogger(logging.Module(root, name).Handler(), slog.LevelInfo)
+	}
+	logger, configLog = moduleLog("pricing"), moduleLog("config")
+	flagsLog, httpLog := moduleLog("flags"), moduleLog("http")
+
+	tp, shutdown, err := telemetry.Setup(telemetry.Config{Service: "pricing", Exporter: cfg.Trace})
+	if err != nil {
+		logger.Fatal(err)
+	}
+	// Ресурсы добавляются раньше сервера и поэтому закрываются после него.
+	reg := metrics.New("pricing")
+	a := app.New(app.Config{Logger: moduleLog("app")})
+	a.Add("telemetry", app.Hook{OnStop: shutdown})
+	h := health.New(health.Config{})
+	// Запуск - задачи: схема базы готова раньше, чем сервер примет первый запрос.
+	startup := tasks.New(tasks.Config{Logger: logger})
+	var serveAfter []string
+	var quotes pricing.QuoteRepository = memstore.New()
+	switch {
+	case cfg.DSN != "":
+		db, err := sql.Open("pgx", cfg.DSN)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		a.Add("postgres", app.Closer(db.Close))
+		h.Readiness(health.Check{Name: "postgres", Probe: health.Ping(db)})
+		store := pgstore.New(db)
+		startup.Add(tasks.Task{Name: "migrate", Timeout: time.Minute, Run: store.Migrate})
+		serveAfter = append(serveAfter, "migrate")
+		quotes = store
+	case cfg.DataDir != "":
+		quotes = datastore.New(metrics.Storage(data.NewFilesystem(cfg.DataDir), reg), data.WithTelemetry(tp, nil))
+	}
+	sources := []featureflags.Source{}
+	switch {
+	case cfg.FlagsURL != "":
+		sources = append(sources, featureflags.Remote(cfg.FlagsURL, &http.Client{Timeout: 5 * time.Second}))
+	case cfg.Flags != "":
+		sources = append(sources, featureflags.File(cfg.Flags))
+	}
+	flags, err := featureflags.New(context.Background(), featureflags.Merge(append(sources, featureflags.Env(""))...), featureflags.Config{
+		Interval: cfg.FlagsPoll,
+		OnError:  func(err error) { flagsLog.Printf("pricing: %v", err) },
+	})
+	if err != nil {
+		// Без флагов сервис работает по-старому, пока опрос не загрузит правила.
+		flagsLog.Printf("pricing: %v", err)
+	}
+	a.Add("featureflags", app.Closer(func() error { flags.Close(); return nil }))
+	svc := pricing.NewService(quotes, ratecache.New(rates, cfg.RatesTTL), flags)
+
+	// Лимит - забота внешнего слоя, ядро о нём не знает. За шлюзом вызывающего
+	// называет X-Caller, напрямую - адрес клиента.
+	var rdb *redis.Client
+	if cfg.Redis != "" {
+		rdb = redis.NewClient(&redis.Options{Addr: cfg.Redis})
+		a.Add("redis", app.Closer(rdb.Close))
+		// Без Redis лимит не считается, но запросы проходят - это деградация, а не отказ.
+		h.Readiness(health.Check{Name: "redis", Probe: func(ctx context.Context) error { return rdb.Ping(ctx).Err() }, Optional: true})
+	}
+	newLimiter := func(cfg settings) ratelimit.Limiter {
+		if rdb != nil {
+			return ratelimit.NewRedisSlidingWindow(rdb, cfg.Limit, cfg.Window)
+		}
+		return ratelimit.NewSlidingWindow(cfg.Limit, cfg.Window)
+	}
+	limiter := ratelimit.NewSwap(newLimiter(cfg))
+	loader.OnChange(func(old, cur settings) {
+		if cur.Limit != old.Limit || cur.Window != old.Window {
+			limiter.Store(newLimiter(cur))
+		}
+		if err := levels.Update(cur.LogLevel, cur.LogLevels); err != nil {
+			configLog.Printf("pricing: %v", err)
+		}
+		old.Limit, old.Window, old.LogLevel, old.LogLevels = cur.Limit, cur.Window, cur.LogLevel, cur.LogLevels
+		if !reflect.DeepEqual(old, cur) {
+			configLog.Print("pricing: config reloaded; only limit, window and log levels apply without a restart")
+			return
+		}
+		configLog.Print("pricing: config reloaded")
+	})
+	a.Worker("config", func(ctx context.Context) error {
+		loader.Watch(ctx, *poll)
+		return ctx.Err()
+	})
+	limited := ratelimit.Middleware(limiter, ratelimit.ByHeader("X-Caller", ratelimit.ByRemoteIP), func(r *http.Request, err error) {
+		logger.Printf("pricing: rate limiter: %v", err)
+	})
+	// Проверки здоровья и метрики не проходят через лимит: балансировщик и Prometheus
+	// не вызывающие.
+	mux := http.NewServeMux()
+	h.Register(mux)
+	mux.Handle("GET /metrics", reg.Handler())
+	mux.Handle("/", limited(httpapi.New(svc, logger)))
+	handler := httpmw.Chain(mux, httpmw.RequestID, httpmw.Logging(httpLog), httpmw.Recover(httpLog), httpmw.Gzip, httpmw.Timeout(cfg.Timeout), telemetry.HTTP(tp), metrics.HTTP(reg))
+	a.HTTP("http", &http.Server{Addr: cfg.Addr, Handler: handler, ReadHeaderTimeout: 5 * time.Second})
+	startup.Add(tasks.Task{Name: "serve", After: serveAfter, Run: func(ctx context.Context) error {
+		logger.Printf("pricing listening on %s", cfg.Addr)
+		return a.Run(ctx)
+	}})
+	if _, err := startup.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// cliService - ядро для команд CLI: расчёты живут только до конца процесса, поэтому
+// хранилищем служит память, а флаги берутся только из окружения.
+func cliService() *pricing.Service {
+	flags, err := featureflags.New(context.Background(), featureflags.Env(""), featureflags.Config{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return pricing.NewService(memstore.New(), rates, flags)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: pricing serve [-config file] [-addr addr] [-data-dir dir | -dsn dsn] [-limit n -window d] [-redis addr] [-flags file | -flags-url url] [-trace stdout] | quote -sku s -price p [-discount d] [-currency c] [-country c] [-customer id] | batch [-workers n] < requests.jsonl")
+	os.Exit(2)
+}
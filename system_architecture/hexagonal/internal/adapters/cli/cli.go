@@ -0,0 +1,102 @@
+// Package cli - ведущий адаптер командной строки поверх того же порта pricing.Quoter.
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"solid/concurrency/fan"
+
+	"hexagonal/internal/pricing"
+)
+
+// Quote разбирает флаги команды quote, выполняет расчёт и печатает результат в w.
+func Quote(ctx context.Context, q pricing.Quoter, args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("quote", flag.ContinueOnError)
+	fs.SetOutput(w)
+	var req pricing.QuoteRequest
+	fs.StringVar(&req.SKU, "sku", "", "product SKU")
+	fs.Float64Var(&req.Price, "price", 0, "price in "+pricing.BaseCurrency)
+	fs.StringVar(&req.Discount, "discount", "", "discount: none, regular or holiday")
+	fs.StringVar(&req.Currency, "currency", "", "currency of the total (default "+pricing.BaseCurrency+")")
+	fs.StringVar(&req.Country, "country", "", "buyer country for tax, e.g. DE")
+	fs.StringVar(&req.Customer, "customer", "", "customer id, the key for feature flag rollouts")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	quote, err := q.Quote(ctx, req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "quote %s: %s %.2f %s -> %.2f %s (discount %s, rate %.4f",
+		quote.ID, quote.SKU, quote.Price, pricing.BaseCurrency, quote.Total, quote.Currency, quote.Discount, quote.Rate)
+	if quote.Tax > 0 {
+		fmt.Fprintf(w, ", tax %.2f %s", quote.Tax, quote.Country)
+	}
+	fmt.Fprintln(w, ")")
+	return nil
+}
+
+// BatchResult - строка вывода Batch: номер строки ввода и расчёт или ошибка.
+type BatchResult struct {
+	Line  int            `json:"line"`
+	Quote *pricing.Quote `json:"quote,omitempty"`
+	Error string         `json:"error,omitempty"`
+}
+
+// Batch читает из r запросы расчёта по одному JSON на строку, считает их в workers
+// горутин и пишет в w по строке JSON на запрос в порядке ввода - по мере готовности,
+// не дожидаясь конца ввода. Ошибка запроса попадает в его строку; Batch возвращает
+// только ошибки чтения и записи.
+func Batch(ctx context.Context, q pricing.Quoter, r io.Reader, w io.Writer, workers int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type line struct {
+		n   int
+		raw []byte
+	}
+	lines := make(chan line)
+	var readErr error
+	go func() {
+		defer close(lines)
+		sc := bufio.NewScanner(r)
+		for n := 1; sc.Scan(); n++ {
+			if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+				continue
+			}
+			select {
+			case lines <- line{n: n, raw: bytes.Clone(sc.Bytes())}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr = sc.Err()
+	}()
+	enc := json.NewEncoder(w)
+	for res := range fan.Parallel(ctx, lines, workers, func(ctx context.Context, l line) BatchResult {
+		var req pricing.QuoteRequest
+		dec := json.NewDecoder(bytes.NewReader(l.raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			return BatchResult{Line: l.n, Error: "invalid JSON: " + err.Error()}
+		}
+		quote, err := q.Quote(ctx, req)
+		if err != nil {
+			return BatchResult{Line: l.n, Error: err.Error()}
+		}
+		return BatchResult{Line: l.n, Quote: &quote}
+	}) {
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	}
+	if readErr != nil {
+		return fmt.Errorf("batch: read: %w", readErr)
+	}
+	return ctx.Err()
+}
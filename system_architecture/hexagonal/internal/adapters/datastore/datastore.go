@@ -0,0 +1,39 @@
+// Package datastore - ведомый адаптер над solid/data: расчёты сохраняются через DataManager
+// в любое data.Storage (база, файловая система). Ядро об этом не знает.
+package datastore
+
+import (
+	"context"
+	"errors"
+
+	"solid/data"
+
+	"hexagonal/internal/pricing"
+)
+
+var _ pricing.QuoteRepository = (*Store)(nil)
+
+type Store struct {
+	dm *data.DataManager[pricing.Quote]
+}
+
+func New(storage data.Storage, opts ...data.Option) *Store {
+	return &Store{dm: data.NewDataManager[pricing.Quote](storage, opts...)}
+}
+
+func (s *Store) Save(ctx context.Context, q pricing.Quote) error {
+	return s.dm.SaveData(ctx, key(q.ID), q)
+}
+
+// Get переводит data.ErrNotFound в ошибку ядра, чтобы ведущие адаптеры не зависели от solid/data.
+func (s *Store) Get(ctx context.Context, id string) (pricing.Quote, error) {
+	q, err := s.dm.LoadData(ctx, key(id))
+	if errors.Is(err, data.ErrNotFound) {
+		return pricing.Quote{}, pricing.ErrNotFound
+	}
+	return q, err
+}
+
+func key(id string) string {
+	return "quotes/" + id
+}
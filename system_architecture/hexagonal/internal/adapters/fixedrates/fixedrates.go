@@ -0,0 +1,26 @@
+// Package fixedrates - ведомый адаптер курсов с заранее заданной таблицей, например из конфигурации.
+// Настоящий провайдер (API банка) подключается другой реализацией того же порта.
+package fixedrates
+
+import (
+	"context"
+	"fmt"
+
+	"hexagonal/internal/pricing"
+)
+
+var _ pricing.RatesProvider = Table{}
+
+// Table - курсы к базовой валюте: Table["EUR"] = 0.92 означает 1 USD = 0.92 EUR.
+type Table map[string]float64
+
+func (t Table) Rate(ctx context.Context, from, to string) (float64, error) {
+	if from != pricing.BaseCurrency {
+		return 0, fmt.Errorf("%w: only %s rates are known", pricing.ErrRatesUnavailable, pricing.BaseCurrency)
+	}
+	r, ok := t[to]
+	if !ok {
+		return 0, fmt.Errorf("%w %q", pricing.ErrUnknownCurrency, to)
+	}
+	return r, nil
+}
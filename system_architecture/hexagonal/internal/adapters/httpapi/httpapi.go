@@ -0,0 +1,123 @@
+// Package httpapi - ведущий адаптер HTTP: переводит запросы в вызовы порта pricing.Quoter.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"solid/concurrency/pool"
+
+	"hexagonal/internal/pricing"
+)
+
+// MaxBatch - сколько расчётов принимает один POST /quotes/batch.
+const MaxBatch = 100
+
+// batchWorkers - сколько расчётов пакета идут одновременно: каждый может ждать курс
+// и хранилище, последовательно пакет считался бы в MaxBatch раз дольше.
+const batchWorkers = 8
+
+type Handler struct {
+	quoter pricing.Quoter
+	log    *log.Logger
+}
+
+func New(q pricing.Quoter, logger *log.Logger) http.Handler {
+	h := &Handler{quoter: q, log: logger}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /quotes", h.create)
+	mux.HandleFunc("POST /quotes/batch", h.batch)
+	mux.HandleFunc("GET /quotes/{id}", h.get)
+	return mux
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req pricing.QuoteRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	q, err := h.quoter.Quote(r.Context(), req)
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	w.Header().Set("Location", "/quotes/"+q.ID)
+	writeJSON(w, http.StatusCreated, q)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	q, err := h.quoter.Find(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, q)
+}
+
+// BatchItem - итог одного расчёта пакета; Status - код, который вернул бы POST /quotes.
+type BatchItem struct {
+	Status int            `json:"status"`
+	Quote  *pricing.Quote `json:"quote,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// batch считает пакет параллельно; итоги идут в порядке запросов, а ошибка одного
+// расчёта не мешает остальным.
+func (h *Handler) batch(w http.ResponseWriter, r *http.Request) {
+	var reqs []pricing.QuoteRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&reqs); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	if len(reqs) == 0 || len(reqs) > MaxBatch {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("batch must hold 1 to %d quotes", MaxBatch)})
+		return
+	}
+	results := pool.Map(r.Context(), pool.Config{Workers: batchWorkers}, reqs, h.quoter.Quote)
+	items := make([]BatchItem, len(results))
+	for i, res := range results {
+		if res.Err != nil {
+			status, msg := h.classify(res.Err)
+			items[i] = BatchItem{Status: status, Error: msg}
+			continue
+		}
+		items[i] = BatchItem{Status: http.StatusCreated, Quote: &res.Value}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": items})
+}
+
+func (h *Handler) fail(w http.ResponseWriter, err error) {
+	status, msg := h.classify(err)
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// classify переводит ошибку ядра в код ответа и текст для клиента; неожиданные
+// ошибки пишутся в журнал и наружу не уходят.
+func (h *Handler) classify(err error) (int, string) {
+	switch {
+	case errors.Is(err, pricing.ErrNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, pricing.ErrInvalid), errors.Is(err, pricing.ErrUnknownDiscount),
+		errors.Is(err, pricing.ErrUnknownCurrency), errors.Is(err, pricing.ErrUnknownCountry):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, pricing.ErrRatesUnavailable):
+		return http.StatusBadGateway, err.Error()
+	default:
+		h.log.Printf("pricing: %v", err)
+		return http.StatusInternalServerError, "internal error"
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,37 @@
+// Package memstore - ведомый адаптер хранения расчётов в памяти.
+package memstore
+
+import (
+	"context"
+	"sync"
+
+	"hexagonal/internal/pricing"
+)
+
+var _ pricing.QuoteRepository = (*Store)(nil)
+
+type Store struct {
+	mu     sync.RWMutex
+	quotes map[string]pricing.Quote
+}
+
+func New() *Store {
+	return &Store{quotes: make(map[string]pricing.Quote)}
+}
+
+func (s *Store) Save(ctx context.Context, q pricing.Quote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quotes[q.ID] = q
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, id string) (pricing.Quote, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	q, ok := s.quotes[id]
+	if !ok {
+		return pricing.Quote{}, pricing.ErrNotFound
+	}
+	return q, nil
+}
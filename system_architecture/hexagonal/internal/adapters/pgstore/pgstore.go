@@ -0,0 +1,91 @@
+// Package pgstore - ведомый адаптер хранения расчётов в PostgreSQL через database/sql.
+// Расчёт пишется в pricing_quotes, а сообщение о нём - в outbox solid/data/sqlstore:
+// обе записи идут одной единицей работы uow, так что ретранслятор outbox не опубликует
+// расчёт, которого нет, и не пропустит сохранённый.
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"solid/data/sqlstore"
+	"solid/uow"
+
+	"hexagonal/internal/pricing"
+)
+
+// Schema создаёт таблицу расчётов; таблицы outbox создаёт sqlstore.Schema.
+const Schema = `
+CREATE TABLE IF NOT EXISTS pricing_quotes (
+	id         TEXT PRIMARY KEY,
+	sku        TEXT NOT NULL,
+	price      DOUBLE PRECISION NOT NULL,
+	discount   TEXT NOT NULL,
+	currency   TEXT NOT NULL,
+	rate       DOUBLE PRECISION NOT NULL,
+	country    TEXT NOT NULL DEFAULT '',
+	tax        DOUBLE PRECISION NOT NULL DEFAULT 0,
+	total      DOUBLE PRECISION NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+`
+
+var _ pricing.QuoteRepository = (*Store)(nil)
+
+type Store struct {
+	db     *sql.DB
+	uow    *uow.UnitOfWork
+	outbox *sqlstore.Store
+}
+
+func New(db *sql.DB) *Store {
+	return &Store{db: db, uow: uow.New(db, uow.Config{}), outbox: sqlstore.New(db)}
+}
+
+func (s *Store) Migrate(ctx context.Context) error {
+	if err := s.outbox.Migrate(ctx); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, Schema)
+	return err
+}
+
+// Outbox - очередь сообщений о расчётах для data.Relay или команды outboxrelay.
+func (s *Store) Outbox() *sqlstore.Store {
+	return s.outbox
+}
+
+func (s *Store) Save(ctx context.Context, q pricing.Quote) error {
+	msg, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		if _, err := uow.From(ctx, s.db).ExecContext(ctx, `
+			INSERT INTO pricing_quotes (id, sku, price, discount, currency, rate, country, tax, total, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			q.ID, q.SKU, q.Price, q.Discount, q.Currency, q.Rate, q.Country, q.Tax, q.Total, q.CreatedAt); err != nil {
+			return fmt.Errorf("pgstore: save %s: %w", q.ID, err)
+		}
+		return s.outbox.Enqueue(ctx, "quotes/"+q.ID, string(msg))
+	})
+}
+
+func (s *Store) Get(ctx context.Context, id string) (pricing.Quote, error) {
+	var q pricing.Quote
+	err := uow.From(ctx, s.db).QueryRowContext(ctx, `
+		SELECT id, sku, price, discount, currency, rate, country, tax, total, created_at
+		FROM pricing_quotes WHERE id = $1`, id).
+		Scan(&q.ID, &q.SKU, &q.Price, &q.Discount, &q.Currency, &q.Rate, &q.Country, &q.Tax, &q.Total, &q.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pricing.Quote{}, pricing.ErrNotFound
+	}
+	if err != nil {
+		return pricing.Quote{}, fmt.Errorf("pgstore: get %s: %w", id, err)
+	}
+	q.CreatedAt = q.CreatedAt.UTC()
+	return q, nil
+}
@@ -0,0 +1,37 @@
+// Package ratecache - декоратор порта курсов: полученный курс живёт TTL, чтобы
+// расчёты не ходили к провайдеру каждый раз, а одновременные промахи одной пары
+// валют сводятся в один запрос. Ошибки провайдера не кэшируются; запрос к
+// провайдеру отменяется, только если его бросили все расчёты, которые его ждали.
+package ratecache
+
+import (
+	"context"
+	"time"
+
+	"solid/concurrency/coalesce"
+
+	"hexagonal/internal/pricing"
+)
+
+var _ pricing.RatesProvider = (*Rates)(nil)
+
+type pair struct {
+	from, to string
+}
+
+type Rates struct {
+	next   pricing.RatesProvider
+	shared *coalesce.Group[pair, float64]
+}
+
+func New(next pricing.RatesProvider, ttl time.Duration) *Rates {
+	return &Rates{next: next, shared: coalesce.New[pair, float64](coalesce.Config[pair]{TTL: ttl})}
+}
+
+func (r *Rates) Rate(ctx context.Context, from, to string) (float64, error) {
+	return r.shared.Do(ctx, pair{from, to}, func(ctx context.Context) (float64, error) {
+		return r.next.Rate(ctx, from, to)
+	})
+}
+
+func (r *Rates) Stats() coalesce.Stats { return r.shared.Stats() }
@@ -0,0 +1,72 @@
+// Package pricing - ядро приложения (гексагон): модель расчёта цены и порты.
+// Ядро не импортирует ни одного адаптера; адаптеры импортируют ядро. Направление
+// зависимостей закреплено раскладкой пакетов: internal/adapters/* → internal/pricing.
+package pricing
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalid          = errors.New("pricing: invalid request")
+	ErrUnknownDiscount  = errors.New("pricing: unknown discount")
+	ErrUnknownCurrency  = errors.New("pricing: unknown currency")
+	ErrUnknownCountry   = errors.New("pricing: unknown country")
+	ErrNotFound         = errors.New("pricing: quote not found")
+	ErrRatesUnavailable = errors.New("pricing: rates unavailable")
+)
+
+// BaseCurrency - валюта, в которой заданы цены каталога.
+const BaseCurrency = "USD"
+
+// FlagTaxV2 - флаг нового расчёта налога: НДС по стране покупателя отдельной строкой.
+// Без флага цены, как и раньше, считаются уже включающими налог.
+const FlagTaxV2 = "pricing.tax-v2"
+
+type QuoteRequest struct {
+	SKU      string  `json:"sku"`
+	Price    float64 `json:"price"`
+	Discount string  `json:"discount,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+	// Country - страна покупателя (ISO 3166-1 alpha-2) для налога.
+	Country string `json:"country,omitempty"`
+	// Customer - ключ раскатки флагов; без него процентная раскатка не действует.
+	Customer string `json:"customer,omitempty"`
+}
+
+type Quote struct {
+	ID        string    `json:"id"`
+	SKU       string    `json:"sku"`
+	Price     float64   `json:"price"`
+	Discount  string    `json:"discount"`
+	Currency  string    `json:"currency"`
+	Rate      float64   `json:"rate"`
+	Country   string    `json:"country,omitempty"`
+	Tax       float64   `json:"tax,omitempty"`
+	Total     float64   `json:"total"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Quoter - входящий порт: так ядро видят HTTP, CLI и любые другие ведущие адаптеры.
+type Quoter interface {
+	Quote(ctx context.Context, req QuoteRequest) (Quote, error)
+	Find(ctx context.Context, id string) (Quote, error)
+}
+
+// QuoteRepository - исходящий порт хранения расчётов.
+type QuoteRepository interface {
+	Save(ctx context.Context, q Quote) error
+	Get(ctx context.Context, id string) (Quote, error)
+}
+
+// Features - исходящий порт флагов функций; его реализует, например, solid/featureflags.
+type Features interface {
+	Enabled(ctx context.Context, name, key string) bool
+}
+
+// RatesProvider - исходящий порт курсов валют: сколько единиц to стоит одна единица from.
+type RatesProvider interface {
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
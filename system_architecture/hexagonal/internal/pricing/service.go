@@ -0,0 +1,100 @@
+package pricing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// discounts - правила ядра; они не зависят от того, откуда пришёл запрос.
+var discounts = map[string]float64{"none": 1, "regular": 0.9, "holiday": 0.8}
+
+type Service struct {
+	quotes   QuoteRepository
+	rates    RatesProvider
+	features Features
+	now      func() time.Time
+}
+
+var _ Quoter = (*Service)(nil)
+
+// NewService собирает ядро; features может быть nil - тогда все флаги выключены.
+func NewService(quotes QuoteRepository, rates RatesProvider, features Features) *Service {
+	if features == nil {
+		features = noFeatures{}
+	}
+	return &Service{quotes: quotes, rates: rates, features: features, now: time.Now}
+}
+
+type noFeatures struct{}
+
+func (noFeatures) Enabled(ctx context.Context, name, key string) bool { return false }
+
+func (s *Service) Quote(ctx context.Context, req QuoteRequest) (Quote, error) {
+	req.SKU = strings.TrimSpace(req.SKU)
+	if req.SKU == "" {
+		return Quote{}, fmt.Errorf("%w: sku is required", ErrInvalid)
+	}
+	if req.Price <= 0 || math.IsInf(req.Price, 0) || math.IsNaN(req.Price) {
+		return Quote{}, fmt.Errorf("%w: price must be positive", ErrInvalid)
+	}
+	if req.Discount == "" {
+		req.Discount = "none"
+	}
+	k, ok := discounts[req.Discount]
+	if !ok {
+		return Quote{}, fmt.Errorf("%w %q", ErrUnknownDiscount, req.Discount)
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = BaseCurrency
+	}
+	rate := 1.0
+	if currency != BaseCurrency {
+		r, err := s.rates.Rate(ctx, BaseCurrency, currency)
+		if err != nil {
+			return Quote{}, err
+		}
+		rate = r
+	}
+	country := strings.ToUpper(strings.TrimSpace(req.Country))
+	tax := taxFunc(includedTax)
+	if s.features.Enabled(ctx, FlagTaxV2, req.Customer) {
+		tax = vatTax
+	}
+	subtotal := math.Round(req.Price*k*rate*100) / 100
+	t, err := tax(subtotal, country)
+	if err != nil {
+		return Quote{}, err
+	}
+	q := Quote{
+		ID:        newID(),
+		SKU:       req.SKU,
+		Price:     req.Price,
+		Discount:  req.Discount,
+		Currency:  currency,
+		Rate:      rate,
+		Country:   country,
+		Tax:       t,
+		Total:     math.Round((subtotal+t)*100) / 100,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.quotes.Save(ctx, q); err != nil {
+		return Quote{}, fmt.Errorf("pricing: save quote: %w", err)
+	}
+	return q, nil
+}
+
+func (s *Service) Find(ctx context.Context, id string) (Quote, error) {
+	return s.quotes.Get(ctx, id)
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,30 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+)
+
+// taxFunc считает налог с суммы после скидки в валюте расчёта.
+type taxFunc func(amount float64, country string) (float64, error)
+
+// includedTax - прежний расчёт: цены каталога уже включают налог.
+func includedTax(amount float64, country string) (float64, error) {
+	return 0, nil
+}
+
+// vatRates - ставки НДС по странам покупателя для нового расчёта.
+var vatRates = map[string]float64{"DE": 0.19, "FR": 0.20, "GB": 0.20, "NL": 0.21, "RU": 0.20, "US": 0}
+
+// vatTax - новый расчёт за FlagTaxV2: НДС страны сверх цены. Без страны налог
+// не начисляется, неизвестная страна - ошибка, а не нулевая ставка.
+func vatTax(amount float64, country string) (float64, error) {
+	if country == "" {
+		return 0, nil
+	}
+	rate, ok := vatRates[country]
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownCountry, country)
+	}
+	return math.Round(amount*rate*100) / 100, nil
+}
@@ -0,0 +1,101 @@
+// Команда consumer строит модель чтения из топика событий и отдаёт её по HTTP (GET /books),
+// а метрики - на GET /metrics.
+// Несколько экземпляров с одной -group делят партиции между собой.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"solid/app"
+	"solid/health"
+	"solid/messaging/idempotency"
+	"solid/metrics"
+	"solid/telemetry"
+
+	"kafka/internal/consumer"
+	"kafka/internal/event"
+	"kafka/internal/producer"
+	"kafka/internal/readmodel"
+)
+
+func main() {
+	brokers := flag.String("brokers", envOr("KAFKA_BROKERS", "localhost:9092"), "comma-separated broker addresses")
+	topic := flag.String("topic", "library-events", "topic to consume")
+	group := flag.String("group", "read-model", "consumer group id")
+	addr := flag.String("addr", ":8085", "HTTP address of the read model")
+	redisAddr := flag.String("redis", "", "Redis address for processed event ids shared by all instances (in-memory if empty)")
+	traceExporter := flag.String("trace", telemetry.None, "trace exporter: none or stdout")
+	flag.Parse()
+	logger := log.Default()
+	tp, shutdown, err := telemetry.Setup(telemetry.Config{Service: "consumer", Exporter: *traceExporter})
+	if err != nil {
+		logger.Fatal(err)
+	}
+	list := strings.Split(*brokers, ",")
+
+	model := readmodel.New()
+	// После перебалансировки партиция уходит другому экземпляру вместе с незафиксированными
+	// событиями, поэтому отсеивать дубли между экземплярами помогает только общий Redis.
+	var store idempotency.Store = idempotency.NewMemory()
+	if *redisAddr != "" {
+		store = idempotency.NewRedis(redis.NewClient(&redis.Options{Addr: *redisAddr}))
+	}
+	guard := &idempotency.Guard{Store: store, Logger: logger}
+	reg := metrics.New("consumer")
+	queue := reg.Queue()
+	dlq := producer.NewKafkaWriter(list, *topic+"-dlq")
+	reader := consumer.NewKafkaReader(list, *topic, *group)
+	c := &consumer.Consumer{
+		Reader:     reader,
+		Handle:     idempotency.Wrap(guard, event.ID, func(ctx context.Context, e event.Event) error { model.Apply(e); return nil }),
+		DeadLetter: dlq,
+		Backoff:    100 * time.Millisecond,
+		Log:        logger,
+		Tracer:     tp,
+		Metrics:    queue,
+	}
+
+	// Остановка в обратном порядке: HTTP, затем потребитель дообрабатывает текущее
+	// событие, и только после этого закрываются читатель и очередь недоставленных.
+	a := app.New(app.Config{Logger: logger})
+	a.Add("telemetry", app.Hook{OnStop: shutdown})
+	a.Add("dead letter writer", app.Closer(dlq.Close))
+	a.Add("reader", app.Closer(c.Reader.Close))
+	a.Worker("consumer", c.Run)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /books", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"books": model.Books(), "duplicates": guard.Stats().Duplicates})
+	})
+	// Готов, пока доступен хотя бы один брокер: без него модель чтения отстаёт.
+	checks := health.New(health.Config{})
+	checks.Readiness(health.Check{Name: "kafka", Probe: health.TCP(list...)})
+	checks.Register(mux)
+	// Отставание группы - глубина очереди для Kafka; снимок берётся к каждому опросу.
+	exposition := reg.Handler()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		queue.SetDepth("kafka", *topic, int(reader.Stats().Lag))
+		exposition.ServeHTTP(w, r)
+	})
+	a.HTTP("read model", &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second})
+	logger.Printf("consuming %s as %s, read model on %s", *topic, *group, *addr)
+	if err := a.Run(context.Background()); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,153 @@
+// Команда kafkacheck прогоняет сценарий доставки «хотя бы один раз» (internal/scenario):
+// повторную доставку после падения до фиксации, смещения группы после каждого шага,
+// заголовки сообщений DLQ и то, что сообщение, не дошедшее до DLQ, не фиксируется.
+//
+//	kafkacheck -memory                  на топике в памяти (memlog)
+//	kafkacheck -brokers localhost:9092  на брокере из docker-compose.yml
+//
+// -memory проверяет логику потребителя; группу потребителей, ребалансировку и
+// фиксацию смещений брокером проверяет только прогон на брокере:
+//
+//	docker compose up -d --wait kafka
+//	go run ./cmd/kafkacheck -brokers localhost:9092
+//	docker compose down
+//
+// Для брокера создаются новые топики с одной партицией и новая группа, поэтому
+// прогоны не мешают друг другу и топикам из init-topics.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"kafka/internal/consumer"
+	"kafka/internal/memlog"
+	"kafka/internal/producer"
+	"kafka/internal/scenario"
+)
+
+func main() {
+	memory := flag.Bool("memory", false, "use an in-memory topic instead of a broker")
+	brokers := flag.String("brokers", envOr("KAFKA_BROKERS", "localhost:9092"), "comma-separated broker addresses")
+	flag.Parse()
+
+	var env scenario.Env
+	var err error
+	if *memory {
+		env = memoryEnv()
+	} else {
+		env, err = brokerEnv(strings.Split(*brokers, ","))
+	}
+	if err == nil {
+		err = scenario.Run(os.Stdout, env)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func memoryEnv() scenario.Env {
+	events, dlq := memlog.NewTopic("library-events"), memlog.NewTopic("library-events-dlq")
+	return scenario.Env{
+		Events:     events,
+		DeadLetter: dlq,
+		NewReader:  func() consumer.Reader { return events.Reader("read-model") },
+		DeadLetters: func(ctx context.Context, n int) ([]kafkago.Message, error) {
+			return dlq.Messages(), nil
+		},
+		Committed: func(ctx context.Context) (int64, error) {
+			return events.Committed("read-model"), nil
+		},
+		Timeout: 5 * time.Second,
+	}
+}
+
+func brokerEnv(brokers []string) (scenario.Env, error) {
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	topic, dlqTopic, group := "library-events-"+suffix, "library-events-dlq-"+suffix, "read-model-"+suffix
+	if err := createTopics(brokers[0], topic, dlqTopic); err != nil {
+		return scenario.Env{}, err
+	}
+	return scenario.Env{
+		Events:     producer.NewKafkaWriter(brokers, topic),
+		DeadLetter: producer.NewKafkaWriter(brokers, dlqTopic),
+		NewReader:  func() consumer.Reader { return consumer.NewKafkaReader(brokers, topic, group) },
+		DeadLetters: func(ctx context.Context, n int) ([]kafkago.Message, error) {
+			r := kafkago.NewReader(kafkago.ReaderConfig{Brokers: brokers, Topic: dlqTopic, Partition: 0, MaxWait: 500 * time.Millisecond})
+			defer r.Close()
+			var out []kafkago.Message
+			for len(out) < n {
+				m, err := r.ReadMessage(ctx)
+				if err != nil {
+					return out, err
+				}
+				out = append(out, m)
+			}
+			return out, nil
+		},
+		Committed: func(ctx context.Context) (int64, error) {
+			return committed(ctx, brokers, group, topic)
+		},
+		Timeout: 60 * time.Second,
+	}, nil
+}
+
+// committed спрашивает у брокера смещение группы на единственной партиции топика;
+// -1 (группа ещё ничего не фиксировала) - это 0, как у memlog.
+func committed(ctx context.Context, brokers []string, group, topic string) (int64, error) {
+	client := &kafkago.Client{Addr: kafkago.TCP(brokers...)}
+	resp, err := client.OffsetFetch(ctx, &kafkago.OffsetFetchRequest{GroupID: group, Topics: map[string][]int{topic: {0}}})
+	if err != nil {
+		return 0, fmt.Errorf("kafkacheck: offset fetch: %w", err)
+	}
+	if resp.Error != nil {
+		return 0, fmt.Errorf("kafkacheck: offset fetch: %w", resp.Error)
+	}
+	parts := resp.Topics[topic]
+	if len(parts) != 1 {
+		return 0, fmt.Errorf("kafkacheck: offset fetch: %d partitions for %s", len(parts), topic)
+	}
+	if parts[0].Error != nil {
+		return 0, fmt.Errorf("kafkacheck: offset fetch: %w", parts[0].Error)
+	}
+	return max(parts[0].CommittedOffset, 0), nil
+}
+
+// createTopics создаёт топики через контроллер кластера.
+func createTopics(broker string, topics ...string) error {
+	conn, err := kafkago.Dial("tcp", broker)
+	if err != nil {
+		return fmt.Errorf("kafkacheck: dial %s: %w", broker, err)
+	}
+	defer conn.Close()
+	ctrl, err := conn.Controller()
+	if err != nil {
+		return fmt.Errorf("kafkacheck: find controller: %w", err)
+	}
+	cc, err := kafkago.Dial("tcp", net.JoinHostPort(ctrl.Host, strconv.Itoa(ctrl.Port)))
+	if err != nil {
+		return fmt.Errorf("kafkacheck: dial controller: %w", err)
+	}
+	defer cc.Close()
+	cfgs := make([]kafkago.TopicConfig, 0, len(topics))
+	for _, t := range topics {
+		cfgs = append(cfgs, kafkago.TopicConfig{Topic: t, NumPartitions: 1, ReplicationFactor: 1})
+	}
+	return cc.CreateTopics(cfgs...)
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,60 @@
+// Команда producer публикует одно доменное событие в топик.
+//
+//	producer -type book_lent -book dune -member ann
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"solid/telemetry"
+
+	"kafka/internal/event"
+	"kafka/internal/producer"
+)
+
+func main() {
+	brokers := flag.String("brokers", envOr("KAFKA_BROKERS", "localhost:9092"), "comma-separated broker addresses")
+	topic := flag.String("topic", "library-events", "topic to publish to")
+	typ := flag.String("type", string(event.BookAdded), "event type: book_added, book_lent or book_returned")
+	book := flag.String("book", "", "book id")
+	title := flag.String("title", "", "book title for book_added")
+	member := flag.String("member", "", "member for book_lent and book_returned")
+	traceExporter := flag.String("trace", telemetry.None, "trace exporter: none or stdout")
+	flag.Parse()
+
+	tp, shutdown, err := telemetry.Setup(telemetry.Config{Service: "producer", Exporter: *traceExporter})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer shutdown(context.Background())
+	p := producer.New(producer.NewKafkaWriter(strings.Split(*brokers, ","), *topic))
+	p.Tracer = tp
+	defer p.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	e := event.Event{
+		ID:         strconv.FormatInt(time.Now().UnixNano(), 36),
+		Type:       event.Type(*typ),
+		BookID:     *book,
+		Title:      *title,
+		Member:     *member,
+		OccurredAt: time.Now().UTC(),
+	}
+	if err := p.Publish(ctx, e); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("published %s %s for %s", e.ID, e.Type, e.BookID)
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
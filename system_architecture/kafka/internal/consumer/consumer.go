@@ -0,0 +1,167 @@
+// Package consumer читает события в группе потребителей и передаёт их обработчику.
+// Смещение фиксируется вручную и только после обработки, поэтому сбой между ними
+// приводит к повторной доставке (хотя бы один раз), а не к потере события.
+// Сообщения, которые так и не удалось обработать, уходят в топик недоставленных (DLQ).
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"solid/metrics"
+	"solid/telemetry"
+
+	"kafka/internal/event"
+	"kafka/internal/producer"
+)
+
+// Reader - часть kafka.Reader с ручной фиксацией смещений.
+type Reader interface {
+	FetchMessage(ctx context.Context) (kafkago.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafkago.Message) error
+	Close() error
+}
+
+type Handler func(ctx context.Context, e event.Event) error
+
+type Consumer struct {
+	Reader Reader
+	Handle Handler
+	// DeadLetter получает сообщения, не обработанные за MaxAttempts попыток, и неразбираемые.
+	// Без него такое сообщение не фиксируется, и потребитель останавливается.
+	DeadLetter producer.Writer
+	// MaxAttempts - попыток на сообщение, по умолчанию 3; между ними Backoff, удваиваясь.
+	MaxAttempts int
+	Backoff     time.Duration
+	Log         *log.Logger
+	// Tracer, если задан, продолжает трассу продюсера спаном обработки на каждое
+	// сообщение - со всеми попытками и отправкой в DLQ внутри.
+	Tracer trace.TracerProvider
+	// Metrics, если задан, считает обработку сообщений; ошибка - то, что остановило
+	// потребитель, а ушедшее в DLQ считается обработанным.
+	Metrics *metrics.Queue
+	// Committed, если задан, вызывается после фиксации смещения - для метрик и проверок.
+	Committed func(m kafkago.Message)
+}
+
+// NewKafkaReader настраивает kafka.Reader группы group. CommitInterval = 0 делает
+// CommitMessages синхронным: после возврата смещение уже записано брокером.
+func NewKafkaReader(brokers []string, topic, group string) *kafkago.Reader {
+	return kafkago.NewReader(kafkago.ReaderConfig{
+		Brokers:        brokers,
+		Topic:          topic,
+		GroupID:        group,
+		StartOffset:    kafkago.FirstOffset,
+		CommitInterval: 0,
+		MaxWait:        500 * time.Millisecond,
+	})
+}
+
+// Run обрабатывает сообщения, пока не отменят ctx; отмена - штатная остановка (nil).
+func (c *Consumer) Run(ctx context.Context) error {
+	for {
+		m, err := c.Reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("consumer: fetch: %w", err)
+		}
+		start := time.Now()
+		err = c.traced(ctx, m)
+		if c.Metrics != nil && ctx.Err() == nil {
+			c.Metrics.Process("kafka", m.Topic, start, err)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err := c.Reader.CommitMessages(ctx, m); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("consumer: commit offset %d: %w", m.Offset, err)
+		}
+		if c.Committed != nil {
+			c.Committed(m)
+		}
+	}
+}
+
+func (c *Consumer) traced(ctx context.Context, m kafkago.Message) error {
+	if c.Tracer == nil {
+		return c.process(ctx, m)
+	}
+	carrier := propagation.MapCarrier{}
+	for _, h := range m.Headers {
+		carrier[h.Key] = string(h.Value)
+	}
+	ctx, span := telemetry.StartReceive(ctx, c.Tracer, "kafka", m.Topic, carrier)
+	span.SetAttributes(attribute.Int("messaging.kafka.destination.partition", m.Partition), attribute.Int64("messaging.kafka.message.offset", m.Offset))
+	err := c.process(ctx, m)
+	telemetry.End(span, err)
+	return err
+}
+
+func (c *Consumer) process(ctx context.Context, m kafkago.Message) error {
+	e, err := event.Decode(m.Value)
+	if err != nil {
+		return c.deadLetter(ctx, m, 0, err)
+	}
+	attempts := c.MaxAttempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	backoff := c.Backoff
+	for i := 1; ; i++ {
+		err = c.Handle(ctx, e)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		if i == attempts {
+			return c.deadLetter(ctx, m, i, err)
+		}
+		c.logf("consumer: %s attempt %d/%d: %v", e.ID, i, attempts, err)
+		if backoff > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+	}
+}
+
+func (c *Consumer) deadLetter(ctx context.Context, m kafkago.Message, attempts int, cause error) error {
+	if c.DeadLetter == nil {
+		return fmt.Errorf("consumer: offset %d: %w", m.Offset, cause)
+	}
+	dlq := kafkago.Message{Key: m.Key, Value: m.Value, Headers: append(m.Headers,
+		kafkago.Header{Key: "error", Value: []byte(cause.Error())},
+		kafkago.Header{Key: "attempts", Value: []byte(strconv.Itoa(attempts))},
+		kafkago.Header{Key: "source", Value: []byte(m.Topic + "/" + strconv.Itoa(m.Partition) + "/" + strconv.FormatInt(m.Offset, 10))},
+	)}
+	if err := c.DeadLetter.WriteMessages(ctx, dlq); err != nil {
+		return fmt.Errorf("consumer: dead-letter offset %d: %w", m.Offset, errors.Join(cause, err))
+	}
+	c.logf("consumer: offset %d sent to dead-letter topic: %v", m.Offset, cause)
+	return nil
+}
+
+func (c *Consumer) logf(format string, args ...any) {
+	if c.Log != nil {
+		c.Log.Printf(format, args...)
+	}
+}
@@ -0,0 +1,45 @@
+// Package event - доменные события библиотеки в том виде, в каком они лежат в Kafka:
+// ключ сообщения - id книги (порядок событий одной книги сохраняется), значение - JSON.
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Type string
+
+const (
+	BookAdded    Type = "book_added"
+	BookLent     Type = "book_lent"
+	BookReturned Type = "book_returned"
+)
+
+// Event - конверт события. ID уникален и служит для отсева повторных доставок.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       Type      `json:"type"`
+	BookID     string    `json:"book_id"`
+	Title      string    `json:"title,omitempty"`
+	Member     string    `json:"member,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (e Event) Key() []byte { return []byte(e.BookID) }
+
+// ID - идентификатор для idempotency.Wrap.
+func ID(e Event) string { return e.ID }
+
+func (e Event) Encode() ([]byte, error) { return json.Marshal(e) }
+
+func Decode(b []byte) (Event, error) {
+	var e Event
+	if err := json.Unmarshal(b, &e); err != nil {
+		return Event{}, fmt.Errorf("event: decode: %w", err)
+	}
+	if e.ID == "" || e.BookID == "" {
+		return Event{}, fmt.Errorf("event: decode: id and book_id are required")
+	}
+	return e, nil
+}
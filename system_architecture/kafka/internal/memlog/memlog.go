@@ -0,0 +1,118 @@
+// Package memlog - топик Kafka в памяти с одной партицией и смещениями групп.
+// Его хватает, чтобы проверить логику потребителя без брокера: повторную доставку
+// незафиксированных сообщений и продолжение с зафиксированного смещения.
+package memlog
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"kafka/internal/consumer"
+	"kafka/internal/producer"
+)
+
+var ErrClosed = errors.New("memlog: reader is closed")
+
+var (
+	_ producer.Writer = (*Topic)(nil)
+	_ consumer.Reader = (*Reader)(nil)
+)
+
+type Topic struct {
+	Name string
+
+	mu        sync.Mutex
+	changed   chan struct{}
+	msgs      []kafkago.Message
+	committed map[string]int64
+}
+
+func NewTopic(name string) *Topic {
+	return &Topic{Name: name, changed: make(chan struct{}), committed: map[string]int64{}}
+}
+
+func (t *Topic) WriteMessages(ctx context.Context, msgs ...kafkago.Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, m := range msgs {
+		m.Topic, m.Offset = t.Name, int64(len(t.msgs))
+		t.msgs = append(t.msgs, m)
+	}
+	close(t.changed)
+	t.changed = make(chan struct{})
+	return nil
+}
+
+func (t *Topic) Close() error { return nil }
+
+func (t *Topic) Messages() []kafkago.Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]kafkago.Message(nil), t.msgs...)
+}
+
+// Committed - следующее смещение, которое получит новый читатель группы.
+func (t *Topic) Committed(group string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.committed[group]
+}
+
+// Reader открывает читателя группы с её зафиксированного смещения.
+func (t *Topic) Reader(group string) *Reader {
+	return &Reader{topic: t, group: group, next: t.Committed(group)}
+}
+
+type Reader struct {
+	topic  *Topic
+	group  string
+	next   int64
+	closed bool
+}
+
+func (r *Reader) FetchMessage(ctx context.Context) (kafkago.Message, error) {
+	for {
+		r.topic.mu.Lock()
+		if r.closed {
+			r.topic.mu.Unlock()
+			return kafkago.Message{}, ErrClosed
+		}
+		if r.next < int64(len(r.topic.msgs)) {
+			m := r.topic.msgs[r.next]
+			r.next++
+			r.topic.mu.Unlock()
+			return m, nil
+		}
+		changed := r.topic.changed
+		r.topic.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return kafkago.Message{}, ctx.Err()
+		}
+	}
+}
+
+func (r *Reader) CommitMessages(ctx context.Context, msgs ...kafkago.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.topic.mu.Lock()
+	defer r.topic.mu.Unlock()
+	for _, m := range msgs {
+		if m.Offset+1 > r.topic.committed[r.group] {
+			r.topic.committed[r.group] = m.Offset + 1
+		}
+	}
+	return nil
+}
+
+func (r *Reader) Close() error {
+	r.topic.mu.Lock()
+	defer r.topic.mu.Unlock()
+	r.closed = true
+	return nil
+}
@@ -0,0 +1,99 @@
+// Package producer публикует доменные события в топик. Запись подтверждается всеми
+// репликами (RequiredAcks = all), а балансировщик по ключу кладёт события книги в одну партицию.
+package producer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"solid/metrics"
+	"solid/telemetry"
+
+	"kafka/internal/event"
+)
+
+// Writer - часть kafka.Writer, нужная продюсеру; в проверках её заменяет memlog.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafkago.Message) error
+	Close() error
+}
+
+type Producer struct {
+	w Writer
+	// Tracer, если задан, пишет спан отправки на каждое событие и кладёт контекст трассы
+	// в заголовки сообщения, откуда его продолжит потребитель.
+	Tracer trace.TracerProvider
+	// Metrics, если задан, считает отправленные события и ошибки записи.
+	Metrics *metrics.Queue
+}
+
+func New(w Writer) *Producer {
+	return &Producer{w: w}
+}
+
+// NewKafkaWriter настраивает kafka.Writer для топика событий.
+func NewKafkaWriter(brokers []string, topic string) *kafkago.Writer {
+	return &kafkago.Writer{
+		Addr:         kafkago.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafkago.Hash{},
+		RequiredAcks: kafkago.RequireAll,
+		// Топик создаётся заранее (docker-compose), автосоздание скрыло бы опечатку в имени.
+		AllowAutoTopicCreation: false,
+	}
+}
+
+func (p *Producer) Publish(ctx context.Context, events ...event.Event) (err error) {
+	if p.Metrics != nil {
+		// Пачка пишется одним вызовом, и у всех её событий общие время и исход.
+		defer func(start time.Time) {
+			for range events {
+				p.Metrics.Publish("kafka", topic(p.w), start, err)
+			}
+		}(time.Now())
+	}
+	msgs := make([]kafkago.Message, 0, len(events))
+	spans := make([]trace.Span, 0, len(events))
+	defer func() {
+		for _, span := range spans {
+			telemetry.End(span, err)
+		}
+	}()
+	for _, e := range events {
+		b, err := e.Encode()
+		if err != nil {
+			return fmt.Errorf("producer: encode %s: %w", e.ID, err)
+		}
+		headers := []kafkago.Header{{Key: "type", Value: []byte(e.Type)}}
+		if p.Tracer != nil {
+			carrier := propagation.MapCarrier{}
+			_, span := telemetry.StartSend(ctx, p.Tracer, "kafka", topic(p.w), carrier)
+			span.SetAttributes(attribute.String("messaging.message.id", e.ID), attribute.String("messaging.kafka.message.key", e.BookID))
+			spans = append(spans, span)
+			for k, v := range carrier {
+				headers = append(headers, kafkago.Header{Key: k, Value: []byte(v)})
+			}
+		}
+		msgs = append(msgs, kafkago.Message{Key: e.Key(), Value: b, Headers: headers})
+	}
+	if err := p.w.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("producer: publish %d events: %w", len(msgs), err)
+	}
+	return nil
+}
+
+// topic - имя топика для спанов и метрик: kafka.Writer его знает, у остальных Writer его нет.
+func topic(w Writer) string {
+	if kw, ok := w.(*kafkago.Writer); ok && kw.Topic != "" {
+		return kw.Topic
+	}
+	return "events"
+}
+
+func (p *Producer) Close() error { return p.w.Close() }
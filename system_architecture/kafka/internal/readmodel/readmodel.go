@@ -0,0 +1,56 @@
+// Package readmodel - модель чтения, которую строит потребитель: доступность книг
+// и число выдач. Apply сам повторы не отсеивает: доставка «хотя бы один раз» означает
+// дубли, и их до модели не пускает idempotency.Guard вокруг обработчика.
+package readmodel
+
+import (
+	"sort"
+	"sync"
+
+	"kafka/internal/event"
+)
+
+type Book struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Available bool   `json:"available"`
+	LentTo    string `json:"lent_to,omitempty"`
+	TimesLent int    `json:"times_lent"`
+}
+
+type Model struct {
+	mu    sync.RWMutex
+	books map[string]Book
+}
+
+func New() *Model {
+	return &Model{books: map[string]Book{}}
+}
+
+// Apply применяет событие. Повторное BookLent посчитает выдачу дважды.
+func (m *Model) Apply(e event.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b := m.books[e.BookID]
+	switch e.Type {
+	case event.BookAdded:
+		b = Book{ID: e.BookID, Title: e.Title, Available: true}
+	case event.BookLent:
+		b.Available, b.LentTo = false, e.Member
+		b.TimesLent++
+	case event.BookReturned:
+		b.Available, b.LentTo = true, ""
+	}
+	m.books[e.BookID] = b
+}
+
+func (m *Model) Books() []Book {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Book, 0, len(m.books))
+	for _, b := range m.books {
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
@@ -0,0 +1,308 @@
+// Package scenario - проверка доставки «хотя бы один раз», общая для топика в памяти
+// и настоящего брокера: первый потребитель падает между обработкой и фиксацией,
+// второй получает событие повторно, отсеивает дубль и отправляет ядовитые сообщения в DLQ.
+// Трасса продюсера доходит до обработчика в заголовках сообщения, а метрики очереди
+// считают каждое событие один раз.
+//
+// Фиксация проверяется по смещению группы (Env.Committed), а не по поведению
+// обработчика: после падения оно стоит на необработанном событии, после второго
+// потребителя - за последним. Сообщение, которое не удалось положить в DLQ, не
+// фиксируется, и следующий потребитель получает его снова.
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"solid/messaging/idempotency"
+	"solid/metrics"
+
+	"kafka/internal/consumer"
+	"kafka/internal/event"
+	"kafka/internal/producer"
+	"kafka/internal/readmodel"
+)
+
+// Env - куда писать и откуда читать; топики должны быть пустыми и с одной партицией.
+type Env struct {
+	Events     producer.Writer
+	DeadLetter producer.Writer
+	// NewReader открывает читателя группы с её зафиксированного смещения.
+	NewReader func() consumer.Reader
+	// DeadLetters читает n сообщений из DLQ.
+	DeadLetters func(ctx context.Context, n int) ([]kafkago.Message, error)
+	// Committed - зафиксированное смещение группы: следующее, которое получит новый читатель.
+	Committed func(ctx context.Context) (int64, error)
+	Timeout   time.Duration
+}
+
+func Run(w io.Writer, env Env) error {
+	ctx, cancel := context.WithTimeout(context.Background(), env.Timeout)
+	defer cancel()
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	events := []event.Event{
+		{ID: "e1", Type: event.BookAdded, BookID: "dune", Title: "Dune", OccurredAt: at},
+		{ID: "e2", Type: event.BookAdded, BookID: "solaris", Title: "Solaris", OccurredAt: at},
+		{ID: "e3", Type: event.BookLent, BookID: "dune", Member: "ann", OccurredAt: at},
+	}
+	tail := []event.Event{
+		{ID: "e4", Type: event.BookLent, BookID: "solaris", Member: "flaky", OccurredAt: at},
+		{ID: "e5", Type: event.BookReturned, BookID: "dune", Member: "ann", OccurredAt: at},
+	}
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(context.Background())
+	reg := metrics.New("scenario")
+	queue := reg.Queue()
+	p := producer.New(env.Events)
+	p.Tracer = tp
+	p.Metrics = queue
+	if err := p.Publish(ctx, events...); err != nil {
+		return err
+	}
+	if err := env.Events.WriteMessages(ctx, kafkago.Message{Key: []byte("dune"), Value: []byte("{not json")}); err != nil {
+		return err
+	}
+	traced, span := tp.Tracer("scenario").Start(ctx, "return books")
+	err := p.Publish(traced, tail...)
+	span.End()
+	if err != nil {
+		return err
+	}
+	const total = 6
+
+	model := readmodel.New()
+	guard := &idempotency.Guard{Store: idempotency.NewMemory()}
+	var mu sync.Mutex
+	failedOnce := map[string]bool{}
+	var returned trace.TraceID
+	handle := idempotency.Wrap(guard, event.ID, func(ctx context.Context, e event.Event) error {
+		if e.ID == "e5" {
+			returned = trace.SpanContextFromContext(ctx).TraceID()
+		}
+		// «flaky» не обрабатывается никогда, возврат e5 - со второй попытки.
+		if e.Member == "flaky" {
+			return errors.New("member service rejected flaky")
+		}
+		mu.Lock()
+		first := e.ID == "e5" && !failedOnce[e.ID]
+		failedOnce[e.ID] = true
+		mu.Unlock()
+		if first {
+			return errors.New("transient failure")
+		}
+		model.Apply(e)
+		return nil
+	})
+
+	// Первый потребитель обрабатывает e3 и падает до фиксации смещения.
+	crash, stop := context.WithCancel(ctx)
+	first := &consumer.Consumer{Reader: env.NewReader(), Handle: func(ctx context.Context, e event.Event) error {
+		err := handle(ctx, e)
+		if e.ID == "e3" {
+			stop()
+		}
+		return err
+	}}
+	if err := first.Run(crash); err != nil {
+		return err
+	}
+	first.Reader.Close()
+	// e1 и e2 зафиксированы, e3 обработано, но нет.
+	if err := expectCommitted(ctx, env, 2); err != nil {
+		return fmt.Errorf("scenario: after the crash: %w", err)
+	}
+	fmt.Fprintf(w, "first consumer stopped after e3, before commit: group offset 2\n")
+
+	done, finish := context.WithCancel(ctx)
+	committed := 0
+	resumed := int64(-1)
+	second := &consumer.Consumer{
+		Reader: env.NewReader(), Handle: handle, DeadLetter: env.DeadLetter,
+		MaxAttempts: 3, Backoff: time.Millisecond, Tracer: tp, Metrics: queue,
+		Committed: func(m kafkago.Message) {
+			if resumed < 0 {
+				resumed = m.Offset
+			}
+			if committed++; m.Offset == total-1 {
+				finish()
+			}
+		},
+	}
+	if err := second.Run(done); err != nil {
+		return err
+	}
+	second.Reader.Close()
+	if ctx.Err() != nil {
+		return fmt.Errorf("scenario: timed out after %d commits", committed)
+	}
+	fmt.Fprintf(w, "second consumer committed %d messages, %d duplicate(s) skipped\n", committed, guard.Stats().Duplicates)
+	if dups := guard.Stats().Duplicates; committed != total-2 || dups != 1 {
+		return fmt.Errorf("scenario: committed %d, duplicates %d; want %d and 1", committed, dups, total-2)
+	}
+	if resumed != 2 {
+		return fmt.Errorf("scenario: second consumer resumed at offset %d, want 2", resumed)
+	}
+	if err := expectCommitted(ctx, env, total); err != nil {
+		return fmt.Errorf("scenario: after the second consumer: %w", err)
+	}
+
+	if want := span.SpanContext().TraceID(); returned != want {
+		return fmt.Errorf("scenario: e5 handled in trace %s, want %s", returned, want)
+	}
+	fmt.Fprintf(w, "e5 handled in the producer's trace %s\n", returned)
+
+	books := model.Books()
+	fmt.Fprintf(w, "read model: %+v\n", books)
+	if len(books) != 2 || !books[0].Available || books[0].TimesLent != 1 || !books[1].Available {
+		return fmt.Errorf("scenario: unexpected read model %+v", books)
+	}
+	dlq, err := env.DeadLetters(ctx, 2)
+	if err != nil {
+		return fmt.Errorf("scenario: read dead letters: %w", err)
+	}
+	for _, m := range dlq {
+		fmt.Fprintf(w, "dead letter: %s (%s)\n", m.Value, header(m, "error"))
+	}
+	if err := expectDeadLetters(dlq, []deadLetter{
+		// Неразбираемое не повторяется: повтор дал бы ту же ошибку.
+		{offset: 3, key: "dune", attempts: "0", cause: "invalid character"},
+		{offset: 4, key: "solaris", attempts: "3", cause: "member service rejected flaky"},
+	}); err != nil {
+		return err
+	}
+	// Ядовитое сообщение записано мимо продюсера; второй потребитель обработал всё после e2.
+	published, processed, err := counts(reg)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "metrics: %d published, %d processed\n", published, processed)
+	if published != total-1 || processed != total-2 {
+		return fmt.Errorf("scenario: metrics counted %d published and %d processed, want %d and %d", published, processed, total-1, total-2)
+	}
+	return deadLetterDown(ctx, w, env, handle, total)
+}
+
+// deadLetterDown - DLQ недоступен: ядовитое сообщение не фиксируется, потребитель
+// останавливается с ошибкой, а следующий получает сообщение снова и доводит до DLQ.
+func deadLetterDown(ctx context.Context, w io.Writer, env Env, handle consumer.Handler, offset int64) error {
+	if err := env.Events.WriteMessages(ctx, kafkago.Message{Key: []byte("solaris"), Value: []byte("also not json")}); err != nil {
+		return err
+	}
+	down := errors.New("dead-letter topic unavailable")
+	stuck := &consumer.Consumer{Reader: env.NewReader(), Handle: handle, DeadLetter: failingWriter{down}}
+	err := stuck.Run(ctx)
+	stuck.Reader.Close()
+	if !errors.Is(err, down) {
+		return fmt.Errorf("scenario: consumer with a broken DLQ returned %v, want %v", err, down)
+	}
+	if err := expectCommitted(ctx, env, offset); err != nil {
+		return fmt.Errorf("scenario: after a failed dead-letter write: %w", err)
+	}
+	fmt.Fprintf(w, "dead-letter write failed: consumer stopped, offset %d left uncommitted\n", offset)
+
+	done, finish := context.WithCancel(ctx)
+	retry := &consumer.Consumer{Reader: env.NewReader(), Handle: handle, DeadLetter: env.DeadLetter,
+		Committed: func(m kafkago.Message) {
+			if m.Offset == offset {
+				finish()
+			}
+		}}
+	err = retry.Run(done)
+	retry.Reader.Close()
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("scenario: timed out redelivering offset %d", offset)
+	}
+	if err := expectCommitted(ctx, env, offset+1); err != nil {
+		return fmt.Errorf("scenario: after redelivery: %w", err)
+	}
+	dlq, err := env.DeadLetters(ctx, 3)
+	if err != nil {
+		return fmt.Errorf("scenario: read dead letters: %w", err)
+	}
+	if err := expectDeadLetters(dlq[2:], []deadLetter{{offset: offset, key: "solaris", attempts: "0", cause: "invalid character"}}); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "next consumer redelivered offset %d to the dead-letter topic and committed it\n", offset)
+	return nil
+}
+
+func expectCommitted(ctx context.Context, env Env, want int64) error {
+	got, err := env.Committed(ctx)
+	if err != nil {
+		return fmt.Errorf("read committed offset: %w", err)
+	}
+	if got != want {
+		return fmt.Errorf("group offset %d, want %d", got, want)
+	}
+	return nil
+}
+
+// deadLetter - что должно быть в сообщении DLQ: откуда оно, сколько было попыток и почему.
+type deadLetter struct {
+	offset   int64
+	key      string
+	attempts string
+	cause    string
+}
+
+func expectDeadLetters(got []kafkago.Message, want []deadLetter) error {
+	if len(got) != len(want) {
+		return fmt.Errorf("scenario: %d dead letters, want %d", len(got), len(want))
+	}
+	for i, d := range want {
+		m := got[i]
+		source := fmt.Sprintf("/0/%d", d.offset)
+		if string(m.Key) != d.key || header(m, "attempts") != d.attempts ||
+			!strings.HasSuffix(header(m, "source"), source) || !strings.Contains(header(m, "error"), d.cause) {
+			return fmt.Errorf("scenario: dead letter %d: key %q, attempts %q, source %q, error %q; want key %q, attempts %s, source *%s, error with %q",
+				i, m.Key, header(m, "attempts"), header(m, "source"), header(m, "error"), d.key, d.attempts, source, d.cause)
+		}
+	}
+	return nil
+}
+
+type failingWriter struct{ err error }
+
+func (f failingWriter) WriteMessages(ctx context.Context, msgs ...kafkago.Message) error {
+	return f.err
+}
+func (f failingWriter) Close() error { return nil }
+
+func counts(reg *metrics.Registry) (published, processed int, err error) {
+	families, err := reg.Gatherer().Gather()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			switch f.GetName() {
+			case "scenario_messaging_publish_requests_total":
+				published += int(m.GetCounter().GetValue())
+			case "scenario_messaging_process_requests_total":
+				processed += int(m.GetCounter().GetValue())
+			}
+		}
+	}
+	return published, processed, nil
+}
+
+func header(m kafkago.Message, key string) string {
+	for _, h := range m.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
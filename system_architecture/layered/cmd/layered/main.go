@@ -0,0 +1,62 @@
+// Команда layered поднимает сервис библиотеки, собранный по слоям:
+// handler → service → repository. Все зависимости связываются здесь явно.
+//
+//	layered [-addr :8081]   поднять HTTP API
+//	layered check           проверить каждый слой: хранилище, правила сервиса на
+//	                        хранилище в памяти, HTTP через httptest на подделке сервиса
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"solid/app"
+	"solid/health"
+
+	"layered/internal/handler"
+	"layered/internal/repository"
+	"layered/internal/service"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		if err := check(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	addr := flag.String("addr", ":8081", "HTTP listen address")
+	flag.Parse()
+	logger := log.Default()
+
+	store := repository.NewMemory()
+	library := service.NewLibrary(store.Books(), store.Loans())
+	h := handler.New(library, logger)
+
+	a := app.New(app.Config{Logger: logger})
+	mux := http.NewServeMux()
+	mux.Handle("/", h.Routes())
+	health.New(health.Config{}).Register(mux)
+	a.HTTP("http", &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second})
+	logger.Printf("layered library listening on %s", *addr)
+	if err := a.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// check идёт снизу вверх: верхний слой проверяется, когда нижние уже прошли.
+func check(w io.Writer) error {
+	for _, layer := range []func(io.Writer) error{repository.Check, service.Check, handler.Check} {
+		if err := layer(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"layered/internal/model"
+)
+
+// fakeLibrary отвечает заранее заданной ошибкой или фиксированными данными и
+// запоминает, с чем его вызвали: слой представления проверяется без сервиса.
+type fakeLibrary struct {
+	err    error
+	called bool
+	args   []any
+}
+
+func (f *fakeLibrary) call(args ...any) error {
+	f.called, f.args = true, args
+	return f.err
+}
+
+func (f *fakeLibrary) AddBook(ctx context.Context, title, author string, year int) (model.Book, error) {
+	return model.Book{ID: "book-1", Title: title, Author: author, Year: year}, f.call(title, author, year)
+}
+
+func (f *fakeLibrary) Books(ctx context.Context) ([]model.Book, error) {
+	return []model.Book{{ID: "book-1", Title: "Dune"}}, f.call()
+}
+
+func (f *fakeLibrary) Book(ctx context.Context, id string) (model.Book, error) {
+	return model.Book{ID: id, Title: "Dune"}, f.call(id)
+}
+
+func (f *fakeLibrary) Lend(ctx context.Context, bookID, borrower string) (model.Loan, error) {
+	return model.Loan{ID: "loan-1", BookID: bookID, Borrower: borrower}, f.call(bookID, borrower)
+}
+
+func (f *fakeLibrary) Return(ctx context.Context, loanID string) (model.Loan, error) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	return model.Loan{ID: loanID, ReturnedAt: &now}, f.call(loanID)
+}
+
+// Check прогоняет запросы через Routes и httptest: маршруты, разбор тела, передачу
+// аргументов сервису и перевод ошибок предметной области в статусы.
+func Check(w io.Writer) error {
+	cases := []struct {
+		name         string
+		method, path string
+		body         string
+		err          error
+		status       int
+		// args - с чем должен быть вызван сервис; nil - не должен вызываться.
+		args []any
+		// contains - подстрока тела ответа.
+		contains string
+	}{
+		{"list books", "GET", "/books", "", nil, http.StatusOK, []any{}, `"books":[{"id":"book-1"`},
+		{"add book", "POST", "/books", `{"title":"Dune","author":"Herbert","year":1965}`, nil, http.StatusCreated, []any{"Dune", "Herbert", 1965}, `"id":"book-1"`},
+		{"add invalid book", "POST", "/books", `{"title":""}`, model.ErrInvalid, http.StatusBadRequest, []any{"", "", 0}, `"error":"invalid input"`},
+		{"add with unknown field", "POST", "/books", `{"title":"Dune","isbn":"1"}`, nil, http.StatusBadRequest, nil, "invalid JSON"},
+		{"add malformed JSON", "POST", "/books", `{"title":`, nil, http.StatusBadRequest, nil, "invalid JSON"},
+		{"get book", "GET", "/books/book-7", "", nil, http.StatusOK, []any{"book-7"}, `"id":"book-7"`},
+		{"get unknown book", "GET", "/books/book-7", "", model.ErrNotFound, http.StatusNotFound, []any{"book-7"}, "not found"},
+		{"lend", "POST", "/books/book-7/loans", `{"borrower":"ann"}`, nil, http.StatusCreated, []any{"book-7", "ann"}, `"borrower":"ann"`},
+		{"lend unavailable", "POST", "/books/book-7/loans", `{"borrower":"ann"}`, model.ErrUnavailable, http.StatusConflict, []any{"book-7", "ann"}, "not available"},
+		{"lend over the limit", "POST", "/books/book-7/loans", `{"borrower":"ann"}`, fmt.Errorf("%w: 3 open loans", model.ErrLimitReached), http.StatusConflict, []any{"book-7", "ann"}, "3 open loans"},
+		{"return", "POST", "/loans/loan-1/return", "", nil, http.StatusOK, []any{"loan-1"}, `"returned_at"`},
+		// Подробности внутренней ошибки уходят в журнал, а не клиенту.
+		{"storage failure", "GET", "/books", "", errors.New("disk on fire"), http.StatusInternalServerError, []any{}, `"error":"internal error"`},
+		{"unknown route", "DELETE", "/books/book-7", "", nil, http.StatusMethodNotAllowed, nil, ""},
+	}
+	var logged strings.Builder
+	for _, c := range cases {
+		lib := &fakeLibrary{err: c.err}
+		h := New(lib, log.New(&logged, "", 0))
+		req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+		rec := httptest.NewRecorder()
+		h.Routes().ServeHTTP(rec, req)
+
+		if rec.Code != c.status {
+			return fmt.Errorf("handler: %s: got status %d, want %d (body %s)", c.name, rec.Code, c.status, rec.Body)
+		}
+		if !strings.Contains(rec.Body.String(), c.contains) {
+			return fmt.Errorf("handler: %s: body %s does not contain %s", c.name, rec.Body, c.contains)
+		}
+		// 405 отвечает сам ServeMux, текстом; все ответы обработчиков - JSON.
+		if c.status != http.StatusMethodNotAllowed && !json.Valid(rec.Body.Bytes()) {
+			return fmt.Errorf("handler: %s: body is not JSON: %s", c.name, rec.Body)
+		}
+		if lib.called != (c.args != nil) || fmt.Sprint(lib.args) != fmt.Sprint(c.args) {
+			return fmt.Errorf("handler: %s: service called with %v, want %v", c.name, lib.args, c.args)
+		}
+		if rec.Code == http.StatusCreated && c.path == "/books" && rec.Header().Get("Location") != "/books/book-1" {
+			return fmt.Errorf("handler: %s: got Location %q, want /books/book-1", c.name, rec.Header().Get("Location"))
+		}
+		fmt.Fprintf(w, "ok   handler: %s -> %d\n", c.name, rec.Code)
+	}
+	if !strings.Contains(logged.String(), "disk on fire") {
+		return fmt.Errorf("handler: internal error was not logged")
+	}
+	return nil
+}
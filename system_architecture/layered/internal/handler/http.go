@@ -0,0 +1,140 @@
+// Package handler - слой представления: разбор HTTP-запросов и запись ответов.
+// Обращается только к service; хранилище ему недоступно, и это видно по импортам.
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"layered/internal/model"
+)
+
+// Library - то, что нужно слою представления от сервиса.
+type Library interface {
+	AddBook(ctx context.Context, title, author string, year int) (model.Book, error)
+	Books(ctx context.Context) ([]model.Book, error)
+	Book(ctx context.Context, id string) (model.Book, error)
+	Lend(ctx context.Context, bookID, borrower string) (model.Loan, error)
+	Return(ctx context.Context, loanID string) (model.Loan, error)
+}
+
+type Handler struct {
+	lib Library
+	log *log.Logger
+}
+
+func New(lib Library, logger *log.Logger) *Handler {
+	return &Handler{lib: lib, log: logger}
+}
+
+func (h *Handler) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /books", h.listBooks)
+	mux.HandleFunc("POST /books", h.addBook)
+	mux.HandleFunc("GET /books/{id}", h.getBook)
+	mux.HandleFunc("POST /books/{id}/loans", h.lend)
+	mux.HandleFunc("POST /loans/{id}/return", h.giveBack)
+	return mux
+}
+
+type addBookRequest struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	Year   int    `json:"year"`
+}
+
+type lendRequest struct {
+	Borrower string `json:"borrower"`
+}
+
+func (h *Handler) listBooks(w http.ResponseWriter, r *http.Request) {
+	books, err := h.lib.Books(r.Context())
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"books": books})
+}
+
+func (h *Handler) addBook(w http.ResponseWriter, r *http.Request) {
+	var req addBookRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	b, err := h.lib.AddBook(r.Context(), req.Title, req.Author, req.Year)
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	w.Header().Set("Location", "/books/"+b.ID)
+	writeJSON(w, http.StatusCreated, b)
+}
+
+func (h *Handler) getBook(w http.ResponseWriter, r *http.Request) {
+	b, err := h.lib.Book(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (h *Handler) lend(w http.ResponseWriter, r *http.Request) {
+	var req lendRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	l, err := h.lib.Lend(r.Context(), r.PathValue("id"), req.Borrower)
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, l)
+}
+
+func (h *Handler) giveBack(w http.ResponseWriter, r *http.Request) {
+	l, err := h.lib.Return(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, l)
+}
+
+// fail - единственное место, где ошибки предметной области становятся HTTP-статусами.
+func (h *Handler) fail(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, model.ErrInvalid):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, model.ErrUnavailable), errors.Is(err, model.ErrLimitReached):
+		writeError(w, http.StatusConflict, err)
+	default:
+		h.log.Printf("internal error: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+	}
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
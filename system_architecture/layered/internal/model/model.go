@@ -0,0 +1,35 @@
+// Package model - сущности и ошибки предметной области, общие для всех слоёв.
+// Слои идут сверху вниз: handler → service → repository; model не зависит ни от кого.
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalid      = errors.New("invalid input")
+	ErrUnavailable  = errors.New("book is not available")
+	ErrLimitReached = errors.New("borrower reached the loan limit")
+)
+
+type Book struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	Year   int    `json:"year"`
+}
+
+type Loan struct {
+	ID         string     `json:"id"`
+	BookID     string     `json:"book_id"`
+	Borrower   string     `json:"borrower"`
+	LoanedAt   time.Time  `json:"loaned_at"`
+	DueAt      time.Time  `json:"due_at"`
+	ReturnedAt *time.Time `json:"returned_at,omitempty"`
+}
+
+func (l Loan) Open() bool {
+	return l.ReturnedAt == nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"layered/internal/model"
+)
+
+// Check проверяет Memory: хранение без правил - номера, поиск, порядок списков и
+// то, что закрытые выдачи не считаются открытыми.
+func Check(w io.Writer) error {
+	ctx := context.Background()
+	m := NewMemory()
+	books, loans := m.Books(), m.Loans()
+
+	dune, err := books.Create(ctx, model.Book{Title: "Dune", Author: "Herbert", Year: 1965})
+	if err != nil {
+		return fmt.Errorf("repository: create book: %w", err)
+	}
+	if _, err := books.Create(ctx, model.Book{Title: "Anathem", Author: "Stephenson", Year: 2008}); err != nil {
+		return fmt.Errorf("repository: create book: %w", err)
+	}
+	if dune.ID != "book-1" {
+		return fmt.Errorf("repository: create book: got id %q, want book-1", dune.ID)
+	}
+	if got, err := books.Get(ctx, dune.ID); err != nil || got != dune {
+		return fmt.Errorf("repository: get book: got %+v, %v", got, err)
+	}
+	list, err := books.List(ctx)
+	if err != nil || len(list) != 2 || list[0].Title != "Anathem" || list[1].Title != "Dune" {
+		return fmt.Errorf("repository: list books: want sorted by title, got %+v, %v", list, err)
+	}
+
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	first, _ := loans.Create(ctx, model.Loan{BookID: dune.ID, Borrower: "ann", LoanedAt: now})
+	second, _ := loans.Create(ctx, model.Loan{BookID: "book-2", Borrower: "ann", LoanedAt: now})
+	// Номера общие для книг и выдач: одно хранилище, одна последовательность.
+	if first.ID != "loan-3" || second.ID != "loan-4" {
+		return fmt.Errorf("repository: create loans: got ids %q, %q", first.ID, second.ID)
+	}
+	first.ReturnedAt = &now
+	if err := loans.Update(ctx, first); err != nil {
+		return fmt.Errorf("repository: update loan: %w", err)
+	}
+	byBook, _ := loans.OpenByBook(ctx, dune.ID)
+	byBorrower, _ := loans.OpenByBorrower(ctx, "ann")
+	if len(byBook) != 0 || len(byBorrower) != 1 || byBorrower[0].ID != second.ID {
+		return fmt.Errorf("repository: open loans: returned loan still open (by book %v, by borrower %v)", byBook, byBorrower)
+	}
+
+	missing := []struct {
+		name string
+		err  error
+	}{
+		{"get unknown book", errOf(books.Get(ctx, "book-9"))},
+		{"get unknown loan", errOf(loans.Get(ctx, "loan-9"))},
+		{"update unknown loan", loans.Update(ctx, model.Loan{ID: "loan-9"})},
+	}
+	for _, c := range missing {
+		if !errors.Is(c.err, model.ErrNotFound) {
+			return fmt.Errorf("repository: %s: got %v, want %v", c.name, c.err, model.ErrNotFound)
+		}
+	}
+	fmt.Fprintln(w, "ok   repository: ids, lookups, sorted lists, open loans, not found")
+	return nil
+}
+
+func errOf[T any](_ T, err error) error {
+	return err
+}
@@ -0,0 +1,134 @@
+// Package repository - слой доступа к данным. Он только хранит и находит записи,
+// бизнес-правил здесь нет. Сервис знает лишь интерфейсы, реализация подставляется в main.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"layered/internal/model"
+)
+
+type Books interface {
+	Create(ctx context.Context, b model.Book) (model.Book, error)
+	Get(ctx context.Context, id string) (model.Book, error)
+	List(ctx context.Context) ([]model.Book, error)
+}
+
+type Loans interface {
+	Create(ctx context.Context, l model.Loan) (model.Loan, error)
+	Get(ctx context.Context, id string) (model.Loan, error)
+	Update(ctx context.Context, l model.Loan) error
+	OpenByBook(ctx context.Context, bookID string) ([]model.Loan, error)
+	OpenByBorrower(ctx context.Context, borrower string) ([]model.Loan, error)
+}
+
+// Memory хранит книги и выдачи в памяти; идентификаторы - последовательные номера.
+type Memory struct {
+	mu    sync.RWMutex
+	books map[string]model.Book
+	loans map[string]model.Loan
+	seq   int
+}
+
+var (
+	_ Books = MemoryBooks{}
+	_ Loans = MemoryLoans{}
+)
+
+func NewMemory() *Memory {
+	return &Memory{books: make(map[string]model.Book), loans: make(map[string]model.Loan)}
+}
+
+// Books и Loans - два представления одного хранилища, по интерфейсу на агрегат.
+func (m *Memory) Books() MemoryBooks { return MemoryBooks{m} }
+func (m *Memory) Loans() MemoryLoans { return MemoryLoans{m} }
+
+func (m *Memory) nextID(prefix string) string {
+	m.seq++
+	return fmt.Sprintf("%s-%d", prefix, m.seq)
+}
+
+type MemoryBooks struct{ m *Memory }
+
+func (r MemoryBooks) Create(ctx context.Context, b model.Book) (model.Book, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	b.ID = r.m.nextID("book")
+	r.m.books[b.ID] = b
+	return b, nil
+}
+
+func (r MemoryBooks) Get(ctx context.Context, id string) (model.Book, error) {
+	r.m.mu.RLock()
+	defer r.m.mu.RUnlock()
+	b, ok := r.m.books[id]
+	if !ok {
+		return model.Book{}, fmt.Errorf("book %s: %w", id, model.ErrNotFound)
+	}
+	return b, nil
+}
+
+func (r MemoryBooks) List(ctx context.Context) ([]model.Book, error) {
+	r.m.mu.RLock()
+	list := make([]model.Book, 0, len(r.m.books))
+	for _, b := range r.m.books {
+		list = append(list, b)
+	}
+	r.m.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Title < list[j].Title })
+	return list, nil
+}
+
+type MemoryLoans struct{ m *Memory }
+
+func (r MemoryLoans) Create(ctx context.Context, l model.Loan) (model.Loan, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	l.ID = r.m.nextID("loan")
+	r.m.loans[l.ID] = l
+	return l, nil
+}
+
+func (r MemoryLoans) Get(ctx context.Context, id string) (model.Loan, error) {
+	r.m.mu.RLock()
+	defer r.m.mu.RUnlock()
+	l, ok := r.m.loans[id]
+	if !ok {
+		return model.Loan{}, fmt.Errorf("loan %s: %w", id, model.ErrNotFound)
+	}
+	return l, nil
+}
+
+func (r MemoryLoans) Update(ctx context.Context, l model.Loan) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+	if _, ok := r.m.loans[l.ID]; !ok {
+		return fmt.Errorf("loan %s: %w", l.ID, model.ErrNotFound)
+	}
+	r.m.loans[l.ID] = l
+	return nil
+}
+
+func (r MemoryLoans) OpenByBook(ctx context.Context, bookID string) ([]model.Loan, error) {
+	return r.open(func(l model.Loan) bool { return l.BookID == bookID }), nil
+}
+
+func (r MemoryLoans) OpenByBorrower(ctx context.Context, borrower string) ([]model.Loan, error) {
+	return r.open(func(l model.Loan) bool { return l.Borrower == borrower }), nil
+}
+
+func (r MemoryLoans) open(match func(model.Loan) bool) []model.Loan {
+	r.m.mu.RLock()
+	defer r.m.mu.RUnlock()
+	var list []model.Loan
+	for _, l := range r.m.loans {
+		if l.Open() && match(l) {
+			list = append(list, l)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
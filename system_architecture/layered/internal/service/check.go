@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"layered/internal/model"
+	"layered/internal/repository"
+)
+
+// Check проверяет правила сервиса на хранилище в памяти с остановленными часами:
+// проверку входных данных, доступность книги, лимит выдач и возврат.
+func Check(w io.Writer) error {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	newLibrary := func() *Library {
+		store := repository.NewMemory()
+		s := NewLibrary(store.Books(), store.Loans())
+		s.now = func() time.Time { return now }
+		return s
+	}
+
+	adds := []struct {
+		name          string
+		title, author string
+		year          int
+		want          error
+		// stored - с каким названием книга сохранена, если добавлена.
+		stored string
+	}{
+		{"valid book", "Dune", "Herbert", 1965, nil, "Dune"},
+		{"title and author trimmed", "  Dune ", " Herbert", 1965, nil, "Dune"},
+		{"book from this year", "New", "Author", now.Year(), nil, "New"},
+		{"empty title", "", "Herbert", 1965, model.ErrInvalid, ""},
+		{"blank author", "Dune", "   ", 1965, model.ErrInvalid, ""},
+		{"negative year", "Dune", "Herbert", -1, model.ErrInvalid, ""},
+		{"year in the future", "Dune", "Herbert", now.Year() + 1, model.ErrInvalid, ""},
+	}
+	for _, c := range adds {
+		b, err := newLibrary().AddBook(ctx, c.title, c.author, c.year)
+		if !errors.Is(err, c.want) || b.Title != c.stored || (err == nil) == (b.ID == "") {
+			return fmt.Errorf("service: add %s: got %+v, %v, want %v", c.name, b, err, c.want)
+		}
+		fmt.Fprintf(w, "ok   service: add %s\n", c.name)
+	}
+
+	// Каждый случай выдачи начинается с одной и той же библиотеки (lendSetup).
+	lends := []struct {
+		name     string
+		book     string
+		borrower string
+		want     error
+	}{
+		{"free book", "free", "carol", nil},
+		{"borrower trimmed", "free", "  carol ", nil},
+		{"book on loan", "lent", "carol", model.ErrUnavailable},
+		{"same borrower again", "lent", "ann", model.ErrUnavailable},
+		{"borrower at the limit", "free", "bob", model.ErrLimitReached},
+		{"unknown book", "book-99", "carol", model.ErrNotFound},
+		{"empty borrower", "free", " ", model.ErrInvalid},
+	}
+	for _, c := range lends {
+		s := newLibrary()
+		ids, err := lendSetup(ctx, s)
+		if err != nil {
+			return fmt.Errorf("service: lend setup: %w", err)
+		}
+		ids["book-99"] = "book-99"
+		l, err := s.Lend(ctx, ids[c.book], c.borrower)
+		if !errors.Is(err, c.want) {
+			return fmt.Errorf("service: lend %s: got %v, want %v", c.name, err, c.want)
+		}
+		if err == nil && (l.Borrower != "carol" || !l.LoanedAt.Equal(now) || !l.DueAt.Equal(now.Add(LoanPeriod)) || !l.Open()) {
+			return fmt.Errorf("service: lend %s: unexpected loan %+v", c.name, l)
+		}
+		fmt.Fprintf(w, "ok   service: lend %s\n", c.name)
+	}
+
+	s := newLibrary()
+	b, err := s.AddBook(ctx, "Dune", "Herbert", 1965)
+	if err != nil {
+		return fmt.Errorf("service: add: %w", err)
+	}
+	l, err := s.Lend(ctx, b.ID, "ann")
+	if err != nil {
+		return fmt.Errorf("service: lend: %w", err)
+	}
+	returned, err := s.Return(ctx, l.ID)
+	if err != nil || returned.Open() || !returned.ReturnedAt.Equal(now) {
+		return fmt.Errorf("service: return: got %+v, %v", returned, err)
+	}
+	if _, err := s.Lend(ctx, b.ID, "bob"); err != nil {
+		return fmt.Errorf("service: lend a returned book: %w", err)
+	}
+	returns := []struct {
+		name string
+		loan string
+		want error
+	}{
+		{"return twice", l.ID, model.ErrInvalid},
+		{"return unknown loan", "loan-99", model.ErrNotFound},
+	}
+	for _, c := range returns {
+		if _, err := s.Return(ctx, c.loan); !errors.Is(err, c.want) {
+			return fmt.Errorf("service: %s: got %v, want %v", c.name, err, c.want)
+		}
+	}
+	fmt.Fprintln(w, "ok   service: return frees the book, twice and unknown are rejected")
+	return nil
+}
+
+// lendSetup - книга lent выдана ann, у bob MaxOpenLoans книг, free свободна.
+func lendSetup(ctx context.Context, s *Library) (map[string]string, error) {
+	ids := map[string]string{}
+	for i, title := range []string{"lent", "free"} {
+		b, err := s.AddBook(ctx, title, "Author", 2000+i)
+		if err != nil {
+			return nil, err
+		}
+		ids[title] = b.ID
+	}
+	if _, err := s.Lend(ctx, ids["lent"], "ann"); err != nil {
+		return nil, err
+	}
+	for i := range MaxOpenLoans {
+		b, err := s.AddBook(ctx, fmt.Sprintf("held %d", i), "Author", 2000)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.Lend(ctx, b.ID, "bob"); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
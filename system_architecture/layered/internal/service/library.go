@@ -0,0 +1,98 @@
+// Package service - слой бизнес-логики: проверка входных данных и правила выдачи.
+// Сервис не знает ни про HTTP, ни про конкретное хранилище - только про интерфейсы repository.
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"layered/internal/model"
+	"layered/internal/repository"
+)
+
+const (
+	LoanPeriod = 14 * 24 * time.Hour
+	// MaxOpenLoans - сколько книг читатель может держать одновременно.
+	MaxOpenLoans = 3
+)
+
+type Library struct {
+	books repository.Books
+	loans repository.Loans
+	now   func() time.Time
+	// mu делает проверку доступности и создание выдачи одной операцией.
+	mu sync.Mutex
+}
+
+func NewLibrary(books repository.Books, loans repository.Loans) *Library {
+	return &Library{books: books, loans: loans, now: time.Now}
+}
+
+func (s *Library) AddBook(ctx context.Context, title, author string, year int) (model.Book, error) {
+	title, author = strings.TrimSpace(title), strings.TrimSpace(author)
+	if title == "" || author == "" {
+		return model.Book{}, fmt.Errorf("%w: title and author are required", model.ErrInvalid)
+	}
+	if year < 0 || year > s.now().Year() {
+		return model.Book{}, fmt.Errorf("%w: year %d", model.ErrInvalid, year)
+	}
+	return s.books.Create(ctx, model.Book{Title: title, Author: author, Year: year})
+}
+
+func (s *Library) Books(ctx context.Context) ([]model.Book, error) {
+	return s.books.List(ctx)
+}
+
+func (s *Library) Book(ctx context.Context, id string) (model.Book, error) {
+	return s.books.Get(ctx, id)
+}
+
+// Lend выдаёт книгу, если у неё нет открытой выдачи и читатель не превысил лимит.
+func (s *Library) Lend(ctx context.Context, bookID, borrower string) (model.Loan, error) {
+	borrower = strings.TrimSpace(borrower)
+	if borrower == "" {
+		return model.Loan{}, fmt.Errorf("%w: borrower is required", model.ErrInvalid)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.books.Get(ctx, bookID); err != nil {
+		return model.Loan{}, err
+	}
+	open, err := s.loans.OpenByBook(ctx, bookID)
+	if err != nil {
+		return model.Loan{}, err
+	}
+	if len(open) > 0 {
+		return model.Loan{}, model.ErrUnavailable
+	}
+	held, err := s.loans.OpenByBorrower(ctx, borrower)
+	if err != nil {
+		return model.Loan{}, err
+	}
+	if len(held) >= MaxOpenLoans {
+		return model.Loan{}, fmt.Errorf("%w: %d open loans", model.ErrLimitReached, len(held))
+	}
+	now := s.now()
+	return s.loans.Create(ctx, model.Loan{BookID: bookID, Borrower: borrower, LoanedAt: now, DueAt: now.Add(LoanPeriod)})
+}
+
+func (s *Library) Return(ctx context.Context, loanID string) (model.Loan, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, err := s.loans.Get(ctx, loanID)
+	if err != nil {
+		return model.Loan{}, err
+	}
+	if !l.Open() {
+		return model.Loan{}, fmt.Errorf("%w: loan %s is already returned", model.ErrInvalid, loanID)
+	}
+	now := s.now()
+	l.ReturnedAt = &now
+	if err := s.loans.Update(ctx, l); err != nil {
+		return model.Loan{}, err
+	}
+	return l, nil
+}
@@ -0,0 +1,268 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// AMQP - Broker поверх RabbitMQ. Соединение восстанавливается лениво: операция,
+// заставшая его закрытым, переподключается с нарастающей паузой и заново объявляет
+// всю топологию, объявленную раньше; потребители подписываются снова.
+type AMQP struct {
+	url string
+	// MaxBackoff ограничивает паузу между попытками подключения, по умолчанию 10 секунд.
+	MaxBackoff time.Duration
+	Log        *log.Logger
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	pub      *amqp.Channel
+	topology []Topology
+	closed   bool
+}
+
+var _ Broker = (*AMQP)(nil)
+
+// DialAMQP подключается сразу, чтобы ошибка в адресе была видна при старте.
+func DialAMQP(url string, logger *log.Logger) (*AMQP, error) {
+	b := &AMQP{url: url, MaxBackoff: 10 * time.Second, Log: logger}
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// connect открывает соединение и канал публикации в режиме подтверждений; вызывается под b.mu.
+func (b *AMQP) connect() error {
+	conn, err := amqp.Dial(b.url)
+	if err != nil {
+		return fmt.Errorf("broker: dial: %w", err)
+	}
+	pub, err := conn.Channel()
+	if err == nil {
+		err = pub.Confirm(false)
+	}
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("broker: open publish channel: %w", err)
+	}
+	for _, t := range b.topology {
+		if err := declare(pub, t); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+	b.conn, b.pub = conn, pub
+	return nil
+}
+
+// session возвращает живое соединение, переподключаясь при необходимости.
+func (b *AMQP) session(ctx context.Context) (*amqp.Connection, *amqp.Channel, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	backoff := 100 * time.Millisecond
+	for {
+		if b.closed {
+			return nil, nil, ErrClosed
+		}
+		if b.conn != nil && !b.conn.IsClosed() && !b.pub.IsClosed() {
+			return b.conn, b.pub, nil
+		}
+		if b.conn != nil {
+			b.conn.Close()
+			b.conn = nil
+		}
+		err := b.connect()
+		if err == nil {
+			b.logf("broker: connected to %s", redact(b.url))
+			continue
+		}
+		b.logf("broker: reconnect in %s: %v", backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		if backoff *= 2; backoff > b.MaxBackoff {
+			backoff = b.MaxBackoff
+		}
+	}
+}
+
+func (b *AMQP) Declare(ctx context.Context, t Topology) error {
+	_, pub, err := b.session(ctx)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := declare(pub, t); err != nil {
+		return err
+	}
+	b.topology = append(b.topology, t)
+	return nil
+}
+
+func declare(ch *amqp.Channel, t Topology) error {
+	for _, e := range t.Exchanges {
+		if err := ch.ExchangeDeclare(e.Name, string(e.Kind), true, false, false, false, nil); err != nil {
+			return fmt.Errorf("broker: declare exchange %q: %w", e.Name, err)
+		}
+	}
+	for _, q := range t.Queues {
+		var args amqp.Table
+		if q.DeadLetter != "" {
+			args = amqp.Table{"x-dead-letter-exchange": q.DeadLetter}
+		}
+		if _, err := ch.QueueDeclare(q.Name, true, false, false, false, args); err != nil {
+			return fmt.Errorf("broker: declare queue %q: %w", q.Name, err)
+		}
+		if err := ch.QueueBind(q.Name, q.Key, q.Exchange, false, nil); err != nil {
+			return fmt.Errorf("broker: bind %q to %q: %w", q.Name, q.Exchange, err)
+		}
+	}
+	return nil
+}
+
+// Publish отправляет постоянное сообщение и ждёт подтверждения. Если соединение
+// оборвалось до подтверждения, сообщение отправляется ещё раз: получатели должны
+// быть готовы к дублям.
+func (b *AMQP) Publish(ctx context.Context, exchange, key string, m Message) error {
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		_, pub, err := b.session(ctx)
+		if err != nil {
+			return err
+		}
+		dc, err := pub.PublishWithDeferredConfirmWithContext(ctx, exchange, key, false, false, amqp.Publishing{
+			Headers:      amqp.Table(m.Headers),
+			Body:         m.Body,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+		})
+		if err == nil {
+			var ok bool
+			if ok, err = dc.WaitContext(ctx); err == nil {
+				if !ok {
+					return fmt.Errorf("%w: %s/%s", ErrNacked, exchange, key)
+				}
+				return nil
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !errors.Is(err, amqp.ErrClosed) {
+			return fmt.Errorf("broker: publish to %s/%s: %w", exchange, key, err)
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("broker: publish to %s/%s: %w", exchange, key, lastErr)
+}
+
+func (b *AMQP) Consume(ctx context.Context, queue string, prefetch int, h Handler) error {
+	for {
+		conn, _, err := b.session(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if err := b.consumeOnce(ctx, conn, queue, prefetch, h); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		b.logf("broker: consumer of %q lost its channel, resubscribing", queue)
+	}
+}
+
+// consumeOnce работает на одном канале, пока он жив; nil - канал закрыт, можно переподписаться.
+func (b *AMQP) consumeOnce(ctx context.Context, conn *amqp.Connection, queue string, prefetch int, h Handler) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil
+	}
+	defer ch.Close()
+	if prefetch <= 0 {
+		prefetch = 1
+	}
+	if err := ch.Qos(prefetch, 0, false); err != nil {
+		return nil
+	}
+	deliveries, err := ch.ConsumeWithContext(ctx, queue, "", false, false, false, false, nil)
+	if err != nil {
+		var aerr *amqp.Error
+		if errors.As(err, &aerr) && aerr.Code == amqp.NotFound {
+			return fmt.Errorf("broker: unknown queue %q", queue)
+		}
+		return nil
+	}
+	for d := range deliveries {
+		del := Delivery{Message: Message{Body: d.Body, Headers: d.Headers}, Queue: queue, Redelivered: d.Redelivered}
+		ack, requeue := settle(del, h(ctx, del))
+		if ack {
+			err = d.Ack(false)
+		} else {
+			err = d.Nack(false, requeue)
+		}
+		if err != nil {
+			// Подтверждение не дошло - брокер вернёт сообщение в очередь сам.
+			b.logf("broker: settle %q delivery %d: %v", queue, d.DeliveryTag, err)
+		}
+	}
+	return nil
+}
+
+// Depth - число сообщений, ждущих в очереди. Пассивное объявление идёт в отдельном
+// канале: на неизвестной очереди брокер закрывает канал, и публикации это не заденет.
+func (b *AMQP) Depth(ctx context.Context, queue string) (int, error) {
+	conn, _, err := b.session(ctx)
+	if err != nil {
+		return 0, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("broker: open channel: %w", err)
+	}
+	defer ch.Close()
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil {
+		return 0, fmt.Errorf("broker: inspect queue %q: %w", queue, err)
+	}
+	return q.Messages, nil
+}
+
+func (b *AMQP) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	if b.conn == nil {
+		return nil
+	}
+	return b.conn.Close()
+}
+
+func (b *AMQP) logf(format string, args ...any) {
+	if b.Log != nil {
+		b.Log.Printf(format, args...)
+	}
+}
+
+// redact убирает пароль из адреса для журнала.
+func redact(url string) string {
+	u, err := amqp.ParseURI(url)
+	if err != nil {
+		return "rabbitmq"
+	}
+	u.Password = ""
+	return fmt.Sprintf("amqp://%s@%s:%d/%s", u.Username, u.Host, u.Port, u.Vhost)
+}
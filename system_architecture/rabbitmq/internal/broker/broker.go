@@ -0,0 +1,78 @@
+// Package broker скрывает RabbitMQ за интерфейсом Broker: топология, публикация
+// с подтверждением и потребление с ручным подтверждением. Переподключение
+// и повторное объявление топологии - забота реализации, а не вызывающего кода.
+package broker
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrClosed = errors.New("broker: closed")
+	// ErrNacked - брокер не подтвердил публикацию.
+	ErrNacked = errors.New("broker: publish was not confirmed")
+	// ErrPermanent помечает ошибку обработчика, которую повтор не исправит:
+	// сообщение сразу уходит в очередь недоставленных.
+	ErrPermanent = errors.New("broker: permanent failure")
+)
+
+type Kind string
+
+const (
+	Direct Kind = "direct"
+	Fanout Kind = "fanout"
+)
+
+type Exchange struct {
+	Name string
+	Kind Kind
+}
+
+// Queue - очередь, привязанная к обменнику. DeadLetter - обменник, в который
+// брокер переложит отклонённые сообщения; его очередь объявляется отдельно.
+type Queue struct {
+	Name       string
+	Exchange   string
+	Key        string
+	DeadLetter string
+}
+
+type Topology struct {
+	Exchanges []Exchange
+	Queues    []Queue
+}
+
+type Message struct {
+	Body    []byte
+	Headers map[string]any
+}
+
+// Delivery - полученное сообщение; Redelivered - его уже отдавали кому-то раньше.
+type Delivery struct {
+	Message
+	Queue       string
+	Redelivered bool
+}
+
+// Handler обрабатывает сообщение. nil - ack; ошибка - повтор один раз,
+// ErrPermanent или повторный сбой - отказ без возврата, то есть в DLQ.
+type Handler func(ctx context.Context, d Delivery) error
+
+type Broker interface {
+	Declare(ctx context.Context, t Topology) error
+	// Publish возвращается после подтверждения брокера (publisher confirms).
+	Publish(ctx context.Context, exchange, key string, m Message) error
+	// Consume обрабатывает очередь, пока не отменят ctx; prefetch ограничивает
+	// число неподтверждённых сообщений у этого потребителя.
+	Consume(ctx context.Context, queue string, prefetch int, h Handler) error
+	Close() error
+}
+
+// settle решает судьбу сообщения по результату обработчика.
+func settle(d Delivery, err error) (ack, requeue bool) {
+	if err == nil {
+		return true, false
+	}
+	return false, !d.Redelivered && !errors.Is(err, ErrPermanent)
+}
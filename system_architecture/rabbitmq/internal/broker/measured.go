@@ -0,0 +1,37 @@
+package broker
+
+import (
+	"context"
+	"time"
+
+	"solid/metrics"
+)
+
+// Measured оборачивает b метриками очередей q: публикации считаются по обменнику,
+// обработка доставок - по очереди, с system="rabbitmq".
+func Measured(b Broker, q *metrics.Queue) Broker {
+	return &measured{Broker: b, q: q}
+}
+
+type measured struct {
+	Broker
+	q *metrics.Queue
+}
+
+var _ Broker = (*measured)(nil)
+
+func (m *measured) Publish(ctx context.Context, exchange, key string, msg Message) error {
+	start := time.Now()
+	err := m.Broker.Publish(ctx, exchange, key, msg)
+	m.q.Publish("rabbitmq", exchange, start, err)
+	return err
+}
+
+func (m *measured) Consume(ctx context.Context, queue string, prefetch int, h Handler) error {
+	return m.Broker.Consume(ctx, queue, prefetch, func(ctx context.Context, d Delivery) error {
+		start := time.Now()
+		err := h(ctx, d)
+		m.q.Process("rabbitmq", queue, start, err)
+		return err
+	})
+}
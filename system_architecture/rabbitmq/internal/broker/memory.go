@@ -0,0 +1,140 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Memory - брокер в памяти с теми же правилами маршрутизации, подтверждения
+// и перекладывания в DLQ. Подходит для проверок без RabbitMQ.
+type Memory struct {
+	mu        sync.Mutex
+	closed    bool
+	exchanges map[string]Kind
+	bindings  map[string][]Queue
+	queues    map[string]*memQueue
+}
+
+type memQueue struct {
+	def   Queue
+	items chan Delivery
+}
+
+var _ Broker = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{exchanges: map[string]Kind{}, bindings: map[string][]Queue{}, queues: map[string]*memQueue{}}
+}
+
+func (m *Memory) Declare(ctx context.Context, t Topology) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range t.Exchanges {
+		if k, ok := m.exchanges[e.Name]; ok && k != e.Kind {
+			return fmt.Errorf("broker: exchange %q redeclared as %s, was %s", e.Name, e.Kind, k)
+		}
+		m.exchanges[e.Name] = e.Kind
+	}
+	for _, q := range t.Queues {
+		if _, ok := m.exchanges[q.Exchange]; !ok {
+			return fmt.Errorf("broker: queue %q binds to unknown exchange %q", q.Name, q.Exchange)
+		}
+		if _, ok := m.queues[q.Name]; ok {
+			continue
+		}
+		m.queues[q.Name] = &memQueue{def: q, items: make(chan Delivery, 1024)}
+		m.bindings[q.Exchange] = append(m.bindings[q.Exchange], q)
+	}
+	return nil
+}
+
+func (m *Memory) Publish(ctx context.Context, exchange, key string, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	kind, ok := m.exchanges[exchange]
+	if !ok {
+		return fmt.Errorf("%w: unknown exchange %q", ErrNacked, exchange)
+	}
+	m.route(exchange, kind, key, msg)
+	return nil
+}
+
+// route кладёт сообщение в привязанные очереди; вызывается под m.mu.
+func (m *Memory) route(exchange string, kind Kind, key string, msg Message) {
+	for _, q := range m.bindings[exchange] {
+		if kind == Fanout || q.Key == key {
+			m.queues[q.Name].items <- Delivery{Message: msg, Queue: q.Name}
+		}
+	}
+}
+
+// Depth - число сообщений, ждущих в очереди.
+func (m *Memory) Depth(ctx context.Context, queue string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q, ok := m.queues[queue]
+	if !ok {
+		return 0, fmt.Errorf("broker: unknown queue %q", queue)
+	}
+	return len(q.items), nil
+}
+
+func (m *Memory) Consume(ctx context.Context, queue string, prefetch int, h Handler) error {
+	m.mu.Lock()
+	q, ok := m.queues[queue]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("broker: unknown queue %q", queue)
+	}
+	// В памяти сообщение берётся только после обработки предыдущего - как prefetch = 1.
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d := <-q.items:
+			ack, requeue := settle(d, h(ctx, d))
+			switch {
+			case ack:
+			case requeue:
+				d.Redelivered = true
+				q.items <- d
+			default:
+				m.deadLetter(q.def, d)
+			}
+		}
+	}
+}
+
+func (m *Memory) deadLetter(q Queue, d Delivery) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if q.DeadLetter == "" {
+		return
+	}
+	headers := map[string]any{"x-first-death-queue": q.Name}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	m.route(q.DeadLetter, m.exchanges[q.DeadLetter], q.Key, Message{Body: d.Body, Headers: headers})
+}
+
+// Len - число сообщений, ждущих в очереди.
+func (m *Memory) Len(queue string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if q, ok := m.queues[queue]; ok {
+		return len(q.items)
+	}
+	return 0
+}
+
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
@@ -0,0 +1,67 @@
+package broker
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"solid/telemetry"
+)
+
+// Traced оборачивает b трассировкой: Publish пишет спан отправки и кладёт контекст
+// трассы в заголовки сообщения, Consume продолжает её спаном обработки на каждую
+// доставку.
+func Traced(b Broker, tp trace.TracerProvider) Broker {
+	return &traced{Broker: b, tp: tp}
+}
+
+type traced struct {
+	Broker
+	tp trace.TracerProvider
+}
+
+var _ Broker = (*traced)(nil)
+
+func (t *traced) Publish(ctx context.Context, exchange, key string, m Message) error {
+	// Заголовки вызывающего не меняются: контекст трассы пишется в копию.
+	headers := make(headerCarrier, len(m.Headers)+2)
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+	ctx, span := telemetry.StartSend(ctx, t.tp, "rabbitmq", exchange, headers)
+	span.SetAttributes(attribute.String("messaging.rabbitmq.destination.routing_key", key))
+	m.Headers = headers
+	err := t.Broker.Publish(ctx, exchange, key, m)
+	telemetry.End(span, err)
+	return err
+}
+
+func (t *traced) Consume(ctx context.Context, queue string, prefetch int, h Handler) error {
+	return t.Broker.Consume(ctx, queue, prefetch, func(ctx context.Context, d Delivery) error {
+		ctx, span := telemetry.StartReceive(ctx, t.tp, "rabbitmq", queue, headerCarrier(d.Headers))
+		span.SetAttributes(attribute.Bool("messaging.rabbitmq.redelivered", d.Redelivered))
+		err := h(ctx, d)
+		telemetry.End(span, err)
+		return err
+	})
+}
+
+// headerCarrier - заголовки AMQP как носитель контекста трассы; нестроковые значения
+// пропагатору не интересны.
+type headerCarrier map[string]any
+
+func (c headerCarrier) Get(key string) string {
+	s, _ := c[key].(string)
+	return s
+}
+
+func (c headerCarrier) Set(key, value string) { c[key] = value }
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,47 @@
+// Package notify - рассылка уведомлений через обменник fanout: каждый подписчик
+// объявляет свою очередь и получает копию каждого уведомления.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"rabbitmq/internal/broker"
+)
+
+const Exchange = "notifications"
+
+type Notification struct {
+	Member string `json:"member"`
+	Text   string `json:"text"`
+}
+
+func Publish(ctx context.Context, b broker.Broker, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("notify: encode: %w", err)
+	}
+	return b.Publish(ctx, Exchange, "", broker.Message{Body: body})
+}
+
+// Declare объявляет очередь подписчика notifications.<name>; уведомления,
+// опубликованные до объявления, в неё не попадут.
+func Declare(ctx context.Context, b broker.Broker, name string) error {
+	return b.Declare(ctx, broker.Topology{
+		Exchanges: []broker.Exchange{{Name: Exchange, Kind: broker.Fanout}},
+		Queues:    []broker.Queue{{Name: Exchange + "." + name, Exchange: Exchange}},
+	})
+}
+
+// Consume передаёт в fn каждое уведомление из очереди подписчика, пока не отменят ctx.
+func Consume(ctx context.Context, b broker.Broker, name string, fn func(Notification)) error {
+	return b.Consume(ctx, Exchange+"."+name, 10, func(ctx context.Context, d broker.Delivery) error {
+		var n Notification
+		if err := json.Unmarshal(d.Body, &n); err != nil {
+			return fmt.Errorf("%w: notify: decode: %v", broker.ErrPermanent, err)
+		}
+		fn(n)
+		return nil
+	})
+}
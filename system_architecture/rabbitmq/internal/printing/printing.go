@@ -0,0 +1,112 @@
+// Package printing - очередь заданий печати с конкурирующими потребителями:
+// несколько принтеров читают одну очередь, и каждое задание достаётся одному из них.
+// Испорченные задания уходят в print.jobs.dead.
+package printing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"solid/messaging/idempotency"
+
+	"rabbitmq/internal/broker"
+)
+
+const (
+	Exchange   = "print"
+	Queue      = "print.jobs"
+	DeadQueue  = "print.jobs.dead"
+	deadLetter = "print.dlx"
+	routingKey = "job"
+)
+
+// Topology - обменник заданий, их очередь и очередь недоставленных.
+var Topology = broker.Topology{
+	Exchanges: []broker.Exchange{{Name: Exchange, Kind: broker.Direct}, {Name: deadLetter, Kind: broker.Direct}},
+	Queues: []broker.Queue{
+		{Name: Queue, Exchange: Exchange, Key: routingKey, DeadLetter: deadLetter},
+		{Name: DeadQueue, Exchange: deadLetter, Key: routingKey},
+	},
+}
+
+type Job struct {
+	ID       string `json:"id"`
+	Document string `json:"document"`
+	Copies   int    `json:"copies"`
+}
+
+func Submit(ctx context.Context, b broker.Broker, j Job) error {
+	body, err := json.Marshal(j)
+	if err != nil {
+		return fmt.Errorf("printing: encode %s: %w", j.ID, err)
+	}
+	return b.Publish(ctx, Exchange, routingKey, broker.Message{Body: body})
+}
+
+// Printer - один из конкурирующих потребителей; Out общий у всех принтеров.
+type Printer struct {
+	Name string
+	Out  *Output
+	// Before, если задан, вызывается перед печатью; ошибка - временный сбой принтера.
+	Before func(Job) error
+	// Guard, если задан, не даёт напечатать задание второй раз, когда его доставили
+	// повторно; у конкурирующих принтеров он должен быть общим.
+	Guard *idempotency.Guard
+}
+
+// Output - что напечатано: принтер каждого задания и общее число отпечатков.
+type Output struct {
+	mu      sync.Mutex
+	ByJob   map[string]string
+	Printed int
+}
+
+func NewOutput() *Output {
+	return &Output{ByJob: map[string]string{}}
+}
+
+// Count - сколько раз печатали, включая повторы одного задания.
+func (o *Output) Count() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.Printed
+}
+
+func (o *Output) Snapshot() map[string]string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	c := make(map[string]string, len(o.ByJob))
+	for k, v := range o.ByJob {
+		c[k] = v
+	}
+	return c
+}
+
+func (p *Printer) Handle(ctx context.Context, d broker.Delivery) error {
+	var j Job
+	if err := json.Unmarshal(d.Body, &j); err != nil {
+		return fmt.Errorf("%w: printing: decode job: %v", broker.ErrPermanent, err)
+	}
+	if j.ID == "" || j.Copies <= 0 {
+		return fmt.Errorf("%w: printing: job %q needs an id and copies", broker.ErrPermanent, j.ID)
+	}
+	if p.Guard == nil {
+		return p.print(j)
+	}
+	return p.Guard.Do(ctx, j.ID, func(ctx context.Context) error { return p.print(j) })
+}
+
+func (p *Printer) print(j Job) error {
+	if p.Before != nil {
+		if err := p.Before(j); err != nil {
+			return err
+		}
+	}
+	p.Out.mu.Lock()
+	defer p.Out.mu.Unlock()
+	p.Out.ByJob[j.ID] = p.Name
+	p.Out.Printed++
+	return nil
+}
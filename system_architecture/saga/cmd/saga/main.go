@@ -0,0 +1,96 @@
+// Команда saga прогоняет сагу заказа в четырёх сценариях: успех, отказ в оплате,
+// зависший платёжный шлюз и перезапуск процесса посреди саги с состоянием на диске.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"saga/internal/order"
+	"saga/internal/saga"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx := context.Background()
+	inv := order.NewInventory(map[string]int{"dune": 3})
+	pay := order.NewPayments()
+	svc := order.Services{Inventory: inv, Payments: pay, Notifier: &order.Notifier{}, ChargeTimeout: 50 * time.Millisecond}
+	s := order.NewSaga(svc, saga.NewMemoryStore())
+
+	cases := []struct {
+		id, member string
+		setup      func()
+		want       saga.Status
+	}{
+		{"o-1", "ann@example.com", func() {}, saga.Completed},
+		{"o-2", "bob@example.com", func() { pay.Decline = true }, saga.Compensated},
+		{"o-3", "bob@example.com", func() { pay.Decline, pay.Delay = false, time.Second }, saga.Compensated},
+		// Уведомление падает после оплаты: деньги возвращаются, резерв снимается.
+		{"o-4", "no-address", func() { pay.Delay = 0 }, saga.Compensated},
+	}
+	for _, c := range cases {
+		c.setup()
+		st, err := s.Start(ctx, c.id, order.Order{ID: c.id, CopyID: "dune", Member: c.member, Amount: 500})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %-11s %s\n", c.id, st.Status, strings.Join(st.Log, " → "))
+		if st.Status != c.want {
+			return fmt.Errorf("saga: %s finished %s, want %s (%s)", c.id, st.Status, c.want, st.Error)
+		}
+	}
+	if inv.Stock["dune"] != 2 || len(pay.Refunded) != 1 {
+		return fmt.Errorf("saga: stock %d, refunds %v; want 2 and [o-4]", inv.Stock["dune"], pay.Refunded)
+	}
+	return restart(inv)
+}
+
+// restart останавливает первый «процесс» во время оплаты и доводит сагу вторым.
+func restart(inv *order.Inventory) error {
+	dir, err := os.MkdirTemp("", "saga")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	store := saga.FileStore{Dir: dir}
+
+	pay := order.NewPayments()
+	pay.Delay = time.Second
+	notifier := &order.Notifier{}
+	first := order.NewSaga(order.Services{Inventory: inv, Payments: pay, Notifier: notifier}, store)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := first.Start(ctx, "o-5", order.Order{ID: "o-5", CopyID: "dune", Member: "cat@example.com", Amount: 700}); err == nil {
+		return fmt.Errorf("saga: first process was expected to stop mid-saga")
+	}
+	st, err := store.Load(context.Background(), "o-5")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("o-5 stopped at step %d (%s)\n", st.Next, st.Status)
+
+	pay.Delay = 0
+	second := order.NewSaga(order.Services{Inventory: inv, Payments: pay, Notifier: notifier}, store)
+	resumed, err := second.Resume(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(resumed) != 1 || resumed[0].Status != saga.Completed {
+		return fmt.Errorf("saga: resumed %+v", resumed)
+	}
+	if amount, ok := pay.Charged("o-5"); !ok || amount != 700 || len(notifier.Sent) != 1 || inv.Stock["dune"] != 1 {
+		return fmt.Errorf("saga: after resume charged=%v sent=%v stock=%d", ok, notifier.Sent, inv.Stock["dune"])
+	}
+	fmt.Printf("o-5 %-11s %s\n", resumed[0].Status, strings.Join(resumed[0].Log, " → "))
+	return nil
+}
@@ -0,0 +1,179 @@
+// Package order - сага оформления заказа: зарезервировать экземпляр → списать оплату →
+// уведомить читателя. Резерв и оплата компенсируются снятием резерва и возвратом;
+// уведомление идёт последним и компенсации не требует.
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"saga/internal/saga"
+)
+
+var (
+	ErrOutOfStock = errors.New("order: copy is out of stock")
+	ErrDeclined   = errors.New("order: payment declined")
+)
+
+// Order - данные саги; идентификаторы резерва и платежа заполняют шаги.
+type Order struct {
+	ID          string `json:"id"`
+	CopyID      string `json:"copy_id"`
+	Member      string `json:"member"`
+	Amount      int    `json:"amount"`
+	Reservation string `json:"reservation,omitempty"`
+	Payment     string `json:"payment,omitempty"`
+}
+
+// Inventory - склад экземпляров; резерв привязан к заказу, поэтому повтор безопасен.
+type Inventory struct {
+	mu       sync.Mutex
+	Stock    map[string]int
+	reserved map[string]string
+}
+
+func NewInventory(stock map[string]int) *Inventory {
+	return &Inventory{Stock: stock, reserved: map[string]string{}}
+}
+
+func (inv *Inventory) Reserve(ctx context.Context, orderID, copyID string) (string, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if _, ok := inv.reserved[orderID]; ok {
+		return "res-" + orderID, nil
+	}
+	if inv.Stock[copyID] <= 0 {
+		return "", fmt.Errorf("%w: %q", ErrOutOfStock, copyID)
+	}
+	inv.Stock[copyID]--
+	inv.reserved[orderID] = copyID
+	return "res-" + orderID, nil
+}
+
+func (inv *Inventory) Release(ctx context.Context, orderID string) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	copyID, ok := inv.reserved[orderID]
+	if !ok {
+		return nil
+	}
+	inv.Stock[copyID]++
+	delete(inv.reserved, orderID)
+	return nil
+}
+
+// Payments - платёжный шлюз. Decline отклоняет платежи, Delay задерживает ответ.
+type Payments struct {
+	mu       sync.Mutex
+	Decline  bool
+	Delay    time.Duration
+	charged  map[string]int
+	Refunded []string
+}
+
+func NewPayments() *Payments {
+	return &Payments{charged: map[string]int{}}
+}
+
+func (p *Payments) Charge(ctx context.Context, orderID string, amount int) (string, error) {
+	if p.Delay > 0 {
+		select {
+		case <-time.After(p.Delay):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Decline {
+		return "", ErrDeclined
+	}
+	p.charged[orderID] = amount
+	return "pay-" + orderID, nil
+}
+
+func (p *Payments) Refund(ctx context.Context, orderID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.charged[orderID]; !ok {
+		return nil
+	}
+	delete(p.charged, orderID)
+	p.Refunded = append(p.Refunded, orderID)
+	return nil
+}
+
+func (p *Payments) Charged(orderID string) (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	amount, ok := p.charged[orderID]
+	return amount, ok
+}
+
+type Notifier struct {
+	mu   sync.Mutex
+	Sent []string
+}
+
+func (n *Notifier) Notify(ctx context.Context, member, msg string) error {
+	if !strings.Contains(member, "@") {
+		return fmt.Errorf("order: invalid address %q", member)
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.Sent = append(n.Sent, member+": "+msg)
+	return nil
+}
+
+// Services - участники саги.
+type Services struct {
+	Inventory *Inventory
+	Payments  *Payments
+	Notifier  *Notifier
+	// ChargeTimeout ограничивает ожидание платёжного шлюза; по умолчанию 2 секунды.
+	ChargeTimeout time.Duration
+}
+
+func NewSaga(s Services, store saga.Store) *saga.Saga[Order] {
+	timeout := s.ChargeTimeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &saga.Saga[Order]{
+		Name:  "order",
+		Store: store,
+		Steps: []saga.Step[Order]{
+			{
+				Name: "reserve",
+				Action: func(ctx context.Context, o *Order) (err error) {
+					o.Reservation, err = s.Inventory.Reserve(ctx, o.ID, o.CopyID)
+					return err
+				},
+				Compensate: func(ctx context.Context, o *Order) error {
+					return s.Inventory.Release(ctx, o.ID)
+				},
+			},
+			{
+				Name: "charge",
+				Action: func(ctx context.Context, o *Order) (err error) {
+					o.Payment, err = s.Payments.Charge(ctx, o.ID, o.Amount)
+					return err
+				},
+				Compensate: func(ctx context.Context, o *Order) error {
+					return s.Payments.Refund(ctx, o.ID)
+				},
+				Timeout: timeout,
+			},
+			{
+				Name: "notify",
+				Action: func(ctx context.Context, o *Order) error {
+					return s.Notifier.Notify(ctx, o.Member, fmt.Sprintf("order %s confirmed, %s reserved", o.ID, o.CopyID))
+				},
+			},
+		},
+	}
+}
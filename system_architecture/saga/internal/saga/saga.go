@@ -0,0 +1,190 @@
+// Package saga - координатор долгих процессов из нескольких шагов. У каждого шага
+// есть действие и компенсация; при сбое выполненные шаги откатываются в обратном
+// порядке. Состояние сохраняется после каждого перехода, поэтому прерванная сага
+// продолжается с того же места после перезапуска (Resume).
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalid = errors.New("saga: invalid saga")
+
+type Status string
+
+const (
+	Running      Status = "running"
+	Compensating Status = "compensating"
+	Completed    Status = "completed"
+	Compensated  Status = "compensated"
+	// Failed - компенсация сама не удалась; нужен человек.
+	Failed Status = "failed"
+)
+
+func (s Status) Done() bool {
+	return s == Completed || s == Compensated || s == Failed
+}
+
+// Step - шаг саги над данными T. Действия и компенсации должны быть идемпотентны:
+// после перезапуска шаг, прерванный на середине, выполняется ещё раз.
+type Step[T any] struct {
+	Name       string
+	Action     func(ctx context.Context, data *T) error
+	Compensate func(ctx context.Context, data *T) error
+	// Timeout ограничивает одно выполнение действия; истечение считается сбоем шага.
+	Timeout time.Duration
+}
+
+// State - сохраняемое состояние экземпляра саги.
+type State struct {
+	ID     string `json:"id"`
+	Saga   string `json:"saga"`
+	Status Status `json:"status"`
+	// Next - индекс следующего шага вперёд; при компенсации - число ещё не откаченных шагов.
+	Next      int             `json:"next"`
+	Data      json.RawMessage `json:"data"`
+	Error     string          `json:"error,omitempty"`
+	Log       []string        `json:"log"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type Store interface {
+	Save(ctx context.Context, s State) error
+	Load(ctx context.Context, id string) (State, error)
+	// Pending возвращает незавершённые саги вида name.
+	Pending(ctx context.Context, name string) ([]State, error)
+}
+
+// Saga - определение процесса; один и тот же Saga выполняет любое число экземпляров.
+type Saga[T any] struct {
+	Name  string
+	Steps []Step[T]
+	Store Store
+	Now   func() time.Time
+}
+
+// Start создаёт экземпляр и выполняет его до конца или до отмены ctx.
+func (s *Saga[T]) Start(ctx context.Context, id string, data T) (State, error) {
+	if id == "" || len(s.Steps) == 0 {
+		return State{}, fmt.Errorf("%w: id and steps are required", ErrInvalid)
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return State{}, fmt.Errorf("saga: encode %s: %w", id, err)
+	}
+	st := State{ID: id, Saga: s.Name, Status: Running, Data: raw}
+	if err := s.save(ctx, &st, "started"); err != nil {
+		return st, err
+	}
+	return s.run(ctx, st)
+}
+
+// Resume продолжает все незавершённые экземпляры, например после перезапуска процесса.
+func (s *Saga[T]) Resume(ctx context.Context) ([]State, error) {
+	pending, err := s.Store.Pending(ctx, s.Name)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]State, 0, len(pending))
+	for _, st := range pending {
+		st, err := s.run(ctx, st)
+		out = append(out, st)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// run двигает экземпляр вперёд, а после сбоя - назад. Отмена ctx не считается сбоем
+// шага: сага останавливается как есть и ждёт Resume.
+func (s *Saga[T]) run(ctx context.Context, st State) (State, error) {
+	var data T
+	if err := json.Unmarshal(st.Data, &data); err != nil {
+		return st, fmt.Errorf("saga: decode %s: %w", st.ID, err)
+	}
+	for st.Status == Running && st.Next < len(s.Steps) {
+		step := s.Steps[st.Next]
+		err := s.do(ctx, step, &data)
+		if ctx.Err() != nil {
+			return st, ctx.Err()
+		}
+		if err != nil {
+			st.Status, st.Error = Compensating, fmt.Sprintf("%s: %v", step.Name, err)
+			if err := s.saveData(ctx, &st, data, step.Name+" failed: "+err.Error()); err != nil {
+				return st, err
+			}
+			break
+		}
+		st.Next++
+		if err := s.saveData(ctx, &st, data, step.Name+" done"); err != nil {
+			return st, err
+		}
+	}
+	if st.Status == Running {
+		st.Status = Completed
+		return st, s.save(ctx, &st, "completed")
+	}
+	for st.Status == Compensating && st.Next > 0 {
+		step := s.Steps[st.Next-1]
+		if step.Compensate != nil {
+			if err := step.Compensate(ctx, &data); err != nil {
+				if ctx.Err() != nil {
+					return st, ctx.Err()
+				}
+				st.Status = Failed
+				st.Error += fmt.Sprintf("; compensate %s: %v", step.Name, err)
+				return st, s.saveData(ctx, &st, data, step.Name+" compensation failed")
+			}
+		}
+		st.Next--
+		if err := s.saveData(ctx, &st, data, step.Name+" compensated"); err != nil {
+			return st, err
+		}
+	}
+	if st.Status == Compensating {
+		st.Status = Compensated
+		return st, s.save(ctx, &st, "compensated")
+	}
+	return st, nil
+}
+
+func (s *Saga[T]) do(ctx context.Context, step Step[T], data *T) error {
+	if step.Timeout <= 0 {
+		return step.Action(ctx, data)
+	}
+	ctx, cancel := context.WithTimeout(ctx, step.Timeout)
+	defer cancel()
+	err := step.Action(ctx, data)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", step.Timeout)
+	}
+	return err
+}
+
+func (s *Saga[T]) saveData(ctx context.Context, st *State, data T, msg string) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("saga: encode %s: %w", st.ID, err)
+	}
+	st.Data = raw
+	return s.save(ctx, st, msg)
+}
+
+func (s *Saga[T]) save(ctx context.Context, st *State, msg string) error {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	st.UpdatedAt = now()
+	st.Log = append(st.Log, msg)
+	// Состояние сохраняется и при отменённом ctx, иначе переход потеряется.
+	if err := s.Store.Save(context.WithoutCancel(ctx), *st); err != nil {
+		return fmt.Errorf("saga: save %s: %w", st.ID, err)
+	}
+	return nil
+}
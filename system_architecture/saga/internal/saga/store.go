@@ -0,0 +1,125 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrNotFound = errors.New("saga: not found")
+
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+var (
+	_ Store = (*MemoryStore)(nil)
+	_ Store = (*FileStore)(nil)
+)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: map[string]State{}}
+}
+
+func (m *MemoryStore) Save(ctx context.Context, s State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.Log = append([]string(nil), s.Log...)
+	m.states[s.ID] = s
+	return nil
+}
+
+func (m *MemoryStore) Load(ctx context.Context, id string) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.states[id]
+	if !ok {
+		return State{}, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	return s, nil
+}
+
+func (m *MemoryStore) Pending(ctx context.Context, name string) ([]State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []State
+	for _, s := range m.states {
+		if s.Saga == name && !s.Status.Done() {
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// FileStore хранит каждую сагу в отдельном JSON-файле каталога Dir.
+// Запись идёт через временный файл и переименование, чтобы сбой не оставил половину состояния.
+type FileStore struct {
+	Dir string
+}
+
+func (f FileStore) Save(ctx context.Context, s State) error {
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := f.path(s.ID) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path(s.ID))
+}
+
+func (f FileStore) Load(ctx context.Context, id string) (State, error) {
+	b, err := os.ReadFile(f.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, fmt.Errorf("%w: %q", ErrNotFound, id)
+	}
+	if err != nil {
+		return State{}, err
+	}
+	var s State
+	if err := json.Unmarshal(b, &s); err != nil {
+		return State{}, fmt.Errorf("saga: decode %s: %w", id, err)
+	}
+	return s, nil
+}
+
+func (f FileStore) Pending(ctx context.Context, name string) ([]State, error) {
+	entries, err := os.ReadDir(f.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []State
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok {
+			continue
+		}
+		s, err := f.Load(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if s.Saga == name && !s.Status.Done() {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (f FileStore) path(id string) string {
+	return filepath.Join(f.Dir, filepath.Base(id)+".json")
+}
@@ -0,0 +1,296 @@
+// Package app управляет жизненным циклом процесса: компоненты запускаются в порядке
+// добавления, а останавливаются в обратном, так что сервер перестаёт принимать запросы
+// раньше, чем закроются хранилища, которыми он пользуется. Run ждёт SIGINT/SIGTERM,
+// отмены контекста или отказа компонента и затем останавливает всё с общим дедлайном.
+// Компонент, не успевший остановиться, называется в ошибке Run (ErrBlocked), а
+// остальные всё равно получают свой Stop.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultStopTimeout - дедлайн остановки всех компонентов по умолчанию.
+const DefaultStopTimeout = 15 * time.Second
+
+// forceGrace - сколько ждать компонент, который останавливается уже после дедлайна.
+const forceGrace = time.Second
+
+// ErrBlocked - компонент не остановился до дедлайна.
+var ErrBlocked = errors.New("app: shutdown blocked")
+
+// Component - часть процесса. Start запускает её и возвращается, как только она готова;
+// долгую работу компонент ведёт в своих горутинах. Stop останавливает и дожидается
+// остановки; ctx несёт дедлайн, после которого надо бросить мягкую остановку.
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Hook - компонент из пары функций; любая может быть nil.
+type Hook struct {
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+var _ Component = Hook{}
+
+func (h Hook) Start(ctx context.Context) error {
+	if h.OnStart == nil {
+		return nil
+	}
+	return h.OnStart(ctx)
+}
+
+func (h Hook) Stop(ctx context.Context) error {
+	if h.OnStop == nil {
+		return nil
+	}
+	return h.OnStop(ctx)
+}
+
+// Closer - компонент без запуска: при остановке вызывается close. Так в порядок
+// остановки встают соединения с базой, брокером и прочие ресурсы.
+func Closer(close func() error) Component {
+	return Hook{OnStop: func(context.Context) error { return close() }}
+}
+
+type Config struct {
+	// StopTimeout - дедлайн остановки всех компонентов; 0 - DefaultStopTimeout.
+	StopTimeout time.Duration
+	// Signals - сигналы остановки; по умолчанию SIGINT и SIGTERM. Повторный сигнал
+	// во время остановки завершает процесс сразу.
+	Signals []os.Signal
+	Logger  *log.Logger
+}
+
+// App - упорядоченный набор компонентов процесса.
+type App struct {
+	cfg   Config
+	comps []named
+
+	failed chan failure
+}
+
+type named struct {
+	name string
+	Component
+}
+
+type failure struct {
+	name string
+	err  error
+}
+
+func New(cfg Config) *App {
+	if cfg.StopTimeout <= 0 {
+		cfg.StopTimeout = DefaultStopTimeout
+	}
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	return &App{cfg: cfg, failed: make(chan failure, 1)}
+}
+
+// Add добавляет компонент; добавленный позже запускается позже и останавливается раньше.
+func (a *App) Add(name string, c Component) {
+	a.comps = append(a.comps, named{name: name, Component: c})
+}
+
+// Fail останавливает приложение из-за компонента name, например когда сервер упал
+// уже после запуска. err == nil - компонент просто закончил работу. Учитывается
+// только первый вызов.
+func (a *App) Fail(name string, err error) {
+	select {
+	case a.failed <- failure{name: name, err: err}:
+	default:
+	}
+}
+
+// Run запускает компоненты и ждёт сигнала, отмены ctx или отказа компонента, затем
+// останавливает запущенные. Ошибку запуска или отказа Run возвращает вместе с
+// ошибками остановки; обычная остановка по сигналу - nil.
+func (a *App) Run(ctx context.Context) error {
+	sigCtx, stopSignals := signal.NotifyContext(ctx, a.cfg.Signals...)
+	defer stopSignals()
+
+	var cause error
+	started := 0
+	for _, c := range a.comps {
+		if err := c.Start(sigCtx); err != nil {
+			cause = fmt.Errorf("app: start %s: %w", c.name, err)
+			break
+		}
+		started++
+	}
+	if cause == nil {
+		select {
+		case <-sigCtx.Done():
+			a.cfg.Logger.Print("app: shutting down")
+		case f := <-a.failed:
+			if f.err != nil {
+				cause = fmt.Errorf("app: %s: %w", f.name, f.err)
+				a.cfg.Logger.Printf("app: %s failed, shutting down", f.name)
+			} else {
+				a.cfg.Logger.Printf("app: %s finished, shutting down", f.name)
+			}
+		}
+	}
+	// Дальше сигналы снова обрабатываются по умолчанию: второй Ctrl-C не ждёт дедлайна.
+	stopSignals()
+	return errors.Join(cause, a.stop(started))
+}
+
+// stop останавливает первые n компонентов в обратном порядке. Stop каждого идёт
+// в своей горутине, чтобы зависший компонент не задержал остальные дольше дедлайна.
+// Кому дедлайна уже не досталось, получают просроченный ctx и forceGrace на то, чтобы
+// остановиться принудительно.
+func (a *App) stop(n int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.StopTimeout)
+	defer cancel()
+	var errs []error
+	for i := n - 1; i >= 0; i-- {
+		c := a.comps[i]
+		begin := time.Now()
+		done := make(chan error, 1)
+		go func() { done <- c.Stop(ctx) }()
+		deadline, grace := ctx.Done(), (<-chan time.Time)(nil)
+		if ctx.Err() != nil {
+			deadline, grace = nil, time.After(forceGrace)
+		}
+		var err error
+		blocked := false
+		select {
+		case err = <-done:
+			blocked = errors.Is(err, context.DeadlineExceeded)
+		case <-deadline:
+			blocked = true
+		case <-grace:
+			blocked = true
+		}
+		switch {
+		case blocked:
+			errs = append(errs, fmt.Errorf("%w: %s did not stop within %s (waited %s)",
+				ErrBlocked, c.name, a.cfg.StopTimeout, time.Since(begin).Round(time.Millisecond)))
+		case err != nil:
+			errs = append(errs, fmt.Errorf("app: stop %s: %w", c.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Listen добавляет сервер, принимающий соединения на addr: при запуске занимается порт
+// (ошибка видна сразу), serve работает в своей горутине. Остановка вызывает shutdown,
+// а если тот не уложился в дедлайн - close. Ошибка serve до остановки - отказ компонента.
+func (a *App) Listen(name, addr string, serve func(net.Listener) error, shutdown func(ctx context.Context) error, close func() error) {
+	a.Add(name, &listener{app: a, name: name, addr: addr, serve: serve, shutdown: shutdown, close: close})
+}
+
+// HTTP добавляет HTTP-сервер; остановка дожидается ответов на уже принятые запросы.
+func (a *App) HTTP(name string, srv *http.Server) {
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	a.Listen(name, addr, srv.Serve, srv.Shutdown, srv.Close)
+}
+
+type listener struct {
+	app      *App
+	name     string
+	addr     string
+	serve    func(net.Listener) error
+	shutdown func(ctx context.Context) error
+	close    func() error
+
+	stopping atomic.Bool
+}
+
+func (l *listener) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", l.addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		err := l.serve(ln)
+		if !l.stopping.Load() {
+			if err == nil {
+				err = errors.New("server stopped")
+			}
+			l.app.Fail(l.name, err)
+		}
+	}()
+	return nil
+}
+
+func (l *listener) Stop(ctx context.Context) error {
+	l.stopping.Store(true)
+	err := l.shutdown(ctx)
+	if err != nil && l.close != nil {
+		l.close()
+	}
+	return err
+}
+
+// Worker добавляет фоновую работу: run получает контекст, который отменяется при
+// остановке, и должен после этого вернуться. run, закончившийся сам, останавливает
+// приложение; его ошибка - отказ компонента.
+func (a *App) Worker(name string, run func(ctx context.Context) error) {
+	a.Add(name, &worker{app: a, name: name, run: run})
+}
+
+type worker struct {
+	app  *App
+	name string
+	run  func(ctx context.Context) error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+	err    error
+}
+
+func (w *worker) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	w.cancel, w.done = cancel, make(chan struct{})
+	go func() {
+		defer close(w.done)
+		err := w.run(runCtx)
+		if runCtx.Err() == nil {
+			w.app.Fail(w.name, err)
+			return
+		}
+		if !errors.Is(err, context.Canceled) {
+			w.mu.Lock()
+			w.err = err
+			w.mu.Unlock()
+		}
+	}()
+	return nil
+}
+
+func (w *worker) Stop(ctx context.Context) error {
+	w.cancel()
+	select {
+	case <-w.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
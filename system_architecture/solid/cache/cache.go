@@ -0,0 +1,343 @@
+// Package cache - кэш в памяти с ограниченной ёмкостью: вытеснение LRU или LFU,
+// срок жизни записей и загрузка промахов, при которой параллельные запросы одного
+// ключа ждут одну загрузку, а не идут в источник каждый.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const DefaultCapacity = 1024
+
+// Policy - какую запись вытеснить, когда кэш полон.
+type Policy int
+
+const (
+	// LRU вытесняет запись, к которой дольше всех не обращались.
+	LRU Policy = iota
+	// LFU вытесняет запись с наименьшим числом обращений, среди равных - самую давнюю.
+	LFU
+)
+
+// Config - нулевые поля заменяются значениями по умолчанию.
+type Config struct {
+	// Capacity - сколько записей держит кэш, по умолчанию DefaultCapacity.
+	Capacity int
+	Policy   Policy
+	// TTL - срок жизни записи по умолчанию; 0 - пока не вытеснят или не удалят.
+	TTL time.Duration
+	// Now - часы для сроков жизни, по умолчанию time.Now.
+	Now func() time.Time
+}
+
+// Stats - счётчики с создания кэша.
+type Stats struct {
+	Hits        int64
+	Misses      int64
+	Evictions   int64
+	Expirations int64
+	// Loads - загрузки через GetOrLoad; ожидавшие чужую загрузку не считаются.
+	Loads      int64
+	LoadErrors int64
+	Len        int
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+	freq    int
+	elem    *list.Element
+}
+
+// call - загрузка ключа, которую ждут все запросы промаха.
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// stale - ключ изменили или удалили во время загрузки, результат в кэш не кладётся.
+	stale bool
+}
+
+type Cache[K comparable, V any] struct {
+	cfg Config
+
+	mu      sync.Mutex
+	entries map[K]*entry[K, V]
+	order   evictor[K, V]
+	loading map[K]*call[V]
+	stats   Stats
+}
+
+func New[K comparable, V any](cfg Config) *Cache[K, V] {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = DefaultCapacity
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	c := &Cache[K, V]{cfg: cfg, entries: make(map[K]*entry[K, V]), loading: make(map[K]*call[V])}
+	switch cfg.Policy {
+	case LFU:
+		c.order = newLFU[K, V]()
+	default:
+		c.order = &lru[K, V]{l: list.New()}
+	}
+	return c
+}
+
+// Get возвращает значение, если оно есть и не истекло.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.get(key)
+}
+
+func (c *Cache[K, V]) get(key K) (V, bool) {
+	e, ok := c.entries[key]
+	if ok && !e.expires.IsZero() && !c.cfg.Now().Before(e.expires) {
+		c.remove(e)
+		c.stats.Expirations++
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+	c.stats.Hits++
+	c.order.touch(e)
+	return e.value, true
+}
+
+// Set кладёт значение со сроком жизни из Config.TTL.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetTTL(key, value, c.cfg.TTL)
+}
+
+// SetTTL кладёт значение со своим сроком жизни; ttl 0 - без срока.
+func (c *Cache[K, V]) SetTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.markStale(key)
+	c.set(key, value, ttl)
+}
+
+func (c *Cache[K, V]) set(key K, value V, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.cfg.Now().Add(ttl)
+	}
+	if e, ok := c.entries[key]; ok {
+		e.value, e.expires = value, expires
+		c.order.touch(e)
+		return
+	}
+	if len(c.entries) >= c.cfg.Capacity {
+		if victim := c.order.victim(); victim != nil {
+			c.remove(victim)
+			c.stats.Evictions++
+		}
+	}
+	e := &entry[K, V]{key: key, value: value, expires: expires}
+	c.entries[key] = e
+	c.order.add(e)
+}
+
+// Delete удаляет запись; идущая загрузка ключа завершится, но в кэш не попадёт.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.markStale(key)
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+}
+
+// Purge очищает кэш, не трогая счётчики.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		c.markStale(key)
+		c.remove(e)
+	}
+}
+
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Len = len(c.entries)
+	return s
+}
+
+// GetOrLoad отдаёт значение из кэша, а при промахе загружает его через load и
+// кладёт с Config.TTL; ошибки не кэшируются. Пока ключ загружается, остальные
+// запросы его ждут. Загрузка не отменяется вместе с ctx вызвавшего её запроса,
+// чтобы не провалить ждущих; каждый ждёт не дольше своего ctx.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+	if v, ok := c.get(key); ok {
+		c.mu.Unlock()
+		return v, nil
+	}
+	cl, ok := c.loading[key]
+	if !ok {
+		cl = &call[V]{done: make(chan struct{})}
+		c.loading[key] = cl
+		c.stats.Loads++
+		go c.load(context.WithoutCancel(ctx), key, cl, load)
+	}
+	c.mu.Unlock()
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (c *Cache[K, V]) load(ctx context.Context, key K, cl *call[V], load func(ctx context.Context) (V, error)) {
+	defer close(cl.done)
+	v, err := safeLoad(ctx, load)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cl.value, cl.err = v, err
+	if c.loading[key] == cl {
+		delete(c.loading, key)
+	}
+	switch {
+	case err != nil:
+		c.stats.LoadErrors++
+	case !cl.stale:
+		c.set(key, v, c.cfg.TTL)
+	}
+}
+
+// safeLoad - load с паникой, превращённой в ошибку: загрузка идёт в своей горутине,
+// и паника в ней уронила бы процесс, а ждущие не дождались бы ответа.
+func safeLoad[V any](ctx context.Context, load func(ctx context.Context) (V, error)) (v V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cache: load panicked: %v", r)
+		}
+	}()
+	return load(ctx)
+}
+
+func (c *Cache[K, V]) markStale(key K) {
+	if cl, ok := c.loading[key]; ok {
+		cl.stale = true
+		delete(c.loading, key)
+	}
+}
+
+func (c *Cache[K, V]) remove(e *entry[K, V]) {
+	delete(c.entries, e.key)
+	c.order.remove(e)
+}
+
+// evictor - порядок вытеснения; вызывается под замком кэша.
+type evictor[K comparable, V any] interface {
+	add(e *entry[K, V])
+	touch(e *entry[K, V])
+	remove(e *entry[K, V])
+	victim() *entry[K, V]
+}
+
+// lru держит записи от недавних к давним.
+type lru[K comparable, V any] struct {
+	l *list.List
+}
+
+func (o *lru[K, V]) add(e *entry[K, V])    { e.elem = o.l.PushFront(e) }
+func (o *lru[K, V]) touch(e *entry[K, V])  { o.l.MoveToFront(e.elem) }
+func (o *lru[K, V]) remove(e *entry[K, V]) { o.l.Remove(e.elem) }
+
+func (o *lru[K, V]) victim() *entry[K, V] {
+	if back := o.l.Back(); back != nil {
+		return back.Value.(*entry[K, V])
+	}
+	return nil
+}
+
+// lfu раскладывает записи по числу обращений, внутри частоты - как lru, так что
+// все операции - O(1).
+type lfu[K comparable, V any] struct {
+	buckets map[int]*list.List
+	min     int
+}
+
+func newLFU[K comparable, V any]() *lfu[K, V] {
+	return &lfu[K, V]{buckets: make(map[int]*list.List)}
+}
+
+func (o *lfu[K, V]) add(e *entry[K, V]) {
+	e.freq = 1
+	o.push(e)
+	o.min = 1
+}
+
+func (o *lfu[K, V]) touch(e *entry[K, V]) {
+	o.unlink(e)
+	if e.freq == o.min && o.buckets[e.freq] == nil {
+		o.min++
+	}
+	e.freq++
+	o.push(e)
+}
+
+func (o *lfu[K, V]) remove(e *entry[K, V]) {
+	o.unlink(e)
+	if e.freq == o.min && o.buckets[e.freq] == nil {
+		o.recomputeMin()
+	}
+}
+
+func (o *lfu[K, V]) victim() *entry[K, V] {
+	if b := o.buckets[o.min]; b != nil {
+		return b.Back().Value.(*entry[K, V])
+	}
+	return nil
+}
+
+func (o *lfu[K, V]) push(e *entry[K, V]) {
+	b := o.buckets[e.freq]
+	if b == nil {
+		b = list.New()
+		o.buckets[e.freq] = b
+	}
+	e.elem = b.PushFront(e)
+}
+
+func (o *lfu[K, V]) unlink(e *entry[K, V]) {
+	b := o.buckets[e.freq]
+	b.Remove(e.elem)
+	if b.Len() == 0 {
+		delete(o.buckets, e.freq)
+	}
+}
+
+// recomputeMin нужен только после удаления последней записи наименьшей частоты -
+// при вытеснении её сразу сменит новая запись с частотой 1 - поэтому перебор
+// частот не портит O(1) в обычном потоке.
+func (o *lfu[K, V]) recomputeMin() {
+	o.min = 0
+	for f := range o.buckets {
+		if o.min == 0 || f < o.min {
+			o.min = f
+		}
+	}
+}
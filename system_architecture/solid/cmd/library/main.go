@@ -0,0 +1,201 @@
+// Команда library - консольные операции над JSON-каталогом.
+//
+//	library -data catalog.json dedup
+//	library -data catalog.json merge KEEP_ID DROP_ID
+//	library -data catalog.json import books.json
+//	library -data catalog.json import -stream [-checkpoint dir] books.json
+//	library -data catalog.json seed
+//	library -data catalog.json add -title T -author A [-year Y] [-isbn I] [-copies-count N] [-branch B]
+//	library -copies copies.json labels [-format code128|qr] [-out labels]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"solid/data"
+	"solid/design_patterns/iterator"
+	"solid/library"
+	"solid/library/dedup"
+	"solid/library/facade"
+	"solid/library/importer"
+	"solid/library/inventory"
+	"solid/library/labels"
+	"solid/library/seed"
+)
+
+type command func(ctx context.Context, repo *library.FileRepository, args []string) error
+
+var commands = map[string]command{
+	"add":    runAdd,
+	"dedup":  runDedup,
+	"merge":  runMerge,
+	"import": runImport,
+	"labels": runLabels,
+	"seed":   runSeed,
+}
+
+var copiesFile = flag.String("copies", "copies.json", "JSON file with book copies")
+
+func main() {
+	dataFile := flag.String("data", "catalog.json", "JSON catalog file")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: library [-data file] <dedup|merge KEEP DROP|import [-stream] FILE|labels|seed|add>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+	repo, err := library.OpenFileRepository(*dataFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "open catalog:", err)
+		os.Exit(1)
+	}
+	if err := cmd(context.Background(), repo, flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func runDedup(ctx context.Context, repo *library.FileRepository, args []string) error {
+	found, err := dedup.NewService(repo).Find(ctx)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		fmt.Println("No duplicates found")
+		return nil
+	}
+	for _, c := range found {
+		fmt.Printf("%.2f %-12s %s %q <- %s %q\n", c.Score, c.Reason, c.Keep.ID, c.Keep.Title, c.Drop.ID, c.Drop.Title)
+	}
+	return nil
+}
+
+func runMerge(ctx context.Context, repo *library.FileRepository, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("merge: expected KEEP_ID and DROP_ID")
+	}
+	book, err := dedup.NewService(repo, dedup.TagMover{Tags: repo}).Merge(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Merged into %s %q\n", book.ID, book.Title)
+	return nil
+}
+
+func runImport(ctx context.Context, repo *library.FileRepository, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	stream := fs.Bool("stream", false, "read the file as it goes and print the report as JSON lines, for files too large for memory")
+	checkpoint := fs.String("checkpoint", "", "with -stream, directory for a checkpoint to resume an interrupted import")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("import: expected a JSON file with an array of books")
+	}
+	if *stream {
+		return streamImport(ctx, repo, fs.Arg(0), *checkpoint)
+	}
+	raw, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var books []library.Book
+	if err := json.Unmarshal(raw, &books); err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	report, err := importer.New(repo).Import(ctx, books)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func streamImport(ctx context.Context, repo *library.FileRepository, file, checkpoint string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	im := importer.New(repo)
+	if checkpoint != "" {
+		im.Checkpoint, im.CheckpointKey = data.NewFilesystem(checkpoint), "import/"+filepath.Base(file)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	sum, err := im.ImportStream(ctx, iterator.FileBooks(f), func(e importer.Entry) error {
+		return enc.Encode(e)
+	})
+	fmt.Fprintf(os.Stderr, "accepted %d, rejected %d, duplicates %d, resumed after %d\n", sum.Accepted, sum.Rejected, sum.Duplicates, sum.Resumed)
+	return err
+}
+
+func runLabels(ctx context.Context, repo *library.FileRepository, args []string) error {
+	fs := flag.NewFlagSet("labels", flag.ContinueOnError)
+	format := fs.String("format", string(labels.Code128), "label format: code128 or qr")
+	out := fs.String("out", "labels", "output directory for PNG labels")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	f, err := labels.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	copies, err := inventory.OpenFileStore(*copiesFile)
+	if err != nil {
+		return err
+	}
+	n, err := labels.NewGenerator(f).RenderMissing(ctx, copies, *out)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Rendered %d labels into %s\n", n, *out)
+	return nil
+}
+
+func runSeed(ctx context.Context, repo *library.FileRepository, args []string) error {
+	added, err := seed.Seed(ctx, repo)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Seeded %d books\n", added)
+	return nil
+}
+
+func runAdd(ctx context.Context, repo *library.FileRepository, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	var b library.Book
+	fs.StringVar(&b.Title, "title", "", "book title")
+	fs.StringVar(&b.Author, "author", "", "book author")
+	fs.IntVar(&b.Year, "year", 0, "publication year")
+	fs.StringVar(&b.ISBN, "isbn", "", "ISBN")
+	count := fs.Int("copies-count", 0, "number of copies to register in the -copies file")
+	branch := fs.String("branch", "", "branch for the new copies")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg := facade.Config{Books: repo}
+	if *count > 0 {
+		copies, err := inventory.OpenFileStore(*copiesFile)
+		if err != nil {
+			return err
+		}
+		cfg.Inventory = inventory.NewService(copies, repo)
+	}
+	book, copies, err := facade.New(cfg).AddBook(ctx, b, *count, *branch)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added %s %q with %d copies\n", book.ID, book.Title, len(copies))
+	return nil
+}
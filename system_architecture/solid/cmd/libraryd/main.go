@@ -0,0 +1,303 @@
+// Команда libraryd поднимает REST API каталога книг.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"solid/app"
+	"solid/concurrency/flow"
+	"solid/data"
+	"solid/di"
+	"solid/eventbus"
+	"solid/health"
+	"solid/httpmw"
+	"solid/library"
+	"solid/library/dedup"
+	"solid/library/facade"
+	"solid/library/graphqlapi"
+	"solid/library/httpapi"
+	"solid/library/importer"
+	"solid/library/inventory"
+	"solid/library/lending"
+	"solid/library/reviews"
+	"solid/library/search"
+	"solid/library/seed"
+	"solid/library/stats"
+	"solid/metrics"
+	"solid/recommend"
+	"solid/scheduler"
+	"solid/tasks"
+	"solid/telemetry"
+)
+
+type repository interface {
+	library.Repository
+	library.Searcher
+	library.TagRepository
+	library.AuthorRepository
+}
+
+func main() {
+	addr := flag.String("addr", ":8080", "HTTP listen address")
+	dataFile := flag.String("data", "", "JSON file for the catalog (in-memory if empty)")
+	copiesFile := flag.String("copies", "", "JSON file for book copies (in-memory if empty)")
+	seedCatalog := flag.Bool("seed", false, "load the embedded starter catalog on startup")
+	searchBackend := flag.String("search", "index", "search backend: index or scan")
+	dataDir := flag.String("data-dir", "data", "directory for precomputed data")
+	precompute := flag.Duration("precompute", 0, "interval for precomputing recommendations (disabled if 0)")
+	overdueCheck := flag.String("overdue", "@hourly", "cron schedule for the overdue loans check (disabled if empty)")
+	eventsDir := flag.String("events-dir", "", "directory for the event journal (events are not kept if empty)")
+	eventsFlush := flag.Duration("events-flush", 0, "how long event journal writes may wait to be saved in one batch (saved one by one if 0)")
+	readsTTL := flag.Duration("reads-ttl", 0, "how long a catalog read is shared with later requests (only concurrent reads share if 0)")
+	timeout := flag.Duration("timeout", 30*time.Second, "per-request handler deadline (disabled if 0)")
+	traceExporter := flag.String("trace", telemetry.None, "trace exporter: none or stdout")
+	flag.Parse()
+
+	logger := log.Default()
+	tp, shutdown, err := telemetry.Setup(telemetry.Config{Service: "libraryd", Exporter: *traceExporter})
+	if err != nil {
+		log.Fatal(err)
+	}
+	reg := metrics.New("library")
+
+	// Сборка идёт через контейнер: каждый компонент объявляет, из чего он строится,
+	// а флаги лишь выбирают реализацию портов. Создаётся только то, что нужно HTTP.
+	c := di.New()
+	di.Value(c, logger)
+	di.Provide(c, di.Singleton, func(di.Resolver) (repository, error) {
+		if *dataFile == "" {
+			return library.NewMemoryRepository(), nil
+		}
+		repo, err := library.OpenFileRepository(*dataFile)
+		if err != nil {
+			return nil, fmt.Errorf("open catalog: %w", err)
+		}
+		return repo, nil
+	})
+	// С журналом события каталога и выдач переживают перезапуск, и статистика
+	// восстанавливается из них, а не начинается с нуля.
+	di.Provide(c, di.Singleton, func(di.Resolver) (*eventbus.Bus, error) {
+		var journal data.Storage
+		if *eventsDir != "" {
+			journal = metrics.Storage(data.TraceStorage(data.NewFilesystem(*eventsDir), tp), reg)
+		}
+		var batch *flow.BatchConfig
+		if *eventsFlush > 0 {
+			batch = &flow.BatchConfig{Interval: *eventsFlush}
+		}
+		return eventbus.New(eventbus.Config{Journal: journal, JournalBatch: batch, OnError: func(f eventbus.Failure) {
+			logger.Print(f)
+		}, Hooks: reg.PubSub()}), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (library.Catalog, error) {
+		// Одновременные чтения одной книги или страницы идут в хранилище одним запросом.
+		// Все записи в каталог, и меток с авторами тоже, идут через эту обёртку, иначе
+		// сохранённые на -reads-ttl чтения их не увидят.
+		repo := di.MustResolve[repository](r)
+		books := library.WithEvents(repo, di.MustResolve[*eventbus.Bus](r))
+		return library.CoalescedCatalog(repo, books, *readsTTL), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (library.Repository, error) {
+		return di.MustResolve[library.Catalog](r), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (library.Searcher, error) {
+		repo := di.MustResolve[repository](r)
+		switch *searchBackend {
+		case "index":
+			index := search.NewIndex()
+			if err := index.Rebuild(context.Background(), repo); err != nil {
+				return nil, fmt.Errorf("build search index: %w", err)
+			}
+			if err := index.Subscribe(context.Background(), di.MustResolve[*eventbus.Bus](r)); err != nil {
+				return nil, fmt.Errorf("subscribe search index: %w", err)
+			}
+			return index, nil
+		case "scan":
+			return repo, nil
+		}
+		return nil, fmt.Errorf("unknown search backend %q", *searchBackend)
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*reviews.Service, error) {
+		return reviews.NewService(reviews.NewMemoryRepository(), di.MustResolve[repository](r)), nil
+	})
+	di.Provide(c, di.Singleton, func(di.Resolver) (inventory.Store, error) {
+		if *copiesFile == "" {
+			return inventory.NewMemoryStore(), nil
+		}
+		copies, err := inventory.OpenFileStore(*copiesFile)
+		if err != nil {
+			return nil, fmt.Errorf("open copies: %w", err)
+		}
+		return copies, nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*inventory.Service, error) {
+		return inventory.NewService(di.MustResolve[inventory.Store](r), di.MustResolve[repository](r)), nil
+	})
+	di.Provide(c, di.Singleton, func(di.Resolver) (*lending.MemoryStore, error) {
+		return lending.NewMemoryStore(), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*lending.Service, error) {
+		return lending.NewService(di.MustResolve[*lending.MemoryStore](r), di.MustResolve[inventory.Store](r), di.MustResolve[*eventbus.Bus](r)), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*stats.Projection, error) {
+		p, err := stats.Register(context.Background(), di.MustResolve[*eventbus.Bus](r))
+		if err != nil {
+			return nil, fmt.Errorf("replay stats: %w", err)
+		}
+		return p, nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*recommend.Engine, error) {
+		return recommend.NewEngine(di.MustResolve[*lending.MemoryStore](r), recommend.JaccardScorer{}), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*facade.LibraryFacade, error) {
+		return facade.New(facade.Config{
+			Books:     di.MustResolve[library.Repository](r),
+			Search:    di.MustResolve[library.Searcher](r),
+			Inventory: di.MustResolve[*inventory.Service](r),
+			Lending:   di.MustResolve[*lending.Service](r),
+			Notifier:  facade.LogNotifier{Logger: logger},
+			Logger:    logger,
+		}), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*httpapi.Server, error) {
+		catalog := di.MustResolve[library.Catalog](r)
+		reviewService := di.MustResolve[*reviews.Service](r)
+		inventoryService := di.MustResolve[*inventory.Service](r)
+		lendingService := di.MustResolve[*lending.Service](r)
+		return httpapi.NewServer(httpapi.Deps{
+			Books:     catalog,
+			Search:    di.MustResolve[library.Searcher](r),
+			Tags:      catalog,
+			Authors:   catalog,
+			Reviews:   reviewService,
+			Inventory: inventoryService,
+			Lending:   lendingService,
+			Stats:     di.MustResolve[*stats.Projection](r),
+			Dedup: dedup.NewService(catalog, lendingService, reviewService, inventoryService,
+				dedup.TagMover{Tags: catalog}),
+			Recommend: di.MustResolve[*recommend.Engine](r),
+			Importer:  importer.New(catalog),
+			Facade:    di.MustResolve[*facade.LibraryFacade](r),
+			Timeout:   *timeout,
+			Tracer:    tp,
+			Metrics:   reg,
+			Logger:    logger,
+		}), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (graphql.Schema, error) {
+		schema, err := graphqlapi.NewSchema(graphqlapi.Deps{
+			Books:   di.MustResolve[library.Repository](r),
+			Authors: di.MustResolve[library.Catalog](r),
+			Search:  di.MustResolve[library.Searcher](r),
+			Reviews: di.MustResolve[*reviews.Service](r),
+			Lending: di.MustResolve[*lending.Service](r),
+		})
+		if err != nil {
+			return graphql.Schema{}, fmt.Errorf("build GraphQL schema: %w", err)
+		}
+		return schema, nil
+	})
+
+	// Порядок остановки обратный: сначала HTTP дожидается начатых запросов, затем
+	// фоновые задачи по расписанию, потом контейнер закрывает шину событий, и последними
+	// выгружаются спаны.
+	a := app.New(app.Config{Logger: logger})
+	a.Add("telemetry", app.Hook{OnStop: shutdown})
+	a.Add("container", app.Closer(func() error { c.Close(); return nil }))
+
+	// Запуск - задачи с зависимостями: каталог наполняется до того, как из него
+	// строятся поисковый индекс и остальные компоненты HTTP, и только потом сервер
+	// начинает принимать запросы.
+	startup := tasks.New(tasks.Config{Logger: logger})
+	buildAfter := []string{}
+	if *seedCatalog {
+		startup.Add(tasks.Task{Name: "seed", Timeout: time.Minute, Run: func(ctx context.Context) error {
+			// Мимо library.Catalog: сид идёт до первого чтения, сбрасывать нечего, а
+			// события о стартовом каталоге не публикуются.
+			repo, err := di.Resolve[repository](c)
+			if err != nil {
+				return err
+			}
+			added, err := seed.Seed(ctx, repo)
+			if err != nil {
+				return err
+			}
+			logger.Printf("seeded %d books", added)
+			return nil
+		}})
+		buildAfter = append(buildAfter, "seed")
+	}
+	startup.Add(tasks.Task{Name: "build", After: buildAfter, Run: func(context.Context) error {
+		server, err := di.Resolve[*httpapi.Server](c)
+		if err != nil {
+			return err
+		}
+		schema, err := di.Resolve[graphql.Schema](c)
+		if err != nil {
+			return err
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/", server.Handler())
+		mux.Handle("POST /graphql", httpmw.Chain(graphqlapi.Handler(schema),
+			httpmw.RequestID, httpmw.Logging(logger), httpmw.Recover(logger), httpmw.Gzip, httpmw.Timeout(*timeout), telemetry.HTTP(tp), metrics.HTTP(reg)))
+		mux.Handle("GET /metrics", reg.Handler())
+		health.New(health.Config{}).Register(mux)
+		// Фоновые задачи - по расписанию; итоги запусков лежат рядом с расчётами, так что
+		// после перезапуска проверка просрочек продолжается с прошлого раза.
+		store := metrics.Storage(data.TraceStorage(data.NewFilesystem(*dataDir), tp), reg)
+		jobs := scheduler.New(scheduler.Config{Storage: store, Logger: logger})
+		if *precompute > 0 {
+			engine, err := di.Resolve[*recommend.Engine](c)
+			if err != nil {
+				return err
+			}
+			job := recommend.Job{Engine: engine, Storage: store}
+			if err := jobs.Add(scheduler.Job{Name: "recommendations", Schedule: scheduler.Every(*precompute), Run: func(ctx context.Context, _ scheduler.Run) error {
+				return job.Run(ctx)
+			}}); err != nil {
+				return err
+			}
+		}
+		if *overdueCheck != "" {
+			schedule, err := scheduler.Cron(*overdueCheck)
+			if err != nil {
+				return err
+			}
+			loans, err := di.Resolve[*lending.Service](c)
+			if err != nil {
+				return err
+			}
+			if err := jobs.Add(scheduler.Job{Name: "overdue-loans", Schedule: schedule, Jitter: time.Minute, Run: func(ctx context.Context, run scheduler.Run) error {
+				overdue, err := loans.Overdue(ctx, run.Last, run.Start)
+				if len(overdue) > 0 {
+					logger.Printf("%d loans became overdue", len(overdue))
+				}
+				return err
+			}}); err != nil {
+				return err
+			}
+		}
+		mux.HandleFunc("GET /debug/jobs", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(jobs.Status())
+		})
+		a.Worker("scheduler", jobs.Run)
+		a.HTTP("http", &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second})
+		return nil
+	}})
+	startup.Add(tasks.Task{Name: "serve", After: []string{"build"}, Run: func(ctx context.Context) error {
+		logger.Printf("libraryd listening on %s", *addr)
+		return a.Run(ctx)
+	}})
+	if _, err := startup.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
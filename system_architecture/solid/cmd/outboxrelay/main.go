@@ -0,0 +1,146 @@
+// Команда outboxrelay - отдельный процесс-ретранслятор outbox. Он опрашивает таблицу
+// data_outbox (sqlstore) или, без -dsn, outbox в памяти с демонстрационными записями,
+// отправляет записи в выбранный брокер и помечает доставленные.
+//
+//	outboxrelay -dsn postgres://... -broker webhook -webhook-url http://localhost:9000/events
+//	outboxrelay -broker redis -redis-addr localhost:6379 -stream library-events
+//	outboxrelay -seed 3 -once
+//	outboxrelay -health-addr :9100 -max-pending 1000
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/redis/go-redis/v9"
+
+	"solid/app"
+	"solid/data"
+	"solid/data/outboxrelay"
+	"solid/data/sqlstore"
+	"solid/health"
+	"solid/resilience/breaker"
+)
+
+func main() {
+	dsn := flag.String("dsn", "", "PostgreSQL DSN of the data layer (in-memory outbox if empty)")
+	seed := flag.Int("seed", 3, "demo writes for the in-memory outbox")
+	brokerKind := flag.String("broker", "stdout", "broker: stdout, webhook or redis")
+	webhookURL := flag.String("webhook-url", "", "endpoint for -broker webhook")
+	redisAddr := flag.String("redis-addr", "localhost:6379", "Redis address for -broker redis")
+	stream := flag.String("stream", "outbox", "Redis stream for -broker redis")
+	deadLetter := flag.String("dead-letter", "outbox-dead.jsonl", "file for messages that could not be delivered")
+	interval := flag.Duration("interval", time.Second, "poll interval")
+	batch := flag.Int("batch", data.DefaultRelayBatch, "outbox rows per poll")
+	maxAttempts := flag.Int("max-attempts", 5, "delivery attempts before dead-lettering")
+	backoff := flag.Duration("backoff", time.Second, "pause after the first failed attempt, doubled each time")
+	once := flag.Bool("once", false, "poll once, print stats and exit")
+	healthAddr := flag.String("health-addr", "", "HTTP address for /livez and /readyz (disabled if empty)")
+	maxPending := flag.Int("max-pending", 1000, "undelivered outbox rows above which the relay reports not ready")
+	flag.Parse()
+	logger := log.Default()
+
+	ctx := context.Background()
+	outbox, closeOutbox, err := openOutbox(ctx, *dsn, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	broker, err := newBroker(*brokerKind, *webhookURL, *redisAddr, *stream)
+	if err != nil {
+		closeOutbox()
+		log.Fatal(err)
+	}
+
+	w := &outboxrelay.Worker{
+		Outbox:     outbox,
+		Broker:     broker,
+		DeadLetter: &outboxrelay.FileDeadLetter{Path: *deadLetter},
+		Policy:     outboxrelay.Policy{MaxAttempts: *maxAttempts, Backoff: *backoff},
+		Batch:      *batch,
+		Logger:     logger,
+	}
+	if *once {
+		defer closeOutbox()
+		n, err := w.Poll(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s := w.Stats()
+		logger.Printf("dispatched %d: sent %d, failed %d, dead-lettered %d, retrying %d", n, s.Sent, s.Failed, s.DeadLetter, s.Retrying)
+		return
+	}
+	// Сначала останавливается ретранслятор - текущая пачка дописывается до конца, - затем
+	// закрывается outbox.
+	a := app.New(app.Config{Logger: logger})
+	a.Add("outbox", app.Closer(func() error { closeOutbox(); return nil }))
+	a.Worker("relay", func(ctx context.Context) error {
+		w.Run(ctx, *interval, func(err error) { logger.Print(err) })
+		return ctx.Err()
+	})
+	if *healthAddr != "" {
+		// Хвост outbox растёт, когда брокер недоступен или ретранслятор не успевает.
+		h := health.New(health.Config{})
+		h.Readiness(health.Check{Name: "outbox", Probe: health.MaxDepth(func(ctx context.Context) (int, error) {
+			pending, err := outbox.Pending(ctx, *maxPending+1)
+			return len(pending), err
+		}, *maxPending)})
+		mux := http.NewServeMux()
+		h.Register(mux)
+		a.HTTP("health", &http.Server{Addr: *healthAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second})
+	}
+	logger.Printf("relaying outbox to %s every %s", *brokerKind, *interval)
+	if err := a.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+	s := w.Stats()
+	logger.Printf("stopped: sent %d, failed %d, dead-lettered %d", s.Sent, s.Failed, s.DeadLetter)
+}
+
+func openOutbox(ctx context.Context, dsn string, seed int) (data.Outbox, func(), error) {
+	if dsn == "" {
+		db := data.NewOutboxDatabase()
+		dm := data.NewDataManager[map[string]string](db)
+		for i := 1; i <= seed; i++ {
+			if err := dm.SaveData(ctx, fmt.Sprintf("notes/%d", i), map[string]string{"title": fmt.Sprintf("note %d", i)}); err != nil {
+				return nil, nil, err
+			}
+		}
+		return db, func() {}, nil
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	store := sqlstore.New(db)
+	if err := store.Migrate(ctx); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("outboxrelay: migrate: %w", err)
+	}
+	return store, func() { db.Close() }, nil
+}
+
+func newBroker(kind, webhookURL, redisAddr, stream string) (outboxrelay.Broker, error) {
+	switch kind {
+	case "stdout":
+		return &outboxrelay.WriterBroker{W: os.Stdout}, nil
+	case "webhook":
+		if webhookURL == "" {
+			return nil, fmt.Errorf("outboxrelay: -webhook-url is required for the webhook broker")
+		}
+		b := breaker.New(breaker.Config{OnStateChange: func(from, to breaker.State) {
+			log.Printf("outboxrelay: webhook breaker %s -> %s", from, to)
+		}})
+		return &outboxrelay.Webhook{URL: webhookURL, Breaker: b}, nil
+	case "redis":
+		return &outboxrelay.RedisStream{Client: redis.NewClient(&redis.Options{Addr: redisAddr}), Stream: stream, MaxLen: 100_000}, nil
+	default:
+		return nil, fmt.Errorf("outboxrelay: unknown broker %q", kind)
+	}
+}
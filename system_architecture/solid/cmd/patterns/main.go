@@ -0,0 +1,257 @@
+// Команда patterns запускает демонстрации паттернов проектирования из design_patterns.
+// Пакеты регистрируют свои демонстрации сами (design_patterns/catalog), здесь их достаточно импортировать.
+//
+//	patterns list
+//	patterns run observer
+//	patterns verify [-update] [-dir cmd/patterns/testdata] [demo...]
+//	patterns check [-race] [check...]
+//
+// verify сравнивает вывод каждой демонстрации с эталоном <demo>.golden; check запускает
+// самопроверки и завершается с кодом 1, если хоть одна не прошла. С -race check
+// перезапускает себя через go run -race, и найденная гонка тоже валит проверку.
+// Обе команды запускать из корня модуля.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"solid/design_patterns/catalog"
+
+	_ "solid/concurrency/actor/spooler"
+	_ "solid/concurrency/ctxdemo"
+	_ "solid/concurrency/ring/ringbench"
+	_ "solid/design_patterns/abstractfactory"
+	_ "solid/design_patterns/adapter"
+	_ "solid/design_patterns/bridge"
+	_ "solid/design_patterns/builder"
+	_ "solid/design_patterns/chain"
+	_ "solid/design_patterns/command"
+	_ "solid/design_patterns/decorator"
+	_ "solid/design_patterns/factory"
+	_ "solid/design_patterns/flyweight"
+	_ "solid/design_patterns/iterator"
+	_ "solid/design_patterns/mediator"
+	_ "solid/design_patterns/memento"
+	_ "solid/design_patterns/nullobject"
+	_ "solid/design_patterns/observer"
+	_ "solid/design_patterns/pool"
+	_ "solid/design_patterns/prototype"
+	_ "solid/design_patterns/proxy"
+	_ "solid/design_patterns/singleton"
+	_ "solid/design_patterns/specification"
+	_ "solid/design_patterns/state"
+	_ "solid/design_patterns/strategy"
+	_ "solid/design_patterns/template"
+	_ "solid/design_patterns/visitor"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "list":
+		for _, d := range catalog.All() {
+			fmt.Printf("%-16s %s\n", d.Name, d.Summary)
+		}
+		for _, c := range catalog.Checks() {
+			fmt.Printf("%-16s check: %s\n", c.Name, c.Summary)
+		}
+	case "run":
+		if len(os.Args) != 3 {
+			usage()
+		}
+		d, ok := catalog.Lookup(os.Args[2])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown demo %q; see patterns list\n", os.Args[2])
+			os.Exit(2)
+		}
+		if err := d.Run(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "verify":
+		if !verify(os.Args[2:]) {
+			os.Exit(1)
+		}
+	case "check":
+		os.Exit(runChecks(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: patterns list | run <demo> | verify [-update] [-dir path] [demo...] | check [-race] [check...]")
+	os.Exit(2)
+}
+
+func verify(args []string) bool {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	update := fs.Bool("update", false, "rewrite golden files with the current output")
+	dir := fs.String("dir", filepath.Join("cmd", "patterns", "testdata"), "directory with golden files")
+	fs.Parse(args)
+
+	demos := catalog.All()
+	if fs.NArg() > 0 {
+		demos = demos[:0:0]
+		for _, name := range fs.Args() {
+			d, ok := catalog.Lookup(name)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "unknown demo %q\n", name)
+				return false
+			}
+			demos = append(demos, d)
+		}
+	}
+	ok := true
+	for _, d := range demos {
+		status, err := check(d, *dir, *update)
+		if err != nil {
+			ok = false
+			status = "FAIL " + err.Error()
+		}
+		fmt.Printf("%-16s %s\n", d.Name, status)
+	}
+	return ok
+}
+
+// runChecks возвращает код выхода: 0 - все проверки прошли.
+func runChecks(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	race := fs.Bool("race", false, "run the checks under the race detector")
+	fs.Parse(args)
+	if *race && !raceEnabled {
+		// Детектор включается только при сборке: пересобрать и запустить те же проверки.
+		cmd := exec.Command("go", append([]string{"run", "-race", "./cmd/patterns", "check"}, fs.Args()...)...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			if exit := (*exec.ExitError)(nil); errors.As(err, &exit) {
+				return exit.ExitCode()
+			}
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	checks := catalog.Checks()
+	if fs.NArg() > 0 {
+		checks = checks[:0:0]
+		for _, name := range fs.Args() {
+			c, ok := catalog.LookupCheck(name)
+			if !ok {
+				fmt.Fprintf(os.Stderr, "unknown check %q; see patterns list\n", name)
+				return 2
+			}
+			checks = append(checks, c)
+		}
+	}
+	code := 0
+	for _, c := range checks {
+		fmt.Printf("== %s\n", c.Name)
+		if err := c.Run(os.Stdout); err != nil {
+			fmt.Printf("FAIL %s: %v\n", c.Name, err)
+			code = 1
+			continue
+		}
+		fmt.Printf("ok   %s\n", c.Name)
+	}
+	return code
+}
+
+func check(d catalog.Demo, dir string, update bool) (string, error) {
+	out, err := capture(d)
+	if err != nil {
+		return "", err
+	}
+	if d.Unstable {
+		return "ok (output not compared)", nil
+	}
+	got := normalize(out)
+	path := filepath.Join(dir, d.Name+".golden")
+	if update {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", err
+		}
+		return "updated", os.WriteFile(path, []byte(got), 0o644)
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%v (run verify -update to create it)", err)
+	}
+	if got != string(want) {
+		return "", firstDiff(string(want), got)
+	}
+	return "ok", nil
+}
+
+// capture запускает демонстрацию, подменяя и os.Stdout: вывод мимо w тоже попадает
+// в эталон, как он попал бы на экран при patterns run.
+func capture(d catalog.Demo) (string, error) {
+	f, err := os.CreateTemp("", "patterns-"+d.Name)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	stdout := os.Stdout
+	os.Stdout = f
+	runErr := d.Run(f)
+	os.Stdout = stdout
+	if runErr != nil {
+		return "", runErr
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(f)
+	return buf.String(), err
+}
+
+// volatile - части вывода, меняющиеся от запуска к запуску: время, длительности,
+// случайные идентификаторы и адреса. В эталоне они заменены метками.
+var volatile = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}T[0-9:.]+(Z|[+-]\d{2}:\d{2})?`), "<time>"},
+	{regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`), "<date>"},
+	{regexp.MustCompile(`\b\d+(\.\d+)?(ns|µs|ms|s)\b`), "<duration>"},
+	{regexp.MustCompile(`0x[0-9a-f]+`), "<addr>"},
+	{regexp.MustCompile(`\b[0-9a-f]{16}\b`), "<id>"},
+}
+
+func normalize(s string) string {
+	for _, v := range volatile {
+		s = v.re.ReplaceAllString(s, v.repl)
+	}
+	return s
+}
+
+func firstDiff(want, got string) error {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < max(len(w), len(g)); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			return fmt.Errorf("line %d: want %q, got %q", i+1, wl, gl)
+		}
+	}
+	return fmt.Errorf("output differs")
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package main
+
+// raceEnabled - бинарник собран с -race.
+const raceEnabled = true
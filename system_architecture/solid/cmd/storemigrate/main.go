@@ -0,0 +1,123 @@
+// Команда storemigrate копирует записи из одного хранилища data.Storage в другое:
+// каталог файлов (data.Filesystem) или PostgreSQL (sqlstore), если адрес начинается
+// с postgres://. Записи копируются пулом воркеров, в памяти - только те, что в работе;
+// с -checkpoint прерванное копирование продолжается с места остановки. Записи с
+// ошибкой контрольная точка проходит: повторный запуск без неё докопирует их, а уже
+// скопированные пропустит.
+//
+//	storemigrate -from data -to postgres://... -prefix recommendations/
+//	storemigrate -from old-data -to new-data -workers 16 -checkpoint .migrate
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"solid/concurrency/batch"
+	"solid/data"
+	"solid/data/sqlstore"
+)
+
+type outcome int
+
+const (
+	copied outcome = iota
+	skipped
+)
+
+func main() {
+	from := flag.String("from", "", "source: a directory or a postgres:// DSN")
+	to := flag.String("to", "", "destination: a directory or a postgres:// DSN")
+	prefix := flag.String("prefix", "", "copy only keys with this prefix")
+	workers := flag.Int("workers", 8, "records copied at once")
+	overwrite := flag.Bool("overwrite", false, "replace records that already exist in the destination")
+	checkpoint := flag.String("checkpoint", "", "directory for a checkpoint to resume an interrupted copy (starts over if empty)")
+	flag.Parse()
+	if *from == "" || *to == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	src, closeSrc, err := open(ctx, *from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeSrc()
+	dst, closeDst, err := open(ctx, *to)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeDst()
+
+	// Ключи - единственное, что держится в памяти целиком; значения читаются по одному на воркер.
+	keys, err := src.List(ctx, *prefix)
+	if err != nil {
+		log.Fatalf("storemigrate: list %s: %v", *from, err)
+	}
+	cfg := batch.Config{Workers: *workers}
+	if *checkpoint != "" {
+		cfg.Checkpoint, cfg.Key = data.NewFilesystem(*checkpoint), "storemigrate/"+*prefix
+	}
+	var done [2]int
+	stats, err := batch.Run(ctx, cfg, batch.Slice(keys), func(ctx context.Context, key string) (outcome, error) {
+		if !*overwrite {
+			if _, err := dst.Load(ctx, key); err == nil {
+				return skipped, nil
+			} else if !errors.Is(err, data.ErrNotFound) {
+				return 0, err
+			}
+		}
+		val, err := src.Load(ctx, key)
+		if errors.Is(err, data.ErrNotFound) {
+			// Удалена после List.
+			return skipped, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		return copied, dst.Save(ctx, key, val)
+	}, func(ctx context.Context, it batch.Item[string, outcome]) error {
+		if it.Err != nil {
+			log.Printf("storemigrate: %s: %v", it.Input, it.Err)
+			return nil
+		}
+		done[it.Value]++
+		return nil
+	})
+	log.Printf("storemigrate: %d keys, copied %d, skipped %d, failed %d, resumed after %d",
+		len(keys), done[copied], done[skipped], stats.Failed, stats.Resumed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if stats.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func open(ctx context.Context, spec string) (data.Storage, func(), error) {
+	if !strings.HasPrefix(spec, "postgres://") {
+		return data.NewFilesystem(spec), func() {}, nil
+	}
+	db, err := sql.Open("pgx", spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	store := sqlstore.New(db)
+	if err := store.Migrate(ctx); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("storemigrate: migrate: %w", err)
+	}
+	return store, func() { db.Close() }, nil
+}
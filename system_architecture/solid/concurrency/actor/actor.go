@@ -0,0 +1,357 @@
+// Package actor - минимальная модель акторов: у каждого актора свой почтовый ящик и
+// своё состояние, которое видит только его горутина, поэтому мьютексы не нужны.
+// Сообщения типизированы дженериками: Ref[M] принимает только M.
+//
+// Сбой обработчика (ошибка или паника) разбирает супервизор актора: пропустить
+// сообщение и продолжить, перезапустить актора с чистым состоянием или остановить.
+// Перезапуски ограничены, чтобы актор, падающий на каждом сообщении, не крутился вечно.
+package actor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const DefaultMailbox = 64
+
+var (
+	// ErrStopped - актор остановлен и сообщений больше не принимает.
+	ErrStopped = errors.New("actor: stopped")
+	// ErrTooManyRestarts - супервизор исчерпал MaxRestarts за Within и остановил актора.
+	ErrTooManyRestarts = errors.New("actor: too many restarts")
+)
+
+// PanicError - паника обработчика, превращённая в сбой для супервизора.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("actor: handler panicked: %v", e.Value) }
+
+// Handler обрабатывает одно сообщение. Состояние актора живёт в замыкании, которое
+// создаёт Props.New, и трогать его может только сам обработчик.
+type Handler[M any] func(ctx context.Context, msg M) error
+
+type Directive int
+
+const (
+	// Restart отбрасывает состояние: следующее сообщение получит новый обработчик из Props.New.
+	Restart Directive = iota
+	// Resume пропускает сообщение и продолжает с тем же состоянием.
+	Resume
+	// Stop останавливает актора; оставшиеся сообщения не обрабатываются.
+	Stop
+)
+
+func (d Directive) String() string {
+	switch d {
+	case Restart:
+		return "restart"
+	case Resume:
+		return "resume"
+	case Stop:
+		return "stop"
+	}
+	return fmt.Sprintf("directive(%d)", int(d))
+}
+
+// Supervisor решает, что делать со сбоем; нулевое значение перезапускает актора
+// не больше трёх раз за минуту.
+type Supervisor struct {
+	// Decide выбирает реакцию на ошибку; nil - всегда Restart.
+	Decide func(err error) Directive
+	// MaxRestarts за окно Within, по умолчанию 3 за минуту; сверх них актор
+	// останавливается с ErrTooManyRestarts.
+	MaxRestarts int
+	Within      time.Duration
+	// Backoff - пауза перед перезапуском, чтобы не долбить упавшую зависимость.
+	Backoff time.Duration
+}
+
+// OneForOne - супервизор, который перезапускает упавшего актора, а ошибки из
+// resume пропускает, не трогая состояние.
+func OneForOne(resume ...error) Supervisor {
+	return Supervisor{Decide: func(err error) Directive {
+		for _, target := range resume {
+			if errors.Is(err, target) {
+				return Resume
+			}
+		}
+		return Restart
+	}}
+}
+
+// Props - как создать актора.
+type Props[M any] struct {
+	New func() Handler[M]
+	// Mailbox - ёмкость ящика, по умолчанию DefaultMailbox; при полном ящике Send ждёт.
+	Mailbox    int
+	Supervisor Supervisor
+	// Failed, если задан, получает сообщение, на котором актор упал, и решение
+	// супервизора - например чтобы передать работу другому актору.
+	Failed func(msg M, err error, d Directive)
+}
+
+type Config struct {
+	// Logger получает строку на каждый сбой; nil - log.Default().
+	Logger *log.Logger
+}
+
+// System владеет акторами: отмена её контекста или Shutdown останавливает их всех.
+type System struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	logger *log.Logger
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	actors []interface{ Stop() }
+}
+
+func NewSystem(ctx context.Context, cfg Config) *System {
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &System{ctx: ctx, cancel: cancel, logger: cfg.Logger}
+}
+
+// Shutdown просит всех акторов дообработать ящики и ждёт их до отмены ctx; после
+// этого контекст системы отменяется, и недообработанные сообщения теряются.
+func (s *System) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	actors := s.actors
+	s.mu.Unlock()
+	for _, a := range actors {
+		a.Stop()
+	}
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	defer s.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Ref - адрес актора; копируется свободно.
+type Ref[M any] struct {
+	a *actor[M]
+}
+
+type actor[M any] struct {
+	name    string
+	sys     *System
+	props   Props[M]
+	mailbox chan M
+
+	stopOnce sync.Once
+	stopping chan struct{}
+	done     chan struct{}
+	err      error
+
+	mu       sync.Mutex
+	restarts int
+}
+
+// Spawn запускает актора name.
+func Spawn[M any](s *System, name string, props Props[M]) Ref[M] {
+	if props.Mailbox <= 0 {
+		props.Mailbox = DefaultMailbox
+	}
+	sv := &props.Supervisor
+	if sv.Decide == nil {
+		sv.Decide = func(error) Directive { return Restart }
+	}
+	if sv.MaxRestarts <= 0 {
+		sv.MaxRestarts = 3
+	}
+	if sv.Within <= 0 {
+		sv.Within = time.Minute
+	}
+	a := &actor[M]{
+		name: name, sys: s, props: props,
+		mailbox:  make(chan M, props.Mailbox),
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.actors = append(s.actors, a)
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go a.loop()
+	return Ref[M]{a: a}
+}
+
+func (r Ref[M]) Name() string { return r.a.name }
+
+// Send кладёт сообщение в ящик, ожидая места не дольше ctx. Сообщение, отправленное
+// одновременно со Stop, может не дойти.
+func (r Ref[M]) Send(ctx context.Context, msg M) error {
+	select {
+	case <-r.a.stopping:
+		return ErrStopped
+	default:
+	}
+	select {
+	case r.a.mailbox <- msg:
+		return nil
+	case <-r.a.stopping:
+		return ErrStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TrySend кладёт сообщение, только если в ящике есть место.
+func (r Ref[M]) TrySend(msg M) bool {
+	select {
+	case <-r.a.stopping:
+		return false
+	default:
+	}
+	select {
+	case r.a.mailbox <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop перестаёт принимать сообщения; уже лежащие в ящике будут обработаны.
+func (r Ref[M]) Stop() { r.a.Stop() }
+
+// Done закрывается, когда актор завершился.
+func (r Ref[M]) Done() <-chan struct{} { return r.a.done }
+
+// Err - почему актор завершился: nil после Stop, ErrTooManyRestarts или сбой после
+// решения Stop. До Done - nil.
+func (r Ref[M]) Err() error {
+	select {
+	case <-r.a.done:
+		return r.a.err
+	default:
+		return nil
+	}
+}
+
+// Restarts - сколько раз супервизор решил перезапустить актора.
+func (r Ref[M]) Restarts() int {
+	r.a.mu.Lock()
+	defer r.a.mu.Unlock()
+	return r.a.restarts
+}
+
+// Ask отправляет сообщение, собранное build вокруг канала ответа, и ждёт ответ
+// до отмены ctx или остановки актора.
+func Ask[M, R any](ctx context.Context, r Ref[M], build func(reply chan<- R) M) (R, error) {
+	var zero R
+	reply := make(chan R, 1)
+	if err := r.Send(ctx, build(reply)); err != nil {
+		return zero, err
+	}
+	select {
+	case v := <-reply:
+		return v, nil
+	case <-r.a.done:
+		// Актор мог ответить и сразу завершиться.
+		select {
+		case v := <-reply:
+			return v, nil
+		default:
+			return zero, ErrStopped
+		}
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+func (a *actor[M]) Stop() {
+	a.stopOnce.Do(func() { close(a.stopping) })
+}
+
+func (a *actor[M]) loop() {
+	defer a.sys.wg.Done()
+	defer close(a.done)
+	defer a.Stop()
+	ctx := a.sys.ctx
+	handler := a.props.New()
+	var failures []time.Time
+	for {
+		var msg M
+		select {
+		case msg = <-a.mailbox:
+		case <-a.stopping:
+			// Дообрабатываем то, что успело лечь в ящик до Stop.
+			select {
+			case msg = <-a.mailbox:
+			default:
+				return
+			}
+		case <-ctx.Done():
+			a.err = ctx.Err()
+			return
+		}
+		err := a.receive(ctx, handler, msg)
+		if err == nil {
+			continue
+		}
+		d := a.props.Supervisor.Decide(err)
+		if d == Restart {
+			now := time.Now()
+			failures = append(failures, now)
+			for len(failures) > 0 && now.Sub(failures[0]) > a.props.Supervisor.Within {
+				failures = failures[1:]
+			}
+			if len(failures) > a.props.Supervisor.MaxRestarts {
+				d = Stop
+				err = fmt.Errorf("%w: %d in %s: %w", ErrTooManyRestarts, len(failures), a.props.Supervisor.Within, err)
+			} else {
+				a.mu.Lock()
+				a.restarts++
+				a.mu.Unlock()
+			}
+		}
+		a.sys.logger.Printf("actor: %s failed (%s): %v", a.name, d, err)
+		if a.props.Failed != nil {
+			a.props.Failed(msg, err, d)
+		}
+		switch d {
+		case Stop:
+			a.err = err
+			return
+		case Restart:
+			if b := a.props.Supervisor.Backoff; b > 0 {
+				select {
+				case <-time.After(b):
+				case <-ctx.Done():
+					a.err = ctx.Err()
+					return
+				}
+			}
+			handler = a.props.New()
+		}
+	}
+}
+
+func (a *actor[M]) receive(ctx context.Context, h Handler[M], msg M) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return h(ctx, msg)
+}
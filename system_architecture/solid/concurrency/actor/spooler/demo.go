@@ -0,0 +1,52 @@
+package spooler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "spooler", Summary: "print spooler on a mutex and on supervised actors", Run: Demo})
+}
+
+// Demo печатает одни и те же задания обоими спулерами; у второго задания один раз
+// замятие. Какой принтер что напечатал, зависит от планировщика, поэтому выводятся
+// только итоги, которые от него не зависят.
+func Demo(w io.Writer) error {
+	ctx := context.Background()
+	jobs := []Job{{"j1", 2}, {"j2", 1}, {"j3", 3}, {"j4", 1}, {"j5", 2}, {"j6", 1}}
+	for _, v := range []struct {
+		name string
+		run  func(context.Context, int, []Job, Jam) (Report, error)
+	}{{"locked", Locked}, {"actors", Actors}} {
+		r, err := v.run(ctx, 3, jobs, jamOnce("j2"))
+		if err != nil {
+			return fmt.Errorf("%s: %w", v.name, err)
+		}
+		fmt.Fprintf(w, "%s: %d jobs %v, %d copies, %d jam\n", v.name, len(r.ByJob), slices.Sorted(maps.Keys(r.ByJob)), r.Printed, r.Jams)
+	}
+	return nil
+}
+
+// jamOnce заминает бумагу на задании id при первой попытке; устройств несколько,
+// поэтому счётчик общий и под мьютексом - это состояние «железа», а не спулера.
+func jamOnce(id string) Jam {
+	var mu sync.Mutex
+	jammed := false
+	return func(_ string, j Job) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if j.ID != id || jammed {
+			return nil
+		}
+		jammed = true
+		return errors.New("paper jam")
+	}
+}
@@ -0,0 +1,216 @@
+// Package spooler - очередь печати в двух вариантах для сравнения. Locked устроен как
+// rabbitmq/internal/printing: принтеры-горутины разбирают общую очередь и пишут общий
+// журнал под мьютексом, а сбой печати принтер повторяет сам. В Actors журналом и
+// очередью владеет актор-спулер, принтеры - акторы под надзором: замятый принтер
+// падает, супервизор перезапускает его, а спулер отдаёт задание следующему
+// свободному принтеру. Мьютексов в нём нет - состояние видит только его владелец.
+package spooler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	"solid/concurrency/actor"
+)
+
+type Job struct {
+	ID     string
+	Copies int
+}
+
+// Jam - сбой устройства: ошибка значит, что бумагу замяло и задание не напечатано.
+type Jam func(printer string, j Job) error
+
+// Report - кто напечатал каждое задание, сколько отпечатков и сколько было сбоев.
+type Report struct {
+	ByJob   map[string]string
+	Printed int
+	Jams    int
+}
+
+// Locked печатает jobs на printers принтерах с общим журналом под мьютексом.
+func Locked(ctx context.Context, printers int, jobs []Job, jam Jam) (Report, error) {
+	var (
+		mu     sync.Mutex
+		report = Report{ByJob: map[string]string{}}
+	)
+	queue := make(chan Job, len(jobs))
+	for _, j := range jobs {
+		queue <- j
+	}
+	close(queue)
+	var wg sync.WaitGroup
+	for i := range printers {
+		name := fmt.Sprintf("printer-%d", i+1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				for jam != nil && jam(name, j) != nil {
+					mu.Lock()
+					report.Jams++
+					mu.Unlock()
+				}
+				mu.Lock()
+				report.ByJob[j.ID] = name
+				report.Printed += j.Copies
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return report, ctx.Err()
+}
+
+// spoolMsg - сообщения спулера; закрытый набор типов вместо any.
+type spoolMsg interface{ spool() }
+
+type (
+	submit  struct{ job Job }
+	printed struct {
+		job     Job
+		printer string
+	}
+	jammed struct {
+		job     Job
+		printer string
+	}
+	// stopped - супервизор сдался, принтер больше не вернётся в пул.
+	stopped struct {
+		printer string
+		job     Job
+	}
+	drained struct{ reply chan<- Report }
+)
+
+func (submit) spool()  {}
+func (printed) spool() {}
+func (jammed) spool()  {}
+func (stopped) spool() {}
+func (drained) spool() {}
+
+// ErrNoPrinters - все принтеры остановлены, а задания остались.
+var ErrNoPrinters = errors.New("spooler: no printers left")
+
+// Actors печатает jobs на printers акторах-принтерах через актор-спулер.
+func Actors(ctx context.Context, printers int, jobs []Job, jam Jam) (Report, error) {
+	sys := actor.NewSystem(ctx, actor.Config{Logger: log.New(io.Discard, "", 0)})
+	defer sys.Shutdown(context.Background())
+
+	var spooler actor.Ref[spoolMsg]
+	pool := make(map[string]actor.Ref[Job], printers)
+	for i := range printers {
+		name := fmt.Sprintf("printer-%d", i+1)
+		pool[name] = actor.Spawn(sys, name, actor.Props[Job]{
+			New:     func() actor.Handler[Job] { return printer(name, &spooler, jam) },
+			Mailbox: 1,
+			Failed: func(j Job, err error, d actor.Directive) {
+				var msg spoolMsg = jammed{job: j, printer: name}
+				if d == actor.Stop {
+					msg = stopped{job: j, printer: name}
+				}
+				spooler.Send(context.Background(), msg)
+			},
+		})
+	}
+	spooler = actor.Spawn(sys, "spooler", actor.Props[spoolMsg]{
+		New:     func() actor.Handler[spoolMsg] { return newSpool(pool).receive },
+		Mailbox: len(jobs) + printers,
+	})
+	for _, j := range jobs {
+		if err := spooler.Send(ctx, submit{job: j}); err != nil {
+			return Report{}, err
+		}
+	}
+	report, err := actor.Ask(ctx, spooler, func(reply chan<- Report) spoolMsg { return drained{reply: reply} })
+	if err != nil {
+		return report, err
+	}
+	if len(report.ByJob) < len(jobs) {
+		return report, ErrNoPrinters
+	}
+	return report, nil
+}
+
+// printer - состояние одного принтера; при замятии он паникует, как настоящий
+// драйвер, и супервизор заводит его заново.
+func printer(name string, spooler *actor.Ref[spoolMsg], jam Jam) actor.Handler[Job] {
+	return func(ctx context.Context, j Job) error {
+		if jam != nil {
+			if err := jam(name, j); err != nil {
+				panic(err)
+			}
+		}
+		return spooler.Send(ctx, printed{job: j, printer: name})
+	}
+}
+
+// spool - состояние спулера: очередь, свободные принтеры и журнал. Живёт только в
+// горутине актора-спулера.
+type spool struct {
+	pool    map[string]actor.Ref[Job]
+	idle    []string
+	queue   []Job
+	busy    int
+	report  Report
+	waiters []chan<- Report
+}
+
+func newSpool(pool map[string]actor.Ref[Job]) *spool {
+	s := &spool{pool: pool, report: Report{ByJob: map[string]string{}}}
+	for i := range len(pool) {
+		s.idle = append(s.idle, fmt.Sprintf("printer-%d", i+1))
+	}
+	return s
+}
+
+func (s *spool) receive(ctx context.Context, msg spoolMsg) error {
+	switch m := msg.(type) {
+	case submit:
+		s.queue = append(s.queue, m.job)
+	case printed:
+		s.busy--
+		s.idle = append(s.idle, m.printer)
+		s.report.ByJob[m.job.ID] = m.printer
+		s.report.Printed += m.job.Copies
+	case jammed:
+		// Принтер перезапущен с чистым состоянием и снова свободен; задание - в начало очереди.
+		s.busy--
+		s.idle = append(s.idle, m.printer)
+		s.report.Jams++
+		s.queue = append([]Job{m.job}, s.queue...)
+	case stopped:
+		s.busy--
+		s.report.Jams++
+		delete(s.pool, m.printer)
+		s.queue = append([]Job{m.job}, s.queue...)
+	case drained:
+		s.waiters = append(s.waiters, m.reply)
+	}
+	s.dispatch()
+	if s.busy == 0 && (len(s.queue) == 0 || len(s.pool) == 0) {
+		for _, w := range s.waiters {
+			w <- s.report
+		}
+		s.waiters = nil
+	}
+	return nil
+}
+
+// dispatch раздаёт задания свободным принтерам. Ящик свободного принтера пуст,
+// поэтому отправка не ждёт.
+func (s *spool) dispatch() {
+	for len(s.queue) > 0 && len(s.idle) > 0 {
+		name := s.idle[0]
+		s.idle = s.idle[1:]
+		if !s.pool[name].TrySend(s.queue[0]) {
+			continue
+		}
+		s.queue = s.queue[1:]
+		s.busy++
+	}
+}
@@ -0,0 +1,177 @@
+// Package batch - обработка длинного потока элементов пулом воркеров с ограниченной
+// памятью: элементы читаются из итератора по мере того, как освобождаются места,
+// а результаты сразу, в порядке потока, уходят в write и не копятся.
+//
+// С Checkpoint в data.Storage хранится, сколько элементов с начала потока уже
+// записано. После сбоя или остановки Run с тем же ключом пропускает их и продолжает
+// со следующего, поэтому поток должен выдавать элементы в одном и том же порядке.
+// Элементы после контрольной точки могут пройти повторно: write должна это переносить.
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+
+	"solid/concurrency/pool"
+	"solid/data"
+)
+
+const DefaultEvery = 100
+
+// Config - нулевые поля заменяются значениями по умолчанию.
+type Config struct {
+	// Workers - сколько элементов обрабатываются одновременно, по умолчанию
+	// pool.DefaultWorkers.
+	Workers int
+	// Window - сколько обработанных элементов ждут записи или воркера сверх Workers,
+	// по умолчанию Workers: в памяти не больше Workers+Window элементов.
+	Window int
+	// Checkpoint хранит контрольную точку под Key; nil - каждый Run начинает сначала.
+	Checkpoint data.Storage
+	Key        string
+	// Every - через сколько записанных элементов сохранять контрольную точку, по
+	// умолчанию DefaultEvery; в конце Run она сохраняется всегда.
+	Every int
+}
+
+// Item - результат элемента. Index - номер элемента в потоке с начала, а не с
+// контрольной точки; Err - ошибка process, а не записи.
+type Item[T, R any] struct {
+	Index int
+	Input T
+	Value R
+	Err   error
+}
+
+// Stats - итог Run.
+type Stats struct {
+	// Resumed - сколько элементов пропущено по контрольной точке.
+	Resumed int `json:"resumed"`
+	// Written - сколько результатов принято write в этом запуске.
+	Written int `json:"written"`
+	// Failed - сколько из них с ошибкой process.
+	Failed int `json:"failed"`
+}
+
+// Slice - поток из уже загруженных элементов, для Run над срезом.
+func Slice[T any](items []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, v := range items {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+type checkpoint struct {
+	Done int `json:"done"`
+}
+
+// Run обрабатывает items через process и передаёт результаты write по порядку.
+// Ошибка process не останавливает поток, а приходит в Item.Err. Ошибка итератора
+// или write останавливает его: результаты до неё записаны, контрольная точка
+// сохранена, и следующий Run продолжит с первого незаписанного элемента.
+func Run[T, R any](ctx context.Context, cfg Config, items iter.Seq2[T, error], process pool.Func[T, R], write func(ctx context.Context, it Item[T, R]) error) (Stats, error) {
+	if cfg.Checkpoint != nil && cfg.Key == "" {
+		return Stats{}, errors.New("batch: checkpoint needs a key")
+	}
+	if cfg.Every <= 0 {
+		cfg.Every = DefaultEvery
+	}
+	done, err := load(ctx, cfg)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats := Stats{Resumed: done}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p := pool.New(ctx, pool.Config{Workers: cfg.Workers, Queue: cfg.Window, Ordered: true}, process)
+	// srcErr пишется до p.Close, а читается после закрытия Results.
+	var srcErr error
+	go func() {
+		defer p.Close()
+		i := 0
+		for v, err := range items {
+			if err != nil {
+				srcErr = fmt.Errorf("batch: read item %d: %w", i, err)
+				return
+			}
+			i++
+			if i <= done {
+				continue
+			}
+			if p.Submit(v) != nil {
+				return
+			}
+		}
+	}()
+
+	var writeErr error
+	for r := range p.Results() {
+		// После сбоя или отмены результаты только дочитываются, чтобы воркеры закончили.
+		if writeErr != nil || ctx.Err() != nil {
+			continue
+		}
+		it := Item[T, R]{Index: done + r.Index, Input: r.Input, Value: r.Value, Err: r.Err}
+		if err := write(ctx, it); err != nil {
+			writeErr = fmt.Errorf("batch: write item %d: %w", it.Index, err)
+			cancel()
+			continue
+		}
+		stats.Written++
+		if r.Err != nil {
+			stats.Failed++
+		}
+		if stats.Written%cfg.Every == 0 {
+			if err := save(ctx, cfg, done+stats.Written); err != nil {
+				writeErr = err
+				cancel()
+			}
+		}
+	}
+	// Итоговая точка сохраняется и после отмены: записанное не должно пройти заново.
+	if err := save(context.WithoutCancel(ctx), cfg, done+stats.Written); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	switch {
+	case writeErr != nil:
+		return stats, writeErr
+	case srcErr != nil:
+		return stats, srcErr
+	}
+	return stats, context.Cause(ctx)
+}
+
+func load(ctx context.Context, cfg Config) (int, error) {
+	if cfg.Checkpoint == nil {
+		return 0, nil
+	}
+	raw, err := cfg.Checkpoint.Load(ctx, cfg.Key)
+	if errors.Is(err, data.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("batch: load checkpoint %s: %w", cfg.Key, err)
+	}
+	var cp checkpoint
+	if err := json.Unmarshal([]byte(raw), &cp); err != nil {
+		return 0, fmt.Errorf("batch: decode checkpoint %s: %w", cfg.Key, err)
+	}
+	return cp.Done, nil
+}
+
+func save(ctx context.Context, cfg Config, done int) error {
+	if cfg.Checkpoint == nil {
+		return nil
+	}
+	raw, _ := json.Marshal(checkpoint{Done: done})
+	if err := cfg.Checkpoint.Save(ctx, cfg.Key, string(raw)); err != nil {
+		return fmt.Errorf("batch: save checkpoint %s: %w", cfg.Key, err)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Package coalesce сводит одновременные запросы одного ключа в один вызов
+// (singleflight) и, если задан TTL, ещё какое-то время отдаёт его результат без
+// вызова.
+//
+// В отличие от cache.Cache здесь нет вытеснения по размеру: сохранённый результат
+// живёт только TTL, а истёкшие удаляются по мере роста числа ключей. Вызов общий,
+// поэтому и отмена у него общая: ждущий, чей ctx отменили, уходит сразу, а сам вызов
+// отменяется, только когда его бросили все ждущие, или по сроку ctx первого
+// вызвавшего - дольше, чем позволил запустивший его запрос, вызов не идёт.
+package coalesce
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sweepMin - с какого числа ключей истёкшие результаты начинают вычищаться.
+const sweepMin = 64
+
+// Config - нулевые поля заменяются значениями по умолчанию.
+type Config[K comparable] struct {
+	// TTL - сколько успешный результат отдаётся новым вызовам Do после завершения;
+	// 0 - результат получают только те, кто ждал во время вызова.
+	TTL time.Duration
+	// KeyTTL, если задан, выбирает TTL для ключа вместо TTL; 0 - не сохранять.
+	KeyTTL func(key K) time.Duration
+	Now    func() time.Time
+}
+
+type Stats struct {
+	// Calls - сколько раз вызывалась fn.
+	Calls int64
+	// Shared - вызовы Do, дождавшиеся чужого вызова fn.
+	Shared int64
+	// Hits - вызовы Do, получившие сохранённый результат.
+	Hits int64
+	// Abandoned - вызовы fn, отменённые, потому что их результат никто не ждёт.
+	Abandoned int64
+}
+
+// flight - вызов fn для ключа; после завершения с TTL он же хранит результат.
+type flight[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// waiters - сколько Do ждут вызов; 0 до завершения - вызов брошен.
+	waiters  int
+	cancel   context.CancelFunc
+	finished bool
+	// stale - ключ забыли (Forget, Clear) во время вызова: результат получат только
+	// уже ждущие, сохранён он не будет, как у cache.Cache.
+	stale   bool
+	expires time.Time
+}
+
+// Group - группа вызовов с общим пространством ключей. Результат общий для всех
+// ждущих: значения со ссылками (срезы, карты) менять нельзя.
+type Group[K comparable, V any] struct {
+	cfg Config[K]
+
+	mu      sync.Mutex
+	flights map[K]*flight[V]
+	sweepAt int
+	stats   Stats
+}
+
+func New[K comparable, V any](cfg Config[K]) *Group[K, V] {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Group[K, V]{cfg: cfg, flights: make(map[K]*flight[V]), sweepAt: sweepMin}
+}
+
+// Do отдаёт сохранённый результат key, ждёт уже идущий вызов или запускает fn.
+// fn получает контекст без отмены ctx, но со значениями и сроком ctx первого
+// вызвавшего; он отменяется, когда все ждущие ушли по своим ctx. Ждущий с более
+// долгим сроком получает ошибку срока вместе со всеми. Ошибки не сохраняются.
+func (g *Group[K, V]) Do(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (V, error) {
+	g.mu.Lock()
+	f, ok := g.flights[key]
+	if ok && f.finished {
+		if g.cfg.Now().Before(f.expires) {
+			g.stats.Hits++
+			g.mu.Unlock()
+			return f.value, f.err
+		}
+		delete(g.flights, key)
+		ok = false
+	}
+	if ok {
+		g.stats.Shared++
+	} else {
+		fctx, cancel := detach(ctx)
+		f = &flight[V]{done: make(chan struct{}), cancel: cancel}
+		g.flights[key] = f
+		g.stats.Calls++
+		g.sweep()
+		go g.run(fctx, key, f, fn)
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		g.leave(key, f)
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (g *Group[K, V]) run(ctx context.Context, key K, f *flight[V], fn func(ctx context.Context) (V, error)) {
+	defer close(f.done)
+	defer f.cancel()
+	v, err := call(ctx, fn)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f.value, f.err, f.finished = v, err, true
+	if f.stale || g.flights[key] != f {
+		// Брошен или забыт - результат получают только те, кто его ещё ждёт.
+		return
+	}
+	ttl := g.cfg.TTL
+	if g.cfg.KeyTTL != nil {
+		ttl = g.cfg.KeyTTL(key)
+	}
+	if err != nil || ttl <= 0 {
+		delete(g.flights, key)
+		return
+	}
+	f.expires = g.cfg.Now().Add(ttl)
+}
+
+// detach - контекст вызова: значения и срок ctx, но не его отмена.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	base := context.WithoutCancel(ctx)
+	if d, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(base, d)
+	}
+	return context.WithCancel(base)
+}
+
+// call - fn с паникой, превращённой в ошибку: иначе ждущие не дождались бы вызова.
+func call[V any](ctx context.Context, fn func(ctx context.Context) (V, error)) (v V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("coalesce: panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// leave снимает ушедшего ждущего; последний ушедший отменяет вызов, а следующий Do
+// начнёт новый.
+func (g *Group[K, V]) leave(key K, f *flight[V]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f.waiters--
+	if f.waiters > 0 || f.finished {
+		return
+	}
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+	g.stats.Abandoned++
+	f.cancel()
+}
+
+// Forget забывает сохранённый результат key, а идущий вызов помечает устаревшим:
+// его дождутся те, кто уже ждёт, но сохранён он не будет, и следующий Do вызовет
+// fn заново. Нужен после изменения данных, которые читает fn: вызов, начатый до
+// изменения, мог прочитать старое.
+func (g *Group[K, V]) Forget(key K) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.markStale(key)
+}
+
+// Clear забывает все ключи, как Forget.
+func (g *Group[K, V]) Clear() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for key := range g.flights {
+		g.markStale(key)
+	}
+}
+
+func (g *Group[K, V]) markStale(key K) {
+	if f, ok := g.flights[key]; ok {
+		f.stale = true
+		delete(g.flights, key)
+	}
+}
+
+func (g *Group[K, V]) Stats() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stats
+}
+
+// sweep удаляет истёкшие результаты, когда ключей стало вдвое больше, чем после
+// прошлой чистки, - так каждый Do в среднем платит за чистку постоянное время.
+func (g *Group[K, V]) sweep() {
+	if len(g.flights) < g.sweepAt {
+		return
+	}
+	now := g.cfg.Now()
+	for k, f := range g.flights {
+		if f.finished && !now.Before(f.expires) {
+			delete(g.flights, k)
+		}
+	}
+	g.sweepAt = max(2*len(g.flights), sweepMin)
+}
@@ -0,0 +1,62 @@
+// Package ctxdemo - как передавать отмену так, чтобы горутины не оставались висеть,
+// и проверка этого для фоновых компонентов репозитория.
+//
+// Правила, которым следуют примеры ниже и компоненты из Check:
+//   - ctx - первый аргумент, его получает каждый вызов ниже по цепочке, а не
+//     context.Background() посередине;
+//   - каждая отправка и каждое ожидание в горутине - select с <-ctx.Done(), иначе
+//     горутина переживёт отменённого читателя;
+//   - тот, кто запустил горутину, дожидается её выхода (WaitGroup, закрытый канал);
+//   - работа, которая должна закончиться и после отмены (сохранить итог, отпустить
+//     ресурс), берёт context.WithoutCancel и свой короткий срок.
+//
+// Check запускает каждый компонент, отменяет контекст посреди работы и сверяет
+// горутины до и после (Snapshot, Leaks) - без сторонних библиотек, по runtime.Stack.
+// Утечка или компонент, не услышавший отмену, - провал проверки:
+//
+//	go run ./cmd/patterns check ctxdemo
+package ctxdemo
+
+import (
+	"context"
+	"time"
+)
+
+// Count - источник, который сам не останавливается: отдаёт 0, 1, 2, ... до отмены ctx.
+// Канал закрывается, когда горутина вышла, поэтому читатель может просто дочитать его;
+// читатель, который бросает чтение раньше, должен отменить ctx.
+func Count(ctx context.Context) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for i := 0; ; i++ {
+			select {
+			case out <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Sleep - пауза, которую прерывает отмена. В отличие от time.Sleep она не держит
+// горутину до конца паузы, а таймер останавливается и не ждёт сборщика.
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Finish выполняет fn после отмены ctx, но не дольше timeout: значения ctx, например
+// трасса и идентификатор запроса, сохраняются, а его отмена - нет.
+func Finish(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	return fn(ctx)
+}
@@ -0,0 +1,356 @@
+package ctxdemo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"solid/cache"
+	"solid/concurrency/actor"
+	"solid/concurrency/batch"
+	"solid/concurrency/coalesce"
+	"solid/concurrency/fan"
+	"solid/concurrency/flow"
+	"solid/concurrency/future"
+	"solid/concurrency/pipeline"
+	"solid/concurrency/pool"
+	"solid/concurrency/pubsub"
+	"solid/concurrency/ring"
+	csync "solid/concurrency/sync"
+	"solid/design_patterns/catalog"
+	"solid/eventbus"
+	"solid/resilience/retry"
+	"solid/scheduler"
+	"solid/tasks"
+)
+
+func init() {
+	catalog.RegisterCheck(catalog.Check{Name: "ctxdemo", Summary: "every background component stops on cancel without leaking goroutines", Run: Check})
+}
+
+// cancelAfter - через сколько отменяется контекст сценария: компоненты к этому
+// времени заняты работой, которую отмена должна прервать.
+const cancelAfter = 20 * time.Millisecond
+
+// leakWait - сколько ждать выхода горутин после остановки компонента.
+const leakWait = 2 * time.Second
+
+// scenario запускает компонент с ctx и возвращает, когда тот, по своему же контракту,
+// остановился; ошибка - чем он ответил на отмену.
+type scenario struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+var quiet = log.New(io.Discard, "", 0)
+
+var errStuck = errors.New("did not stop after cancel")
+
+// Сценарий пакета из concurrency/ называется как его каталог: по именам Check
+// находит пакеты без сценария (missing).
+
+var scenarios = []scenario{
+	{"pool", func(ctx context.Context) error {
+		p := pool.New(ctx, pool.Config{Workers: 4}, func(ctx context.Context, v int) (int, error) {
+			return v, Sleep(ctx, time.Hour)
+		})
+		go func() {
+			defer p.Close()
+			for v := range Count(ctx) {
+				if p.Submit(v) != nil {
+					return
+				}
+			}
+		}()
+		return firstErr(p.Results())
+	}},
+	{"pipeline", func(ctx context.Context) error {
+		p := pipeline.New(ctx, pipeline.Hooks{})
+		src := pipeline.Generate(p, "count", func(ctx context.Context, emit func(int) bool) error {
+			for v := range Count(ctx) {
+				if !emit(v) {
+					return nil
+				}
+			}
+			return nil
+		})
+		slow := pipeline.Map(p, "slow", 4, src, func(ctx context.Context, v int) (int, error) {
+			return v, Sleep(ctx, time.Millisecond)
+		})
+		_, err := pipeline.Collect(p, slow)
+		return err
+	}},
+	{"fan", func(ctx context.Context) error {
+		for range fan.Parallel(ctx, Count(ctx), 4, func(ctx context.Context, v int) int { return v }) {
+		}
+		return ctx.Err()
+	}},
+	{"pubsub", func(ctx context.Context) error {
+		hub := pubsub.New[int](pubsub.Config{Name: "demo"})
+		defer hub.Close()
+		// Подписчик не читает: издатель встаёт на полном буфере и ждёт отмены.
+		hub.Subscribe(pubsub.SubConfig[int]{Buffer: 1, Policy: pubsub.Block})
+		for v := range Count(ctx) {
+			hub.Publish(ctx, v)
+		}
+		return ctx.Err()
+	}},
+	{"eventbus", func(ctx context.Context) error {
+		// Шина живёт дольше запроса, который публикует: её останавливает Close, а не ctx.
+		// Событие, которое издатель не дождался положить в буфер, теряется - здесь это ожидаемо.
+		bus := eventbus.New(eventbus.Config{OnError: func(eventbus.Failure) {}})
+		topic := eventbus.NewTopic[int]("ticks")
+		if _, err := eventbus.Subscribe(ctx, bus, topic, func(ctx context.Context, v int) error {
+			time.Sleep(time.Millisecond)
+			return nil
+		}, eventbus.Async(8)); err != nil {
+			return err
+		}
+		for v := range Count(ctx) {
+			if err := eventbus.Publish(ctx, bus, topic, v); err != nil {
+				break
+			}
+		}
+		bus.Close()
+		return ctx.Err()
+	}},
+	{"actor", func(ctx context.Context) error {
+		sys := actor.NewSystem(ctx, actor.Config{Logger: quiet})
+		worker := actor.Spawn(sys, "worker", actor.Props[int]{New: func() actor.Handler[int] {
+			return func(ctx context.Context, v int) error { return Sleep(ctx, time.Millisecond) }
+		}})
+		for v := range Count(ctx) {
+			if worker.Send(ctx, v) != nil {
+				break
+			}
+		}
+		// Отмена уже остановила акторов; Shutdown только дожидается их, со своим сроком.
+		return Finish(ctx, time.Second, sys.Shutdown)
+	}},
+	{"scheduler", func(ctx context.Context) error {
+		s := scheduler.New(scheduler.Config{Logger: quiet})
+		if err := s.Add(scheduler.Job{Name: "busy", Schedule: scheduler.Every(time.Millisecond), Run: func(ctx context.Context, _ scheduler.Run) error {
+			return Sleep(ctx, time.Hour)
+		}}); err != nil {
+			return err
+		}
+		return s.Run(ctx)
+	}},
+	{"tasks", func(ctx context.Context) error {
+		g := tasks.New(tasks.Config{Logger: quiet})
+		g.Add(tasks.Task{Name: "wait", Run: func(ctx context.Context) error { return Sleep(ctx, time.Hour) }})
+		g.Add(tasks.Task{Name: "after", After: []string{"wait"}, Run: func(ctx context.Context) error { return nil }})
+		_, err := g.Run(ctx)
+		return err
+	}},
+	{"batch", func(ctx context.Context) error {
+		_, err := batch.Run(ctx, batch.Config{Workers: 4}, counted(ctx), func(ctx context.Context, v int) (int, error) {
+			return v, Sleep(ctx, time.Millisecond)
+		}, func(ctx context.Context, it batch.Item[int, int]) error { return nil })
+		return err
+	}},
+	{"future", func(ctx context.Context) error {
+		f := future.Go(ctx, func(ctx context.Context) (int, error) { return 0, Sleep(ctx, time.Hour) })
+		_, err := future.All(f, future.Resolved(1)).Get(context.Background())
+		return err
+	}},
+	{"cache", func(ctx context.Context) error {
+		c := cache.New[string, int](cache.Config{})
+		// Загрузка переживает отмену ждущего (её ждут и другие), но заканчивается сама.
+		_, err := c.GetOrLoad(ctx, "k", func(ctx context.Context) (int, error) {
+			return 1, Sleep(ctx, 2*cancelAfter)
+		})
+		return err
+	}},
+	{"coalesce", func(ctx context.Context) error {
+		// Общий вызов отменяется, когда ушёл последний ждущий.
+		g := coalesce.New[string, int](coalesce.Config[string]{})
+		_, err := g.Do(ctx, "k", func(ctx context.Context) (int, error) { return 0, Sleep(ctx, time.Hour) })
+		return err
+	}},
+	{"flow", func(ctx context.Context) error {
+		// Сброс по Interval идёт без ctx издателя, поэтому здесь только сброс из Add:
+		// издатель ждёт хранилище и уходит по отмене.
+		b := flow.NewBatcher(flow.BatchConfig{Size: 4}, func(ctx context.Context, items []int) error {
+			return Sleep(ctx, time.Hour)
+		})
+		var err error
+		for v := range Count(ctx) {
+			if err = b.Add(ctx, v); err != nil {
+				break
+			}
+		}
+		return errors.Join(err, b.Close(ctx))
+	}},
+	{"ring", func(ctx context.Context) error {
+		// Читатель медленнее писателя: Push ждёт места, Pop - значения, оба до отмены.
+		r := ring.New[int](4)
+		pushed := make(chan error, 1)
+		go func() {
+			for v := range Count(ctx) {
+				if err := r.Push(ctx, v); err != nil {
+					pushed <- err
+					return
+				}
+			}
+			pushed <- ctx.Err()
+		}()
+		var err error
+		for err == nil {
+			if _, err = r.Pop(ctx); err == nil {
+				err = Sleep(ctx, time.Millisecond)
+			}
+		}
+		return errors.Join(err, <-pushed)
+	}},
+	{"sync", func(ctx context.Context) error {
+		// Один держит ключ и все места семафора, остальные ждут их до отмены.
+		var keys csync.KeyedMutex
+		sem := csync.NewSemaphore(2)
+		unlock, err := keys.LockKey(ctx, "k")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		if err := sem.Acquire(ctx, 2); err != nil {
+			return err
+		}
+		defer sem.Release(2)
+		errs := make(chan error, 8)
+		for i := range cap(errs) {
+			go func() {
+				if i%2 == 0 {
+					errs <- keys.Do(ctx, "k", func() error { return nil })
+				} else {
+					errs <- sem.Do(ctx, 1, func() error { return nil })
+				}
+			}()
+		}
+		var all []error
+		for range cap(errs) {
+			all = append(all, <-errs)
+		}
+		// Ушедшие по отмене не должны оставить за собой ключ.
+		if n := keys.Len(); n != 1 {
+			return fmt.Errorf("%d keys held after cancel, want 1", n)
+		}
+		return errors.Join(all...)
+	}},
+	{"hedge", func(ctx context.Context) error {
+		_, err := retry.Hedge(ctx, time.Millisecond, 3, func(ctx context.Context) (int, error) {
+			return 0, Sleep(ctx, time.Hour)
+		})
+		return err
+	}},
+}
+
+// Check прогоняет сценарии по очереди: отмена через cancelAfter, затем проверка, что
+// компонент ответил на неё и все горутины сценария вышли. Провалившийся сценарий не
+// останавливает остальные - ошибки собираются в одну.
+func Check(w io.Writer) error {
+	var failed []error
+	for _, s := range scenarios {
+		base := Snapshot()
+		ctx, cancel := context.WithTimeout(context.Background(), cancelAfter)
+		err := stopsOnCancel(s.run, ctx)
+		cancel()
+		leaked := base.Leaks(leakWait)
+		switch {
+		case errors.Is(err, errStuck):
+			err = fmt.Errorf("%s: %w", s.name, err)
+		case len(leaked) > 0:
+			err = fmt.Errorf("%s leaked %d goroutines: %v", s.name, len(leaked), leaked)
+		case err != nil && !errors.Is(err, context.DeadlineExceeded):
+			err = fmt.Errorf("%s answered cancel with: %w", s.name, err)
+		default:
+			fmt.Fprintf(w, "ok   %-9s stopped: %s, no leaked goroutines\n", s.name, describeErr(err))
+			continue
+		}
+		failed = append(failed, err)
+	}
+	if names := missing(); len(names) > 0 {
+		failed = append(failed, fmt.Errorf("no scenario for %v", names))
+	} else {
+		fmt.Fprintln(w, "ok   every package in concurrency/ has a scenario")
+	}
+	return errors.Join(failed...)
+}
+
+// missing - пакеты concurrency/, для которых нет сценария. Каталог ищется по пути
+// этого файла, поэтому проверка работает там, где собрана: вне исходников (скажем,
+// у собранного заранее бинарника) сравнивать не с чем, и missing ничего не находит.
+func missing() []string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return nil
+	}
+	self := filepath.Dir(file)
+	entries, err := os.ReadDir(filepath.Dir(self))
+	if err != nil {
+		return nil
+	}
+	covered := map[string]bool{filepath.Base(self): true}
+	for _, s := range scenarios {
+		covered[s.name] = true
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && !covered[e.Name()] {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// stopsOnCancel ограничивает сценарий: компонент, который не слышит отмену, валит
+// проверку, а не вешает её.
+func stopsOnCancel(run func(ctx context.Context) error, ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- run(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(leakWait):
+		return errStuck
+	}
+}
+
+// describeErr убирает из ошибки подробности, которые зависят от того, где именно
+// компонент застала отмена: важно, что она дошла.
+func describeErr(err error) string {
+	switch {
+	case err == nil:
+		return "no error"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline exceeded"
+	}
+	return err.Error()
+}
+
+func firstErr[R any](results <-chan pool.Result[int, R]) error {
+	var err error
+	for r := range results {
+		if err == nil {
+			err = r.Err
+		}
+	}
+	return err
+}
+
+// counted - Count в виде итератора для batch.
+func counted(ctx context.Context) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		for v := range Count(ctx) {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ctxdemo
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Baseline - горутины, которые жили до проверяемого кода.
+type Baseline map[int]bool
+
+// Snapshot запоминает живые горутины.
+func Snapshot() Baseline {
+	base := Baseline{}
+	for id := range goroutines() {
+		base[id] = true
+	}
+	return base
+}
+
+// Leaks ждёт до wait, пока не завершатся горутины, появившиеся после Snapshot, и
+// возвращает оставшиеся как «функция, которая её запустила [состояние]». Ждать нужно:
+// горутина, которой уже отменили контекст, выходит не мгновенно.
+func (b Baseline) Leaks(wait time.Duration) []string {
+	deadline := time.Now().Add(wait)
+	for {
+		var leaked []string
+		for id, stack := range goroutines() {
+			if !b[id] {
+				leaked = append(leaked, describe(stack))
+			}
+		}
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			slices.Sort(leaked)
+			return leaked
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// goroutines - стеки живых горутин по номеру.
+func goroutines() map[int]string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	out := map[int]string{}
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		var id int
+		if _, err := fmt.Sscanf(g, "goroutine %d ", &id); err == nil {
+			out[id] = g
+		}
+	}
+	return out
+}
+
+// describe сводит стек к тому, кто запустил горутину и на чём она стоит.
+func describe(stack string) string {
+	header, _, _ := strings.Cut(stack, "\n")
+	state := header
+	if i := strings.IndexByte(header, '['); i >= 0 {
+		state = strings.TrimSuffix(header[i:], ":")
+	}
+	creator := "unknown"
+	for _, line := range strings.Split(stack, "\n") {
+		if fn, ok := strings.CutPrefix(line, "created by "); ok {
+			creator, _, _ = strings.Cut(fn, " in goroutine")
+			break
+		}
+	}
+	return creator + " " + state
+}
@@ -0,0 +1,162 @@
+// Package fan - раздача потока между воркерами и сбор обратно, в том числе с
+// восстановлением исходного порядка.
+//
+// FanOut делит элементы между выходами (каждый достаётся одному читателю, в отличие от
+// pipeline.FanOut, который копирует его во все), FanIn сливает выходы в порядке готовности.
+// Чтобы после параллельной обработки вернуть порядок, элементы нумеруются Number, а
+// Ordered выдаёт их по номерам; Parallel собирает всё это в один вызов.
+//
+// Все функции закрывают выходы, когда закончились входы или отменён ctx.
+package fan
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Seq - элемент с номером в исходном потоке, начиная с нуля.
+type Seq[T any] struct {
+	N int
+	V T
+}
+
+// FanOut раздаёт элементы in между n выходами: элемент забирает тот, кто первым
+// готов читать, поэтому медленный читатель получает меньше.
+func FanOut[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	outs := make([]<-chan T, n)
+	for i := range outs {
+		out := make(chan T)
+		outs[i] = out
+		go func() {
+			defer close(out)
+			for {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						return
+					}
+					select {
+					case out <- v:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return outs
+}
+
+// FanIn сливает входы в один выход в порядке готовности элементов.
+func FanIn[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	for _, in := range ins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range in {
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Number нумерует элементы in по порядку поступления.
+func Number[T any](ctx context.Context, in <-chan T) <-chan Seq[T] {
+	out := make(chan Seq[T])
+	go func() {
+		defer close(out)
+		n := 0
+		for v := range in {
+			select {
+			case out <- Seq[T]{N: n, V: v}:
+				n++
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Ordered сливает пронумерованные входы и выдаёт значения строго по возрастанию
+// номеров без пропусков, начиная с нуля. Элементы, пришедшие раньше своей очереди,
+// ждут в памяти; пропущенный номер задерживает все следующие до закрытия входов,
+// после чего они выдаются по возрастанию.
+func Ordered[T any](ctx context.Context, ins ...<-chan Seq[T]) <-chan T {
+	out := make(chan T)
+	merged := FanIn(ctx, ins...)
+	go func() {
+		defer close(out)
+		pending := make(map[int]T)
+		next := 0
+		send := func(v T) bool {
+			select {
+			case out <- v:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for s := range merged {
+			pending[s.N] = s.V
+			for {
+				v, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				if !send(v) {
+					return
+				}
+			}
+		}
+		// Входы закрыты, а номера с пропусками остались - отдаём их по порядку.
+		for _, n := range slices.Sorted(maps.Keys(pending)) {
+			if !send(pending[n]) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Parallel применяет fn к элементам in в workers горутин и выдаёт результаты в
+// порядке in. Ошибки fn нужно класть в R: поток не останавливается на них.
+func Parallel[T, R any](ctx context.Context, in <-chan T, workers int, fn func(ctx context.Context, v T) R) <-chan R {
+	if workers <= 0 {
+		workers = 1
+	}
+	parts := FanOut(ctx, Number(ctx, in), workers)
+	done := make([]<-chan Seq[R], len(parts))
+	for i, part := range parts {
+		out := make(chan Seq[R])
+		done[i] = out
+		go func() {
+			defer close(out)
+			for s := range part {
+				select {
+				case out <- Seq[R]{N: s.N, V: fn(ctx, s.V)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	return Ordered(ctx, done...)
+}
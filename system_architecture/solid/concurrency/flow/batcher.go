@@ -0,0 +1,133 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"solid/concurrency/ring"
+)
+
+const DefaultBatchSize = 100
+
+var ErrClosed = errors.New("flow: batcher closed")
+
+// BatchConfig - нулевые поля заменяются значениями по умолчанию.
+type BatchConfig struct {
+	// Size - при скольких накопленных значениях пачка сбрасывается, по умолчанию
+	// DefaultBatchSize.
+	Size int
+	// Interval - сколько самое старое значение ждёт сброса; 0 - только по Size и Flush.
+	Interval time.Duration
+	// OnError получает ошибки сбросов по Interval, по умолчанию они пишутся в log.
+	OnError func(error)
+}
+
+// Batcher копит значения и отдаёт их flush пачками в порядке Add. Пачка, которую
+// flush не принял, не повторяется: повторы - дело самой flush.
+//
+// Add не берёт замков: значения копятся в ring.Ring, а читает его только тот, кто
+// держит flushing. Поэтому издатели из разных горутин не ждут друг друга, пока
+// пачка не набралась.
+type Batcher[T any] struct {
+	cfg   BatchConfig
+	flush func(ctx context.Context, items []T) error
+
+	// flushing держится на всё время сброса: пачки уходят по порядку, а у ring
+	// всегда один читатель.
+	flushing sync.Mutex
+	items    *ring.Ring[T]
+	// armed - таймер сброса по Interval заведён для ждущих значений.
+	armed  atomic.Bool
+	timer  *time.Timer
+	closed atomic.Bool
+}
+
+func NewBatcher[T any](cfg BatchConfig, flush func(ctx context.Context, items []T) error) *Batcher[T] {
+	if cfg.Size <= 0 {
+		cfg.Size = DefaultBatchSize
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(err error) { log.Print(err) }
+	}
+	// Запас вдвое: пока одна пачка сбрасывается, следующая копится без ожидания.
+	b := &Batcher[T]{cfg: cfg, flush: flush, items: ring.New[T](2 * cfg.Size)}
+	if cfg.Interval > 0 {
+		b.timer = time.AfterFunc(cfg.Interval, b.tick)
+		b.timer.Stop()
+	}
+	return b
+}
+
+// Add добавляет значение. Если пачка набралась, Add сбрасывает её сам и возвращает
+// ошибку flush: издатель, который обгоняет хранилище, ждёт его.
+func (b *Batcher[T]) Add(ctx context.Context, v T) error {
+	for !b.items.TryPush(v) {
+		if b.closed.Load() {
+			return ErrClosed
+		}
+		// Буфер полон: сброс освобождает место.
+		if err := b.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	if b.closed.Load() {
+		// Close мог сбросить накопленное раньше, чем значение легло в буфер.
+		return b.Flush(ctx)
+	}
+	if b.items.Len() >= b.cfg.Size {
+		return b.Flush(ctx)
+	}
+	if b.timer != nil && b.armed.CompareAndSwap(false, true) {
+		b.timer.Reset(b.cfg.Interval)
+	}
+	return nil
+}
+
+// Flush сбрасывает накопленное сейчас.
+func (b *Batcher[T]) Flush(ctx context.Context) error {
+	b.flushing.Lock()
+	defer b.flushing.Unlock()
+	if b.timer != nil {
+		// Сначала таймер, потом флаг: значение, добавленное после флага, заведёт
+		// таймер заново, а добавленное до него попадёт в эту пачку.
+		b.timer.Stop()
+		b.armed.Store(false)
+	}
+	n := b.items.Len()
+	if n == 0 {
+		return nil
+	}
+	// Не больше, чем было при входе: издатели, которые пишут не переставая, не
+	// растягивают сброс без конца.
+	items := b.items.Drain(make([]T, 0, n), n)
+	if len(items) == 0 {
+		return nil
+	}
+	if err := b.flush(ctx, items); err != nil {
+		return fmt.Errorf("flow: flush %d items: %w", len(items), err)
+	}
+	return nil
+}
+
+func (b *Batcher[T]) tick() {
+	if err := b.Flush(context.Background()); err != nil {
+		b.cfg.OnError(err)
+	}
+}
+
+// Len - сколько значений ждут сброса.
+func (b *Batcher[T]) Len() int {
+	return b.items.Len()
+}
+
+// Close перестаёт принимать значения и сбрасывает накопленное.
+func (b *Batcher[T]) Close(ctx context.Context) error {
+	b.closed.Store(true)
+	b.items.Close()
+	return b.Flush(ctx)
+}
@@ -0,0 +1,167 @@
+// Package flow - как часто вызывать обработчик частых событий: Debounce ждёт затишья
+// и вызывает его один раз с последним значением, Throttle - не чаще раза в интервал,
+// Batcher копит значения и отдаёт их пачкой по размеру или по времени.
+//
+// Отложенные вызовы идут из горутины таймера; обработчик одного Debouncer или
+// Throttler не вызывается одновременно сам с собой.
+package flow
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer вызывает fn с последним значением, когда d прошло без новых Call.
+type Debouncer[T any] struct {
+	d  time.Duration
+	fn func(T)
+
+	run     sync.Mutex
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending bool
+	v       T
+	stopped bool
+}
+
+func Debounce[T any](d time.Duration, fn func(T)) *Debouncer[T] {
+	return &Debouncer[T]{d: d, fn: fn}
+}
+
+// Call запоминает v и откладывает вызов ещё на d.
+func (db *Debouncer[T]) Call(v T) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.stopped {
+		return
+	}
+	db.v, db.pending = v, true
+	if db.timer == nil {
+		db.timer = time.AfterFunc(db.d, db.Flush)
+		return
+	}
+	db.timer.Reset(db.d)
+}
+
+// Flush вызывает fn сейчас, если есть отложенное значение.
+func (db *Debouncer[T]) Flush() {
+	db.run.Lock()
+	defer db.run.Unlock()
+	db.mu.Lock()
+	if !db.pending {
+		db.mu.Unlock()
+		return
+	}
+	v := db.v
+	var zero T
+	db.v, db.pending = zero, false
+	db.timer.Stop()
+	db.mu.Unlock()
+	db.fn(v)
+}
+
+// Stop отменяет отложенный вызов; следующие Call ничего не делают.
+func (db *Debouncer[T]) Stop() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.stopped, db.pending = true, false
+	if db.timer != nil {
+		db.timer.Stop()
+	}
+}
+
+// Throttler вызывает fn не чаще раза в d: первый Call - сразу, а пришедшие за
+// интервал сливаются в один вызов с последним значением в его конце.
+type Throttler[T any] struct {
+	d  time.Duration
+	fn func(T)
+
+	run     sync.Mutex
+	mu      sync.Mutex
+	timer   *time.Timer
+	window  bool
+	pending bool
+	v       T
+	stopped bool
+}
+
+func Throttle[T any](d time.Duration, fn func(T)) *Throttler[T] {
+	return &Throttler[T]{d: d, fn: fn}
+}
+
+// Call вызывает fn в вызывающей горутине, если интервал свободен, иначе откладывает
+// v до его конца, заменяя отложенное раньше.
+func (t *Throttler[T]) Call(v T) {
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+	if t.window {
+		t.v, t.pending = v, true
+		t.mu.Unlock()
+		return
+	}
+	t.open()
+	t.mu.Unlock()
+	t.call(v)
+}
+
+// open начинает интервал; вызывается под mu.
+func (t *Throttler[T]) open() {
+	t.window = true
+	if t.timer == nil {
+		t.timer = time.AfterFunc(t.d, t.tick)
+		return
+	}
+	t.timer.Reset(t.d)
+}
+
+// tick завершает интервал: отложенное значение открывает следующий.
+func (t *Throttler[T]) tick() {
+	t.mu.Lock()
+	if !t.pending || t.stopped {
+		t.window = false
+		t.mu.Unlock()
+		return
+	}
+	v := t.take()
+	t.open()
+	t.mu.Unlock()
+	t.call(v)
+}
+
+// Flush вызывает fn с отложенным значением сейчас, не дожидаясь конца интервала.
+func (t *Throttler[T]) Flush() {
+	t.mu.Lock()
+	if !t.pending {
+		t.mu.Unlock()
+		return
+	}
+	v := t.take()
+	t.mu.Unlock()
+	t.call(v)
+}
+
+// Stop отменяет отложенный вызов; следующие Call ничего не делают.
+func (t *Throttler[T]) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped, t.pending = true, false
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+func (t *Throttler[T]) take() T {
+	v := t.v
+	var zero T
+	t.v, t.pending = zero, false
+	return v
+}
+
+func (t *Throttler[T]) call(v T) {
+	t.run.Lock()
+	defer t.run.Unlock()
+	t.fn(v)
+}
@@ -0,0 +1,228 @@
+// Package future - результат вычисления, которое идёт в своей горутине: запустить
+// сразу, а дождаться потом, когда он нужен, не дольше, чем позволяет ctx ждущего.
+//
+// Future получают через Go или из Promise, когда результат поставляет чужой код.
+// Комбинаторы тоже возвращают Future и ничего не блокируют: Map и Then строят
+// следующий шаг, All ждёт всех, Any - первого успешного.
+package future
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNoFutures - Any без единого Future.
+var ErrNoFutures = errors.New("future: no futures")
+
+// Future - результат, который появится один раз и больше не изменится.
+type Future[T any] struct {
+	done  chan struct{}
+	once  sync.Once
+	value T
+	err   error
+}
+
+func newFuture[T any]() *Future[T] {
+	return &Future[T]{done: make(chan struct{})}
+}
+
+// Go запускает fn в горутине; паника fn становится ошибкой Future. Отмена ctx -
+// дело самой fn: Future её не прерывает.
+func Go[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) *Future[T] {
+	f := newFuture[T]()
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				var zero T
+				f.complete(zero, fmt.Errorf("future: panicked: %v", r))
+			}
+		}()
+		f.complete(fn(ctx))
+	}()
+	return f
+}
+
+// Resolved - уже готовый Future со значением v.
+func Resolved[T any](v T) *Future[T] {
+	f := newFuture[T]()
+	f.complete(v, nil)
+	return f
+}
+
+// Failed - уже готовый Future с ошибкой err.
+func Failed[T any](err error) *Future[T] {
+	f := newFuture[T]()
+	var zero T
+	f.complete(zero, err)
+	return f
+}
+
+// complete выставляет результат; повторные вызовы ничего не меняют.
+func (f *Future[T]) complete(v T, err error) bool {
+	ok := false
+	f.once.Do(func() {
+		f.value, f.err = v, err
+		close(f.done)
+		ok = true
+	})
+	return ok
+}
+
+// Get ждёт результат не дольше ctx и возвращает то, что вернула fn: значение
+// отдаётся и вместе с ошибкой, если fn его вернула. Истёкший ctx - ctx.Err(), но
+// вычисление продолжается, и следующий Get может его дождаться.
+func (f *Future[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	default:
+	}
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Done закрывается, когда результат готов.
+func (f *Future[T]) Done() <-chan struct{} { return f.done }
+
+// wait - результат готового или дождавшегося Future, для комбинаторов.
+func (f *Future[T]) wait() (T, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// Promise - сторона, которая выставляет результат Future. Выигрывает первый
+// Resolve или Reject, остальные возвращают false.
+type Promise[T any] struct {
+	f *Future[T]
+}
+
+func NewPromise[T any]() *Promise[T] {
+	return &Promise[T]{f: newFuture[T]()}
+}
+
+func (p *Promise[T]) Future() *Future[T] { return p.f }
+
+func (p *Promise[T]) Resolve(v T) bool { return p.f.complete(v, nil) }
+
+func (p *Promise[T]) Reject(err error) bool {
+	var zero T
+	return p.f.complete(zero, err)
+}
+
+// Map преобразует значение f, когда оно готово; ошибка f проходит мимо fn.
+func Map[T, U any](f *Future[T], fn func(T) (U, error)) *Future[U] {
+	return Then(f, func(v T) *Future[U] {
+		u, err := fn(v)
+		if err != nil {
+			return Failed[U](err)
+		}
+		return Resolved(u)
+	})
+}
+
+// Then продолжает f следующим асинхронным шагом; ошибка f проходит мимо fn.
+func Then[T, U any](f *Future[T], fn func(T) *Future[U]) *Future[U] {
+	next := newFuture[U]()
+	go func() {
+		v, err := f.wait()
+		if err != nil {
+			var zero U
+			next.complete(zero, err)
+			return
+		}
+		u, err := call(func() *Future[U] { return fn(v) }).wait()
+		next.complete(u, err)
+	}()
+	return next
+}
+
+// Recover заменяет ошибку f результатом fn, например значением по умолчанию.
+func Recover[T any](f *Future[T], fn func(error) (T, error)) *Future[T] {
+	next := newFuture[T]()
+	go func() {
+		v, err := f.wait()
+		if err == nil {
+			next.complete(v, nil)
+			return
+		}
+		next.complete(call(func() *Future[T] {
+			v, err := fn(err)
+			if err != nil {
+				return Failed[T](err)
+			}
+			return Resolved(v)
+		}).wait())
+	}()
+	return next
+}
+
+// call изолирует панику шага комбинатора.
+func call[T any](fn func() *Future[T]) (f *Future[T]) {
+	defer func() {
+		if r := recover(); r != nil {
+			f = Failed[T](fmt.Errorf("future: panicked: %v", r))
+		}
+	}()
+	return fn()
+}
+
+// All - значения всех fs в их порядке. На первой ошибке All завершается сразу с
+// ней, не дожидаясь остальных; отменить их - дело того ctx, с которым они запущены.
+func All[T any](fs ...*Future[T]) *Future[[]T] {
+	all := newFuture[[]T]()
+	values := make([]T, len(fs))
+	var wg sync.WaitGroup
+	wg.Add(len(fs))
+	for i, f := range fs {
+		go func() {
+			defer wg.Done()
+			v, err := f.wait()
+			if err != nil {
+				all.complete(nil, err)
+				return
+			}
+			values[i] = v
+		}()
+	}
+	go func() {
+		wg.Wait()
+		all.complete(values, nil)
+	}()
+	return all
+}
+
+// Any - первое успешное значение из fs. Если ошиблись все, ошибка объединяет их
+// ошибки в порядке fs.
+func Any[T any](fs ...*Future[T]) *Future[T] {
+	if len(fs) == 0 {
+		return Failed[T](ErrNoFutures)
+	}
+	first := newFuture[T]()
+	errs := make([]error, len(fs))
+	var wg sync.WaitGroup
+	wg.Add(len(fs))
+	for i, f := range fs {
+		go func() {
+			defer wg.Done()
+			v, err := f.wait()
+			if err == nil {
+				first.complete(v, nil)
+				return
+			}
+			errs[i] = err
+		}()
+	}
+	go func() {
+		wg.Wait()
+		var zero T
+		first.complete(zero, errors.Join(errs...))
+	}()
+	return first
+}
@@ -0,0 +1,239 @@
+// Package pipeline собирает конвейер из типизированных стадий, связанных каналами:
+// источник (Generate, Slice), преобразование (Map), размножение потока (FanOut),
+// слияние (Merge) и сбор результата (Collect).
+//
+// Каждая стадия работает в своих горутинах и закрывает выход, когда закончился вход.
+// Первая ошибка любой стадии отменяет контекст конвейера: стадии выше по потоку
+// перестают отдавать элементы, ниже - дочитывают закрытые входы, и Wait возвращает
+// эту ошибку.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Skip, возвращённый функцией Map, отбрасывает элемент без ошибки конвейера.
+var Skip = errors.New("pipeline: skip")
+
+// Hooks - наблюдатели за стадиями, например для метрик; любое поле может быть nil.
+type Hooks struct {
+	// Item вызывается после каждого элемента Map: сколько он занял и чем закончился,
+	// включая Skip.
+	Item func(stage string, took time.Duration, err error)
+	// Done вызывается, когда стадия закрыла выход: сколько элементов она отдала.
+	Done func(stage string, items int)
+}
+
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	hooks  Hooks
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+func New(ctx context.Context, hooks Hooks) *Pipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Pipeline{ctx: ctx, cancel: cancel, hooks: hooks}
+}
+
+// Context отменяется при первой ошибке стадии или отмене родительского контекста.
+func (p *Pipeline) Context() context.Context { return p.ctx }
+
+// Wait дожидается всех стадий и возвращает первую ошибку; без ошибок стадий -
+// ошибку родительского контекста, если его отменили.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.mu.Lock()
+	err := p.err
+	p.mu.Unlock()
+	if err == nil {
+		err = p.ctx.Err()
+	}
+	p.cancel()
+	return err
+}
+
+func (p *Pipeline) fail(stage string, err error) {
+	p.mu.Lock()
+	if p.err == nil {
+		p.err = fmt.Errorf("pipeline: stage %s: %w", stage, err)
+	}
+	p.mu.Unlock()
+	p.cancel()
+}
+
+// stage запускает workers горутин run с номерами от нуля и закрывает out, когда все
+// они вернулись.
+func stage[T any](p *Pipeline, name string, workers int, run func(worker int, emit func(T) bool) error) <-chan T {
+	out := make(chan T)
+	var mu sync.Mutex
+	items := 0
+	emit := func(v T) bool {
+		select {
+		case out <- v:
+			mu.Lock()
+			items++
+			mu.Unlock()
+			return true
+		case <-p.ctx.Done():
+			return false
+		}
+	}
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			defer wg.Done()
+			if err := run(i, emit); err != nil {
+				p.fail(name, err)
+			}
+		}()
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		wg.Wait()
+		close(out)
+		if p.hooks.Done != nil {
+			p.hooks.Done(name, items)
+		}
+	}()
+	return out
+}
+
+// Generate - источник: gen отдаёт элементы через emit, пока тот возвращает true.
+// false значит, что конвейер остановлен и продолжать не нужно.
+func Generate[T any](p *Pipeline, name string, gen func(ctx context.Context, emit func(T) bool) error) <-chan T {
+	return stage(p, name, 1, func(_ int, emit func(T) bool) error {
+		return gen(p.ctx, emit)
+	})
+}
+
+// Slice - источник из готового списка.
+func Slice[T any](p *Pipeline, name string, items []T) <-chan T {
+	return Generate(p, name, func(_ context.Context, emit func(T) bool) error {
+		for _, v := range items {
+			if !emit(v) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// Map преобразует элементы in в workers горутин (не меньше одной); при workers > 1
+// порядок не сохраняется. Ошибка fn, кроме Skip, останавливает конвейер.
+func Map[T, R any](p *Pipeline, name string, workers int, in <-chan T, fn func(ctx context.Context, v T) (R, error)) <-chan R {
+	return stage(p, name, max(workers, 1), func(_ int, emit func(R) bool) error {
+		for {
+			var v T
+			var ok bool
+			select {
+			case v, ok = <-in:
+			case <-p.ctx.Done():
+				return nil
+			}
+			if !ok {
+				return nil
+			}
+			start := time.Now()
+			r, err := fn(p.ctx, v)
+			if p.hooks.Item != nil {
+				p.hooks.Item(name, time.Since(start), err)
+			}
+			switch {
+			case errors.Is(err, Skip):
+				continue
+			case err != nil:
+				return err
+			}
+			if !emit(r) {
+				return nil
+			}
+		}
+	})
+}
+
+// FanOut отдаёт каждый элемент in во все n выходов; медленный потребитель
+// притормаживает остальных, поэтому каждый выход нужно читать.
+func FanOut[T any](p *Pipeline, name string, in <-chan T, n int) []<-chan T {
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		items := 0
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+			if p.hooks.Done != nil {
+				p.hooks.Done(name, items)
+			}
+		}()
+		for {
+			var v T
+			var ok bool
+			select {
+			case v, ok = <-in:
+			case <-p.ctx.Done():
+				return
+			}
+			if !ok {
+				return
+			}
+			for _, out := range outs {
+				select {
+				case out <- v:
+				case <-p.ctx.Done():
+					return
+				}
+			}
+			items++
+		}
+	}()
+	return result
+}
+
+// Merge сливает входы в один выход в порядке поступления; без входов выход сразу закрыт.
+func Merge[T any](p *Pipeline, name string, ins ...<-chan T) <-chan T {
+	return stage(p, name, len(ins), func(i int, emit func(T) bool) error {
+		in := ins[i]
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return nil
+				}
+				if !emit(v) {
+					return nil
+				}
+			case <-p.ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+// Collect дочитывает in в список и дожидается конвейера. При ошибке список содержит
+// то, что успело дойти.
+func Collect[T any](p *Pipeline, in <-chan T) ([]T, error) {
+	var items []T
+	for v := range in {
+		items = append(items, v)
+	}
+	return items, p.Wait()
+}
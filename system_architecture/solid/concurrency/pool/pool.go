@@ -0,0 +1,205 @@
+// Package pool - пул воркеров с ограниченным числом горутин: задачи подаются через
+// Submit, результаты приходят в канал Results, по желанию - в порядке подачи.
+//
+// На каждую принятую задачу приходит ровно один результат: выполненная, упавшая
+// с паникой или не начатая из-за отмены контекста пула. Close перестаёт принимать
+// задачи и дорабатывает очередь, после чего Results закрывается.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+const DefaultWorkers = 4
+
+var ErrClosed = errors.New("pool: closed")
+
+// PanicError - результат задачи, которая запаниковала; воркер при этом продолжает работу.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("pool: task panicked: %v", e.Value) }
+
+// Config - размеры пула; нулевые поля заменяются значениями по умолчанию.
+type Config struct {
+	// Workers - сколько задач выполняются одновременно, по умолчанию DefaultWorkers.
+	Workers int
+	// Queue - сколько задач ждут воркера или выдачи результата, по умолчанию Workers.
+	// Сверх Workers+Queue задач в работе Submit ждёт.
+	Queue int
+	// Ordered выдаёт результаты в порядке подачи: готовый раньше ждёт предыдущих.
+	Ordered bool
+}
+
+type Func[T, R any] func(ctx context.Context, v T) (R, error)
+
+// Result - итог задачи; Index - её номер в порядке подачи, с нуля.
+type Result[T, R any] struct {
+	Index int
+	Input T
+	Value R
+	Err   error
+}
+
+type task[T any] struct {
+	index int
+	v     T
+}
+
+type Pool[T, R any] struct {
+	ctx     context.Context
+	fn      Func[T, R]
+	ordered bool
+	// window - места для задач от Submit до выдачи результата; при Ordered
+	// ограничивает и число результатов, ждущих отстающую задачу.
+	window chan struct{}
+	tasks  chan task[T]
+	done   chan Result[T, R]
+	out    chan Result[T, R]
+
+	mu     sync.Mutex
+	next   int
+	closed bool
+}
+
+// New запускает воркеры. Отмена ctx останавливает пул: задачи, до которых не дошла
+// очередь, приходят с ошибкой ctx.Err() невыполненными. Results нужно читать до
+// закрытия, иначе воркеры встанут.
+func New[T, R any](ctx context.Context, cfg Config, fn Func[T, R]) *Pool[T, R] {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+	if cfg.Queue <= 0 {
+		cfg.Queue = cfg.Workers
+	}
+	size := cfg.Workers + cfg.Queue
+	p := &Pool[T, R]{
+		ctx: ctx, fn: fn, ordered: cfg.Ordered,
+		window: make(chan struct{}, size),
+		// Места в tasks не меньше, чем в window, поэтому отправка после захвата места не ждёт.
+		tasks: make(chan task[T], size),
+		done:  make(chan Result[T, R]),
+		out:   make(chan Result[T, R]),
+	}
+	var wg sync.WaitGroup
+	for range cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range p.tasks {
+				p.done <- p.run(t)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(p.done)
+	}()
+	go p.collect()
+	return p
+}
+
+// Submit ставит задачу в очередь и ждёт места, если пул загружен. После Close
+// возвращает ErrClosed, после отмены контекста пула - ctx.Err().
+func (p *Pool[T, R]) Submit(v T) error {
+	if err := p.ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case p.window <- struct{}{}:
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		<-p.window
+		return ErrClosed
+	}
+	p.tasks <- task[T]{index: p.next, v: v}
+	p.next++
+	return nil
+}
+
+// Close перестаёт принимать задачи; принятые дорабатываются. Повторный вызов ничего не делает.
+func (p *Pool[T, R]) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.closed {
+		p.closed = true
+		close(p.tasks)
+	}
+}
+
+// Results закрывается, когда после Close выданы результаты всех принятых задач.
+func (p *Pool[T, R]) Results() <-chan Result[T, R] { return p.out }
+
+func (p *Pool[T, R]) run(t task[T]) (r Result[T, R]) {
+	r = Result[T, R]{Index: t.index, Input: t.v}
+	if err := p.ctx.Err(); err != nil {
+		r.Err = err
+		return r
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			r.Err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	r.Value, r.Err = p.fn(p.ctx, t.v)
+	return r
+}
+
+func (p *Pool[T, R]) collect() {
+	defer close(p.out)
+	pending := make(map[int]Result[T, R])
+	next := 0
+	for r := range p.done {
+		if !p.ordered {
+			p.out <- r
+			<-p.window
+			continue
+		}
+		pending[r.Index] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			p.out <- r
+			<-p.window
+		}
+	}
+}
+
+// Map прогоняет in через fn и возвращает результаты в порядке in. При отмене ctx
+// неподанные элементы получают ошибку ctx.Err().
+func Map[T, R any](ctx context.Context, cfg Config, in []T, fn Func[T, R]) []Result[T, R] {
+	p := New(ctx, cfg, fn)
+	go func() {
+		defer p.Close()
+		for _, v := range in {
+			if p.Submit(v) != nil {
+				return
+			}
+		}
+	}()
+	results := make([]Result[T, R], len(in))
+	got := make([]bool, len(in))
+	for r := range p.Results() {
+		results[r.Index], got[r.Index] = r, true
+	}
+	for i, ok := range got {
+		if !ok {
+			results[i] = Result[T, R]{Index: i, Input: in[i], Err: ctx.Err()}
+		}
+	}
+	return results
+}
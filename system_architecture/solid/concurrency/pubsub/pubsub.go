@@ -0,0 +1,318 @@
+// Package pubsub - публикация в памяти с отдельным буфером у каждого подписчика.
+// Что делать, когда буфер полон, выбирает сам подписчик (Policy): издателя можно
+// притормозить, а можно пожертвовать старыми или новыми сообщениями либо отключить
+// отстающего, чтобы медленный потребитель не держал остальных.
+//
+// Отставание (сколько сообщений ждёт в буфере) и потери уходят в Hooks, например в
+// метрики.
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const DefaultBuffer = 16
+
+var (
+	ErrClosed = errors.New("pubsub: closed")
+	// ErrOverflow - сообщение не принято или вытеснено, потому что буфер подписчика полон.
+	ErrOverflow = errors.New("pubsub: buffer full")
+	// ErrDisconnected - подписчик с политикой Disconnect отстал и отключён.
+	ErrDisconnected = errors.New("pubsub: slow subscriber disconnected")
+)
+
+// Policy - поведение при полном буфере подписчика.
+type Policy int
+
+const (
+	// Block - издатель ждёт места не дольше своего ctx, затем сообщение теряется.
+	Block Policy = iota
+	// DropOldest вытесняет самое старое сообщение буфера: подписчик видит свежие.
+	DropOldest
+	// DropNew отбрасывает новое сообщение: подписчик дочитывает то, что уже принял.
+	DropNew
+	// Disconnect отключает подписчика: его канал закрывается после уже принятых сообщений.
+	Disconnect
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropOldest:
+		return "drop_oldest"
+	case DropNew:
+		return "drop_new"
+	case Disconnect:
+		return "disconnect"
+	}
+	return fmt.Sprintf("policy(%d)", int(p))
+}
+
+// Hooks - наблюдатели хаба; любое поле может быть nil. Вызываются из Publish.
+type Hooks struct {
+	// Lag - заполненность буфера подписчика сразу после доставки ему сообщения.
+	Lag func(hub, sub string, lag int)
+	// Dropped - сообщение для подписчика потеряно по его политике.
+	Dropped func(hub, sub string, p Policy)
+	// Disconnected - подписчик отключён за отставание.
+	Disconnected func(hub, sub string)
+}
+
+type Config struct {
+	// Name - имя хаба в Hooks.
+	Name  string
+	Hooks Hooks
+}
+
+// SubConfig - настройки подписчика; нулевые поля - значения по умолчанию.
+type SubConfig[T any] struct {
+	// Name - имя подписчика в Hooks; пустое - "sub-<номер>".
+	Name string
+	// Buffer - ёмкость буфера, по умолчанию DefaultBuffer.
+	Buffer int
+	Policy Policy
+	// OnDrop получает каждое потерянное сообщение и причину; вызывается из Publish.
+	OnDrop func(v T, err error)
+}
+
+type Hub[T any] struct {
+	cfg Config
+
+	mu     sync.RWMutex
+	subs   []*Subscriber[T]
+	n      int
+	closed bool
+}
+
+func New[T any](cfg Config) *Hub[T] {
+	return &Hub[T]{cfg: cfg}
+}
+
+// Subscribe добавляет подписчика; после Close хаба его канал сразу закрыт.
+func (h *Hub[T]) Subscribe(cfg SubConfig[T]) *Subscriber[T] {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = DefaultBuffer
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.n++
+	if cfg.Name == "" {
+		cfg.Name = fmt.Sprintf("sub-%d", h.n)
+	}
+	s := &Subscriber[T]{hub: h, cfg: cfg, ch: make(chan T, cfg.Buffer), quit: make(chan struct{})}
+	if h.closed {
+		s.shut(ErrClosed)
+		return s
+	}
+	h.subs = append(h.subs, s)
+	return s
+}
+
+// Publish доставляет v всем подписчикам по их политикам. Ошибка - только ErrClosed:
+// потери отдельных подписчиков уходят в их OnDrop и Hooks.
+func (h *Hub[T]) Publish(ctx context.Context, v T) error {
+	h.mu.RLock()
+	if h.closed {
+		h.mu.RUnlock()
+		return ErrClosed
+	}
+	subs := h.subs
+	h.mu.RUnlock()
+	for _, s := range subs {
+		s.Deliver(ctx, v)
+	}
+	return nil
+}
+
+// Len - сколько подписчиков подключено.
+func (h *Hub[T]) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+// Stats - снимок по подписчикам в порядке подписки.
+func (h *Hub[T]) Stats() []Stats {
+	h.mu.RLock()
+	subs := h.subs
+	h.mu.RUnlock()
+	stats := make([]Stats, len(subs))
+	for i, s := range subs {
+		stats[i] = s.Stats()
+	}
+	return stats
+}
+
+// Close отключает всех подписчиков; их каналы закрываются после уже принятых сообщений.
+func (h *Hub[T]) Close() {
+	h.mu.Lock()
+	subs := h.subs
+	h.subs, h.closed = nil, true
+	h.mu.Unlock()
+	for _, s := range subs {
+		s.shut(ErrClosed)
+	}
+}
+
+func (h *Hub[T]) remove(s *Subscriber[T]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, other := range h.subs {
+		if other == s {
+			// Новый срез, чтобы не задеть копию, по которой идёт Publish.
+			h.subs = append(h.subs[:i:i], h.subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Stats - состояние подписчика для метрик.
+type Stats struct {
+	Name      string
+	Policy    Policy
+	Lag       int
+	Delivered int64
+	Dropped   int64
+}
+
+type Subscriber[T any] struct {
+	hub  *Hub[T]
+	cfg  SubConfig[T]
+	ch   chan T
+	quit chan struct{}
+
+	// mu упорядочивает запись в канал, вытеснение и закрытие. Издатели, ждущие места
+	// по Block, держат не замок, а senders: канал закрывается, когда они ушли.
+	mu        sync.Mutex
+	senders   sync.WaitGroup
+	closed    bool
+	err       error
+	delivered int64
+	dropped   int64
+}
+
+// C - канал сообщений; закрывается после Close, отключения или закрытия хаба.
+func (s *Subscriber[T]) C() <-chan T { return s.ch }
+
+func (s *Subscriber[T]) Name() string { return s.cfg.Name }
+
+// Err - почему канал закрыт: ErrDisconnected, ErrClosed для закрытого хаба, nil после Close.
+func (s *Subscriber[T]) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *Subscriber[T]) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{Name: s.cfg.Name, Policy: s.cfg.Policy, Lag: len(s.ch), Delivered: s.delivered, Dropped: s.dropped}
+}
+
+// Close отписывает; уже принятые сообщения остаются в канале до прочтения.
+func (s *Subscriber[T]) Close() {
+	s.hub.remove(s)
+	s.shut(nil)
+}
+
+// Deliver доставляет v только этому подписчику по его политике, например чтобы
+// догнать его пропущенными сообщениями до живых. false - сообщение потеряно.
+func (s *Subscriber[T]) Deliver(ctx context.Context, v T) bool {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return false
+	}
+	select {
+	case s.ch <- v:
+		return s.accepted()
+	default:
+	}
+	switch s.cfg.Policy {
+	case DropOldest:
+		var evicted T
+		dropped := false
+		select {
+		case evicted = <-s.ch:
+			dropped = true
+		default:
+			// Подписчик успел освободить место сам: терять нечего.
+		}
+		s.ch <- v
+		s.accepted()
+		if dropped {
+			s.drop(evicted, fmt.Errorf("%w: evicted by a newer message", ErrOverflow))
+		}
+		return true
+	case DropNew:
+		s.mu.Unlock()
+		s.drop(v, ErrOverflow)
+		return false
+	case Disconnect:
+		s.mu.Unlock()
+		s.hub.remove(s)
+		first := s.shut(ErrDisconnected)
+		s.drop(v, ErrDisconnected)
+		if h := s.hub.cfg.Hooks.Disconnected; h != nil && first {
+			h(s.hub.cfg.Name, s.cfg.Name)
+		}
+		return false
+	}
+	// Block: ждём без замка, чтобы Close не вставал за издателем.
+	s.senders.Add(1)
+	s.mu.Unlock()
+	defer s.senders.Done()
+	select {
+	case <-s.quit:
+		return false
+	case <-ctx.Done():
+		s.drop(v, fmt.Errorf("%w: %w", ErrOverflow, ctx.Err()))
+		return false
+	case s.ch <- v:
+		s.mu.Lock()
+		return s.accepted()
+	}
+}
+
+// accepted учитывает доставку и отпускает замок.
+func (s *Subscriber[T]) accepted() bool {
+	s.delivered++
+	lag := len(s.ch)
+	s.mu.Unlock()
+	if h := s.hub.cfg.Hooks.Lag; h != nil {
+		h(s.hub.cfg.Name, s.cfg.Name, lag)
+	}
+	return true
+}
+
+func (s *Subscriber[T]) drop(v T, err error) {
+	s.mu.Lock()
+	s.dropped++
+	s.mu.Unlock()
+	if h := s.hub.cfg.Hooks.Dropped; h != nil {
+		h(s.hub.cfg.Name, s.cfg.Name, s.cfg.Policy)
+	}
+	if s.cfg.OnDrop != nil {
+		s.cfg.OnDrop(v, err)
+	}
+}
+
+// shut закрывает канал, дождавшись издателей, которые ждут в нём места: после
+// закрытия quit они уходят, не отправив. false - канал уже закрыт раньше.
+func (s *Subscriber[T]) shut(err error) bool {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return false
+	}
+	s.closed, s.err = true, err
+	close(s.quit)
+	s.mu.Unlock()
+	s.senders.Wait()
+	close(s.ch)
+	return true
+}
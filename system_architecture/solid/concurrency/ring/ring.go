@@ -0,0 +1,219 @@
+// Package ring - кольцевой буфер фиксированного размера для многих писателей и
+// одного читателя (MPSC) без замков: писатели занимают ячейку сравнением с обменом
+// номера, у каждой ячейки свой счётчик поколений, поэтому читатель видит значение
+// только после того, как писатель его дописал.
+//
+// Читать должна одна горутина за раз - Pop и TryPop не защищены друг от друга.
+// Полный буфер не растёт: TryPush возвращает false, Push ждёт места. Close ставит
+// флаг в тот же счётчик, которым писатели занимают ячейки, поэтому каждое значение,
+// для которого TryPush вернул true, читатель получит и после Close.
+package ring
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+var ErrClosed = errors.New("ring: closed")
+
+// pad разносит счётчики писателей и читателя по разным строкам кэша.
+type pad [56]byte
+
+// closedBit - старший бит head: буфер закрыт, занимать ячейки больше нельзя.
+const closedBit = 1 << 63
+
+type slot[T any] struct {
+	// seq - позиция, которую ячейка ждёт: равна номеру записи - свободна, на
+	// единицу больше - записана и ждёт читателя.
+	seq atomic.Uint64
+	v   T
+}
+
+type Ring[T any] struct {
+	mask  uint64
+	slots []slot[T]
+
+	_ pad
+	// head - номер следующей записи и closedBit.
+	head atomic.Uint64
+	_    pad
+	tail atomic.Uint64
+	_    pad
+
+	// waiting - читатель спит на wake; писатель, заставший флаг, будит его.
+	waiting atomic.Bool
+	wake    chan struct{}
+}
+
+// New - буфер не меньше чем на size значений; размер округляется вверх до степени
+// двойки, но не меньше 2.
+func New[T any](size int) *Ring[T] {
+	n := uint64(2)
+	for n < uint64(size) {
+		n <<= 1
+	}
+	r := &Ring[T]{mask: n - 1, slots: make([]slot[T], n), wake: make(chan struct{}, 1)}
+	for i := range r.slots {
+		r.slots[i].seq.Store(uint64(i))
+	}
+	return r
+}
+
+// Cap - сколько значений помещается в буфер.
+func (r *Ring[T]) Cap() int { return len(r.slots) }
+
+// Len - сколько значений ждут читателя; при работающих писателях - приблизительно.
+func (r *Ring[T]) Len() int {
+	// tail раньше head: иначе читатель между загрузками сделает разность отрицательной.
+	tail := r.tail.Load()
+	return int(r.head.Load()&^closedBit - tail)
+}
+
+func (r *Ring[T]) closed() bool { return r.head.Load()&closedBit != 0 }
+
+// TryPush кладёт v, если есть место; false - буфер полон или закрыт.
+func (r *Ring[T]) TryPush(v T) bool {
+	for {
+		pos := r.head.Load()
+		if pos&closedBit != 0 {
+			return false
+		}
+		s := &r.slots[pos&r.mask]
+		switch diff := int64(s.seq.Load()) - int64(pos); {
+		case diff == 0:
+			if r.head.CompareAndSwap(pos, pos+1) {
+				s.v = v
+				s.seq.Store(pos + 1)
+				r.notify()
+				return true
+			}
+		case diff < 0:
+			// Ячейка ещё не прочитана с прошлого круга.
+			return false
+		}
+		// Другой писатель успел занять pos или буфер закрыли - пробуем снова.
+	}
+}
+
+// Push кладёт v, дожидаясь места не дольше ctx. Места ждут опросом с нарастающей
+// паузой: размер буфера стоит выбирать так, чтобы он почти не заполнялся.
+func (r *Ring[T]) Push(ctx context.Context, v T) error {
+	for spin := 0; ; spin++ {
+		if r.closed() {
+			return ErrClosed
+		}
+		if r.TryPush(v) {
+			return nil
+		}
+		if err := backoff(ctx, spin); err != nil {
+			return err
+		}
+	}
+}
+
+// TryPop забирает самое старое значение; false - буфер пуст.
+func (r *Ring[T]) TryPop() (T, bool) {
+	var zero T
+	pos := r.tail.Load()
+	s := &r.slots[pos&r.mask]
+	if s.seq.Load() != pos+1 {
+		return zero, false
+	}
+	v := s.v
+	s.v = zero
+	// Ячейка свободна для записи на следующем круге.
+	s.seq.Store(pos + r.mask + 1)
+	r.tail.Store(pos + 1)
+	return v, true
+}
+
+// Pop ждёт значение не дольше ctx. После Close отдаёт оставшееся, в том числе то,
+// что писатели заняли до Close и ещё дописывают, затем ErrClosed.
+func (r *Ring[T]) Pop(ctx context.Context) (T, error) {
+	for {
+		if v, ok := r.TryPop(); ok {
+			return v, nil
+		}
+		r.waiting.Store(true)
+		// Повторная проверка после флага: писатель, положивший значение до него,
+		// мог не увидеть, что читателя надо будить.
+		if v, ok := r.TryPop(); ok {
+			r.waiting.Store(false)
+			return v, nil
+		}
+		if head := r.head.Load(); head&closedBit != 0 && head&^closedBit == r.tail.Load() {
+			r.waiting.Store(false)
+			var zero T
+			return zero, ErrClosed
+		}
+		// Закрыт, но занятая ячейка ещё не дописана: её писатель разбудит читателя.
+		select {
+		case <-r.wake:
+		case <-ctx.Done():
+			r.waiting.Store(false)
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Drain забирает всё, что есть сейчас, максимум max значений (0 - без предела), и
+// дописывает их в buf.
+func (r *Ring[T]) Drain(buf []T, max int) []T {
+	for n := 0; max <= 0 || n < max; n++ {
+		v, ok := r.TryPop()
+		if !ok {
+			break
+		}
+		buf = append(buf, v)
+	}
+	return buf
+}
+
+// Close перестаёт принимать значения; уже положенные читатель дочитывает.
+func (r *Ring[T]) Close() {
+	for {
+		head := r.head.Load()
+		if head&closedBit != 0 || r.head.CompareAndSwap(head, head|closedBit) {
+			break
+		}
+	}
+	r.waiting.Store(false)
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Ring[T]) notify() {
+	if r.waiting.Load() && r.waiting.CompareAndSwap(true, false) {
+		select {
+		case r.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// backoff - пауза перед повтором: сначала уступить процессор, потом спать, удваивая
+// паузу до миллисекунды.
+func backoff(ctx context.Context, spin int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if spin < 16 {
+		runtime.Gosched()
+		return nil
+	}
+	d := time.Microsecond << min(spin-16, 10)
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,155 @@
+// Package ringbench сравнивает ring.Ring с очередью на срезе под мьютексом и с
+// буферизованным каналом: несколько писателей, один читатель. Замеры собраны в
+// демонстрацию patterns; цифры зависят от машины, поэтому вывод с эталоном не
+// сверяется. Правильность ring - доставку ровно один раз в порядке писателя,
+// полный и пустой буфер, круги по ячейкам - проверяет Check:
+//
+//	go run ./cmd/patterns run ring
+//	go run ./cmd/patterns check -race ring
+package ringbench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+
+	"solid/concurrency/ring"
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "ring", Summary: "lock-free MPSC ring against a mutex-guarded slice and a channel", Run: Demo, Unstable: true})
+	catalog.RegisterCheck(catalog.Check{Name: "ring", Summary: "ring and flow.Batcher deliver every value once, in producer order", Run: Check})
+}
+
+// size - вместимость ring и канала; у среза её нет.
+const size = 1024
+
+// queue - общий вид испытуемых: push из многих горутин, pop - из одной.
+type queue interface {
+	push(ctx context.Context, v int)
+	// pop ждёт значение; false - очередь закрыта и пуста.
+	pop(ctx context.Context) (int, bool)
+}
+
+type ringQueue struct{ r *ring.Ring[int] }
+
+func (q ringQueue) push(ctx context.Context, v int) { q.r.Push(ctx, v) }
+func (q ringQueue) pop(ctx context.Context) (int, bool) {
+	v, err := q.r.Pop(ctx)
+	return v, err == nil
+}
+
+type chanQueue chan int
+
+func (q chanQueue) push(ctx context.Context, v int) { q <- v }
+func (q chanQueue) pop(ctx context.Context) (int, bool) {
+	v, ok := <-q
+	return v, ok
+}
+
+// sliceQueue - срез под мьютексом; читатель забирает его целиком, а ждёт на sync.Cond.
+type sliceQueue struct {
+	mu    sync.Mutex
+	ready *sync.Cond
+	items []int
+	taken []int
+}
+
+func newSliceQueue() *sliceQueue {
+	q := &sliceQueue{}
+	q.ready = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *sliceQueue) push(ctx context.Context, v int) {
+	q.mu.Lock()
+	q.items = append(q.items, v)
+	q.mu.Unlock()
+	q.ready.Signal()
+}
+
+func (q *sliceQueue) pop(ctx context.Context) (int, bool) {
+	if len(q.taken) == 0 {
+		q.mu.Lock()
+		for len(q.items) == 0 {
+			q.ready.Wait()
+		}
+		q.items, q.taken = q.taken[:0], q.items
+		q.mu.Unlock()
+	}
+	v := q.taken[0]
+	q.taken = q.taken[1:]
+	return v, true
+}
+
+// values - сколько значений проходит через очередь за один замер.
+const values = 1 << 18
+
+// bench - время на одно значение, когда values значений пишут producers горутин, а
+// читает одна.
+func bench(producers int, mk func() queue) time.Duration {
+	q := mk()
+	ctx := context.Background()
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		defer close(done)
+		for range values {
+			if _, ok := q.pop(ctx); !ok {
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	for p := range producers {
+		n := values / producers
+		if p == 0 {
+			n += values % producers
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range n {
+				q.push(ctx, i)
+			}
+		}()
+	}
+	wg.Wait()
+	<-done
+	return time.Since(start) / values
+}
+
+// rounds - замеров на ячейку таблицы: лучший отсекает паузы сборщика и соседей.
+const rounds = 3
+
+var queues = []struct {
+	name string
+	mk   func() queue
+}{
+	{"ring", func() queue { return ringQueue{ring.New[int](size)} }},
+	{"mutex slice", func() queue { return newSliceQueue() }},
+	{"channel", func() queue { return make(chanQueue, size) }},
+}
+
+// Demo печатает время на одно значение для каждой очереди при 1, 4 и 16 писателях;
+// у каждой очереди лучший из rounds замеров.
+func Demo(w io.Writer) error {
+	fmt.Fprintf(w, "GOMAXPROCS=%d, ns per value, one consumer\n", runtime.GOMAXPROCS(0))
+	fmt.Fprintf(w, "%-12s %10s %10s %10s\n", "queue", "x1", "x4", "x16")
+	for _, q := range queues {
+		fmt.Fprintf(w, "%-12s", q.name)
+		for _, producers := range []int{1, 4, 16} {
+			best := bench(producers, q.mk)
+			for range rounds - 1 {
+				best = min(best, bench(producers, q.mk))
+			}
+			fmt.Fprintf(w, " %10d", best.Nanoseconds())
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package ringbench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"solid/concurrency/flow"
+	"solid/concurrency/ring"
+)
+
+// Check проверяет ring.Ring без замеров: пустой и полный буфер, много кругов по
+// ячейкам, Close, в том числе посреди записи, а под нагрузкой многих писателей - что каждое
+// значение дошло ровно один раз и в порядке своего писателя. То же проверяется для
+// flow.Batcher, который копит значения в ring. Под patterns check -race детектор
+// гонок заодно проверяет, что значение в ячейке опубликовано без гонки.
+func Check(w io.Writer) error {
+	for _, c := range []struct {
+		name string
+		run  func() error
+	}{
+		{"empty and full", checkBounds},
+		{"wraparound", checkWraparound},
+		{"close", checkClose},
+		{"close while pushing", checkCloseRace},
+		{"producers", func() error { return checkProducers(ringQueue{ring.New[int](64)}, 8, 20000) }},
+		{"batcher", checkBatcher},
+	} {
+		if err := c.run(); err != nil {
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
+		fmt.Fprintf(w, "ok   %s\n", c.name)
+	}
+	return nil
+}
+
+func checkBounds() error {
+	r := ring.New[int](3)
+	if r.Cap() != 4 {
+		return fmt.Errorf("New(3).Cap() = %d, want 4", r.Cap())
+	}
+	if _, ok := r.TryPop(); ok || r.Len() != 0 {
+		return fmt.Errorf("new ring: TryPop succeeded or Len %d", r.Len())
+	}
+	for i := range r.Cap() {
+		if !r.TryPush(i) {
+			return fmt.Errorf("TryPush %d of %d failed", i+1, r.Cap())
+		}
+	}
+	if r.TryPush(99) || r.Len() != r.Cap() {
+		return fmt.Errorf("full ring: TryPush succeeded or Len %d", r.Len())
+	}
+	// Push на полном буфере ждёт места до срока.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.Push(ctx, 99); !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("Push on a full ring: got %v, want %v", err, context.DeadlineExceeded)
+	}
+	for i := range r.Cap() {
+		if v, ok := r.TryPop(); !ok || v != i {
+			return fmt.Errorf("TryPop %d: got %d, %v", i, v, ok)
+		}
+	}
+	if _, ok := r.TryPop(); ok || r.Len() != 0 {
+		return fmt.Errorf("drained ring: TryPop succeeded or Len %d", r.Len())
+	}
+	return nil
+}
+
+// checkWraparound проходит по ячейкам много кругов, каждый раз заполняя буфер на
+// разную глубину: счётчики поколений должны пускать писателя только в прочитанную
+// ячейку, а читателя - только в записанную.
+func checkWraparound() error {
+	r := ring.New[int](4)
+	next, want := 0, 0
+	for lap := range 100 * r.Cap() {
+		depth := lap%r.Cap() + 1
+		for range depth {
+			if !r.TryPush(next) {
+				return fmt.Errorf("lap %d: TryPush %d failed at Len %d", lap, next, r.Len())
+			}
+			next++
+		}
+		if r.Len() != depth {
+			return fmt.Errorf("lap %d: Len %d, want %d", lap, r.Len(), depth)
+		}
+		for _, v := range r.Drain(nil, 0) {
+			if v != want {
+				return fmt.Errorf("lap %d: got %d, want %d", lap, v, want)
+			}
+			want++
+		}
+	}
+	if want != next {
+		return fmt.Errorf("read %d values, pushed %d", want, next)
+	}
+	return nil
+}
+
+func checkClose() error {
+	r := ring.New[int](4)
+	r.TryPush(1)
+	r.TryPush(2)
+	r.Close()
+	if r.TryPush(3) {
+		return fmt.Errorf("TryPush after Close succeeded")
+	}
+	ctx := context.Background()
+	if err := r.Push(ctx, 3); !errors.Is(err, ring.ErrClosed) {
+		return fmt.Errorf("Push after Close: got %v, want %v", err, ring.ErrClosed)
+	}
+	for _, want := range []int{1, 2} {
+		if v, err := r.Pop(ctx); err != nil || v != want {
+			return fmt.Errorf("Pop after Close: got %d, %v, want %d", v, err, want)
+		}
+	}
+	if _, err := r.Pop(ctx); !errors.Is(err, ring.ErrClosed) {
+		return fmt.Errorf("Pop on a closed empty ring: got %v, want %v", err, ring.ErrClosed)
+	}
+	return nil
+}
+
+// checkCloseRace закрывает буфер посреди записи: всё, что Push принял, читатель
+// должен получить до ErrClosed, даже если писатель дописывал ячейку уже после Close.
+func checkCloseRace() error {
+	const rounds, producers = 200, 4
+	ctx := context.Background()
+	for round := range rounds {
+		r := ring.New[int](4)
+		var accepted atomic.Int64
+		var wg sync.WaitGroup
+		for range producers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; r.Push(ctx, i) == nil; i++ {
+					accepted.Add(1)
+				}
+			}()
+		}
+		read := make(chan int64)
+		go func() {
+			var n int64
+			for {
+				if _, err := r.Pop(ctx); err != nil {
+					read <- n
+					return
+				}
+				n++
+			}
+		}()
+		// Разная задержка перед Close - разные места, где она застаёт писателей.
+		for range round % 8 {
+			runtime.Gosched()
+		}
+		r.Close()
+		wg.Wait()
+		if got, want := <-read, accepted.Load(); got != want {
+			return fmt.Errorf("round %d: read %d values, Push accepted %d", round, got, want)
+		}
+	}
+	return nil
+}
+
+// seq - значение писателя p с номером i, по которому читатель проверяет порядок.
+func seq(p, i int) int { return p<<32 | i }
+
+// checkProducers пишет n значений из каждого из producers писателей в маленький
+// буфер, чтобы писатели ждали места, а читатель - значений.
+func checkProducers(q queue, producers, n int) error {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for p := range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range n {
+				q.push(ctx, seq(p, i))
+			}
+		}()
+	}
+	got := make([]int, 0, producers*n)
+	for range producers * n {
+		v, ok := q.pop(ctx)
+		if !ok {
+			return fmt.Errorf("closed after %d values", len(got))
+		}
+		got = append(got, v)
+	}
+	wg.Wait()
+	return inProducerOrder(got, producers, n)
+}
+
+// inProducerOrder - каждое значение каждого писателя ровно один раз и по порядку.
+func inProducerOrder(got []int, producers, n int) error {
+	next := make([]int, producers)
+	for _, v := range got {
+		p, i := v>>32, v&(1<<32-1)
+		if p >= producers || i != next[p] {
+			return fmt.Errorf("producer %d: got value %d, want %d (lost, duplicated or reordered)", p, i, next[p])
+		}
+		next[p]++
+	}
+	for p, i := range next {
+		if i != n {
+			return fmt.Errorf("producer %d: delivered %d of %d", p, i, n)
+		}
+	}
+	return nil
+}
+
+// checkBatcher - те же писатели через flow.Batcher со сбросом и по размеру, и по
+// таймеру: пачки не больше буфера, значения - ровно один раз и в порядке писателя.
+func checkBatcher() error {
+	const producers, n, size = 8, 5000, 16
+	var (
+		mu      sync.Mutex
+		got     []int
+		biggest atomic.Int64
+	)
+	b := flow.NewBatcher(flow.BatchConfig{Size: size, Interval: time.Millisecond, OnError: func(error) {}},
+		func(ctx context.Context, items []int) error {
+			mu.Lock()
+			got = append(got, items...)
+			mu.Unlock()
+			if int64(len(items)) > biggest.Load() {
+				biggest.Store(int64(len(items)))
+			}
+			return nil
+		})
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make(chan error, producers)
+	for p := range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range n {
+				if err := b.Add(ctx, seq(p, i)); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	if err := errors.Join(<-errs, b.Close(ctx)); err != nil {
+		return err
+	}
+	if !errors.Is(b.Add(ctx, 0), flow.ErrClosed) {
+		return fmt.Errorf("Add after Close accepted a value")
+	}
+	// Пачка не может быть больше ring внутри Batcher - двух Size.
+	if biggest.Load() > 2*size {
+		return fmt.Errorf("flushed a batch of %d, want at most %d", biggest.Load(), 2*size)
+	}
+	return inProducerOrder(got, producers, n)
+}
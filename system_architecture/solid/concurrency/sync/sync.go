@@ -0,0 +1,182 @@
+// Package sync - примитивы синхронизации поверх стандартного sync: взвешенный
+// семафор и мьютекс по ключу, оба с ожиданием по контексту и неблокирующими
+// Try-вариантами.
+//
+// Имя совпадает со стандартным пакетом, поэтому импортируется под псевдонимом:
+//
+//	csync "solid/concurrency/sync"
+package sync
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ErrTooHeavy - запрошенный вес больше ёмкости семафора, и дождаться его нельзя.
+var ErrTooHeavy = errors.New("sync: weight exceeds semaphore size")
+
+// Semaphore ограничивает суммарный вес одновременных операций: тяжёлая задача
+// занимает несколько мест сразу. Ожидающие обслуживаются по очереди, поэтому
+// крупный запрос не голодает за потоком мелких.
+type Semaphore struct {
+	size int64
+	sem  *semaphore.Weighted
+
+	mu   sync.Mutex
+	held int64
+}
+
+// NewSemaphore создаёт семафор на size мест, не меньше одного.
+func NewSemaphore(size int64) *Semaphore {
+	size = max(size, 1)
+	return &Semaphore{size: size, sem: semaphore.NewWeighted(size)}
+}
+
+// Acquire ждёт, пока освободится w мест, или отмены ctx - тогда возвращает ctx.Err().
+func (s *Semaphore) Acquire(ctx context.Context, w int64) error {
+	if w > s.size {
+		return ErrTooHeavy
+	}
+	if err := s.sem.Acquire(ctx, w); err != nil {
+		return err
+	}
+	s.add(w)
+	return nil
+}
+
+// TryAcquire занимает w мест, только если они свободны прямо сейчас.
+func (s *Semaphore) TryAcquire(w int64) bool {
+	if !s.sem.TryAcquire(w) {
+		return false
+	}
+	s.add(w)
+	return true
+}
+
+// Release возвращает w мест; отпустить больше, чем занято, - паника.
+func (s *Semaphore) Release(w int64) {
+	s.add(-w)
+	s.sem.Release(w)
+}
+
+// Do выполняет fn, заняв w мест на время вызова.
+func (s *Semaphore) Do(ctx context.Context, w int64, fn func() error) error {
+	if err := s.Acquire(ctx, w); err != nil {
+		return err
+	}
+	defer s.Release(w)
+	return fn()
+}
+
+func (s *Semaphore) Size() int64 { return s.size }
+
+// Held - сколько мест занято сейчас; для метрик.
+func (s *Semaphore) Held() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.held
+}
+
+func (s *Semaphore) add(w int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.held+w < 0 {
+		panic("sync: semaphore released more than held")
+	}
+	s.held += w
+}
+
+// KeyedMutex - мьютекс на каждый ключ: операции с одним ключом идут по очереди,
+// с разными - параллельно. Запись о ключе живёт, пока его держат или ждут, так что
+// память не растёт вместе с числом когда-либо встреченных ключей. Нулевое значение
+// готово к работе.
+type KeyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock - канал на одно место вместо sync.Mutex, чтобы ждать его можно было вместе с ctx.
+type keyLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+func NewKeyedMutex() *KeyedMutex { return &KeyedMutex{} }
+
+// LockKey ждёт ключ key или отмены ctx. unlock отпускает ключ; повторный вызов
+// ничего не делает.
+func (m *KeyedMutex) LockKey(ctx context.Context, key string) (unlock func(), err error) {
+	l := m.ref(key)
+	select {
+	case l.ch <- struct{}{}:
+		return m.unlocker(key, l), nil
+	case <-ctx.Done():
+		m.unref(key, l)
+		return nil, ctx.Err()
+	}
+}
+
+// TryLockKey захватывает key, только если он свободен; иначе ok == false.
+func (m *KeyedMutex) TryLockKey(key string) (unlock func(), ok bool) {
+	l := m.ref(key)
+	select {
+	case l.ch <- struct{}{}:
+		return m.unlocker(key, l), true
+	default:
+		m.unref(key, l)
+		return nil, false
+	}
+}
+
+// Do выполняет fn, держа ключ key.
+func (m *KeyedMutex) Do(ctx context.Context, key string, fn func() error) error {
+	unlock, err := m.LockKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// Len - сколько ключей сейчас держат или ждут.
+func (m *KeyedMutex) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.locks)
+}
+
+func (m *KeyedMutex) ref(key string) *keyLock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.locks == nil {
+		m.locks = make(map[string]*keyLock)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyLock{ch: make(chan struct{}, 1)}
+		m.locks[key] = l
+	}
+	l.refs++
+	return l
+}
+
+func (m *KeyedMutex) unref(key string, l *keyLock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l.refs--; l.refs == 0 {
+		delete(m.locks, key)
+	}
+}
+
+func (m *KeyedMutex) unlocker(key string, l *keyLock) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-l.ch
+			m.unref(key, l)
+		})
+	}
+}
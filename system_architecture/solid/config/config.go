@@ -0,0 +1,324 @@
+// Package config собирает настройки сервиса в структуру из нескольких источников.
+// Каждый следующий перекрывает предыдущий:
+//
+//	значения по умолчанию (тег default) < файлы JSON/YAML < переменные окружения < флаги
+//
+// Поля связываются тегами: config - ключ в файле (по умолчанию имя поля в snake_case,
+// вложенные структуры - секции), env и flag - имена переменной и флага, если они
+// отличаются от выведенных из ключа, usage - подсказка флага, validate - правила
+// required, min=, max= и oneof=. Флаг перекрывает остальное, только если задан явно,
+// поэтому значение из файла не затирается значением флага по умолчанию.
+//
+// Loader помнит последнюю удачную загрузку и умеет перечитать источники: Watch следит
+// за файлами, а подписчики OnChange узнают о новой конфигурации. Неудачная перезагрузка
+// (битый файл, нарушенное правило) оставляет прежние настройки.
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalid - настройки не прошли проверку validate или метода Validate.
+var ErrInvalid = errors.New("config: invalid")
+
+// Validator - структура настроек с собственной проверкой, например связей между полями.
+// Validate вызывается после правил из тегов.
+type Validator interface {
+	Validate() error
+}
+
+type Options struct {
+	// Files - файлы настроек по порядку: более поздний перекрывает ранний. Формат
+	// выбирается по расширению: .json, .yaml или .yml.
+	Files []string
+	// FileFlag - имя флага со списком файлов через запятую, которые читаются после Files;
+	// пустое - такого флага нет.
+	FileFlag string
+	// EnvPrefix - префикс переменных окружения: с "GATEWAY_" поле rate читается из
+	// GATEWAY_RATE. Пустой - окружение не читается, кроме полей с явным тегом env.
+	EnvPrefix string
+	// OnError получает ошибки перезагрузки в Watch; по умолчанию они пишутся в log.
+	OnError func(error)
+}
+
+// Loader - источники настроек типа T и последняя удачно загруженная конфигурация.
+type Loader[T any] struct {
+	opts   Options
+	fs     *flag.FlagSet
+	fields []field
+	flags  map[string]*flagValue
+	files  *string
+
+	mu      sync.Mutex
+	current T
+	loaded  bool
+	subs    []func(old, new T)
+}
+
+// Bind заводит в fs по флагу на каждое поле T (и FileFlag, если задан). Разберите fs
+// как обычно и вызовите Load.
+func Bind[T any](fs *flag.FlagSet, opts Options) (*Loader[T], error) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: %s is not a struct", t)
+	}
+	fields, err := fieldsOf(t, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	l := &Loader[T]{opts: opts, fs: fs, fields: fields, flags: map[string]*flagValue{}}
+	for i := range fields {
+		f := &fields[i]
+		if f.flag == "" {
+			continue
+		}
+		v := &flagValue{def: f.def, typ: f.typ}
+		fs.Var(v, f.flag, f.usage)
+		l.flags[f.flag] = v
+	}
+	if opts.FileFlag != "" {
+		l.files = fs.String(opts.FileFlag, "", "comma-separated JSON or YAML config files")
+	}
+	return l, nil
+}
+
+// Load читает все источники, проверяет результат и запоминает его как текущий.
+func (l *Loader[T]) Load() (T, error) {
+	cfg, err := l.read()
+	if err != nil {
+		return cfg, err
+	}
+	l.mu.Lock()
+	l.current, l.loaded = cfg, true
+	l.mu.Unlock()
+	return cfg, nil
+}
+
+// Current - последняя удачно загруженная конфигурация.
+func (l *Loader[T]) Current() T {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.current
+}
+
+// OnChange подписывает fn на перезагрузки, изменившие настройки. Подписчики вызываются
+// по порядку подписки, в горутине Reload.
+func (l *Loader[T]) OnChange(fn func(old, new T)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subs = append(l.subs, fn)
+}
+
+// Reload перечитывает источники и, если настройки изменились, сообщает подписчикам.
+// При ошибке текущая конфигурация не меняется.
+func (l *Loader[T]) Reload() error {
+	cfg, err := l.read()
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	old, changed := l.current, !l.loaded || !reflect.DeepEqual(l.current, cfg)
+	l.current, l.loaded = cfg, true
+	subs := l.subs
+	l.mu.Unlock()
+	if changed {
+		for _, fn := range subs {
+			fn(old, cfg)
+		}
+	}
+	return nil
+}
+
+// Files - файлы, из которых читаются настройки: Files и затем файлы из FileFlag.
+func (l *Loader[T]) Files() []string {
+	files := append([]string(nil), l.opts.Files...)
+	if l.files != nil {
+		for _, name := range strings.Split(*l.files, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				files = append(files, name)
+			}
+		}
+	}
+	return files
+}
+
+// Watch раз в interval проверяет время изменения и размер файлов и при отличии
+// вызывает Reload. Блокируется до отмены ctx, даже если следить не за чем.
+func (l *Loader[T]) Watch(ctx context.Context, interval time.Duration) {
+	files := l.Files()
+	if len(files) == 0 {
+		<-ctx.Done()
+		return
+	}
+	last := stamp(files)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := stamp(files)
+		if now == last {
+			continue
+		}
+		last = now
+		if err := l.Reload(); err != nil {
+			l.report(err)
+		}
+	}
+}
+
+// stamp - отпечаток состояния файлов; пропавший файл тоже считается изменением.
+func stamp(files []string) string {
+	var b strings.Builder
+	for _, name := range files {
+		fi, err := os.Stat(name)
+		if err != nil {
+			b.WriteString("-;")
+			continue
+		}
+		fmt.Fprintf(&b, "%d:%d;", fi.ModTime().UnixNano(), fi.Size())
+	}
+	return b.String()
+}
+
+func (l *Loader[T]) report(err error) {
+	if l.opts.OnError != nil {
+		l.opts.OnError(err)
+		return
+	}
+	log.Print(err)
+}
+
+// read собирает настройки из всех источников по старшинству.
+func (l *Loader[T]) read() (T, error) {
+	var cfg T
+	v := reflect.ValueOf(&cfg).Elem()
+	for _, f := range l.fields {
+		if f.def == "" {
+			continue
+		}
+		if err := setText(v.FieldByIndex(f.index), f.def); err != nil {
+			return cfg, fmt.Errorf("config: default %s: %w", f.key, err)
+		}
+	}
+	for _, name := range l.Files() {
+		values, err := readFile(name)
+		if err != nil {
+			return cfg, fmt.Errorf("config: %w", err)
+		}
+		if err := l.apply(v, values, name); err != nil {
+			return cfg, err
+		}
+	}
+	for _, f := range l.fields {
+		name := f.env
+		if name == "" && l.opts.EnvPrefix != "" {
+			name = l.opts.EnvPrefix + envName(f.key)
+		}
+		if name == "" {
+			continue
+		}
+		if s, ok := os.LookupEnv(name); ok {
+			if err := setText(v.FieldByIndex(f.index), s); err != nil {
+				return cfg, fmt.Errorf("config: %s: %w", name, err)
+			}
+		}
+	}
+	var errs []error
+	l.fs.Visit(func(fl *flag.Flag) {
+		for _, f := range l.fields {
+			if f.flag == fl.Name {
+				if err := setText(v.FieldByIndex(f.index), l.flags[f.flag].text); err != nil {
+					errs = append(errs, fmt.Errorf("config: -%s: %w", f.flag, err))
+				}
+			}
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return cfg, err
+	}
+	if err := validate(v, l.fields); err != nil {
+		return cfg, err
+	}
+	if val, ok := any(&cfg).(Validator); ok {
+		if err := val.Validate(); err != nil {
+			return cfg, fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+	}
+	return cfg, nil
+}
+
+// apply переносит значения одного файла в поля. Неизвестный ключ - ошибка, чтобы
+// опечатка в файле не превращалась молча в значение по умолчанию.
+func (l *Loader[T]) apply(v reflect.Value, values map[string]any, file string) error {
+	known := map[string]field{}
+	for _, f := range l.fields {
+		known[f.key] = f
+	}
+	var errs []error
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for k, raw := range m {
+			key := prefix + normalizeKey(k)
+			if f, ok := known[key]; ok {
+				if err := setAny(v.FieldByIndex(f.index), raw); err != nil {
+					errs = append(errs, fmt.Errorf("config: %s: %s: %w", file, key, err))
+				}
+				continue
+			}
+			if section, ok := raw.(map[string]any); ok && l.hasSection(key) {
+				walk(key+".", section)
+				continue
+			}
+			errs = append(errs, fmt.Errorf("config: %s: unknown key %q", file, key))
+		}
+	}
+	walk("", values)
+	return errors.Join(errs...)
+}
+
+func (l *Loader[T]) hasSection(key string) bool {
+	for _, f := range l.fields {
+		if strings.HasPrefix(f.key, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// flagValue копит текст флага; в поле он попадает в read, после файлов и окружения.
+// Set разбирает текст сразу, чтобы ошибку показал сам FlagSet вместе с подсказкой.
+type flagValue struct {
+	def  string
+	text string
+	typ  reflect.Type
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.def
+}
+
+func (v *flagValue) Set(s string) error {
+	if err := setText(reflect.New(v.typ).Elem(), s); err != nil {
+		return err
+	}
+	v.text = s
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool { return v.typ.Kind() == reflect.Bool }
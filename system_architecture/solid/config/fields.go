@@ -0,0 +1,260 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// field - лист структуры настроек со всеми его именами.
+type field struct {
+	index    []int
+	typ      reflect.Type
+	key      string
+	env      string
+	flag     string
+	def      string
+	usage    string
+	validate string
+}
+
+var (
+	durationType = reflect.TypeFor[time.Duration]()
+	textType     = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// fieldsOf обходит структуру; вложенная структура без UnmarshalText становится секцией.
+func fieldsOf(t reflect.Type, index []int, prefix string) ([]field, error) {
+	var out []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		key := sf.Tag.Get("config")
+		if !sf.IsExported() || key == "-" {
+			continue
+		}
+		if key == "" {
+			key = snake(sf.Name)
+		}
+		key = prefix + normalizeKey(key)
+		idx := append(append([]int(nil), index...), i)
+		if sf.Type.Kind() == reflect.Struct && !reflect.PointerTo(sf.Type).Implements(textType) {
+			nested, err := fieldsOf(sf.Type, idx, key+".")
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, nested...)
+			continue
+		}
+		if !supported(sf.Type) {
+			return nil, fmt.Errorf("config: %s: unsupported type %s", key, sf.Type)
+		}
+		f := field{
+			index:    idx,
+			typ:      sf.Type,
+			key:      key,
+			env:      sf.Tag.Get("env"),
+			flag:     sf.Tag.Get("flag"),
+			def:      sf.Tag.Get("default"),
+			usage:    sf.Tag.Get("usage"),
+			validate: sf.Tag.Get("validate"),
+		}
+		switch f.flag {
+		case "":
+			f.flag = strings.NewReplacer(".", "-", "_", "-").Replace(key)
+		case "-":
+			f.flag = ""
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func supported(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		return supported(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// snake переводит имя поля в ключ: HedgeAfter -> hedge_after, HTTPAddr -> http_addr.
+func snake(name string) string {
+	r := []rune(name)
+	var b strings.Builder
+	for i, c := range r {
+		if i > 0 && unicode.IsUpper(c) &&
+			(!unicode.IsUpper(r[i-1]) || i+1 < len(r) && unicode.IsLower(r[i+1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
+
+// normalizeKey уравнивает написания ключа: max-inflight и Max_Inflight - один ключ.
+func normalizeKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(key), "-", "_")
+}
+
+// envName - имя переменной для ключа без префикса: redis.addr -> REDIS_ADDR.
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// readFile разбирает файл в дерево значений. Скаляры из JSON и YAML дальше проходят
+// через тот же текст, что и флаги: длительность пишется как "30s", а не числом.
+func readFile(name string) (map[string]any, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		err = dec.Decode(&values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &values)
+	default:
+		return nil, fmt.Errorf("%s: unknown config format %q, want .json, .yaml or .yml", name, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return values, nil
+}
+
+// setAny присваивает значение из файла: списки и объекты поэлементно, остальное - через текст.
+func setAny(v reflect.Value, raw any) error {
+	switch raw := raw.(type) {
+	case []any:
+		if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Errorf("unexpected list for %s", v.Type())
+		}
+		s := reflect.MakeSlice(v.Type(), len(raw), len(raw))
+		for i, item := range raw {
+			if err := setAny(s.Index(i), item); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		v.Set(s)
+		return nil
+	case map[string]any:
+		if v.Kind() != reflect.Map {
+			return fmt.Errorf("unexpected object for %s", v.Type())
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(raw))
+		for k, item := range raw {
+			m.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), reflect.ValueOf(scalar(item)).Convert(v.Type().Elem()))
+		}
+		v.Set(m)
+		return nil
+	case nil:
+		v.SetZero()
+		return nil
+	}
+	return setText(v, scalar(raw))
+}
+
+func scalar(raw any) string {
+	switch raw := raw.(type) {
+	case string:
+		return raw
+	case float64:
+		return strconv.FormatFloat(raw, 'f', -1, 64)
+	}
+	return fmt.Sprint(raw)
+}
+
+// setText разбирает текстовое значение - из тега default, окружения или флага. Списки
+// пишутся через запятую, словари - парами key=value через запятую.
+func setText(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		var items []string
+		if s != "" {
+			items = strings.Split(s, ",")
+		}
+		out := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setText(out.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		v.Set(out)
+	case reflect.Map:
+		out := reflect.MakeMap(v.Type())
+		for _, pair := range strings.Split(s, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			k, val, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return fmt.Errorf("invalid pair %q, want key=value", pair)
+			}
+			out.SetMapIndex(reflect.ValueOf(strings.TrimSpace(k)).Convert(v.Type().Key()),
+				reflect.ValueOf(strings.TrimSpace(val)).Convert(v.Type().Elem()))
+		}
+		v.Set(out)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// validate проверяет правила тегов validate через запятую:
+//
+//	required     значение не нулевое
+//	min=N max=N  для чисел и длительностей - само значение, для строк, списков и словарей - длина
+//	oneof=a b c  значение из перечисленных
+//
+// Ошибки всех полей собираются вместе, чтобы за один запуск увидеть все.
+func validate(v reflect.Value, fields []field) error {
+	var errs []error
+	for _, f := range fields {
+		if f.validate == "" {
+			continue
+		}
+		fv := v.FieldByIndex(f.index)
+		for _, rule := range strings.Split(f.validate, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			if err := check(fv, name, arg); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s: %w", ErrInvalid, f.key, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func check(v reflect.Value, rule, arg string) error {
+	switch rule {
+	case "required":
+		if v.IsZero() {
+			return errors.New("is required")
+		}
+	case "min", "max":
+		c, err := compare(v, arg)
+		if err != nil {
+			return fmt.Errorf("rule %s=%s: %w", rule, arg, err)
+		}
+		if rule == "min" && c < 0 {
+			return fmt.Errorf("must be at least %s", arg)
+		}
+		if rule == "max" && c > 0 {
+			return fmt.Errorf("must be at most %s", arg)
+		}
+	case "oneof":
+		allowed := strings.Fields(arg)
+		if got := fmt.Sprint(v.Interface()); !slices.Contains(allowed, got) {
+			return fmt.Errorf("%q is not one of %s", got, strings.Join(allowed, ", "))
+		}
+	default:
+		return fmt.Errorf("unknown rule %q", rule)
+	}
+	return nil
+}
+
+// compare сравнивает значение (или длину) с границей, разобранной в тот же тип.
+func compare(v reflect.Value, arg string) (int, error) {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return 0, err
+		}
+		return v.Len() - n, nil
+	}
+	bound := reflect.New(v.Type()).Elem()
+	if err := setText(bound, arg); err != nil {
+		return 0, err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(v.Int(), bound.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(v.Uint(), bound.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(v.Float(), bound.Float()), nil
+	}
+	return 0, fmt.Errorf("not comparable: %s", v.Type())
+}
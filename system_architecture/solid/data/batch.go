@@ -0,0 +1,181 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrNoKey = errors.New("data: no key for value")
+
+const (
+	DefaultChunkSize   = 100
+	DefaultConcurrency = 4
+)
+
+// Keyer - значение, которое само знает свой ключ хранения.
+type Keyer interface {
+	Key() string
+}
+
+// WithKeyFunc задаёт вычисление ключа для SaveAll и SaveStream; без неё используется Keyer.
+func WithKeyFunc(fn func(v any) string) Option {
+	return func(o *options) {
+		o.keyFunc = fn
+	}
+}
+
+// WithBatch задаёт размер чанка и число параллельных сохранений внутри чанка.
+func WithBatch(chunkSize, concurrency int) Option {
+	return func(o *options) {
+		o.chunkSize = chunkSize
+		o.concurrency = concurrency
+	}
+}
+
+// ItemResult - итог сохранения одного элемента пакета.
+type ItemResult struct {
+	Index int
+	Key   string
+	Err   error
+}
+
+// BatchResult - итог пакетного сохранения; Items упорядочены по индексу во входных данных.
+type BatchResult struct {
+	Items  []ItemResult
+	Saved  int
+	Failed int
+}
+
+func (r BatchResult) Failures() []ItemResult {
+	var failed []ItemResult
+	for _, it := range r.Items {
+		if it.Err != nil {
+			failed = append(failed, it)
+		}
+	}
+	return failed
+}
+
+func (dm *DataManager[T]) keyOf(v T) (string, error) {
+	if dm.opts.keyFunc != nil {
+		if key := dm.opts.keyFunc(v); key != "" {
+			return key, nil
+		}
+	} else if k, ok := any(v).(Keyer); ok && k.Key() != "" {
+		return k.Key(), nil
+	}
+	return "", ErrNoKey
+}
+
+func (dm *DataManager[T]) saveItem(ctx context.Context, index int, v T) ItemResult {
+	key, err := dm.keyOf(v)
+	if err != nil {
+		return ItemResult{Index: index, Err: fmt.Errorf("item %d: %w", index, err)}
+	}
+	return ItemResult{Index: index, Key: key, Err: dm.SaveData(ctx, key, v)}
+}
+
+func (dm *DataManager[T]) concurrency() int {
+	if dm.opts.concurrency > 0 {
+		return dm.opts.concurrency
+	}
+	return DefaultConcurrency
+}
+
+// SaveAll сохраняет значения чанками, внутри чанка - не более concurrency параллельно.
+// Ошибка отдельного элемента попадает в его ItemResult и не прерывает пакет;
+// возвращаемая ошибка непуста только при отмене контекста.
+func (dm *DataManager[T]) SaveAll(ctx context.Context, values []T) (BatchResult, error) {
+	chunk := dm.opts.chunkSize
+	if chunk <= 0 {
+		chunk = DefaultChunkSize
+	}
+	res := BatchResult{Items: make([]ItemResult, len(values))}
+	for start := 0; start < len(values); start += chunk {
+		if err := ctx.Err(); err != nil {
+			for i := start; i < len(values); i++ {
+				res.Items[i] = ItemResult{Index: i, Err: err}
+			}
+			res.count()
+			return res, err
+		}
+		end := min(start+chunk, len(values))
+		sem := make(chan struct{}, dm.concurrency())
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(i int) {
+				defer func() { <-sem; wg.Done() }()
+				res.Items[i] = dm.saveItem(ctx, i, values[i])
+			}(i)
+		}
+		wg.Wait()
+	}
+	res.count()
+	// Отмена во время последнего чанка видна только в ItemResult - здесь её тоже вернуть.
+	return res, ctx.Err()
+}
+
+func (r *BatchResult) count() {
+	r.Saved, r.Failed = 0, 0
+	for _, it := range r.Items {
+		if it.Err != nil {
+			r.Failed++
+		} else {
+			r.Saved++
+		}
+	}
+}
+
+// SaveStream читает значения из in и сохраняет их не более чем concurrency параллельно.
+// Новое значение забирается из in, только когда освободился воркер и прочитан предыдущий
+// результат, поэтому медленное хранилище или потребитель притормаживают производителя.
+// Канал результатов закрывается после закрытия in или отмены контекста.
+func (dm *DataManager[T]) SaveStream(ctx context.Context, in <-chan T) <-chan ItemResult {
+	out := make(chan ItemResult)
+	type job struct {
+		index int
+		v     T
+	}
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for w := 0; w < dm.concurrency(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				select {
+				case out <- dm.saveItem(ctx, j.index, j.v):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Wait()
+			close(out)
+		}()
+		for i := 0; ; i++ {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case jobs <- job{index: i, v: v}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,77 @@
+package data
+
+import (
+	"context"
+	"errors"
+
+	"solid/resilience/breaker"
+)
+
+// Выключатель живёт в resilience/breaker; здесь - прежние имена для кода слоя данных.
+type (
+	Breaker       = breaker.Breaker
+	BreakerConfig = breaker.Config
+	BreakerState  = breaker.State
+	BreakerStats  = breaker.Stats
+)
+
+const (
+	StateClosed   = breaker.StateClosed
+	StateOpen     = breaker.StateOpen
+	StateHalfOpen = breaker.StateHalfOpen
+)
+
+// NewBreaker - выключатель для хранилища: ErrNotFound, ErrConflict и ValidationError
+// означают, что хранилище ответило, и сбоем не считаются.
+func NewBreaker(cfg BreakerConfig) *Breaker {
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = isStorageFailure
+	}
+	return breaker.New(cfg)
+}
+
+func isStorageFailure(err error) bool {
+	var ve *ValidationError
+	return err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrConflict) && !errors.As(err, &ve)
+}
+
+// breakerStorage направляет вызовы через Breaker; пока цепь разомкнута,
+// запросы уходят в fallback, а если его нет - завершаются ErrCircuitOpen.
+type breakerStorage struct {
+	primary  Storage
+	breaker  *Breaker
+	fallback Storage
+}
+
+func (s breakerStorage) Save(ctx context.Context, key, data string) error {
+	err := s.breaker.Do(func() error { return s.primary.Save(ctx, key, data) })
+	if errors.Is(err, breaker.ErrOpen) && s.fallback != nil {
+		return s.fallback.Save(ctx, key, data)
+	}
+	return circuitErr(err)
+}
+
+func (s breakerStorage) Load(ctx context.Context, key string) (string, error) {
+	data, err := breaker.Do(s.breaker, func() (string, error) { return s.primary.Load(ctx, key) })
+	if errors.Is(err, breaker.ErrOpen) && s.fallback != nil {
+		return s.fallback.Load(ctx, key)
+	}
+	return data, circuitErr(err)
+}
+
+func (s breakerStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := breaker.Do(s.breaker, func() ([]string, error) { return s.primary.List(ctx, prefix) })
+	if errors.Is(err, breaker.ErrOpen) && s.fallback != nil {
+		return s.fallback.List(ctx, prefix)
+	}
+	return keys, circuitErr(err)
+}
+
+// circuitErr переводит breaker.ErrOpen в ErrCircuitOpen, чтобы отказ выключателя
+// ветвился как ErrUnavailable вместе с остальными ошибками слоя данных.
+func circuitErr(err error) error {
+	if errors.Is(err, breaker.ErrOpen) {
+		return ErrCircuitOpen
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"solid/resilience/bulkhead"
+)
+
+// ErrBulkheadFull - частный случай ErrUnavailable: хранилище не вызывалось, потому что
+// все места переборки заняты медленными вызовами.
+var ErrBulkheadFull = fmt.Errorf("%w (bulkhead full)", ErrUnavailable)
+
+// WithBulkhead ограничивает число одновременных обращений к хранилищу. Переборка стоит
+// снаружи выключателя: отказ по переполнению не считается сбоем хранилища.
+func WithBulkhead(b *bulkhead.Bulkhead) Option {
+	return func(o *options) {
+		o.bulkhead = b
+	}
+}
+
+type bulkheadStorage struct {
+	Storage
+	bulkhead *bulkhead.Bulkhead
+}
+
+func (s bulkheadStorage) Save(ctx context.Context, key, data string) error {
+	return bulkheadErr(s.bulkhead.Do(ctx, func() error { return s.Storage.Save(ctx, key, data) }))
+}
+
+func (s bulkheadStorage) Load(ctx context.Context, key string) (string, error) {
+	data, err := bulkhead.Do(ctx, s.bulkhead, func() (string, error) { return s.Storage.Load(ctx, key) })
+	return data, bulkheadErr(err)
+}
+
+func (s bulkheadStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := bulkhead.Do(ctx, s.bulkhead, func() ([]string, error) { return s.Storage.List(ctx, prefix) })
+	return keys, bulkheadErr(err)
+}
+
+func bulkheadErr(err error) error {
+	if errors.Is(err, bulkhead.ErrFull) || errors.Is(err, bulkhead.ErrTimeout) {
+		return fmt.Errorf("%w: %v", ErrBulkheadFull, err)
+	}
+	return err
+}
@@ -0,0 +1,127 @@
+package data
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec превращает Go-значение в байты и обратно. Name сохраняется вместе с данными,
+// чтобы при чтении выбрать тот же кодек.
+type Codec interface {
+	Name() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	ErrUnknownCodec          = errors.New("data: unknown codec")
+	ErrInvalidEnvelope       = errors.New("data: payload has no codec header")
+	ErrNotProtoMessage       = errors.New("data: value is not a proto.Message")
+	codecs                   = map[string]Codec{}
+	_                  Codec = JSONCodec{}
+)
+
+func init() {
+	for _, c := range []Codec{JSONCodec{}, GobCodec{}, ProtobufCodec{}, MsgpackCodec{}} {
+		RegisterCodec(c)
+	}
+}
+
+// RegisterCodec добавляет кодек в реестр, по которому Decode находит кодек по имени.
+func RegisterCodec(c Codec) {
+	codecs[c.Name()] = c
+}
+
+func CodecByName(name string) (Codec, error) {
+	c, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownCodec, name)
+	}
+	return c, nil
+}
+
+// Конверт хранится как "<имя кодека>:<данные>".
+func encodeEnvelope(c Codec, v any) (string, error) {
+	payload, err := c.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("data: encode with %s: %w", c.Name(), err)
+	}
+	return c.Name() + ":" + string(payload), nil
+}
+
+func splitEnvelope(env string) (codec, payload string, err error) {
+	codec, payload, ok := strings.Cut(env, ":")
+	if !ok {
+		return "", "", ErrInvalidEnvelope
+	}
+	return codec, payload, nil
+}
+
+// Decode раскодирует данные, сохранённые DataManager, кодеком из их заголовка.
+func Decode(env string, v any) error {
+	name, payload, err := splitEnvelope(env)
+	if err != nil {
+		return err
+	}
+	c, err := CodecByName(name)
+	if err != nil {
+		return err
+	}
+	return c.Unmarshal([]byte(payload), v)
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) Name() string                       { return "json" }
+func (JSONCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type GobCodec struct{}
+
+func (GobCodec) Name() string { return "gob" }
+
+func (GobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// ProtobufCodec работает только со сгенерированными protobuf-сообщениями.
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) Name() string { return "protobuf" }
+
+func (ProtobufCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+	return proto.Marshal(m)
+}
+
+func (ProtobufCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) Name() string                       { return "msgpack" }
+func (MsgpackCodec) Marshal(v any) ([]byte, error)      { return msgpack.Marshal(v) }
+func (MsgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
@@ -0,0 +1,149 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+)
+
+// Mismatch описывает расхождение старого и нового хранилища во время миграции.
+type Mismatch struct {
+	Op       string
+	Key      string
+	OldErr   error
+	NewErr   error
+	OldValue string
+	NewValue string
+}
+
+type DualWriteConfig struct {
+	// CompareReads - читать из обоих хранилищ и сообщать о расхождениях.
+	CompareReads bool
+	// Strict - ошибка записи во второстепенное хранилище возвращается вызывающему.
+	// По умолчанию она только передаётся в OnMismatch, чтобы миграция не влияла на клиентов.
+	Strict bool
+	// OnMismatch вызывается при каждом расхождении результатов.
+	OnMismatch func(Mismatch)
+}
+
+// DualWrite - хранилище для миграции без простоя: запись идёт в оба бэкенда,
+// чтение - из основного. До SwitchOver основным считается старый бэкенд, после - новый.
+type DualWrite struct {
+	old, new Storage
+	cfg      DualWriteConfig
+	switched atomic.Bool
+}
+
+var _ Storage = (*DualWrite)(nil)
+
+func NewDualWrite(oldStorage, newStorage Storage, cfg DualWriteConfig) *DualWrite {
+	return &DualWrite{old: oldStorage, new: newStorage, cfg: cfg}
+}
+
+// SwitchOver делает новый бэкенд основным; старый продолжает получать записи для отката.
+func (d *DualWrite) SwitchOver() { d.switched.Store(true) }
+
+// Rollback возвращает основным старый бэкенд.
+func (d *DualWrite) Rollback() { d.switched.Store(false) }
+
+func (d *DualWrite) SwitchedOver() bool { return d.switched.Load() }
+
+func (d *DualWrite) primary() (primary, secondary Storage) {
+	if d.switched.Load() {
+		return d.new, d.old
+	}
+	return d.old, d.new
+}
+
+func (d *DualWrite) report(m Mismatch) {
+	if d.cfg.OnMismatch != nil {
+		d.cfg.OnMismatch(m)
+	}
+}
+
+// pair раскладывает результаты основного и второстепенного бэкенда по old/new.
+func (d *DualWrite) pair(op, key string, pErr, sErr error, pVal, sVal string) Mismatch {
+	m := Mismatch{Op: op, Key: key, OldErr: pErr, NewErr: sErr, OldValue: pVal, NewValue: sVal}
+	if d.switched.Load() {
+		m.OldErr, m.NewErr = sErr, pErr
+		m.OldValue, m.NewValue = sVal, pVal
+	}
+	return m
+}
+
+func (d *DualWrite) Save(ctx context.Context, key, data string) error {
+	primary, secondary := d.primary()
+	if err := primary.Save(ctx, key, data); err != nil {
+		return err
+	}
+	if err := secondary.Save(ctx, key, data); err != nil {
+		d.report(d.pair("save", key, nil, err, "", ""))
+		if d.cfg.Strict {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DualWrite) Load(ctx context.Context, key string) (string, error) {
+	primary, secondary := d.primary()
+	val, err := primary.Load(ctx, key)
+	if !d.cfg.CompareReads {
+		return val, err
+	}
+	sVal, sErr := secondary.Load(ctx, key)
+	if val != sVal || !sameOutcome(err, sErr) {
+		d.report(d.pair("load", key, err, sErr, val, sVal))
+	}
+	return val, err
+}
+
+func (d *DualWrite) List(ctx context.Context, prefix string) ([]string, error) {
+	primary, secondary := d.primary()
+	keys, err := primary.List(ctx, prefix)
+	if !d.cfg.CompareReads {
+		return keys, err
+	}
+	sKeys, sErr := secondary.List(ctx, prefix)
+	if !slices.Equal(keys, sKeys) || !sameOutcome(err, sErr) {
+		d.report(d.pair("list", prefix, err, sErr, "", ""))
+	}
+	return keys, err
+}
+
+// sameOutcome считает одинаковыми успех/успех и ErrNotFound/ErrNotFound.
+func sameOutcome(a, b error) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return errors.Is(a, ErrNotFound) == errors.Is(b, ErrNotFound)
+}
+
+// Backfill копирует в новый бэкенд записи старого, которых в нём ещё нет; возвращает число скопированных.
+func (d *DualWrite) Backfill(ctx context.Context, prefix string) (int, error) {
+	keys, err := d.old.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	copied := 0
+	for _, key := range keys {
+		if _, err := d.new.Load(ctx, key); err == nil {
+			continue
+		} else if !errors.Is(err, ErrNotFound) {
+			return copied, err
+		}
+		val, err := d.old.Load(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return copied, err
+		}
+		if err := d.new.Save(ctx, key, val); err != nil {
+			return copied, err
+		}
+		copied++
+	}
+	return copied, nil
+}
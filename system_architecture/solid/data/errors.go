@@ -0,0 +1,20 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Ошибки слоя данных. DataManager и все бэкенды оборачивают их через %w,
+// поэтому вызывающий код ветвится по errors.Is, а отказ валидации - по errors.As(*ValidationError):
+//   - ErrNotFound - ключа нет; Load/LoadData;
+//   - ErrConflict - операция противоречит уже сохранённому состоянию;
+//   - ErrUnavailable - хранилище не ответило (сеть, диск, разомкнутая цепь); запрос можно повторить.
+var (
+	ErrNotFound    = errors.New("data: not found")
+	ErrConflict    = errors.New("data: conflict")
+	ErrUnavailable = errors.New("data: storage unavailable")
+)
+
+// ErrCircuitOpen - частный случай ErrUnavailable: хранилище не вызывалось, потому что цепь разомкнута.
+var ErrCircuitOpen = fmt.Errorf("%w (circuit open)", ErrUnavailable)
@@ -0,0 +1,26 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+// Publisher - порт для публикации событий сохранения; реализуется шинами events.Bus и eventbus.Bus.
+// Проекции, сброс кэшей и аудит подписываются на события и не встраиваются в цепочку сохранения.
+type Publisher interface {
+	Publish(ctx context.Context, event any)
+}
+
+type DataSaved struct {
+	Key      string
+	Codec    string
+	Bytes    int
+	Duration time.Duration
+}
+
+type DataSaveFailed struct {
+	Key      string
+	Codec    string
+	Err      error
+	Duration time.Duration
+}
@@ -0,0 +1,130 @@
+package data
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord - запомненный итог сохранения. Fields непуст, если сохранение
+// было отклонено валидаторами: такой отказ детерминирован и воспроизводится как есть.
+// Pending - сохранение с этим ключом ещё идёт, итога пока нет.
+type IdempotencyRecord struct {
+	Key       string       `json:"key"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	Pending   bool         `json:"pending,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+func (r IdempotencyRecord) result() error {
+	if len(r.Fields) > 0 {
+		return &ValidationError{Fields: r.Fields}
+	}
+	return nil
+}
+
+// KeyStore хранит итоги сохранений по ключу идемпотентности в течение ttl.
+type KeyStore interface {
+	Get(ctx context.Context, idemKey string) (IdempotencyRecord, bool, error)
+	// Reserve атомарно кладёт rec, если ключа нет, и возвращает true; иначе -
+	// существующую запись и false. Так из одновременных повторов сохраняет только один.
+	Reserve(ctx context.Context, idemKey string, rec IdempotencyRecord, ttl time.Duration) (IdempotencyRecord, bool, error)
+	// Put записывает итог поверх резерва.
+	Put(ctx context.Context, idemKey string, rec IdempotencyRecord, ttl time.Duration) error
+	// Delete снимает резерв, если сохранение не удалось и его можно повторить.
+	Delete(ctx context.Context, idemKey string) error
+}
+
+type idemCtxKey struct{}
+
+// WithIdempotencyKey помечает сохранение ключом идемпотентности: повтор с тем же ключом
+// в пределах TTL не доходит до хранилища и возвращает первоначальный результат.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idemCtxKey{}, key)
+}
+
+func IdempotencyKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(idemCtxKey{}).(string)
+	return key
+}
+
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// reservationTTL - сколько живёт резерв ключа: если сохранявший процесс упал, повтор
+// с тем же ключом снова пройдёт спустя это время, а не через TTL итога.
+const reservationTTL = time.Minute
+
+// pendingPoll - как часто повтор проверяет, закончилось ли сохранение с его ключом.
+const pendingPoll = 20 * time.Millisecond
+
+// WithIdempotency включает дедупликацию сохранений, помеченных WithIdempotencyKey.
+// При ttl <= 0 используется DefaultIdempotencyTTL.
+func WithIdempotency(store KeyStore, ttl time.Duration) Option {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return func(o *options) {
+		o.keyStore = store
+		o.idemTTL = ttl
+	}
+}
+
+type memoryEntry struct {
+	rec       IdempotencyRecord
+	expiresAt time.Time
+}
+
+type MemoryKeyStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+var _ KeyStore = (*MemoryKeyStore)(nil)
+
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+func (s *MemoryKeyStore) Get(ctx context.Context, idemKey string) (IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.get(idemKey)
+	return rec, ok, nil
+}
+
+func (s *MemoryKeyStore) get(idemKey string) (IdempotencyRecord, bool) {
+	e, ok := s.entries[idemKey]
+	if !ok {
+		return IdempotencyRecord{}, false
+	}
+	if !s.now().Before(e.expiresAt) {
+		delete(s.entries, idemKey)
+		return IdempotencyRecord{}, false
+	}
+	return e.rec, true
+}
+
+func (s *MemoryKeyStore) Reserve(ctx context.Context, idemKey string, rec IdempotencyRecord, ttl time.Duration) (IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.get(idemKey); ok {
+		return existing, false, nil
+	}
+	s.entries[idemKey] = memoryEntry{rec: rec, expiresAt: s.now().Add(ttl)}
+	return rec, true, nil
+}
+
+func (s *MemoryKeyStore) Put(ctx context.Context, idemKey string, rec IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[idemKey] = memoryEntry{rec: rec, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryKeyStore) Delete(ctx context.Context, idemKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, idemKey)
+	return nil
+}
@@ -0,0 +1,29 @@
+package data
+
+import (
+	"context"
+
+	csync "solid/concurrency/sync"
+)
+
+// WithKeyLocks выполняет сохранения одного ключа по очереди, чтобы параллельные
+// SaveData не перемешивали запись в хранилище. Общий locks упорядочивает и
+// менеджеры, пишущие в одно хранилище; nil - свой мьютекс у менеджера.
+// Очередь стоит снаружи переборки: ждущий ключа вызов не занимает её место.
+func WithKeyLocks(locks *csync.KeyedMutex) Option {
+	return func(o *options) {
+		o.keyLocks = locks
+		if o.keyLocks == nil {
+			o.keyLocks = csync.NewKeyedMutex()
+		}
+	}
+}
+
+type keyLockedStorage struct {
+	Storage
+	locks *csync.KeyedMutex
+}
+
+func (s keyLockedStorage) Save(ctx context.Context, key, data string) error {
+	return s.locks.Do(ctx, key, func() error { return s.Storage.Save(ctx, key, data) })
+}
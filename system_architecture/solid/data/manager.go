@@ -0,0 +1,313 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	csync "solid/concurrency/sync"
+	"solid/resilience/bulkhead"
+)
+
+// SaveFunc - один шаг сохранения. Конечный шаг цепочки - вызов Storage.Save.
+type SaveFunc func(ctx context.Context, key, data string) error
+
+// DataMiddleware оборачивает шаг сохранения, добавляя поведение до и/или после него
+// (валидация, логирование, метрики, повторы) без изменения самого DataManager.
+type DataMiddleware func(next SaveFunc) SaveFunc
+
+// DataManager сохраняет значения типа T. Тип проверяется при компиляции,
+// а формат хранения определяется кодеком.
+type DataManager[T any] struct {
+	storage     Storage
+	opts        options
+	middlewares []DataMiddleware
+	save        SaveFunc
+	tel         telemetry
+}
+
+// options - настройки, не зависящие от T, поэтому Option не обобщённый
+// и одни и те же опции подходят любому DataManager[T].
+type options struct {
+	codec          Codec
+	validators     []Validator
+	breaker        *Breaker
+	bulkhead       *bulkhead.Bulkhead
+	keyLocks       *csync.KeyedMutex
+	fallback       Storage
+	publisher      Publisher
+	keyFunc        func(v any) string
+	chunkSize      int
+	concurrency    int
+	keyStore       KeyStore
+	idemTTL        time.Duration
+	limiter        Limiter
+	dryRun         bool
+	logger         Logger
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+type Option func(*options)
+
+// WithCodec задаёт формат сериализации значений; по умолчанию JSON.
+func WithCodec(c Codec) Option {
+	return func(o *options) {
+		o.codec = c
+	}
+}
+
+// WithValidators добавляет проверки, выполняемые непосредственно перед Storage.Save.
+func WithValidators(vs ...Validator) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, vs...)
+	}
+}
+
+// WithBreaker пропускает все обращения к хранилищу через автоматический выключатель.
+// Пока цепь разомкнута, вызовы уходят в fallback; при fallback == nil они завершаются ErrCircuitOpen.
+func WithBreaker(b *Breaker, fallback Storage) Option {
+	return func(o *options) {
+		o.breaker = b
+		o.fallback = fallback
+	}
+}
+
+// WithPublisher включает публикацию DataSaved/DataSaveFailed после каждого SaveData;
+// по умолчанию события уходят в NopEventBus.
+func WithPublisher(pub Publisher) Option {
+	return func(o *options) {
+		o.publisher = pub
+	}
+}
+
+// WithDryRun выполняет всю цепочку сохранения, включая валидацию и сериализацию,
+// но вместо записи в хранилище только логирует, что было бы записано.
+func WithDryRun() Option {
+	return func(o *options) {
+		o.dryRun = true
+	}
+}
+
+// WithLogger задаёт логгер DataManager; по умолчанию log.Default().
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+func NewDataManager[T any](storage Storage, opts ...Option) *DataManager[T] {
+	dm := &DataManager[T]{storage: storage, opts: options{codec: JSONCodec{}, logger: log.Default()}}
+	for _, opt := range opts {
+		opt(&dm.opts)
+	}
+	if dm.opts.publisher == nil {
+		dm.opts.publisher = NopEventBus{}
+	}
+	if dm.opts.logger == nil {
+		dm.opts.logger = NopLogger{}
+	}
+	if dm.opts.tracerProvider != nil {
+		storage = TraceStorage(storage, dm.opts.tracerProvider)
+		dm.storage = storage
+		if dm.opts.fallback != nil {
+			dm.opts.fallback = TraceStorage(dm.opts.fallback, dm.opts.tracerProvider)
+		}
+	}
+	if dm.opts.breaker != nil {
+		dm.storage = breakerStorage{primary: storage, breaker: dm.opts.breaker, fallback: dm.opts.fallback}
+	}
+	if dm.opts.bulkhead != nil {
+		dm.storage = bulkheadStorage{Storage: dm.storage, bulkhead: dm.opts.bulkhead}
+	}
+	if dm.opts.keyLocks != nil {
+		dm.storage = keyLockedStorage{Storage: dm.storage, locks: dm.opts.keyLocks}
+	}
+	// Лимит проверяется снаружи выключателя: отказ по лимиту не считается сбоем хранилища.
+	if dm.opts.limiter != nil {
+		dm.storage = limitedStorage{Storage: dm.storage, limiter: dm.opts.limiter}
+	}
+	dm.tel = newTelemetry(dm.opts.tracerProvider, dm.opts.meterProvider)
+	dm.build()
+	return dm
+}
+
+// Use добавляет middleware в конец цепочки: первый добавленный выполняется первым.
+func (dm *DataManager[T]) Use(mws ...DataMiddleware) {
+	dm.middlewares = append(dm.middlewares, mws...)
+	dm.build()
+}
+
+func (dm *DataManager[T]) build() {
+	save := dm.store
+	for i := len(dm.middlewares) - 1; i >= 0; i-- {
+		save = dm.middlewares[i](save)
+	}
+	dm.save = save
+}
+
+// store - последний шаг цепочки: валидаторы проверяют сами данные без заголовка кодека.
+func (dm *DataManager[T]) store(ctx context.Context, key, env string) error {
+	if err := dm.validate(ctx, env); err != nil {
+		return err
+	}
+	if dm.opts.dryRun {
+		dm.opts.logger.Printf("dry run: would save %q (%d bytes): %s", key, len(env), env)
+		return nil
+	}
+	ctx, span := dm.tel.start(ctx, "data.store", attribute.String("data.key", key))
+	err := dm.storage.Save(ctx, key, env)
+	finish(span, err)
+	if err != nil {
+		return fmt.Errorf("data: save %q: %w", key, err)
+	}
+	return nil
+}
+
+func (dm *DataManager[T]) validate(ctx context.Context, env string) (err error) {
+	_, span := dm.tel.start(ctx, "data.validate", attribute.Int("data.validators", len(dm.opts.validators)))
+	defer func() { finish(span, err) }()
+	_, payload, err := splitEnvelope(env)
+	if err != nil {
+		return err
+	}
+	return validateAll(dm.opts.validators, payload)
+}
+
+// SaveData сериализует значение выбранным кодеком и передаёт его по цепочке middleware в хранилище.
+func (dm *DataManager[T]) SaveData(ctx context.Context, key string, v T) error {
+	idemKey := IdempotencyKeyFrom(ctx)
+	// Пробный прогон не должен занимать ключ идемпотентности настоящего сохранения.
+	if dm.opts.keyStore == nil || idemKey == "" || dm.opts.dryRun {
+		return dm.saveOnce(ctx, key, v)
+	}
+	if done, err := dm.reserve(ctx, key, idemKey); done {
+		return err
+	}
+	err := dm.saveOnce(ctx, key, v)
+	// Запоминаются только успех и отказ валидации; после сбоя хранилища резерв
+	// снимается, и сохранение можно повторить с тем же ключом.
+	rec := IdempotencyRecord{Key: key, CreatedAt: time.Now()}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		rec.Fields = ve.Fields
+	} else if err != nil {
+		if derr := dm.opts.keyStore.Delete(context.WithoutCancel(ctx), idemKey); derr != nil {
+			dm.opts.logger.Printf("data: idempotency release %q: %v", idemKey, derr)
+		}
+		return err
+	}
+	if perr := dm.opts.keyStore.Put(context.WithoutCancel(ctx), idemKey, rec, dm.opts.idemTTL); perr != nil {
+		return fmt.Errorf("data: idempotency store: %w", perr)
+	}
+	return err
+}
+
+// reserve занимает ключ идемпотентности за этим сохранением. Если ключ занят
+// сохранением, которое ещё идёт, reserve ждёт его итога. done - сохранять не нужно,
+// а err - что вернуть вызывающему: запомненный итог, конфликт ключей или сбой.
+func (dm *DataManager[T]) reserve(ctx context.Context, key, idemKey string) (done bool, err error) {
+	pending := IdempotencyRecord{Key: key, Pending: true, CreatedAt: time.Now()}
+	for {
+		rec, reserved, err := dm.opts.keyStore.Reserve(ctx, idemKey, pending, min(reservationTTL, dm.opts.idemTTL))
+		switch {
+		case err != nil:
+			return true, fmt.Errorf("data: idempotency reserve: %w", err)
+		case reserved:
+			return false, nil
+		case rec.Key != key:
+			return true, fmt.Errorf("%w: idempotency key %q already used for %q", ErrConflict, idemKey, rec.Key)
+		case !rec.Pending:
+			return true, rec.result()
+		}
+		t := time.NewTimer(pendingPoll)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return true, ctx.Err()
+		}
+	}
+}
+
+func (dm *DataManager[T]) saveOnce(ctx context.Context, key string, v T) error {
+	start := time.Now()
+	ctx, span := dm.tel.start(ctx, "data.SaveData",
+		attribute.String("data.key", key), attribute.String("data.codec", dm.opts.codec.Name()))
+	_, serialize := dm.tel.start(ctx, "data.serialize")
+	env, err := encodeEnvelope(dm.opts.codec, v)
+	finish(serialize, err)
+	if err == nil {
+		err = dm.save(ctx, key, env)
+	}
+	dm.publish(ctx, key, env, err, time.Since(start))
+	dm.tel.end(ctx, span, "save", start, err)
+	return err
+}
+
+func (dm *DataManager[T]) publish(ctx context.Context, key, env string, err error, elapsed time.Duration) {
+	codec := dm.opts.codec.Name()
+	if err != nil {
+		dm.opts.publisher.Publish(ctx, DataSaveFailed{Key: key, Codec: codec, Err: err, Duration: elapsed})
+		return
+	}
+	dm.opts.publisher.Publish(ctx, DataSaved{Key: key, Codec: codec, Bytes: len(env), Duration: elapsed})
+}
+
+// LoadData читает значение по ключу. Отсутствие ключа проверяется через errors.Is(err, ErrNotFound),
+// сбой хранилища - через errors.Is(err, ErrUnavailable).
+func (dm *DataManager[T]) LoadData(ctx context.Context, key string) (_ T, err error) {
+	start := time.Now()
+	ctx, span := dm.tel.start(ctx, "data.LoadData", attribute.String("data.key", key))
+	defer func() { dm.tel.end(ctx, span, "load", start, err) }()
+	var zero T
+	env, err := dm.storage.Load(ctx, key)
+	if err != nil {
+		return zero, fmt.Errorf("data: load %q: %w", key, err)
+	}
+	v, err := dm.Decode(env)
+	if err != nil {
+		return zero, fmt.Errorf("data: decode %q: %w", key, err)
+	}
+	return v, nil
+}
+
+// Record - значение вместе с ключом, под которым оно сохранено.
+type Record[T any] struct {
+	Key   string
+	Value T
+}
+
+// ListData возвращает все значения с ключами, начинающимися с prefix, упорядоченные по ключу.
+func (dm *DataManager[T]) ListData(ctx context.Context, prefix string) ([]Record[T], error) {
+	keys, err := dm.storage.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("data: list %q: %w", prefix, err)
+	}
+	records := make([]Record[T], 0, len(keys))
+	for _, key := range keys {
+		v, err := dm.LoadData(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			// Запись удалили между List и Load - просто пропускаем её.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, Record[T]{Key: key, Value: v})
+	}
+	return records, nil
+}
+
+// Decode восстанавливает значение T из сохранённых данных.
+func (dm *DataManager[T]) Decode(env string) (T, error) {
+	var v T
+	err := Decode(env, &v)
+	return v, err
+}
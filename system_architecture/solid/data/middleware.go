@@ -0,0 +1,108 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"solid/resilience/retry"
+)
+
+// Logger - минимальный printf-интерфейс логгера; ему удовлетворяет *log.Logger,
+// а *slog.Logger подключается через адаптер design_patterns/adapter.
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+// Logging пишет в лог результат и длительность каждого сохранения.
+func Logging(logger Logger) DataMiddleware {
+	return func(next SaveFunc) SaveFunc {
+		return func(ctx context.Context, key, data string) error {
+			start := time.Now()
+			err := next(ctx, key, data)
+			if err != nil {
+				logger.Printf("save %q failed after %s: %v", key, time.Since(start), err)
+			} else {
+				logger.Printf("saved %q (%d bytes) in %s", key, len(data), time.Since(start))
+			}
+			return err
+		}
+	}
+}
+
+// Validate отклоняет данные до обращения к хранилищу.
+func Validate(check func(data string) error) DataMiddleware {
+	return func(next SaveFunc) SaveFunc {
+		return func(ctx context.Context, key, data string) error {
+			if err := check(data); err != nil {
+				return err
+			}
+			return next(ctx, key, data)
+		}
+	}
+}
+
+// Retry повторяет неудачное сохранение до attempts раз, удваивая паузу между попытками.
+// Ошибки валидации, разомкнутой цепи, переполненной переборки, превышения лимита
+// и отмены контекста не повторяются.
+func Retry(attempts int, backoff time.Duration) DataMiddleware {
+	return RetryPolicy(retry.Policy{
+		MaxAttempts: max(attempts, 1),
+		Backoff:     retry.Exponential(backoff, 0),
+		Jitter:      retry.NoJitter,
+	})
+}
+
+// RetryPolicy повторяет неудачное сохранение по политике p; ошибки, которые Retry
+// не повторяет, не повторяются и здесь, что бы ни говорил p.Retryable.
+func RetryPolicy(p retry.Policy) DataMiddleware {
+	retryable := p.Retryable
+	p.Retryable = func(err error) bool {
+		return IsRetryable(err) && (retryable == nil || retryable(err))
+	}
+	return func(next SaveFunc) SaveFunc {
+		return func(ctx context.Context, key, data string) error {
+			return retry.Do(ctx, p, func(ctx context.Context) error { return next(ctx, key, data) })
+		}
+	}
+}
+
+// IsRetryable сообщает, есть ли смысл повторять операцию хранилища: отказ валидации,
+// разомкнутая цепь, полная переборка и превышенный лимит от повтора не исчезнут.
+func IsRetryable(err error) bool {
+	var ve *ValidationError
+	return err != nil && !errors.As(err, &ve) && !errors.Is(err, ErrCircuitOpen) &&
+		!errors.Is(err, ErrBulkheadFull) && !errors.Is(err, ErrRateLimited)
+}
+
+// Counters - простые метрики сохранений.
+type Counters struct {
+	Saves    atomic.Int64
+	Failures atomic.Int64
+	Bytes    atomic.Int64
+}
+
+func (c *Counters) RecordSave(bytes int, err error) {
+	if err != nil {
+		c.Failures.Add(1)
+		return
+	}
+	c.Saves.Add(1)
+	c.Bytes.Add(int64(bytes))
+}
+
+// MetricsRecorder принимает результат каждого сохранения; *Counters - простейшая реализация.
+type MetricsRecorder interface {
+	RecordSave(bytes int, err error)
+}
+
+func Metrics(m MetricsRecorder) DataMiddleware {
+	return func(next SaveFunc) SaveFunc {
+		return func(ctx context.Context, key, data string) error {
+			err := next(ctx, key, data)
+			m.RecordSave(len(data), err)
+			return err
+		}
+	}
+}
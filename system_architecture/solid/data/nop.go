@@ -0,0 +1,36 @@
+package data
+
+import "context"
+
+// Пустые объекты (Null Object) для необязательных зависимостей: конструкторы
+// подставляют их вместо nil, и вызывающему коду не нужны проверки на nil.
+
+// NopStorage ничего не хранит: Save проходит успешно, Load всегда отвечает ErrNotFound.
+// Подходит для отключённого сохранения, например в пробных прогонах и демонстрациях.
+type NopStorage struct{}
+
+func (NopStorage) Save(ctx context.Context, key, data string) error { return nil }
+
+func (NopStorage) Load(ctx context.Context, key string) (string, error) { return "", ErrNotFound }
+
+func (NopStorage) List(ctx context.Context, prefix string) ([]string, error) { return nil, nil }
+
+type NopLogger struct{}
+
+func (NopLogger) Printf(format string, args ...any) {}
+
+// NopEventBus отбрасывает события; подходит и как data.Publisher, и как library.Publisher.
+type NopEventBus struct{}
+
+func (NopEventBus) Publish(ctx context.Context, event any) {}
+
+type NopMetrics struct{}
+
+func (NopMetrics) RecordSave(bytes int, err error) {}
+
+var (
+	_ Storage         = NopStorage{}
+	_ Logger          = NopLogger{}
+	_ Publisher       = NopEventBus{}
+	_ MetricsRecorder = NopMetrics{}
+)
@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// OutboxEntry - сообщение об изменении, записанное вместе с самими данными.
+// ID служит маркером дедупликации: при повторной доставке он не меняется.
+type OutboxEntry struct {
+	ID        string
+	Key       string
+	Data      string
+	CreatedAt time.Time
+}
+
+// Outbox - очередь неопубликованных сообщений хранилища.
+type Outbox interface {
+	Pending(ctx context.Context, limit int) ([]OutboxEntry, error)
+	MarkPublished(ctx context.Context, ids ...string) error
+}
+
+var _ Outbox = (*Database)(nil)
+
+// DataChanged публикуется ретранслятором для каждой записи outbox.
+type DataChanged struct {
+	EventID string
+	Key     string
+	Data    string
+}
+
+const DefaultRelayBatch = 100
+
+// Relay переносит сообщения из outbox в шину. Запись помечается опубликованной только
+// после Publish, поэтому при сбое между ними сообщение уйдёт повторно (at-least-once);
+// подписчики отсеивают повторы по EventID, например через Deduper.
+type Relay struct {
+	Outbox    Outbox
+	Publisher Publisher
+	Batch     int
+}
+
+// Flush публикует одну порцию сообщений и возвращает их число.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	batch := r.Batch
+	if batch <= 0 {
+		batch = DefaultRelayBatch
+	}
+	pending, err := r.Outbox.Pending(ctx, batch)
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+	ids := make([]string, len(pending))
+	for i, e := range pending {
+		r.Publisher.Publish(ctx, DataChanged{EventID: e.ID, Key: e.Key, Data: e.Data})
+		ids[i] = e.ID
+	}
+	return len(pending), r.Outbox.MarkPublished(ctx, ids...)
+}
+
+// Run вызывает Flush каждые interval до отмены контекста; ошибки передаются в onError.
+func (r *Relay) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Flush(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Deduper запоминает обработанные EventID, превращая at-least-once доставку в однократную обработку.
+type Deduper struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func NewDeduper() *Deduper {
+	return &Deduper{seen: make(map[string]struct{})}
+}
+
+// First сообщает, встречается ли id впервые, и запоминает его.
+func (d *Deduper) First(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.seen[id]; ok {
+		return false
+	}
+	d.seen[id] = struct{}{}
+	return true
+}
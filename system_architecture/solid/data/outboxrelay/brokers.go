@@ -0,0 +1,173 @@
+package outboxrelay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"solid/data"
+	"solid/resilience/breaker"
+)
+
+var (
+	_ Broker     = (*WriterBroker)(nil)
+	_ Broker     = (*Webhook)(nil)
+	_ Broker     = (*RedisStream)(nil)
+	_ Broker     = PublisherBroker{}
+	_ DeadLetter = (*FileDeadLetter)(nil)
+	_ DeadLetter = (*StorageDeadLetter)(nil)
+)
+
+// WriterBroker пишет сообщения строками JSON, например в stdout.
+type WriterBroker struct {
+	mu sync.Mutex
+	W  io.Writer
+}
+
+func (b *WriterBroker) Send(ctx context.Context, m Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return json.NewEncoder(b.W).Encode(m)
+}
+
+// Webhook отправляет каждое сообщение POST-запросом. 2xx - доставлено; 408, 429 и 5xx -
+// временный сбой; прочие ответы 4xx - ErrPermanent.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+	// Breaker, если задан, размыкается на временных сбоях: пока получатель лежит,
+	// сообщения сразу уходят на повтор с ErrOpen, не дожидаясь таймаутов.
+	Breaker *breaker.Breaker
+}
+
+func (b *Webhook) Send(ctx context.Context, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("%w: encode %s: %v", ErrPermanent, m.ID, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", m.ID)
+	// Отказ ErrPermanent - ответ получателя по существу, выключателю он не сбой.
+	var permanent error
+	err = b.Breaker.Do(func() error {
+		err := b.post(req)
+		if errors.Is(err, ErrPermanent) {
+			permanent = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("outboxrelay: webhook: %w", err)
+	}
+	return permanent
+}
+
+func (b *Webhook) post(req *http.Request) error {
+	client := b.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return fmt.Errorf("status %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("%w: webhook: status %d", ErrPermanent, resp.StatusCode)
+	}
+}
+
+// RedisStream добавляет сообщения в поток Redis (XADD); MaxLen ограничивает его длину приблизительно.
+type RedisStream struct {
+	Client redis.UniversalClient
+	Stream string
+	MaxLen int64
+}
+
+func (b *RedisStream) Send(ctx context.Context, m Message) error {
+	err := b.Client.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.Stream,
+		MaxLen: b.MaxLen,
+		Approx: b.MaxLen > 0,
+		Values: map[string]any{"id": m.ID, "key": m.Key, "data": m.Data, "created_at": m.CreatedAt.Format(time.RFC3339Nano)},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("outboxrelay: xadd %s: %w", b.Stream, err)
+	}
+	return nil
+}
+
+// PublisherBroker передаёт сообщения во внутреннюю шину как data.DataChanged; отказов не бывает.
+type PublisherBroker struct {
+	Publisher data.Publisher
+}
+
+func (b PublisherBroker) Send(ctx context.Context, m Message) error {
+	b.Publisher.Publish(ctx, data.DataChanged{EventID: m.ID, Key: m.Key, Data: m.Data})
+	return nil
+}
+
+// FileDeadLetter дописывает недоставленные сообщения строками JSON в файл Path.
+type FileDeadLetter struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+type buried struct {
+	Message
+	Error    string    `json:"error"`
+	BuriedAt time.Time `json:"buried_at"`
+}
+
+func (d *FileDeadLetter) Bury(ctx context.Context, m Message, cause error) error {
+	line, err := json.Marshal(buried{Message: m, Error: cause.Error(), BuriedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	f, err := os.OpenFile(d.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// StorageDeadLetter сохраняет недоставленные сообщения в хранилище под Prefix+ID.
+type StorageDeadLetter struct {
+	Storage data.Saver
+	Prefix  string
+}
+
+func (d *StorageDeadLetter) Bury(ctx context.Context, m Message, cause error) error {
+	line, err := json.Marshal(buried{Message: m, Error: cause.Error(), BuriedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	return d.Storage.Save(ctx, d.Prefix+m.ID, string(line))
+}
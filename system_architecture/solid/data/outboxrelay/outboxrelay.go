@@ -0,0 +1,214 @@
+// Package outboxrelay - самостоятельный ретранслятор outbox: опрашивает data.Outbox,
+// отправляет записи во внешний брокер и помечает доставленные. В отличие от data.Relay,
+// брокер здесь может отказать: запись повторяется с нарастающей паузой, а «ядовитая»
+// после MaxAttempts попыток (или сразу при ErrPermanent) уходит в очередь недоставленных,
+// чтобы не задерживать остальные.
+package outboxrelay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"solid/data"
+)
+
+// ErrPermanent - отказ брокера, который повтор не исправит (например, 4xx от вебхука).
+var ErrPermanent = errors.New("outboxrelay: permanent failure")
+
+// Message - запись outbox в том виде, в каком её получает брокер. ID не меняется
+// между попытками, по нему получатель отсеивает повторы.
+type Message struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key"`
+	Data      string    `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+	Attempt   int       `json:"attempt"`
+}
+
+type Broker interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// DeadLetter хранит сообщения, от которых ретранслятор отказался.
+type DeadLetter interface {
+	Bury(ctx context.Context, m Message, cause error) error
+}
+
+// Policy - правила повторов. Нулевые поля заменяются значениями по умолчанию.
+type Policy struct {
+	// MaxAttempts - попыток до отправки в DeadLetter, по умолчанию 5.
+	MaxAttempts int
+	// Backoff - пауза после первой неудачи, дальше удваивается до MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 5
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = time.Second
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = time.Minute
+	}
+	return p
+}
+
+// Stats - счётчики для метрик и журнала.
+type Stats struct {
+	Sent       int
+	Failed     int
+	DeadLetter int
+	Retrying   int
+}
+
+// Worker - ретранслятор. Число попыток хранится в памяти: после перезапуска отсчёт
+// начинается заново, но записи не теряются - они остаются в outbox неотмеченными.
+// Записи в паузе после неудачи пропускаются, поэтому при сбоях порядок доставки
+// может отличаться от порядка записи.
+type Worker struct {
+	Outbox     data.Outbox
+	Broker     Broker
+	DeadLetter DeadLetter
+	Policy     Policy
+	Batch      int
+	Logger     data.Logger
+
+	mu       sync.Mutex
+	attempts map[string]retry
+	stats    Stats
+	now      func() time.Time
+}
+
+type retry struct {
+	attempts int
+	next     time.Time
+}
+
+// Poll обрабатывает одну порцию outbox и возвращает число отмеченных записей.
+func (w *Worker) Poll(ctx context.Context) (int, error) {
+	policy := w.Policy.withDefaults()
+	batch := w.Batch
+	if batch <= 0 {
+		batch = data.DefaultRelayBatch
+	}
+	pending, err := w.Outbox.Pending(ctx, batch)
+	if err != nil {
+		return 0, fmt.Errorf("outboxrelay: poll: %w", err)
+	}
+	w.mu.Lock()
+	if w.attempts == nil {
+		w.attempts = map[string]retry{}
+	}
+	now := time.Now
+	if w.now != nil {
+		now = w.now
+	}
+	w.mu.Unlock()
+
+	var done []string
+	for _, e := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		w.mu.Lock()
+		r := w.attempts[e.ID]
+		w.mu.Unlock()
+		if now().Before(r.next) {
+			continue
+		}
+		m := Message{ID: e.ID, Key: e.Key, Data: e.Data, CreatedAt: e.CreatedAt, Attempt: r.attempts + 1}
+		sendErr := w.Broker.Send(ctx, m)
+		if sendErr == nil {
+			done = append(done, e.ID)
+			w.record(e.ID, func(s *Stats) { s.Sent++ })
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		w.record("", func(s *Stats) { s.Failed++ })
+		if m.Attempt < policy.MaxAttempts && !errors.Is(sendErr, ErrPermanent) {
+			pause := min(policy.Backoff<<(m.Attempt-1), policy.MaxBackoff)
+			w.mu.Lock()
+			w.attempts[e.ID] = retry{attempts: m.Attempt, next: now().Add(pause)}
+			w.mu.Unlock()
+			w.logf("outboxrelay: %s attempt %d failed, retry in %s: %v", e.ID, m.Attempt, pause, sendErr)
+			continue
+		}
+		if w.DeadLetter == nil {
+			return len(done), w.mark(ctx, done, fmt.Errorf("outboxrelay: %s: no dead-letter sink: %w", e.ID, sendErr))
+		}
+		if err := w.DeadLetter.Bury(ctx, m, sendErr); err != nil {
+			// Не закопали - не отмечаем: запись останется в outbox и будет повторена.
+			return len(done), w.mark(ctx, done, fmt.Errorf("outboxrelay: dead-letter %s: %w", e.ID, err))
+		}
+		w.logf("outboxrelay: %s moved to dead letters after %d attempt(s): %v", e.ID, m.Attempt, sendErr)
+		done = append(done, e.ID)
+		w.record(e.ID, func(s *Stats) { s.DeadLetter++ })
+	}
+	return len(done), w.mark(ctx, done, nil)
+}
+
+// mark отмечает доставленные записи; cause - ошибка, прервавшая порцию.
+func (w *Worker) mark(ctx context.Context, ids []string, cause error) error {
+	if len(ids) > 0 {
+		if err := w.Outbox.MarkPublished(context.WithoutCancel(ctx), ids...); err != nil {
+			// Записи уйдут повторно; получатели отсеют их по ID.
+			return errors.Join(cause, fmt.Errorf("outboxrelay: mark dispatched: %w", err))
+		}
+	}
+	w.mu.Lock()
+	w.stats.Retrying = len(w.attempts)
+	w.mu.Unlock()
+	return cause
+}
+
+func (w *Worker) record(id string, f func(*Stats)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if id != "" {
+		delete(w.attempts, id)
+	}
+	f(&w.stats)
+}
+
+func (w *Worker) Stats() Stats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+// Run вызывает Poll каждые interval до отмены ctx; полная порция опрашивается сразу,
+// без паузы, чтобы догнать отставание.
+func (w *Worker) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	batch := w.Batch
+	if batch <= 0 {
+		batch = data.DefaultRelayBatch
+	}
+	for {
+		n, err := w.Poll(ctx)
+		if err != nil && onError != nil {
+			onError(err)
+		}
+		if n >= batch && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (w *Worker) logf(format string, args ...any) {
+	if w.Logger != nil {
+		w.Logger.Printf(format, args...)
+	}
+}
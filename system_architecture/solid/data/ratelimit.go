@@ -0,0 +1,99 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"solid/resilience/ratelimit"
+)
+
+var ErrRateLimited = errors.New("data: rate limited")
+
+// RateLimitError сообщает, через сколько можно повторить запрос; errors.Is(err, ErrRateLimited) == true.
+type RateLimitError struct {
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v: %q, retry after %s", ErrRateLimited, e.Key, e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error { return ErrRateLimited }
+
+// Limiter решает, можно ли выполнить ещё одно обращение к хранилищу от имени key.
+// Реализации живут в resilience/ratelimit: локальные (TokenBucket, SlidingWindow)
+// и общие для нескольких процессов (RedisTokenBucket, RedisSlidingWindow).
+type Limiter = ratelimit.Limiter
+
+// GlobalKey - ключ лимита для вызовов без WithCaller.
+const GlobalKey = "global"
+
+type callerCtxKey struct{}
+
+// WithCaller задаёт, от чьего имени выполняются обращения: лимит считается отдельно для каждого вызывающего.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerCtxKey{}, caller)
+}
+
+func CallerFrom(ctx context.Context) string {
+	if caller, _ := ctx.Value(callerCtxKey{}).(string); caller != "" {
+		return caller
+	}
+	return GlobalKey
+}
+
+// WithRateLimit проверяет лимит перед каждым обращением к хранилищу.
+func WithRateLimit(l Limiter) Option {
+	return func(o *options) {
+		o.limiter = l
+	}
+}
+
+// TokenBucket оставлен под прежним именем для кода слоя данных.
+type TokenBucket = ratelimit.TokenBucket
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return ratelimit.NewTokenBucket(rate, burst)
+}
+
+// limitedStorage проверяет лимит до обращения к хранилищу.
+type limitedStorage struct {
+	Storage
+	limiter Limiter
+}
+
+func (s limitedStorage) wait(ctx context.Context) error {
+	key := CallerFrom(ctx)
+	ok, retryAfter, err := s.limiter.Allow(ctx, key)
+	if err != nil {
+		return fmt.Errorf("%w: rate limiter: %w", ErrUnavailable, err)
+	}
+	if !ok {
+		return &RateLimitError{Key: key, RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+func (s limitedStorage) Save(ctx context.Context, key, data string) error {
+	if err := s.wait(ctx); err != nil {
+		return err
+	}
+	return s.Storage.Save(ctx, key, data)
+}
+
+func (s limitedStorage) Load(ctx context.Context, key string) (string, error) {
+	if err := s.wait(ctx); err != nil {
+		return "", err
+	}
+	return s.Storage.Load(ctx, key)
+}
+
+func (s limitedStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.Storage.List(ctx, prefix)
+}
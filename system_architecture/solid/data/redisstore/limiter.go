@@ -0,0 +1,17 @@
+package redisstore
+
+import (
+	"github.com/redis/go-redis/v9"
+
+	"solid/resilience/ratelimit"
+)
+
+const DefaultLimiterPrefix = ratelimit.DefaultRedisPrefix
+
+// Limiter - распределённый token bucket; взаимозаменяем с data.TokenBucket.
+// Сама реализация - ratelimit.RedisTokenBucket.
+type Limiter = ratelimit.RedisTokenBucket
+
+func NewLimiter(client redis.UniversalClient, rate float64, burst int) *Limiter {
+	return ratelimit.NewRedisTokenBucket(client, rate, burst)
+}
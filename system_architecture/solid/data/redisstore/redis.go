@@ -0,0 +1,84 @@
+// Package redisstore - хранилище ключей идемпотентности DataManager в Redis.
+// Вынесено отдельно, чтобы пакет data не зависел от клиента Redis.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"solid/data"
+)
+
+const DefaultPrefix = "idempotency:"
+
+type KeyStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ data.KeyStore = (*KeyStore)(nil)
+
+func New(client redis.UniversalClient) *KeyStore {
+	return &KeyStore{client: client, prefix: DefaultPrefix}
+}
+
+func (s *KeyStore) Get(ctx context.Context, idemKey string) (data.IdempotencyRecord, bool, error) {
+	raw, err := s.client.Get(ctx, s.prefix+idemKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return data.IdempotencyRecord{}, false, nil
+	}
+	if err != nil {
+		return data.IdempotencyRecord{}, false, fmt.Errorf("%w: redisstore: get %q: %w", data.ErrUnavailable, idemKey, err)
+	}
+	var rec data.IdempotencyRecord
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return data.IdempotencyRecord{}, false, fmt.Errorf("redisstore: decode %q: %w", idemKey, err)
+	}
+	return rec, true, nil
+}
+
+// Reserve - SetNX: из одновременных повторов ключ займёт только один.
+func (s *KeyStore) Reserve(ctx context.Context, idemKey string, rec data.IdempotencyRecord, ttl time.Duration) (data.IdempotencyRecord, bool, error) {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return data.IdempotencyRecord{}, false, err
+	}
+	for {
+		ok, err := s.client.SetNX(ctx, s.prefix+idemKey, raw, ttl).Result()
+		if err != nil {
+			return data.IdempotencyRecord{}, false, fmt.Errorf("%w: redisstore: reserve %q: %w", data.ErrUnavailable, idemKey, err)
+		}
+		if ok {
+			return rec, true, nil
+		}
+		existing, found, err := s.Get(ctx, idemKey)
+		if err != nil || found {
+			return existing, false, err
+		}
+		// Запись истекла или снята между SetNX и GET - пробуем занять снова.
+	}
+}
+
+// Put перезаписывает резерв итогом.
+func (s *KeyStore) Put(ctx context.Context, idemKey string, rec data.IdempotencyRecord, ttl time.Duration) error {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.prefix+idemKey, raw, ttl).Err(); err != nil {
+		return fmt.Errorf("%w: redisstore: put %q: %w", data.ErrUnavailable, idemKey, err)
+	}
+	return nil
+}
+
+func (s *KeyStore) Delete(ctx context.Context, idemKey string) error {
+	if err := s.client.Del(ctx, s.prefix+idemKey).Err(); err != nil {
+		return fmt.Errorf("%w: redisstore: delete %q: %w", data.ErrUnavailable, idemKey, err)
+	}
+	return nil
+}
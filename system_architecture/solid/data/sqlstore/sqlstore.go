@@ -0,0 +1,153 @@
+// Package sqlstore - хранилище DataManager поверх database/sql (диалект PostgreSQL)
+// с транзакционным outbox: запись данных и сообщения о ней фиксируются одной транзакцией.
+// Внутри uow.Do вызывающего Save присоединяется к его транзакции.
+// Драйвер (например, pgx/stdlib) подключает вызывающий код.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"solid/data"
+	"solid/uow"
+)
+
+// Schema создаёт таблицы, с которыми работает Store.
+const Schema = `
+CREATE TABLE IF NOT EXISTS data_records (
+	key        TEXT PRIMARY KEY,
+	value      TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS data_outbox (
+	id           BIGSERIAL PRIMARY KEY,
+	key          TEXT NOT NULL,
+	value        TEXT NOT NULL,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	published_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS data_outbox_pending ON data_outbox (id) WHERE published_at IS NULL;
+`
+
+type Store struct {
+	db  *sql.DB
+	uow *uow.UnitOfWork
+}
+
+var (
+	_ data.Storage = (*Store)(nil)
+	_ data.Outbox  = (*Store)(nil)
+)
+
+func New(db *sql.DB) *Store {
+	return &Store{db: db, uow: uow.New(db, uow.Config{})}
+}
+
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, Schema)
+	return err
+}
+
+func (s *Store) Save(ctx context.Context, key, value string) error {
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		q := uow.From(ctx, s.db)
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO data_records (key, value) VALUES ($1, $2)
+			ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = now()`, key, value); err != nil {
+			return fmt.Errorf("%w: sqlstore: save %q: %w", data.ErrUnavailable, key, err)
+		}
+		return s.Enqueue(ctx, key, value)
+	})
+	// Сбой начала или фиксации транзакции - тоже недоступность хранилища.
+	if err != nil && !errors.Is(err, data.ErrUnavailable) {
+		return fmt.Errorf("%w: sqlstore: save %q: %w", data.ErrUnavailable, key, err)
+	}
+	return err
+}
+
+// Enqueue ставит сообщение в outbox, не трогая data_records, - для репозиториев со своими
+// таблицами. Вызванный внутри uow.Do, фиксируется вместе с их записями.
+func (s *Store) Enqueue(ctx context.Context, key, value string) error {
+	if _, err := uow.From(ctx, s.db).ExecContext(ctx, `INSERT INTO data_outbox (key, value) VALUES ($1, $2)`, key, value); err != nil {
+		return fmt.Errorf("%w: sqlstore: outbox %q: %w", data.ErrUnavailable, key, err)
+	}
+	return nil
+}
+
+func (s *Store) Load(ctx context.Context, key string) (string, error) {
+	var value string
+	err := uow.From(ctx, s.db).QueryRowContext(ctx, `SELECT value FROM data_records WHERE key = $1`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", data.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: sqlstore: load %q: %w", data.ErrUnavailable, key, err)
+	}
+	return value, nil
+}
+
+func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
+	rows, err := uow.From(ctx, s.db).QueryContext(ctx,
+		`SELECT key FROM data_records WHERE key LIKE $1 ESCAPE '\' ORDER BY key`, escapeLike(prefix)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("%w: sqlstore: list %q: %w", data.ErrUnavailable, prefix, err)
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("%w: sqlstore: list %q: %w", data.ErrUnavailable, prefix, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (s *Store) Pending(ctx context.Context, limit int) ([]data.OutboxEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, key, value, created_at FROM data_outbox
+		WHERE published_at IS NULL ORDER BY id LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sqlstore: pending: %w", data.ErrUnavailable, err)
+	}
+	defer rows.Close()
+	var entries []data.OutboxEntry
+	for rows.Next() {
+		var (
+			id int64
+			e  data.OutboxEntry
+		)
+		if err := rows.Scan(&id, &e.Key, &e.Data, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: sqlstore: pending: %w", data.ErrUnavailable, err)
+		}
+		e.ID = strconv.FormatInt(id, 10)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (s *Store) MarkPublished(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	for _, id := range ids {
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			return fmt.Errorf("sqlstore: invalid outbox id %q", id)
+		}
+	}
+	// Идентификаторы проверены как числа, поэтому их можно подставить в запрос напрямую.
+	_, err := s.db.ExecContext(ctx, `UPDATE data_outbox SET published_at = now() WHERE id IN (`+strings.Join(ids, ",")+`)`)
+	if err != nil {
+		return fmt.Errorf("%w: sqlstore: mark published: %w", data.ErrUnavailable, err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package data - слой хранения из примера принципа инверсии зависимостей (DIP).
+// DataManager зависит от абстракции Storage, а не от конкретной базы или файловой системы.
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Интерфейс хранилища разделён по операциям (принцип I): потребителю, которому
+// нужна только запись, достаточно Saver.
+type Saver interface {
+	Save(ctx context.Context, key, data string) error
+}
+
+type Loader interface {
+	Load(ctx context.Context, key string) (string, error)
+}
+
+type Lister interface {
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+type Storage interface {
+	Saver
+	Loader
+	Lister
+}
+
+// Database имитирует базу данных: записи хранятся в памяти процесса.
+type Database struct {
+	// Logger, если задан, получает каждую запись на уровне debug.
+	Logger *slog.Logger
+
+	mu   sync.RWMutex
+	rows map[string]string
+	// outbox != nil, если база создана NewOutboxDatabase.
+	outbox []OutboxEntry
+	seq    int64
+}
+
+func NewDatabase() *Database {
+	return &Database{rows: make(map[string]string)}
+}
+
+// NewOutboxDatabase создаёт базу, которая вместе с каждой записью атомарно
+// добавляет сообщение в outbox (аналог вставки в ту же транзакцию).
+func NewOutboxDatabase() *Database {
+	return &Database{rows: make(map[string]string), outbox: []OutboxEntry{}}
+}
+
+func (db *Database) Save(ctx context.Context, key, data string) error {
+	if db.Logger != nil {
+		db.Logger.DebugContext(ctx, "saving data to the database", "key", key, "data", data)
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.rows[key] = data
+	if db.outbox != nil {
+		db.seq++
+		db.outbox = append(db.outbox, OutboxEntry{
+			ID: fmt.Sprintf("%d", db.seq), Key: key, Data: data, CreatedAt: time.Now(),
+		})
+	}
+	return nil
+}
+
+func (db *Database) Pending(ctx context.Context, limit int) ([]OutboxEntry, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	n := min(limit, len(db.outbox))
+	return append([]OutboxEntry(nil), db.outbox[:n]...), nil
+}
+
+func (db *Database) MarkPublished(ctx context.Context, ids ...string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	marked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		marked[id] = true
+	}
+	// Опубликованные записи удаляются, чтобы outbox не рос бесконечно.
+	kept := db.outbox[:0]
+	for _, e := range db.outbox {
+		if !marked[e.ID] {
+			kept = append(kept, e)
+		}
+	}
+	db.outbox = kept
+	return nil
+}
+
+func (db *Database) Load(ctx context.Context, key string) (string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	data, ok := db.rows[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return data, nil
+}
+
+func (db *Database) List(ctx context.Context, prefix string) ([]string, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	var keys []string
+	for k := range db.rows {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Filesystem хранит каждую запись отдельным файлом в каталоге Dir.
+type Filesystem struct {
+	Dir string
+	// Logger, если задан, получает каждую запись на уровне debug.
+	Logger *slog.Logger
+}
+
+func NewFilesystem(dir string) *Filesystem {
+	return &Filesystem{Dir: dir}
+}
+
+// path кодирует ключ в имя файла, чтобы ключи с "/" не превращались в подкаталоги.
+func (fs *Filesystem) path(key string) string {
+	return filepath.Join(fs.Dir, strings.NewReplacer("%", "%25", "/", "%2F").Replace(key))
+}
+
+func (fs *Filesystem) Save(ctx context.Context, key, data string) error {
+	if fs.Logger != nil {
+		fs.Logger.DebugContext(ctx, "saving data to the filesystem", "key", key, "data", data)
+	}
+	if err := os.MkdirAll(fs.Dir, 0o755); err != nil {
+		return fmt.Errorf("%w: filesystem save %q: %w", ErrUnavailable, key, err)
+	}
+	if err := os.WriteFile(fs.path(key), []byte(data), 0o644); err != nil {
+		return fmt.Errorf("%w: filesystem save %q: %w", ErrUnavailable, key, err)
+	}
+	return nil
+}
+
+func (fs *Filesystem) Load(ctx context.Context, key string) (string, error) {
+	raw, err := os.ReadFile(fs.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: filesystem load %q: %w", ErrUnavailable, key, err)
+	}
+	return string(raw), nil
+}
+
+func (fs *Filesystem) List(ctx context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(fs.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: filesystem list: %w", ErrUnavailable, err)
+	}
+	unescape := strings.NewReplacer("%2F", "/", "%25", "%")
+	var keys []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if key := unescape.Replace(e.Name()); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,139 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+const instrumentationName = "solid/data"
+
+// WithTelemetry включает спаны и метрики OpenTelemetry. Провайдеры передаются явно,
+// без глобального состояния; по умолчанию используются no-op реализации.
+func WithTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = tp
+		o.meterProvider = mp
+	}
+}
+
+// TraceStorage оборачивает s спанами storage.Save, storage.Load и storage.List с типом
+// хранилища в атрибуте storage.type. DataManager с WithTelemetry делает это сам - и для
+// основного хранилища, и для запасного.
+func TraceStorage(s Storage, tp trace.TracerProvider) Storage {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	return tracedStorage{Storage: s, tracer: tp.Tracer(instrumentationName), typ: fmt.Sprintf("%T", s)}
+}
+
+type tracedStorage struct {
+	Storage
+	tracer trace.Tracer
+	typ    string
+}
+
+func (s tracedStorage) start(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "storage."+op, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, attribute.String("storage.type", s.typ))...))
+}
+
+func (s tracedStorage) Save(ctx context.Context, key, data string) error {
+	ctx, span := s.start(ctx, "Save", attribute.String("data.key", key), attribute.Int("data.bytes", len(data)))
+	err := s.Storage.Save(ctx, key, data)
+	finish(span, err)
+	return err
+}
+
+func (s tracedStorage) Load(ctx context.Context, key string) (string, error) {
+	ctx, span := s.start(ctx, "Load", attribute.String("data.key", key))
+	data, err := s.Storage.Load(ctx, key)
+	// Отсутствие ключа - обычный ответ хранилища, а не его сбой.
+	if errors.Is(err, ErrNotFound) {
+		span.SetAttributes(attribute.Bool("data.found", false))
+		finish(span, nil)
+		return data, err
+	}
+	finish(span, err)
+	return data, err
+}
+
+func (s tracedStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	ctx, span := s.start(ctx, "List", attribute.String("data.prefix", prefix))
+	keys, err := s.Storage.List(ctx, prefix)
+	if err == nil {
+		span.SetAttributes(attribute.Int("data.keys", len(keys)))
+	}
+	finish(span, err)
+	return keys, err
+}
+
+type telemetry struct {
+	tracer   trace.Tracer
+	ops      metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) telemetry {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	if mp == nil {
+		mp = metricnoop.NewMeterProvider()
+	}
+	meter := mp.Meter(instrumentationName)
+	// Ошибки создания инструментов не критичны: в этом случае возвращаются no-op инструменты.
+	ops, _ := meter.Int64Counter("data.operations",
+		metric.WithDescription("DataManager operations by outcome"))
+	duration, _ := meter.Float64Histogram("data.operation.duration",
+		metric.WithDescription("DataManager operation duration"), metric.WithUnit("s"))
+	return telemetry{tracer: tp.Tracer(instrumentationName), ops: ops, duration: duration}
+}
+
+func (t telemetry) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// end закрывает спан операции и учитывает её в метриках с атрибутами op и outcome.
+func (t telemetry) end(ctx context.Context, span trace.Span, op string, start time.Time, err error) {
+	outcome := outcomeOf(err)
+	attrs := metric.WithAttributes(attribute.String("op", op), attribute.String("outcome", outcome))
+	t.ops.Add(ctx, 1, attrs)
+	t.duration.Record(ctx, time.Since(start).Seconds(), attrs)
+	finish(span, err)
+}
+
+func finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func outcomeOf(err error) string {
+	var ve *ValidationError
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.As(err, &ve):
+		return "invalid"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	case errors.Is(err, ErrBulkheadFull):
+		return "bulkhead_full"
+	}
+	return "error"
+}
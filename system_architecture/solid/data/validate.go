@@ -0,0 +1,131 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator проверяет данные перед передачей в Storage.Save.
+type Validator interface {
+	Validate(data string) error
+}
+
+type ValidatorFunc func(data string) error
+
+func (f ValidatorFunc) Validate(data string) error {
+	return f(data)
+}
+
+// FieldError - замечание к конкретному полю; Field пуст, если проблема во всём документе.
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		if f.Field == "" {
+			parts[i] = f.Message
+		} else {
+			parts[i] = f.Field + ": " + f.Message
+		}
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// validateAll запускает все валидаторы и собирает их замечания в одну ValidationError.
+func validateAll(validators []Validator, data string) error {
+	var fields []FieldError
+	for _, v := range validators {
+		err := v.Validate(data)
+		if err == nil {
+			continue
+		}
+		var ve *ValidationError
+		if errors.As(err, &ve) {
+			fields = append(fields, ve.Fields...)
+		} else {
+			fields = append(fields, FieldError{Message: err.Error()})
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
+}
+
+func MaxSize(n int) Validator {
+	return ValidatorFunc(func(data string) error {
+		if len(data) > n {
+			return &ValidationError{Fields: []FieldError{{Message: fmt.Sprintf("size %d exceeds limit of %d bytes", len(data), n)}}}
+		}
+		return nil
+	})
+}
+
+func UTF8() Validator {
+	return ValidatorFunc(func(data string) error {
+		if !utf8.ValidString(data) {
+			return &ValidationError{Fields: []FieldError{{Message: "data is not valid UTF-8"}}}
+		}
+		return nil
+	})
+}
+
+// Schema - упрощённая схема JSON-объекта: обязательные поля и типы полей
+// ("string", "number", "boolean", "object", "array").
+type Schema struct {
+	Required []string
+	Types    map[string]string
+}
+
+func (s Schema) Validate(data string) error {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return &ValidationError{Fields: []FieldError{{Message: "data is not a JSON object"}}}
+	}
+	var fields []FieldError
+	for _, name := range s.Required {
+		if _, ok := doc[name]; !ok {
+			fields = append(fields, FieldError{Field: name, Message: "is required"})
+		}
+	}
+	for name, want := range s.Types {
+		v, ok := doc[name]
+		if !ok {
+			continue
+		}
+		if got := jsonType(v); got != want {
+			fields = append(fields, FieldError{Field: name, Message: fmt.Sprintf("must be %s, got %s", want, got)})
+		}
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,174 @@
+// Package abstractfactory - паттерн «Абстрактная фабрика»: семейства согласованных
+// элементов отчёта (HTML и простой текст). Отчёт собирается через ElementFactory
+// и не может случайно смешать элементы разных семейств.
+package abstractfactory
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+type Element interface {
+	Render() string
+}
+
+// ElementFactory создаёт все элементы одного семейства.
+type ElementFactory interface {
+	Heading(text string) Element
+	Paragraph(text string) Element
+	List(items []string) Element
+	Table(header []string, rows [][]string) Element
+	Document(title string, body []Element) Element
+}
+
+// Report - содержимое отчёта без привязки к формату.
+type Report struct {
+	Title   string
+	Summary string
+	Items   []string
+	Header  []string
+	Rows    [][]string
+}
+
+// Render строит отчёт элементами выбранного семейства.
+func Render(f ElementFactory, r Report) string {
+	body := []Element{f.Heading(r.Title), f.Paragraph(r.Summary)}
+	if len(r.Items) > 0 {
+		body = append(body, f.List(r.Items))
+	}
+	if len(r.Header) > 0 {
+		body = append(body, f.Table(r.Header, r.Rows))
+	}
+	return f.Document(r.Title, body).Render()
+}
+
+type renderFunc func() string
+
+func (fn renderFunc) Render() string { return fn() }
+
+// HTML - семейство элементов разметки HTML; текст экранируется.
+type HTML struct{}
+
+var _ ElementFactory = HTML{}
+
+func (HTML) Heading(text string) Element {
+	return renderFunc(func() string { return "<h1>" + html.EscapeString(text) + "</h1>" })
+}
+
+func (HTML) Paragraph(text string) Element {
+	return renderFunc(func() string { return "<p>" + html.EscapeString(text) + "</p>" })
+}
+
+func (HTML) List(items []string) Element {
+	return renderFunc(func() string {
+		var b strings.Builder
+		b.WriteString("<ul>")
+		for _, it := range items {
+			b.WriteString("<li>" + html.EscapeString(it) + "</li>")
+		}
+		b.WriteString("</ul>")
+		return b.String()
+	})
+}
+
+func (HTML) Table(header []string, rows [][]string) Element {
+	return renderFunc(func() string {
+		var b strings.Builder
+		b.WriteString("<table><tr>")
+		for _, h := range header {
+			b.WriteString("<th>" + html.EscapeString(h) + "</th>")
+		}
+		b.WriteString("</tr>")
+		for _, row := range rows {
+			b.WriteString("<tr>")
+			for _, c := range row {
+				b.WriteString("<td>" + html.EscapeString(c) + "</td>")
+			}
+			b.WriteString("</tr>")
+		}
+		b.WriteString("</table>")
+		return b.String()
+	})
+}
+
+func (HTML) Document(title string, body []Element) Element {
+	return renderFunc(func() string {
+		var b strings.Builder
+		b.WriteString("<html><head><title>" + html.EscapeString(title) + "</title></head><body>\n")
+		for _, e := range body {
+			b.WriteString(e.Render() + "\n")
+		}
+		b.WriteString("</body></html>")
+		return b.String()
+	})
+}
+
+// Text - семейство элементов для терминала: заголовок подчёркивается, таблица выравнивается.
+type Text struct{}
+
+var _ ElementFactory = Text{}
+
+func (Text) Heading(text string) Element {
+	return renderFunc(func() string { return text + "\n" + strings.Repeat("=", len([]rune(text))) })
+}
+
+func (Text) Paragraph(text string) Element {
+	return renderFunc(func() string { return text })
+}
+
+func (Text) List(items []string) Element {
+	return renderFunc(func() string {
+		lines := make([]string, len(items))
+		for i, it := range items {
+			lines[i] = "  * " + it
+		}
+		return strings.Join(lines, "\n")
+	})
+}
+
+func (Text) Table(header []string, rows [][]string) Element {
+	return renderFunc(func() string {
+		widths := make([]int, len(header))
+		for i, h := range header {
+			widths[i] = len([]rune(h))
+		}
+		for _, row := range rows {
+			for i, c := range row {
+				if i < len(widths) && len([]rune(c)) > widths[i] {
+					widths[i] = len([]rune(c))
+				}
+			}
+		}
+		line := func(cells []string) string {
+			parts := make([]string, len(widths))
+			for i := range widths {
+				var c string
+				if i < len(cells) {
+					c = cells[i]
+				}
+				parts[i] = fmt.Sprintf("%-*s", widths[i], c)
+			}
+			return strings.TrimRight(strings.Join(parts, " | "), " ")
+		}
+		sep := make([]string, len(widths))
+		for i, w := range widths {
+			sep[i] = strings.Repeat("-", w)
+		}
+		out := []string{line(header), strings.Join(sep, "-+-")}
+		for _, row := range rows {
+			out = append(out, line(row))
+		}
+		return strings.Join(out, "\n")
+	})
+}
+
+func (Text) Document(title string, body []Element) Element {
+	return renderFunc(func() string {
+		parts := make([]string, len(body))
+		for i, e := range body {
+			parts[i] = e.Render()
+		}
+		return strings.Join(parts, "\n\n")
+	})
+}
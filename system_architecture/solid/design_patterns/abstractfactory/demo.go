@@ -0,0 +1,43 @@
+package abstractfactory
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "abstractfactory", Summary: "families of HTML and text UI elements", Run: Demo})
+}
+
+// Demo печатает один и тот же отчёт в обоих форматах и проверяет, что HTML-вывод экранирован.
+func Demo(w io.Writer) error {
+	report := Report{
+		Title:   "Loans <weekly>",
+		Summary: "Top borrowed books for the week.",
+		Items:   []string{"3 new members", "1 overdue loan"},
+		Header:  []string{"Title", "Loans"},
+		Rows:    [][]string{{"Clean Code", "12"}, {"Refactoring", "7"}},
+	}
+	for _, f := range []struct {
+		name    string
+		factory ElementFactory
+	}{{"text", Text{}}, {"html", HTML{}}} {
+		fmt.Fprintf(w, "--- %s ---\n%s\n", f.name, Render(f.factory, report))
+	}
+	if out := Render(HTML{}, report); !containsAll(out, "<h1>Loans &lt;weekly&gt;</h1>", "<td>Clean Code</td>") {
+		return fmt.Errorf("abstractfactory: unexpected HTML output:\n%s", out)
+	}
+	return nil
+}
+
+func containsAll(s string, parts ...string) bool {
+	for _, p := range parts {
+		if !strings.Contains(s, p) {
+			return false
+		}
+	}
+	return true
+}
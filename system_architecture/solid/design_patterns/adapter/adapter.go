@@ -0,0 +1,125 @@
+// Package adapter - паттерн «Адаптер» между printf-логгером (data.Logger, *log.Logger)
+// и структурированным *slog.Logger в обе стороны.
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"solid/data"
+)
+
+// Printf адаптирует *slog.Logger к data.Logger: каждая строка становится записью уровня Level.
+type Printf struct {
+	Logger *slog.Logger
+	Level  slog.Level
+}
+
+var _ data.Logger = Printf{}
+
+func FromSlog(l *slog.Logger) Printf {
+	return Printf{Logger: l, Level: slog.LevelInfo}
+}
+
+func (p Printf) Printf(format string, args ...any) {
+	p.Logger.Log(context.Background(), p.Level, strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"))
+}
+
+// Handler адаптирует printf-логгер к slog.Handler, так что старый логгер
+// можно передать в код, ожидающий *slog.Logger.
+type Handler struct {
+	legacy data.Logger
+	level  slog.Leveler
+	attrs  []slog.Attr
+	group  string
+	mu     *sync.Mutex
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+func NewHandler(legacy data.Logger, level slog.Leveler) *Handler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &Handler{legacy: legacy, level: level, mu: &sync.Mutex{}}
+}
+
+// ToSlog - короткий способ получить *slog.Logger поверх printf-логгера.
+func ToSlog(legacy data.Logger) *slog.Logger {
+	return slog.New(NewHandler(legacy, nil))
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle печатает запись одной строкой: уровень, сообщение и атрибуты в виде key=value.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Level.String())
+	b.WriteString(" ")
+	b.WriteString(r.Message)
+	for _, a := range h.attrs {
+		writeAttr(&b, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.group, a)
+		return true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.legacy.Printf("%s", b.String())
+	return nil
+}
+
+func writeAttr(b *strings.Builder, group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	key := a.Key
+	if group != "" {
+		key = group + "." + key
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, key, ga)
+		}
+		return
+	}
+	fmt.Fprintf(b, " %s=%v", key, a.Value.Any())
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(append([]slog.Attr(nil), h.attrs...), qualify(h.group, attrs)...)
+	return &c
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	if c.group != "" {
+		c.group += "." + name
+	} else {
+		c.group = name
+	}
+	return &c
+}
+
+// qualify переносит текущую группу в ключи атрибутов, добавленных через WithAttrs.
+func qualify(group string, attrs []slog.Attr) []slog.Attr {
+	if group == "" {
+		return attrs
+	}
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = slog.Attr{Key: group + "." + a.Key, Value: a.Value}
+	}
+	return out
+}
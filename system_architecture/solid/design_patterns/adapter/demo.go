@@ -0,0 +1,41 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+
+	"solid/data"
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "adapter", Summary: "printf logger and slog adapted to each other", Run: Demo})
+}
+
+// Demo подключает slog к middleware data.Logging через адаптер и, наоборот,
+// пишет структурированные записи slog через старый *log.Logger.
+func Demo(w io.Writer) error {
+	structured := slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	dm := data.NewDataManager[string](data.NewDatabase())
+	dm.Use(data.Logging(FromSlog(structured.With("component", "data"))))
+	if err := dm.SaveData(context.Background(), "adapter/demo", "hello"); err != nil {
+		return err
+	}
+
+	legacy := log.New(w, "legacy: ", 0)
+	logger := ToSlog(legacy).With("component", "library")
+	logger.Info("book loaned", "book_id", "b1", slog.Group("member", "id", "m42"))
+	logger.Debug("filtered out by level")
+	fmt.Fprintln(w, "done")
+	return nil
+}
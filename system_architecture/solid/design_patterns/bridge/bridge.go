@@ -0,0 +1,140 @@
+// Package bridge - паттерн «Мост»: вид сообщения (абстракция) и канал доставки
+// (реализация) развиваются независимо. Новый канал не требует изменений в сообщениях,
+// новое сообщение - в каналах.
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var ErrNoRecipient = errors.New("bridge: no recipient")
+
+// Content - то, что канал умеет отправить: тема и текст.
+type Content struct {
+	Subject string
+	Body    string
+}
+
+// Sender - реализация моста: конкретный канал доставки.
+type Sender interface {
+	Send(ctx context.Context, to string, c Content) error
+}
+
+// Message - абстракция моста: сообщение само решает, как оформить содержание,
+// а доставку поручает Sender.
+type Message interface {
+	Deliver(ctx context.Context, to string) error
+}
+
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Critical
+)
+
+func (s Severity) String() string {
+	return [...]string{"INFO", "WARNING", "CRITICAL"}[s]
+}
+
+// Alert - короткое срочное сообщение.
+type Alert struct {
+	Sender   Sender
+	Severity Severity
+	Text     string
+}
+
+func (a Alert) Deliver(ctx context.Context, to string) error {
+	if to == "" {
+		return ErrNoRecipient
+	}
+	return a.Sender.Send(ctx, to, Content{
+		Subject: fmt.Sprintf("[%s] %s", a.Severity, a.Text),
+		Body:    a.Text,
+	})
+}
+
+// Report - периодическая сводка из строк ключ-значение.
+type Report struct {
+	Sender Sender
+	Title  string
+	Lines  [][2]string
+}
+
+func (r Report) Deliver(ctx context.Context, to string) error {
+	if to == "" {
+		return ErrNoRecipient
+	}
+	var b strings.Builder
+	for _, l := range r.Lines {
+		fmt.Fprintf(&b, "%s: %s\n", l[0], l[1])
+	}
+	return r.Sender.Send(ctx, to, Content{Subject: r.Title, Body: b.String()})
+}
+
+// Sent - запись об отправке, которую сохраняют фейковые каналы.
+type Sent struct {
+	Channel string
+	To      string
+	Payload string
+}
+
+// Outbox собирает отправленные сообщения фейковых каналов.
+type Outbox struct {
+	mu   sync.Mutex
+	sent []Sent
+}
+
+func (o *Outbox) add(s Sent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.sent = append(o.sent, s)
+}
+
+func (o *Outbox) Sent() []Sent {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Sent(nil), o.sent...)
+}
+
+// Email формирует письмо с темой и телом.
+type Email struct{ Outbox *Outbox }
+
+func (e Email) Send(ctx context.Context, to string, c Content) error {
+	if !strings.Contains(to, "@") {
+		return fmt.Errorf("bridge: email: invalid address %q", to)
+	}
+	e.Outbox.add(Sent{Channel: "email", To: to, Payload: "Subject: " + c.Subject + "\n\n" + c.Body})
+	return nil
+}
+
+// SMSLimit - длина одного SMS; длинный текст обрезается.
+const SMSLimit = 160
+
+// SMS отправляет только тему, уложенную в SMSLimit символов.
+type SMS struct{ Outbox *Outbox }
+
+func (s SMS) Send(ctx context.Context, to string, c Content) error {
+	text := []rune(c.Subject)
+	if len(text) > SMSLimit {
+		text = append(text[:SMSLimit-1], '…')
+	}
+	s.Outbox.add(Sent{Channel: "sms", To: to, Payload: string(text)})
+	return nil
+}
+
+// Webhook отправляет JSON-подобное тело на URL получателя.
+type Webhook struct{ Outbox *Outbox }
+
+func (h Webhook) Send(ctx context.Context, to string, c Content) error {
+	if !strings.HasPrefix(to, "https://") {
+		return fmt.Errorf("bridge: webhook: url must use https: %q", to)
+	}
+	h.Outbox.add(Sent{Channel: "webhook", To: to, Payload: fmt.Sprintf(`{"subject":%q,"body":%q}`, c.Subject, c.Body)})
+	return nil
+}
@@ -0,0 +1,47 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "bridge", Summary: "messages and delivery channels varied independently", Run: Demo})
+}
+
+// Demo отправляет оба вида сообщений через все каналы: 2 абстракции x 3 реализации
+// без единого класса вида «EmailAlert».
+func Demo(w io.Writer) error {
+	outbox := &Outbox{}
+	channels := []struct {
+		sender Sender
+		to     string
+	}{
+		{Email{outbox}, "librarian@example.com"},
+		{SMS{outbox}, "+10000000000"},
+		{Webhook{outbox}, "https://hooks.example.com/library"},
+	}
+	ctx := context.Background()
+	for _, ch := range channels {
+		messages := []Message{
+			Alert{Sender: ch.sender, Severity: Critical, Text: "Storage is unavailable"},
+			Report{Sender: ch.sender, Title: "Daily loans", Lines: [][2]string{{"loaned", "12"}, {"returned", "9"}}},
+		}
+		for _, m := range messages {
+			if err := m.Deliver(ctx, ch.to); err != nil {
+				return err
+			}
+		}
+	}
+	sent := outbox.Sent()
+	if len(sent) != 6 {
+		return fmt.Errorf("bridge: expected 6 deliveries, got %d", len(sent))
+	}
+	for _, s := range sent {
+		fmt.Fprintf(w, "%-7s %-34s %q\n", s.Channel, s.To, s.Payload)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Package builder - паттерн «Строитель» на примере SQL-подобного запроса.
+// Этапы построения разведены по типам: From доступен только после Select,
+// а Where/OrderBy/Limit - только после From, поэтому неполный запрос не соберётся при компиляции.
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalid = errors.New("builder: invalid query")
+
+// Dialect определяет вид плейсхолдеров: "?" или "$1, $2, ...".
+type Dialect int
+
+const (
+	Question Dialect = iota
+	Dollar
+)
+
+// SelectStage - запрос, у которого ещё нет таблицы.
+type SelectStage struct {
+	columns []string
+}
+
+// Select начинает построение; без колонок выбираются все (*).
+func Select(columns ...string) SelectStage {
+	return SelectStage{columns: append([]string(nil), columns...)}
+}
+
+func (s SelectStage) From(table string) *Query {
+	q := &Query{columns: s.columns, table: table}
+	if table == "" {
+		q.fail("empty table")
+	}
+	return q
+}
+
+type condition struct {
+	expr string
+	args []any
+}
+
+type order struct {
+	column string
+	desc   bool
+}
+
+// Query - запрос после From. Методы возвращают тот же *Query для цепочки вызовов;
+// первая ошибка запоминается и возвращается из Build.
+type Query struct {
+	columns []string
+	table   string
+	where   []condition
+	orders  []order
+	limit   int
+	offset  int
+	err     error
+}
+
+func (q *Query) fail(format string, args ...any) {
+	if q.err == nil {
+		q.err = fmt.Errorf("%w: %s", ErrInvalid, fmt.Sprintf(format, args...))
+	}
+}
+
+// Where добавляет условие, объединяемое с остальными через AND.
+// Число "?" в выражении должно совпадать с числом аргументов.
+func (q *Query) Where(expr string, args ...any) *Query {
+	if n := strings.Count(expr, "?"); n != len(args) {
+		q.fail("where %q expects %d args, got %d", expr, n, len(args))
+	}
+	q.where = append(q.where, condition{expr: expr, args: args})
+	return q
+}
+
+// WhereIn добавляет условие column IN (...); пустой список означает «ни одной строки».
+func (q *Query) WhereIn(column string, values ...any) *Query {
+	if len(values) == 0 {
+		return q.Where("1 = 0")
+	}
+	marks := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	return q.Where(column+" IN ("+marks+")", values...)
+}
+
+func (q *Query) OrderBy(column string) *Query {
+	q.orders = append(q.orders, order{column: column})
+	return q
+}
+
+func (q *Query) OrderByDesc(column string) *Query {
+	q.orders = append(q.orders, order{column: column, desc: true})
+	return q
+}
+
+func (q *Query) Limit(n int) *Query {
+	if n <= 0 {
+		q.fail("limit must be positive, got %d", n)
+	}
+	q.limit = n
+	return q
+}
+
+func (q *Query) Offset(n int) *Query {
+	if n < 0 {
+		q.fail("offset must not be negative, got %d", n)
+	}
+	q.offset = n
+	return q
+}
+
+// Build возвращает текст запроса и аргументы в порядке плейсхолдеров.
+func (q *Query) Build(d Dialect) (string, []any, error) {
+	if q.err != nil {
+		return "", nil, q.err
+	}
+	var b strings.Builder
+	var args []any
+	b.WriteString("SELECT ")
+	if len(q.columns) == 0 {
+		b.WriteString("*")
+	} else {
+		b.WriteString(strings.Join(q.columns, ", "))
+	}
+	b.WriteString(" FROM " + q.table)
+	if len(q.where) > 0 {
+		parts := make([]string, len(q.where))
+		for i, c := range q.where {
+			parts[i] = c.expr
+			if len(q.where) > 1 && strings.Contains(strings.ToUpper(c.expr), " OR ") {
+				parts[i] = "(" + c.expr + ")"
+			}
+			args = append(args, c.args...)
+		}
+		b.WriteString(" WHERE " + strings.Join(parts, " AND "))
+	}
+	if len(q.orders) > 0 {
+		parts := make([]string, len(q.orders))
+		for i, o := range q.orders {
+			parts[i] = o.column
+			if o.desc {
+				parts[i] += " DESC"
+			}
+		}
+		b.WriteString(" ORDER BY " + strings.Join(parts, ", "))
+	}
+	if q.limit > 0 {
+		b.WriteString(" LIMIT " + strconv.Itoa(q.limit))
+	}
+	if q.offset > 0 {
+		b.WriteString(" OFFSET " + strconv.Itoa(q.offset))
+	}
+	sql := b.String()
+	if d == Dollar {
+		sql = numberPlaceholders(sql)
+	}
+	return sql, args, nil
+}
+
+func numberPlaceholders(sql string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range sql {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package builder
+
+import (
+	"fmt"
+	"io"
+
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "builder", Summary: "fluent SQL query builder with dialects", Run: Demo})
+}
+
+// Demo строит несколько запросов и сверяет результат с ожидаемым текстом.
+func Demo(w io.Writer) error {
+	cases := []struct {
+		query *Query
+		d     Dialect
+		want  string
+	}{
+		{Select().From("books"), Question, "SELECT * FROM books"},
+		{
+			Select("id", "title").From("books").
+				Where("year >= ?", 2000).
+				Where("author = ? OR author = ?", "Fowler", "Martin").
+				OrderByDesc("year").OrderBy("title").
+				Limit(10).Offset(20),
+			Dollar,
+			"SELECT id, title FROM books WHERE year >= $1 AND (author = $2 OR author = $3) ORDER BY year DESC, title LIMIT 10 OFFSET 20",
+		},
+		{Select("id").From("loans").WhereIn("book_id", "b1", "b2"), Question, "SELECT id FROM loans WHERE book_id IN (?, ?)"},
+	}
+	for _, c := range cases {
+		sql, args, err := c.query.Build(c.d)
+		if err != nil {
+			return err
+		}
+		if sql != c.want {
+			return fmt.Errorf("builder: got %q, want %q", sql, c.want)
+		}
+		fmt.Fprintf(w, "%s %v\n", sql, args)
+	}
+	if _, _, err := Select().From("books").Where("id = ?").Limit(0).Build(Question); err != nil {
+		fmt.Fprintln(w, "rejected:", err)
+	}
+	return nil
+}
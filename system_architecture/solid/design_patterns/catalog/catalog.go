@@ -0,0 +1,94 @@
+// Package catalog - реестр демонстраций паттернов и самопроверок. Каждый пакет
+// design_patterns регистрирует свою демонстрацию в init, поэтому новый паттерн
+// появляется в cmd/patterns простым импортом, без правки списка.
+package catalog
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+type Demo struct {
+	Name    string
+	Summary string
+	Run     func(w io.Writer) error
+	// Unstable - вывод зависит от планировщика или рантайма, поэтому с эталоном
+	// не сравнивается; проверяется только успешное завершение.
+	Unstable bool
+}
+
+// Check - самопроверка: Run возвращает ошибку, если нарушено свойство, ради которого
+// написан пакет (однократная инициализация, отсутствие утечек горутин). Вывод
+// проверки поясняет результат, но с эталоном не сравнивается. patterns check
+// запускает проверки, в том числе под детектором гонок.
+type Check struct {
+	Name    string
+	Summary string
+	Run     func(w io.Writer) error
+}
+
+var (
+	mu     sync.RWMutex
+	demos  = map[string]Demo{}
+	checks = map[string]Check{}
+)
+
+// Register добавляет демонстрацию; повтор имени - ошибка программиста, поэтому паника.
+func Register(d Demo) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := demos[d.Name]; dup {
+		panic(fmt.Sprintf("catalog: demo %q registered twice", d.Name))
+	}
+	demos[d.Name] = d
+}
+
+func Lookup(name string) (Demo, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	d, ok := demos[name]
+	return d, ok
+}
+
+// All возвращает демонстрации по имени.
+func All() []Demo {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]Demo, 0, len(demos))
+	for _, d := range demos {
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// RegisterCheck добавляет самопроверку; повтор имени - паника, как у Register.
+func RegisterCheck(c Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := checks[c.Name]; dup {
+		panic(fmt.Sprintf("catalog: check %q registered twice", c.Name))
+	}
+	checks[c.Name] = c
+}
+
+func LookupCheck(name string) (Check, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := checks[name]
+	return c, ok
+}
+
+// Checks возвращает самопроверки по имени.
+func Checks() []Check {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]Check, 0, len(checks))
+	for _, c := range checks {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
@@ -0,0 +1,75 @@
+// Package chain - паттерн «Цепочка обязанностей»: запрос проходит через обработчики
+// (аутентификация, квота, проверка данных, сохранение), и любой из них может
+// остановить цепочку типизированным отказом Rejection.
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Rejection - отказ обработчика с HTTP-статусом и машиночитаемым кодом.
+type Rejection struct {
+	Status int
+	Code   string
+	Reason string
+}
+
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("chain: %s: %s", r.Code, r.Reason)
+}
+
+func reject(status int, code, format string, args ...any) *Rejection {
+	return &Rejection{Status: status, Code: code, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Request - данные, которые передаются по цепочке и дополняются обработчиками.
+type Request struct {
+	APIKey string
+	Caller string
+	Body   []byte
+	// Quote заполняет обработчик проверки данных, Saved - обработчик сохранения.
+	Quote Quote
+	Saved string
+}
+
+// Handler - звено цепочки; next вызывает следующее звено.
+type Handler interface {
+	Handle(ctx context.Context, req *Request, next func(context.Context, *Request) error) error
+}
+
+type HandlerFunc func(ctx context.Context, req *Request, next func(context.Context, *Request) error) error
+
+func (f HandlerFunc) Handle(ctx context.Context, req *Request, next func(context.Context, *Request) error) error {
+	return f(ctx, req, next)
+}
+
+// Chain - упорядоченный набор обработчиков; первый в списке выполняется первым.
+type Chain []Handler
+
+func (c Chain) Run(ctx context.Context, req *Request) error {
+	var step func(i int) func(context.Context, *Request) error
+	step = func(i int) func(context.Context, *Request) error {
+		return func(ctx context.Context, req *Request) error {
+			if i == len(c) {
+				return nil
+			}
+			return c[i].Handle(ctx, req, step(i+1))
+		}
+	}
+	return step(0)(ctx, req)
+}
+
+// StatusOf возвращает HTTP-статус ошибки цепочки: статус Rejection или 500.
+func StatusOf(err error) int {
+	var r *Rejection
+	if errors.As(err, &r) {
+		return r.Status
+	}
+	if err == nil {
+		return http.StatusOK
+	}
+	return http.StatusInternalServerError
+}
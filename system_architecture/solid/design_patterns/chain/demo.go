@@ -0,0 +1,66 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"solid/data"
+	"solid/design_patterns/catalog"
+	"solid/eventbus"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "chain", Summary: "pricing API as a chain of request handlers", Run: Demo})
+}
+
+// Demo отправляет в API цен запросы, каждый из которых останавливается на своём звене.
+// До шины доходят только расчёты, прошедшие всю цепочку.
+func Demo(w io.Writer) error {
+	bus := eventbus.New(eventbus.Config{})
+	defer bus.Close()
+	var announced []string
+	if _, err := eventbus.Subscribe(context.Background(), bus, QuotesSaved, func(ctx context.Context, e QuoteSaved) error {
+		announced = append(announced, fmt.Sprintf("%s=%g", e.Key, e.Quote.Total))
+		return nil
+	}); err != nil {
+		return err
+	}
+	api := PricingAPI{Chain: Chain{
+		Auth(map[string]string{"k-shop": "shop"}),
+		Quota(4, time.Minute),
+		ValidatePayload(),
+		Persist(data.NewDataManager[Quote](data.NewDatabase())),
+		Announce(bus, data.NopLogger{}),
+	}}
+	cases := []struct {
+		key, body string
+		want      int
+	}{
+		{"bad-key", `{"sku":"book-1","price":10}`, http.StatusUnauthorized},
+		{"k-shop", `{"sku":"book-1","price":-1}`, http.StatusBadRequest},
+		{"k-shop", `{"sku":"book-1","price":25,"discount":"holiday"}`, http.StatusCreated},
+		{"k-shop", `{"sku":"book-2","price":10,"discount":"regular"}`, http.StatusCreated},
+		{"k-shop", `{"sku":"book-2","price":10,"discount":"holiday"}`, http.StatusUnprocessableEntity},
+		{"k-shop", `{"sku":"book-3","price":10}`, http.StatusTooManyRequests},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(c.body))
+		r.Header.Set("X-API-Key", c.key)
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, r)
+		fmt.Fprintf(w, "%d %s", rec.Code, rec.Body.String())
+		if rec.Code != c.want {
+			return fmt.Errorf("chain: %s: got status %d, want %d", c.body, rec.Code, c.want)
+		}
+	}
+	fmt.Fprintf(w, "announced: %v\n", announced)
+	if len(announced) != 2 {
+		return fmt.Errorf("chain: announced %d quotes, want 2", len(announced))
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"solid/data"
+	"solid/design_patterns/specification"
+	"solid/design_patterns/strategy"
+	"solid/eventbus"
+)
+
+// Quote - расчёт цены со скидкой, который сохраняет API цен.
+type Quote struct {
+	SKU      string  `json:"sku"`
+	Price    float64 `json:"price"`
+	Discount string  `json:"discount"`
+	Total    float64 `json:"total"`
+}
+
+// DiscountRules - условия, при которых скидка доступна; скидка без правила доступна всем.
+var DiscountRules = map[string]specification.Spec[Quote]{
+	"holiday": MinPrice(20),
+}
+
+// MinPrice - скидка положена только на покупки не дешевле p.
+func MinPrice(p float64) specification.Spec[Quote] {
+	return specification.Func[Quote](func(q Quote) bool { return q.Price >= p })
+}
+
+// Auth сопоставляет API-ключ вызывающему.
+func Auth(keys map[string]string) Handler {
+	return HandlerFunc(func(ctx context.Context, req *Request, next func(context.Context, *Request) error) error {
+		caller, ok := keys[req.APIKey]
+		if !ok {
+			return reject(http.StatusUnauthorized, "unauthorized", "unknown API key")
+		}
+		req.Caller = caller
+		return next(ctx, req)
+	})
+}
+
+// Quota разрешает каждому вызывающему не больше limit запросов за окно window.
+func Quota(limit int, window time.Duration) Handler {
+	var (
+		mu     sync.Mutex
+		counts = map[string]int{}
+		reset  = time.Now().Add(window)
+	)
+	return HandlerFunc(func(ctx context.Context, req *Request, next func(context.Context, *Request) error) error {
+		mu.Lock()
+		if now := time.Now(); now.After(reset) {
+			counts, reset = map[string]int{}, now.Add(window)
+		}
+		counts[req.Caller]++
+		used := counts[req.Caller]
+		mu.Unlock()
+		if used > limit {
+			return reject(http.StatusTooManyRequests, "quota_exceeded", "%s used %d of %d requests", req.Caller, used, limit)
+		}
+		return next(ctx, req)
+	})
+}
+
+// ValidatePayload разбирает тело запроса и считает итоговую цену.
+func ValidatePayload() Handler {
+	return HandlerFunc(func(ctx context.Context, req *Request, next func(context.Context, *Request) error) error {
+		var q Quote
+		if err := json.Unmarshal(req.Body, &q); err != nil {
+			return reject(http.StatusBadRequest, "invalid_json", "%v", err)
+		}
+		if q.SKU == "" {
+			return reject(http.StatusBadRequest, "invalid_payload", "sku is required")
+		}
+		if q.Price <= 0 || math.IsInf(q.Price, 0) || math.IsNaN(q.Price) {
+			return reject(http.StatusBadRequest, "invalid_payload", "price must be positive")
+		}
+		if q.Discount == "" {
+			q.Discount = "none"
+		}
+		discount, err := strategy.Discounts.Lookup(q.Discount)
+		if err != nil {
+			return reject(http.StatusBadRequest, "invalid_payload", "unknown discount %q", q.Discount)
+		}
+		if rule, ok := DiscountRules[q.Discount]; ok && !rule.IsSatisfiedBy(q) {
+			return reject(http.StatusUnprocessableEntity, "not_eligible", "discount %q does not apply to this quote", q.Discount)
+		}
+		q.Total = discount(q.Price)
+		req.Quote = q
+		return next(ctx, req)
+	})
+}
+
+// Persist сохраняет расчёт через DataManager - последнее звено цепочки.
+func Persist(dm *data.DataManager[Quote]) Handler {
+	var seq int64
+	var mu sync.Mutex
+	return HandlerFunc(func(ctx context.Context, req *Request, next func(context.Context, *Request) error) error {
+		mu.Lock()
+		seq++
+		key := "quotes/" + req.Caller + "/" + strconv.FormatInt(seq, 10)
+		mu.Unlock()
+		if err := dm.SaveData(ctx, key, req.Quote); err != nil {
+			return fmt.Errorf("chain: persist quote: %w", err)
+		}
+		req.Saved = key
+		return next(ctx, req)
+	})
+}
+
+// QuoteSaved - событие о сохранённом расчёте для подписчиков шины.
+type QuoteSaved struct {
+	Key    string `json:"key"`
+	Caller string `json:"caller"`
+	Quote  Quote  `json:"quote"`
+}
+
+// QuotesSaved - тема шины с событиями QuoteSaved.
+var QuotesSaved = eventbus.NewTopic[QuoteSaved]("pricing.quote_saved")
+
+// Announce публикует сохранённый расчёт в шину; ставится после Persist.
+// Расчёт к этому моменту уже записан, поэтому сбой журнала шины только журналируется.
+func Announce(bus *eventbus.Bus, logger data.Logger) Handler {
+	return HandlerFunc(func(ctx context.Context, req *Request, next func(context.Context, *Request) error) error {
+		if err := eventbus.Publish(ctx, bus, QuotesSaved, QuoteSaved{Key: req.Saved, Caller: req.Caller, Quote: req.Quote}); err != nil {
+			logger.Printf("chain: announce %s: %v", req.Saved, err)
+		}
+		return next(ctx, req)
+	})
+}
+
+// PricingAPI - HTTP API цен: POST принимает Quote в теле и ключ в заголовке X-API-Key.
+type PricingAPI struct {
+	Chain Chain
+}
+
+func (a PricingAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := readBody(w, r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"code": "invalid_body", "error": err.Error()})
+		return
+	}
+	req := &Request{APIKey: r.Header.Get("X-API-Key"), Body: body}
+	if err := a.Chain.Run(r.Context(), req); err != nil {
+		resp := map[string]string{"error": err.Error()}
+		var rej *Rejection
+		if errors.As(err, &rej) {
+			resp = map[string]string{"code": rej.Code, "error": rej.Reason}
+		}
+		writeJSON(w, StatusOf(err), resp)
+		return
+	}
+	w.Header().Set("Location", "/"+req.Saved)
+	writeJSON(w, http.StatusCreated, req.Quote)
+}
+
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,180 @@
+// Package command - паттерн «Команда» для правок каталога: каждая операция над
+// library.Repository - объект с Execute/Undo, история поддерживает отмену и повтор,
+// а Macro объединяет несколько команд в одну атомарную правку.
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"solid/library"
+)
+
+var (
+	ErrNothingToUndo = errors.New("command: nothing to undo")
+	ErrNothingToRedo = errors.New("command: nothing to redo")
+)
+
+type Command interface {
+	Execute(ctx context.Context) error
+	Undo(ctx context.Context) error
+	Name() string
+}
+
+// AddBook добавляет книгу; после Execute в Book хранится присвоенный ID.
+// Повторное выполнение после отмены восстанавливает книгу под тем же ID.
+type AddBook struct {
+	Repo library.Repository
+	Book library.Book
+}
+
+func (c *AddBook) Name() string { return fmt.Sprintf("add %q", c.Book.Title) }
+
+func (c *AddBook) Execute(ctx context.Context) error {
+	added, err := c.Repo.Add(ctx, c.Book)
+	if err != nil {
+		return err
+	}
+	c.Book = added
+	return nil
+}
+
+func (c *AddBook) Undo(ctx context.Context) error {
+	return c.Repo.Delete(ctx, c.Book.ID)
+}
+
+// UpdateBook заменяет книгу и запоминает прежнюю версию для отмены.
+type UpdateBook struct {
+	Repo     library.Repository
+	Book     library.Book
+	previous library.Book
+}
+
+func (c *UpdateBook) Name() string { return fmt.Sprintf("update %s", c.Book.ID) }
+
+func (c *UpdateBook) Execute(ctx context.Context) error {
+	prev, err := c.Repo.Get(ctx, c.Book.ID)
+	if err != nil {
+		return err
+	}
+	if err := c.Repo.Update(ctx, c.Book); err != nil {
+		return err
+	}
+	c.previous = prev
+	return nil
+}
+
+func (c *UpdateBook) Undo(ctx context.Context) error {
+	return c.Repo.Update(ctx, c.previous)
+}
+
+// DeleteBook удаляет книгу и при отмене возвращает её с прежним ID.
+type DeleteBook struct {
+	Repo    library.Repository
+	ID      string
+	deleted library.Book
+}
+
+func (c *DeleteBook) Name() string { return fmt.Sprintf("delete %s", c.ID) }
+
+func (c *DeleteBook) Execute(ctx context.Context) error {
+	b, err := c.Repo.Get(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	if err := c.Repo.Delete(ctx, c.ID); err != nil {
+		return err
+	}
+	c.deleted = b
+	return nil
+}
+
+func (c *DeleteBook) Undo(ctx context.Context) error {
+	_, err := c.Repo.Add(ctx, c.deleted)
+	return err
+}
+
+// Macro выполняет команды по порядку; если одна из них падает, уже выполненные
+// отменяются в обратном порядке, и каталог остаётся как до макроса.
+type Macro struct {
+	Label    string
+	Commands []Command
+}
+
+func (m *Macro) Name() string { return m.Label }
+
+func (m *Macro) Execute(ctx context.Context) error {
+	for i, c := range m.Commands {
+		if err := c.Execute(ctx); err != nil {
+			if rerr := undoAll(ctx, m.Commands[:i]); rerr != nil {
+				return errors.Join(fmt.Errorf("command: %s: %w", c.Name(), err), rerr)
+			}
+			return fmt.Errorf("command: %s: %w", c.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (m *Macro) Undo(ctx context.Context) error {
+	return undoAll(ctx, m.Commands)
+}
+
+func undoAll(ctx context.Context, cmds []Command) error {
+	for i := len(cmds) - 1; i >= 0; i-- {
+		if err := cmds[i].Undo(ctx); err != nil {
+			return fmt.Errorf("command: undo %s: %w", cmds[i].Name(), err)
+		}
+	}
+	return nil
+}
+
+// History хранит выполненные и отменённые команды. Новая команда очищает стек повтора.
+type History struct {
+	done   []Command
+	undone []Command
+}
+
+func (h *History) Execute(ctx context.Context, c Command) error {
+	if err := c.Execute(ctx); err != nil {
+		return err
+	}
+	h.done = append(h.done, c)
+	h.undone = nil
+	return nil
+}
+
+func (h *History) Undo(ctx context.Context) (Command, error) {
+	if len(h.done) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	c := h.done[len(h.done)-1]
+	if err := c.Undo(ctx); err != nil {
+		return nil, err
+	}
+	h.done = h.done[:len(h.done)-1]
+	h.undone = append(h.undone, c)
+	return c, nil
+}
+
+func (h *History) Redo(ctx context.Context) (Command, error) {
+	if len(h.undone) == 0 {
+		return nil, ErrNothingToRedo
+	}
+	c := h.undone[len(h.undone)-1]
+	if err := c.Execute(ctx); err != nil {
+		return nil, err
+	}
+	h.undone = h.undone[:len(h.undone)-1]
+	h.done = append(h.done, c)
+	return c, nil
+}
+
+// Names возвращает выполненные команды от старой к новой.
+func (h *History) Names() []string {
+	names := make([]string, len(h.done))
+	for i, c := range h.done {
+		names[i] = c.Name()
+	}
+	return names
+}
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"solid/design_patterns/catalog"
+	"solid/library"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "command", Summary: "catalog edits with undo, redo and macros", Run: Demo})
+}
+
+func titles(ctx context.Context, repo library.Repository) []string {
+	page, _ := repo.List(ctx, library.PageRequest{SortBy: library.SortByTitle})
+	out := make([]string, len(page.Books))
+	for i, b := range page.Books {
+		out[i] = b.Title
+	}
+	return out
+}
+
+// Demo правит каталог командами, отменяет и повторяет правки и проверяет откат неудачного макроса.
+func Demo(w io.Writer) error {
+	ctx := context.Background()
+	repo := library.NewMemoryRepository()
+	var h History
+
+	add := &AddBook{Repo: repo, Book: library.Book{Title: "Clean Code", Author: "Robert C. Martin", Year: 2008}}
+	if err := h.Execute(ctx, add); err != nil {
+		return err
+	}
+	fixed := add.Book
+	fixed.Title = "Clean Code: A Handbook"
+	if err := h.Execute(ctx, &UpdateBook{Repo: repo, Book: fixed}); err != nil {
+		return err
+	}
+	batch := &Macro{Label: "import two books", Commands: []Command{
+		&AddBook{Repo: repo, Book: library.Book{Title: "Refactoring", Author: "Martin Fowler", Year: 1999}},
+		&AddBook{Repo: repo, Book: library.Book{Title: "The Pragmatic Programmer", Author: "Andrew Hunt", Year: 1999}},
+	}}
+	if err := h.Execute(ctx, batch); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "history %v\n  catalog %v\n", h.Names(), titles(ctx, repo))
+
+	for i := 0; i < 2; i++ {
+		c, err := h.Undo(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "undo %s\n  catalog %v\n", c.Name(), titles(ctx, repo))
+	}
+	c, err := h.Redo(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "redo %s\n  catalog %v\n", c.Name(), titles(ctx, repo))
+
+	broken := &Macro{Label: "broken batch", Commands: []Command{
+		&AddBook{Repo: repo, Book: library.Book{Title: "Domain-Driven Design", Author: "Eric Evans", Year: 2003}},
+		&DeleteBook{Repo: repo, ID: "missing"},
+	}}
+	err = h.Execute(ctx, broken)
+	fmt.Fprintf(w, "broken batch: %v\n  catalog %v\n", err, titles(ctx, repo))
+	if len(titles(ctx, repo)) != 1 {
+		return fmt.Errorf("command: broken macro was not rolled back")
+	}
+	return nil
+}
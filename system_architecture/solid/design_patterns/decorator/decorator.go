@@ -0,0 +1,145 @@
+// Package decorator - паттерн «Декоратор» для data.Storage. Каждая обёртка реализует
+// тот же интерфейс и добавляет одно поведение; Compose собирает их в нужном порядке.
+// Запись в хранилище оборачивается теми же middleware, что и в DataManager (data.Retry,
+// data.Logging, data.Metrics), чтобы поведение совпадало.
+package decorator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"solid/data"
+	"solid/resilience/retry"
+)
+
+type Decorator func(data.Storage) data.Storage
+
+// Compose оборачивает s так, что первый декоратор в списке оказывается внешним
+// и первым видит каждый вызов - как Use у DataManager и Chain в httpapi.
+func Compose(s data.Storage, decorators ...Decorator) data.Storage {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		s = decorators[i](s)
+	}
+	return s
+}
+
+// saveWrapper подменяет Save на обёрнутую middleware версию, остальные методы делегирует.
+type saveWrapper struct {
+	data.Storage
+	save data.SaveFunc
+}
+
+func (w saveWrapper) Save(ctx context.Context, key, value string) error {
+	return w.save(ctx, key, value)
+}
+
+// Retry повторяет неудачные записи и чтения (кроме ErrNotFound) с той же политикой.
+func Retry(attempts int, backoff time.Duration) Decorator {
+	p := retry.Policy{
+		MaxAttempts: max(attempts, 1),
+		Backoff:     retry.Exponential(backoff, 0),
+		Jitter:      retry.NoJitter,
+		Retryable: func(err error) bool {
+			return data.IsRetryable(err) && !errors.Is(err, data.ErrNotFound)
+		},
+	}
+	return func(s data.Storage) data.Storage {
+		return retrying{saveWrapper: saveWrapper{Storage: s, save: data.RetryPolicy(p)(s.Save)}, policy: p}
+	}
+}
+
+type retrying struct {
+	saveWrapper
+	policy retry.Policy
+}
+
+func (r retrying) Load(ctx context.Context, key string) (string, error) {
+	return retry.DoValue(ctx, r.policy, func(ctx context.Context) (string, error) { return r.Storage.Load(ctx, key) })
+}
+
+// Logging пишет в лог каждую запись и чтение.
+func Logging(logger data.Logger) Decorator {
+	return func(s data.Storage) data.Storage {
+		return logging{saveWrapper: saveWrapper{Storage: s, save: data.Logging(logger)(s.Save)}, logger: logger}
+	}
+}
+
+type logging struct {
+	saveWrapper
+	logger data.Logger
+}
+
+func (l logging) Load(ctx context.Context, key string) (string, error) {
+	v, err := l.Storage.Load(ctx, key)
+	l.logger.Printf("load %q: err=%v", key, err)
+	return v, err
+}
+
+// Stats дополняет data.Counters счётчиком чтений, дошедших до обёрнутого хранилища.
+type Stats struct {
+	data.Counters
+	Loads atomic.Int64
+}
+
+func Metrics(st *Stats) Decorator {
+	return func(s data.Storage) data.Storage {
+		return metrics{saveWrapper: saveWrapper{Storage: s, save: data.Metrics(&st.Counters)(s.Save)}, stats: st}
+	}
+}
+
+type metrics struct {
+	saveWrapper
+	stats *Stats
+}
+
+func (m metrics) Load(ctx context.Context, key string) (string, error) {
+	m.stats.Loads.Add(1)
+	return m.Storage.Load(ctx, key)
+}
+
+// Cache - кэш чтений в памяти: Load отдаёт сохранённое значение без обращения к хранилищу,
+// успешный Save обновляет кэш.
+func Cache() Decorator {
+	return func(s data.Storage) data.Storage {
+		return &cache{Storage: s, entries: make(map[string]string)}
+	}
+}
+
+type cache struct {
+	data.Storage
+	mu      sync.RWMutex
+	entries map[string]string
+}
+
+func (c *cache) Save(ctx context.Context, key, value string) error {
+	if err := c.Storage.Save(ctx, key, value); err != nil {
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+		return err
+	}
+	c.mu.Lock()
+	c.entries[key] = value
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *cache) Load(ctx context.Context, key string) (string, error) {
+	c.mu.RLock()
+	v, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok {
+		return v, nil
+	}
+	v, err := c.Storage.Load(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.entries[key] = v
+	c.mu.Unlock()
+	return v, nil
+}
@@ -0,0 +1,76 @@
+package decorator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"solid/data"
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "decorator", Summary: "retry, logging, metrics and cache around storage", Run: Demo})
+}
+
+// flaky отказывает на каждой второй записи, чтобы Retry было что повторять.
+type flaky struct {
+	data.Storage
+	calls int
+}
+
+func (f *flaky) Save(ctx context.Context, key, value string) error {
+	f.calls++
+	if f.calls%2 == 1 {
+		return fmt.Errorf("%w: flaky backend", data.ErrUnavailable)
+	}
+	return f.Storage.Save(ctx, key, value)
+}
+
+// run сохраняет ключ и трижды читает его через хранилище, собранное в заданном порядке.
+func run(ctx context.Context, s data.Storage) error {
+	if err := s.Save(ctx, "decorator/demo", "value"); err != nil {
+		return err
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := s.Load(ctx, "decorator/demo"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Demo собирает одни и те же декораторы в разном порядке и проверяет, как порядок
+// меняет наблюдаемое поведение.
+func Demo(w io.Writer) error {
+	ctx := context.Background()
+	logger := log.New(w, "  log: ", 0)
+
+	// Metrics снаружи Cache видит все чтения, а снаружи Retry - одну логическую запись.
+	var outer Stats
+	s := Compose(&flaky{Storage: data.NewDatabase()}, Metrics(&outer), Cache(), Retry(3, time.Millisecond), Logging(logger))
+	fmt.Fprintln(w, "metrics -> cache -> retry -> logging:")
+	if err := run(ctx, s); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "  saves=%d failures=%d loads=%d\n", outer.Saves.Load(), outer.Failures.Load(), outer.Loads.Load())
+	if outer.Saves.Load() != 1 || outer.Failures.Load() != 0 || outer.Loads.Load() != 3 {
+		return errors.New("decorator: unexpected counters for metrics-outermost order")
+	}
+
+	// Metrics внутри Retry видит каждую попытку, а внутри Cache - только промахи кэша.
+	var inner Stats
+	s = Compose(&flaky{Storage: data.NewDatabase()}, Logging(logger), Cache(), Retry(3, time.Millisecond), Metrics(&inner))
+	fmt.Fprintln(w, "logging -> cache -> retry -> metrics:")
+	if err := run(ctx, s); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "  saves=%d failures=%d loads=%d\n", inner.Saves.Load(), inner.Failures.Load(), inner.Loads.Load())
+	if inner.Saves.Load() != 1 || inner.Failures.Load() != 1 || inner.Loads.Load() != 0 {
+		return errors.New("decorator: unexpected counters for metrics-innermost order")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"solid/data"
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "factory", Summary: "storage backends created from configuration", Run: Demo})
+}
+
+// Demo создаёт хранилища по списку конфигураций и работает с ними через общий интерфейс.
+func Demo(w io.Writer) error {
+	configs := []Config{
+		{Kind: "memory"},
+		{Kind: "filesystem", Dir: filepath.Join(os.TempDir(), "solid-factory")},
+		{Kind: "filesystem"},
+		{Kind: "s3"},
+	}
+	fmt.Fprintln(w, "registered kinds:", Kinds())
+	ctx := context.Background()
+	for _, cfg := range configs {
+		storage, err := New(cfg)
+		if err != nil {
+			fmt.Fprintf(w, "%-10s -> %v\n", cfg.Kind, err)
+			continue
+		}
+		dm := data.NewDataManager[string](storage)
+		if err := dm.SaveData(ctx, "factory/demo", "created by "+cfg.Kind); err != nil {
+			return err
+		}
+		v, err := dm.LoadData(ctx, "factory/demo")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%-10s -> %T, loaded %q\n", cfg.Kind, storage, v)
+	}
+	return nil
+}
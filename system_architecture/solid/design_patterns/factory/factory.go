@@ -0,0 +1,62 @@
+// Package factory - порождающий паттерн «Фабричный метод» на примере хранилищ пакета data.
+// Клиентский код получает data.Storage по конфигурации и не знает, какой конструктор был вызван;
+// новый бэкенд добавляется регистрацией фабрики, без правки существующего кода (принцип O).
+package factory
+
+import (
+	"errors"
+	"fmt"
+
+	"solid/data"
+	"solid/design_patterns/strategy"
+)
+
+var (
+	ErrUnknownKind = errors.New("factory: unknown storage kind")
+	ErrInvalid     = errors.New("factory: invalid config")
+)
+
+// Config - описание бэкенда, как оно приходит из файла настроек или флагов.
+type Config struct {
+	Kind string            `json:"kind"`
+	Dir  string            `json:"dir,omitempty"`
+	Opts map[string]string `json:"opts,omitempty"`
+}
+
+// Creator - фабричный метод: создаёт конкретное хранилище по конфигурации.
+type Creator func(cfg Config) (data.Storage, error)
+
+var creators = strategy.NewRegistry[Creator]("storage")
+
+// Register добавляет фабрику для вида хранилища; повторная регистрация заменяет прежнюю.
+func Register(kind string, c Creator) {
+	creators.Register(kind, c)
+}
+
+func Kinds() []string {
+	return creators.Names()
+}
+
+// New выбирает фабрику по cfg.Kind и делегирует ей создание хранилища.
+func New(cfg Config) (data.Storage, error) {
+	c, err := creators.Lookup(cfg.Kind)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKind, cfg.Kind)
+	}
+	return c(cfg)
+}
+
+func init() {
+	Register("memory", func(Config) (data.Storage, error) {
+		return data.NewDatabase(), nil
+	})
+	Register("outbox", func(Config) (data.Storage, error) {
+		return data.NewOutboxDatabase(), nil
+	})
+	Register("filesystem", func(cfg Config) (data.Storage, error) {
+		if cfg.Dir == "" {
+			return nil, fmt.Errorf("%w: filesystem requires dir", ErrInvalid)
+		}
+		return data.NewFilesystem(cfg.Dir), nil
+	})
+}
@@ -0,0 +1,84 @@
+package flyweight
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "flyweight", Summary: "shared shape styles and their memory savings", Run: Demo})
+}
+
+// palette - несколько стилей, из которых собирается вся сцена.
+var palette = []Style{
+	{Stroke: Color{0, 0, 0, 255}, StrokeWidth: 1, Fill: Color{255, 255, 255, 255}, Font: "Inter", FontSize: 12},
+	{Stroke: Color{200, 0, 0, 255}, StrokeWidth: 2, Fill: Color{255, 230, 230, 255}, Font: "Inter", FontSize: 14, Dash: []float64{4, 2}},
+	{Stroke: Color{0, 0, 200, 255}, StrokeWidth: 1.5, Fill: Color{230, 230, 255, 255}, Font: "JetBrains Mono", FontSize: 11},
+}
+
+func heapAlloc() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// measure возвращает прирост кучи после build; результат build удерживается до замера.
+func measure(build func() any) (uint64, any) {
+	before := heapAlloc()
+	v := build()
+	after := heapAlloc()
+	if after < before {
+		return 0, v
+	}
+	return after - before, v
+}
+
+// minSaving - во сколько раз, не меньше, сцена с собственными стилями тяжелее сцены с
+// общими. Замер кучи от запуска к запуску немного плавает, поэтому в выводе не
+// килобайты, а проверка с запасом: сейчас разница около 2.5 раза.
+const minSaving = 2
+
+// Demo строит одну и ту же сцену с разделяемыми и с собственными стилями и сравнивает память.
+// Стили у наивных фигур создаются заново, как при чтении описания сцены из файла.
+func Demo(w io.Writer) error {
+	const n = 100_000
+	factory := NewStyleFactory()
+	shared, sharedScene := measure(func() any {
+		shapes := make([]Shape, n)
+		for i := range shapes {
+			st := palette[i%len(palette)]
+			st.Dash = append([]float64(nil), st.Dash...)
+			shapes[i] = Shape{Kind: "rect", X: float64(i), Y: float64(i), W: 10, H: 10, Style: factory.Get(st)}
+		}
+		return shapes
+	})
+	naive, naiveScene := measure(func() any {
+		shapes := make([]*NaiveShape, n)
+		for i := range shapes {
+			st := palette[i%len(palette)]
+			st.Dash = append([]float64(nil), st.Dash...)
+			shapes[i] = &NaiveShape{Kind: "rect", X: float64(i), Y: float64(i), W: 10, H: 10, Style: st}
+		}
+		return shapes
+	})
+	if factory.Len() != len(palette) {
+		return fmt.Errorf("flyweight: expected %d interned styles, got %d", len(palette), factory.Len())
+	}
+	var b strings.Builder
+	for _, s := range sharedScene.([]Shape)[:3] {
+		s.Render(&b)
+	}
+	fmt.Fprint(w, b.String())
+	runtime.KeepAlive(sharedScene)
+	runtime.KeepAlive(naiveScene)
+	if naive < minSaving*shared {
+		return fmt.Errorf("flyweight: per-shape styles take %d KiB, shared %d KiB: less than %dx saving", naive/1024, shared/1024, minSaving)
+	}
+	fmt.Fprintf(w, "%d shapes, %d shared styles: per-shape styles take at least %dx the memory\n", n, factory.Len(), minSaving)
+	return nil
+}
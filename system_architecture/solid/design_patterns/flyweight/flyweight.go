@@ -0,0 +1,79 @@
+// Package flyweight - паттерн «Приспособленец»: стили отрисовки (обводка, заливка, шрифт)
+// хранятся в единственном экземпляре и разделяются тысячами фигур. Фигура держит
+// только своё внешнее состояние - координаты - и указатель на общий стиль.
+package flyweight
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type Color struct{ R, G, B, A uint8 }
+
+// Style - внутреннее (разделяемое) состояние. Неизменяемо после создания,
+// поэтому один экземпляр безопасно использовать из разных горутин.
+type Style struct {
+	Stroke      Color
+	StrokeWidth float64
+	Fill        Color
+	Font        string
+	FontSize    float64
+	Dash        []float64
+}
+
+func (s Style) key() string {
+	return fmt.Sprintf("%v|%g|%v|%s|%g|%v", s.Stroke, s.StrokeWidth, s.Fill, s.Font, s.FontSize, s.Dash)
+}
+
+// StyleFactory интернирует стили: для равных описаний возвращается один и тот же *Style.
+type StyleFactory struct {
+	mu     sync.Mutex
+	styles map[string]*Style
+}
+
+func NewStyleFactory() *StyleFactory {
+	return &StyleFactory{styles: make(map[string]*Style)}
+}
+
+func (f *StyleFactory) Get(s Style) *Style {
+	k := s.key()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if shared, ok := f.styles[k]; ok {
+		return shared
+	}
+	s.Dash = append([]float64(nil), s.Dash...)
+	shared := &s
+	f.styles[k] = shared
+	return shared
+}
+
+func (f *StyleFactory) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.styles)
+}
+
+// Shape - фигура с разделяемым стилем.
+type Shape struct {
+	Kind  string
+	X, Y  float64
+	W, H  float64
+	Style *Style
+}
+
+// NaiveShape хранит копию стиля в каждой фигуре - для сравнения расхода памяти.
+type NaiveShape struct {
+	Kind  string
+	X, Y  float64
+	W, H  float64
+	Style Style
+}
+
+// Render выводит фигуру в SVG-подобном виде; стиль читается из общего экземпляра.
+func (s Shape) Render(b *strings.Builder) {
+	st := s.Style
+	fmt.Fprintf(b, `<%s x="%g" y="%g" w="%g" h="%g" stroke="#%02x%02x%02x" fill="#%02x%02x%02x" font="%s %gpx"/>`+"\n",
+		s.Kind, s.X, s.Y, s.W, s.H, st.Stroke.R, st.Stroke.G, st.Stroke.B, st.Fill.R, st.Fill.G, st.Fill.B, st.Font, st.FontSize)
+}
@@ -0,0 +1,57 @@
+package iterator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"solid/design_patterns/catalog"
+	"solid/library"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "iterator", Summary: "paged and streaming catalog iterators", Run: Demo})
+}
+
+// countingRepo считает обращения к List, чтобы показать постраничную загрузку.
+type countingRepo struct {
+	library.Repository
+	calls int
+}
+
+func (r *countingRepo) List(ctx context.Context, req library.PageRequest) (library.Page, error) {
+	r.calls++
+	return r.Repository.List(ctx, req)
+}
+
+// Demo обходит каталог из 1000 книг страницами по 50 и останавливается на первых находках,
+// затем потоково читает книги из JSON.
+func Demo(w io.Writer) error {
+	ctx := context.Background()
+	mem := library.NewMemoryRepository()
+	for i := 0; i < 1000; i++ {
+		if _, err := mem.Add(ctx, library.Book{Title: fmt.Sprintf("Book %04d", i), Author: "Author", Year: 1900 + i%120}); err != nil {
+			return err
+		}
+	}
+	repo := &countingRepo{Repository: mem}
+	var err error
+	books := Values(Books(ctx, repo, library.PageRequest{Limit: 50, SortBy: library.SortByTitle}), &err)
+	for b := range Take(Filter(books, func(b library.Book) bool { return b.Year == 2000 }), 3) {
+		fmt.Fprintf(w, "found %s (%d)\n", b.Title, b.Year)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "loaded %d pages instead of the whole catalog\n", repo.calls)
+
+	snapshot := `{"authors": [], "books": [{"id": "1", "title": "Go", "author": "Pike", "year": 2015}, {"id": "2", "title": "Unix", "author": "Kernighan", "year": 2019}]}`
+	for b, err := range FileBooks(strings.NewReader(snapshot)) {
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "streamed %s %q\n", b.ID, b.Title)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Package iterator - паттерн «Итератор» на итераторах Go 1.23 (range-over-func).
+// Каталог обходится по мере чтения: постранично из репозитория или потоково из файла,
+// без загрузки всех книг в память.
+package iterator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+
+	"solid/library"
+)
+
+// Books обходит репозиторий страницами по req.Limit книг. Ошибка выдаётся последним
+// элементом пары, после неё обход завершается.
+func Books(ctx context.Context, repo library.Repository, req library.PageRequest) iter.Seq2[library.Book, error] {
+	return func(yield func(library.Book, error) bool) {
+		for {
+			page, err := repo.List(ctx, req)
+			if err != nil {
+				yield(library.Book{}, err)
+				return
+			}
+			for _, b := range page.Books {
+				if !yield(b, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			req.Cursor = page.NextCursor
+		}
+	}
+}
+
+// FileBooks потоково читает книги из JSON: массива книг или снимка FileRepository
+// ({"books": [...]}). В памяти одновременно находится только одна книга.
+func FileBooks(r io.Reader) iter.Seq2[library.Book, error] {
+	return func(yield func(library.Book, error) bool) {
+		dec := json.NewDecoder(r)
+		if err := seekArray(dec); err != nil {
+			yield(library.Book{}, err)
+			return
+		}
+		for dec.More() {
+			var b library.Book
+			if err := dec.Decode(&b); err != nil {
+				yield(library.Book{}, fmt.Errorf("iterator: decode book: %w", err))
+				return
+			}
+			if !yield(b, nil) {
+				return
+			}
+		}
+	}
+}
+
+// seekArray устанавливает декодер на начало массива книг.
+func seekArray(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("iterator: %w", err)
+	}
+	switch tok {
+	case json.Delim('['):
+		return nil
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return fmt.Errorf("iterator: %w", err)
+			}
+			if key == "books" {
+				if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+					return fmt.Errorf("iterator: \"books\" is not an array")
+				}
+				return nil
+			}
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("iterator: %w", err)
+			}
+		}
+	}
+	return fmt.Errorf("iterator: no array of books found")
+}
+
+// Values отбрасывает ошибки, сохраняя первую в *err, - для удобного range по iter.Seq.
+func Values(seq iter.Seq2[library.Book, error], err *error) iter.Seq[library.Book] {
+	return func(yield func(library.Book) bool) {
+		for b, e := range seq {
+			if e != nil {
+				*err = e
+				return
+			}
+			if !yield(b) {
+				return
+			}
+		}
+	}
+}
+
+func Filter[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if keep(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func Take[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			if i++; i == n {
+				return
+			}
+		}
+	}
+}
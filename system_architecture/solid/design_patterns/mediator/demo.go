@@ -0,0 +1,91 @@
+package mediator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"solid/design_patterns/catalog"
+	"solid/eventbus"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "mediator", Summary: "coordinator for scanner, printer and mailer", Run: Demo})
+}
+
+// Demo выполняет три задания: успешное, с нехваткой бумаги и с отсутствующим оригиналом.
+func Demo(w io.Writer) error {
+	c := NewJobCoordinator(map[string]Document{
+		"contract": {Name: "contract", Pages: []string{"p1", "p2"}},
+	}, 5)
+	// Распознавание страниц идёт параллельно, и поздние страницы готовы раньше ранних,
+	// но в документ они попадают по порядку.
+	c.Scanner.Workers = 2
+	c.Scanner.Process = func(ctx context.Context, page string) (string, error) {
+		if page == "p1" {
+			time.Sleep(5 * time.Millisecond)
+		}
+		return strings.ToUpper(page), nil
+	}
+	// Наблюдатель считает сбои устройств, не вмешиваясь в логику посредника.
+	failures := 0
+	ctx := context.Background()
+	if _, err := eventbus.Subscribe(ctx, c.Events, DeviceEvents, func(ctx context.Context, e DeviceEvent) error {
+		if e.Error != "" {
+			failures++
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	// Табло получает по сводке на устройство, сколько бы событий оно ни прислало; в
+	// демонстрации тишина не наступает, и сводки уходят по FlushStatus.
+	c.StatusDelay = time.Minute
+	var (
+		boardMu sync.Mutex
+		board   []DeviceStatus
+	)
+	if _, err := eventbus.Subscribe(ctx, c.Events, DeviceStatuses, func(ctx context.Context, st DeviceStatus) error {
+		boardMu.Lock()
+		defer boardMu.Unlock()
+		board = append(board, st)
+		return nil
+	}); err != nil {
+		return err
+	}
+	jobs := []Job{
+		{ID: "job-1", Source: "contract", Copies: 2, Email: "ann@example.com"},
+		{ID: "job-2", Source: "contract", Copies: 3, Email: "bob@example.com"},
+		{ID: "job-3", Source: "missing", Copies: 1},
+	}
+	for _, j := range jobs {
+		r := c.Submit(ctx, j)
+		fmt.Fprintf(w, "%s printed=%v emailed=%v errors=%v\n", r.JobID, r.Printed, r.Emailed, r.Errors)
+	}
+	fmt.Fprintln(w, "event log:")
+	for _, l := range c.Log {
+		fmt.Fprintln(w, " ", l)
+	}
+	fmt.Fprintf(w, "mail: %v\n", c.Mailer.Sent)
+	fmt.Fprintf(w, "device failures observed: %d\n", failures)
+	c.FlushStatus()
+	boardMu.Lock()
+	defer boardMu.Unlock()
+	fmt.Fprintln(w, "status board:")
+	for _, st := range board {
+		fmt.Fprintf(w, "  %s: %d events, %d failures, last %s %s\n", st.Device, st.Events, st.Failures, st.JobID, st.Last)
+	}
+	if len(c.Printer.Out) != 2 || c.Printer.Paper != 1 {
+		return fmt.Errorf("mediator: unexpected printer state %d copies, %d sheets left", len(c.Printer.Out), c.Printer.Paper)
+	}
+	if got := c.Printer.Out[0]; got != "contract: P1 | P2" {
+		return fmt.Errorf("mediator: printed %q, want pages in scan order", got)
+	}
+	if failures != 2 {
+		return fmt.Errorf("mediator: observed %d device failures, want 2", failures)
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+// Package mediator - паттерн «Посредник»: JobCoordinator управляет сканером, принтером
+// и уведомлениями в сценарии «скопировать и отправить». Устройства знают только
+// интерфейс Mediator и сообщают ему о событиях; друг о друге они не знают.
+package mediator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"solid/concurrency/fan"
+	"solid/concurrency/flow"
+	"solid/eventbus"
+)
+
+type EventKind string
+
+const (
+	JobRequested EventKind = "job_requested"
+	JobFailed    EventKind = "job_failed"
+	Scanned      EventKind = "scanned"
+	Printed      EventKind = "printed"
+	PrintFailed  EventKind = "print_failed"
+	Notified     EventKind = "notified"
+	NotifyFailed EventKind = "notify_failed"
+)
+
+// Event - сообщение компонента посреднику.
+type Event struct {
+	Kind  EventKind
+	JobID string
+	Doc   Document
+	Err   error
+}
+
+// DeviceEvent - событие устройства для внешних наблюдателей. В отличие от Event
+// оно сериализуется, поэтому шина с журналом может его хранить и повторять.
+type DeviceEvent struct {
+	Kind   EventKind `json:"kind"`
+	JobID  string    `json:"job_id"`
+	Device string    `json:"device"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// DeviceEvents - тема шины, в которую посредник публикует события устройств.
+var DeviceEvents = eventbus.NewTopic[DeviceEvent]("devices")
+
+// DeviceStatus - сводка по устройству для табло. Табло важно последнее состояние,
+// а не каждый шаг, поэтому сводки одного устройства сливаются: публикуется
+// последняя, когда устройство StatusDelay ничего не сообщает.
+type DeviceStatus struct {
+	Device   string    `json:"device"`
+	JobID    string    `json:"job_id"`
+	Last     EventKind `json:"last"`
+	Events   int       `json:"events"`
+	Failures int       `json:"failures"`
+}
+
+// DeviceStatuses - тема шины со сводками устройств.
+var DeviceStatuses = eventbus.NewTopic[DeviceStatus]("device-status")
+
+const DefaultStatusDelay = 50 * time.Millisecond
+
+type Document struct {
+	Name  string
+	Pages []string
+}
+
+// Mediator - единственное, что видят компоненты.
+type Mediator interface {
+	Notify(ctx context.Context, from Component, e Event)
+}
+
+type Component interface {
+	Name() string
+}
+
+// Job - задание «скопировать N экземпляров и отправить скан на почту».
+type Job struct {
+	ID     string
+	Source string
+	Copies int
+	Email  string
+}
+
+type Scanner struct {
+	M Mediator
+	// Originals - документы в лотке сканера по имени.
+	Originals map[string]Document
+	// Process, если задан, обрабатывает каждую отсканированную страницу, например
+	// распознаёт текст. Страницы идут в Workers горутин, а в документ попадают в
+	// исходном порядке; ошибка любой страницы проваливает задание.
+	Process func(ctx context.Context, page string) (string, error)
+	Workers int
+}
+
+func (s *Scanner) Name() string { return "scanner" }
+
+func (s *Scanner) Scan(ctx context.Context, jobID, source string) {
+	doc, ok := s.Originals[source]
+	if !ok {
+		s.M.Notify(ctx, s, Event{Kind: JobFailed, JobID: jobID, Err: fmt.Errorf("scanner: no document %q", source)})
+		return
+	}
+	if s.Process != nil {
+		pages, err := s.process(ctx, doc.Pages)
+		if err != nil {
+			s.M.Notify(ctx, s, Event{Kind: JobFailed, JobID: jobID, Err: err})
+			return
+		}
+		doc = Document{Name: doc.Name, Pages: pages}
+	}
+	s.M.Notify(ctx, s, Event{Kind: Scanned, JobID: jobID, Doc: doc})
+}
+
+type page struct {
+	text string
+	err  error
+}
+
+func (s *Scanner) process(ctx context.Context, pages []string) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	in := make(chan string)
+	go func() {
+		defer close(in)
+		for _, p := range pages {
+			select {
+			case in <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	out := make([]string, 0, len(pages))
+	for p := range fan.Parallel(ctx, in, s.Workers, func(ctx context.Context, text string) page {
+		text, err := s.Process(ctx, text)
+		return page{text: text, err: err}
+	}) {
+		if p.err != nil {
+			return nil, fmt.Errorf("scanner: page %d: %w", len(out)+1, p.err)
+		}
+		out = append(out, p.text)
+	}
+	return out, ctx.Err()
+}
+
+type Printer struct {
+	M     Mediator
+	Paper int
+	Out   []string
+}
+
+func (p *Printer) Name() string { return "printer" }
+
+func (p *Printer) Print(ctx context.Context, jobID string, doc Document, copies int) {
+	need := len(doc.Pages) * copies
+	if need > p.Paper {
+		p.M.Notify(ctx, p, Event{Kind: PrintFailed, JobID: jobID, Doc: doc,
+			Err: fmt.Errorf("printer: need %d sheets, have %d", need, p.Paper)})
+		return
+	}
+	p.Paper -= need
+	for i := 0; i < copies; i++ {
+		p.Out = append(p.Out, doc.Name+": "+strings.Join(doc.Pages, " | "))
+	}
+	p.M.Notify(ctx, p, Event{Kind: Printed, JobID: jobID, Doc: doc})
+}
+
+type Mailer struct {
+	M    Mediator
+	Sent []string
+}
+
+func (m *Mailer) Name() string { return "mailer" }
+
+func (m *Mailer) Send(ctx context.Context, jobID, to string, doc Document) {
+	if !strings.Contains(to, "@") {
+		m.M.Notify(ctx, m, Event{Kind: NotifyFailed, JobID: jobID, Err: fmt.Errorf("mailer: invalid address %q", to)})
+		return
+	}
+	m.Sent = append(m.Sent, fmt.Sprintf("%s <- %s (%d pages)", to, doc.Name, len(doc.Pages)))
+	m.M.Notify(ctx, m, Event{Kind: Notified, JobID: jobID, Doc: doc})
+}
+
+// Result - итог задания, который собирает посредник.
+type Result struct {
+	JobID   string
+	Printed bool
+	Emailed bool
+	Errors  []error
+}
+
+// JobCoordinator - посредник. Вся логика сценария (что делать после скана,
+// как реагировать на ошибку печати) сосредоточена здесь.
+type JobCoordinator struct {
+	Scanner *Scanner
+	Printer *Printer
+	Mailer  *Mailer
+	Log     []string
+	// Events получает каждое событие устройств в теме DeviceEvents; без подписчиков это пустая шина.
+	Events *eventbus.Bus
+	// StatusDelay - сколько тишины ждёт сводка устройства перед публикацией в
+	// DeviceStatuses, по умолчанию DefaultStatusDelay.
+	StatusDelay time.Duration
+
+	mu       sync.Mutex
+	jobs     map[string]Job
+	results  map[string]*Result
+	statuses map[string]*DeviceStatus
+	pending  map[string]*flow.Debouncer[DeviceStatus]
+}
+
+var _ Mediator = (*JobCoordinator)(nil)
+
+// NewJobCoordinator создаёт посредника и подключает к нему устройства.
+func NewJobCoordinator(originals map[string]Document, paper int) *JobCoordinator {
+	c := &JobCoordinator{jobs: map[string]Job{}, results: map[string]*Result{}, Events: eventbus.New(eventbus.Config{}),
+		statuses: map[string]*DeviceStatus{}, pending: map[string]*flow.Debouncer[DeviceStatus]{}}
+	c.Scanner = &Scanner{M: c, Originals: originals}
+	c.Printer = &Printer{M: c, Paper: paper}
+	c.Mailer = &Mailer{M: c}
+	return c
+}
+
+// Submit запускает задание и возвращает его итог.
+func (c *JobCoordinator) Submit(ctx context.Context, job Job) Result {
+	c.mu.Lock()
+	c.jobs[job.ID] = job
+	c.results[job.ID] = &Result{JobID: job.ID}
+	c.mu.Unlock()
+	c.Notify(ctx, nil, Event{Kind: JobRequested, JobID: job.ID})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return *c.results[job.ID]
+}
+
+func (c *JobCoordinator) Notify(ctx context.Context, from Component, e Event) {
+	c.mu.Lock()
+	job := c.jobs[e.JobID]
+	res := c.results[e.JobID]
+	name := "coordinator"
+	if from != nil {
+		name = from.Name()
+	}
+	c.Log = append(c.Log, fmt.Sprintf("%s: %s %s", e.JobID, name, e.Kind))
+	de := DeviceEvent{Kind: e.Kind, JobID: e.JobID, Device: name}
+	st := c.status(name)
+	st.JobID, st.Last = e.JobID, e.Kind
+	st.Events++
+	if e.Err != nil {
+		res.Errors = append(res.Errors, e.Err)
+		de.Error = e.Err.Error()
+		st.Failures++
+	}
+	status, pending := *st, c.pending[name]
+	c.mu.Unlock()
+	pending.Call(status)
+	if err := eventbus.Publish(ctx, c.Events, DeviceEvents, de); err != nil {
+		c.mu.Lock()
+		c.Log = append(c.Log, fmt.Sprintf("%s: coordinator: %v", e.JobID, err))
+		c.mu.Unlock()
+	}
+
+	switch e.Kind {
+	case JobRequested:
+		c.Scanner.Scan(ctx, job.ID, job.Source)
+	case Scanned:
+		// Печать и отправка независимы: сбой принтера не мешает отправить скан.
+		if job.Copies > 0 {
+			c.Printer.Print(ctx, job.ID, e.Doc, job.Copies)
+		}
+		if job.Email != "" {
+			c.Mailer.Send(ctx, job.ID, job.Email, e.Doc)
+		}
+	case Printed:
+		c.mu.Lock()
+		res.Printed = true
+		c.mu.Unlock()
+	case Notified:
+		c.mu.Lock()
+		res.Emailed = true
+		c.mu.Unlock()
+	case PrintFailed:
+		// Если печать не удалась, пользователь узнаёт об этом письмом, если адрес указан.
+		if job.Email != "" {
+			c.Mailer.Send(ctx, job.ID, job.Email, Document{Name: "print failed: " + e.Doc.Name})
+		}
+	}
+}
+
+// status - сводка устройства; вызывается под mu.
+func (c *JobCoordinator) status(device string) *DeviceStatus {
+	if st, ok := c.statuses[device]; ok {
+		return st
+	}
+	st := &DeviceStatus{Device: device}
+	c.statuses[device] = st
+	delay := c.StatusDelay
+	if delay <= 0 {
+		delay = DefaultStatusDelay
+	}
+	// Сводка публикуется уже после Notify, поэтому со своим контекстом.
+	c.pending[device] = flow.Debounce(delay, func(st DeviceStatus) {
+		if err := eventbus.Publish(context.Background(), c.Events, DeviceStatuses, st); err != nil {
+			c.mu.Lock()
+			c.Log = append(c.Log, fmt.Sprintf("%s: coordinator: %v", st.Device, err))
+			c.mu.Unlock()
+		}
+	})
+	return st
+}
+
+// FlushStatus публикует отложенные сводки сейчас, по устройствам в алфавитном порядке.
+func (c *JobCoordinator) FlushStatus() {
+	c.mu.Lock()
+	devices := slices.Sorted(maps.Keys(c.pending))
+	pending := make([]*flow.Debouncer[DeviceStatus], len(devices))
+	for i, d := range devices {
+		pending[i] = c.pending[d]
+	}
+	c.mu.Unlock()
+	for _, p := range pending {
+		p.Flush()
+	}
+}
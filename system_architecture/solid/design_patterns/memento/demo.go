@@ -0,0 +1,62 @@
+package memento
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"solid/data"
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "memento", Summary: "editor snapshots with bounded, persisted history", Run: Demo})
+}
+
+// Demo редактирует текст, откатывается по снимкам, показывает вытеснение
+// и восстанавливает историю из файлового хранилища, как после перезапуска.
+func Demo(w io.Writer) error {
+	ed := &Editor{}
+	h := NewHistory(3)
+	for _, word := range []string{"Hello", ", world", "!", " Bye."} {
+		h.Push(ed.Save("before " + word))
+		ed.Type(word)
+	}
+	fmt.Fprintf(w, "text: %s (snapshots %d, evicted %d)\n", ed, h.Len(), h.Evicted())
+
+	dir, err := os.MkdirTemp("", "solid-memento")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	archive := Archive{DM: data.NewDataManager[Memento](data.NewFilesystem(filepath.Join(dir, "history"))), Doc: "letter"}
+	ctx := context.Background()
+	if err := archive.Store(ctx, h); err != nil {
+		return err
+	}
+
+	m, err := h.Pop()
+	if err != nil {
+		return err
+	}
+	ed.Restore(m)
+	fmt.Fprintf(w, "undo %q: %s\n", m.Label, ed)
+
+	restored, err := archive.Load(ctx, 3)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "restored %d snapshots from storage\n", restored.Len())
+	fresh := &Editor{}
+	for restored.Len() > 0 {
+		m, _ := restored.Pop()
+		fresh.Restore(m)
+		fmt.Fprintf(w, "  %q: %s\n", m.Label, fresh)
+	}
+	if fresh.Text() != "Hello" {
+		return fmt.Errorf("memento: oldest kept snapshot should read %q, got %q", "Hello", fresh.Text())
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Package memento - паттерн «Снимок»: редактор документа сохраняет состояние в снимки,
+// история хранит ограниченное их число, а Archive сохраняет снимки через DataManager,
+// чтобы история переживала перезапуск.
+package memento
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"solid/data"
+)
+
+var ErrNoSnapshot = errors.New("memento: no snapshot")
+
+// Memento - непрозрачный для хранителя снимок. Поля экспортированы только
+// ради сериализации; History и Archive их не читают.
+type Memento struct {
+	Label   string    `json:"label"`
+	Text    string    `json:"text"`
+	Cursor  int       `json:"cursor"`
+	TakenAt time.Time `json:"taken_at"`
+}
+
+// Editor - создатель снимков: текст и позиция курсора.
+type Editor struct {
+	text   []rune
+	cursor int
+}
+
+func (e *Editor) Type(s string) {
+	r := []rune(s)
+	e.text = append(e.text[:e.cursor], append(r, e.text[e.cursor:]...)...)
+	e.cursor += len(r)
+}
+
+func (e *Editor) Backspace(n int) {
+	n = min(n, e.cursor)
+	e.text = append(e.text[:e.cursor-n], e.text[e.cursor:]...)
+	e.cursor -= n
+}
+
+func (e *Editor) MoveTo(pos int) {
+	e.cursor = max(0, min(pos, len(e.text)))
+}
+
+func (e *Editor) Text() string { return string(e.text) }
+
+// String показывает текст с курсором "|".
+func (e *Editor) String() string {
+	return string(e.text[:e.cursor]) + "|" + string(e.text[e.cursor:])
+}
+
+func (e *Editor) Save(label string) Memento {
+	return Memento{Label: label, Text: string(e.text), Cursor: e.cursor, TakenAt: time.Now()}
+}
+
+func (e *Editor) Restore(m Memento) {
+	e.text = []rune(m.Text)
+	e.MoveTo(m.Cursor)
+}
+
+// History - хранитель с ограниченной ёмкостью: при переполнении вытесняется самый старый снимок.
+type History struct {
+	limit     int
+	snapshots []Memento
+	evicted   int
+}
+
+func NewHistory(limit int) *History {
+	if limit <= 0 {
+		limit = 1
+	}
+	return &History{limit: limit}
+}
+
+func (h *History) Push(m Memento) {
+	if len(h.snapshots) == h.limit {
+		h.snapshots = append(h.snapshots[:0], h.snapshots[1:]...)
+		h.evicted++
+	}
+	h.snapshots = append(h.snapshots, m)
+}
+
+func (h *History) Pop() (Memento, error) {
+	if len(h.snapshots) == 0 {
+		return Memento{}, ErrNoSnapshot
+	}
+	m := h.snapshots[len(h.snapshots)-1]
+	h.snapshots = h.snapshots[:len(h.snapshots)-1]
+	return m, nil
+}
+
+func (h *History) Len() int     { return len(h.snapshots) }
+func (h *History) Evicted() int { return h.evicted }
+
+// Archive сохраняет историю документа через DataManager: каждый снимок - отдельный ключ
+// "<doc>/<номер>", поэтому подходит любое хранилище пакета data.
+type Archive struct {
+	DM  *data.DataManager[Memento]
+	Doc string
+}
+
+func (a Archive) key(i int) string {
+	return fmt.Sprintf("%s/%06d", a.Doc, i)
+}
+
+// Store записывает все снимки истории по порядку.
+func (a Archive) Store(ctx context.Context, h *History) error {
+	for i, m := range h.snapshots {
+		if err := a.DM.SaveData(ctx, a.key(i), m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load восстанавливает историю ёмкостью limit; при большем числе снимков остаются последние.
+func (a Archive) Load(ctx context.Context, limit int) (*History, error) {
+	records, err := a.DM.ListData(ctx, a.Doc+"/")
+	if err != nil {
+		return nil, err
+	}
+	h := NewHistory(limit)
+	for _, r := range records {
+		h.Push(r.Value)
+	}
+	return h, nil
+}
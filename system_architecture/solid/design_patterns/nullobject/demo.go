@@ -0,0 +1,74 @@
+package nullobject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"solid/data"
+	"solid/design_patterns/catalog"
+	"solid/library"
+	"solid/library/inventory"
+	"solid/library/lending"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "nullobject", Summary: "no-op storage, logger, event bus and metrics as defaults", Run: Demo})
+}
+
+type recorder struct {
+	events []any
+}
+
+func (r *recorder) Publish(ctx context.Context, e any) { r.events = append(r.events, e) }
+
+// Demo запускает один и тот же код с настоящими зависимостями и без них, а также
+// пользуется конструкторами пакетов library, lending и data, принимающими nil.
+func Demo(w io.Writer) error {
+	ctx := context.Background()
+	var counters data.Counters
+	bus := &recorder{}
+	full := New(Deps{
+		Storage: data.NewDatabase(),
+		Logger:  log.New(w, "  log: ", 0),
+		Events:  bus,
+		Metrics: &counters,
+	})
+	fmt.Fprintln(w, "with dependencies:")
+	if err := full.Export(ctx, "notes/1", "hello"); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "  events %d, saves %d\n", len(bus.events), counters.Saves.Load())
+
+	// Без зависимостей ничего не пишется и не публикуется, но и паники на nil нет.
+	bare := New(Deps{})
+	if err := bare.Export(ctx, "notes/1", "hello"); err != nil {
+		return err
+	}
+	if _, err := data.NewDataManager[string](data.NopStorage{}).LoadData(ctx, "notes/1"); !errors.Is(err, data.ErrNotFound) {
+		return fmt.Errorf("nullobject: NopStorage load: %v", err)
+	}
+	fmt.Fprintln(w, "without dependencies: export ok, NopStorage load reports not found")
+
+	// Конструкторы с nil вместо шины событий тоже подставляют NopEventBus.
+	books := library.WithEvents(library.NewMemoryRepository(), nil)
+	b, err := books.Add(ctx, library.Book{Title: "Patterns of Enterprise Application Architecture", Author: "Fowler"})
+	if err != nil {
+		return err
+	}
+	copies := inventory.NewMemoryStore()
+	if _, err := inventory.NewService(copies, books).AddCopy(ctx, inventory.Copy{BookID: b.ID, Branch: "main"}); err != nil {
+		return err
+	}
+	loan, err := lending.NewService(lending.NewMemoryStore(), copies, nil).Loan(ctx, b.ID, "ann")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "loaned %q without an event bus: active=%v\n", b.Title, loan.Active())
+	if len(bus.events) != 1 || counters.Saves.Load() != 1 {
+		return fmt.Errorf("nullobject: bare exporter leaked into real dependencies")
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package nullobject - паттерн «Пустой объект». Необязательная зависимость
+// (хранилище, логгер, шина событий, метрики) заменяется реализацией, которая ничего
+// не делает, поэтому код пользуется ею без проверок на nil. Сами реализации
+// живут рядом с интерфейсами: data.NopStorage, data.NopLogger, data.NopEventBus, data.NopMetrics.
+package nullobject
+
+import (
+	"context"
+
+	"solid/data"
+)
+
+// Exporter выгружает заметки. Все зависимости необязательны: New подставляет пустые объекты,
+// и Export написан так, будто они заданы всегда.
+type Exporter struct {
+	storage data.Storage
+	log     data.Logger
+	events  data.Publisher
+	metrics data.MetricsRecorder
+}
+
+// Exported - событие о выгруженной записи.
+type Exported struct {
+	Key string
+}
+
+type Deps struct {
+	Storage data.Storage
+	Logger  data.Logger
+	Events  data.Publisher
+	Metrics data.MetricsRecorder
+}
+
+func New(d Deps) *Exporter {
+	e := &Exporter{storage: d.Storage, log: d.Logger, events: d.Events, metrics: d.Metrics}
+	if e.storage == nil {
+		e.storage = data.NopStorage{}
+	}
+	if e.log == nil {
+		e.log = data.NopLogger{}
+	}
+	if e.events == nil {
+		e.events = data.NopEventBus{}
+	}
+	if e.metrics == nil {
+		e.metrics = data.NopMetrics{}
+	}
+	return e
+}
+
+func (e *Exporter) Export(ctx context.Context, key, body string) error {
+	err := e.storage.Save(ctx, key, body)
+	e.metrics.RecordSave(len(body), err)
+	if err != nil {
+		e.log.Printf("export %s: %v", key, err)
+		return err
+	}
+	e.log.Printf("exported %s (%d bytes)", key, len(body))
+	e.events.Publish(ctx, Exported{Key: key})
+	return nil
+}
@@ -0,0 +1,87 @@
+package observer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"solid/design_patterns/catalog"
+	"solid/library"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "observer", Summary: "typed event bus with async delivery and panic isolation", Run: Demo})
+}
+
+// EventBus[any] подходит как порт публикации событий каталога.
+var _ library.Publisher = (*EventBus[any])(nil)
+
+// Demo подписывает наблюдателей на события каталога: синхронный журнал, асинхронный
+// счётчик и «сломанный» подписчик, чья паника не мешает остальным. Затем журнал отписывается.
+func Demo(w io.Writer) error {
+	var mu sync.Mutex
+	var panics []error
+	bus := New[any](WithAsync(8), WithPanicHandler(func(r any) {
+		mu.Lock()
+		panics = append(panics, PanicError(r))
+		mu.Unlock()
+	}))
+
+	var journal []string
+	unsubscribe := bus.Subscribe(func(ctx context.Context, e any) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch e := e.(type) {
+		case library.BookAdded:
+			journal = append(journal, "added "+e.Book.Title)
+		case library.BookDeleted:
+			journal = append(journal, "deleted "+e.ID)
+		}
+	})
+	added := 0
+	bus.Subscribe(func(ctx context.Context, e any) {
+		if _, ok := e.(library.BookAdded); ok {
+			mu.Lock()
+			added++
+			mu.Unlock()
+		}
+	})
+	bus.Subscribe(func(ctx context.Context, e any) {
+		if _, ok := e.(library.BookDeleted); ok {
+			panic("broken subscriber")
+		}
+	})
+
+	ctx := context.Background()
+	books := library.WithEvents(library.NewMemoryRepository(), bus)
+	var ids []string
+	for _, title := range []string{"Dune", "Solaris", "Neuromancer"} {
+		b, err := books.Add(ctx, library.Book{Title: title, Author: "unknown"})
+		if err != nil {
+			return err
+		}
+		ids = append(ids, b.ID)
+	}
+	if err := books.Delete(ctx, ids[0]); err != nil {
+		return err
+	}
+	// Отмена ждёт, пока журнал обработает уже принятые события; следующее удаление он не увидит.
+	unsubscribe()
+	if err := books.Delete(ctx, ids[1]); err != nil {
+		return err
+	}
+	bus.Close()
+
+	for _, line := range journal {
+		fmt.Fprintln(w, "journal:", line)
+	}
+	fmt.Fprintf(w, "books added: %d, subscribers left: %d\n", added, bus.Len())
+	for _, err := range panics {
+		fmt.Fprintln(w, "isolated:", err)
+	}
+	if len(journal) != 4 || added != 3 || len(panics) != 2 {
+		return fmt.Errorf("observer: got %d journal lines, %d additions, %d panics", len(journal), added, len(panics))
+	}
+	return nil
+}
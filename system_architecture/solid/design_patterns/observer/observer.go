@@ -0,0 +1,161 @@
+// Package observer - паттерн «Наблюдатель» в виде обобщённой шины EventBus[T].
+// В отличие от library/events.Bus, тема задаётся параметром типа, подписку можно
+// отменить, а доставка бывает синхронной или асинхронной через буфер подписчика.
+package observer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type Handler[T any] func(ctx context.Context, event T)
+
+type config struct {
+	async   bool
+	buffer  int
+	onPanic func(recovered any)
+}
+
+type Option func(*config)
+
+// WithAsync доставляет события в отдельной горутине каждого подписчика через буфер
+// размером buffer. Когда буфер полон, Publish ждёт - медленный подписчик притормаживает издателя.
+func WithAsync(buffer int) Option {
+	return func(c *config) {
+		c.async = true
+		c.buffer = buffer
+	}
+}
+
+// WithPanicHandler получает панику подписчика; по умолчанию паника просто подавляется,
+// чтобы не помешать остальным подписчикам.
+func WithPanicHandler(fn func(recovered any)) Option {
+	return func(c *config) {
+		c.onPanic = fn
+	}
+}
+
+type delivery[T any] struct {
+	ctx   context.Context
+	event T
+}
+
+type subscriber[T any] struct {
+	fn    Handler[T]
+	queue chan delivery[T]
+	done  chan struct{}
+}
+
+type EventBus[T any] struct {
+	cfg    config
+	mu     sync.RWMutex
+	subs   map[int]*subscriber[T]
+	next   int
+	closed bool
+}
+
+func New[T any](opts ...Option) *EventBus[T] {
+	b := &EventBus[T]{subs: make(map[int]*subscriber[T])}
+	for _, opt := range opts {
+		opt(&b.cfg)
+	}
+	return b
+}
+
+// Subscribe регистрирует обработчик и возвращает функцию отмены подписки.
+// В асинхронном режиме отмена дожидается обработки уже принятых событий.
+func (b *EventBus[T]) Subscribe(fn Handler[T]) (unsubscribe func()) {
+	s := &subscriber[T]{fn: fn}
+	if b.cfg.async {
+		s.queue = make(chan delivery[T], b.cfg.buffer)
+		s.done = make(chan struct{})
+		go b.loop(s)
+	}
+	b.mu.Lock()
+	id := b.next
+	b.next++
+	b.subs[id] = s
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			_, ok := b.subs[id]
+			delete(b.subs, id)
+			b.mu.Unlock()
+			if ok {
+				b.stop(s)
+			}
+		})
+	}
+}
+
+func (b *EventBus[T]) loop(s *subscriber[T]) {
+	defer close(s.done)
+	for d := range s.queue {
+		b.call(s, d.ctx, d.event)
+	}
+}
+
+func (b *EventBus[T]) stop(s *subscriber[T]) {
+	if s.queue != nil {
+		close(s.queue)
+		<-s.done
+	}
+}
+
+// call изолирует панику подписчика от издателя и других подписчиков.
+func (b *EventBus[T]) call(s *subscriber[T], ctx context.Context, event T) {
+	defer func() {
+		if r := recover(); r != nil && b.cfg.onPanic != nil {
+			b.cfg.onPanic(r)
+		}
+	}()
+	s.fn(ctx, event)
+}
+
+// Publish доставляет событие всем текущим подписчикам. Для EventBus[any] метод
+// совпадает с library.Publisher, так что шину можно передать в library.WithEvents.
+func (b *EventBus[T]) Publish(ctx context.Context, event T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, s := range b.subs {
+		if s.queue == nil {
+			b.call(s, ctx, event)
+			continue
+		}
+		select {
+		case s.queue <- delivery[T]{ctx: ctx, event: event}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Close отписывает всех и дожидается доставки принятых асинхронных событий.
+func (b *EventBus[T]) Close() {
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = make(map[int]*subscriber[T])
+	b.closed = true
+	b.mu.Unlock()
+	for _, s := range subs {
+		b.stop(s)
+	}
+}
+
+func (b *EventBus[T]) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// PanicError оформляет значение паники как ошибку для журналирования.
+func PanicError(recovered any) error {
+	return fmt.Errorf("observer: subscriber panicked: %v", recovered)
+}
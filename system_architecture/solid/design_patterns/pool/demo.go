@@ -0,0 +1,75 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "pool", Summary: "bounded pool of connections and PNG encoder buffers", Run: Demo})
+}
+
+// conn - условное соединение с базой: открывать его дорого, а сеть может его оборвать.
+type conn struct {
+	id     int
+	broken bool
+}
+
+// Demo проходит по жизни пула соединений: ожидание при заполненном пуле, отбраковка
+// сломанного соединения, вытеснение простоявшего. Пул буферов PNG см. в library/labels.
+func Demo(w io.Writer) error {
+	opened := 0
+	clock := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	p := New(Config[*conn]{
+		New: func(context.Context) (*conn, error) {
+			opened++
+			return &conn{id: opened}, nil
+		},
+		Check: func(c *conn) error {
+			if c.broken {
+				return errors.New("connection reset")
+			}
+			return nil
+		},
+		Destroy:     func(c *conn) { fmt.Fprintf(w, "close conn %d\n", c.id) },
+		MaxSize:     2,
+		IdleTimeout: time.Minute,
+	})
+	p.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	a, _ := p.Get(ctx)
+	b, _ := p.Get(ctx)
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	_, err := p.Get(waitCtx)
+	cancel()
+	fmt.Fprintf(w, "third checkout while full: %v\n", errors.Is(err, context.DeadlineExceeded))
+	p.Put(a)
+	p.Put(b)
+
+	c, _ := p.Get(ctx)
+	fmt.Fprintf(w, "reused conn %d\n", c.id)
+	c.broken = true
+	p.Put(c)
+	c, _ = p.Get(ctx)
+	fmt.Fprintf(w, "after health check got conn %d\n", c.id)
+	p.Put(c)
+
+	clock = clock.Add(2 * time.Minute)
+	c, _ = p.Get(ctx)
+	fmt.Fprintf(w, "after idle timeout got conn %d\n", c.id)
+	p.Put(c)
+	p.Close()
+	s := p.Stats()
+	fmt.Fprintf(w, "connections: created %d, reused %d, invalid %d, expired %d, waits %d\n",
+		s.Created, s.Reused, s.Invalid, s.Expired, s.Waits)
+	if s.Created != 3 || s.Reused != 2 || s.Invalid != 1 || s.Expired != 1 || s.Waits != 1 || s.Destroyed != 3 {
+		return fmt.Errorf("pool: unexpected stats %+v", s)
+	}
+	return nil
+}
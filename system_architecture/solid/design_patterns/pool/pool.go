@@ -0,0 +1,219 @@
+// Package pool - паттерн «Объектный пул»: дорогие ресурсы (соединения, буферы)
+// создаются не чаще, чем нужно, и переиспользуются. Pool ограничивает число объектов,
+// выбрасывает простоявшие дольше IdleTimeout и проверяет здоровье при выдаче.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrClosed = errors.New("pool: closed")
+
+// Config описывает, как создавать, проверять и уничтожать объекты. New обязателен.
+type Config[T any] struct {
+	New func(ctx context.Context) (T, error)
+	// Check вызывается при выдаче простаивавшего объекта; ошибка - объект уничтожается.
+	Check func(T) error
+	// Destroy освобождает объект, выпавший из пула.
+	Destroy func(T)
+	// MaxSize - предел объектов, выданных и простаивающих вместе; 0 - без предела.
+	MaxSize int
+	// IdleTimeout - сколько объект может простаивать; 0 - сколько угодно.
+	IdleTimeout time.Duration
+}
+
+// Stats - счётчики пула на момент вызова.
+type Stats struct {
+	Created   int64
+	Reused    int64
+	Destroyed int64
+	Invalid   int64
+	Expired   int64
+	// Waits - сколько раз Get ждал освобождения объекта из-за MaxSize.
+	Waits int64
+	InUse int
+	Idle  int
+}
+
+type idle[T any] struct {
+	v     T
+	since time.Time
+}
+
+type Pool[T any] struct {
+	cfg Config[T]
+	// slots ограничивает число живых объектов; nil - без предела.
+	slots chan struct{}
+	now   func() time.Time
+
+	mu     sync.Mutex
+	idle   []idle[T]
+	stats  Stats
+	closed bool
+}
+
+func New[T any](cfg Config[T]) *Pool[T] {
+	p := &Pool[T]{cfg: cfg, now: time.Now}
+	if cfg.MaxSize > 0 {
+		p.slots = make(chan struct{}, cfg.MaxSize)
+	}
+	return p
+}
+
+// Get выдаёт простаивающий объект или создаёт новый. Если пул заполнен,
+// Get ждёт возврата объекта или отмены ctx.
+func (p *Pool[T]) Get(ctx context.Context) (T, error) {
+	var zero T
+	if err := p.acquire(ctx); err != nil {
+		return zero, err
+	}
+	for {
+		v, ok, err := p.popIdle()
+		if err != nil {
+			p.release()
+			return zero, err
+		}
+		if !ok {
+			break
+		}
+		if p.cfg.Check != nil {
+			if err := p.cfg.Check(v); err != nil {
+				p.count(func(s *Stats) { s.Invalid++ })
+				p.destroy(v)
+				continue
+			}
+		}
+		p.count(func(s *Stats) { s.Reused++; s.InUse++ })
+		return v, nil
+	}
+	v, err := p.cfg.New(ctx)
+	if err != nil {
+		p.release()
+		return zero, fmt.Errorf("pool: create: %w", err)
+	}
+	p.count(func(s *Stats) { s.Created++; s.InUse++ })
+	return v, nil
+}
+
+// Put возвращает исправный объект в пул.
+func (p *Pool[T]) Put(v T) {
+	p.mu.Lock()
+	p.stats.InUse--
+	if p.closed {
+		p.mu.Unlock()
+		p.destroy(v)
+		p.release()
+		return
+	}
+	p.idle = append(p.idle, idle[T]{v: v, since: p.now()})
+	p.mu.Unlock()
+	p.release()
+}
+
+// Discard уничтожает объект вместо возврата, например соединение после сетевой ошибки.
+func (p *Pool[T]) Discard(v T) {
+	p.count(func(s *Stats) { s.InUse-- })
+	p.destroy(v)
+	p.release()
+}
+
+// Close уничтожает простаивающие объекты; выданные уничтожатся при возврате.
+func (p *Pool[T]) Close() {
+	p.mu.Lock()
+	items := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+	for _, it := range items {
+		p.destroy(it.v)
+	}
+}
+
+func (p *Pool[T]) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Idle = len(p.idle)
+	return s
+}
+
+func (p *Pool[T]) acquire(ctx context.Context) error {
+	if p.slots == nil {
+		return nil
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	p.count(func(s *Stats) { s.Waits++ })
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("pool: wait: %w", ctx.Err())
+	}
+}
+
+// release не блокируется: лишний Put без Get не должен подвесить вызывающего.
+func (p *Pool[T]) release() {
+	if p.slots == nil {
+		return
+	}
+	select {
+	case <-p.slots:
+	default:
+	}
+}
+
+// popIdle берёт последний возвращённый объект (он «теплее»), по пути выбрасывая просроченные.
+func (p *Pool[T]) popIdle() (T, bool, error) {
+	var zero T
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return zero, false, ErrClosed
+	}
+	var expired []T
+	if p.cfg.IdleTimeout > 0 {
+		cutoff := p.now().Add(-p.cfg.IdleTimeout)
+		fresh := p.idle[:0]
+		for _, it := range p.idle {
+			if it.since.Before(cutoff) {
+				expired = append(expired, it.v)
+				continue
+			}
+			fresh = append(fresh, it)
+		}
+		p.idle = fresh
+		p.stats.Expired += int64(len(expired))
+	}
+	var v T
+	ok := len(p.idle) > 0
+	if ok {
+		v = p.idle[len(p.idle)-1].v
+		p.idle = p.idle[:len(p.idle)-1]
+	}
+	p.mu.Unlock()
+	for _, e := range expired {
+		p.destroy(e)
+	}
+	return v, ok, nil
+}
+
+func (p *Pool[T]) destroy(v T) {
+	if p.cfg.Destroy != nil {
+		p.cfg.Destroy(v)
+	}
+	p.count(func(s *Stats) { s.Destroyed++ })
+}
+
+func (p *Pool[T]) count(fn func(*Stats)) {
+	p.mu.Lock()
+	fn(&p.stats)
+	p.mu.Unlock()
+}
@@ -0,0 +1,61 @@
+package prototype
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "prototype", Summary: "deep and shallow copies from a prototype registry", Run: Demo})
+}
+
+func template() *Document {
+	return &Document{
+		Title: "Monthly report",
+		Tags:  []string{"report"},
+		Meta:  map[string]string{"lang": "ru"},
+		Sections: []Section{
+			{Heading: "Summary", Paragraphs: []string{"TBD"}},
+		},
+		Author:  &Author{Name: "Library", Emails: []string{"library@example.com"}},
+		Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// mutate меняет каждое вложенное поле копии.
+func mutate(d *Document) {
+	d.Title = "March report"
+	d.Tags[0] = "draft"
+	d.Meta["lang"] = "en"
+	d.Sections[0].Paragraphs[0] = "Loans grew by 10%"
+	d.Author.Emails[0] = "someone@example.com"
+}
+
+// Demo изменяет глубокую и поверхностную копии и проверяет, что прототип пострадал только во втором случае.
+func Demo(w io.Writer) error {
+	registry := NewRegistry[*Document]()
+	registry.Register("report", template())
+
+	deep, _ := registry.New("report")
+	mutate(deep)
+	proto, _ := registry.New("report")
+	if proto.Tags[0] != "report" || proto.Meta["lang"] != "ru" ||
+		proto.Sections[0].Paragraphs[0] != "TBD" || proto.Author.Emails[0] != "library@example.com" {
+		return fmt.Errorf("prototype: deep clone leaked changes into the prototype: %+v", proto)
+	}
+	fmt.Fprintf(w, "deep copy:    prototype keeps tag=%s lang=%s paragraph=%q\n",
+		proto.Tags[0], proto.Meta["lang"], proto.Sections[0].Paragraphs[0])
+
+	original := template()
+	shallow := original.ShallowCopy()
+	mutate(shallow)
+	fmt.Fprintf(w, "shallow copy: original now has title=%q but tag=%s lang=%s paragraph=%q\n",
+		original.Title, original.Tags[0], original.Meta["lang"], original.Sections[0].Paragraphs[0])
+	if original.Tags[0] != "draft" {
+		return fmt.Errorf("prototype: expected shallow copy to share tags")
+	}
+	return nil
+}
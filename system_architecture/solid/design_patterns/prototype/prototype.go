@@ -0,0 +1,94 @@
+// Package prototype - паттерн «Прототип»: новые документы создаются копированием шаблона.
+// Копия обязана быть глубокой - иначе срезы и карты остаются общими с прототипом.
+package prototype
+
+import "time"
+
+// Cloner - объект, умеющий создавать независимую копию самого себя.
+type Cloner[T any] interface {
+	Clone() T
+}
+
+type Section struct {
+	Heading    string
+	Paragraphs []string
+}
+
+type Document struct {
+	Title    string
+	Tags     []string
+	Meta     map[string]string
+	Sections []Section
+	Author   *Author
+	Created  time.Time
+}
+
+type Author struct {
+	Name   string
+	Emails []string
+}
+
+var _ Cloner[*Document] = (*Document)(nil)
+
+// Clone копирует документ целиком, включая вложенные срезы, карты и автора.
+func (d *Document) Clone() *Document {
+	if d == nil {
+		return nil
+	}
+	c := *d
+	c.Tags = cloneSlice(d.Tags)
+	if d.Meta != nil {
+		c.Meta = make(map[string]string, len(d.Meta))
+		for k, v := range d.Meta {
+			c.Meta[k] = v
+		}
+	}
+	if d.Sections != nil {
+		c.Sections = make([]Section, len(d.Sections))
+		for i, s := range d.Sections {
+			c.Sections[i] = Section{Heading: s.Heading, Paragraphs: cloneSlice(s.Paragraphs)}
+		}
+	}
+	if d.Author != nil {
+		a := Author{Name: d.Author.Name, Emails: cloneSlice(d.Author.Emails)}
+		c.Author = &a
+	}
+	return &c
+}
+
+// ShallowCopy - антипример: копируется только верхний уровень структуры.
+func (d *Document) ShallowCopy() *Document {
+	c := *d
+	return &c
+}
+
+// cloneSlice сохраняет разницу между nil и пустым срезом.
+func cloneSlice[E any](s []E) []E {
+	if s == nil {
+		return nil
+	}
+	return append(make([]E, 0, len(s)), s...)
+}
+
+// Registry хранит именованные прототипы и выдаёт их копии.
+type Registry[T Cloner[T]] struct {
+	prototypes map[string]T
+}
+
+func NewRegistry[T Cloner[T]]() *Registry[T] {
+	return &Registry[T]{prototypes: make(map[string]T)}
+}
+
+func (r *Registry[T]) Register(name string, proto T) {
+	r.prototypes[name] = proto
+}
+
+// New возвращает копию прототипа; изменения копии не затрагивают зарегистрированный шаблон.
+func (r *Registry[T]) New(name string) (T, bool) {
+	p, ok := r.prototypes[name]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return p.Clone(), true
+}
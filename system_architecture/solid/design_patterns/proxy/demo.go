@@ -0,0 +1,61 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"solid/data"
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "proxy", Summary: "lazy and access-checking proxies for storage", Run: Demo})
+}
+
+// Demo собирает заместители поверх одной базы: защищающий снаружи, виртуальный внутри,
+// так что отклонённые запросы даже не открывают хранилище.
+func Demo(w io.Writer) error {
+	lazy := NewLazy(func(ctx context.Context) (data.Storage, error) {
+		fmt.Fprintln(w, "opening the real storage")
+		return data.NewDatabase(), nil
+	})
+	guard := NewGuard(lazy, Policy{
+		"librarian": {"books": Read | Write, "loans": Read | Write},
+		"reader":    {"books": Read},
+		"admin":     {"*": Read | Write},
+	})
+	dm := data.NewDataManager[string](guard)
+
+	reader := data.WithCaller(context.Background(), "reader")
+	err := dm.SaveData(reader, "books/1", "Clean Code")
+	if !errors.Is(err, ErrForbidden) {
+		return fmt.Errorf("proxy: expected forbidden save, got %v", err)
+	}
+	fmt.Fprintf(w, "reader save: %v (storage opened: %v)\n", err, lazy.Opened())
+
+	librarian := data.WithCaller(context.Background(), "librarian")
+	if err := dm.SaveData(librarian, "books/1", "Clean Code"); err != nil {
+		return err
+	}
+	if err := dm.SaveData(librarian, "loans/1", "books/1 -> ann"); err != nil {
+		return err
+	}
+	title, err := dm.LoadData(reader, "books/1")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "reader load books/1: %q\n", title)
+	if _, err := dm.LoadData(reader, "loans/1"); !errors.Is(err, ErrForbidden) {
+		return fmt.Errorf("proxy: expected forbidden load, got %v", err)
+	}
+	for _, caller := range []string{"reader", "admin"} {
+		keys, err := guard.List(data.WithCaller(context.Background(), caller), "")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s sees %v\n", caller, keys)
+	}
+	return nil
+}
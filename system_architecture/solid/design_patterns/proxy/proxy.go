@@ -0,0 +1,155 @@
+// Package proxy - паттерн «Заместитель» для data.Storage: виртуальный заместитель
+// откладывает открытие настоящего хранилища до первого обращения, защищающий -
+// проверяет права вызывающего на пространство имён ключа.
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"solid/data"
+)
+
+// Lazy - виртуальный заместитель: open вызывается один раз при первом обращении.
+// Ошибка открытия запоминается и возвращается всем последующим вызовам.
+type Lazy struct {
+	open func(ctx context.Context) (data.Storage, error)
+
+	once    sync.Once
+	opened  atomic.Bool
+	storage data.Storage
+	err     error
+}
+
+var _ data.Storage = (*Lazy)(nil)
+
+func NewLazy(open func(ctx context.Context) (data.Storage, error)) *Lazy {
+	return &Lazy{open: open}
+}
+
+func (l *Lazy) real(ctx context.Context) (data.Storage, error) {
+	l.once.Do(func() {
+		l.storage, l.err = l.open(ctx)
+		if l.err != nil {
+			l.err = fmt.Errorf("%w: open: %w", data.ErrUnavailable, l.err)
+		}
+		l.opened.Store(true)
+	})
+	return l.storage, l.err
+}
+
+// Opened сообщает, было ли уже открыто настоящее хранилище.
+func (l *Lazy) Opened() bool {
+	return l.opened.Load()
+}
+
+func (l *Lazy) Save(ctx context.Context, key, value string) error {
+	s, err := l.real(ctx)
+	if err != nil {
+		return err
+	}
+	return s.Save(ctx, key, value)
+}
+
+func (l *Lazy) Load(ctx context.Context, key string) (string, error) {
+	s, err := l.real(ctx)
+	if err != nil {
+		return "", err
+	}
+	return s.Load(ctx, key)
+}
+
+func (l *Lazy) List(ctx context.Context, prefix string) ([]string, error) {
+	s, err := l.real(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.List(ctx, prefix)
+}
+
+var ErrForbidden = errors.New("proxy: forbidden")
+
+type Permission uint8
+
+const (
+	Read Permission = 1 << iota
+	Write
+)
+
+// Policy - права вызывающих по пространствам имён: caller -> namespace -> права.
+// Пространство имён - часть ключа до первого "/"; "*" задаёт права на все пространства.
+type Policy map[string]map[string]Permission
+
+func (p Policy) allowed(caller, namespace string, need Permission) bool {
+	grants := p[caller]
+	return grants[namespace]&need == need || grants["*"]&need == need
+}
+
+func namespaceOf(key string) string {
+	ns, _, _ := strings.Cut(key, "/")
+	return ns
+}
+
+// Guard - защищающий заместитель. Вызывающий берётся из контекста (data.WithCaller).
+type Guard struct {
+	storage data.Storage
+	policy  Policy
+}
+
+var _ data.Storage = (*Guard)(nil)
+
+func NewGuard(s data.Storage, p Policy) *Guard {
+	return &Guard{storage: s, policy: p}
+}
+
+func (g *Guard) check(ctx context.Context, key string, need Permission) error {
+	caller, ns := data.CallerFrom(ctx), namespaceOf(key)
+	if !g.policy.allowed(caller, ns, need) {
+		return fmt.Errorf("%w: %s may not %s namespace %q", ErrForbidden, caller, need, ns)
+	}
+	return nil
+}
+
+func (p Permission) String() string {
+	switch p {
+	case Read:
+		return "read"
+	case Write:
+		return "write"
+	}
+	return "access"
+}
+
+func (g *Guard) Save(ctx context.Context, key, value string) error {
+	if err := g.check(ctx, key, Write); err != nil {
+		return err
+	}
+	return g.storage.Save(ctx, key, value)
+}
+
+func (g *Guard) Load(ctx context.Context, key string) (string, error) {
+	if err := g.check(ctx, key, Read); err != nil {
+		return "", err
+	}
+	return g.storage.Load(ctx, key)
+}
+
+// List возвращает только ключи из пространств имён, которые вызывающему разрешено читать.
+func (g *Guard) List(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := g.storage.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	caller := data.CallerFrom(ctx)
+	visible := keys[:0]
+	for _, k := range keys {
+		if g.policy.allowed(caller, namespaceOf(k), Read) {
+			visible = append(visible, k)
+		}
+	}
+	return visible, nil
+}
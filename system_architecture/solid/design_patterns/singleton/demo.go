@@ -0,0 +1,106 @@
+package singleton
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "singleton", Summary: "settings loaded once with sync.Once", Run: Demo})
+	catalog.Register(catalog.Demo{Name: "singleton-naive", Summary: "unsynchronized singleton loading many times", Run: NaiveDemo, Unstable: true})
+	catalog.RegisterCheck(catalog.Check{Name: "singleton", Summary: "sync.Once loads exactly once under concurrent first calls", Run: Check})
+}
+
+// checkRounds - сколько свежих одиночек проверяет Check: гонка первого обращения
+// бывает только один раз на экземпляр, поэтому одного Instance мало.
+const checkRounds = 100
+
+// Check проверяет то же, что Demo, на checkRounds новых экземплярах lazy: каждый
+// загружен ровно один раз, и все горутины получили одно значение. Под patterns check
+// -race детектор гонок заодно проверяет, что значение опубликовано без гонки.
+func Check(w io.Writer) error {
+	const goroutines = 64
+	for round := range checkRounds {
+		var loaded atomic.Int64
+		l := &lazy[*Settings]{load: func() *Settings {
+			loaded.Add(1)
+			// Как в load: медленная загрузка расширяет окно, в которое попадёт повторная.
+			time.Sleep(time.Millisecond)
+			return &Settings{MaxConns: round}
+		}}
+		seen := make([]*Settings, goroutines)
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := range seen {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				seen[i] = l.get()
+			}()
+		}
+		// Горутины стартуют разом: первое обращение - одновременное.
+		close(start)
+		wg.Wait()
+		if n := loaded.Load(); n != 1 {
+			return fmt.Errorf("round %d: loaded %d times", round, n)
+		}
+		for i, s := range seen {
+			if s != seen[0] || s.MaxConns != round {
+				return fmt.Errorf("round %d: goroutine %d got a different instance", round, i)
+			}
+		}
+	}
+	fmt.Fprintf(w, "%d instances, %d concurrent first calls each: every one loaded once\n", checkRounds, goroutines)
+	return nil
+}
+
+// Demo вызывает Instance из многих горутин и проверяет, что загрузка выполнилась один раз
+// и все получили один и тот же указатель. Под детектором гонок то же проверяет Check:
+// go run ./cmd/patterns check -race singleton.
+func Demo(w io.Writer) error {
+	const goroutines = 64
+	before := Loads()
+	seen := make([]*Settings, goroutines)
+	var wg sync.WaitGroup
+	for i := range seen {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			seen[i] = Instance()
+		}(i)
+	}
+	wg.Wait()
+	for _, s := range seen {
+		if s != seen[0] {
+			return fmt.Errorf("singleton: got different instances %p and %p", s, seen[0])
+		}
+	}
+	if n := Loads() - before; n != 1 {
+		return fmt.Errorf("singleton: settings loaded %d times", n)
+	}
+	fmt.Fprintf(w, "%d goroutines share one instance %p (dsn %s), loaded once\n", goroutines, seen[0], seen[0].DSN)
+	return nil
+}
+
+// NaiveDemo показывает повторные загрузки в наивной версии; под -race она намеренно сообщает о гонке.
+func NaiveDemo(w io.Writer) error {
+	const goroutines = 64
+	before := Loads()
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			NaiveInstance()
+		}()
+	}
+	wg.Wait()
+	fmt.Fprintf(w, "naive version loaded settings %d times\n", Loads()-before)
+	return nil
+}
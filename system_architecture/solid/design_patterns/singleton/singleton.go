@@ -0,0 +1,71 @@
+// Package singleton - паттерн «Одиночка» с ленивой инициализацией через sync.Once
+// и, для сравнения, наивная версия с гонкой данных.
+package singleton
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Settings - настройки подключения, которые дорого загружать и нужно создать один раз на процесс.
+type Settings struct {
+	DSN      string
+	MaxConns int
+	LoadedAt time.Time
+}
+
+// loads считает, сколько раз выполнялась загрузка; для корректного одиночки - ровно один.
+var loads atomic.Int64
+
+func load() *Settings {
+	loads.Add(1)
+	// Имитация медленной загрузки расширяет окно гонки в наивной версии.
+	time.Sleep(time.Millisecond)
+	maxConns, err := strconv.Atoi(os.Getenv("SOLID_MAX_CONNS"))
+	if err != nil || maxConns <= 0 {
+		maxConns = 10
+	}
+	dsn := os.Getenv("SOLID_DSN")
+	if dsn == "" {
+		dsn = "postgres://localhost/solid"
+	}
+	return &Settings{DSN: dsn, MaxConns: maxConns, LoadedAt: time.Now()}
+}
+
+// lazy - значение, которое загружается при первом обращении. sync.Once гарантирует и
+// однократную загрузку, и то, что остальные горутины увидят полностью
+// инициализированное значение.
+type lazy[T any] struct {
+	once sync.Once
+	load func() T
+	v    T
+}
+
+func (l *lazy[T]) get() T {
+	l.once.Do(func() { l.v = l.load() })
+	return l.v
+}
+
+var instance = &lazy[*Settings]{load: load}
+
+// Instance возвращает единственный экземпляр Settings, загружая его при первом вызове.
+func Instance() *Settings {
+	return instance.get()
+}
+
+var naive *Settings
+
+// NaiveInstance - антипример: проверка и присваивание не синхронизированы, поэтому
+// несколько горутин могут одновременно увидеть nil и загрузить настройки повторно,
+// а go run -race сообщит о гонке данных. Не используйте эту версию.
+func NaiveInstance() *Settings {
+	if naive == nil {
+		naive = load()
+	}
+	return naive
+}
+
+func Loads() int64 { return loads.Load() }
@@ -0,0 +1,61 @@
+package specification
+
+import (
+	"strings"
+
+	"solid/library"
+)
+
+// Спецификации книг совпадают с колонками таблицы books: title, author, year, isbn.
+
+type byAuthor struct{ name string }
+
+// ByAuthor сравнивает автора без учёта регистра.
+func ByAuthor(name string) Spec[library.Book] { return byAuthor{name: strings.ToLower(name)} }
+
+func (s byAuthor) IsSatisfiedBy(b library.Book) bool { return strings.ToLower(b.Author) == s.name }
+
+func (s byAuthor) SQL() (string, []any, error) { return "LOWER(author) = ?", []any{s.name}, nil }
+
+type titleContains struct{ part string }
+
+func TitleContains(part string) Spec[library.Book] {
+	return titleContains{part: strings.ToLower(part)}
+}
+
+func (s titleContains) IsSatisfiedBy(b library.Book) bool {
+	return strings.Contains(strings.ToLower(b.Title), s.part)
+}
+
+func (s titleContains) SQL() (string, []any, error) {
+	return "LOWER(title) LIKE ?", []any{"%" + s.part + "%"}, nil
+}
+
+type publishedBetween struct{ from, to int }
+
+// PublishedBetween - год издания в диапазоне [from, to]; 0 снимает соответствующую границу.
+func PublishedBetween(from, to int) Spec[library.Book] { return publishedBetween{from: from, to: to} }
+
+func (s publishedBetween) IsSatisfiedBy(b library.Book) bool {
+	return (s.from == 0 || b.Year >= s.from) && (s.to == 0 || b.Year <= s.to)
+}
+
+func (s publishedBetween) SQL() (string, []any, error) {
+	switch {
+	case s.from != 0 && s.to != 0:
+		return "year BETWEEN ? AND ?", []any{s.from, s.to}, nil
+	case s.from != 0:
+		return "year >= ?", []any{s.from}, nil
+	case s.to != 0:
+		return "year <= ?", []any{s.to}, nil
+	}
+	return "1 = 1", nil, nil
+}
+
+type hasISBN struct{}
+
+func HasISBN() Spec[library.Book] { return hasISBN{} }
+
+func (hasISBN) IsSatisfiedBy(b library.Book) bool { return b.ISBN != "" }
+
+func (hasISBN) SQL() (string, []any, error) { return "isbn <> ''", nil, nil }
@@ -0,0 +1,51 @@
+package specification
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"solid/design_patterns/builder"
+	"solid/design_patterns/catalog"
+	"solid/library"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "specification", Summary: "composable book predicates evaluated in memory and as SQL", Run: Demo})
+}
+
+// Demo проверяет одно составное правило на книгах в памяти и переводит его в SQL;
+// правило на основе функции переводу не поддаётся и сообщает об этом.
+func Demo(w io.Writer) error {
+	books := []library.Book{
+		{Title: "The Go Programming Language", Author: "Donovan", Year: 2015, ISBN: "9780134190440"},
+		{Title: "Go in Action", Author: "Kennedy", Year: 2015},
+		{Title: "Learning Go", Author: "Bodner", Year: 2021, ISBN: "9781492077213"},
+		{Title: "The C Programming Language", Author: "Kernighan", Year: 1978, ISBN: "9780131103627"},
+	}
+	modernGo := And(
+		TitleContains("go"),
+		Or(PublishedBetween(2020, 0), HasISBN()),
+		Not(ByAuthor("kennedy")),
+	)
+	for _, b := range Filter(books, modernGo) {
+		fmt.Fprintf(w, "match: %s (%d)\n", b.Title, b.Year)
+	}
+	q, err := Where(builder.Select("id", "title").From("books"), modernGo)
+	if err != nil {
+		return err
+	}
+	sql, args, err := q.OrderBy("title").Build(builder.Dollar)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "sql: %s\nargs: %v\n", sql, args)
+
+	short := Func[library.Book](func(b library.Book) bool { return len(b.Title) < 15 })
+	_, _, err = ToSQL(And(HasISBN(), short))
+	fmt.Fprintln(w, "func spec:", err)
+	if !errors.Is(err, ErrNoSQL) || len(Filter(books, modernGo)) != 2 || len(args) != 3 {
+		return fmt.Errorf("specification: unexpected result %v, %d args", err, len(args))
+	}
+	return nil
+}
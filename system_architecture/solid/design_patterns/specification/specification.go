@@ -0,0 +1,123 @@
+// Package specification - паттерн «Спецификация»: бизнес-правило отбора оформлено
+// отдельным объектом и комбинируется через And/Or/Not. Одно и то же правило проверяет
+// объект в памяти (IsSatisfiedBy) и, если умеет, переводится в условие SQL (ToSQL).
+package specification
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"solid/design_patterns/builder"
+)
+
+var ErrNoSQL = errors.New("specification: no SQL translation")
+
+type Spec[T any] interface {
+	IsSatisfiedBy(v T) bool
+}
+
+// SQLer - спецификация, которую можно выразить условием WHERE с плейсхолдерами "?".
+type SQLer interface {
+	SQL() (string, []any, error)
+}
+
+// Func - спецификация из функции; в SQL не переводится.
+type Func[T any] func(v T) bool
+
+func (f Func[T]) IsSatisfiedBy(v T) bool { return f(v) }
+
+type and[T any] struct{ specs []Spec[T] }
+
+type or[T any] struct{ specs []Spec[T] }
+
+type not[T any] struct{ spec Spec[T] }
+
+// And выполняется, когда выполняются все specs; пустой And выполняется всегда.
+func And[T any](specs ...Spec[T]) Spec[T] { return and[T]{specs: specs} }
+
+// Or выполняется, когда выполняется хотя бы одна из specs; пустой Or не выполняется никогда.
+func Or[T any](specs ...Spec[T]) Spec[T] { return or[T]{specs: specs} }
+
+func Not[T any](spec Spec[T]) Spec[T] { return not[T]{spec: spec} }
+
+func (s and[T]) IsSatisfiedBy(v T) bool {
+	for _, sp := range s.specs {
+		if !sp.IsSatisfiedBy(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s or[T]) IsSatisfiedBy(v T) bool {
+	for _, sp := range s.specs {
+		if sp.IsSatisfiedBy(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s not[T]) IsSatisfiedBy(v T) bool { return !s.spec.IsSatisfiedBy(v) }
+
+func (s and[T]) SQL() (string, []any, error) { return join(s.specs, " AND ", "1 = 1") }
+
+func (s or[T]) SQL() (string, []any, error) { return join(s.specs, " OR ", "1 = 0") }
+
+func (s not[T]) SQL() (string, []any, error) {
+	expr, args, err := ToSQL[T](s.spec)
+	if err != nil {
+		return "", nil, err
+	}
+	return "NOT (" + expr + ")", args, nil
+}
+
+func join[T any](specs []Spec[T], sep, empty string) (string, []any, error) {
+	if len(specs) == 0 {
+		return empty, nil, nil
+	}
+	parts := make([]string, len(specs))
+	var args []any
+	for i, sp := range specs {
+		expr, a, err := ToSQL(sp)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(specs) > 1 {
+			expr = "(" + expr + ")"
+		}
+		parts[i] = expr
+		args = append(args, a...)
+	}
+	return strings.Join(parts, sep), args, nil
+}
+
+// ToSQL переводит спецификацию в условие; ErrNoSQL, если хоть одна часть не умеет.
+func ToSQL[T any](spec Spec[T]) (string, []any, error) {
+	s, ok := spec.(SQLer)
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %T", ErrNoSQL, spec)
+	}
+	return s.SQL()
+}
+
+// Where добавляет спецификацию в запрос builder; ошибка перевода возвращается сразу.
+func Where[T any](q *builder.Query, spec Spec[T]) (*builder.Query, error) {
+	expr, args, err := ToSQL(spec)
+	if err != nil {
+		return nil, err
+	}
+	return q.Where(expr, args...), nil
+}
+
+// Filter оставляет элементы, удовлетворяющие спецификации.
+func Filter[T any](items []T, spec Spec[T]) []T {
+	var out []T
+	for _, v := range items {
+		if spec.IsSatisfiedBy(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
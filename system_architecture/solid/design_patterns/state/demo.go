@@ -0,0 +1,142 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"solid/design_patterns/catalog"
+	"solid/library"
+	"solid/library/events"
+	"solid/library/inventory"
+	"solid/library/lending"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "state", Summary: "loan lifecycle driven by state objects", Run: Demo})
+}
+
+// table - ожидаемые переходы; отсутствующая пара означает ErrTransition.
+var table = map[Status]map[Event]Status{
+	Requested: {Approve: Active},
+	Active:    {Expire: Overdue, Return: Returned, Lose: Lost},
+	Overdue:   {Return: Returned, Lose: Lost},
+	Returned:  {},
+	Lost:      {},
+}
+
+// Demo проводит две выдачи через настоящий lending.Service (просрочка с возвратом и утеря),
+// а затем сверяет каждое состояние с таблицей переходов.
+func Demo(w io.Writer) error {
+	ctx := context.Background()
+	books := library.NewMemoryRepository()
+	book, err := books.Add(ctx, library.Book{Title: "The Pragmatic Programmer", Author: "Hunt, Thomas"})
+	if err != nil {
+		return err
+	}
+	copies := inventory.NewMemoryStore()
+	inv := inventory.NewService(copies, books)
+	for i := 0; i < 2; i++ {
+		if _, err := inv.AddCopy(ctx, inventory.Copy{BookID: book.ID, Branch: "main"}); err != nil {
+			return err
+		}
+	}
+	svc := lending.NewService(lending.NewMemoryStore(), copies, events.NewBus())
+
+	first := Request(svc, book.ID, "ann")
+	for _, e := range []Event{Approve, Expire} {
+		if err := first.Fire(ctx, e); err != nil {
+			fmt.Fprintln(w, "refused:", err)
+		}
+	}
+	// Через пятнадцать дней срок возврата истёк.
+	first.Clock = func() time.Time { return time.Now().Add(15 * 24 * time.Hour) }
+	for _, e := range []Event{Expire, Return, Return} {
+		if err := first.Fire(ctx, e); err != nil {
+			fmt.Fprintln(w, "refused:", err)
+			continue
+		}
+		fmt.Fprintf(w, "ann: %s -> %s\n", e, first.Status())
+	}
+
+	second := Request(svc, book.ID, "bob")
+	for _, e := range []Event{Approve, Lose} {
+		if err := second.Fire(ctx, e); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "bob: %s -> %s\n", e, second.Status())
+	}
+	avail, err := inv.Availability(ctx, book.ID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "copies available: %d of %d\n", avail.Available, avail.Total)
+	if first.Status() != Returned || second.Status() != Lost || avail.Available != 1 {
+		return fmt.Errorf("state: unexpected outcome %s/%s, %d available", first.Status(), second.Status(), avail.Available)
+	}
+	return checkTable(ctx, w)
+}
+
+// checkTable прогоняет каждое событие из каждого состояния и сравнивает результат с таблицей.
+func checkTable(ctx context.Context, w io.Writer) error {
+	for _, from := range []Status{Requested, Active, Overdue, Returned, Lost} {
+		for _, e := range Events {
+			l := loanIn(from)
+			err := l.Fire(ctx, e)
+			want, allowed := table[from][e]
+			switch {
+			case allowed && err != nil:
+				return fmt.Errorf("state: %s from %s: %w", e, from, err)
+			case allowed && l.Status() != want:
+				return fmt.Errorf("state: %s from %s led to %s, want %s", e, from, l.Status(), want)
+			case !allowed && !errors.Is(err, ErrTransition):
+				return fmt.Errorf("state: %s from %s should be refused, got %v", e, from, err)
+			}
+		}
+	}
+	fmt.Fprintf(w, "transition table: %d states x %d events verified\n", len(table), len(Events))
+	return nil
+}
+
+// loanIn собирает выдачу в заданном состоянии поверх заглушки lending.
+func loanIn(s Status) *Loan {
+	now := time.Now()
+	due := now.Add(time.Hour)
+	rec := lending.Loan{ID: "loan-1", BookID: "book-1", Borrower: "ann", LoanedAt: now, DueAt: due}
+	var l *Loan
+	switch s {
+	case Requested:
+		return Request(stubLending{}, rec.BookID, rec.Borrower)
+	case Overdue:
+		l = Restore(stubLending{}, rec, due.Add(time.Hour))
+	case Returned:
+		rec.ReturnedAt = &now
+		l = Restore(stubLending{}, rec, now)
+	case Lost:
+		rec.LostAt = &now
+		l = Restore(stubLending{}, rec, now)
+	default:
+		l = Restore(stubLending{}, rec, now)
+	}
+	// Срок уже прошёл, поэтому Expire из Active допустим.
+	l.Clock = func() time.Time { return due.Add(time.Hour) }
+	return l
+}
+
+type stubLending struct{}
+
+func (stubLending) Loan(ctx context.Context, bookID, borrower string) (lending.Loan, error) {
+	return lending.Loan{ID: "loan-1", BookID: bookID, Borrower: borrower, DueAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (stubLending) Return(ctx context.Context, loanID string) (lending.Loan, error) {
+	now := time.Now()
+	return lending.Loan{ID: loanID, ReturnedAt: &now}, nil
+}
+
+func (stubLending) MarkLost(ctx context.Context, loanID string) (lending.Loan, error) {
+	now := time.Now()
+	return lending.Loan{ID: loanID, LostAt: &now}, nil
+}
@@ -0,0 +1,189 @@
+// Package state - паттерн «Состояние» для жизненного цикла выдачи книги.
+// Каждое состояние - отдельный объект, который сам решает, какие события допустимы
+// и в какое состояние они ведут. Побочные эффекты выполняет lending.Service.
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"solid/library/lending"
+)
+
+var ErrTransition = errors.New("state: transition not allowed")
+
+type Status string
+
+const (
+	Requested Status = "requested"
+	Active    Status = "active"
+	Overdue   Status = "overdue"
+	Returned  Status = "returned"
+	Lost      Status = "lost"
+)
+
+type Event string
+
+const (
+	Approve Event = "approve"
+	Expire  Event = "expire"
+	Return  Event = "return"
+	Lose    Event = "lose"
+)
+
+// Events - все события в порядке их обычного появления.
+var Events = []Event{Approve, Expire, Return, Lose}
+
+// Lending - операции выдачи, которые выполняют переходы; реализуется lending.Service.
+type Lending interface {
+	Loan(ctx context.Context, bookID, borrower string) (lending.Loan, error)
+	Return(ctx context.Context, loanID string) (lending.Loan, error)
+	MarkLost(ctx context.Context, loanID string) (lending.Loan, error)
+}
+
+var _ Lending = (*lending.Service)(nil)
+
+// State - поведение выдачи в одном состоянии. Недопустимое событие возвращает ErrTransition.
+type State interface {
+	Status() Status
+	Approve(ctx context.Context, l *Loan) (State, error)
+	Expire(ctx context.Context, l *Loan) (State, error)
+	Return(ctx context.Context, l *Loan) (State, error)
+	Lose(ctx context.Context, l *Loan) (State, error)
+}
+
+// Loan - контекст паттерна: хранит текущее состояние и передаёт ему события.
+type Loan struct {
+	BookID   string
+	Borrower string
+	// Record - запись lending, появляется после Approve.
+	Record lending.Loan
+	// Clock задаёт текущее время для Expire; по умолчанию time.Now.
+	Clock func() time.Time
+
+	svc   Lending
+	state State
+}
+
+// Request создаёт заявку на выдачу; экземпляр закрепляется только при Approve.
+func Request(svc Lending, bookID, borrower string) *Loan {
+	return &Loan{BookID: bookID, Borrower: borrower, svc: svc, state: requested{}}
+}
+
+// Restore восстанавливает состояние по записи lending, например после загрузки из хранилища.
+func Restore(svc Lending, rec lending.Loan, now time.Time) *Loan {
+	l := &Loan{BookID: rec.BookID, Borrower: rec.Borrower, Record: rec, svc: svc}
+	switch {
+	case rec.LostAt != nil:
+		l.state = lost{}
+	case rec.ReturnedAt != nil:
+		l.state = returned{}
+	case now.After(rec.DueAt):
+		l.state = overdue{}
+	default:
+		l.state = active{}
+	}
+	return l
+}
+
+func (l *Loan) Status() Status {
+	return l.state.Status()
+}
+
+// Fire передаёт событие текущему состоянию и при успехе переходит в новое.
+func (l *Loan) Fire(ctx context.Context, e Event) error {
+	var next State
+	var err error
+	switch e {
+	case Approve:
+		next, err = l.state.Approve(ctx, l)
+	case Expire:
+		next, err = l.state.Expire(ctx, l)
+	case Return:
+		next, err = l.state.Return(ctx, l)
+	case Lose:
+		next, err = l.state.Lose(ctx, l)
+	default:
+		return fmt.Errorf("state: unknown event %q", e)
+	}
+	if err != nil {
+		return fmt.Errorf("state: %s %s loan: %w", e, l.Status(), err)
+	}
+	l.state = next
+	return nil
+}
+
+func (l *Loan) now() time.Time {
+	if l.Clock != nil {
+		return l.Clock()
+	}
+	return time.Now()
+}
+
+// refuse отклоняет все события; состояния переопределяют только допустимые.
+type refuse struct{}
+
+func (refuse) Approve(context.Context, *Loan) (State, error) { return nil, ErrTransition }
+func (refuse) Expire(context.Context, *Loan) (State, error)  { return nil, ErrTransition }
+func (refuse) Return(context.Context, *Loan) (State, error)  { return nil, ErrTransition }
+func (refuse) Lose(context.Context, *Loan) (State, error)    { return nil, ErrTransition }
+
+type requested struct{ refuse }
+
+func (requested) Status() Status { return Requested }
+
+func (requested) Approve(ctx context.Context, l *Loan) (State, error) {
+	rec, err := l.svc.Loan(ctx, l.BookID, l.Borrower)
+	if err != nil {
+		return nil, err
+	}
+	l.Record = rec
+	return active{}, nil
+}
+
+// onLoan - общее поведение выданной книги: её можно вернуть или потерять.
+type onLoan struct{ refuse }
+
+func (onLoan) Return(ctx context.Context, l *Loan) (State, error) {
+	rec, err := l.svc.Return(ctx, l.Record.ID)
+	if err != nil {
+		return nil, err
+	}
+	l.Record = rec
+	return returned{}, nil
+}
+
+func (onLoan) Lose(ctx context.Context, l *Loan) (State, error) {
+	rec, err := l.svc.MarkLost(ctx, l.Record.ID)
+	if err != nil {
+		return nil, err
+	}
+	l.Record = rec
+	return lost{}, nil
+}
+
+type active struct{ onLoan }
+
+func (active) Status() Status { return Active }
+
+// Expire переводит выдачу в просроченные только после срока возврата.
+func (active) Expire(ctx context.Context, l *Loan) (State, error) {
+	if !l.now().After(l.Record.DueAt) {
+		return nil, fmt.Errorf("%w: due at %s", ErrTransition, l.Record.DueAt.Format(time.DateOnly))
+	}
+	return overdue{}, nil
+}
+
+type overdue struct{ onLoan }
+
+func (overdue) Status() Status { return Overdue }
+
+type returned struct{ refuse }
+
+func (returned) Status() Status { return Returned }
+
+type lost struct{ refuse }
+
+func (lost) Status() Status { return Lost }
@@ -0,0 +1,50 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "strategy", Summary: "named discount strategies with a default", Run: Demo})
+}
+
+// Demo считает одну цену разными скидками, показывает запасной вариант по умолчанию
+// и добавляет новую стратегию в отдельный реестр, не трогая существующий код.
+func Demo(w io.Writer) error {
+	const price = 250.0
+	for _, name := range Discounts.Names() {
+		d, err := Discounts.Lookup(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%-8s %.2f -> %.2f\n", name, price, d(price))
+	}
+	_, err := Discounts.Lookup("black-friday")
+	if !errors.Is(err, ErrUnknown) {
+		return fmt.Errorf("strategy: expected ErrUnknown, got %v", err)
+	}
+	fmt.Fprintln(w, "lookup:", err)
+	d, _ := Discounts.Get("black-friday")
+	fmt.Fprintf(w, "get falls back to the default: %.2f\n", d(price))
+
+	// Стратегия не обязана быть коэффициентом: фиксированная скидка с нижней границей.
+	coupons := NewRegistry[Discount]("coupon")
+	coupons.Register("minus50", func(p float64) float64 { return math.Max(p-50, 0) })
+	coupons.Register("half", Rate(0.5))
+	coupons.SetDefault("half")
+	coupon, err := coupons.Lookup("")
+	if err != nil {
+		return err
+	}
+	minus, _ := coupons.Get("minus50")
+	fmt.Fprintf(w, "coupons %v: default %.2f, minus50 %.2f\n", coupons.Names(), coupon(price), minus(price))
+	if d(price) != price || coupon(price) != 125 || minus(price) != 200 {
+		return fmt.Errorf("strategy: unexpected totals %.2f/%.2f/%.2f", d(price), coupon(price), minus(price))
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package strategy
+
+import "math"
+
+// Discount - стратегия скидки: возвращает цену после её применения.
+type Discount func(price float64) float64
+
+// Rate - скидка, умножающая цену на коэффициент, с округлением до копеек.
+func Rate(k float64) Discount {
+	return func(price float64) float64 {
+		return math.Round(price*k*100) / 100
+	}
+}
+
+// Discounts - скидки из примера принципа открытости/закрытости; по умолчанию «none».
+var Discounts = NewRegistry[Discount]("discount")
+
+func init() {
+	Discounts.Register("none", Rate(1))
+	Discounts.Register("regular", Rate(0.9))
+	Discounts.Register("holiday", Rate(0.8))
+	Discounts.SetDefault("none")
+}
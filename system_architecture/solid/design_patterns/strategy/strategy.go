@@ -0,0 +1,78 @@
+// Package strategy - паттерн «Стратегия» и общий реестр взаимозаменяемых реализаций.
+// Registry[T] выбирает стратегию по имени из конфигурации или запроса; им пользуются
+// скидки API цен и фабрика хранилищ, вместо собственных map под мьютексом.
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrUnknown = errors.New("strategy: unknown strategy")
+
+// Registry - именованные стратегии одного вида с необязательной стратегией по умолчанию.
+type Registry[T any] struct {
+	kind string
+
+	mu         sync.RWMutex
+	strategies map[string]T
+	def        string
+}
+
+// NewRegistry создаёт реестр; kind попадает в сообщения об ошибках («discount», «storage»).
+func NewRegistry[T any](kind string) *Registry[T] {
+	return &Registry[T]{kind: kind, strategies: make(map[string]T)}
+}
+
+// Register добавляет стратегию; повторная регистрация заменяет прежнюю.
+func (r *Registry[T]) Register(name string, s T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strategies[name] = s
+}
+
+// SetDefault задаёт стратегию для пустого имени в Lookup и для любого неизвестного в Get.
+func (r *Registry[T]) SetDefault(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.def = name
+}
+
+// Lookup возвращает стратегию по имени; пустое имя означает стратегию по умолчанию.
+func (r *Registry[T]) Lookup(name string) (T, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if name == "" && r.def != "" {
+		name = r.def
+	}
+	s, ok := r.strategies[name]
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: %s %q", ErrUnknown, r.kind, name)
+	}
+	return s, nil
+}
+
+// Get не отказывает: неизвестное имя заменяется стратегией по умолчанию.
+// Второй результат сообщает, была ли найдена хоть какая-то стратегия.
+func (r *Registry[T]) Get(name string) (T, bool) {
+	if s, err := r.Lookup(name); err == nil {
+		return s, true
+	}
+	s, err := r.Lookup("")
+	return s, err == nil
+}
+
+// Names возвращает зарегистрированные имена по алфавиту.
+func (r *Registry[T]) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.strategies))
+	for name := range r.strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,102 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"solid/data"
+	"solid/design_patterns/catalog"
+	"solid/design_patterns/chain"
+	"solid/design_patterns/strategy"
+	"solid/library"
+	"solid/library/events"
+	"solid/library/inventory"
+	"solid/library/lending"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "template", Summary: "report pipeline with overridable steps", Run: Demo})
+}
+
+// Demo строит оба отчёта по одному шаблону: статистику библиотеки печатает в w,
+// а сводку цен сохраняет в хранилище и читает обратно.
+func Demo(w io.Writer) error {
+	ctx := context.Background()
+	books := library.NewMemoryRepository()
+	copies := inventory.NewMemoryStore()
+	inv := inventory.NewService(copies, books)
+	loans := lending.NewMemoryStore()
+	svc := lending.NewService(loans, copies, events.NewBus())
+	var ids []string
+	for _, title := range []string{"Refactoring", "Domain-Driven Design"} {
+		b, err := books.Add(ctx, library.Book{Title: title, Author: "various"})
+		if err != nil {
+			return err
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := inv.AddCopy(ctx, inventory.Copy{BookID: b.ID, Branch: "main"}); err != nil {
+				return err
+			}
+		}
+		ids = append(ids, b.ID)
+	}
+	var opened []lending.Loan
+	for _, bookID := range []string{ids[0], ids[0], ids[1]} {
+		l, err := svc.Loan(ctx, bookID, "reader")
+		if err != nil {
+			return err
+		}
+		opened = append(opened, l)
+	}
+	if _, err := svc.Return(ctx, opened[0].ID); err != nil {
+		return err
+	}
+	if _, err := svc.MarkLost(ctx, opened[2].ID); err != nil {
+		return err
+	}
+
+	stats := LibraryStats(loans, func(id string) string {
+		if b, err := books.Get(ctx, id); err == nil {
+			return b.Title
+		}
+		return id
+	})
+	stats.Deliver = ToWriter(w)
+	body, err := stats.Run(ctx)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(body, "Refactoring (2)") {
+		return fmt.Errorf("template: unexpected stats report:\n%s", body)
+	}
+
+	db := data.NewDatabase()
+	quotes := data.NewDataManager[chain.Quote](db)
+	for i, q := range []chain.Quote{{SKU: "a", Price: 40, Discount: "regular"}, {SKU: "b", Price: 10, Discount: "holiday"}, {SKU: "c", Price: 5, Discount: "none"}} {
+		discount, err := strategy.Discounts.Lookup(q.Discount)
+		if err != nil {
+			return err
+		}
+		q.Total = discount(q.Price)
+		if err := quotes.SaveData(ctx, fmt.Sprintf("quotes/shop/%d", i+1), q); err != nil {
+			return err
+		}
+	}
+	reports := data.NewDataManager[string](db)
+	pricing := PricingSummary(quotes)
+	pricing.Deliver = ToStorage(reports, "reports/")
+	if _, err := pricing.Run(ctx); err != nil {
+		return err
+	}
+	saved, err := reports.LoadData(ctx, "reports/pricing-summary")
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(w, saved)
+	if !strings.Contains(saved, "| discounted | 6.00 |") {
+		return fmt.Errorf("template: unexpected pricing summary:\n%s", saved)
+	}
+	return nil
+}
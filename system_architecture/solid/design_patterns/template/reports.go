@@ -0,0 +1,109 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"solid/data"
+	"solid/design_patterns/chain"
+	"solid/library/lending"
+)
+
+// LoanLister - источник выдач для статистики; реализуется lending.Store.
+type LoanLister interface {
+	List(ctx context.Context) ([]lending.Loan, error)
+}
+
+// LibraryStats сводит выдачи: сколько открыто, возвращено и утеряно, и чаще всего выдаваемую книгу.
+// titles переводит идентификатор книги в название; nil оставляет идентификатор.
+func LibraryStats(loans LoanLister, titles func(bookID string) string) Report[lending.Loan] {
+	return Report[lending.Loan]{
+		Title: "Library stats",
+		Fetch: loans.List,
+		Aggregate: func(items []lending.Loan) []Line {
+			var active, returned, lost int
+			perBook := map[string]int{}
+			for _, l := range items {
+				switch {
+				case l.LostAt != nil:
+					lost++
+				case l.ReturnedAt != nil:
+					returned++
+				default:
+					active++
+				}
+				perBook[l.BookID]++
+			}
+			lines := []Line{
+				{"loans", strconv.Itoa(len(items))},
+				{"active", strconv.Itoa(active)},
+				{"returned", strconv.Itoa(returned)},
+				{"lost", strconv.Itoa(lost)},
+			}
+			if top, n := most(perBook); n > 0 {
+				if titles != nil {
+					top = titles(top)
+				}
+				lines = append(lines, Line{"most borrowed", fmt.Sprintf("%s (%d)", top, n)})
+			}
+			return lines
+		},
+	}
+}
+
+// PricingSummary сводит расчёты API цен по скидкам; оформление по умолчанию - Markdown.
+func PricingSummary(quotes *data.DataManager[chain.Quote]) Report[chain.Quote] {
+	return Report[chain.Quote]{
+		Title: "Pricing summary",
+		Fetch: func(ctx context.Context) ([]chain.Quote, error) {
+			records, err := quotes.ListData(ctx, "quotes/")
+			if err != nil {
+				return nil, err
+			}
+			items := make([]chain.Quote, len(records))
+			for i, r := range records {
+				items[i] = r.Value
+			}
+			return items, nil
+		},
+		Aggregate: func(items []chain.Quote) []Line {
+			var list, total float64
+			byDiscount := map[string]int{}
+			for _, q := range items {
+				list += q.Price
+				total += q.Total
+				byDiscount[q.Discount]++
+			}
+			lines := []Line{
+				{"quotes", strconv.Itoa(len(items))},
+				{"list price", fmt.Sprintf("%.2f", list)},
+				{"charged", fmt.Sprintf("%.2f", total)},
+				{"discounted", fmt.Sprintf("%.2f", list-total)},
+			}
+			names := make([]string, 0, len(byDiscount))
+			for name := range byDiscount {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				lines = append(lines, Line{"discount " + name, strconv.Itoa(byDiscount[name])})
+			}
+			return lines
+		},
+		Format: Markdown,
+	}
+}
+
+// most возвращает ключ с наибольшим счётчиком; при равенстве - меньший по алфавиту.
+func most(counts map[string]int) (string, int) {
+	var top string
+	n := 0
+	for k, c := range counts {
+		if c > n || c == n && k < top {
+			top, n = k, c
+		}
+	}
+	return top, n
+}
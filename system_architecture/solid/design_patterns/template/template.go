@@ -0,0 +1,95 @@
+// Package template - паттерн «Шаблонный метод» для отчётов. Report.Run задаёт неизменный
+// порядок шагов (получить данные, свести, оформить, доставить), а конкретный отчёт
+// подставляет свои шаги через поля-функции; незаданные шаги берутся по умолчанию.
+package template
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"solid/data"
+)
+
+var ErrIncomplete = errors.New("template: report step is missing")
+
+// Line - одна строка сводки отчёта.
+type Line struct {
+	Label string
+	Value string
+}
+
+// Report - отчёт по элементам типа T. Fetch и Aggregate обязательны.
+type Report[T any] struct {
+	Title     string
+	Fetch     func(ctx context.Context) ([]T, error)
+	Aggregate func(items []T) []Line
+	// Format по умолчанию PlainText.
+	Format func(title string, lines []Line) string
+	// Deliver по умолчанию отбрасывает результат - его всё равно возвращает Run.
+	Deliver func(ctx context.Context, title, body string) error
+}
+
+// Run - шаблонный метод: шаги выполняются всегда в одном порядке, первая ошибка прерывает отчёт.
+func (r Report[T]) Run(ctx context.Context) (string, error) {
+	if r.Fetch == nil || r.Aggregate == nil {
+		return "", fmt.Errorf("%w: %s needs Fetch and Aggregate", ErrIncomplete, r.Title)
+	}
+	items, err := r.Fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("template: %s: fetch: %w", r.Title, err)
+	}
+	lines := r.Aggregate(items)
+	format := r.Format
+	if format == nil {
+		format = PlainText
+	}
+	body := format(r.Title, lines)
+	if r.Deliver != nil {
+		if err := r.Deliver(ctx, r.Title, body); err != nil {
+			return "", fmt.Errorf("template: %s: deliver: %w", r.Title, err)
+		}
+	}
+	return body, nil
+}
+
+// PlainText выравнивает значения по самой длинной подписи.
+func PlainText(title string, lines []Line) string {
+	width := 0
+	for _, l := range lines {
+		width = max(width, len(l.Label))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s\n", title, strings.Repeat("=", len(title)))
+	for _, l := range lines {
+		fmt.Fprintf(&b, "%-*s  %s\n", width, l.Label, l.Value)
+	}
+	return b.String()
+}
+
+// Markdown оформляет сводку таблицей.
+func Markdown(title string, lines []Line) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n| | |\n|---|---|\n", title)
+	for _, l := range lines {
+		fmt.Fprintf(&b, "| %s | %s |\n", l.Label, l.Value)
+	}
+	return b.String()
+}
+
+// ToWriter доставляет отчёт в w, например в stdout.
+func ToWriter(w io.Writer) func(ctx context.Context, title, body string) error {
+	return func(ctx context.Context, title, body string) error {
+		_, err := io.WriteString(w, body)
+		return err
+	}
+}
+
+// ToStorage сохраняет отчёт через DataManager под ключом prefix + заголовок.
+func ToStorage(dm *data.DataManager[string], prefix string) func(ctx context.Context, title, body string) error {
+	return func(ctx context.Context, title, body string) error {
+		return dm.SaveData(ctx, prefix+strings.ToLower(strings.ReplaceAll(title, " ", "-")), body)
+	}
+}
@@ -0,0 +1,60 @@
+package visitor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"solid/design_patterns/catalog"
+)
+
+func init() {
+	catalog.Register(catalog.Demo{Name: "visitor", Summary: "word count, HTML export and validation of a document", Run: Demo})
+}
+
+// Demo применяет три посетителя к одному документу, затем ломает документ и проверяет снова.
+func Demo(w io.Writer) error {
+	doc := &Section{Heading: "Annual report", Children: []Node{
+		&Paragraph{Text: "Loans grew & the catalog doubled."},
+		&Section{Heading: "Top books", Children: []Node{
+			&Table{Header: []string{"Title", "Loans"}, Rows: [][]string{{"Refactoring", "12"}, {"Clean Code", "9"}}},
+			&Image{Src: "chart.png", Alt: "Loans per month"},
+		}},
+	}}
+
+	var words WordCount
+	if err := doc.Accept(&words); err != nil {
+		return err
+	}
+	var out HTML
+	if err := doc.Accept(&out); err != nil {
+		return err
+	}
+	var check Validate
+	if err := doc.Accept(&check); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "words: %d, valid: %v\n%s", words.Words, check.Err() == nil, out.String())
+	if words.Words != 17 || check.Err() != nil {
+		return fmt.Errorf("visitor: got %d words, validation %v", words.Words, check.Err())
+	}
+
+	broken := &Section{Heading: "Draft", Children: []Node{
+		&Section{Heading: "Figures", Children: []Node{
+			&Table{Header: []string{"Title", "Loans"}, Rows: [][]string{{"Refactoring"}}},
+			&Image{Src: "chart.png"},
+		}},
+		&Section{Heading: "Notes"},
+	}}
+	check = Validate{}
+	if err := broken.Accept(&check); err != nil {
+		return err
+	}
+	for _, p := range check.Problems {
+		fmt.Fprintln(w, "problem:", p)
+	}
+	if !errors.Is(check.Err(), ErrInvalid) || len(check.Problems) != 3 {
+		return fmt.Errorf("visitor: expected 3 problems, got %v", check.Problems)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+// Package visitor - паттерн «Посетитель» для дерева документа. Узлы знают только свой
+// Accept, а операции (подсчёт слов, экспорт в HTML, проверка) живут в посетителях.
+// Двойная диспетчеризация заменяет type switch по узлам у каждого вызывающего.
+package visitor
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Node - узел документа.
+type Node interface {
+	Accept(v Visitor) error
+}
+
+// Visitor - операция над документом: по методу на каждый вид узла.
+// Обход детей секции выбирает сам посетитель, обычно через VisitChildren.
+type Visitor interface {
+	VisitSection(s *Section) error
+	VisitParagraph(p *Paragraph) error
+	VisitTable(t *Table) error
+	VisitImage(i *Image) error
+}
+
+type Section struct {
+	Heading  string
+	Children []Node
+}
+
+type Paragraph struct {
+	Text string
+}
+
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+type Image struct {
+	Src string
+	Alt string
+}
+
+func (s *Section) Accept(v Visitor) error   { return v.VisitSection(s) }
+func (p *Paragraph) Accept(v Visitor) error { return v.VisitParagraph(p) }
+func (t *Table) Accept(v Visitor) error     { return v.VisitTable(t) }
+func (i *Image) Accept(v Visitor) error     { return v.VisitImage(i) }
+
+// VisitChildren передаёт посетителю узлы по порядку и останавливается на первой ошибке.
+func VisitChildren(v Visitor, nodes []Node) error {
+	for _, n := range nodes {
+		if err := n.Accept(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WordCount считает слова в заголовках, абзацах и ячейках таблиц.
+type WordCount struct {
+	Words int
+}
+
+func (c *WordCount) VisitSection(s *Section) error {
+	c.Words += len(strings.Fields(s.Heading))
+	return VisitChildren(c, s.Children)
+}
+
+func (c *WordCount) VisitParagraph(p *Paragraph) error {
+	c.Words += len(strings.Fields(p.Text))
+	return nil
+}
+
+func (c *WordCount) VisitTable(t *Table) error {
+	for _, cell := range t.Header {
+		c.Words += len(strings.Fields(cell))
+	}
+	for _, row := range t.Rows {
+		for _, cell := range row {
+			c.Words += len(strings.Fields(cell))
+		}
+	}
+	return nil
+}
+
+// VisitImage не считает подпись: её не видно в тексте.
+func (c *WordCount) VisitImage(i *Image) error { return nil }
+
+// HTML экспортирует документ; уровень заголовка растёт с вложенностью секций.
+type HTML struct {
+	b     strings.Builder
+	depth int
+}
+
+func (h *HTML) String() string { return h.b.String() }
+
+func (h *HTML) VisitSection(s *Section) error {
+	level := min(h.depth+1, 6)
+	fmt.Fprintf(&h.b, "<section>\n<h%d>%s</h%d>\n", level, html.EscapeString(s.Heading), level)
+	h.depth++
+	err := VisitChildren(h, s.Children)
+	h.depth--
+	h.b.WriteString("</section>\n")
+	return err
+}
+
+func (h *HTML) VisitParagraph(p *Paragraph) error {
+	fmt.Fprintf(&h.b, "<p>%s</p>\n", html.EscapeString(p.Text))
+	return nil
+}
+
+func (h *HTML) VisitTable(t *Table) error {
+	h.b.WriteString("<table>\n")
+	h.row("th", t.Header)
+	for _, r := range t.Rows {
+		h.row("td", r)
+	}
+	h.b.WriteString("</table>\n")
+	return nil
+}
+
+func (h *HTML) row(tag string, cells []string) {
+	h.b.WriteString("<tr>")
+	for _, c := range cells {
+		fmt.Fprintf(&h.b, "<%s>%s</%s>", tag, html.EscapeString(c), tag)
+	}
+	h.b.WriteString("</tr>\n")
+}
+
+func (h *HTML) VisitImage(i *Image) error {
+	fmt.Fprintf(&h.b, "<img src=\"%s\" alt=\"%s\">\n", html.EscapeString(i.Src), html.EscapeString(i.Alt))
+	return nil
+}
+
+var ErrInvalid = errors.New("visitor: invalid document")
+
+// Validate собирает все нарушения, а не останавливается на первом; Err объединяет их.
+type Validate struct {
+	Problems []string
+	path     []string
+}
+
+func (v *Validate) Err() error {
+	if len(v.Problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(v.Problems, "; "))
+}
+
+func (v *Validate) report(format string, args ...any) {
+	where := "document"
+	if len(v.path) > 0 {
+		where = strings.Join(v.path, " > ")
+	}
+	v.Problems = append(v.Problems, where+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *Validate) VisitSection(s *Section) error {
+	if strings.TrimSpace(s.Heading) == "" {
+		v.report("section without heading")
+	}
+	if len(s.Children) == 0 {
+		v.report("section %q is empty", s.Heading)
+	}
+	v.path = append(v.path, s.Heading)
+	err := VisitChildren(v, s.Children)
+	v.path = v.path[:len(v.path)-1]
+	return err
+}
+
+func (v *Validate) VisitParagraph(p *Paragraph) error {
+	if strings.TrimSpace(p.Text) == "" {
+		v.report("empty paragraph")
+	}
+	return nil
+}
+
+func (v *Validate) VisitTable(t *Table) error {
+	for i, r := range t.Rows {
+		if len(r) != len(t.Header) {
+			v.report("table row %d has %d cells, header has %d", i+1, len(r), len(t.Header))
+		}
+	}
+	return nil
+}
+
+func (v *Validate) VisitImage(i *Image) error {
+	if strings.TrimSpace(i.Alt) == "" {
+		v.report("image %s has no alt text", i.Src)
+	}
+	return nil
+}
+
+var (
+	_ Visitor = (*WordCount)(nil)
+	_ Visitor = (*HTML)(nil)
+	_ Visitor = (*Validate)(nil)
+)
@@ -0,0 +1,302 @@
+// Package di - небольшой контейнер внедрения зависимостей для сборки приложения в main.
+// Конструктор регистрируется под типом, который он возвращает (обычно интерфейсом),
+// и вызывается лениво - при первом Resolve. Код приложения контейнера не видит: он
+// получает зависимости аргументами конструкторов, а контейнер только решает, кто их создаёт.
+//
+// Время жизни: Singleton создаётся один раз на контейнер, Scoped - один раз на Scope
+// (например, на запрос), Transient - при каждом Resolve. Singleton строится в корне
+// контейнера и не может зависеть от Scoped, иначе он удержал бы объект одной области.
+// Цикл между конструкторами обнаруживается при разрешении и возвращается как ErrCycle.
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotRegistered = errors.New("di: not registered")
+	ErrCycle         = errors.New("di: dependency cycle")
+	ErrLifetime      = errors.New("di: scoped dependency outside a scope")
+	ErrClosed        = errors.New("di: closed")
+)
+
+type Lifetime int
+
+const (
+	Singleton Lifetime = iota
+	Scoped
+	Transient
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Scoped:
+		return "scoped"
+	case Transient:
+		return "transient"
+	}
+	return fmt.Sprintf("Lifetime(%d)", int(l))
+}
+
+// Resolver - то, из чего можно получить зависимость: контейнер, область или
+// аргумент конструктора.
+type Resolver interface {
+	resolve(t reflect.Type, path []reflect.Type) (any, error)
+}
+
+type provider struct {
+	lifetime Lifetime
+	build    func(r Resolver) (any, error)
+}
+
+type Container struct {
+	mu        sync.RWMutex
+	providers map[reflect.Type]provider
+	root      *Scope
+}
+
+var _ Resolver = (*Container)(nil)
+
+func New() *Container {
+	c := &Container{providers: map[reflect.Type]provider{}}
+	c.root = &Scope{c: c, root: true, instances: map[reflect.Type]any{}}
+	return c
+}
+
+// Provide регистрирует конструктор T. Повторная регистрация того же типа заменяет
+// прежнюю - так main подменяет реализацию по флагу.
+func Provide[T any](c *Container, lifetime Lifetime, ctor func(r Resolver) (T, error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.providers[reflect.TypeFor[T]()] = provider{lifetime: lifetime, build: func(r Resolver) (any, error) {
+		return ctor(r)
+	}}
+}
+
+// Value регистрирует готовый объект как Singleton.
+func Value[T any](c *Container, v T) {
+	Provide(c, Singleton, func(Resolver) (T, error) { return v, nil })
+}
+
+// Bind отдаёт под интерфейсом I то, что зарегистрировано как T. Собственного
+// времени жизни у I нет: каждый Resolve[I] - это Resolve[T].
+func Bind[I, T any](c *Container) {
+	Provide(c, Transient, func(r Resolver) (I, error) {
+		v, err := Resolve[T](r)
+		if err != nil {
+			var zero I
+			return zero, err
+		}
+		i, ok := any(v).(I)
+		if !ok {
+			var zero I
+			return zero, fmt.Errorf("di: %s does not implement %s", reflect.TypeFor[T](), reflect.TypeFor[I]())
+		}
+		return i, nil
+	})
+}
+
+// Resolve возвращает T, при необходимости создав его и всё, от чего он зависит.
+func Resolve[T any](r Resolver) (T, error) {
+	var zero T
+	v, err := r.resolve(reflect.TypeFor[T](), nil)
+	if err != nil {
+		return zero, err
+	}
+	if v == nil {
+		return zero, nil
+	}
+	return v.(T), nil
+}
+
+// MustResolve - Resolve, который паникует при ошибке. Внутри конструктора паника
+// становится ошибкой внешнего Resolve, так что зависимости можно брать без if err.
+func MustResolve[T any](r Resolver) T {
+	v, err := Resolve[T](r)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (c *Container) provider(t reflect.Type) (provider, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	p, ok := c.providers[t]
+	return p, ok
+}
+
+func (c *Container) resolve(t reflect.Type, path []reflect.Type) (any, error) {
+	return c.root.resolve(t, path)
+}
+
+// Scope открывает область для Scoped-зависимостей; Singleton берутся из корня.
+func (c *Container) Scope() *Scope {
+	return &Scope{c: c, instances: map[reflect.Type]any{}}
+}
+
+// Close закрывает созданные Singleton в обратном порядке создания.
+func (c *Container) Close() error {
+	return c.root.Close()
+}
+
+// Scope хранит Scoped-объекты одной области и закрывает их в Close.
+type Scope struct {
+	c    *Container
+	root bool
+
+	// mu держится, пока строится зависимость верхнего уровня, поэтому
+	// конструкторы одной области не выполняются одновременно.
+	mu        sync.Mutex
+	instances map[reflect.Type]any
+	order     []any
+	closed    bool
+}
+
+var _ Resolver = (*Scope)(nil)
+
+func (s *Scope) resolve(t reflect.Type, path []reflect.Type) (any, error) {
+	p, ok := s.c.provider(t)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s%s", ErrNotRegistered, t, via(path))
+	}
+	for i, seen := range path {
+		if seen == t {
+			return nil, fmt.Errorf("%w: %s", ErrCycle, chain(append(path[i:], t)))
+		}
+	}
+	path = append(path[:len(path):len(path)], t)
+	switch p.lifetime {
+	case Transient:
+		return s.build(p, path)
+	case Singleton:
+		if !s.root {
+			return s.c.root.cached(p, t, path)
+		}
+	case Scoped:
+		if s.root {
+			return nil, fmt.Errorf("%w: %s", ErrLifetime, chain(path))
+		}
+	}
+	return s.cached(p, t, path)
+}
+
+// cached вызывается для типов этой области. Вложенные разрешения идут через
+// resolution с уже взятым замком; замок области берёт только первый уровень.
+func (s *Scope) cached(p provider, t reflect.Type, path []reflect.Type) (any, error) {
+	if len(path) == 1 || !s.locked(path) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+	if s.closed {
+		return nil, ErrClosed
+	}
+	if v, ok := s.instances[t]; ok {
+		return v, nil
+	}
+	v, err := s.build(p, path)
+	if err != nil {
+		return nil, err
+	}
+	s.instances[t] = v
+	s.order = append(s.order, v)
+	return v, nil
+}
+
+// locked - строится ли path уже под замком этой области: так бывает, когда
+// одна зависимость области требует другую той же области.
+func (s *Scope) locked(path []reflect.Type) bool {
+	for _, t := range path[:len(path)-1] {
+		p, ok := s.c.provider(t)
+		if !ok {
+			continue
+		}
+		if (s.root && p.lifetime == Singleton) || (!s.root && p.lifetime == Scoped) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scope) build(p provider, path []reflect.Type) (v any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			rerr, ok := r.(error)
+			if !ok || !resolveError(rerr) {
+				panic(r)
+			}
+			v, err = nil, rerr
+		}
+	}()
+	v, err = p.build(&resolution{scope: s, path: path})
+	if err != nil && !resolveError(err) {
+		err = &buildError{path: chain(path), err: err}
+	}
+	return v, err
+}
+
+// resolveError - ошибка уже с путём: её не нужно оборачивать на каждом уровне.
+func resolveError(err error) bool {
+	var be *buildError
+	return errors.As(err, &be) || errors.Is(err, ErrNotRegistered) || errors.Is(err, ErrCycle) ||
+		errors.Is(err, ErrLifetime) || errors.Is(err, ErrClosed)
+}
+
+// buildError - ошибка конструктора с путём до него.
+type buildError struct {
+	path string
+	err  error
+}
+
+func (e *buildError) Error() string { return "di: build " + e.path + ": " + e.err.Error() }
+
+func (e *buildError) Unwrap() error { return e.err }
+
+// Close закрывает объекты области с методом Close в обратном порядке создания.
+func (s *Scope) Close() error {
+	s.mu.Lock()
+	order := s.order
+	s.order, s.instances, s.closed = nil, nil, true
+	s.mu.Unlock()
+	var errs []error
+	for i := len(order) - 1; i >= 0; i-- {
+		switch v := order[i].(type) {
+		case interface{ Close() error }:
+			errs = append(errs, v.Close())
+		case interface{ Close() }:
+			v.Close()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolution - аргумент конструктора: область плюс путь разрешения для поиска циклов.
+type resolution struct {
+	scope *Scope
+	path  []reflect.Type
+}
+
+func (r *resolution) resolve(t reflect.Type, _ []reflect.Type) (any, error) {
+	return r.scope.resolve(t, r.path)
+}
+
+func chain(path []reflect.Type) string {
+	names := make([]string, len(path))
+	for i, t := range path {
+		names[i] = t.String()
+	}
+	return strings.Join(names, " -> ")
+}
+
+func via(path []reflect.Type) string {
+	if len(path) == 0 {
+		return ""
+	}
+	return " (needed by " + chain(path) + ")"
+}
@@ -0,0 +1,145 @@
+package discovery
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BalancerConfig задаёт кэширование и пассивную проверку здоровья. Нулевые поля
+// заменяются значениями по умолчанию.
+type BalancerConfig struct {
+	// TTL - как долго список экземпляров берётся из кэша без обращения к Resolver.
+	TTL time.Duration
+	// MaxFails - сколько ошибок подряд исключают экземпляр из выбора.
+	MaxFails int
+	// Cooldown - на сколько исключается экземпляр.
+	Cooldown time.Duration
+}
+
+// Balancer выбирает экземпляр взвешенным round robin, пропуская тех, кто недавно
+// отвечал ошибками. Если исключены все, выбирает из всех: лучше попытаться, чем отказать.
+type Balancer struct {
+	r   Resolver
+	cfg BalancerConfig
+	now func() time.Time
+
+	mu    sync.Mutex
+	pools map[string]*pool
+}
+
+type pool struct {
+	eps     []Endpoint
+	fetched time.Time
+	current map[string]int
+	fails   map[string]int
+	down    map[string]time.Time
+}
+
+func NewBalancer(r Resolver, cfg BalancerConfig) *Balancer {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 10 * time.Second
+	}
+	if cfg.MaxFails <= 0 {
+		cfg.MaxFails = 3
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	return &Balancer{r: r, cfg: cfg, now: time.Now, pools: map[string]*pool{}}
+}
+
+// Pick возвращает экземпляр для следующего запроса к service. Если Resolver
+// недоступен, используется последний известный список.
+func (b *Balancer) Pick(ctx context.Context, service string) (Endpoint, error) {
+	p, err := b.pool(ctx, service)
+	if err != nil {
+		return Endpoint{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	healthy := make([]Endpoint, 0, len(p.eps))
+	for _, e := range p.eps {
+		if now.After(p.down[e.Addr]) {
+			healthy = append(healthy, e)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = p.eps
+	}
+	// Плавный взвешенный round robin: не выдаёт один тяжёлый экземпляр подряд.
+	total, best := 0, -1
+	for i, e := range healthy {
+		w := max(e.Weight, 1)
+		total += w
+		p.current[e.Addr] += w
+		if best < 0 || p.current[e.Addr] > p.current[healthy[best].Addr] {
+			best = i
+		}
+	}
+	chosen := healthy[best]
+	p.current[chosen.Addr] -= total
+	return chosen, nil
+}
+
+// Report сообщает итог запроса к экземпляру; nil сбрасывает счётчик ошибок.
+func (b *Balancer) Report(service, addr string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p, ok := b.pools[service]
+	if !ok {
+		return
+	}
+	if err == nil {
+		delete(p.fails, addr)
+		return
+	}
+	p.fails[addr]++
+	if p.fails[addr] >= b.cfg.MaxFails {
+		p.down[addr] = b.now().Add(b.cfg.Cooldown)
+		delete(p.fails, addr)
+	}
+}
+
+// Down перечисляет исключённые сейчас экземпляры service.
+func (b *Balancer) Down(service string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []string
+	if p, ok := b.pools[service]; ok {
+		now := b.now()
+		for _, e := range p.eps {
+			if now.Before(p.down[e.Addr]) {
+				out = append(out, e.Addr)
+			}
+		}
+	}
+	return out
+}
+
+func (b *Balancer) pool(ctx context.Context, service string) (*pool, error) {
+	b.mu.Lock()
+	p, ok := b.pools[service]
+	fresh := ok && b.now().Sub(p.fetched) < b.cfg.TTL
+	b.mu.Unlock()
+	if fresh {
+		return p, nil
+	}
+	// Resolver вызывается без блокировки: DNS или Consul могут отвечать долго.
+	eps, err := b.r.Resolve(ctx, service)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p, ok = b.pools[service]; !ok {
+		if err != nil {
+			return nil, err
+		}
+		p = &pool{current: map[string]int{}, fails: map[string]int{}, down: map[string]time.Time{}}
+		b.pools[service] = p
+	}
+	if err == nil {
+		p.eps = eps
+	}
+	p.fetched = b.now()
+	return p, nil
+}
@@ -0,0 +1,116 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"solid/resilience/breaker"
+	"solid/resilience/retry"
+)
+
+// Consul читает экземпляры из HTTP API агента: /v1/health/service/<name>?passing.
+// Экземпляры с непройденными проверками Consul не отдаёт.
+type Consul struct {
+	// Addr - адрес агента, например http://localhost:8500.
+	Addr string
+	// Tag, если задан, отбирает экземпляры с этим тегом.
+	Tag    string
+	Token  string
+	Client *http.Client
+	// Breaker, если задан, перестаёт дёргать недоступного агента; Balancer тем
+	// временем работает по последнему известному списку.
+	Breaker *breaker.Breaker
+	// Retry - повторы запроса к агенту; нулевая политика - значения retry по умолчанию.
+	Retry retry.Policy
+}
+
+var _ Resolver = (*Consul)(nil)
+
+type consulEntry struct {
+	Node struct {
+		Address string
+	}
+	Service struct {
+		Address string
+		Port    int
+		Tags    []string
+		Weights struct {
+			Passing int
+		}
+	}
+}
+
+func (c *Consul) Resolve(ctx context.Context, service string) ([]Endpoint, error) {
+	q := url.Values{"passing": {"1"}}
+	if c.Tag != "" {
+		q.Set("tag", c.Tag)
+	}
+	u := strings.TrimSuffix(c.Addr, "/") + "/v1/health/service/" + url.PathEscape(service) + "?" + q.Encode()
+	// Разомкнутый выключатель повторять бессмысленно: он и ответил мгновенно.
+	p, retryable := c.Retry, c.Retry.Retryable
+	p.Retryable = func(err error) bool {
+		return !errors.Is(err, breaker.ErrOpen) && (retryable == nil || retryable(err))
+	}
+	entries, err := retry.DoValue(ctx, p, func(ctx context.Context) ([]consulEntry, error) {
+		return breaker.Do(c.Breaker, func() ([]consulEntry, error) { return c.fetch(ctx, u) })
+	})
+	if err != nil {
+		return nil, fmt.Errorf("discovery: consul %s: %w", service, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w for %q", ErrNoEndpoints, service)
+	}
+	eps := make([]Endpoint, 0, len(entries))
+	for _, e := range entries {
+		// Адрес сервиса может быть пустым - тогда сервис слушает на адресе узла.
+		host := e.Service.Address
+		if host == "" {
+			host = e.Node.Address
+		}
+		eps = append(eps, Endpoint{
+			Addr:   net.JoinHostPort(host, strconv.Itoa(e.Service.Port)),
+			Weight: e.Service.Weights.Passing,
+			Tags:   e.Service.Tags,
+		})
+	}
+	sortEndpoints(eps)
+	return eps, nil
+}
+
+func (c *Consul) fetch(ctx context.Context, u string) ([]consulEntry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, retry.Permanent(err)
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Consul-Token", c.Token)
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusForbidden, resp.StatusCode == http.StatusNotFound:
+		// Неверный токен или адрес - повтор не поможет.
+		return nil, retry.Permanent(fmt.Errorf("status %d", resp.StatusCode))
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var entries []consulEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,95 @@
+// Package discovery находит адреса сервисов по имени. Resolver отвечает, где сервис
+// живёт (статический список, DNS SRV, Consul), Balancer - к какому экземпляру идти сейчас.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"solid/resilience/breaker"
+)
+
+var ErrNoEndpoints = errors.New("discovery: no endpoints")
+
+// Endpoint - один экземпляр сервиса.
+type Endpoint struct {
+	// Addr - host:port.
+	Addr string
+	// Weight - вес из SRV-записи или метаданных; 0 значит «как у всех».
+	Weight int
+	Tags   []string
+}
+
+func (e Endpoint) String() string { return e.Addr }
+
+// Resolver возвращает текущие экземпляры сервиса. Если реестр знает о проверках
+// здоровья (Consul), в ответ попадают только здоровые экземпляры.
+type Resolver interface {
+	Resolve(ctx context.Context, service string) ([]Endpoint, error)
+}
+
+// Static - адреса из конфигурации: имя сервиса → список host:port.
+type Static map[string][]string
+
+var _ Resolver = Static(nil)
+
+func (s Static) Resolve(ctx context.Context, service string) ([]Endpoint, error) {
+	addrs := s[service]
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("%w for %q", ErrNoEndpoints, service)
+	}
+	eps := make([]Endpoint, len(addrs))
+	for i, a := range addrs {
+		eps[i] = Endpoint{Addr: a}
+	}
+	return eps, nil
+}
+
+// ParseStatic разбирает строку вида "library=h1:8080,h2:8080;pricing=h3:8082".
+func ParseStatic(s string) (Static, error) {
+	st := Static{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, list, ok := strings.Cut(part, "=")
+		if !ok || name == "" || list == "" {
+			return nil, fmt.Errorf("discovery: invalid static entry %q", part)
+		}
+		for _, a := range strings.Split(list, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				st[name] = append(st[name], a)
+			}
+		}
+	}
+	return st, nil
+}
+
+// sortEndpoints упорядочивает экземпляры, чтобы порядок не зависел от ответа реестра.
+func sortEndpoints(eps []Endpoint) {
+	sort.Slice(eps, func(i, j int) bool { return eps[i].Addr < eps[j].Addr })
+}
+
+// New создаёт Resolver по виду и параметру из флагов командной строки:
+//
+//	static  library=localhost:8080;pricing=localhost:8082
+//	dns     service.consul (ищутся записи _<service>._tcp.service.consul)
+//	consul  http://localhost:8500
+func New(kind, target string) (Resolver, error) {
+	switch kind {
+	case "static":
+		return ParseStatic(target)
+	case "dns":
+		return &DNS{Domain: target}, nil
+	case "consul":
+		if target == "" {
+			target = "http://localhost:8500"
+		}
+		return &Consul{Addr: target, Breaker: breaker.New(breaker.Config{})}, nil
+	}
+	return nil, fmt.Errorf("discovery: unknown resolver %q", kind)
+}
@@ -0,0 +1,48 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DNS ищет SRV-записи _service._tcp.Domain, например _pricing._tcp.service.consul.
+type DNS struct {
+	Domain string
+	// Resolver по умолчанию - net.DefaultResolver.
+	Resolver *net.Resolver
+}
+
+var _ Resolver = (*DNS)(nil)
+
+func (d *DNS) Resolve(ctx context.Context, service string) ([]Endpoint, error) {
+	r := d.Resolver
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	_, srvs, err := r.LookupSRV(ctx, service, "tcp", d.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: dns srv %s: %w", service, err)
+	}
+	if len(srvs) == 0 {
+		return nil, fmt.Errorf("%w for %q", ErrNoEndpoints, service)
+	}
+	// Берём только записи с наименьшим приоритетом, как того требует RFC 2782;
+	// остальные - резерв, которым DNS-сервер управляет сам.
+	best := srvs[0].Priority
+	for _, s := range srvs {
+		best = min(best, s.Priority)
+	}
+	var eps []Endpoint
+	for _, s := range srvs {
+		if s.Priority != best {
+			continue
+		}
+		host := strings.TrimSuffix(s.Target, ".")
+		eps = append(eps, Endpoint{Addr: net.JoinHostPort(host, strconv.Itoa(int(s.Port))), Weight: int(s.Weight)})
+	}
+	sortEndpoints(eps)
+	return eps, nil
+}
@@ -0,0 +1,305 @@
+// Package eventbus - внутрипроцессная шина событий с типизированными темами.
+// Тема Topic[T] несёт события одного типа, подписчик получает их синхронно или через
+// собственный буфер (Async); ошибка и паника подписчика не доходят ни до издателя,
+// ни до остальных подписчиков, а уходят в Config.OnError. Буферы Async-подписчиков
+// держит pubsub-хаб темы, и полный буфер ведёт себя по политике подписчика (Overflow).
+//
+// С журналом (Config.Journal) каждое событие сохраняется в data.Storage. Постоянный
+// подписчик (Durable) хранит там же курсор и после перезапуска получает всё, что
+// пропустил. Доставка «хотя бы один раз»: обработчики должны переносить повторы.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"solid/concurrency/flow"
+	"solid/concurrency/pubsub"
+	"solid/data"
+)
+
+var (
+	ErrClosed = errors.New("eventbus: closed")
+	// ErrJournal - событие доставлено подписчикам, но не сохранено в журнал.
+	ErrJournal = errors.New("eventbus: journal unavailable")
+)
+
+// Topic - имя темы и тип её событий. Имя не должно содержать "/".
+type Topic[T any] struct {
+	Name string
+}
+
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{Name: name}
+}
+
+// TopicOf - тема по умолчанию для типа T, например "library.BookAdded". В неё же
+// попадают события, опубликованные через Bus.Publish без объявленной темы.
+func TopicOf[T any]() Topic[T] {
+	return Topic[T]{Name: reflect.TypeFor[T]().String()}
+}
+
+// Failure - сбой подписчика или журнала. У сбоя сброса пачки журнала (JournalBatch)
+// нет ни темы, ни номера.
+type Failure struct {
+	Topic      string
+	Subscriber string
+	Seq        int64
+	Err        error
+}
+
+func (f Failure) Error() string {
+	where := f.Topic
+	if f.Seq > 0 {
+		where += fmt.Sprintf(" #%d", f.Seq)
+	}
+	if f.Subscriber != "" {
+		where += " -> " + f.Subscriber
+	}
+	if where == "" {
+		// Сбой пачки журнала: в ней события разных тем.
+		return f.Err.Error()
+	}
+	return fmt.Sprintf("eventbus: %s: %v", where, f.Err)
+}
+
+func (f Failure) Unwrap() error { return f.Err }
+
+type Config struct {
+	// Journal - где хранить события и курсоры постоянных подписчиков; без него
+	// шина только доставляет, а Durable и FromStart ничего не повторяют.
+	Journal data.Storage
+	// JournalBatch, если задан, копит записи журнала и сохраняет их пачками: Publish
+	// не ждёт хранилище на каждом событии, а сбои сброса по интервалу уходят в
+	// OnError. Записи разных тем копятся в кольцевом буфере без замков (ring), так что
+	// издатели тем не ждут друг друга. Close сбрасывает накопленное; при аварийной
+	// остановке теряется то, что не успело сброситься, - не больше вместимости буфера,
+	// пары пачек. Без OnError в JournalBatch сбои пишутся, как и остальные.
+	JournalBatch *flow.BatchConfig
+	// OnError получает сбои подписчиков и журнала, по умолчанию они пишутся в log.
+	// Вызывается из горутин подписчиков Async, в том числе одновременно.
+	OnError func(Failure)
+	// Hooks наблюдают за буферами Async-подписчиков, например metrics.Registry.PubSub;
+	// хаб называется по теме, подписчик - по имени Durable.
+	Hooks pubsub.Hooks
+}
+
+type Bus struct {
+	cfg     Config
+	journal *journal
+
+	mu     sync.Mutex
+	topics map[string]*topic
+	types  map[reflect.Type]string
+	closed bool
+}
+
+func New(cfg Config) *Bus {
+	b := &Bus{cfg: cfg, topics: map[string]*topic{}, types: map[reflect.Type]string{}}
+	if cfg.Journal != nil {
+		batch := cfg.JournalBatch
+		if batch != nil && batch.OnError == nil {
+			bc := *batch
+			bc.OnError = func(err error) { b.report(Failure{Err: fmt.Errorf("%w: %w", ErrJournal, err)}) }
+			batch = &bc
+		}
+		b.journal = newJournal(cfg.Journal, batch)
+	}
+	return b
+}
+
+type topic struct {
+	name string
+	typ  reflect.Type
+
+	// mu упорядочивает публикации темы: номер, запись в журнал и доставка идут под ним.
+	mu     sync.Mutex
+	seq    int64
+	loaded bool
+	subs   []*Subscription
+	// hub раздаёт живые события Async-подписчикам темы.
+	hub *pubsub.Hub[queued]
+}
+
+// delivery - событие для подписчика: значение при живой доставке, JSON при повторе.
+type delivery struct {
+	seq   int64
+	event any
+	raw   json.RawMessage
+}
+
+// Publish публикует событие в тему t. Ошибка означает, что событие не записано
+// в журнал; живые подписчики его всё равно получили.
+func Publish[T any](ctx context.Context, b *Bus, t Topic[T], event T) error {
+	tp, err := b.topic(t.Name, reflect.TypeFor[T]())
+	if err != nil {
+		return err
+	}
+	return tp.publish(ctx, b, event)
+}
+
+// Publish публикует событие в тему его типа, объявленную через Subscribe или
+// Publish, а без неё - в TopicOf. Метод совпадает с library.Publisher и
+// data.Publisher; сбои уходят в Config.OnError.
+func (b *Bus) Publish(ctx context.Context, event any) {
+	if event == nil {
+		return
+	}
+	typ := reflect.TypeOf(event)
+	b.mu.Lock()
+	name, ok := b.types[typ]
+	b.mu.Unlock()
+	if !ok {
+		name = typ.String()
+	}
+	tp, err := b.topic(name, typ)
+	if err == nil {
+		err = tp.publish(ctx, b, event)
+	}
+	if err != nil {
+		b.report(Failure{Topic: name, Err: err})
+	}
+}
+
+func (b *Bus) topic(name string, typ reflect.Type) (*topic, error) {
+	if name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("eventbus: invalid topic name %q", name)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	tp, ok := b.topics[name]
+	if !ok {
+		tp = &topic{name: name, typ: typ, hub: pubsub.New[queued](pubsub.Config{Name: name, Hooks: b.cfg.Hooks})}
+		b.topics[name] = tp
+	}
+	if tp.typ != typ {
+		return nil, fmt.Errorf("eventbus: topic %q carries %s, not %s", name, tp.typ, typ)
+	}
+	if _, ok := b.types[typ]; !ok {
+		b.types[typ] = name
+	}
+	return tp, nil
+}
+
+// load узнаёт последний номер темы из журнала; вызывается под tp.mu.
+func (tp *topic) load(ctx context.Context, j *journal) error {
+	if tp.loaded || j == nil {
+		tp.loaded = true
+		return nil
+	}
+	last, err := j.last(ctx, tp.name)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrJournal, tp.name, err)
+	}
+	tp.seq, tp.loaded = last, true
+	return nil
+}
+
+func (tp *topic) publish(ctx context.Context, b *Bus, event any) error {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if b.isClosed() {
+		return ErrClosed
+	}
+	if err := tp.load(ctx, b.journal); err != nil {
+		return err
+	}
+	tp.seq++
+	d := delivery{seq: tp.seq, event: event}
+	var jerr error
+	if b.journal != nil {
+		raw, err := json.Marshal(event)
+		if err != nil {
+			tp.seq--
+			return fmt.Errorf("eventbus: encode %s: %w", tp.name, err)
+		}
+		rec := Record{Topic: tp.name, Seq: d.seq, At: time.Now().UTC(), Data: raw}
+		if err := b.journal.append(ctx, rec); err != nil {
+			jerr = fmt.Errorf("%w: %s #%d: %w", ErrJournal, tp.name, d.seq, err)
+		}
+	}
+	for _, s := range tp.subs {
+		if s.sub == nil {
+			s.run(ctx, d)
+		}
+	}
+	tp.hub.Publish(ctx, queued{ctx: context.WithoutCancel(ctx), d: d})
+	return jerr
+}
+
+func (b *Bus) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+func (b *Bus) report(f Failure) {
+	if b.cfg.OnError != nil {
+		b.cfg.OnError(f)
+		return
+	}
+	log.Print(f)
+}
+
+// Close отписывает всех, дожидается обработки уже принятых асинхронных событий и
+// сбрасывает накопленную пачку журнала.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	b.closed = true
+	topics := make([]*topic, 0, len(b.topics))
+	for _, tp := range b.topics {
+		topics = append(topics, tp)
+	}
+	b.mu.Unlock()
+	for _, tp := range topics {
+		tp.mu.Lock()
+		subs := tp.subs
+		tp.subs = nil
+		tp.mu.Unlock()
+		for _, s := range subs {
+			s.stop()
+		}
+		tp.hub.Close()
+	}
+	// Подписчики остановлены, их курсоры - в пачке вместе с записями.
+	if b.journal != nil {
+		if err := b.journal.close(context.Background()); err != nil {
+			b.report(Failure{Err: fmt.Errorf("%w: %w", ErrJournal, err)})
+		}
+	}
+}
+
+// Replay читает журнал темы после номера after по порядку - например, чтобы
+// перестроить проекцию. Без журнала повторять нечего.
+func Replay[T any](ctx context.Context, b *Bus, t Topic[T], after int64, fn func(ctx context.Context, seq int64, e T) error) error {
+	if _, err := b.topic(t.Name, reflect.TypeFor[T]()); err != nil {
+		return err
+	}
+	if b.journal == nil {
+		return nil
+	}
+	recs, err := b.journal.after(ctx, t.Name, after)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrJournal, t.Name, err)
+	}
+	for _, rec := range recs {
+		var e T
+		if err := json.Unmarshal(rec.Data, &e); err != nil {
+			return fmt.Errorf("eventbus: decode %s #%d: %w", t.Name, rec.Seq, err)
+		}
+		if err := fn(ctx, rec.Seq, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
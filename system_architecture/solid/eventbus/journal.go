@@ -0,0 +1,157 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"solid/concurrency/flow"
+	"solid/data"
+)
+
+// Record - событие в журнале. Номер Seq растёт в пределах темы.
+type Record struct {
+	Topic string          `json:"topic"`
+	Seq   int64           `json:"seq"`
+	At    time.Time       `json:"at"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// journal раскладывает события по ключам eventbus/journal/<тема>/<номер>: номер
+// дополнен нулями, так что List возвращает записи по порядку.
+//
+// С batch записи и курсоры копятся и сохраняются пачками, в порядке публикации:
+// курсор не оказывается в хранилище раньше записи, до которой он дошёл. Чтение
+// сначала сбрасывает накопленное, поэтому видит всё опубликованное.
+type journal struct {
+	s     data.Storage
+	batch *flow.Batcher[write]
+}
+
+// write - отложенное сохранение.
+type write struct {
+	key, data string
+}
+
+func newJournal(s data.Storage, batch *flow.BatchConfig) *journal {
+	j := &journal{s: s}
+	if batch != nil {
+		j.batch = flow.NewBatcher(*batch, j.saveAll)
+	}
+	return j
+}
+
+// saveAll сохраняет пачку по порядку и останавливается на первой ошибке, чтобы
+// следующие записи не обогнали несохранённую.
+func (j *journal) saveAll(ctx context.Context, writes []write) error {
+	for i, w := range writes {
+		if err := j.s.Save(ctx, w.key, w.data); err != nil {
+			return fmt.Errorf("%s (%d writes lost): %w", w.key, len(writes)-i, err)
+		}
+	}
+	return nil
+}
+
+func (j *journal) save(ctx context.Context, key, data string) error {
+	if j.batch == nil {
+		return j.s.Save(ctx, key, data)
+	}
+	return j.batch.Add(ctx, write{key: key, data: data})
+}
+
+func (j *journal) flush(ctx context.Context) error {
+	if j.batch == nil {
+		return nil
+	}
+	return j.batch.Flush(ctx)
+}
+
+func (j *journal) close(ctx context.Context) error {
+	if j.batch == nil {
+		return nil
+	}
+	return j.batch.Close(ctx)
+}
+
+func recordPrefix(topic string) string { return "eventbus/journal/" + topic + "/" }
+
+func cursorKey(topic, sub string) string { return "eventbus/cursor/" + topic + "/" + sub }
+
+func (j *journal) append(ctx context.Context, rec Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return j.save(ctx, fmt.Sprintf("%s%016d", recordPrefix(rec.Topic), rec.Seq), string(b))
+}
+
+// seqs - номера записей темы после after по возрастанию.
+func (j *journal) seqs(ctx context.Context, topic string, after int64) ([]int64, error) {
+	if err := j.flush(ctx); err != nil {
+		return nil, err
+	}
+	prefix := recordPrefix(topic)
+	keys, err := j.s.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var out []int64
+	for _, k := range keys {
+		seq, err := strconv.ParseInt(strings.TrimPrefix(k, prefix), 10, 64)
+		if err != nil || seq <= after {
+			continue
+		}
+		out = append(out, seq)
+	}
+	return out, nil
+}
+
+func (j *journal) last(ctx context.Context, topic string) (int64, error) {
+	seqs, err := j.seqs(ctx, topic, 0)
+	if err != nil || len(seqs) == 0 {
+		return 0, err
+	}
+	return seqs[len(seqs)-1], nil
+}
+
+func (j *journal) after(ctx context.Context, topic string, after int64) ([]Record, error) {
+	seqs, err := j.seqs(ctx, topic, after)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Record, 0, len(seqs))
+	for _, seq := range seqs {
+		raw, err := j.s.Load(ctx, fmt.Sprintf("%s%016d", recordPrefix(topic), seq))
+		if err != nil {
+			return nil, err
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(raw), &rec); err != nil {
+			return nil, fmt.Errorf("decode record %s #%d: %w", topic, seq, err)
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+func (j *journal) cursor(ctx context.Context, topic, sub string) (int64, error) {
+	if err := j.flush(ctx); err != nil {
+		return 0, err
+	}
+	raw, err := j.s.Load(ctx, cursorKey(topic, sub))
+	if errors.Is(err, data.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(raw, 10, 64)
+}
+
+func (j *journal) commit(ctx context.Context, topic, sub string, seq int64) error {
+	return j.save(ctx, cursorKey(topic, sub), strconv.FormatInt(seq, 10))
+}
@@ -0,0 +1,222 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"solid/concurrency/pubsub"
+)
+
+type subConfig struct {
+	async     bool
+	buffer    int
+	overflow  pubsub.Policy
+	durable   string
+	fromStart bool
+}
+
+type Option func(*subConfig)
+
+// Async доставляет события в отдельной горутине подписчика через буфер размером
+// buffer (0 - pubsub.DefaultBuffer). Когда буфер полон, издатель ждёт; если его ctx
+// отменят раньше, событие для этого подписчика пропускается и считается сбоем.
+func Async(buffer int) Option {
+	return func(c *subConfig) {
+		c.async = true
+		c.buffer = buffer
+	}
+}
+
+// Overflow меняет поведение полного буфера Async: вместо ожидания издателя
+// отбросить старое или новое событие либо отключить подписчика. Каждое потерянное
+// событие - сбой в Config.OnError, а постоянный подписчик после него не двигает
+// курсор и получит пропущенное при следующей подписке.
+func Overflow(p pubsub.Policy) Option {
+	return func(c *subConfig) {
+		c.overflow = p
+	}
+}
+
+// Durable делает подписчика постоянным: курсор name хранится в журнале, и при
+// подписке сначала приходят события, пропущенные с прошлого раза. После сбоя
+// курсор не двигается, так что событие и всё, что после него, придут снова.
+func Durable(name string) Option {
+	return func(c *subConfig) {
+		c.durable = name
+	}
+}
+
+// FromStart перед живыми событиями повторяет весь журнал темы - для проекций
+// в памяти, которые после перезапуска строятся заново.
+func FromStart() Option {
+	return func(c *subConfig) {
+		c.fromStart = true
+	}
+}
+
+type queued struct {
+	ctx context.Context
+	d   delivery
+}
+
+// Subscription - подписка на тему; Unsubscribe её отменяет.
+type Subscription struct {
+	bus    *Bus
+	topic  *topic
+	name   string
+	handle func(ctx context.Context, d delivery) error
+
+	// sub - буфер Async-подписчика в pubsub-хабе темы; nil у синхронного.
+	sub  *pubsub.Subscriber[queued]
+	done chan struct{}
+	once sync.Once
+
+	// mu защищает курсор: его двигают и издатель, и горутина Async.
+	mu      sync.Mutex
+	durable bool
+	cursor  int64
+	stuck   bool
+}
+
+// Subscribe подписывает fn на тему t. Повтор пропущенных событий (Durable, FromStart)
+// идёт до возврата, под замком темы: синхронный обработчик не должен публиковать
+// в свою же тему.
+func Subscribe[T any](ctx context.Context, b *Bus, t Topic[T], fn func(ctx context.Context, e T) error, opts ...Option) (*Subscription, error) {
+	var cfg subConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	tp, err := b.topic(t.Name, reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscription{bus: b, topic: tp, name: cfg.durable, durable: cfg.durable != "" && b.journal != nil}
+	s.handle = func(ctx context.Context, d delivery) error {
+		e, ok := d.event.(T)
+		if !ok {
+			if err := json.Unmarshal(d.raw, &e); err != nil {
+				return fmt.Errorf("eventbus: decode: %w", err)
+			}
+		}
+		return fn(ctx, e)
+	}
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if err := tp.load(ctx, b.journal); err != nil {
+		return nil, err
+	}
+	var backlog []Record
+	if b.journal != nil && (s.durable || cfg.fromStart) {
+		var after int64
+		if s.durable {
+			if after, err = b.journal.cursor(ctx, tp.name, s.name); err != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrJournal, tp.name, err)
+			}
+		}
+		if backlog, err = b.journal.after(ctx, tp.name, after); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrJournal, tp.name, err)
+		}
+		s.cursor = after
+	}
+	if cfg.async {
+		s.sub = tp.hub.Subscribe(pubsub.SubConfig[queued]{
+			Name: s.name, Buffer: cfg.buffer, Policy: cfg.overflow,
+			OnDrop: func(q queued, err error) { s.fail(q.d.seq, err) },
+		})
+		s.done = make(chan struct{})
+		go s.loop()
+	}
+	for _, rec := range backlog {
+		s.deliver(ctx, delivery{seq: rec.Seq, raw: rec.Data})
+	}
+	tp.subs = append(tp.subs, s)
+	return s, nil
+}
+
+// deliver передаёт событие одному подписчику - при повторе журнала; живые события
+// Async-подписчикам раздаёт хаб темы.
+func (s *Subscription) deliver(ctx context.Context, d delivery) {
+	if s.sub == nil {
+		s.run(ctx, d)
+		return
+	}
+	s.sub.Deliver(ctx, queued{ctx: context.WithoutCancel(ctx), d: d})
+}
+
+func (s *Subscription) loop() {
+	defer close(s.done)
+	for q := range s.sub.C() {
+		s.run(q.ctx, q.d)
+	}
+}
+
+func (s *Subscription) run(ctx context.Context, d delivery) {
+	if err := s.call(ctx, d); err != nil {
+		s.fail(d.seq, err)
+		return
+	}
+	s.ack(ctx, d.seq)
+}
+
+// call изолирует панику подписчика от издателя и других подписчиков.
+func (s *Subscription) call(ctx context.Context, d delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("subscriber panicked: %v", r)
+		}
+	}()
+	return s.handle(ctx, d)
+}
+
+func (s *Subscription) fail(seq int64, err error) {
+	s.mu.Lock()
+	s.stuck = true
+	s.mu.Unlock()
+	s.bus.report(Failure{Topic: s.topic.name, Subscriber: s.name, Seq: seq, Err: err})
+}
+
+// ack двигает курсор постоянного подписчика, пока обработка идёт без пропусков.
+func (s *Subscription) ack(ctx context.Context, seq int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.durable || s.stuck || seq <= s.cursor {
+		return
+	}
+	s.cursor = seq
+	if err := s.bus.journal.commit(ctx, s.topic.name, s.name, seq); err != nil {
+		s.bus.report(Failure{Topic: s.topic.name, Subscriber: s.name, Seq: seq, Err: fmt.Errorf("%w: %w", ErrJournal, err)})
+	}
+}
+
+// Cursor - номер последнего события, обработанного постоянным подписчиком без пропусков.
+func (s *Subscription) Cursor() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor
+}
+
+// Unsubscribe отменяет подписку и дожидается обработки уже принятых событий.
+func (s *Subscription) Unsubscribe() {
+	s.topic.mu.Lock()
+	for i, other := range s.topic.subs {
+		if other == s {
+			s.topic.subs = append(s.topic.subs[:i:i], s.topic.subs[i+1:]...)
+			break
+		}
+	}
+	s.topic.mu.Unlock()
+	s.stop()
+}
+
+func (s *Subscription) stop() {
+	s.once.Do(func() {
+		if s.sub != nil {
+			s.sub.Close()
+			<-s.done
+		}
+	})
+}
@@ -0,0 +1,216 @@
+// Package featureflags - флаги функций: включить новое поведение для части пользователей,
+// не выкатывая код заново. Правила (Flag) приходят из источника - переменных окружения,
+// JSON-файла или удалённого JSON, - а Client держит последний удачно загруженный снимок
+// и обновляет его опросом. Процентная раскатка привязана к хешу ключа (обычно
+// идентификатора пользователя), поэтому один и тот же пользователь всегда попадает
+// в одну и ту же группу, на любой реплике.
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Flags - проверка флага для ключа раскатки. Неизвестный флаг выключен.
+type Flags interface {
+	Enabled(ctx context.Context, name, key string) bool
+}
+
+// Flag - правило одного флага.
+type Flag struct {
+	// Enabled - общий выключатель: false выключает флаг для всех, включая Users.
+	Enabled bool `json:"enabled"`
+	// Percent - доля ключей в процентах, для которых флаг включён; nil - для всех.
+	// Пустой ключ при неполной раскатке в группу не попадает.
+	Percent *float64 `json:"percent,omitempty"`
+	// Users - ключи, для которых включённый флаг действует независимо от Percent.
+	Users []string `json:"users,omitempty"`
+}
+
+// On - включённый для всех флаг, Rollout - включённый для доли ключей.
+func On() Flag { return Flag{Enabled: true} }
+
+func Rollout(percent float64) Flag { return Flag{Enabled: true, Percent: &percent} }
+
+// For - включён ли флаг name для ключа key.
+func (f Flag) For(name, key string) bool {
+	switch {
+	case !f.Enabled:
+		return false
+	case f.Percent == nil || *f.Percent >= 100 || slices.Contains(f.Users, key):
+		return true
+	case key == "" || *f.Percent <= 0:
+		return false
+	}
+	return bucket(name, key) < *f.Percent
+}
+
+// bucket - место ключа на шкале 0..100 для флага name. Имя входит в хеш, чтобы
+// раскатки разных флагов на 10% не доставались одним и тем же пользователям.
+func bucket(name, key string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(Normalize(name)))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) / 100
+}
+
+// Normalize приводит имя флага к виду, общему для JSON и окружения: "pricing.tax-v2"
+// и FEATURE_PRICING_TAX_V2 - один и тот же флаг pricing_tax_v2.
+func Normalize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '_'
+	}, name)
+}
+
+// Snapshot - правила всех флагов по нормализованным именам. Сам снимок тоже Flags.
+type Snapshot map[string]Flag
+
+var _ Flags = Snapshot(nil)
+
+// Set - снимок из правил с именами в любом написании.
+func Set(flags map[string]Flag) Snapshot {
+	s := make(Snapshot, len(flags))
+	for name, f := range flags {
+		s[Normalize(name)] = f
+	}
+	return s
+}
+
+func (s Snapshot) Enabled(ctx context.Context, name, key string) bool {
+	f, ok := s[Normalize(name)]
+	return ok && f.For(name, key)
+}
+
+// Source загружает текущие правила.
+type Source interface {
+	Load(ctx context.Context) (Snapshot, error)
+}
+
+// SourceFunc - функция как Source.
+type SourceFunc func(ctx context.Context) (Snapshot, error)
+
+func (f SourceFunc) Load(ctx context.Context) (Snapshot, error) { return f(ctx) }
+
+// Merge накладывает источники по порядку: правило из более позднего заменяет
+// правило того же флага из более раннего. Ошибка любого источника - ошибка Merge,
+// чтобы сбойный источник не выключил молча переопределённые им флаги.
+func Merge(sources ...Source) Source {
+	return SourceFunc(func(ctx context.Context) (Snapshot, error) {
+		out := Snapshot{}
+		var errs []error
+		for _, src := range sources {
+			s, err := src.Load(ctx)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for name, f := range s {
+				out[name] = f
+			}
+		}
+		return out, errors.Join(errs...)
+	})
+}
+
+type Config struct {
+	// Interval - период опроса источника; 0 - только первая загрузка и Refresh.
+	Interval time.Duration
+	// OnError получает ошибки опроса; по умолчанию они пишутся в log.
+	OnError func(error)
+}
+
+// Client - Flags поверх источника. Снимок меняется атомарно, Enabled не блокируется
+// на опросе; после ошибки загрузки продолжает действовать прежний снимок.
+type Client struct {
+	src Source
+	cfg Config
+
+	snap atomic.Pointer[Snapshot]
+	stop context.CancelFunc
+	done chan struct{}
+	once sync.Once
+}
+
+var _ Flags = (*Client)(nil)
+
+// New загружает правила и при Interval > 0 запускает опрос до Close. Клиент
+// возвращается и при ошибке первой загрузки: до удачного опроса все флаги выключены.
+func New(ctx context.Context, src Source, cfg Config) (*Client, error) {
+	c := &Client{src: src, cfg: cfg, done: make(chan struct{})}
+	c.snap.Store(&Snapshot{})
+	err := c.Refresh(ctx)
+	if cfg.Interval <= 0 {
+		close(c.done)
+		c.stop = func() {}
+		return c, err
+	}
+	pollCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c.stop = cancel
+	go c.poll(pollCtx)
+	return c, err
+}
+
+// Refresh загружает правила сейчас, не дожидаясь опроса.
+func (c *Client) Refresh(ctx context.Context) error {
+	s, err := c.src.Load(ctx)
+	if err != nil {
+		return err
+	}
+	c.snap.Store(&s)
+	return nil
+}
+
+func (c *Client) poll(ctx context.Context) {
+	defer close(c.done)
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := c.Refresh(ctx); err != nil && ctx.Err() == nil {
+			c.report(err)
+		}
+	}
+}
+
+func (c *Client) report(err error) {
+	if c.cfg.OnError != nil {
+		c.cfg.OnError(err)
+		return
+	}
+	log.Print(err)
+}
+
+func (c *Client) Enabled(ctx context.Context, name, key string) bool {
+	return c.Snapshot().Enabled(ctx, name, key)
+}
+
+// Snapshot - действующие правила; менять возвращённый снимок нельзя.
+func (c *Client) Snapshot() Snapshot {
+	return *c.snap.Load()
+}
+
+// Close останавливает опрос.
+func (c *Client) Close() {
+	c.once.Do(func() {
+		c.stop()
+		<-c.done
+	})
+}
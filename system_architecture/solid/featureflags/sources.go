@@ -0,0 +1,142 @@
+package featureflags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultEnvPrefix - префикс переменных окружения для Env.
+const DefaultEnvPrefix = "FEATURE_"
+
+// Env читает флаги из переменных prefix + ИМЯ: on/true/1 включают флаг для всех,
+// off/false/0 выключают, "25%" раскатывает на четверть ключей, а "users:a,b" включает
+// только для перечисленных. Пустой prefix - DefaultEnvPrefix.
+func Env(prefix string) Source {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	return SourceFunc(func(ctx context.Context) (Snapshot, error) {
+		s := Snapshot{}
+		for _, kv := range os.Environ() {
+			name, value, _ := strings.Cut(kv, "=")
+			if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+				continue
+			}
+			f, err := parseEnv(value)
+			if err != nil {
+				return nil, fmt.Errorf("featureflags: %s: %w", name, err)
+			}
+			s[Normalize(strings.TrimPrefix(name, prefix))] = f
+		}
+		return s, nil
+	})
+}
+
+func parseEnv(value string) (Flag, error) {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(value) {
+	case "on", "true", "1":
+		return On(), nil
+	case "off", "false", "0", "":
+		return Flag{}, nil
+	}
+	if users, ok := strings.CutPrefix(value, "users:"); ok {
+		return Flag{Enabled: true, Percent: new(float64), Users: strings.Split(users, ",")}, nil
+	}
+	if p, ok := strings.CutSuffix(value, "%"); ok {
+		percent, err := strconv.ParseFloat(p, 64)
+		if err == nil && percent >= 0 && percent <= 100 {
+			return Rollout(percent), nil
+		}
+	}
+	return Flag{}, fmt.Errorf("invalid value %q, want on, off, N%% or users:a,b", value)
+}
+
+// decode разбирает JSON-объект «имя флага -> правило», общий для File и Remote.
+func decode(r io.Reader) (Snapshot, error) {
+	var flags map[string]Flag
+	if err := json.NewDecoder(r).Decode(&flags); err != nil {
+		return nil, err
+	}
+	for name, f := range flags {
+		if f.Percent != nil && (*f.Percent < 0 || *f.Percent > 100) {
+			return nil, fmt.Errorf("flag %s: percent %g out of range 0..100", name, *f.Percent)
+		}
+	}
+	return Set(flags), nil
+}
+
+// File читает JSON вида {"pricing.tax-v2": {"enabled": true, "percent": 25}} при
+// каждой загрузке, так что правка файла подхватывается следующим опросом.
+func File(path string) Source {
+	return SourceFunc(func(ctx context.Context) (Snapshot, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("featureflags: %w", err)
+		}
+		defer f.Close()
+		s, err := decode(f)
+		if err != nil {
+			return nil, fmt.Errorf("featureflags: %s: %w", path, err)
+		}
+		return s, nil
+	})
+}
+
+// Remote загружает тот же JSON по HTTP. Ответ с ETag запоминается, и следующий
+// опрос спрашивает If-None-Match: на 304 возвращается прежний снимок без разбора.
+func Remote(url string, client *http.Client) Source {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	r := &remote{url: url, client: client}
+	return SourceFunc(r.load)
+}
+
+type remote struct {
+	url    string
+	client *http.Client
+
+	mu   sync.Mutex
+	etag string
+	last Snapshot
+}
+
+func (r *remote) load(ctx context.Context) (Snapshot, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("featureflags: %w", err)
+	}
+	r.mu.Lock()
+	etag, last := r.etag, r.last
+	r.mu.Unlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("featureflags: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && last != nil:
+		return last, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("featureflags: %s: unexpected status %s", r.url, resp.Status)
+	}
+	s, err := decode(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("featureflags: %s: %w", r.url, err)
+	}
+	r.mu.Lock()
+	r.etag, r.last = resp.Header.Get("ETag"), s
+	r.mu.Unlock()
+	return s, nil
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Pinger - то, что умеет проверить соединение, например *sql.DB. У клиента Redis
+// ping другой формы: Probe: func(ctx context.Context) error { return rdb.Ping(ctx).Err() }.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Ping - проверка хранилища за database/sql.
+func Ping(p Pinger) func(ctx context.Context) error {
+	return p.PingContext
+}
+
+// TCP проверяет, что хотя бы один из адресов принимает соединения: так проверяется
+// брокер, у которого нет своего ping, например Kafka по списку bootstrap-адресов.
+func TCP(addrs ...string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		var errs []error
+		for _, addr := range addrs {
+			conn, err := d.DialContext(ctx, "tcp", addr)
+			if err == nil {
+				return conn.Close()
+			}
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// HTTP проверяет, что url отвечает 2xx, - например /livez соседнего сервиса.
+// client == nil - http.DefaultClient.
+func HTTP(url string, client *http.Client) func(ctx context.Context) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("%s: unexpected status %s", url, resp.Status)
+		}
+		return nil
+	}
+}
+
+// MaxDepth - проверка глубины очереди: отказ, когда в ней больше max элементов.
+// Растущий хвост outbox или очереди заданий значит, что обработчик не успевает.
+func MaxDepth(depth func(ctx context.Context) (int, error), max int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		n, err := depth(ctx)
+		if err != nil {
+			return err
+		}
+		if n > max {
+			return fmt.Errorf("queue depth %d exceeds %d", n, max)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,216 @@
+// Package health собирает именованные проверки сервиса в два HTTP-ответа:
+//
+//	GET /livez   процесс жив и не завис - при отказе его стоит перезапустить
+//	GET /readyz  сервис готов принимать запросы - при отказе балансировщик уводит трафик
+//
+// Зависимости (база, брокер, глубина очереди) проверяются только в /readyz: недоступная
+// база - повод не слать запросы, но не повод перезапускать процесс. У каждой проверки
+// свой дедлайн, а результат кэшируется на TTL, так что частые опросы балансировщика
+// не превращаются в такой же поток запросов к базе.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout - дедлайн одной проверки по умолчанию.
+	DefaultTimeout = 2 * time.Second
+	// DefaultTTL - сколько по умолчанию помнится результат проверки.
+	DefaultTTL = time.Second
+)
+
+// Check - одна проверка.
+type Check struct {
+	Name string
+	// Probe возвращает ошибку, если проверяемое не в порядке.
+	Probe func(ctx context.Context) error
+	// Timeout и TTL - дедлайн проверки и срок жизни результата; 0 - из Config.
+	Timeout time.Duration
+	TTL     time.Duration
+	// Optional - сбой виден в отчёте, но ответ остаётся 200: сервис работает
+	// с деградацией, например без кэша.
+	Optional bool
+}
+
+type Config struct {
+	// Timeout и TTL по умолчанию для проверок; 0 - DefaultTimeout и DefaultTTL.
+	Timeout time.Duration
+	TTL     time.Duration
+}
+
+// Health - проверки живости и готовности одного процесса.
+type Health struct {
+	cfg Config
+
+	mu    sync.Mutex
+	live  []*check
+	ready []*check
+}
+
+func New(cfg Config) *Health {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+	return &Health{cfg: cfg}
+}
+
+// Liveness добавляет проверку в /livez и /readyz: неживой сервис и не готов.
+func (h *Health) Liveness(c Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.live = append(h.live, h.newCheck(c))
+}
+
+// Readiness добавляет проверку только в /readyz.
+func (h *Health) Readiness(c Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ready = append(h.ready, h.newCheck(c))
+}
+
+// Register вешает /livez и /readyz на mux.
+func (h *Health) Register(mux *http.ServeMux) {
+	mux.Handle("GET /livez", h.LiveHandler())
+	mux.Handle("GET /readyz", h.ReadyHandler())
+}
+
+func (h *Health) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		checks := h.live
+		h.mu.Unlock()
+		write(w, run(r.Context(), checks))
+	})
+}
+
+func (h *Health) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		checks := append(append([]*check(nil), h.live...), h.ready...)
+		h.mu.Unlock()
+		write(w, run(r.Context(), checks))
+	})
+}
+
+// Report - ответ /livez и /readyz.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Result - итог одной проверки. Cached - ответ взят из кэша, а не получен сейчас.
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+	Optional bool   `json:"optional,omitempty"`
+	Cached   bool   `json:"cached,omitempty"`
+
+	at time.Time
+}
+
+const (
+	statusOK   = "ok"
+	statusFail = "fail"
+)
+
+func write(w http.ResponseWriter, results map[string]Result) {
+	report := Report{Status: statusOK, Checks: results}
+	for _, res := range results {
+		if res.Status == statusFail && !res.Optional {
+			report.Status = statusFail
+		}
+	}
+	code := http.StatusOK
+	if report.Status != statusOK {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
+
+// run выполняет проверки параллельно; каждая ограничена своим дедлайном.
+func run(ctx context.Context, checks []*check) map[string]Result {
+	results := make(map[string]Result, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := c.result(ctx)
+			mu.Lock()
+			results[c.Name] = res
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+type check struct {
+	Check
+
+	// mu держится всё время проверки: одновременные запросы ждут один результат,
+	// а не запускают по проверке каждый.
+	mu   sync.Mutex
+	last Result
+}
+
+func (h *Health) newCheck(c Check) *check {
+	if c.Timeout <= 0 {
+		c.Timeout = h.cfg.Timeout
+	}
+	if c.TTL <= 0 {
+		c.TTL = h.cfg.TTL
+	}
+	return &check{Check: c}
+}
+
+func (c *check) result(ctx context.Context) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.last.at.IsZero() && time.Since(c.last.at) < c.TTL {
+		res := c.last
+		res.Cached = true
+		return res
+	}
+	// Отменённый клиентом запрос не должен оставить в кэше ложный отказ.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := probe(ctx, c.Probe)
+	res := Result{Status: statusOK, Duration: time.Since(start).Round(time.Microsecond).String(), Optional: c.Optional, at: time.Now()}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		res.Status, res.Error = statusFail, fmt.Sprintf("timed out after %s", c.Timeout)
+	case err != nil:
+		res.Status, res.Error = statusFail, err.Error()
+	}
+	c.last = res
+	return res
+}
+
+// probe не даёт зависшей проверке, не смотрящей на ctx, удержать ответ дольше дедлайна.
+func probe(ctx context.Context, fn func(context.Context) error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
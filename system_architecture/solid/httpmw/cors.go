@@ -0,0 +1,82 @@
+package httpmw
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CORSConfig struct {
+	// Origins - источники, которым браузер разрешит читать ответы; "*" - любой.
+	// Пустой список - CORS выключен, ответы не меняются.
+	Origins []string
+	// Methods - разрешённые методы; пустой - GET, HEAD и POST.
+	Methods []string
+	// Headers - заголовки, которые страница может прислать; пустой - только Content-Type.
+	Headers []string
+	// Expose - заголовки ответа, которые увидит страница, кроме стандартных.
+	Expose []string
+	// Credentials разрешает запросы с cookie; тогда вместо "*" возвращается сам источник.
+	Credentials bool
+	// MaxAge - сколько браузер помнит ответ на предварительный запрос; 0 - решает браузер.
+	MaxAge time.Duration
+}
+
+// CORS отвечает на предварительные запросы OPTIONS и добавляет заголовки
+// Access-Control-* к ответам разрешённым источникам. Ответ незнакомому источнику
+// остаётся без них - читать его браузер странице не даст.
+func CORS(cfg CORSConfig) Middleware {
+	if len(cfg.Origins) == 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+	if len(cfg.Headers) == 0 {
+		cfg.Headers = []string{"Content-Type"}
+	}
+	anyOrigin := slices.Contains(cfg.Origins, "*")
+	methods, headers, expose := strings.Join(cfg.Methods, ", "), strings.Join(cfg.Headers, ", "), strings.Join(cfg.Expose, ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			allowed := anyOrigin || slices.Contains(cfg.Origins, origin)
+			if allowed {
+				if anyOrigin && !cfg.Credentials {
+					h.Set("Access-Control-Allow-Origin", "*")
+				} else {
+					h.Set("Access-Control-Allow-Origin", origin)
+				}
+				if cfg.Credentials {
+					h.Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+			if !preflight {
+				if allowed && expose != "" {
+					h.Set("Access-Control-Expose-Headers", expose)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if allowed {
+				h.Set("Access-Control-Allow-Methods", methods)
+				h.Set("Access-Control-Allow-Headers", headers)
+				if cfg.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge/time.Second)))
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
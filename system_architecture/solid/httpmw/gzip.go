@@ -0,0 +1,171 @@
+package httpmw
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipMinSize - ответы короче не сжимаются: заголовок gzip и затраты на сжатие
+// съедают выигрыш.
+const gzipMinSize = 1024
+
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+// Gzip сжимает ответ, если клиент принимает gzip, ответ не короче gzipMinSize и ещё
+// не сжат: уже закодированные ответы (например, пришедшие через прокси) и картинки,
+// видео, архивы проходят как есть. Решение принимается по первым gzipMinSize байтам
+// или по первому Flush, поэтому потоковые ответы тоже работают.
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipWriter{ResponseWriter: w}
+		next.ServeHTTP(gw, r)
+		// Не в defer: после паники накопленное не отправляется, и Recover ещё может
+		// ответить 500.
+		gw.close()
+	})
+}
+
+// acceptsGzip разбирает Accept-Encoding; gzip;q=0 - явный отказ.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			w, err := strconv.ParseFloat(q, 64)
+			return err == nil && w > 0
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter копит начало ответа, пока не станет ясно, стоит ли его сжимать.
+type gzipWriter struct {
+	http.ResponseWriter
+	status  int
+	buf     []byte
+	decided bool
+	gz      *gzip.Writer
+}
+
+func (g *gzipWriter) WriteHeader(status int) {
+	if status < 200 {
+		g.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if g.status != 0 {
+		return
+	}
+	g.status = status
+	// У этих ответов нет тела - и сжимать нечего.
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		g.decide(false)
+	}
+}
+
+func (g *gzipWriter) Write(b []byte) (int, error) {
+	if g.status == 0 {
+		g.WriteHeader(http.StatusOK)
+	}
+	if !g.decided {
+		g.buf = append(g.buf, b...)
+		if len(g.buf) < gzipMinSize {
+			return len(b), nil
+		}
+		if err := g.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if g.gz != nil {
+		return g.gz.Write(b)
+	}
+	return g.ResponseWriter.Write(b)
+}
+
+// Flush отправляет накопленное: ответ, который сбрасывают по частям, сжимается сразу.
+func (g *gzipWriter) Flush() {
+	if g.status == 0 {
+		g.WriteHeader(http.StatusOK)
+	}
+	if !g.decided {
+		g.decide(true)
+	}
+	if g.gz != nil {
+		g.gz.Flush()
+	}
+	http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+func (g *gzipWriter) Unwrap() http.ResponseWriter { return g.ResponseWriter }
+
+// decide отправляет заголовки и накопленные байты, сжимая их, если compress и ответ
+// того стоит.
+func (g *gzipWriter) decide(compress bool) error {
+	g.decided = true
+	h := g.ResponseWriter.Header()
+	if h.Get("Content-Type") == "" && len(g.buf) > 0 {
+		// Иначе net/http угадает тип по уже сжатым байтам.
+		h.Set("Content-Type", http.DetectContentType(g.buf))
+	}
+	if compress && h.Get("Content-Encoding") == "" && h.Get("Content-Range") == "" && compressible(h.Get("Content-Type")) {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", "gzip")
+		g.gz = gzipWriters.Get().(*gzip.Writer)
+		g.gz.Reset(g.ResponseWriter)
+	}
+	g.ResponseWriter.WriteHeader(g.status)
+	buf := g.buf
+	g.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if g.gz != nil {
+		_, err = g.gz.Write(buf)
+	} else {
+		_, err = g.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close дописывает ответ: короткий уходит несжатым, у сжатого закрывается поток gzip.
+func (g *gzipWriter) close() {
+	if g.status == 0 {
+		return
+	}
+	if !g.decided {
+		g.decide(false)
+	}
+	if g.gz != nil {
+		g.gz.Close()
+		g.gz.Reset(nil)
+		gzipWriters.Put(g.gz)
+		g.gz = nil
+	}
+}
+
+// compressible - стоит ли сжимать тело такого типа: медиа и архивы уже сжаты.
+func compressible(contentType string) bool {
+	ct, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	ct = strings.TrimSpace(ct)
+	switch {
+	case ct == "image/svg+xml":
+		return true
+	case strings.HasPrefix(ct, "image/"), strings.HasPrefix(ct, "video/"), strings.HasPrefix(ct, "audio/"),
+		strings.Contains(ct, "zip"), strings.Contains(ct, "compress"), ct == "application/octet-stream":
+		return false
+	}
+	return true
+}
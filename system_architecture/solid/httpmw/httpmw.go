@@ -0,0 +1,138 @@
+// Package httpmw - общие сквозные обработчики HTTP-серверов: идентификатор запроса,
+// перехват паник, журнал, сжатие, CORS и дедлайн обработчика. Каждый - обычный
+// func(http.Handler) http.Handler, а Chain собирает их в цепочку:
+//
+//	httpmw.Chain(mux, httpmw.RequestID, httpmw.Logging(logger), httpmw.Recover(logger), httpmw.Gzip)
+//
+// Порядок важен: Logging снаружи Recover видит 500 после паники, а RequestID первым
+// даёт идентификатор всем остальным.
+package httpmw
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"solid/logging"
+)
+
+type Middleware = func(http.Handler) http.Handler
+
+// Chain применяет middleware так, что первый в списке выполняется первым.
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen - длиннее клиентский идентификатор не принимается: он попадает в журнал.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// RequestIDFrom возвращает идентификатор текущего запроса.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestID берёт X-Request-ID клиента или создаёт новый, кладёт его в контекст
+// (и в поля журнала logging - как request_id), в заголовок запроса (его подхватят
+// прокси и клиенты дальше по цепочке) и в ответ.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLen {
+			b := make([]byte, 8)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		r.Header.Set(RequestIDHeader, id)
+		w.Header().Set(RequestIDHeader, id)
+		ctx := logging.With(context.WithValue(r.Context(), requestIDKey{}, id), "request_id", id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Logging пишет строку на запрос: идентификатор, метод, путь, статус и время.
+func Logging(logger *log.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &recorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			logger.Printf("%s %s %s %d %s", RequestIDFrom(r.Context()), r.Method, r.URL.Path, rec.code(), time.Since(start).Round(time.Millisecond))
+		})
+	}
+}
+
+// Recover перехватывает панику обработчика, пишет её в журнал со стеком и отвечает 500,
+// если ответ ещё не начат. http.ErrAbortHandler пропускается дальше: им обработчик
+// намеренно обрывает соединение.
+func Recover(logger *log.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &recorder{ResponseWriter: w}
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+				logger.Printf("%s panic: %s %s: %v\n%s", RequestIDFrom(r.Context()), r.Method, r.URL.Path, p, debug.Stack())
+				if rec.status == 0 {
+					writeError(w, http.StatusInternalServerError, "internal server error")
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// recorder запоминает статус ответа; 0 - ответ ещё не начат.
+type recorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *recorder) WriteHeader(status int) {
+	// 1xx - промежуточные ответы, настоящий ещё впереди.
+	if r.status == 0 && status >= 200 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap открывает http.ResponseController доступ к Flush и прочему исходного writer.
+func (r *recorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+// code - статус для журнала: обработчик, не написавший ничего, отвечает 200.
+func (r *recorder) code() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,116 @@
+package httpmw
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Timeout ограничивает обработчик дедлайном d: контекст запроса отменяется, и если
+// к этому времени ответ не начат, клиент получает 503. Начатый ответ не обрывается -
+// Timeout ждёт обработчик, которому отмена контекста велит закончить. В отличие от
+// http.TimeoutHandler ответ не копится в памяти, так что потоковые ответы и прокси
+// работают как обычно. d <= 0 - без ограничения.
+func Timeout(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			tw := &timeoutWriter{w: w, h: make(http.Header)}
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						if p != http.ErrAbortHandler {
+							p = fmt.Sprintf("%v\n\n%s", p, debug.Stack())
+						}
+						panicked <- p
+					}
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+			select {
+			case <-done:
+				return
+			case p := <-panicked:
+				// Паника уходит в горутину сервера, где её перехватит Recover или net/http.
+				panic(p)
+			case <-ctx.Done():
+			}
+			tw.mu.Lock()
+			if !tw.wrote {
+				tw.timedOut = true
+				tw.mu.Unlock()
+				writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("request timed out after %s", d))
+				return
+			}
+			tw.mu.Unlock()
+			select {
+			case <-done:
+			case p := <-panicked:
+				panic(p)
+			}
+		})
+	}
+}
+
+// timeoutWriter пропускает ответ к клиенту, пока его не перехватил дедлайн. Заголовки
+// копятся в своём h, чтобы опоздавший обработчик не трогал заголовки ответа 503.
+type timeoutWriter struct {
+	w http.ResponseWriter
+	h http.Header
+
+	mu       sync.Mutex
+	wrote    bool
+	timedOut bool
+}
+
+func (t *timeoutWriter) Header() http.Header { return t.h }
+
+func (t *timeoutWriter) WriteHeader(status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.writeHeader(status)
+}
+
+func (t *timeoutWriter) writeHeader(status int) {
+	if t.timedOut || t.wrote {
+		return
+	}
+	dst := t.w.Header()
+	for k, v := range t.h {
+		dst[k] = v
+	}
+	if status >= 200 {
+		t.wrote = true
+	}
+	t.w.WriteHeader(status)
+}
+
+func (t *timeoutWriter) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	t.writeHeader(http.StatusOK)
+	return t.w.Write(b)
+}
+
+func (t *timeoutWriter) Flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.timedOut {
+		return
+	}
+	t.writeHeader(http.StatusOK)
+	http.NewResponseController(t.w).Flush()
+}
@@ -0,0 +1,32 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Author - автор как самостоятельная сущность. Book.Author остаётся для совместимости
+// и заполняется именем автора, связь задаётся через Book.AuthorID.
+type Author struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type AuthorRepository interface {
+	AddAuthor(ctx context.Context, a Author) (Author, error)
+	GetAuthor(ctx context.Context, id string) (Author, error)
+	ListAuthors(ctx context.Context) ([]Author, error)
+	BooksByAuthor(ctx context.Context, authorID string, req PageRequest) (Page, error)
+}
+
+func (a Author) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return fmt.Errorf("%w: author name is required", ErrInvalid)
+	}
+	return nil
+}
+
+func authorKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
@@ -0,0 +1,56 @@
+// Package library - каталог книг: модель, репозиторий и его реализации.
+package library
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrNotFound = errors.New("library: not found")
+	ErrConflict = errors.New("library: already exists")
+	ErrInvalid  = errors.New("library: invalid book")
+)
+
+type Book struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Author   string `json:"author"`
+	AuthorID string `json:"author_id,omitempty"`
+	Year     int    `json:"year"`
+	ISBN     string `json:"isbn,omitempty"`
+}
+
+func (b Book) Validate() error {
+	if strings.TrimSpace(b.Title) == "" {
+		return fmt.Errorf("%w: title is required", ErrInvalid)
+	}
+	if b.Year < 0 {
+		return fmt.Errorf("%w: year must not be negative", ErrInvalid)
+	}
+	return nil
+}
+
+// NormalizeISBN приводит ISBN к виду для сравнения: только цифры и X, без дефисов,
+// пробелов и префикса «ISBN». Одна и та же книга у импорта и поиска дубликатов - это
+// книги с равным NormalizeISBN.
+func NormalizeISBN(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || r == 'X' || r == 'x' {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, s)
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,137 @@
+package library
+
+import (
+	"context"
+	"time"
+
+	"solid/concurrency/coalesce"
+)
+
+// coalescingRepository - декоратор, сводящий одновременные чтения одной книги и
+// одной страницы в один запрос к хранилищу.
+type coalescingRepository struct {
+	Repository
+	books *coalesce.Group[string, Book]
+	pages *coalesce.Group[PageRequest, Page]
+}
+
+// Coalesced при ttl > 0 ещё и отдаёт прочитанное в течение ttl без запроса.
+// Изменения через этот же репозиторий сбрасывают сохранённое, изменения в обход -
+// нет, поэтому ttl ограничивает, насколько устаревшим может быть чтение. Книги и
+// страницы общие для ждущих: менять их срезы нельзя.
+func Coalesced(repo Repository, ttl time.Duration) Repository {
+	return coalesced(repo, ttl)
+}
+
+func coalesced(repo Repository, ttl time.Duration) *coalescingRepository {
+	return &coalescingRepository{
+		Repository: repo,
+		books:      coalesce.New[string, Book](coalesce.Config[string]{TTL: ttl}),
+		pages:      coalesce.New[PageRequest, Page](coalesce.Config[PageRequest]{TTL: ttl}),
+	}
+}
+
+func (r *coalescingRepository) Get(ctx context.Context, id string) (Book, error) {
+	return r.books.Do(ctx, id, func(ctx context.Context) (Book, error) {
+		return r.Repository.Get(ctx, id)
+	})
+}
+
+func (r *coalescingRepository) List(ctx context.Context, req PageRequest) (Page, error) {
+	return r.pages.Do(ctx, req, func(ctx context.Context) (Page, error) {
+		return r.Repository.List(ctx, req)
+	})
+}
+
+// Add сбрасывает только страницы: книгу с новым id ещё никто не читал.
+func (r *coalescingRepository) Add(ctx context.Context, b Book) (Book, error) {
+	added, err := r.Repository.Add(ctx, b)
+	r.pages.Clear()
+	return added, err
+}
+
+func (r *coalescingRepository) Update(ctx context.Context, b Book) error {
+	err := r.Repository.Update(ctx, b)
+	r.changed(b.ID)
+	return err
+}
+
+func (r *coalescingRepository) Delete(ctx context.Context, id string) error {
+	err := r.Repository.Delete(ctx, id)
+	r.changed(id)
+	return err
+}
+
+// changed сбрасывает сохранённое после изменения, и неудачного тоже: оно могло
+// примениться частично.
+func (r *coalescingRepository) changed(id string) {
+	r.books.Forget(id)
+	r.pages.Clear()
+}
+
+// Catalog - книги вместе с метками и авторами, как их хранит MemoryRepository.
+type Catalog interface {
+	Repository
+	TagRepository
+	AuthorRepository
+}
+
+// coalescingCatalog - Coalesced, через который идут и изменения меток и авторов.
+type coalescingCatalog struct {
+	*coalescingRepository
+	TagRepository
+	AuthorRepository
+}
+
+// CoalescedCatalog - Coalesced для всего каталога c, чтобы ни одна запись не шла в
+// обход сохранённых чтений. books - c с декораторами книг, например WithEvents; nil -
+// сам c. Метки и авторы в Book и Page сейчас не входят, но их изменения тоже сбрасывают
+// сохранённое: иначе обёртка молча устарела бы, как только страница начнёт их учитывать.
+func CoalescedCatalog(c Catalog, books Repository, ttl time.Duration) Catalog {
+	if books == nil {
+		books = c
+	}
+	return &coalescingCatalog{coalescingRepository: coalesced(books, ttl), TagRepository: c, AuthorRepository: c}
+}
+
+func (r *coalescingCatalog) CreateTag(ctx context.Context, name string) (Tag, error) {
+	t, err := r.TagRepository.CreateTag(ctx, name)
+	r.pages.Clear()
+	return t, err
+}
+
+// RenameTag и DeleteTag меняют метку у всех её книг сразу.
+func (r *coalescingCatalog) RenameTag(ctx context.Context, oldName, newName string) error {
+	err := r.TagRepository.RenameTag(ctx, oldName, newName)
+	r.changedAll()
+	return err
+}
+
+func (r *coalescingCatalog) DeleteTag(ctx context.Context, name string) error {
+	err := r.TagRepository.DeleteTag(ctx, name)
+	r.changedAll()
+	return err
+}
+
+func (r *coalescingCatalog) TagBook(ctx context.Context, bookID, tag string) error {
+	err := r.TagRepository.TagBook(ctx, bookID, tag)
+	r.changed(bookID)
+	return err
+}
+
+func (r *coalescingCatalog) UntagBook(ctx context.Context, bookID, tag string) error {
+	err := r.TagRepository.UntagBook(ctx, bookID, tag)
+	r.changed(bookID)
+	return err
+}
+
+func (r *coalescingCatalog) AddAuthor(ctx context.Context, a Author) (Author, error) {
+	added, err := r.AuthorRepository.AddAuthor(ctx, a)
+	r.pages.Clear()
+	return added, err
+}
+
+func (r *coalescingCatalog) changedAll() {
+	r.books.Clear()
+	r.pages.Clear()
+}
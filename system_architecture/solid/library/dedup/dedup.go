@@ -0,0 +1,164 @@
+// Package dedup ищет вероятные дубликаты книг и сливает их в одну запись.
+package dedup
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"solid/library"
+)
+
+const DefaultThreshold = 0.85
+
+var ErrSameBook = errors.New("dedup: cannot merge a book with itself")
+
+type Reason string
+
+const (
+	ReasonISBN  Reason = "isbn"
+	ReasonFuzzy Reason = "title_author"
+)
+
+type Candidate struct {
+	Keep   library.Book `json:"keep"`
+	Drop   library.Book `json:"drop"`
+	Reason Reason       `json:"reason"`
+	Score  float64      `json:"score"`
+}
+
+// Reassigner переносит зависимые от книги данные (выдачи, отзывы, метки) на другую книгу.
+type Reassigner interface {
+	Reassign(ctx context.Context, fromBookID, toBookID string) (int, error)
+}
+
+type Service struct {
+	books     library.Repository
+	movers    []Reassigner
+	Threshold float64
+}
+
+func NewService(books library.Repository, movers ...Reassigner) *Service {
+	return &Service{books: books, movers: movers, Threshold: DefaultThreshold}
+}
+
+// Find сравнивает книги попарно: совпадение ISBN - точный дубликат, иначе
+// сравнивается нормализованная строка "название автор".
+func (s *Service) Find(ctx context.Context) ([]Candidate, error) {
+	books, err := s.all(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(books))
+	for i, b := range books {
+		keys[i] = normalize(b.Title) + " " + normalize(b.Author)
+	}
+
+	var found []Candidate
+	for i := 0; i < len(books); i++ {
+		for j := i + 1; j < len(books); j++ {
+			a, b := books[i], books[j]
+			if isbn := library.NormalizeISBN(a.ISBN); isbn != "" && isbn == library.NormalizeISBN(b.ISBN) {
+				found = append(found, Candidate{Keep: a, Drop: b, Reason: ReasonISBN, Score: 1})
+				continue
+			}
+			if score := similarity(keys[i], keys[j]); score >= s.Threshold {
+				found = append(found, Candidate{Keep: a, Drop: b, Reason: ReasonFuzzy, Score: score})
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Score > found[j].Score })
+	return found, nil
+}
+
+// Merge оставляет книгу keepID, дополняя её пустые поля данными дубликата,
+// переносит на неё зависимые записи и удаляет дубликат.
+func (s *Service) Merge(ctx context.Context, keepID, dropID string) (library.Book, error) {
+	if keepID == dropID {
+		return library.Book{}, ErrSameBook
+	}
+	keep, err := s.books.Get(ctx, keepID)
+	if err != nil {
+		return library.Book{}, err
+	}
+	drop, err := s.books.Get(ctx, dropID)
+	if err != nil {
+		return library.Book{}, err
+	}
+	for _, m := range s.movers {
+		if _, err := m.Reassign(ctx, dropID, keepID); err != nil {
+			return library.Book{}, err
+		}
+	}
+
+	if keep.Author == "" {
+		keep.Author = drop.Author
+	}
+	if keep.Year == 0 {
+		keep.Year = drop.Year
+	}
+	if keep.ISBN == "" {
+		keep.ISBN = drop.ISBN
+	}
+	if err := s.books.Update(ctx, keep); err != nil {
+		return library.Book{}, err
+	}
+	if err := s.books.Delete(ctx, dropID); err != nil {
+		return library.Book{}, err
+	}
+	return keep, nil
+}
+
+func (s *Service) all(ctx context.Context) ([]library.Book, error) {
+	var books []library.Book
+	req := library.PageRequest{Limit: library.MaxPageSize}
+	for {
+		page, err := s.books.List(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, page.Books...)
+		if page.NextCursor == "" {
+			return books, nil
+		}
+		req.Cursor = page.NextCursor
+	}
+}
+
+func normalize(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// TagMover переносит метки книги через library.TagRepository.
+type TagMover struct {
+	Tags library.TagRepository
+}
+
+func (m TagMover) Reassign(ctx context.Context, fromBookID, toBookID string) (int, error) {
+	tags, err := m.Tags.BookTags(ctx, fromBookID)
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range tags {
+		if err := m.Tags.TagBook(ctx, toBookID, t); err != nil {
+			return 0, err
+		}
+	}
+	return len(tags), nil
+}
@@ -0,0 +1,75 @@
+package library
+
+import (
+	"context"
+
+	"solid/data"
+	"solid/eventbus"
+)
+
+// Publisher - порт для публикации доменных событий; реализуется шинами events.Bus и eventbus.Bus.
+type Publisher interface {
+	Publish(ctx context.Context, event any)
+}
+
+// Темы событий каталога в eventbus; Bus.Publish выводит те же имена из типа события.
+var (
+	BookAddedTopic   = eventbus.TopicOf[BookAdded]()
+	BookUpdatedTopic = eventbus.TopicOf[BookUpdated]()
+	BookDeletedTopic = eventbus.TopicOf[BookDeleted]()
+)
+
+type BookAdded struct {
+	Book Book
+}
+
+type BookUpdated struct {
+	Book Book
+}
+
+type BookDeleted struct {
+	ID string
+}
+
+// publishingRepository - декоратор, публикующий события после успешных изменений.
+type publishingRepository struct {
+	Repository
+	pub Publisher
+}
+
+// WithEvents при pub == nil публикует в data.NopEventBus.
+func WithEvents(repo Repository, pub Publisher) Repository {
+	if pub == nil {
+		pub = data.NopEventBus{}
+	}
+	return &publishingRepository{Repository: repo, pub: pub}
+}
+
+func (r *publishingRepository) Add(ctx context.Context, b Book) (Book, error) {
+	added, err := r.Repository.Add(ctx, b)
+	if err != nil {
+		return Book{}, err
+	}
+	r.pub.Publish(ctx, BookAdded{Book: added})
+	return added, nil
+}
+
+func (r *publishingRepository) Update(ctx context.Context, b Book) error {
+	if err := r.Repository.Update(ctx, b); err != nil {
+		return err
+	}
+	// Репозиторий может дополнить запись (например, связать автора), поэтому публикуется сохранённая версия.
+	if stored, err := r.Repository.Get(ctx, b.ID); err == nil {
+		b = stored
+	}
+	r.pub.Publish(ctx, BookUpdated{Book: b})
+	return nil
+}
+
+func (r *publishingRepository) Delete(ctx context.Context, id string) error {
+	if err := r.Repository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.pub.Publish(ctx, BookDeleted{ID: id})
+	return nil
+}
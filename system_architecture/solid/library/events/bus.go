@@ -0,0 +1,41 @@
+// Package events - внутрипроцессная шина доменных событий (паттерн Observer).
+// Издатели не знают о подписчиках: проекции подключаются к шине независимо.
+package events
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+type handler func(ctx context.Context, event any)
+
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[reflect.Type][]handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[reflect.Type][]handler)}
+}
+
+// Subscribe регистрирует обработчик событий типа E. Тип события задаёт тему,
+// поэтому обработчик получает уже типизированное значение.
+func Subscribe[E any](b *Bus, fn func(ctx context.Context, e E)) {
+	t := reflect.TypeOf((*E)(nil)).Elem()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[t] = append(b.handlers[t], func(ctx context.Context, event any) {
+		fn(ctx, event.(E))
+	})
+}
+
+// Publish синхронно доставляет событие всем подписчикам его типа.
+func (b *Bus) Publish(ctx context.Context, event any) {
+	b.mu.RLock()
+	hs := b.handlers[reflect.TypeOf(event)]
+	b.mu.RUnlock()
+	for _, h := range hs {
+		h(ctx, event)
+	}
+}
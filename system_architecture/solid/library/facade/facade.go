@@ -0,0 +1,150 @@
+// Package facade - паттерн «Фасад» над подсистемами библиотеки. LibraryFacade прячет
+// репозиторий, экземпляры, выдачу, поиск и уведомления за несколькими операциями,
+// поэтому HTTP- и CLI-слои не собирают сценарии из отдельных сервисов сами.
+package facade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"solid/library"
+	"solid/library/inventory"
+	"solid/library/lending"
+)
+
+// Notifier отправляет уведомление читателю; канал доставки (почта, SMS) выбирает реализация.
+type Notifier interface {
+	Notify(ctx context.Context, to, subject, body string) error
+}
+
+// LogNotifier вместо отправки пишет уведомления в лог.
+type LogNotifier struct {
+	Logger *log.Logger
+}
+
+func (n LogNotifier) Notify(ctx context.Context, to, subject, body string) error {
+	n.Logger.Printf("notify %s: %s: %s", to, subject, body)
+	return nil
+}
+
+// Config - подсистемы фасада. Inventory, Lending, Search и Notifier необязательны:
+// без них соответствующие операции возвращают ErrUnsupported или пропускают шаг.
+type Config struct {
+	Books     library.Repository
+	Search    library.Searcher
+	Inventory *inventory.Service
+	Lending   *lending.Service
+	Notifier  Notifier
+	Logger    *log.Logger
+}
+
+var ErrUnsupported = errors.New("facade: subsystem not configured")
+
+type LibraryFacade struct {
+	cfg Config
+}
+
+func New(cfg Config) *LibraryFacade {
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	if cfg.Notifier == nil {
+		cfg.Notifier = nopNotifier{}
+	}
+	return &LibraryFacade{cfg: cfg}
+}
+
+// AddBook добавляет книгу в каталог и заводит для неё copies экземпляров в филиале branch.
+func (f *LibraryFacade) AddBook(ctx context.Context, b library.Book, copies int, branch string) (library.Book, []inventory.Copy, error) {
+	if copies > 0 && f.cfg.Inventory == nil {
+		return library.Book{}, nil, fmt.Errorf("%w: inventory", ErrUnsupported)
+	}
+	// Филиал проверяется до добавления книги, чтобы не оставить в каталоге книгу без заказанных экземпляров.
+	if copies > 0 && strings.TrimSpace(branch) == "" {
+		return library.Book{}, nil, fmt.Errorf("%w: branch is required for new copies", inventory.ErrInvalid)
+	}
+	added, err := f.cfg.Books.Add(ctx, b)
+	if err != nil {
+		return library.Book{}, nil, err
+	}
+	created := make([]inventory.Copy, 0, copies)
+	for i := 0; i < copies; i++ {
+		c, err := f.cfg.Inventory.AddCopy(ctx, inventory.Copy{BookID: added.ID, Branch: branch})
+		if err != nil {
+			return added, created, fmt.Errorf("facade: add copy %d of %s: %w", i+1, added.ID, err)
+		}
+		created = append(created, c)
+	}
+	return added, created, nil
+}
+
+// LoanBook выдаёт свободный экземпляр книги и уведомляет читателя о сроке возврата.
+func (f *LibraryFacade) LoanBook(ctx context.Context, bookID, borrower string) (lending.Loan, error) {
+	if f.cfg.Lending == nil {
+		return lending.Loan{}, fmt.Errorf("%w: lending", ErrUnsupported)
+	}
+	book, err := f.cfg.Books.Get(ctx, bookID)
+	if err != nil {
+		return lending.Loan{}, err
+	}
+	loan, err := f.cfg.Lending.Loan(ctx, book.ID, borrower)
+	if err != nil {
+		return lending.Loan{}, err
+	}
+	f.notify(ctx, loan.Borrower, "Book loaned",
+		fmt.Sprintf("%q by %s is due %s", book.Title, book.Author, loan.DueAt.Format("2006-01-02")))
+	return loan, nil
+}
+
+// LoanCopy выдаёт конкретный экземпляр, например отсканированный по штрихкоду.
+func (f *LibraryFacade) LoanCopy(ctx context.Context, copyID, borrower string) (lending.Loan, error) {
+	if f.cfg.Lending == nil {
+		return lending.Loan{}, fmt.Errorf("%w: lending", ErrUnsupported)
+	}
+	loan, err := f.cfg.Lending.LoanCopy(ctx, copyID, borrower)
+	if err != nil {
+		return lending.Loan{}, err
+	}
+	f.notify(ctx, loan.Borrower, "Book loaned", fmt.Sprintf("copy %s is due %s", loan.CopyID, loan.DueAt.Format("2006-01-02")))
+	return loan, nil
+}
+
+// ReturnBook закрывает выдачу, освобождает экземпляр и подтверждает возврат читателю.
+func (f *LibraryFacade) ReturnBook(ctx context.Context, loanID string) (lending.Loan, error) {
+	if f.cfg.Lending == nil {
+		return lending.Loan{}, fmt.Errorf("%w: lending", ErrUnsupported)
+	}
+	loan, err := f.cfg.Lending.Return(ctx, loanID)
+	if err != nil {
+		return lending.Loan{}, err
+	}
+	title := loan.BookID
+	if book, err := f.cfg.Books.Get(ctx, loan.BookID); err == nil {
+		title = book.Title
+	}
+	f.notify(ctx, loan.Borrower, "Book returned", fmt.Sprintf("thank you for returning %q", title))
+	return loan, nil
+}
+
+// FindBooks ищет книги выбранным поисковым бэкендом.
+func (f *LibraryFacade) FindBooks(ctx context.Context, query string, limit int) ([]library.Book, error) {
+	if f.cfg.Search == nil {
+		return nil, fmt.Errorf("%w: search", ErrUnsupported)
+	}
+	return f.cfg.Search.Search(ctx, query, limit)
+}
+
+// notify не прерывает операцию: выдача уже состоялась, сбой уведомления только логируется.
+func (f *LibraryFacade) notify(ctx context.Context, to, subject, body string) {
+	if err := f.cfg.Notifier.Notify(ctx, to, subject, body); err != nil {
+		f.cfg.Logger.Printf("facade: notify %s: %v", to, err)
+	}
+}
+
+// nopNotifier - пустой объект для Config без Notifier.
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(ctx context.Context, to, subject, body string) error { return nil }
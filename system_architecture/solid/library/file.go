@@ -0,0 +1,233 @@
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FileRepository хранит каталог в JSON-файле. Чтение идёт из памяти,
+// каждое изменение целиком переписывает файл.
+type FileRepository struct {
+	mem  *MemoryRepository
+	path string
+	mu   sync.Mutex
+}
+
+var (
+	_ Repository       = (*FileRepository)(nil)
+	_ TagRepository    = (*FileRepository)(nil)
+	_ AuthorRepository = (*FileRepository)(nil)
+)
+
+type fileSnapshot struct {
+	Books    []Book              `json:"books"`
+	Tags     []string            `json:"tags,omitempty"`
+	BookTags map[string][]string `json:"book_tags,omitempty"`
+	Authors  []Author            `json:"authors,omitempty"`
+}
+
+func OpenFileRepository(path string) (*FileRepository, error) {
+	r := &FileRepository{mem: NewMemoryRepository(), path: path}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snap fileSnapshot
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return nil, err
+	}
+	for _, a := range snap.Authors {
+		r.mem.authors[a.ID] = a
+	}
+	// Каталоги, сохранённые до появления авторов, связываются с ними при загрузке.
+	for _, b := range snap.Books {
+		if err := r.mem.linkAuthor(&b); err != nil {
+			return nil, err
+		}
+		r.mem.books[b.ID] = b
+	}
+	for _, t := range snap.Tags {
+		r.mem.tags[t] = struct{}{}
+	}
+	for id, tags := range snap.BookTags {
+		set := make(map[string]struct{}, len(tags))
+		for _, t := range tags {
+			set[t] = struct{}{}
+		}
+		r.mem.bookTags[id] = set
+	}
+	return r, nil
+}
+
+// write выполняет изменение на копии каталога, сохраняет её снимок на диск и только
+// потом подменяет ею каталог в памяти. Изменение, которое не удалось сохранить, не
+// видно читателям и не попадёт на диск со следующим сохранением.
+func (r *FileRepository) write(fn func(m *MemoryRepository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := r.mem.clone()
+	if err := fn(next); err != nil {
+		return err
+	}
+	if err := r.flush(next); err != nil {
+		return err
+	}
+	r.mem.replace(next)
+	return nil
+}
+
+func (r *FileRepository) Add(ctx context.Context, b Book) (Book, error) {
+	var added Book
+	err := r.write(func(m *MemoryRepository) (err error) {
+		added, err = m.Add(ctx, b)
+		return err
+	})
+	if err != nil {
+		return Book{}, err
+	}
+	return added, nil
+}
+
+func (r *FileRepository) Get(ctx context.Context, id string) (Book, error) {
+	return r.mem.Get(ctx, id)
+}
+
+func (r *FileRepository) Update(ctx context.Context, b Book) error {
+	return r.write(func(m *MemoryRepository) error { return m.Update(ctx, b) })
+}
+
+func (r *FileRepository) Delete(ctx context.Context, id string) error {
+	return r.write(func(m *MemoryRepository) error { return m.Delete(ctx, id) })
+}
+
+func (r *FileRepository) List(ctx context.Context, req PageRequest) (Page, error) {
+	return r.mem.List(ctx, req)
+}
+
+func (r *FileRepository) Search(ctx context.Context, query string, limit int) ([]Book, error) {
+	return r.mem.Search(ctx, query, limit)
+}
+
+func (r *FileRepository) CreateTag(ctx context.Context, name string) (Tag, error) {
+	var tag Tag
+	err := r.write(func(m *MemoryRepository) (err error) {
+		tag, err = m.CreateTag(ctx, name)
+		return err
+	})
+	if err != nil {
+		return Tag{}, err
+	}
+	return tag, nil
+}
+
+func (r *FileRepository) RenameTag(ctx context.Context, oldName, newName string) error {
+	return r.write(func(m *MemoryRepository) error { return m.RenameTag(ctx, oldName, newName) })
+}
+
+func (r *FileRepository) DeleteTag(ctx context.Context, name string) error {
+	return r.write(func(m *MemoryRepository) error { return m.DeleteTag(ctx, name) })
+}
+
+func (r *FileRepository) ListTags(ctx context.Context) ([]Tag, error) {
+	return r.mem.ListTags(ctx)
+}
+
+func (r *FileRepository) TagBook(ctx context.Context, bookID, tag string) error {
+	return r.write(func(m *MemoryRepository) error { return m.TagBook(ctx, bookID, tag) })
+}
+
+func (r *FileRepository) UntagBook(ctx context.Context, bookID, tag string) error {
+	return r.write(func(m *MemoryRepository) error { return m.UntagBook(ctx, bookID, tag) })
+}
+
+func (r *FileRepository) BookTags(ctx context.Context, bookID string) ([]string, error) {
+	return r.mem.BookTags(ctx, bookID)
+}
+
+func (r *FileRepository) BooksByTag(ctx context.Context, tag string, req PageRequest) (Page, error) {
+	return r.mem.BooksByTag(ctx, tag, req)
+}
+
+func (r *FileRepository) AddAuthor(ctx context.Context, a Author) (Author, error) {
+	var added Author
+	err := r.write(func(m *MemoryRepository) (err error) {
+		added, err = m.AddAuthor(ctx, a)
+		return err
+	})
+	if err != nil {
+		return Author{}, err
+	}
+	return added, nil
+}
+
+func (r *FileRepository) GetAuthor(ctx context.Context, id string) (Author, error) {
+	return r.mem.GetAuthor(ctx, id)
+}
+
+func (r *FileRepository) ListAuthors(ctx context.Context) ([]Author, error) {
+	return r.mem.ListAuthors(ctx)
+}
+
+func (r *FileRepository) BooksByAuthor(ctx context.Context, authorID string, req PageRequest) (Page, error) {
+	return r.mem.BooksByAuthor(ctx, authorID, req)
+}
+
+func snapshot(m *MemoryRepository) fileSnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	snap := fileSnapshot{Books: make([]Book, 0, len(m.books)), BookTags: make(map[string][]string)}
+	for _, b := range m.books {
+		snap.Books = append(snap.Books, b)
+	}
+	sort.Slice(snap.Books, func(i, j int) bool { return snap.Books[i].ID < snap.Books[j].ID })
+	for _, a := range m.authors {
+		snap.Authors = append(snap.Authors, a)
+	}
+	sort.Slice(snap.Authors, func(i, j int) bool { return snap.Authors[i].ID < snap.Authors[j].ID })
+	for t := range m.tags {
+		snap.Tags = append(snap.Tags, t)
+	}
+	sort.Strings(snap.Tags)
+	for id, set := range m.bookTags {
+		if len(set) == 0 {
+			continue
+		}
+		tags := make([]string, 0, len(set))
+		for t := range set {
+			tags = append(tags, t)
+		}
+		sort.Strings(tags)
+		snap.BookTags[id] = tags
+	}
+	return snap
+}
+
+// flush пишет снимок m во временный файл и атомарно подменяет основной.
+func (r *FileRepository) flush(m *MemoryRepository) error {
+	raw, err := json.MarshalIndent(snapshot(m), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), ".library-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
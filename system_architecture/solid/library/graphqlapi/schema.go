@@ -0,0 +1,231 @@
+// Package graphqlapi - GraphQL-доступ к каталогу поверх тех же интерфейсов репозиториев,
+// что и REST API: доменный слой не зависит от транспорта.
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+
+	"solid/library"
+	"solid/library/lending"
+	"solid/library/reviews"
+)
+
+type Deps struct {
+	Books   library.Repository
+	Authors library.AuthorRepository
+	Search  library.Searcher
+	Reviews *reviews.Service
+	Lending *lending.Service
+}
+
+func NewSchema(d Deps) (graphql.Schema, error) {
+	reviewType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Review",
+		Fields: graphql.Fields{
+			"id":     &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"rating": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"text":   &graphql.Field{Type: graphql.String},
+			"status": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return string(p.Source.(reviews.Review).Status), nil
+			}},
+		},
+	})
+	loanType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Loan",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"copyId":   &graphql.Field{Type: graphql.ID, Resolve: field(func(l lending.Loan) any { return l.CopyID })},
+			"borrower": &graphql.Field{Type: graphql.String},
+			"loanedAt": &graphql.Field{Type: graphql.DateTime, Resolve: field(func(l lending.Loan) any { return l.LoanedAt })},
+			"dueAt":    &graphql.Field{Type: graphql.DateTime, Resolve: field(func(l lending.Loan) any { return l.DueAt })},
+			"active":   &graphql.Field{Type: graphql.Boolean, Resolve: field(func(l lending.Loan) any { return l.Active() })},
+		},
+	})
+	authorType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Author",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+	bookType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Book",
+		Fields: graphql.Fields{
+			"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"title": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"year":  &graphql.Field{Type: graphql.Int},
+			"isbn":  &graphql.Field{Type: graphql.String, Resolve: field(func(b library.Book) any { return b.ISBN })},
+			"author": &graphql.Field{Type: authorType, Resolve: func(p graphql.ResolveParams) (any, error) {
+				b := p.Source.(library.Book)
+				if b.AuthorID == "" || d.Authors == nil {
+					return nil, nil
+				}
+				return d.Authors.GetAuthor(p.Context, b.AuthorID)
+			}},
+		},
+	})
+	if d.Reviews != nil {
+		bookType.AddFieldConfig("reviews", &graphql.Field{
+			Type: graphql.NewList(reviewType),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return d.Reviews.List(p.Context, p.Source.(library.Book).ID, false)
+			},
+		})
+		bookType.AddFieldConfig("rating", &graphql.Field{
+			Type: graphql.Float,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				r, err := d.Reviews.Rating(p.Context, p.Source.(library.Book).ID)
+				return r.Average, err
+			},
+		})
+	}
+	if d.Lending != nil {
+		bookType.AddFieldConfig("loans", &graphql.Field{
+			Type: graphql.NewList(loanType),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return d.Lending.History(p.Context, p.Source.(library.Book).ID)
+			},
+		})
+	}
+
+	pageArgs := graphql.FieldConfigArgument{
+		"limit":  &graphql.ArgumentConfig{Type: graphql.Int},
+		"cursor": &graphql.ArgumentConfig{Type: graphql.String},
+		"sort":   &graphql.ArgumentConfig{Type: graphql.String},
+	}
+	pageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "BookPage",
+		Fields: graphql.Fields{
+			"books":      &graphql.Field{Type: graphql.NewList(bookType), Resolve: field(func(p library.Page) any { return p.Books })},
+			"nextCursor": &graphql.Field{Type: graphql.String, Resolve: field(func(p library.Page) any { return p.NextCursor })},
+		},
+	})
+	if d.Authors != nil {
+		authorType.AddFieldConfig("books", &graphql.Field{
+			Type: pageType,
+			Args: pageArgs,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return d.Authors.BooksByAuthor(p.Context, p.Source.(library.Author).ID, pageRequest(p.Args))
+			},
+		})
+	}
+
+	query := graphql.Fields{
+		"book": &graphql.Field{
+			Type: bookType,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return d.Books.Get(p.Context, p.Args["id"].(string))
+			},
+		},
+		"books": &graphql.Field{
+			Type: pageType,
+			Args: pageArgs,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return d.Books.List(p.Context, pageRequest(p.Args))
+			},
+		},
+	}
+	if d.Search != nil {
+		query["search"] = &graphql.Field{
+			Type: graphql.NewList(bookType),
+			Args: graphql.FieldConfigArgument{
+				"q":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: library.DefaultPageSize},
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return d.Search.Search(p.Context, p.Args["q"].(string), p.Args["limit"].(int))
+			},
+		}
+	}
+	if d.Authors != nil {
+		query["authors"] = &graphql.Field{
+			Type: graphql.NewList(authorType),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return d.Authors.ListAuthors(p.Context)
+			},
+		}
+		query["author"] = &graphql.Field{
+			Type: authorType,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return d.Authors.GetAuthor(p.Context, p.Args["id"].(string))
+			},
+		}
+	}
+
+	mutation := graphql.Fields{
+		"addBook": &graphql.Field{
+			Type: bookType,
+			Args: graphql.FieldConfigArgument{
+				"title":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"author": &graphql.ArgumentConfig{Type: graphql.String},
+				"year":   &graphql.ArgumentConfig{Type: graphql.Int},
+				"isbn":   &graphql.ArgumentConfig{Type: graphql.String},
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				b := library.Book{Title: p.Args["title"].(string)}
+				b.Author, _ = p.Args["author"].(string)
+				b.Year, _ = p.Args["year"].(int)
+				b.ISBN, _ = p.Args["isbn"].(string)
+				return d.Books.Add(p.Context, b)
+			},
+		},
+	}
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: query}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: mutation}),
+	})
+}
+
+// field строит резолвер для значения, которое не совпадает по имени с полем структуры.
+func field[T any](get func(T) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(T)), nil
+	}
+}
+
+func pageRequest(args map[string]any) library.PageRequest {
+	req := library.PageRequest{}
+	req.Limit, _ = args["limit"].(int)
+	req.Cursor, _ = args["cursor"].(string)
+	if sort, ok := args["sort"].(string); ok {
+		req.SortBy = library.SortKey(sort)
+	}
+	return req
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Handler принимает POST-запросы в стандартном формате {"query", "variables"}.
+func Handler(schema graphql.Schema) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		result := execute(r.Context(), schema, req)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+func execute(ctx context.Context, schema graphql.Schema, req request) *graphql.Result {
+	return graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        ctx,
+	})
+}
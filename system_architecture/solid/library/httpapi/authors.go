@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"solid/library"
+)
+
+func (s *Server) authorRoutes() {
+	s.mux.HandleFunc("GET /authors", s.listAuthors)
+	s.mux.HandleFunc("POST /authors", s.createAuthor)
+	s.mux.HandleFunc("GET /authors/{id}", s.getAuthor)
+	s.mux.HandleFunc("GET /authors/{id}/books", s.authorBooks)
+}
+
+func (s *Server) listAuthors(w http.ResponseWriter, r *http.Request) {
+	authors, err := s.authors.ListAuthors(r.Context())
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"authors": authors})
+}
+
+func (s *Server) createAuthor(w http.ResponseWriter, r *http.Request) {
+	var a library.Author
+	if !decode(w, r, &a) {
+		return
+	}
+	a.ID = ""
+	created, err := s.authors.AddAuthor(r.Context(), a)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/authors/"+created.ID)
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (s *Server) getAuthor(w http.ResponseWriter, r *http.Request) {
+	a, err := s.authors.GetAuthor(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+func (s *Server) authorBooks(w http.ResponseWriter, r *http.Request) {
+	req, ok := pageRequest(w, r)
+	if !ok {
+		return
+	}
+	page, err := s.authors.BooksByAuthor(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// pageRequest разбирает параметры постраничного вывода limit, cursor, sort и order.
+func pageRequest(w http.ResponseWriter, r *http.Request) (library.PageRequest, bool) {
+	q := r.URL.Query()
+	req := library.PageRequest{
+		Cursor: q.Get("cursor"),
+		SortBy: library.SortKey(q.Get("sort")),
+		Desc:   q.Get("order") == "desc",
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "limit must be a number")
+			return req, false
+		}
+		req.Limit = n
+	}
+	return req, true
+}
@@ -0,0 +1,42 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"solid/library/dedup"
+)
+
+func (s *Server) dedupRoutes() {
+	s.mux.HandleFunc("GET /duplicates", s.findDuplicates)
+	s.mux.HandleFunc("POST /duplicates/merge", s.mergeDuplicates)
+}
+
+type mergeRequest struct {
+	KeepID string `json:"keep_id"`
+	DropID string `json:"drop_id"`
+}
+
+func (s *Server) findDuplicates(w http.ResponseWriter, r *http.Request) {
+	found, err := s.dedup.Find(r.Context())
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if found == nil {
+		found = []dedup.Candidate{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"duplicates": found})
+}
+
+func (s *Server) mergeDuplicates(w http.ResponseWriter, r *http.Request) {
+	var req mergeRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	book, err := s.dedup.Merge(r.Context(), req.KeepID, req.DropID)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, book)
+}
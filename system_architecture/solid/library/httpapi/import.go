@@ -0,0 +1,29 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"solid/library"
+)
+
+// maxImportBody ограничивает размер тела массового импорта.
+const maxImportBody = 32 << 20
+
+func (s *Server) importBooks(w http.ResponseWriter, r *http.Request) {
+	var books []library.Book
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportBody)).Decode(&books); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	report, err := s.importer.Import(r.Context(), books)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	status := http.StatusOK
+	if len(report.Rejected) > 0 {
+		status = http.StatusMultiStatus
+	}
+	writeJSON(w, status, report)
+}
@@ -0,0 +1,76 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"solid/library/inventory"
+)
+
+func (s *Server) inventoryRoutes() {
+	s.mux.HandleFunc("GET /books/{id}/copies", s.listCopies)
+	s.mux.HandleFunc("POST /books/{id}/copies", s.addCopy)
+	s.mux.HandleFunc("GET /books/{id}/availability", s.availability)
+	s.mux.HandleFunc("GET /copies/{id}", s.getCopy)
+	s.mux.HandleFunc("POST /copies/{id}/transfer", s.transferCopy)
+}
+
+type transferRequest struct {
+	Branch string `json:"branch"`
+}
+
+func (s *Server) listCopies(w http.ResponseWriter, r *http.Request) {
+	copies, err := s.inventory.ListByBook(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if copies == nil {
+		copies = []inventory.Copy{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"copies": copies})
+}
+
+func (s *Server) addCopy(w http.ResponseWriter, r *http.Request) {
+	var c inventory.Copy
+	if !decode(w, r, &c) {
+		return
+	}
+	c.BookID = r.PathValue("id")
+	created, err := s.inventory.AddCopy(r.Context(), c)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (s *Server) availability(w http.ResponseWriter, r *http.Request) {
+	a, err := s.inventory.Availability(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+func (s *Server) getCopy(w http.ResponseWriter, r *http.Request) {
+	c, err := s.inventory.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) transferCopy(w http.ResponseWriter, r *http.Request) {
+	var req transferRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	c, err := s.inventory.Transfer(r.Context(), r.PathValue("id"), req.Branch)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"solid/library/lending"
+)
+
+func (s *Server) lendingRoutes() {
+	s.mux.HandleFunc("GET /books/{id}/loans", s.loanHistory)
+	s.mux.HandleFunc("POST /books/{id}/loans", s.loanBook)
+	s.mux.HandleFunc("POST /copies/{id}/loans", s.loanCopy)
+	s.mux.HandleFunc("POST /loans/{id}/return", s.returnLoan)
+}
+
+type loanRequest struct {
+	Borrower string `json:"borrower"`
+}
+
+func (s *Server) loanHistory(w http.ResponseWriter, r *http.Request) {
+	loans, err := s.lending.History(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if loans == nil {
+		loans = []lending.Loan{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"loans": loans})
+}
+
+func (s *Server) loanBook(w http.ResponseWriter, r *http.Request) {
+	var req loanRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	loan, err := s.facade.LoanBook(r.Context(), r.PathValue("id"), req.Borrower)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, loan)
+}
+
+func (s *Server) loanCopy(w http.ResponseWriter, r *http.Request) {
+	var req loanRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	loan, err := s.facade.LoanCopy(r.Context(), r.PathValue("id"), req.Borrower)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, loan)
+}
+
+func (s *Server) returnLoan(w http.ResponseWriter, r *http.Request) {
+	loan, err := s.facade.ReturnBook(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, loan)
+}
@@ -0,0 +1,16 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"solid/recommend"
+)
+
+func (s *Server) recommendations(w http.ResponseWriter, r *http.Request) {
+	list, err := s.recommend.Recommend(r.Context(), r.PathValue("id"), recommend.DefaultTopN)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"recommendations": list})
+}
@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"solid/library/reviews"
+)
+
+func (s *Server) reviewRoutes() {
+	s.mux.HandleFunc("GET /books/{id}/reviews", s.listReviews)
+	s.mux.HandleFunc("POST /books/{id}/reviews", s.addReview)
+	s.mux.HandleFunc("GET /books/{id}/rating", s.bookRating)
+	s.mux.HandleFunc("PUT /reviews/{id}/status", s.moderateReview)
+}
+
+type reviewRequest struct {
+	Rating int    `json:"rating"`
+	Text   string `json:"text"`
+}
+
+type moderationRequest struct {
+	Status string `json:"status"`
+}
+
+func (s *Server) listReviews(w http.ResponseWriter, r *http.Request) {
+	list, err := s.reviews.List(r.Context(), r.PathValue("id"), r.URL.Query().Get("status") == "all")
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if list == nil {
+		list = []reviews.Review{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"reviews": list})
+}
+
+func (s *Server) addReview(w http.ResponseWriter, r *http.Request) {
+	var req reviewRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	rev, err := s.reviews.Add(r.Context(), r.PathValue("id"), req.Rating, req.Text)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, rev)
+}
+
+func (s *Server) bookRating(w http.ResponseWriter, r *http.Request) {
+	rating, err := s.reviews.Rating(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rating)
+}
+
+func (s *Server) moderateReview(w http.ResponseWriter, r *http.Request) {
+	var req moderationRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	status, err := reviews.ParseStatus(req.Status)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	rev, err := s.reviews.Moderate(r.Context(), r.PathValue("id"), status)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rev)
+}
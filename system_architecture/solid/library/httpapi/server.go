@@ -0,0 +1,350 @@
+// Package httpapi - REST API каталога. Сервер зависит только от интерфейсов
+// library.Repository и library.Searcher (принцип инверсии зависимостей).
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"solid/design_patterns/specification"
+	"solid/httpmw"
+	"solid/library"
+	"solid/library/dedup"
+	"solid/library/facade"
+	"solid/library/importer"
+	"solid/library/inventory"
+	"solid/library/lending"
+	"solid/library/reviews"
+	"solid/library/search"
+	"solid/library/stats"
+	"solid/metrics"
+	"solid/recommend"
+	"solid/telemetry"
+)
+
+// Deps - зависимости сервера. Необязательные подсистемы (nil) просто не регистрируют свои маршруты.
+type Deps struct {
+	Books     library.Repository
+	Search    library.Searcher
+	Tags      library.TagRepository
+	Authors   library.AuthorRepository
+	Reviews   *reviews.Service
+	Lending   *lending.Service
+	Inventory *inventory.Service
+	Stats     *stats.Projection
+	Dedup     *dedup.Service
+	Importer  *importer.Importer
+	Recommend *recommend.Engine
+	// Facade выполняет сценарии добавления книги и выдачи; если nil, собирается из полей выше.
+	Facade *facade.LibraryFacade
+	// Timeout - дедлайн обработки одного запроса; 0 - без ограничения.
+	Timeout time.Duration
+	// Tracer, если задан, пишет спан на каждый запрос, названный по маршруту.
+	Tracer trace.TracerProvider
+	// Metrics, если задан, считает запросы по маршрутам в http_server_*.
+	Metrics *metrics.Registry
+	Logger  *log.Logger
+}
+
+type Server struct {
+	books     library.Repository
+	search    library.Searcher
+	tags      library.TagRepository
+	authors   library.AuthorRepository
+	reviews   *reviews.Service
+	lending   *lending.Service
+	inventory *inventory.Service
+	stats     *stats.Projection
+	dedup     *dedup.Service
+	importer  *importer.Importer
+	recommend *recommend.Engine
+	facade    *facade.LibraryFacade
+	timeout   time.Duration
+	tracer    trace.TracerProvider
+	metrics   *metrics.Registry
+	log       *log.Logger
+	mux       *http.ServeMux
+}
+
+func NewServer(d Deps) *Server {
+	if d.Logger == nil {
+		d.Logger = log.Default()
+	}
+	if d.Facade == nil {
+		d.Facade = facade.New(facade.Config{
+			Books: d.Books, Search: d.Search, Inventory: d.Inventory, Lending: d.Lending, Logger: d.Logger,
+		})
+	}
+	s := &Server{
+		books:     d.Books,
+		search:    d.Search,
+		tags:      d.Tags,
+		authors:   d.Authors,
+		reviews:   d.Reviews,
+		lending:   d.Lending,
+		inventory: d.Inventory,
+		stats:     d.Stats,
+		dedup:     d.Dedup,
+		importer:  d.Importer,
+		recommend: d.Recommend,
+		facade:    d.Facade,
+		timeout:   d.Timeout,
+		tracer:    d.Tracer,
+		metrics:   d.Metrics,
+		log:       d.Logger,
+		mux:       http.NewServeMux(),
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /books", s.listBooks)
+	s.mux.HandleFunc("POST /books", s.createBook)
+	s.mux.HandleFunc("GET /books/search", s.searchBooks)
+	s.mux.HandleFunc("GET /books/{id}", s.getBook)
+	s.mux.HandleFunc("PUT /books/{id}", s.updateBook)
+	s.mux.HandleFunc("DELETE /books/{id}", s.deleteBook)
+	if s.tags != nil {
+		s.tagRoutes()
+	}
+	if s.authors != nil {
+		s.authorRoutes()
+	}
+	if s.reviews != nil {
+		s.reviewRoutes()
+	}
+	if s.inventory != nil {
+		s.inventoryRoutes()
+	}
+	if s.lending != nil {
+		s.lendingRoutes()
+	}
+	if s.importer != nil {
+		s.mux.HandleFunc("POST /books/import", s.importBooks)
+	}
+	if s.recommend != nil {
+		s.mux.HandleFunc("GET /books/{id}/recommendations", s.recommendations)
+	}
+	if s.dedup != nil {
+		s.dedupRoutes()
+	}
+	if s.stats != nil {
+		s.mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, s.stats.Snapshot())
+		})
+	}
+}
+
+// Handler возвращает мультиплексор, обёрнутый в стандартные middleware.
+func (s *Server) Handler() http.Handler {
+	mws := []httpmw.Middleware{httpmw.RequestID, httpmw.Logging(s.log), httpmw.Recover(s.log), httpmw.Gzip, httpmw.Timeout(s.timeout), telemetry.HTTP(s.tracer)}
+	if s.metrics != nil {
+		mws = append(mws, metrics.HTTP(s.metrics))
+	}
+	return httpmw.Chain(s.mux, mws...)
+}
+
+func (s *Server) listBooks(w http.ResponseWriter, r *http.Request) {
+	req, ok := pageRequest(w, r)
+	if !ok {
+		return
+	}
+	var page library.Page
+	var err error
+	if tag := r.URL.Query().Get("tag"); tag != "" && s.tags != nil {
+		page, err = s.tags.BooksByTag(r.Context(), tag, req)
+	} else {
+		page, err = s.books.List(r.Context(), req)
+	}
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// createBook принимает ?copies=N&branch=B, чтобы сразу завести экземпляры новой книги.
+func (s *Server) createBook(w http.ResponseWriter, r *http.Request) {
+	var b library.Book
+	if !decode(w, r, &b) {
+		return
+	}
+	copies := 0
+	if v := r.URL.Query().Get("copies"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "copies must be a non-negative number")
+			return
+		}
+		copies = n
+	}
+	b.ID = ""
+	created, _, err := s.facade.AddBook(r.Context(), b, copies, r.URL.Query().Get("branch"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/books/"+created.ID)
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (s *Server) getBook(w http.ResponseWriter, r *http.Request) {
+	b, err := s.books.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (s *Server) updateBook(w http.ResponseWriter, r *http.Request) {
+	var b library.Book
+	if !decode(w, r, &b) {
+		return
+	}
+	b.ID = r.PathValue("id")
+	if err := s.books.Update(r.Context(), b); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	// Хранилище дополняет книгу (author_id, имя автора из каталога), поэтому в ответе
+	// сохранённая запись, а не тело запроса.
+	stored, err := s.books.Get(r.Context(), b.ID)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stored)
+}
+
+func (s *Server) deleteBook(w http.ResponseWriter, r *http.Request) {
+	if err := s.books.Delete(r.Context(), r.PathValue("id")); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) searchBooks(w http.ResponseWriter, r *http.Request) {
+	limit := library.DefaultPageSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+	searcher := s.search
+	spec, ok := bookSpec(w, r)
+	if !ok {
+		return
+	}
+	if spec != nil {
+		searcher = search.Matching(searcher, spec)
+	}
+	found, err := searcher.Search(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if tag := r.URL.Query().Get("tag"); tag != "" && s.tags != nil {
+		if found, err = s.filterByTag(r, found, tag); err != nil {
+			s.fail(w, r, err)
+			return
+		}
+	}
+	if found == nil {
+		found = []library.Book{}
+	}
+	resp := map[string]any{"books": found}
+	if s.tags != nil {
+		facets, err := s.tagFacets(r, found)
+		if err != nil {
+			s.fail(w, r, err)
+			return
+		}
+		resp["tags"] = facets
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// bookSpec собирает уточнения поиска ?author=&year_from=&year_to=&has_isbn=true; nil - уточнений нет.
+func bookSpec(w http.ResponseWriter, r *http.Request) (specification.Spec[library.Book], bool) {
+	q := r.URL.Query()
+	var specs []specification.Spec[library.Book]
+	if author := q.Get("author"); author != "" {
+		specs = append(specs, specification.ByAuthor(author))
+	}
+	var years [2]int
+	for i, name := range []string{"year_from", "year_to"} {
+		if v := q.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, name+" must be a non-negative number")
+				return nil, false
+			}
+			years[i] = n
+		}
+	}
+	if years[0] != 0 || years[1] != 0 {
+		specs = append(specs, specification.PublishedBetween(years[0], years[1]))
+	}
+	if q.Get("has_isbn") == "true" {
+		specs = append(specs, specification.HasISBN())
+	}
+	if len(specs) == 0 {
+		return nil, true
+	}
+	return specification.And(specs...), true
+}
+
+// fail переводит ошибки доменного слоя в HTTP-статусы.
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, library.ErrNotFound), errors.Is(err, reviews.ErrNotFound),
+		errors.Is(err, lending.ErrNotFound), errors.Is(err, inventory.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, library.ErrConflict), errors.Is(err, lending.ErrAlreadyLoaned),
+		errors.Is(err, lending.ErrAlreadyClosed), errors.Is(err, lending.ErrNoCopies),
+		errors.Is(err, inventory.ErrDuplicateBarcode), errors.Is(err, inventory.ErrOnLoan):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, library.ErrInvalid), errors.Is(err, library.ErrInvalidCursor),
+		errors.Is(err, library.ErrInvalidSort), errors.Is(err, reviews.ErrInvalidRating),
+		errors.Is(err, reviews.ErrInvalidStatus), errors.Is(err, lending.ErrEmptyBorrower),
+		errors.Is(err, dedup.ErrSameBook), errors.Is(err, inventory.ErrInvalid):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, facade.ErrUnsupported):
+		writeError(w, http.StatusNotImplemented, err.Error())
+	default:
+		s.log.Printf("request %s: %v", httpmw.RequestIDFrom(r.Context()), err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
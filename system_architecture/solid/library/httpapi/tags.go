@@ -0,0 +1,135 @@
+package httpapi
+
+import (
+	"net/http"
+	"sort"
+
+	"solid/library"
+)
+
+func (s *Server) tagRoutes() {
+	s.mux.HandleFunc("GET /tags", s.listTags)
+	s.mux.HandleFunc("POST /tags", s.createTag)
+	s.mux.HandleFunc("PUT /tags/{name}", s.renameTag)
+	s.mux.HandleFunc("DELETE /tags/{name}", s.deleteTag)
+	s.mux.HandleFunc("GET /books/{id}/tags", s.bookTags)
+	s.mux.HandleFunc("PUT /books/{id}/tags/{tag}", s.tagBook)
+	s.mux.HandleFunc("DELETE /books/{id}/tags/{tag}", s.untagBook)
+}
+
+type tagRequest struct {
+	Name string `json:"name"`
+}
+
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.tags.ListTags(r.Context())
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
+
+func (s *Server) createTag(w http.ResponseWriter, r *http.Request) {
+	var req tagRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	tag, err := s.tags.CreateTag(r.Context(), req.Name)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, tag)
+}
+
+func (s *Server) renameTag(w http.ResponseWriter, r *http.Request) {
+	var req tagRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if err := s.tags.RenameTag(r.Context(), r.PathValue("name"), req.Name); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteTag(w http.ResponseWriter, r *http.Request) {
+	if err := s.tags.DeleteTag(r.Context(), r.PathValue("name")); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) bookTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.tags.BookTags(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tags": tags})
+}
+
+func (s *Server) tagBook(w http.ResponseWriter, r *http.Request) {
+	if err := s.tags.TagBook(r.Context(), r.PathValue("id"), r.PathValue("tag")); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) untagBook(w http.ResponseWriter, r *http.Request) {
+	if err := s.tags.UntagBook(r.Context(), r.PathValue("id"), r.PathValue("tag")); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) filterByTag(r *http.Request, books []library.Book, tag string) ([]library.Book, error) {
+	tag, err := library.NormalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	var kept []library.Book
+	for _, b := range books {
+		tags, err := s.tags.BookTags(r.Context(), b.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tags {
+			if t == tag {
+				kept = append(kept, b)
+				break
+			}
+		}
+	}
+	return kept, nil
+}
+
+// tagFacets считает, сколько книг из результата поиска помечено каждой меткой.
+func (s *Server) tagFacets(r *http.Request, books []library.Book) ([]library.Tag, error) {
+	counts := make(map[string]int)
+	for _, b := range books {
+		tags, err := s.tags.BookTags(r.Context(), b.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tags {
+			counts[t]++
+		}
+	}
+	facets := make([]library.Tag, 0, len(counts))
+	for name, n := range counts {
+		facets = append(facets, library.Tag{Name: name, Count: n})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Name < facets[j].Name
+	})
+	return facets, nil
+}
@@ -0,0 +1,260 @@
+// Package importer - массовая загрузка книг пулом воркеров (concurrency/batch) с отчётом по каждой записи.
+// Ошибка в одной записи не прерывает импорт остальных. Import принимает пакет целиком,
+// ImportStream - поток любой длины и пишет отчёт по мере импорта.
+package importer
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"solid/concurrency/batch"
+	"solid/data"
+	"solid/library"
+)
+
+const DefaultWorkers = 8
+
+type Accepted struct {
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+}
+
+type Rejected struct {
+	Index  int    `json:"index"`
+	Title  string `json:"title,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type Duplicate struct {
+	Index      int    `json:"index"`
+	ISBN       string `json:"isbn"`
+	ExistingID string `json:"existing_id,omitempty"`
+}
+
+// Report - машиночитаемый итог импорта; записи в каждом списке упорядочены по индексу во входных данных.
+type Report struct {
+	Total      int         `json:"total"`
+	Accepted   []Accepted  `json:"accepted"`
+	Rejected   []Rejected  `json:"rejected"`
+	Duplicates []Duplicate `json:"duplicates"`
+}
+
+type Importer struct {
+	books   library.Repository
+	Workers int
+	// Checkpoint, если задан, хранит под CheckpointKey, сколько книг потока уже
+	// импортировано: ImportStream после сбоя продолжает со следующей, а не начинает
+	// заново и не считает свои же книги дубликатами.
+	Checkpoint    data.Storage
+	CheckpointKey string
+}
+
+func New(books library.Repository) *Importer {
+	return &Importer{books: books, Workers: DefaultWorkers}
+}
+
+// Entry - строка потокового отчёта; задано ровно одно поле.
+type Entry struct {
+	Accepted  *Accepted  `json:"accepted,omitempty"`
+	Rejected  *Rejected  `json:"rejected,omitempty"`
+	Duplicate *Duplicate `json:"duplicate,omitempty"`
+}
+
+// Summary - итог потокового импорта: только счётчики, записи ушли в write.
+type Summary struct {
+	// Resumed - сколько книг пропущено по контрольной точке.
+	Resumed    int `json:"resumed"`
+	Accepted   int `json:"accepted"`
+	Rejected   int `json:"rejected"`
+	Duplicates int `json:"duplicates"`
+}
+
+func (im *Importer) Import(ctx context.Context, books []library.Book) (Report, error) {
+	report := Report{Total: len(books), Accepted: []Accepted{}, Rejected: []Rejected{}, Duplicates: []Duplicate{}}
+	_, err := im.stream(ctx, batch.Config{}, batch.Slice(books), func(e Entry) error {
+		switch {
+		case e.Accepted != nil:
+			report.Accepted = append(report.Accepted, *e.Accepted)
+		case e.Rejected != nil:
+			report.Rejected = append(report.Rejected, *e.Rejected)
+		case e.Duplicate != nil:
+			report.Duplicates = append(report.Duplicates, *e.Duplicate)
+		}
+		return nil
+	})
+	if err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+// ImportStream импортирует книги по мере чтения books и передаёт строку отчёта по
+// каждой в write в порядке потока, так что в памяти не копятся ни книги, ни отчёт.
+// Ошибка чтения или write останавливает импорт; с Checkpoint следующий вызов с тем же
+// потоком продолжит с места остановки.
+func (im *Importer) ImportStream(ctx context.Context, books iter.Seq2[library.Book, error], write func(Entry) error) (Summary, error) {
+	return im.stream(ctx, batch.Config{Checkpoint: im.Checkpoint, Key: im.CheckpointKey}, books, write)
+}
+
+type outcome struct {
+	accepted  *Accepted
+	rejected  *Rejected
+	duplicate *Duplicate
+}
+
+func (im *Importer) stream(ctx context.Context, cfg batch.Config, books iter.Seq2[library.Book, error], write func(Entry) error) (Summary, error) {
+	existing, err := im.existingISBNs(ctx)
+	if err != nil {
+		return Summary{}, err
+	}
+	seen := newClaims(existing)
+	cfg.Workers = im.Workers
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+	var sum Summary
+	// Результаты приходят по порядку потока, так что отчёт не зависит от того, какой
+	// воркер закончил первым.
+	stats, err := batch.Run(ctx, cfg, books, func(ctx context.Context, b library.Book) (outcome, error) {
+		return im.importOne(ctx, b, seen), nil
+	}, func(ctx context.Context, it batch.Item[library.Book, outcome]) error {
+		var e Entry
+		o := it.Value
+		switch {
+		case it.Err != nil:
+			// Упала с паникой.
+			e.Rejected = &Rejected{Title: it.Input.Title, Reason: it.Err.Error()}
+		case o.accepted != nil:
+			e.Accepted = o.accepted
+		case o.rejected != nil:
+			e.Rejected = o.rejected
+		case o.duplicate != nil:
+			e.Duplicate = o.duplicate
+		}
+		switch {
+		case e.Accepted != nil:
+			e.Accepted.Index = it.Index
+			sum.Accepted++
+		case e.Rejected != nil:
+			e.Rejected.Index = it.Index
+			sum.Rejected++
+		case e.Duplicate != nil:
+			e.Duplicate.Index = it.Index
+			sum.Duplicates++
+		}
+		return write(e)
+	})
+	sum.Resumed = stats.Resumed
+	return sum, err
+}
+
+// importOne не знает индекса записи - его проставляет stream.
+func (im *Importer) importOne(ctx context.Context, b library.Book, seen *claims) outcome {
+	if err := b.Validate(); err != nil {
+		return outcome{rejected: &Rejected{Title: b.Title, Reason: err.Error()}}
+	}
+	isbn := library.NormalizeISBN(b.ISBN)
+	if isbn != "" {
+		existing, ok, err := seen.claim(ctx, isbn)
+		if err != nil {
+			return outcome{rejected: &Rejected{Title: b.Title, Reason: err.Error()}}
+		}
+		if !ok {
+			return outcome{duplicate: &Duplicate{ISBN: b.ISBN, ExistingID: existing}}
+		}
+	}
+	b.ID = ""
+	added, err := im.books.Add(ctx, b)
+	if isbn != "" {
+		// Неудачная запись отдаёт ISBN следующей с тем же ISBN, удачная - свой id её дубликатам.
+		seen.settle(isbn, added.ID, err == nil)
+	}
+	if err != nil {
+		return outcome{rejected: &Rejected{Title: b.Title, Reason: err.Error()}}
+	}
+	return outcome{accepted: &Accepted{ID: added.ID}}
+}
+
+// claims - ISBN каталога и пакета. ISBN резервируется за записью до конца её Add,
+// чтобы дубликаты внутри пакета тоже отсеивались; запись с тем же ISBN ждёт, чем
+// Add закончится.
+type claims struct {
+	mu   sync.Mutex
+	isbn map[string]*claimed
+}
+
+type claimed struct {
+	// done закрыт, когда Add завершился; до этого id пуст.
+	done chan struct{}
+	id   string
+}
+
+func newClaims(existing map[string]string) *claims {
+	c := &claims{isbn: make(map[string]*claimed, len(existing))}
+	for isbn, id := range existing {
+		done := make(chan struct{})
+		close(done)
+		c.isbn[isbn] = &claimed{done: done, id: id}
+	}
+	return c
+}
+
+// claim резервирует isbn; false - он уже занят книгой existing.
+func (c *claims) claim(ctx context.Context, isbn string) (existing string, ok bool, err error) {
+	for {
+		c.mu.Lock()
+		cl, taken := c.isbn[isbn]
+		if !taken {
+			c.isbn[isbn] = &claimed{done: make(chan struct{})}
+			c.mu.Unlock()
+			return "", true, nil
+		}
+		c.mu.Unlock()
+		select {
+		case <-cl.done:
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		}
+		c.mu.Lock()
+		id := cl.id
+		c.mu.Unlock()
+		if id != "" {
+			return id, false, nil
+		}
+		// Книгу с этим ISBN добавить не удалось - пробуем сами.
+	}
+}
+
+// settle завершает резерв isbn: при успехе он остаётся за книгой id, иначе снимается.
+func (c *claims) settle(isbn, id string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cl := c.isbn[isbn]
+	if ok {
+		cl.id = id
+	} else {
+		delete(c.isbn, isbn)
+	}
+	close(cl.done)
+}
+
+func (im *Importer) existingISBNs(ctx context.Context) (map[string]string, error) {
+	seen := make(map[string]string)
+	req := library.PageRequest{Limit: library.MaxPageSize}
+	for {
+		page, err := im.books.List(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range page.Books {
+			if isbn := library.NormalizeISBN(b.ISBN); isbn != "" {
+				seen[isbn] = b.ID
+			}
+		}
+		if page.NextCursor == "" {
+			return seen, nil
+		}
+		req.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,73 @@
+// Package inventory - физические экземпляры книг. Библиографическая запись (library.Book)
+// описывает издание, а Copy - конкретный экземпляр со штрихкодом на полке филиала.
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound         = errors.New("inventory: copy not found")
+	ErrDuplicateBarcode = errors.New("inventory: barcode already registered")
+	ErrInvalid          = errors.New("inventory: invalid copy")
+	ErrOnLoan           = errors.New("inventory: copy is on loan")
+)
+
+type Condition string
+
+const (
+	ConditionNew     Condition = "new"
+	ConditionGood    Condition = "good"
+	ConditionWorn    Condition = "worn"
+	ConditionDamaged Condition = "damaged"
+)
+
+type Status string
+
+const (
+	StatusAvailable Status = "available"
+	StatusOnLoan    Status = "on_loan"
+	StatusLost      Status = "lost"
+)
+
+type Copy struct {
+	ID        string    `json:"id"`
+	BookID    string    `json:"book_id"`
+	Barcode   string    `json:"barcode"`
+	Condition Condition `json:"condition"`
+	Branch    string    `json:"branch"`
+	Status    Status    `json:"status"`
+	Label     string    `json:"label,omitempty"`
+}
+
+func (c Copy) Validate() error {
+	switch c.Condition {
+	case ConditionNew, ConditionGood, ConditionWorn, ConditionDamaged:
+	default:
+		return fmt.Errorf("%w: unknown condition %q", ErrInvalid, c.Condition)
+	}
+	if c.Branch == "" {
+		return fmt.Errorf("%w: branch is required", ErrInvalid)
+	}
+	return nil
+}
+
+// Store хранит экземпляры целиком: Update пишет весь Copy. Поэтому каждый, кто
+// читает экземпляр, чтобы записать его обратно, - выдача, перемещение, слияние,
+// этикетки - держит LockCopy и перечитывает экземпляр под ней, иначе запишет старый
+// статус поверх чужой выдачи.
+type Store interface {
+	Add(ctx context.Context, c Copy) (Copy, error)
+	Get(ctx context.Context, id string) (Copy, error)
+	GetByBarcode(ctx context.Context, barcode string) (Copy, error)
+	Update(ctx context.Context, c Copy) error
+	ListByBook(ctx context.Context, bookID string) ([]Copy, error)
+	List(ctx context.Context) ([]Copy, error)
+	// LockCopy ждёт блокировку экземпляра id или отмены ctx; разные экземпляры не
+	// мешают друг другу.
+	LockCopy(ctx context.Context, id string) (unlock func(), err error)
+	// TryLockCopy захватывает экземпляр, только если его никто не держит.
+	TryLockCopy(id string) (unlock func(), ok bool)
+}
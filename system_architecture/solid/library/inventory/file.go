@@ -0,0 +1,93 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+var _ Store = (*FileStore)(nil)
+
+// FileStore хранит экземпляры в JSON-файле поверх MemoryStore, переписывая файл при каждом изменении.
+type FileStore struct {
+	mem  *MemoryStore
+	path string
+	mu   sync.Mutex
+}
+
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{mem: NewMemoryStore(), path: path}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var copies []Copy
+	if err := json.Unmarshal(raw, &copies); err != nil {
+		return nil, err
+	}
+	for _, c := range copies {
+		s.mem.copies[c.ID] = c
+	}
+	return s, nil
+}
+
+func (s *FileStore) Add(ctx context.Context, c Copy) (Copy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	added, err := s.mem.Add(ctx, c)
+	if err != nil {
+		return Copy{}, err
+	}
+	return added, s.flush(ctx)
+}
+
+func (s *FileStore) Get(ctx context.Context, id string) (Copy, error) {
+	return s.mem.Get(ctx, id)
+}
+
+func (s *FileStore) GetByBarcode(ctx context.Context, barcode string) (Copy, error) {
+	return s.mem.GetByBarcode(ctx, barcode)
+}
+
+func (s *FileStore) Update(ctx context.Context, c Copy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.mem.Update(ctx, c); err != nil {
+		return err
+	}
+	return s.flush(ctx)
+}
+
+func (s *FileStore) ListByBook(ctx context.Context, bookID string) ([]Copy, error) {
+	return s.mem.ListByBook(ctx, bookID)
+}
+
+func (s *FileStore) List(ctx context.Context) ([]Copy, error) {
+	return s.mem.List(ctx)
+}
+
+func (s *FileStore) LockCopy(ctx context.Context, id string) (func(), error) {
+	return s.mem.LockCopy(ctx, id)
+}
+
+func (s *FileStore) TryLockCopy(id string) (func(), bool) {
+	return s.mem.TryLockCopy(id)
+}
+
+func (s *FileStore) flush(ctx context.Context) error {
+	copies, _ := s.mem.List(ctx)
+	raw, err := json.MarshalIndent(copies, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
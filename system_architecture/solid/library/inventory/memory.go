@@ -0,0 +1,103 @@
+package inventory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+
+	csync "solid/concurrency/sync"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+type MemoryStore struct {
+	mu     sync.RWMutex
+	copies map[string]Copy
+	// locks - блокировки экземпляров для LockCopy, отдельно от mu: их держат, пока
+	// вызывающий читает и пишет экземпляр.
+	locks csync.KeyedMutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{copies: make(map[string]Copy)}
+}
+
+func (m *MemoryStore) Add(ctx context.Context, c Copy) (Copy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.copies {
+		if existing.Barcode == c.Barcode {
+			return Copy{}, ErrDuplicateBarcode
+		}
+	}
+	if c.ID == "" {
+		b := make([]byte, 8)
+		rand.Read(b)
+		c.ID = hex.EncodeToString(b)
+	}
+	m.copies[c.ID] = c
+	return c, nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, id string) (Copy, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.copies[id]
+	if !ok {
+		return Copy{}, ErrNotFound
+	}
+	return c, nil
+}
+
+func (m *MemoryStore) GetByBarcode(ctx context.Context, barcode string) (Copy, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, c := range m.copies {
+		if c.Barcode == barcode {
+			return c, nil
+		}
+	}
+	return Copy{}, ErrNotFound
+}
+
+func (m *MemoryStore) Update(ctx context.Context, c Copy) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.copies[c.ID]; !ok {
+		return ErrNotFound
+	}
+	m.copies[c.ID] = c
+	return nil
+}
+
+func (m *MemoryStore) ListByBook(ctx context.Context, bookID string) ([]Copy, error) {
+	all, _ := m.List(ctx)
+	var list []Copy
+	for _, c := range all {
+		if c.BookID == bookID {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+
+func (m *MemoryStore) List(ctx context.Context) ([]Copy, error) {
+	m.mu.RLock()
+	list := make([]Copy, 0, len(m.copies))
+	for _, c := range m.copies {
+		list = append(list, c)
+	}
+	m.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Barcode < list[j].Barcode })
+	return list, nil
+}
+
+func (m *MemoryStore) LockCopy(ctx context.Context, id string) (func(), error) {
+	return m.locks.LockKey(ctx, id)
+}
+
+func (m *MemoryStore) TryLockCopy(id string) (func(), bool) {
+	return m.locks.TryLockKey(id)
+}
@@ -0,0 +1,147 @@
+package inventory
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"solid/library"
+)
+
+type BookFinder interface {
+	Get(ctx context.Context, id string) (library.Book, error)
+}
+
+// Availability - сколько экземпляров книги есть и сколько из них на полках, в том числе по филиалам.
+type Availability struct {
+	BookID    string         `json:"book_id"`
+	Total     int            `json:"total"`
+	Available int            `json:"available"`
+	ByBranch  map[string]int `json:"by_branch"`
+}
+
+type Service struct {
+	store Store
+	books BookFinder
+}
+
+func NewService(store Store, books BookFinder) *Service {
+	return &Service{store: store, books: books}
+}
+
+// AddCopy регистрирует экземпляр; пустой штрихкод генерируется автоматически.
+func (s *Service) AddCopy(ctx context.Context, c Copy) (Copy, error) {
+	if _, err := s.books.Get(ctx, c.BookID); err != nil {
+		return Copy{}, err
+	}
+	c.ID = ""
+	c.Status = StatusAvailable
+	c.Branch = strings.TrimSpace(c.Branch)
+	c.Barcode = strings.TrimSpace(c.Barcode)
+	if c.Condition == "" {
+		c.Condition = ConditionGood
+	}
+	if c.Barcode == "" {
+		c.Barcode = newBarcode()
+	}
+	if err := c.Validate(); err != nil {
+		return Copy{}, err
+	}
+	return s.store.Add(ctx, c)
+}
+
+func (s *Service) Get(ctx context.Context, id string) (Copy, error) {
+	return s.store.Get(ctx, id)
+}
+
+func (s *Service) ListByBook(ctx context.Context, bookID string) ([]Copy, error) {
+	return s.store.ListByBook(ctx, bookID)
+}
+
+func (s *Service) Availability(ctx context.Context, bookID string) (Availability, error) {
+	copies, err := s.store.ListByBook(ctx, bookID)
+	if err != nil {
+		return Availability{}, err
+	}
+	a := Availability{BookID: bookID, Total: len(copies), ByBranch: make(map[string]int)}
+	for _, c := range copies {
+		if c.Status == StatusAvailable {
+			a.Available++
+			a.ByBranch[c.Branch]++
+		}
+	}
+	return a, nil
+}
+
+// Transfer перемещает экземпляр в другой филиал. Выданный экземпляр перемещать нельзя.
+func (s *Service) Transfer(ctx context.Context, copyID, toBranch string) (Copy, error) {
+	toBranch = strings.TrimSpace(toBranch)
+	if toBranch == "" {
+		return Copy{}, fmt.Errorf("%w: branch is required", ErrInvalid)
+	}
+	// Под блокировкой экземпляра и по свежему чтению: параллельная выдача меняет
+	// статус, и Update с прочитанным раньше вернул бы экземпляр на полку.
+	unlock, err := s.store.LockCopy(ctx, copyID)
+	if err != nil {
+		return Copy{}, err
+	}
+	defer unlock()
+	c, err := s.store.Get(ctx, copyID)
+	if err != nil {
+		return Copy{}, err
+	}
+	if c.Status == StatusOnLoan {
+		return Copy{}, ErrOnLoan
+	}
+	c.Branch = toBranch
+	if err := s.store.Update(ctx, c); err != nil {
+		return Copy{}, err
+	}
+	return c, nil
+}
+
+// Reassign переносит экземпляры на другую запись каталога при слиянии дубликатов.
+// Каждый экземпляр перечитывается под своей блокировкой: список - только то, какие
+// экземпляры переносить, их статус к этому времени мог измениться.
+func (s *Service) Reassign(ctx context.Context, fromBookID, toBookID string) (int, error) {
+	copies, err := s.store.ListByBook(ctx, fromBookID)
+	if err != nil {
+		return 0, err
+	}
+	moved := 0
+	for _, listed := range copies {
+		ok, err := s.reassign(ctx, listed.ID, fromBookID, toBookID)
+		if err != nil {
+			return moved, err
+		}
+		if ok {
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// reassign переносит один экземпляр; false - его уже перенесли.
+func (s *Service) reassign(ctx context.Context, copyID, fromBookID, toBookID string) (bool, error) {
+	unlock, err := s.store.LockCopy(ctx, copyID)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	c, err := s.store.Get(ctx, copyID)
+	if err != nil {
+		return false, err
+	}
+	if c.BookID != fromBookID {
+		return false, nil
+	}
+	c.BookID = toBookID
+	return true, s.store.Update(ctx, c)
+}
+
+func newBarcode() string {
+	b := make([]byte, 5)
+	rand.Read(b)
+	return fmt.Sprintf("LIB%012d", (uint64(b[0])<<32|uint64(b[1])<<24|uint64(b[2])<<16|uint64(b[3])<<8|uint64(b[4]))%1e12)
+}
@@ -0,0 +1,172 @@
+// Package labels рисует PNG-этикетки со штрихкодом (Code128) или QR-кодом для экземпляров книг.
+package labels
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+
+	"solid/concurrency/pipeline"
+	"solid/design_patterns/pool"
+	"solid/library/inventory"
+)
+
+type Format string
+
+const (
+	Code128 Format = "code128"
+	QR      Format = "qr"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case Code128, QR:
+		return f, nil
+	default:
+		return "", fmt.Errorf("labels: unknown format %q", s)
+	}
+}
+
+// renderWorkers - сколько этикеток RenderMissing рисует одновременно; столько же
+// буферов в пуле NewGenerator, чтобы рисование не ждало буфер.
+const renderWorkers = 4
+
+type Generator struct {
+	Format Format
+	Width  int
+	Height int
+	// Buffers переиспользует внутренние буферы PNG-кодировщика между этикетками; nil - без пула.
+	Buffers *pool.Pool[*png.EncoderBuffer]
+	// Hooks наблюдают за стадиями RenderMissing, например metrics.Registry.Pipeline.
+	Hooks pipeline.Hooks
+}
+
+// NewBufferPool - пул буферов кодировщика; size ограничивает число одновременных кодирований.
+func NewBufferPool(size int) *pool.Pool[*png.EncoderBuffer] {
+	return pool.New(pool.Config[*png.EncoderBuffer]{
+		New:     func(context.Context) (*png.EncoderBuffer, error) { return new(png.EncoderBuffer), nil },
+		MaxSize: size,
+	})
+}
+
+// encoderBuffers приводит пул к png.EncoderBufferPool.
+type encoderBuffers struct {
+	p *pool.Pool[*png.EncoderBuffer]
+}
+
+// Get при ошибке возвращает nil, и кодировщик просто выделяет буфер сам.
+func (b encoderBuffers) Get() *png.EncoderBuffer {
+	buf, err := b.p.Get(context.Background())
+	if err != nil {
+		return nil
+	}
+	return buf
+}
+
+func (b encoderBuffers) Put(buf *png.EncoderBuffer) { b.p.Put(buf) }
+
+// NewGenerator задаёт размеры по умолчанию: вытянутая этикетка для штрихкода, квадрат для QR.
+func NewGenerator(f Format) Generator {
+	if f == QR {
+		return Generator{Format: QR, Width: 256, Height: 256, Buffers: NewBufferPool(renderWorkers)}
+	}
+	return Generator{Format: Code128, Width: 400, Height: 120, Buffers: NewBufferPool(renderWorkers)}
+}
+
+func (g Generator) Render(c inventory.Copy) ([]byte, error) {
+	var code barcode.Barcode
+	var err error
+	switch g.Format {
+	case QR:
+		code, err = qr.Encode(c.Barcode, qr.M, qr.Auto)
+	default:
+		code, err = code128.Encode(c.Barcode)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("labels: encode %q: %w", c.Barcode, err)
+	}
+	scaled, err := barcode.Scale(code, g.Width, g.Height)
+	if err != nil {
+		return nil, fmt.Errorf("labels: scale %q: %w", c.Barcode, err)
+	}
+	enc := png.Encoder{}
+	if g.Buffers != nil {
+		enc.BufferPool = encoderBuffers{p: g.Buffers}
+	}
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, scaled); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Store - то, что пакетной генерации нужно от хранилища экземпляров; реализуется
+// inventory.Store.
+type Store interface {
+	List(ctx context.Context) ([]inventory.Copy, error)
+	Get(ctx context.Context, id string) (inventory.Copy, error)
+	Update(ctx context.Context, c inventory.Copy) error
+	LockCopy(ctx context.Context, id string) (unlock func(), err error)
+}
+
+// rendered - этикетка, нарисованная, но ещё не сохранённая.
+type rendered struct {
+	copy inventory.Copy
+	img  []byte
+}
+
+// RenderMissing рисует этикетки для всех экземпляров без этикетки, складывает их в dir
+// и запоминает путь в Copy.Label. Возвращает число нарисованных этикеток.
+//
+// Рисование идёт в renderWorkers горутин, а запись - по одной:
+// файлы и хранилище экземпляров от параллельности не выигрывают.
+func (g Generator) RenderMissing(ctx context.Context, store Store, dir string) (int, error) {
+	copies, err := store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+	p := pipeline.New(ctx, g.Hooks)
+	images := pipeline.Map(p, "render", renderWorkers, pipeline.Slice(p, "copies", copies), func(_ context.Context, c inventory.Copy) (rendered, error) {
+		if c.Label != "" {
+			return rendered{}, pipeline.Skip
+		}
+		img, err := g.Render(c)
+		return rendered{copy: c, img: img}, err
+	})
+	saved := pipeline.Map(p, "save", 1, images, func(ctx context.Context, r rendered) (string, error) {
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.png", r.copy.Barcode, g.Format))
+		if err := os.WriteFile(path, r.img, 0o644); err != nil {
+			return "", err
+		}
+		return path, saveLabel(ctx, store, r.copy.ID, path)
+	})
+	paths, err := pipeline.Collect(p, saved)
+	return len(paths), err
+}
+
+// saveLabel запоминает путь этикетки в экземпляре, перечитанном под его блокировкой:
+// снимок из List к этому времени устарел, и запись его целиком затёрла бы выдачу,
+// случившуюся, пока этикетка рисовалась.
+func saveLabel(ctx context.Context, store Store, copyID, path string) error {
+	unlock, err := store.LockCopy(ctx, copyID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	c, err := store.Get(ctx, copyID)
+	if err != nil {
+		return err
+	}
+	c.Label = path
+	return store.Update(ctx, c)
+}
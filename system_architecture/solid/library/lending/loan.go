@@ -0,0 +1,67 @@
+// Package lending - выдача и возврат книг.
+package lending
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"solid/eventbus"
+)
+
+var (
+	ErrNotFound      = errors.New("lending: loan not found")
+	ErrAlreadyLoaned = errors.New("lending: copy is already on loan")
+	ErrNoCopies      = errors.New("lending: no available copies")
+	ErrAlreadyClosed = errors.New("lending: loan is already closed")
+	ErrEmptyBorrower = errors.New("lending: borrower is required")
+)
+
+type Loan struct {
+	ID         string     `json:"id"`
+	BookID     string     `json:"book_id"`
+	CopyID     string     `json:"copy_id"`
+	Borrower   string     `json:"borrower"`
+	LoanedAt   time.Time  `json:"loaned_at"`
+	DueAt      time.Time  `json:"due_at"`
+	ReturnedAt *time.Time `json:"returned_at,omitempty"`
+	LostAt     *time.Time `json:"lost_at,omitempty"`
+}
+
+// Active сообщает, что выдача ещё не закрыта ни возвратом, ни утерей.
+func (l Loan) Active() bool {
+	return l.ReturnedAt == nil && l.LostAt == nil
+}
+
+type BookLoaned struct {
+	Loan Loan
+}
+
+type BookReturned struct {
+	Loan Loan
+}
+
+type BookLost struct {
+	Loan Loan
+}
+
+// LoanOverdue - срок выдачи прошёл, а книгу не вернули.
+type LoanOverdue struct {
+	Loan Loan
+}
+
+// Темы событий выдачи в eventbus.
+var (
+	BookLoanedTopic   = eventbus.TopicOf[BookLoaned]()
+	BookReturnedTopic = eventbus.TopicOf[BookReturned]()
+	BookLostTopic     = eventbus.TopicOf[BookLost]()
+	LoanOverdueTopic  = eventbus.TopicOf[LoanOverdue]()
+)
+
+type Store interface {
+	Add(ctx context.Context, l Loan) (Loan, error)
+	Get(ctx context.Context, id string) (Loan, error)
+	Update(ctx context.Context, l Loan) error
+	ListByBook(ctx context.Context, bookID string) ([]Loan, error)
+	List(ctx context.Context) ([]Loan, error)
+}
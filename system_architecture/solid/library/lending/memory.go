@@ -0,0 +1,74 @@
+package lending
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+type MemoryStore struct {
+	mu    sync.RWMutex
+	loans map[string]Loan
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{loans: make(map[string]Loan)}
+}
+
+func (m *MemoryStore) Add(ctx context.Context, l Loan) (Loan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l.ID == "" {
+		b := make([]byte, 8)
+		rand.Read(b)
+		l.ID = hex.EncodeToString(b)
+	}
+	m.loans[l.ID] = l
+	return l, nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, id string) (Loan, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	l, ok := m.loans[id]
+	if !ok {
+		return Loan{}, ErrNotFound
+	}
+	return l, nil
+}
+
+func (m *MemoryStore) Update(ctx context.Context, l Loan) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.loans[l.ID]; !ok {
+		return ErrNotFound
+	}
+	m.loans[l.ID] = l
+	return nil
+}
+
+func (m *MemoryStore) ListByBook(ctx context.Context, bookID string) ([]Loan, error) {
+	all, _ := m.List(ctx)
+	var list []Loan
+	for _, l := range all {
+		if l.BookID == bookID {
+			list = append(list, l)
+		}
+	}
+	return list, nil
+}
+
+func (m *MemoryStore) List(ctx context.Context) ([]Loan, error) {
+	m.mu.RLock()
+	list := make([]Loan, 0, len(m.loans))
+	for _, l := range m.loans {
+		list = append(list, l)
+	}
+	m.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].LoanedAt.Before(list[j].LoanedAt) })
+	return list, nil
+}
@@ -0,0 +1,26 @@
+package lending
+
+import (
+	"context"
+	"time"
+)
+
+// Overdue находит активные выдачи, срок которых истёк после since и не позже now,
+// и публикует LoanOverdue по каждой. Если since - время прошлой проверки, о каждой
+// просрочке сообщается ровно один раз, как бы часто ни шли проверки; нулевой since
+// - все просроченные на сейчас.
+func (s *Service) Overdue(ctx context.Context, since, now time.Time) ([]Loan, error) {
+	loans, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var overdue []Loan
+	for _, l := range loans {
+		if !l.Active() || !l.DueAt.After(since) || l.DueAt.After(now) {
+			continue
+		}
+		overdue = append(overdue, l)
+		s.pub.Publish(ctx, LoanOverdue{Loan: l})
+	}
+	return overdue, nil
+}
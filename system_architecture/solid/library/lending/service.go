@@ -0,0 +1,227 @@
+package lending
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"solid/data"
+	"solid/library"
+	"solid/library/inventory"
+)
+
+const DefaultLoanPeriod = 14 * 24 * time.Hour
+
+// Copies - доступ к экземплярам, который нужен выдаче; реализуется inventory.Store.
+// Блокировки экземпляров берутся у хранилища, а не у Service: их же держат
+// перемещение, слияние и этикетки, которые тоже пишут экземпляр целиком.
+type Copies interface {
+	Get(ctx context.Context, id string) (inventory.Copy, error)
+	ListByBook(ctx context.Context, bookID string) ([]inventory.Copy, error)
+	Update(ctx context.Context, c inventory.Copy) error
+	LockCopy(ctx context.Context, id string) (unlock func(), err error)
+	TryLockCopy(id string) (unlock func(), ok bool)
+}
+
+// Service выдаёт конкретные экземпляры. Книга считается доступной, пока у неё есть
+// хотя бы один экземпляр на полке.
+type Service struct {
+	store  Store
+	copies Copies
+	pub    library.Publisher
+	period time.Duration
+	now    func() time.Time
+}
+
+// NewService при pub == nil публикует события в data.NopEventBus.
+func NewService(store Store, copies Copies, pub library.Publisher) *Service {
+	if pub == nil {
+		pub = data.NopEventBus{}
+	}
+	return &Service{store: store, copies: copies, pub: pub, period: DefaultLoanPeriod, now: time.Now}
+}
+
+// Loan выдаёт любой свободный экземпляр книги. Экземпляры, которые прямо сейчас
+// выдаёт параллельный запрос, сначала пропускаются, а если свободных без блокировки
+// не нашлось, ожидаются по очереди: параллельный запрос мог и не выдать свой
+// экземпляр, и тогда ErrNoCopies был бы ложным.
+func (s *Service) Loan(ctx context.Context, bookID, borrower string) (Loan, error) {
+	borrower = strings.TrimSpace(borrower)
+	if borrower == "" {
+		return Loan{}, ErrEmptyBorrower
+	}
+	copies, err := s.copies.ListByBook(ctx, bookID)
+	if err != nil {
+		return Loan{}, err
+	}
+	var busy []string
+	for _, c := range copies {
+		if c.Status != inventory.StatusAvailable {
+			continue
+		}
+		unlock, ok := s.copies.TryLockCopy(c.ID)
+		if !ok {
+			busy = append(busy, c.ID)
+			continue
+		}
+		loan, err := s.loanLocked(ctx, c.ID, borrower)
+		unlock()
+		if !errors.Is(err, ErrAlreadyLoaned) {
+			return loan, err
+		}
+	}
+	for _, id := range busy {
+		unlock, err := s.copies.LockCopy(ctx, id)
+		if err != nil {
+			return Loan{}, err
+		}
+		loan, err := s.loanLocked(ctx, id, borrower)
+		unlock()
+		if !errors.Is(err, ErrAlreadyLoaned) {
+			return loan, err
+		}
+	}
+	return Loan{}, ErrNoCopies
+}
+
+// LoanCopy выдаёт конкретный экземпляр, например отсканированный по штрихкоду.
+func (s *Service) LoanCopy(ctx context.Context, copyID, borrower string) (Loan, error) {
+	borrower = strings.TrimSpace(borrower)
+	if borrower == "" {
+		return Loan{}, ErrEmptyBorrower
+	}
+	unlock, err := s.copies.LockCopy(ctx, copyID)
+	if err != nil {
+		return Loan{}, err
+	}
+	defer unlock()
+	return s.loanLocked(ctx, copyID, borrower)
+}
+
+// loanLocked выдаёт экземпляр copyID; вызывающий держит его блокировку, поэтому
+// статус перечитывается уже под ней.
+func (s *Service) loanLocked(ctx context.Context, copyID, borrower string) (Loan, error) {
+	c, err := s.copies.Get(ctx, copyID)
+	if err != nil {
+		return Loan{}, err
+	}
+	if c.Status != inventory.StatusAvailable {
+		return Loan{}, ErrAlreadyLoaned
+	}
+	shelved := c
+	c.Status = inventory.StatusOnLoan
+	if err := s.copies.Update(ctx, c); err != nil {
+		return Loan{}, err
+	}
+	now := s.now()
+	loan, err := s.store.Add(ctx, Loan{BookID: c.BookID, CopyID: c.ID, Borrower: borrower, LoanedAt: now, DueAt: now.Add(s.period)})
+	if err != nil {
+		// Без записи о выдаче экземпляр нельзя было бы вернуть: он возвращается на
+		// полку, и даже если ctx запроса уже отменён.
+		if rerr := s.copies.Update(context.WithoutCancel(ctx), shelved); rerr != nil {
+			return Loan{}, errors.Join(err, fmt.Errorf("lending: restore copy %s: %w", c.ID, rerr))
+		}
+		return Loan{}, err
+	}
+	s.pub.Publish(ctx, BookLoaned{Loan: loan})
+	return loan, nil
+}
+
+func (s *Service) Return(ctx context.Context, loanID string) (Loan, error) {
+	loan, unlock, err := s.lockLoan(ctx, loanID)
+	if err != nil {
+		return Loan{}, err
+	}
+	defer unlock()
+	if !loan.Active() {
+		return Loan{}, ErrAlreadyClosed
+	}
+	now := s.now()
+	loan.ReturnedAt = &now
+	if err := s.store.Update(ctx, loan); err != nil {
+		return Loan{}, err
+	}
+	c, err := s.copies.Get(ctx, loan.CopyID)
+	if err != nil {
+		return Loan{}, err
+	}
+	c.Status = inventory.StatusAvailable
+	if err := s.copies.Update(ctx, c); err != nil {
+		return Loan{}, err
+	}
+	s.pub.Publish(ctx, BookReturned{Loan: loan})
+	return loan, nil
+}
+
+// MarkLost закрывает выдачу как утерянную; экземпляр списывается и на полку не возвращается.
+func (s *Service) MarkLost(ctx context.Context, loanID string) (Loan, error) {
+	loan, unlock, err := s.lockLoan(ctx, loanID)
+	if err != nil {
+		return Loan{}, err
+	}
+	defer unlock()
+	if !loan.Active() {
+		return Loan{}, ErrAlreadyClosed
+	}
+	now := s.now()
+	loan.LostAt = &now
+	if err := s.store.Update(ctx, loan); err != nil {
+		return Loan{}, err
+	}
+	c, err := s.copies.Get(ctx, loan.CopyID)
+	if err != nil {
+		return Loan{}, err
+	}
+	c.Status = inventory.StatusLost
+	if err := s.copies.Update(ctx, c); err != nil {
+		return Loan{}, err
+	}
+	s.pub.Publish(ctx, BookLost{Loan: loan})
+	return loan, nil
+}
+
+// lockLoan захватывает экземпляр выдачи loanID и перечитывает её под блокировкой:
+// пока ждали, параллельный запрос мог её закрыть.
+func (s *Service) lockLoan(ctx context.Context, loanID string) (Loan, func(), error) {
+	loan, err := s.store.Get(ctx, loanID)
+	if err != nil {
+		return Loan{}, nil, err
+	}
+	unlock, err := s.copies.LockCopy(ctx, loan.CopyID)
+	if err != nil {
+		return Loan{}, nil, err
+	}
+	if loan, err = s.store.Get(ctx, loanID); err != nil {
+		unlock()
+		return Loan{}, nil, err
+	}
+	return loan, unlock, nil
+}
+
+func (s *Service) History(ctx context.Context, bookID string) ([]Loan, error) {
+	return s.store.ListByBook(ctx, bookID)
+}
+
+// Reassign переносит историю выдач на другую книгу (используется при слиянии дубликатов).
+func (s *Service) Reassign(ctx context.Context, fromBookID, toBookID string) (int, error) {
+	loans, err := s.store.ListByBook(ctx, fromBookID)
+	if err != nil {
+		return 0, err
+	}
+	for _, l := range loans {
+		// Под блокировкой экземпляра, чтобы не затереть параллельный возврат этой выдачи.
+		l, unlock, err := s.lockLoan(ctx, l.ID)
+		if err != nil {
+			return 0, err
+		}
+		l.BookID = toBookID
+		err = s.store.Update(ctx, l)
+		unlock()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(loans), nil
+}
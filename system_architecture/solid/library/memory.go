@@ -0,0 +1,380 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	_ Repository       = (*MemoryRepository)(nil)
+	_ TagRepository    = (*MemoryRepository)(nil)
+	_ AuthorRepository = (*MemoryRepository)(nil)
+)
+
+type MemoryRepository struct {
+	mu    sync.RWMutex
+	books map[string]Book
+	// tags - множество меток, bookTags - метки каждой книги.
+	tags     map[string]struct{}
+	bookTags map[string]map[string]struct{}
+	authors  map[string]Author
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		books:    make(map[string]Book),
+		tags:     make(map[string]struct{}),
+		bookTags: make(map[string]map[string]struct{}),
+		authors:  make(map[string]Author),
+	}
+}
+
+func (r *MemoryRepository) Add(ctx context.Context, b Book) (Book, error) {
+	if err := b.Validate(); err != nil {
+		return Book{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if b.ID == "" {
+		b.ID = newID()
+	}
+	if _, ok := r.books[b.ID]; ok {
+		return Book{}, ErrConflict
+	}
+	if err := r.linkAuthor(&b); err != nil {
+		return Book{}, err
+	}
+	r.books[b.ID] = b
+	return b, nil
+}
+
+func (r *MemoryRepository) Get(ctx context.Context, id string) (Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b, ok := r.books[id]
+	if !ok {
+		return Book{}, ErrNotFound
+	}
+	return b, nil
+}
+
+func (r *MemoryRepository) Update(ctx context.Context, b Book) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.books[b.ID]; !ok {
+		return ErrNotFound
+	}
+	if err := r.linkAuthor(&b); err != nil {
+		return err
+	}
+	r.books[b.ID] = b
+	return nil
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.books[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.books, id)
+	delete(r.bookTags, id)
+	return nil
+}
+
+func (r *MemoryRepository) List(ctx context.Context, req PageRequest) (Page, error) {
+	r.mu.RLock()
+	books := make([]Book, 0, len(r.books))
+	for _, b := range r.books {
+		books = append(books, b)
+	}
+	r.mu.RUnlock()
+	return paginate(books, req)
+}
+
+// Search - простой поиск подстроки по названию, автору и ISBN.
+func (r *MemoryRepository) Search(ctx context.Context, query string, limit int) ([]Book, error) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	r.mu.RLock()
+	var found []Book
+	for _, b := range r.books {
+		if q == "" || strings.Contains(strings.ToLower(b.Title), q) ||
+			strings.Contains(strings.ToLower(b.Author), q) || strings.Contains(b.ISBN, q) {
+			found = append(found, b)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(found, func(i, j int) bool { return compareBooks(found[i], found[j], SortByTitle) < 0 })
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func (r *MemoryRepository) CreateTag(ctx context.Context, name string) (Tag, error) {
+	name, err := NormalizeTag(name)
+	if err != nil {
+		return Tag{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tags[name]; ok {
+		return Tag{}, ErrConflict
+	}
+	r.tags[name] = struct{}{}
+	return Tag{Name: name}, nil
+}
+
+func (r *MemoryRepository) RenameTag(ctx context.Context, oldName, newName string) error {
+	oldName, err := NormalizeTag(oldName)
+	if err != nil {
+		return err
+	}
+	if newName, err = NormalizeTag(newName); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tags[oldName]; !ok {
+		return ErrNotFound
+	}
+	if _, ok := r.tags[newName]; ok {
+		return ErrConflict
+	}
+	delete(r.tags, oldName)
+	r.tags[newName] = struct{}{}
+	for _, tags := range r.bookTags {
+		if _, ok := tags[oldName]; ok {
+			delete(tags, oldName)
+			tags[newName] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) DeleteTag(ctx context.Context, name string) error {
+	name, err := NormalizeTag(name)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tags[name]; !ok {
+		return ErrNotFound
+	}
+	delete(r.tags, name)
+	for _, tags := range r.bookTags {
+		delete(tags, name)
+	}
+	return nil
+}
+
+func (r *MemoryRepository) ListTags(ctx context.Context) ([]Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	counts := make(map[string]int, len(r.tags))
+	for _, tags := range r.bookTags {
+		for t := range tags {
+			counts[t]++
+		}
+	}
+	list := make([]Tag, 0, len(r.tags))
+	for t := range r.tags {
+		list = append(list, Tag{Name: t, Count: counts[t]})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+// TagBook помечает книгу; несуществующая метка создаётся автоматически.
+func (r *MemoryRepository) TagBook(ctx context.Context, bookID, tag string) error {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.books[bookID]; !ok {
+		return ErrNotFound
+	}
+	r.tags[tag] = struct{}{}
+	if r.bookTags[bookID] == nil {
+		r.bookTags[bookID] = make(map[string]struct{})
+	}
+	r.bookTags[bookID][tag] = struct{}{}
+	return nil
+}
+
+func (r *MemoryRepository) UntagBook(ctx context.Context, bookID, tag string) error {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.bookTags[bookID][tag]; !ok {
+		return ErrNotFound
+	}
+	delete(r.bookTags[bookID], tag)
+	return nil
+}
+
+func (r *MemoryRepository) BookTags(ctx context.Context, bookID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.books[bookID]; !ok {
+		return nil, ErrNotFound
+	}
+	tags := make([]string, 0, len(r.bookTags[bookID]))
+	for t := range r.bookTags[bookID] {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+func (r *MemoryRepository) BooksByTag(ctx context.Context, tag string, req PageRequest) (Page, error) {
+	tag, err := NormalizeTag(tag)
+	if err != nil {
+		return Page{}, err
+	}
+	r.mu.RLock()
+	if _, ok := r.tags[tag]; !ok {
+		r.mu.RUnlock()
+		return Page{}, ErrNotFound
+	}
+	var books []Book
+	for id, tags := range r.bookTags {
+		if _, ok := tags[tag]; ok {
+			books = append(books, r.books[id])
+		}
+	}
+	r.mu.RUnlock()
+	return paginate(books, req)
+}
+
+// clone - независимая копия каталога: FileRepository меняет её, пока сохраняет.
+func (m *MemoryRepository) clone() *MemoryRepository {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c := &MemoryRepository{
+		books:    maps.Clone(m.books),
+		tags:     maps.Clone(m.tags),
+		bookTags: make(map[string]map[string]struct{}, len(m.bookTags)),
+		authors:  maps.Clone(m.authors),
+	}
+	for id, set := range m.bookTags {
+		c.bookTags[id] = maps.Clone(set)
+	}
+	return c
+}
+
+// replace подменяет содержимое m содержимым from, которое больше никто не меняет.
+func (m *MemoryRepository) replace(from *MemoryRepository) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.books, m.tags, m.bookTags, m.authors = from.books, from.tags, from.bookTags, from.authors
+}
+
+// linkAuthor связывает книгу с автором: по AuthorID, если он указан, иначе по имени,
+// создавая автора при первом упоминании; имя в книге становится именем автора из
+// каталога. Имя рядом с AuthorID либо пустое, либо совпадает с именем автора: иначе
+// непонятно, что клиент хотел поменять. Вызывается под r.mu.
+func (r *MemoryRepository) linkAuthor(b *Book) error {
+	if b.AuthorID != "" {
+		a, ok := r.authors[b.AuthorID]
+		if !ok {
+			return fmt.Errorf("%w: unknown author %q", ErrInvalid, b.AuthorID)
+		}
+		if key := authorKey(b.Author); key != "" && key != authorKey(a.Name) {
+			return fmt.Errorf("%w: author %q does not match author_id %q (%s)", ErrInvalid, b.Author, b.AuthorID, a.Name)
+		}
+		b.Author = a.Name
+		return nil
+	}
+	key := authorKey(b.Author)
+	if key == "" {
+		return nil
+	}
+	for _, a := range r.authors {
+		if authorKey(a.Name) == key {
+			b.AuthorID, b.Author = a.ID, a.Name
+			return nil
+		}
+	}
+	a := Author{ID: newID(), Name: strings.TrimSpace(b.Author)}
+	r.authors[a.ID] = a
+	b.AuthorID = a.ID
+	return nil
+}
+
+func (r *MemoryRepository) AddAuthor(ctx context.Context, a Author) (Author, error) {
+	if err := a.Validate(); err != nil {
+		return Author{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a.Name = strings.TrimSpace(a.Name)
+	for _, existing := range r.authors {
+		if authorKey(existing.Name) == authorKey(a.Name) {
+			return Author{}, ErrConflict
+		}
+	}
+	if a.ID == "" {
+		a.ID = newID()
+	}
+	if _, ok := r.authors[a.ID]; ok {
+		return Author{}, ErrConflict
+	}
+	r.authors[a.ID] = a
+	return a, nil
+}
+
+func (r *MemoryRepository) GetAuthor(ctx context.Context, id string) (Author, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.authors[id]
+	if !ok {
+		return Author{}, ErrNotFound
+	}
+	return a, nil
+}
+
+func (r *MemoryRepository) ListAuthors(ctx context.Context) ([]Author, error) {
+	r.mu.RLock()
+	list := make([]Author, 0, len(r.authors))
+	for _, a := range r.authors {
+		list = append(list, a)
+	}
+	r.mu.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return authorKey(list[i].Name) < authorKey(list[j].Name) })
+	return list, nil
+}
+
+func (r *MemoryRepository) BooksByAuthor(ctx context.Context, authorID string, req PageRequest) (Page, error) {
+	r.mu.RLock()
+	if _, ok := r.authors[authorID]; !ok {
+		r.mu.RUnlock()
+		return Page{}, ErrNotFound
+	}
+	var books []Book
+	for _, b := range r.books {
+		if b.AuthorID == authorID {
+			books = append(books, b)
+		}
+	}
+	r.mu.RUnlock()
+	return paginate(books, req)
+}
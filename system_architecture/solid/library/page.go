@@ -0,0 +1,159 @@
+package library
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type SortKey string
+
+const (
+	SortByTitle  SortKey = "title"
+	SortByAuthor SortKey = "author"
+	SortByYear   SortKey = "year"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("library: invalid cursor")
+	ErrInvalidSort   = errors.New("library: unknown sort key")
+)
+
+// PageRequest - запрос страницы. Cursor берётся из Page.NextCursor предыдущего ответа,
+// пустой курсор означает первую страницу.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+	SortBy SortKey
+	Desc   bool
+}
+
+type Page struct {
+	Books      []Book `json:"books"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func ParseSortKey(s string) (SortKey, error) {
+	switch k := SortKey(s); k {
+	case "":
+		return SortByTitle, nil
+	case SortByTitle, SortByAuthor, SortByYear:
+		return k, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrInvalidSort, s)
+	}
+}
+
+// cursor запоминает позицию последней отданной книги. ID входит в ключ сортировки,
+// поэтому порядок стабилен даже при одинаковых названиях.
+type cursor struct {
+	SortBy SortKey `json:"s"`
+	Desc   bool    `json:"d,omitempty"`
+	Value  string  `json:"v"`
+	ID     string  `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+func sortValue(b Book, key SortKey) string {
+	switch key {
+	case SortByAuthor:
+		return strings.ToLower(b.Author)
+	case SortByYear:
+		// Год дополняется нулями, чтобы строковое сравнение совпадало с числовым.
+		return fmt.Sprintf("%010d", b.Year)
+	default:
+		return strings.ToLower(b.Title)
+	}
+}
+
+func compareBooks(a, b Book, key SortKey) int {
+	if c := strings.Compare(sortValue(a, key), sortValue(b, key)); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// paginate - общая для всех бэкендов логика сортировки и нарезки страниц.
+func paginate(books []Book, req PageRequest) (Page, error) {
+	key, err := ParseSortKey(string(req.SortBy))
+	if err != nil {
+		return Page{}, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	sorted := make([]Book, len(books))
+	copy(sorted, books)
+	sort.Slice(sorted, func(i, j int) bool {
+		c := compareBooks(sorted[i], sorted[j], key)
+		if req.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	start := 0
+	if req.Cursor != "" {
+		c, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		if c.SortBy != key || c.Desc != req.Desc {
+			return Page{}, fmt.Errorf("%w: sort order changed", ErrInvalidCursor)
+		}
+		start = sort.Search(len(sorted), func(i int) bool {
+			c := compareCursor(sorted[i], key, c)
+			if req.Desc {
+				return c < 0
+			}
+			return c > 0
+		})
+	}
+
+	end := start + limit
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+	page := Page{Books: sorted[start:end]}
+	if end < len(sorted) {
+		last := sorted[end-1]
+		page.NextCursor = encodeCursor(cursor{SortBy: key, Desc: req.Desc, Value: sortValue(last, key), ID: last.ID})
+	}
+	return page, nil
+}
+
+func compareCursor(b Book, key SortKey, c cursor) int {
+	if r := strings.Compare(sortValue(b, key), c.Value); r != 0 {
+		return r
+	}
+	return strings.Compare(b.ID, c.ID)
+}
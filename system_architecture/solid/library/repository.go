@@ -0,0 +1,19 @@
+package library
+
+import "context"
+
+// Repository - абстракция хранилища книг. Верхние слои (HTTP API, CLI) зависят только от неё,
+// конкретный бэкенд (память, файл) подставляется при сборке приложения.
+type Repository interface {
+	Add(ctx context.Context, b Book) (Book, error)
+	Get(ctx context.Context, id string) (Book, error)
+	Update(ctx context.Context, b Book) error
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, req PageRequest) (Page, error)
+}
+
+// Searcher вынесен в отдельный интерфейс (принцип I): поиск может обслуживаться
+// не тем же компонентом, что хранит книги.
+type Searcher interface {
+	Search(ctx context.Context, query string, limit int) ([]Book, error)
+}
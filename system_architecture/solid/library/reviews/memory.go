@@ -0,0 +1,81 @@
+package reviews
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+)
+
+var _ Repository = (*MemoryRepository)(nil)
+
+type MemoryRepository struct {
+	mu      sync.RWMutex
+	reviews map[string]Review
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{reviews: make(map[string]Review)}
+}
+
+func (m *MemoryRepository) Add(ctx context.Context, r Review) (Review, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r.ID == "" {
+		b := make([]byte, 8)
+		rand.Read(b)
+		r.ID = hex.EncodeToString(b)
+	}
+	m.reviews[r.ID] = r
+	return r, nil
+}
+
+func (m *MemoryRepository) Get(ctx context.Context, id string) (Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	r, ok := m.reviews[id]
+	if !ok {
+		return Review{}, ErrNotFound
+	}
+	return r, nil
+}
+
+func (m *MemoryRepository) SetStatus(ctx context.Context, id string, status Status) (Review, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.reviews[id]
+	if !ok {
+		return Review{}, ErrNotFound
+	}
+	r.Status = status
+	m.reviews[id] = r
+	return r, nil
+}
+
+func (m *MemoryRepository) ListByBook(ctx context.Context, bookID string) ([]Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var list []Review
+	for _, r := range m.reviews {
+		if r.BookID == bookID {
+			list = append(list, r)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (m *MemoryRepository) Reassign(ctx context.Context, fromBookID, toBookID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, r := range m.reviews {
+		if r.BookID == fromBookID {
+			r.BookID = toBookID
+			m.reviews[id] = r
+			n++
+		}
+	}
+	return n, nil
+}
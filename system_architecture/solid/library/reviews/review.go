@@ -0,0 +1,56 @@
+// Package reviews - отзывы и оценки книг с модерацией.
+package reviews
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("reviews: not found")
+	ErrInvalidRating = errors.New("reviews: rating must be between 1 and 5")
+	ErrInvalidStatus = errors.New("reviews: unknown moderation status")
+)
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+func ParseStatus(s string) (Status, error) {
+	switch st := Status(s); st {
+	case StatusPending, StatusApproved, StatusRejected:
+		return st, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidStatus, s)
+	}
+}
+
+type Review struct {
+	ID        string    `json:"id"`
+	BookID    string    `json:"book_id"`
+	Rating    int       `json:"rating"`
+	Text      string    `json:"text,omitempty"`
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Rating - средняя оценка книги по одобренным отзывам.
+type Rating struct {
+	BookID  string  `json:"book_id"`
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+type Repository interface {
+	Add(ctx context.Context, r Review) (Review, error)
+	Get(ctx context.Context, id string) (Review, error)
+	SetStatus(ctx context.Context, id string, status Status) (Review, error)
+	ListByBook(ctx context.Context, bookID string) ([]Review, error)
+	Reassign(ctx context.Context, fromBookID, toBookID string) (int, error)
+}
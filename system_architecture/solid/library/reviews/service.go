@@ -0,0 +1,115 @@
+package reviews
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"solid/cache"
+	"solid/library"
+)
+
+// BookFinder - всё, что сервису нужно знать о каталоге: существует ли книга.
+type BookFinder interface {
+	Get(ctx context.Context, id string) (library.Book, error)
+}
+
+// ratingCacheSize - сколько книг держит кэш средних оценок; редко открываемые вытесняются.
+const ratingCacheSize = 4096
+
+// Service добавляет отзывы, модерирует их и кэширует среднюю оценку.
+// Кэш сбрасывается для книги при любом изменении её отзывов.
+type Service struct {
+	repo  Repository
+	books BookFinder
+	now   func() time.Time
+	cache *cache.Cache[string, Rating]
+}
+
+func NewService(repo Repository, books BookFinder) *Service {
+	return &Service{repo: repo, books: books, now: time.Now, cache: cache.New[string, Rating](cache.Config{Capacity: ratingCacheSize})}
+}
+
+// Add сохраняет отзыв в статусе "на модерации".
+func (s *Service) Add(ctx context.Context, bookID string, rating int, text string) (Review, error) {
+	if rating < 1 || rating > 5 {
+		return Review{}, ErrInvalidRating
+	}
+	if _, err := s.books.Get(ctx, bookID); err != nil {
+		return Review{}, err
+	}
+	r, err := s.repo.Add(ctx, Review{
+		BookID:    bookID,
+		Rating:    rating,
+		Text:      strings.TrimSpace(text),
+		Status:    StatusPending,
+		CreatedAt: s.now(),
+	})
+	if err != nil {
+		return Review{}, err
+	}
+	s.invalidate(bookID)
+	return r, nil
+}
+
+func (s *Service) Moderate(ctx context.Context, id string, status Status) (Review, error) {
+	r, err := s.repo.SetStatus(ctx, id, status)
+	if err != nil {
+		return Review{}, err
+	}
+	s.invalidate(r.BookID)
+	return r, nil
+}
+
+// List возвращает отзывы книги; неодобренные отдаются только при all=true.
+func (s *Service) List(ctx context.Context, bookID string, all bool) ([]Review, error) {
+	list, err := s.repo.ListByBook(ctx, bookID)
+	if err != nil || all {
+		return list, err
+	}
+	approved := list[:0]
+	for _, r := range list {
+		if r.Status == StatusApproved {
+			approved = append(approved, r)
+		}
+	}
+	return approved, nil
+}
+
+// Rating - средняя оценка одобренных отзывов. Одновременные запросы одной книги
+// при пустом кэше считают её один раз.
+func (s *Service) Rating(ctx context.Context, bookID string) (Rating, error) {
+	return s.cache.GetOrLoad(ctx, bookID, func(ctx context.Context) (Rating, error) {
+		return s.rating(ctx, bookID)
+	})
+}
+
+func (s *Service) rating(ctx context.Context, bookID string) (Rating, error) {
+	list, err := s.List(ctx, bookID, false)
+	if err != nil {
+		return Rating{}, err
+	}
+	rating := Rating{BookID: bookID, Count: len(list)}
+	if len(list) > 0 {
+		sum := 0
+		for _, r := range list {
+			sum += r.Rating
+		}
+		rating.Average = float64(sum) / float64(len(list))
+	}
+	return rating, nil
+}
+
+func (s *Service) invalidate(bookID string) {
+	s.cache.Delete(bookID)
+}
+
+func (s *Service) Reassign(ctx context.Context, fromBookID, toBookID string) (int, error) {
+	n, err := s.repo.Reassign(ctx, fromBookID, toBookID)
+	if err != nil {
+		return 0, err
+	}
+	s.invalidate(fromBookID)
+	s.invalidate(toBookID)
+	return n, nil
+}
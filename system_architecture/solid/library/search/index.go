@@ -0,0 +1,217 @@
+// Package search - инвертированный индекс каталога с поиском по префиксам и ранжированием.
+// Индекс реализует library.Searcher и обновляется по доменным событиям репозитория.
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"solid/eventbus"
+	"solid/library"
+)
+
+// Веса полей: совпадение в названии важнее совпадения в имени автора.
+const (
+	titleWeight  = 2.0
+	authorWeight = 1.5
+	isbnWeight   = 1.0
+	// prefixPenalty снижает вклад терминов, совпавших только по префиксу.
+	prefixPenalty = 0.5
+)
+
+var _ library.Searcher = (*Index)(nil)
+
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]library.Book
+	postings map[string]map[string]float64
+	terms    []string // отсортированный словарь для поиска по префиксу
+	dirty    bool
+}
+
+func NewIndex() *Index {
+	return &Index{docs: make(map[string]library.Book), postings: make(map[string]map[string]float64)}
+}
+
+// Subscribe подключает индекс к шине событий, чтобы он следил за изменениями каталога.
+// Доставка синхронная: книга находится поиском сразу после ответа на её добавление.
+func (ix *Index) Subscribe(ctx context.Context, bus *eventbus.Bus) error {
+	if _, err := eventbus.Subscribe(ctx, bus, library.BookAddedTopic, func(ctx context.Context, e library.BookAdded) error {
+		ix.Put(e.Book)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if _, err := eventbus.Subscribe(ctx, bus, library.BookUpdatedTopic, func(ctx context.Context, e library.BookUpdated) error {
+		ix.Put(e.Book)
+		return nil
+	}); err != nil {
+		return err
+	}
+	_, err := eventbus.Subscribe(ctx, bus, library.BookDeletedTopic, func(ctx context.Context, e library.BookDeleted) error {
+		ix.Remove(e.ID)
+		return nil
+	})
+	return err
+}
+
+// Rebuild заполняет индекс текущим содержимым репозитория.
+func (ix *Index) Rebuild(ctx context.Context, repo library.Repository) error {
+	req := library.PageRequest{Limit: library.MaxPageSize}
+	for {
+		page, err := repo.List(ctx, req)
+		if err != nil {
+			return err
+		}
+		for _, b := range page.Books {
+			ix.Put(b)
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		req.Cursor = page.NextCursor
+	}
+}
+
+func (ix *Index) Put(b library.Book) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(b.ID)
+	ix.docs[b.ID] = b
+	weights := make(map[string]float64)
+	for _, t := range Tokenize(b.Title) {
+		weights[t] += titleWeight
+	}
+	for _, t := range Tokenize(b.Author) {
+		weights[t] += authorWeight
+	}
+	for _, t := range Tokenize(b.ISBN) {
+		weights[t] += isbnWeight
+	}
+	for t, w := range weights {
+		if ix.postings[t] == nil {
+			ix.postings[t] = make(map[string]float64)
+			ix.dirty = true
+		}
+		ix.postings[t][b.ID] = w
+	}
+}
+
+func (ix *Index) Remove(id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+}
+
+func (ix *Index) remove(id string) {
+	if _, ok := ix.docs[id]; !ok {
+		return
+	}
+	delete(ix.docs, id)
+	for t, docs := range ix.postings {
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(ix.postings, t)
+			ix.dirty = true
+		}
+	}
+}
+
+// Search ищет книги, содержащие все слова запроса (каждое - как термин или его префикс),
+// и упорядочивает их по TF-IDF с весами полей.
+func (ix *Index) Search(ctx context.Context, query string, limit int) ([]library.Book, error) {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	ix.prepare()
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	n := float64(len(ix.docs))
+	var scores map[string]float64
+	for _, tok := range tokens {
+		tokScores := make(map[string]float64)
+		for _, term := range ix.expand(tok) {
+			docs := ix.postings[term]
+			idf := math.Log(1 + n/float64(len(docs)))
+			boost := 1.0
+			if term != tok {
+				boost = prefixPenalty
+			}
+			for id, w := range docs {
+				tokScores[id] = math.Max(tokScores[id], w*idf*boost)
+			}
+		}
+		if scores == nil {
+			scores = tokScores
+			continue
+		}
+		for id, s := range scores {
+			if ts, ok := tokScores[id]; ok {
+				scores[id] = s + ts
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	found := make([]library.Book, len(ids))
+	for i, id := range ids {
+		found[i] = ix.docs[id]
+	}
+	return found, nil
+}
+
+// prepare пересобирает отсортированный словарь, если он устарел.
+func (ix *Index) prepare() {
+	ix.mu.RLock()
+	dirty := ix.dirty
+	ix.mu.RUnlock()
+	if !dirty {
+		return
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.terms = ix.terms[:0]
+	for t := range ix.postings {
+		ix.terms = append(ix.terms, t)
+	}
+	sort.Strings(ix.terms)
+	ix.dirty = false
+}
+
+// expand возвращает термины словаря, начинающиеся с prefix.
+func (ix *Index) expand(prefix string) []string {
+	i := sort.SearchStrings(ix.terms, prefix)
+	var out []string
+	for ; i < len(ix.terms) && strings.HasPrefix(ix.terms[i], prefix); i++ {
+		if _, ok := ix.postings[ix.terms[i]]; ok {
+			out = append(out, ix.terms[i])
+		}
+	}
+	return out
+}
+
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,32 @@
+package search
+
+import (
+	"context"
+
+	"solid/design_patterns/specification"
+	"solid/library"
+)
+
+// matching - декоратор поиска, оставляющий только книги, удовлетворяющие спецификации.
+type matching struct {
+	library.Searcher
+	spec specification.Spec[library.Book]
+}
+
+// Matching уточняет результаты s спецификацией. Внутренний поиск выполняется без лимита,
+// чтобы отсеянные книги не уменьшали выдачу; лимит применяется после фильтра.
+func Matching(s library.Searcher, spec specification.Spec[library.Book]) library.Searcher {
+	return matching{Searcher: s, spec: spec}
+}
+
+func (m matching) Search(ctx context.Context, query string, limit int) ([]library.Book, error) {
+	found, err := m.Searcher.Search(ctx, query, 0)
+	if err != nil {
+		return nil, err
+	}
+	found = specification.Filter(found, m.spec)
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
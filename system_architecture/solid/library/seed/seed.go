@@ -0,0 +1,43 @@
+// Package seed загружает стартовый каталог, встроенный в бинарник через go:embed,
+// в любой настроенный бэкенд library.Repository.
+package seed
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+
+	"solid/library"
+)
+
+//go:embed catalog.json
+var catalog []byte
+
+func Books() ([]library.Book, error) {
+	var books []library.Book
+	if err := json.Unmarshal(catalog, &books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// Seed добавляет книги стартового каталога. У книг фиксированные ID, поэтому
+// повторный запуск ничего не дублирует: уже существующие книги пропускаются.
+func Seed(ctx context.Context, repo library.Repository) (added int, err error) {
+	books, err := Books()
+	if err != nil {
+		return 0, err
+	}
+	for _, b := range books {
+		_, err := repo.Add(ctx, b)
+		switch {
+		case err == nil:
+			added++
+		case errors.Is(err, library.ErrConflict):
+		default:
+			return added, err
+		}
+	}
+	return added, nil
+}
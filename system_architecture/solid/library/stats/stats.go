@@ -0,0 +1,65 @@
+// Package stats - проекция со счётчиками каталога, собираемая из доменных событий.
+package stats
+
+import (
+	"context"
+	"sync"
+
+	"solid/eventbus"
+	"solid/library"
+	"solid/library/lending"
+)
+
+type Snapshot struct {
+	Books       int `json:"books"`
+	ActiveLoans int `json:"active_loans"`
+	TotalLoans  int `json:"total_loans"`
+}
+
+type Projection struct {
+	mu   sync.RWMutex
+	snap Snapshot
+}
+
+// Register подписывает проекцию на события шины. Проекция живёт в памяти, поэтому
+// при журнале она сначала проигрывает всю историю и после перезапуска не обнуляется.
+func Register(ctx context.Context, bus *eventbus.Bus) (*Projection, error) {
+	p := &Projection{}
+	if err := subscribe(ctx, bus, library.BookAddedTopic, p, func(s *Snapshot) { s.Books++ }); err != nil {
+		return nil, err
+	}
+	if err := subscribe(ctx, bus, library.BookDeletedTopic, p, func(s *Snapshot) { s.Books-- }); err != nil {
+		return nil, err
+	}
+	if err := subscribe(ctx, bus, lending.BookLoanedTopic, p, func(s *Snapshot) { s.ActiveLoans++; s.TotalLoans++ }); err != nil {
+		return nil, err
+	}
+	if err := subscribe(ctx, bus, lending.BookReturnedTopic, p, func(s *Snapshot) { s.ActiveLoans-- }); err != nil {
+		return nil, err
+	}
+	if err := subscribe(ctx, bus, lending.BookLostTopic, p, func(s *Snapshot) { s.ActiveLoans-- }); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// subscribe применяет fn к снимку на каждое событие темы t; содержимое события счётчикам не нужно.
+func subscribe[E any](ctx context.Context, bus *eventbus.Bus, t eventbus.Topic[E], p *Projection, fn func(*Snapshot)) error {
+	_, err := eventbus.Subscribe(ctx, bus, t, func(ctx context.Context, e E) error {
+		p.update(fn)
+		return nil
+	}, eventbus.FromStart())
+	return err
+}
+
+func (p *Projection) update(fn func(*Snapshot)) {
+	p.mu.Lock()
+	fn(&p.snap)
+	p.mu.Unlock()
+}
+
+func (p *Projection) Snapshot() Snapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.snap
+}
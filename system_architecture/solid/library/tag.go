@@ -0,0 +1,36 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Tag - метка книги вместе с числом книг, которые ей помечены.
+type Tag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// TagRepository - связь книг и меток "многие ко многим".
+type TagRepository interface {
+	CreateTag(ctx context.Context, name string) (Tag, error)
+	RenameTag(ctx context.Context, oldName, newName string) error
+	DeleteTag(ctx context.Context, name string) error
+	ListTags(ctx context.Context) ([]Tag, error)
+	TagBook(ctx context.Context, bookID, tag string) error
+	UntagBook(ctx context.Context, bookID, tag string) error
+	BookTags(ctx context.Context, bookID string) ([]string, error)
+	BooksByTag(ctx context.Context, tag string, req PageRequest) (Page, error)
+}
+
+func NormalizeTag(name string) (string, error) {
+	n := strings.ToLower(strings.TrimSpace(name))
+	if n == "" {
+		return "", fmt.Errorf("%w: empty tag", ErrInvalid)
+	}
+	if strings.ContainsAny(n, "/,") {
+		return "", fmt.Errorf("%w: tag %q contains reserved characters", ErrInvalid, name)
+	}
+	return n, nil
+}
@@ -0,0 +1,200 @@
+// Package logging собирает *slog.Logger для сервисов и примеров: текст или JSON,
+// уровни по модулям и поля запроса, которые едут в контексте.
+//
+// Модуль - атрибут "module", который добавляет Module. Уровень модуля ищется в Levels
+// от полного имени к родителю: для "library/lending" подойдёт и "library". Levels можно
+// обновить на ходу, например из перезагруженных настроек.
+//
+// Код, который принимает *log.Logger, получает мост slog.NewLogLogger(l.Handler(), level):
+// уровни модулей действуют и на него.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Форматы для Config.Format.
+const (
+	Text = "text"
+	JSON = "json"
+)
+
+// ModuleKey - атрибут, по которому выбирается уровень.
+const ModuleKey = "module"
+
+type Config struct {
+	// Format - Text (по умолчанию) или JSON.
+	Format string
+	// Output по умолчанию - os.Stderr.
+	Output io.Writer
+	// Levels - уровни по модулям; nil - info для всех.
+	Levels *Levels
+	// AddSource добавляет файл и строку вызова.
+	AddSource bool
+	// OmitTime убирает время из записей - для примеров, вывод которых читают глазами.
+	OmitTime bool
+}
+
+func New(cfg Config) (*slog.Logger, error) {
+	if cfg.Output == nil {
+		cfg.Output = os.Stderr
+	}
+	if cfg.Levels == nil {
+		cfg.Levels = &Levels{}
+	}
+	// Уровень решает handler ниже, внутренний пропускает всё.
+	opts := &slog.HandlerOptions{AddSource: cfg.AddSource, Level: slog.Level(math.MinInt)}
+	if cfg.OmitTime {
+		opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		}
+	}
+	var inner slog.Handler
+	switch cfg.Format {
+	case "", Text:
+		inner = slog.NewTextHandler(cfg.Output, opts)
+	case JSON:
+		inner = slog.NewJSONHandler(cfg.Output, opts)
+	default:
+		return nil, fmt.Errorf("logging: unknown format %q, want %s or %s", cfg.Format, Text, JSON)
+	}
+	return slog.New(&handler{inner: inner, levels: cfg.Levels}), nil
+}
+
+// Module - журнал модуля name: записи помечены им, а уровень берётся из Levels.
+func Module(l *slog.Logger, name string) *slog.Logger {
+	return l.With(ModuleKey, name)
+}
+
+// Levels - уровень по умолчанию и уровни отдельных модулей. Нулевое значение - info
+// для всех; безопасен для одновременного чтения и Update.
+type Levels struct {
+	table atomic.Pointer[levelTable]
+}
+
+type levelTable struct {
+	def     slog.Level
+	modules map[string]slog.Level
+}
+
+// ParseLevels разбирает уровень по умолчанию и уровни модулей: "debug", "info",
+// "warn", "error" или смещения вроде "info+2". Пустой def - info.
+func ParseLevels(def string, modules map[string]string) (*Levels, error) {
+	l := &Levels{}
+	if err := l.Update(def, modules); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Update заменяет все уровни разом; при ошибке разбора прежние остаются.
+func (l *Levels) Update(def string, modules map[string]string) error {
+	t := &levelTable{modules: make(map[string]slog.Level, len(modules))}
+	if def != "" {
+		if err := t.def.UnmarshalText([]byte(def)); err != nil {
+			return fmt.Errorf("logging: default level: %w", err)
+		}
+	}
+	for m, s := range modules {
+		var lv slog.Level
+		if err := lv.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("logging: module %s: %w", m, err)
+		}
+		t.modules[strings.Trim(m, "/")] = lv
+	}
+	l.table.Store(t)
+	return nil
+}
+
+// For - уровень модуля: его собственный, ближайшего родителя или по умолчанию.
+func (l *Levels) For(module string) slog.Level {
+	t := l.table.Load()
+	if t == nil {
+		return slog.LevelInfo
+	}
+	for m := module; m != ""; {
+		if lv, ok := t.modules[m]; ok {
+			return lv
+		}
+		i := strings.LastIndexByte(m, '/')
+		if i < 0 {
+			break
+		}
+		m = m[:i]
+	}
+	return t.def
+}
+
+type fieldsKey struct{}
+
+// With кладёт в контекст поля, которые допишутся ко всем записям с этим контекстом,
+// - идентификатор запроса, вызывающего и тому подобное. Аргументы - как у slog.Logger.With.
+func With(ctx context.Context, args ...any) context.Context {
+	var r slog.Record
+	r.Add(args...)
+	fields := append([]slog.Attr(nil), Fields(ctx)...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = append(fields, a)
+		return true
+	})
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// Fields - поля, которые положил в контекст With.
+func Fields(ctx context.Context) []slog.Attr {
+	fields, _ := ctx.Value(fieldsKey{}).([]slog.Attr)
+	return fields
+}
+
+// handler фильтрует записи по уровню своего модуля и дописывает поля из контекста.
+type handler struct {
+	inner  slog.Handler
+	levels *Levels
+	module string
+	// grouped - атрибуты идут в группу, и "module" в них уже не имя модуля.
+	grouped bool
+}
+
+var _ slog.Handler = (*handler)(nil)
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.For(h.module)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if fields := Fields(ctx); len(fields) > 0 {
+		r = r.Clone()
+		r.AddAttrs(fields...)
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.inner = h.inner.WithAttrs(attrs)
+	if !h.grouped {
+		for _, a := range attrs {
+			if a.Key == ModuleKey {
+				c.module = a.Value.String()
+			}
+		}
+	}
+	return &c
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.inner = h.inner.WithGroup(name)
+	c.grouped = c.grouped || name != ""
+	return &c
+}
@@ -1,7 +1,24 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"solid/data"
+	"solid/di"
+	"solid/library/events"
+	"solid/logging"
 )
 
 // Принцип S - Принцип единственной ответственности (Single Responsibility Principle)
@@ -11,8 +28,8 @@ type BookPrint struct {
 	Author string
 }
 
-func (b BookPrint) PrintDetails() {
-	fmt.Printf("Title: %s, Author: %s\n", b.Title, b.Author)
+func (b BookPrint) PrintDetails(logger *slog.Logger) {
+	logger.Info("book", "title", b.Title, "author", b.Author)
 }
 
 // Принцип О - Принцип открытости/закрытости (Open/Closed Principle)
@@ -71,16 +88,20 @@ type MultiFunctionDevice interface {
 	Scanner
 }
 
-type MyPrinter struct{}
+type MyPrinter struct {
+	Log *slog.Logger
+}
 
 func (p MyPrinter) Print() {
-	fmt.Println("Printing...")
+	p.Log.Info("printing")
 }
 
-type MyScanner struct{}
+type MyScanner struct {
+	Log *slog.Logger
+}
 
 func (s MyScanner) Scan() {
-	fmt.Println("Scanning...")
+	s.Log.Info("scanning")
 }
 
 type MyMultiFunctionDevice struct {
@@ -91,58 +112,184 @@ type MyMultiFunctionDevice struct {
 // Принцип инверсии зависимостей (Dependency Inversion Principle)
 // Суть в том, чтобы основной функционал нашего проекта был зависим от абстракции, а не от конкретной реализации чего либо.
 // Так как в будущем, реализация (к примеру - способ оплаты в приложении, используемая бд и тп.) может меняться.
-type Storage interface {
-	Save(data string)
-}
-
-type Database struct{}
-
-func (db Database) Save(data string) {
-	fmt.Println("Saving data to the database:", data)
+// Реализация вынесена в пакет data, чтобы хранилищем могли пользоваться и другие модули.
+type Note struct {
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text,omitempty"`
 }
 
-type Filesystem struct{}
-
-func (fs Filesystem) Save(data string) {
-	fmt.Println("Saving data to the filesystem:", data)
-}
-
-type DataManager struct {
-	storage Storage
+// Key позволяет сохранять заметки пакетом через SaveAll без явных ключей.
+func (n Note) Key() string {
+	if n.Title == "" {
+		return ""
+	}
+	return "notes/" + strings.ToLower(strings.ReplaceAll(n.Title, " ", "-"))
 }
 
-func NewDataManager(storage Storage) *DataManager {
-	return &DataManager{storage: storage}
+// tracerProvider по умолчанию ничего не экспортирует; SOLID_TRACE=stdout печатает спаны DataManager.
+func tracerProvider() (trace.TracerProvider, func()) {
+	if os.Getenv("SOLID_TRACE") != "stdout" {
+		return tracenoop.NewTracerProvider(), func() {}
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	if err != nil {
+		log.Fatal(err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return tp, func() { tp.Shutdown(context.Background()) }
 }
 
-func (dm *DataManager) SaveData(data string) {
-	dm.storage.Save(data)
+// newLogger - журнал примера в stdout без времени. Записи хранилищ идут на уровне debug,
+// поэтому модулю data он открыт; SOLID_LOG_FORMAT=json переключает формат.
+func newLogger() *slog.Logger {
+	levels, err := logging.ParseLevels("info", map[string]string{"data": "debug"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	logger, err := logging.New(logging.Config{Format: os.Getenv("SOLID_LOG_FORMAT"), Output: os.Stdout, Levels: levels, OmitTime: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return logger
 }
 
 func main() {
+	logger := newLogger()
 	book := BookPrint{Title: "Clean Code", Author: "Robert C. Martin"}
-	book.PrintDetails()
+	book.PrintDetails(logger)
 
 	discountPrice := 100.0
 	regularDiscount := RegularDiscount{}
-	fmt.Printf("Regular Price: $%.2f, Discounted Price: $%.2f\n", discountPrice, regularDiscount.ApplyDiscount(discountPrice))
+	logger.Info("discount", "price", discountPrice, "discounted", regularDiscount.ApplyDiscount(discountPrice))
 
 	square := Square{Width: 5}
-	fmt.Printf("Square Area: %.2f\n", square.Area())
+	logger.Info("area", "shape", "square", "area", square.Area())
 
 	circle := Circle{Radius: 3}
-	fmt.Printf("Circle Area: %.2f\n", circle.Area())
+	logger.Info("area", "shape", "circle", "area", circle.Area())
 
-	multiFunctionDevice := MyMultiFunctionDevice{}
+	multiFunctionDevice := MyMultiFunctionDevice{MyPrinter{Log: logger}, MyScanner{Log: logger}}
 	multiFunctionDevice.Print()
 	multiFunctionDevice.Scan()
 
-	db := Database{}
-	fs := Filesystem{}
-
-	dataManagerDB := NewDataManager(db)
-	dataManagerFS := NewDataManager(fs)
-
-	dataManagerDB.SaveData("Data to save with Database storage")
-	dataManagerFS.SaveData("Data to save with Filesystem storage")
+	// Сборку берёт на себя контейнер di: менеджер заметок получает data.Storage, а какая
+	// реализация за ним стоит, решает одна строка Bind - код ниже о ней не знает.
+	tp, shutdown := tracerProvider()
+	defer shutdown()
+	c := di.New()
+	storageLog := logging.Module(logger, "data")
+	database := data.NewDatabase()
+	database.Logger = storageLog
+	di.Value(c, database)
+	di.Bind[data.Storage, *data.Database](c)
+	di.Value(c, events.NewBus())
+	di.Value(c, tp)
+	// Заметки для базы сериализуются в JSON и проверяются по схеме перед сохранением.
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*data.DataManager[Note], error) {
+		return data.NewDataManager[Note](di.MustResolve[data.Storage](r),
+			data.WithPublisher(di.MustResolve[*events.Bus](r)),
+			data.WithTelemetry(di.MustResolve[trace.TracerProvider](r), nil),
+			data.WithValidators(
+				data.MaxSize(1<<10),
+				data.Schema{Required: []string{"title"}, Types: map[string]string{"title": "string"}},
+			)), nil
+	})
+	di.Provide(c, di.Singleton, func(di.Resolver) (*data.DataManager[string], error) {
+		fs := data.NewFilesystem(filepath.Join(os.TempDir(), "solid-demo"))
+		fs.Logger = storageLog
+		return data.NewDataManager[string](fs, data.WithIdempotency(data.NewMemoryKeyStore(), time.Minute)), nil
+	})
+	db := di.MustResolve[*data.Database](c)
+
+	// Подписчики узнают о каждом сохранении через шину, не вмешиваясь в сам процесс.
+	bus := di.MustResolve[*events.Bus](c)
+	events.Subscribe(bus, func(ctx context.Context, e data.DataSaved) {
+		logger.InfoContext(ctx, "event: saved", "key", e.Key, "bytes", e.Bytes)
+	})
+	events.Subscribe(bus, func(ctx context.Context, e data.DataSaveFailed) {
+		logger.WarnContext(ctx, "event: save failed", "key", e.Key)
+	})
+	dataManagerDB := di.MustResolve[*data.DataManager[Note]](c)
+	dataManagerFS := di.MustResolve[*data.DataManager[string]](c)
+
+	// Поведение вокруг сохранения добавляется цепочкой middleware, сам DataManager не меняется.
+	var counters data.Counters
+	dataManagerDB.Use(data.Metrics(&counters))
+
+	ctx := context.Background()
+	if err := dataManagerDB.SaveData(ctx, "notes/1", Note{Title: "Data to save with Database storage"}); err != nil {
+		logger.Error("save note", "err", err)
+	}
+	if err := dataManagerDB.SaveData(ctx, "notes/2", Note{Text: "Note without a title"}); err != nil {
+		var ve *data.ValidationError
+		if errors.As(err, &ve) {
+			logger.Warn("validation error", "field", ve.Fields[0].Field, "message", ve.Fields[0].Message)
+		}
+	}
+	// Повтор с тем же ключом идемпотентности не доходит до хранилища.
+	// Поля контекста попадают во все записи с ним, в том числе в журнал хранилища.
+	retryCtx := logging.With(data.WithIdempotencyKey(ctx, "greeting-request-1"), "request_id", "greeting-request-1")
+	for i := 0; i < 2; i++ {
+		if err := dataManagerFS.SaveData(retryCtx, "greeting", "Data to save with Filesystem storage"); err != nil {
+			logger.ErrorContext(retryCtx, "save greeting", "err", err)
+		}
+	}
+	logger.Info("database counters", "saves", counters.Saves.Load(), "failures", counters.Failures.Load())
+
+	// Чтение идёт через тот же DataManager: значение декодируется кодеком из конверта.
+	if note, err := dataManagerDB.LoadData(ctx, "notes/1"); err == nil {
+		logger.Info("loaded note", "title", note.Title)
+	}
+	if _, err := dataManagerDB.LoadData(ctx, "notes/2"); errors.Is(err, data.ErrNotFound) {
+		logger.Info("note was never saved", "key", "notes/2")
+	}
+	notes, err := dataManagerDB.ListData(ctx, "notes/")
+	if err != nil {
+		logger.Error("list notes", "err", err)
+	}
+	logger.Info("notes in the database", "count", len(notes))
+
+	// Пакетное сохранение: ошибка одной записи не мешает остальным.
+	batch, err := dataManagerDB.SaveAll(ctx, []Note{{Title: "First"}, {Text: "no title"}, {Title: "Second"}})
+	if err != nil {
+		logger.Error("save batch", "err", err)
+	}
+	logger.Info("batch", "saved", batch.Saved, "failed", batch.Failed)
+
+	// Пробный прогон показывает, что было бы записано, не трогая хранилище.
+	preview := data.NewDataManager[Note](db, data.WithDryRun(), data.WithLogger(slog.NewLogLogger(storageLog.Handler(), slog.LevelInfo)))
+	if err := preview.SaveData(ctx, "notes/draft", Note{Title: "Draft"}); err != nil {
+		logger.Error("preview note", "err", err)
+	}
+	if greeting, err := dataManagerFS.LoadData(ctx, "greeting"); err == nil {
+		logger.Info("loaded from filesystem", "value", greeting)
+	}
+
+	// Transactional outbox: сообщение о записи сохраняется вместе с ней и доставляется ретранслятором.
+	// Повторная доставка возможна, поэтому подписчик отсеивает дубли по EventID.
+	outboxDB := data.NewOutboxDatabase()
+	seen := data.NewDeduper()
+	events.Subscribe(bus, func(ctx context.Context, e data.DataChanged) {
+		if seen.First(e.EventID) {
+			logger.InfoContext(ctx, "outbox event", "id", e.EventID, "key", e.Key)
+		}
+	})
+	if err := data.NewDataManager[Note](outboxDB).SaveData(ctx, "notes/outbox", Note{Title: "Outbox"}); err != nil {
+		logger.Error("save outbox note", "err", err)
+	}
+	relay := data.Relay{Outbox: outboxDB, Publisher: bus}
+	if _, err := relay.Flush(ctx); err != nil {
+		logger.Error("flush outbox", "err", err)
+	}
+
+	// Миграция без простоя: пишем в оба хранилища, переносим старые записи и переключаем чтение.
+	migration := data.NewDualWrite(db, data.NewDatabase(), data.DualWriteConfig{CompareReads: true})
+	copied, err := migration.Backfill(ctx, "notes/")
+	if err != nil {
+		logger.Error("backfill", "err", err)
+	}
+	migration.SwitchOver()
+	if note, err := data.NewDataManager[Note](migration).LoadData(ctx, "notes/1"); err == nil {
+		logger.Info("migrated notes, reading from the new backend", "copied", copied, "title", note.Title)
+	}
 }
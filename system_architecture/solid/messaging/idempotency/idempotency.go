@@ -0,0 +1,127 @@
+// Package idempotency - идемпотентный потребитель. Брокеры доставляют «хотя бы один раз»,
+// поэтому обработчик может получить сообщение повторно; Guard запоминает id обработанных
+// сообщений в Store на TTL и подтверждает дубли, не вызывая обработчик второй раз.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"solid/data"
+)
+
+// ErrInProgress - сообщение с этим id прямо сейчас обрабатывает другой потребитель.
+// Его не подтверждают: если тот потребитель упадёт, сообщение должно вернуться.
+var ErrInProgress = errors.New("idempotency: message is being processed")
+
+type Status int
+
+const (
+	// New - id захвачен этим вызовом, сообщение нужно обработать.
+	New Status = iota
+	// InProgress - id захвачен другим потребителем, аренда ещё не истекла.
+	InProgress
+	// Done - сообщение уже обработано.
+	Done
+)
+
+// Store хранит состояние id сообщений. Begin должен быть атомарным: из нескольких
+// потребителей одновременно New получает только один.
+type Store interface {
+	// Begin захватывает id на время lease, если он ещё не обработан и не захвачен.
+	Begin(ctx context.Context, id string, lease time.Duration) (Status, error)
+	// Finish отмечает id обработанным на ttl.
+	Finish(ctx context.Context, id string, ttl time.Duration) error
+	// Abort снимает захват после неудачной обработки, чтобы повтор мог начать заново.
+	Abort(ctx context.Context, id string) error
+}
+
+// Stats - счётчики Guard для метрик и проверок.
+type Stats struct {
+	Processed  int64
+	Duplicates int64
+	Failed     int64
+}
+
+// Guard пропускает каждое сообщение к обработчику не больше одного раза за TTL.
+type Guard struct {
+	Store Store
+	// TTL - сколько помнить обработанный id, по умолчанию сутки. Должен быть
+	// больше окна, в котором брокер может доставить сообщение повторно.
+	TTL time.Duration
+	// Lease - на сколько захватывается id на время обработки, по умолчанию минута.
+	// Если потребитель упал, через Lease сообщение сможет обработать другой.
+	Lease  time.Duration
+	Logger data.Logger
+
+	processed, duplicates, failed atomic.Int64
+}
+
+// Do вызывает fn, если сообщение id ещё не обрабатывалось. Дубль - nil без вызова fn;
+// ошибка fn снимает захват и возвращается как есть. Сообщения без id обрабатываются
+// без проверки.
+func (g *Guard) Do(ctx context.Context, id string, fn func(ctx context.Context) error) error {
+	if id == "" {
+		g.logf("idempotency: message without id processed unguarded")
+		return fn(ctx)
+	}
+	status, err := g.Store.Begin(ctx, id, g.lease())
+	if err != nil {
+		return fmt.Errorf("idempotency: begin %s: %w", id, err)
+	}
+	switch status {
+	case Done:
+		g.duplicates.Add(1)
+		return nil
+	case InProgress:
+		return fmt.Errorf("%w: %s", ErrInProgress, id)
+	}
+	if err := fn(ctx); err != nil {
+		g.failed.Add(1)
+		// Снять захват стоит и после отмены ctx вызывающего: иначе повтор ждёт Lease.
+		if aerr := g.Store.Abort(context.WithoutCancel(ctx), id); aerr != nil {
+			g.logf("idempotency: abort %s: %v", id, aerr)
+		}
+		return err
+	}
+	g.processed.Add(1)
+	if err := g.Store.Finish(context.WithoutCancel(ctx), id, g.ttl()); err != nil {
+		// Обработка уже состоялась; без отметки дубль пройдёт не раньше, чем истечёт Lease.
+		g.logf("idempotency: finish %s: %v", id, err)
+	}
+	return nil
+}
+
+func (g *Guard) Stats() Stats {
+	return Stats{Processed: g.processed.Load(), Duplicates: g.duplicates.Load(), Failed: g.failed.Load()}
+}
+
+// Wrap оборачивает обработчик сообщений типа M; id достаёт идентификатор сообщения.
+func Wrap[M any](g *Guard, id func(M) string, h func(ctx context.Context, m M) error) func(ctx context.Context, m M) error {
+	return func(ctx context.Context, m M) error {
+		return g.Do(ctx, id(m), func(ctx context.Context) error { return h(ctx, m) })
+	}
+}
+
+func (g *Guard) ttl() time.Duration {
+	if g.TTL <= 0 {
+		return 24 * time.Hour
+	}
+	return g.TTL
+}
+
+func (g *Guard) lease() time.Duration {
+	if g.Lease <= 0 {
+		return time.Minute
+	}
+	return g.Lease
+}
+
+func (g *Guard) logf(format string, args ...any) {
+	if g.Logger != nil {
+		g.Logger.Printf(format, args...)
+	}
+}
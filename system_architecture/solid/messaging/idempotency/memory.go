@@ -0,0 +1,62 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory - Store в памяти процесса: годится для одного потребителя и проверок.
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]entry
+	now     func() time.Time
+}
+
+type entry struct {
+	done      bool
+	expiresAt time.Time
+}
+
+var _ Store = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{entries: map[string]entry{}, now: time.Now}
+}
+
+func (m *Memory) Begin(ctx context.Context, id string, lease time.Duration) (Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if e, ok := m.entries[id]; ok && now.Before(e.expiresAt) {
+		if e.done {
+			return Done, nil
+		}
+		return InProgress, nil
+	}
+	m.entries[id] = entry{expiresAt: now.Add(lease)}
+	return New, nil
+}
+
+func (m *Memory) Finish(ctx context.Context, id string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[id] = entry{done: true, expiresAt: m.now().Add(ttl)}
+	return nil
+}
+
+func (m *Memory) Abort(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[id]; ok && !e.done {
+		delete(m.entries, id)
+	}
+	return nil
+}
+
+// Len - сколько id сейчас помнит хранилище, включая истёкшие, но ещё не вытесненные.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
@@ -0,0 +1,68 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisPrefix - префикс ключей id сообщений в Redis.
+const DefaultRedisPrefix = "idempotency:"
+
+// beginScript: "done" - уже обработано; иначе SET NX захватывает id на аренду.
+// Возвращает Status.
+var beginScript = redis.NewScript(`
+local v = redis.call("GET", KEYS[1])
+if v == "done" then
+  return 2
+end
+if v then
+  return 1
+end
+redis.call("SET", KEYS[1], "processing", "PX", ARGV[1])
+return 0
+`)
+
+// abortScript снимает только захват: отметку «done» другого потребителя не трогает.
+var abortScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == "processing" then
+  return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Redis - Store, общий для всех экземпляров потребителя.
+type Redis struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ Store = (*Redis)(nil)
+
+func NewRedis(client redis.UniversalClient) *Redis {
+	return &Redis{client: client, prefix: DefaultRedisPrefix}
+}
+
+func (r *Redis) Begin(ctx context.Context, id string, lease time.Duration) (Status, error) {
+	n, err := beginScript.Run(ctx, r.client, []string{r.prefix + id}, lease.Milliseconds()).Int()
+	if err != nil {
+		return 0, fmt.Errorf("idempotency: redis begin %s: %w", id, err)
+	}
+	return Status(n), nil
+}
+
+func (r *Redis) Finish(ctx context.Context, id string, ttl time.Duration) error {
+	if err := r.client.Set(ctx, r.prefix+id, "done", ttl).Err(); err != nil {
+		return fmt.Errorf("idempotency: redis finish %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *Redis) Abort(ctx context.Context, id string) error {
+	if err := abortScript.Run(ctx, r.client, []string{r.prefix + id}).Err(); err != nil {
+		return fmt.Errorf("idempotency: redis abort %s: %w", id, err)
+	}
+	return nil
+}
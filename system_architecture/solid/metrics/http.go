@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"solid/httpmw"
+)
+
+// knownMethods - остальные методы считаются как "other", чтобы случайные строки
+// от клиентов не плодили ряды.
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// HTTP считает запросы сервера в http_server_* с метками method, route и code.
+// route - шаблон маршрута ServeMux сразу за HTTP ("unmatched", если маршрут не
+// нашёлся), поэтому в цепочке httpmw его место - последним. Запрос HTTP не копирует
+// и может стоять за telemetry.HTTP, которому шаблон нужен так же. Ошибка - ответ 5xx.
+func HTTP(r *Registry) httpmw.Middleware {
+	red := r.RED("http_server", "method", "route", "code")
+	inflight := r.Gauge("http_server", "requests_in_flight", "HTTP requests being handled.")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			inflight.WithLabelValues().Inc()
+			defer inflight.WithLabelValues().Dec()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, req)
+			method := req.Method
+			if !knownMethods[method] {
+				method = "other"
+			}
+			route := "unmatched"
+			if _, p, ok := strings.Cut(req.Pattern, " "); ok {
+				route = p
+			} else if req.Pattern != "" {
+				route = req.Pattern
+			}
+			red.Observe(start, rec.status >= 500, method, route, strconv.Itoa(rec.status))
+		})
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wrote && status >= 200 {
+		r.status, r.wrote = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wrote = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
@@ -0,0 +1,138 @@
+// Package metrics - метрики Prometheus для сервисов: реестр с эндпоинтом /metrics
+// и готовые RED-метрики (rate, errors, duration) для HTTP, gRPC, хранилищ и очередей.
+//
+// Имена держатся соглашений, по которым дашборды Grafana переносятся между сервисами
+// без правок; реестр проверяет их сам:
+//
+//	<namespace>_<subsystem>_<name>   только [a-z0-9_], без повторов namespace в имени
+//	счётчики                          оканчиваются на _total
+//	гистограммы                       в базовых единицах: _seconds или _bytes
+//	метки                             snake_case, без неограниченных значений (path, id, user...)
+//
+// Нарушение - ошибка программиста, и, как prometheus.MustRegister, методы реестра
+// паникуют: это случается при старте процесса, а не посреди работы.
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var nameRe = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// unboundedLabels - метки, значения которых растут без предела вместе с данными:
+// каждая комбинация - отдельный ряд в Prometheus. Путь заменяет шаблон маршрута.
+var unboundedLabels = []string{"path", "url", "id", "key", "user", "email", "request_id", "trace_id"}
+
+// unitSuffixes - базовые единицы гистограмм.
+var unitSuffixes = []string{"_seconds", "_bytes"}
+
+// Registry - реестр метрик одного сервиса; namespace - первое слово всех имён.
+// Метрики с одним именем и метками регистрируются один раз: повторный вызов
+// возвращает уже созданную, так что RED и HTTP можно звать из нескольких мест.
+type Registry struct {
+	reg       *prometheus.Registry
+	namespace string
+
+	mu   sync.Mutex
+	vecs map[string]registered
+}
+
+type registered struct {
+	kind   string
+	labels []string
+	vec    any
+}
+
+// New создаёт реестр с метриками рантайма Go и процесса.
+func New(namespace string) *Registry {
+	if !nameRe.MatchString(namespace) {
+		panic(fmt.Sprintf("metrics: namespace %q must be snake_case", namespace))
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return &Registry{reg: reg, namespace: namespace, vecs: map[string]registered{}}
+}
+
+// Handler отдаёт метрики в формате Prometheus; его место - GET /metrics.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.reg, promhttp.HandlerOpts{Registry: r.reg})
+}
+
+// Gatherer - для проверок и собственных экспортёров.
+func (r *Registry) Gatherer() prometheus.Gatherer { return r.reg }
+
+func (r *Registry) Counter(subsystem, name, help string, labels ...string) *prometheus.CounterVec {
+	if !strings.HasSuffix(name, "_total") {
+		panic(fmt.Sprintf("metrics: counter %s must end with _total", name))
+	}
+	return register(r, "counter", subsystem, name, help, labels, func(opts prometheus.Opts) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts(opts), labels)
+	})
+}
+
+func (r *Registry) Gauge(subsystem, name, help string, labels ...string) *prometheus.GaugeVec {
+	if strings.HasSuffix(name, "_total") {
+		panic(fmt.Sprintf("metrics: gauge %s must not end with _total, it is reserved for counters", name))
+	}
+	return register(r, "gauge", subsystem, name, help, labels, func(opts prometheus.Opts) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts(opts), labels)
+	})
+}
+
+// Histogram - распределение в базовых единицах; buckets nil - prometheus.DefBuckets,
+// рассчитанные на секунды.
+func (r *Registry) Histogram(subsystem, name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	if !slices.ContainsFunc(unitSuffixes, func(s string) bool { return strings.HasSuffix(name, s) }) {
+		panic(fmt.Sprintf("metrics: histogram %s must end with a base unit: %s", name, strings.Join(unitSuffixes, " or ")))
+	}
+	return register(r, "histogram", subsystem, name, help, labels, func(opts prometheus.Opts) *prometheus.HistogramVec {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace, Subsystem: opts.Subsystem, Name: opts.Name, Help: opts.Help, Buckets: buckets,
+		}, labels)
+	})
+}
+
+func register[V prometheus.Collector](r *Registry, kind, subsystem, name, help string, labels []string, build func(prometheus.Opts) V) V {
+	checkName(r.namespace, subsystem, name, labels)
+	full := prometheus.BuildFQName(r.namespace, subsystem, name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if prev, ok := r.vecs[full]; ok {
+		if prev.kind != kind || !slices.Equal(prev.labels, labels) {
+			panic(fmt.Sprintf("metrics: %s is already a %s with labels %v", full, prev.kind, prev.labels))
+		}
+		return prev.vec.(V)
+	}
+	vec := build(prometheus.Opts{Namespace: r.namespace, Subsystem: subsystem, Name: name, Help: help})
+	r.reg.MustRegister(vec)
+	r.vecs[full] = registered{kind: kind, labels: slices.Clone(labels), vec: vec}
+	return vec
+}
+
+func checkName(namespace, subsystem, name string, labels []string) {
+	for _, part := range []string{subsystem, name} {
+		if part != "" && !nameRe.MatchString(part) {
+			panic(fmt.Sprintf("metrics: %q must be snake_case", part))
+		}
+	}
+	if subsystem == namespace || strings.HasPrefix(subsystem, namespace+"_") || strings.HasPrefix(name, namespace+"_") {
+		panic(fmt.Sprintf("metrics: %s_%s repeats the namespace %s", subsystem, name, namespace))
+	}
+	for _, l := range labels {
+		if !nameRe.MatchString(l) {
+			panic(fmt.Sprintf("metrics: label %q must be snake_case", l))
+		}
+		if slices.Contains(unboundedLabels, l) {
+			panic(fmt.Sprintf("metrics: label %q has unbounded values", l))
+		}
+	}
+}
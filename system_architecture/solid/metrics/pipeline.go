@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"solid/concurrency/pipeline"
+)
+
+// Pipeline - наблюдатели для конвейера name: RED-метрики pipeline_stage_* по стадиям
+// и счётчик элементов, которые стадия отдала дальше. Отброшенный Skip - не ошибка.
+func (r *Registry) Pipeline(name string) pipeline.Hooks {
+	red := r.RED("pipeline_stage", "pipeline", "stage")
+	out := r.Counter("pipeline_stage", "emitted_total", "Items a stage passed downstream.", "pipeline", "stage")
+	return pipeline.Hooks{
+		Item: func(stage string, took time.Duration, err error) {
+			red.ObserveDuration(took, err != nil && !errors.Is(err, pipeline.Skip), name, stage)
+		},
+		Done: func(stage string, items int) {
+			out.WithLabelValues(name, stage).Add(float64(items))
+		},
+	}
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import "solid/concurrency/pubsub"
+
+// PubSub - наблюдатели хабов pubsub: отставание подписчика (сообщений в его буфере
+// после последней доставки), потери по политике и отключения отстающих.
+func (r *Registry) PubSub() pubsub.Hooks {
+	lag := r.Gauge("pubsub", "subscriber_lag", "Messages waiting in the subscriber buffer after the last delivery.", "hub", "subscriber")
+	dropped := r.Counter("pubsub", "dropped_total", "Messages lost by the subscriber overflow policy.", "hub", "subscriber", "policy")
+	disconnected := r.Counter("pubsub", "disconnected_total", "Slow subscribers disconnected by the hub.", "hub", "subscriber")
+	return pubsub.Hooks{
+		Lag: func(hub, sub string, n int) {
+			lag.WithLabelValues(hub, sub).Set(float64(n))
+		},
+		Dropped: func(hub, sub string, p pubsub.Policy) {
+			dropped.WithLabelValues(hub, sub, p.String()).Inc()
+		},
+		Disconnected: func(hub, sub string) {
+			disconnected.WithLabelValues(hub, sub).Inc()
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"solid/data"
+)
+
+// RED - три ряда подсистемы с общими метками: <subsystem>_requests_total,
+// <subsystem>_errors_total и <subsystem>_request_duration_seconds. Что считать
+// ошибкой, решает вызывающий: для HTTP это 5xx, для хранилища - сбой, но не
+// отсутствие ключа.
+type RED struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func (r *Registry) RED(subsystem string, labels ...string) *RED {
+	what := strings.ReplaceAll(subsystem, "_", " ")
+	return &RED{
+		requests: r.Counter(subsystem, "requests_total", "Handled "+what+" requests.", labels...),
+		errors:   r.Counter(subsystem, "errors_total", "Failed "+what+" requests.", labels...),
+		duration: r.Histogram(subsystem, "request_duration_seconds", "Duration of "+what+" requests.", nil, labels...),
+	}
+}
+
+// Observe записывает запрос, начатый в start; values - значения меток в порядке RED.
+func (m *RED) Observe(start time.Time, failed bool, values ...string) {
+	m.ObserveDuration(time.Since(start), failed, values...)
+}
+
+// ObserveDuration - то же, что Observe, когда длительность уже измерена.
+func (m *RED) ObserveDuration(took time.Duration, failed bool, values ...string) {
+	m.requests.WithLabelValues(values...).Inc()
+	if failed {
+		m.errors.WithLabelValues(values...).Inc()
+	}
+	m.duration.WithLabelValues(values...).Observe(took.Seconds())
+}
+
+// Storage оборачивает s метриками storage_* с метками storage (тип хранилища)
+// и operation (save, load, list).
+func Storage(s data.Storage, r *Registry) data.Storage {
+	return measuredStorage{Storage: s, red: r.RED("storage", "storage", "operation"), typ: strings.TrimPrefix(fmt.Sprintf("%T", s), "*")}
+}
+
+type measuredStorage struct {
+	data.Storage
+	red *RED
+	typ string
+}
+
+func (s measuredStorage) Save(ctx context.Context, key, value string) error {
+	start := time.Now()
+	err := s.Storage.Save(ctx, key, value)
+	s.red.Observe(start, err != nil, s.typ, "save")
+	return err
+}
+
+func (s measuredStorage) Load(ctx context.Context, key string) (string, error) {
+	start := time.Now()
+	value, err := s.Storage.Load(ctx, key)
+	// Отсутствие ключа - обычный ответ хранилища, а не его сбой.
+	s.red.Observe(start, err != nil && !errors.Is(err, data.ErrNotFound), s.typ, "load")
+	return value, err
+}
+
+func (s measuredStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	start := time.Now()
+	keys, err := s.Storage.List(ctx, prefix)
+	s.red.Observe(start, err != nil, s.typ, "list")
+	return keys, err
+}
+
+// Queue - метрики брокера сообщений: messaging_publish_* и messaging_process_*
+// с метками system (rabbitmq, kafka) и destination, плюс глубина очереди.
+type Queue struct {
+	publish *RED
+	process *RED
+	depth   *prometheus.GaugeVec
+}
+
+func (r *Registry) Queue() *Queue {
+	return &Queue{
+		publish: r.RED("messaging_publish", "system", "destination"),
+		process: r.RED("messaging_process", "system", "destination"),
+		depth:   r.Gauge("messaging", "queue_depth", "Messages waiting in the queue.", "system", "destination"),
+	}
+}
+
+func (q *Queue) Publish(system, destination string, start time.Time, err error) {
+	q.publish.Observe(start, err != nil, system, destination)
+}
+
+func (q *Queue) Process(system, source string, start time.Time, err error) {
+	q.process.Observe(start, err != nil, system, source)
+}
+
+func (q *Queue) SetDepth(system, queue string, n int) {
+	q.depth.WithLabelValues(system, queue).Set(float64(n))
+}
@@ -0,0 +1,107 @@
+// Package recommend строит рекомендации "читатели также брали" по совместной
+// встречаемости книг в истории выдач.
+package recommend
+
+import (
+	"context"
+	"sort"
+
+	"solid/library/lending"
+)
+
+// History - источник истории выдач; его реализует lending.Store.
+type History interface {
+	List(ctx context.Context) ([]lending.Loan, error)
+}
+
+type Suggestion struct {
+	BookID string  `json:"book_id"`
+	Score  float64 `json:"score"`
+}
+
+type Engine struct {
+	history History
+	scorer  Scorer
+}
+
+func NewEngine(history History, scorer Scorer) *Engine {
+	if scorer == nil {
+		scorer = JaccardScorer{}
+	}
+	return &Engine{history: history, scorer: scorer}
+}
+
+// matrix - читатели каждой книги и число общих читателей для каждой пары книг.
+type matrix struct {
+	readers map[string]int
+	co      map[string]map[string]int
+}
+
+func (e *Engine) build(ctx context.Context) (matrix, error) {
+	loans, err := e.history.List(ctx)
+	if err != nil {
+		return matrix{}, err
+	}
+	byBorrower := make(map[string]map[string]struct{})
+	for _, l := range loans {
+		if byBorrower[l.Borrower] == nil {
+			byBorrower[l.Borrower] = make(map[string]struct{})
+		}
+		byBorrower[l.Borrower][l.BookID] = struct{}{}
+	}
+
+	m := matrix{readers: make(map[string]int), co: make(map[string]map[string]int)}
+	for _, books := range byBorrower {
+		for a := range books {
+			m.readers[a]++
+			for b := range books {
+				if a == b {
+					continue
+				}
+				if m.co[a] == nil {
+					m.co[a] = make(map[string]int)
+				}
+				m.co[a][b]++
+			}
+		}
+	}
+	return m, nil
+}
+
+func (e *Engine) suggest(m matrix, bookID string, limit int) []Suggestion {
+	list := make([]Suggestion, 0, len(m.co[bookID]))
+	for other, co := range m.co[bookID] {
+		list = append(list, Suggestion{BookID: other, Score: e.scorer.Score(co, m.readers[bookID], m.readers[other])})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return list[i].BookID < list[j].BookID
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+func (e *Engine) Recommend(ctx context.Context, bookID string, limit int) ([]Suggestion, error) {
+	m, err := e.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return e.suggest(m, bookID, limit), nil
+}
+
+// All считает рекомендации сразу для всех книг, у которых есть история выдач.
+func (e *Engine) All(ctx context.Context, limit int) (map[string][]Suggestion, error) {
+	m, err := e.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all := make(map[string][]Suggestion, len(m.co))
+	for bookID := range m.co {
+		all[bookID] = e.suggest(m, bookID, limit)
+	}
+	return all, nil
+}
@@ -0,0 +1,43 @@
+package recommend
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"solid/data"
+)
+
+const DefaultTopN = 10
+
+// StorageKey - ключ, под которым сохраняется последний расчёт.
+const StorageKey = "recommendations/latest"
+
+// Job пересчитывает рекомендации и сохраняет их через data.Saver; по расписанию
+// его запускает scheduler.
+type Job struct {
+	Engine  *Engine
+	Storage data.Saver
+	TopN    int
+}
+
+type snapshot struct {
+	GeneratedAt     time.Time               `json:"generated_at"`
+	Recommendations map[string][]Suggestion `json:"recommendations"`
+}
+
+func (j Job) Run(ctx context.Context) error {
+	topN := j.TopN
+	if topN <= 0 {
+		topN = DefaultTopN
+	}
+	all, err := j.Engine.All(ctx, topN)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(snapshot{GeneratedAt: time.Now().UTC(), Recommendations: all})
+	if err != nil {
+		return err
+	}
+	return j.Storage.Save(ctx, StorageKey, string(raw))
+}
@@ -0,0 +1,37 @@
+package recommend
+
+import "math"
+
+// Scorer оценивает схожесть двух книг по совместным выдачам.
+// co - сколько читателей брали обе книги, a и b - сколько брали каждую.
+type Scorer interface {
+	Score(co, a, b int) float64
+}
+
+// CountScorer - просто число общих читателей.
+type CountScorer struct{}
+
+func (CountScorer) Score(co, a, b int) float64 {
+	return float64(co)
+}
+
+// JaccardScorer - доля общих читателей среди всех читателей обеих книг.
+type JaccardScorer struct{}
+
+func (JaccardScorer) Score(co, a, b int) float64 {
+	union := a + b - co
+	if union == 0 {
+		return 0
+	}
+	return float64(co) / float64(union)
+}
+
+// CosineScorer меньше штрафует популярные книги, чем учёт сырых совпадений.
+type CosineScorer struct{}
+
+func (CosineScorer) Score(co, a, b int) float64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return float64(co) / math.Sqrt(float64(a)*float64(b))
+}
@@ -0,0 +1,213 @@
+// Package breaker - автоматический выключатель: при высокой доле ошибок зависимости
+// перестаёт к ней обращаться на OpenTimeout, затем пропускает пробные запросы.
+// Им пользуются хранилища data, шлюз и HTTP-клиенты.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrOpen - вызов не выполнялся, потому что цепь разомкнута.
+var ErrOpen = errors.New("breaker: circuit open")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Config задаёт пороги срабатывания. Нулевые поля заменяются значениями по умолчанию.
+type Config struct {
+	// Window - сколько последних вызовов учитывается при расчёте доли ошибок.
+	Window int
+	// MinRequests - минимум вызовов в окне, прежде чем цепь может разомкнуться.
+	MinRequests int
+	// FailureRate - доля ошибок (0..1), при которой цепь размыкается.
+	FailureRate float64
+	// OpenTimeout - сколько цепь остаётся разомкнутой до пробных запросов.
+	OpenTimeout time.Duration
+	// HalfOpenProbes - сколько успешных пробных запросов нужно для замыкания цепи.
+	HalfOpenProbes int
+	// OnStateChange вызывается при каждом переходе, например для метрик.
+	OnStateChange func(from, to State)
+	// IsFailure решает, считать ли ошибку сбоем зависимости; по умолчанию сбой - любая
+	// ошибка, кроме отмены контекста вызывающим. Ответы по существу (404, конфликт)
+	// сюда обычно не относят.
+	IsFailure func(error) bool
+}
+
+// Stats - снимок состояния для метрик.
+type Stats struct {
+	State    State
+	Requests int
+	Failures int
+}
+
+// Breaker - автоматический выключатель. Один Breaker охраняет одну зависимость.
+type Breaker struct {
+	cfg Config
+	now func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	results  []bool
+	next     int
+	filled   int
+	failures int
+	openedAt time.Time
+	inflight int
+	probesOK int
+}
+
+func New(cfg Config) *Breaker {
+	if cfg.Window <= 0 {
+		cfg.Window = 20
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 5
+	}
+	if cfg.FailureRate <= 0 || cfg.FailureRate > 1 {
+		cfg.FailureRate = 0.5
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 5 * time.Second
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = defaultIsFailure
+	}
+	return &Breaker{cfg: cfg, now: time.Now, results: make([]bool, cfg.Window)}
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tick()
+	return b.state
+}
+
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tick()
+	return Stats{State: b.state, Requests: b.filled, Failures: b.failures}
+}
+
+// Do выполняет fn, если цепь это позволяет; иначе сразу возвращает ErrOpen.
+// nil-выключатель пропускает все вызовы.
+func (b *Breaker) Do(fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.record(!b.cfg.IsFailure(err))
+	return err
+}
+
+// Do - то же, что Breaker.Do, для функций с результатом.
+func Do[T any](b *Breaker, fn func() (T, error)) (T, error) {
+	var v T
+	err := b.Do(func() (err error) {
+		v, err = fn()
+		return err
+	})
+	return v, err
+}
+
+func defaultIsFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+// tick переводит разомкнутую цепь в полуоткрытое состояние по истечении OpenTimeout.
+func (b *Breaker) tick() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.setState(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tick()
+	switch b.state {
+	case StateOpen:
+		return ErrOpen
+	case StateHalfOpen:
+		if b.inflight >= b.cfg.HalfOpenProbes-b.probesOK {
+			return ErrOpen
+		}
+		b.inflight++
+	}
+	return nil
+}
+
+func (b *Breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateHalfOpen:
+		b.inflight--
+		if !ok {
+			b.trip()
+			return
+		}
+		if b.probesOK++; b.probesOK >= b.cfg.HalfOpenProbes {
+			b.setState(StateClosed)
+		}
+	case StateClosed:
+		if b.filled == len(b.results) {
+			if !b.results[b.next] {
+				b.failures--
+			}
+		} else {
+			b.filled++
+		}
+		b.results[b.next] = ok
+		b.next = (b.next + 1) % len(b.results)
+		if !ok {
+			b.failures++
+		}
+		if b.filled >= b.cfg.MinRequests && float64(b.failures)/float64(b.filled) >= b.cfg.FailureRate {
+			b.trip()
+		}
+	}
+}
+
+func (b *Breaker) trip() {
+	b.openedAt = b.now()
+	b.setState(StateOpen)
+}
+
+// setState сбрасывает счётчики нового состояния и сообщает о переходе.
+func (b *Breaker) setState(to State) {
+	from := b.state
+	b.state = to
+	b.next, b.filled, b.failures = 0, 0, 0
+	b.inflight, b.probesOK = 0, 0
+	if from != to && b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,126 @@
+// Package bulkhead - переборка: ограничивает число одновременных вызовов одной
+// зависимости. Лишние вызовы ждут в очереди не дольше QueueTimeout, а при полной
+// очереди отклоняются сразу, поэтому медленный бэкенд занимает не больше
+// MaxConcurrent горутин и не утягивает за собой остальные пути.
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrFull - очередь заполнена, вызов отклонён без ожидания.
+	ErrFull = errors.New("bulkhead: full")
+	// ErrTimeout - вызов простоял в очереди QueueTimeout и не дождался места.
+	ErrTimeout = errors.New("bulkhead: queue timeout")
+)
+
+// Config задаёт размеры переборки. Нулевые поля заменяются значениями по умолчанию.
+type Config struct {
+	// MaxConcurrent - сколько вызовов выполняются одновременно.
+	MaxConcurrent int
+	// MaxQueue - сколько вызовов могут ждать места; 0 - по умолчанию MaxConcurrent,
+	// отрицательное значение - без очереди.
+	MaxQueue int
+	// QueueTimeout - сколько вызов ждёт места, по умолчанию секунда.
+	QueueTimeout time.Duration
+}
+
+// Stats - снимок состояния для метрик.
+type Stats struct {
+	Active   int
+	Queued   int
+	Rejected int64
+}
+
+type Bulkhead struct {
+	cfg   Config
+	slots chan struct{}
+
+	mu       sync.Mutex
+	queued   int
+	rejected int64
+}
+
+func New(cfg Config) *Bulkhead {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 10
+	}
+	switch {
+	case cfg.MaxQueue == 0:
+		cfg.MaxQueue = cfg.MaxConcurrent
+	case cfg.MaxQueue < 0:
+		cfg.MaxQueue = 0
+	}
+	if cfg.QueueTimeout <= 0 {
+		cfg.QueueTimeout = time.Second
+	}
+	return &Bulkhead{cfg: cfg, slots: make(chan struct{}, cfg.MaxConcurrent)}
+}
+
+// Do выполняет fn, когда есть свободное место. Ожидание прерывается также отменой ctx.
+// nil-переборка пропускает все вызовы.
+func (b *Bulkhead) Do(ctx context.Context, fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+	if err := b.acquire(ctx); err != nil {
+		return err
+	}
+	defer func() { <-b.slots }()
+	return fn()
+}
+
+// Do - то же, что Bulkhead.Do, для функций с результатом.
+func Do[T any](ctx context.Context, b *Bulkhead, fn func() (T, error)) (T, error) {
+	var v T
+	err := b.Do(ctx, func() (err error) {
+		v, err = fn()
+		return err
+	})
+	return v, err
+}
+
+func (b *Bulkhead) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Stats{Active: len(b.slots), Queued: b.queued, Rejected: b.rejected}
+}
+
+func (b *Bulkhead) acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	b.mu.Lock()
+	if b.queued >= b.cfg.MaxQueue {
+		b.rejected++
+		b.mu.Unlock()
+		return ErrFull
+	}
+	b.queued++
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.queued--
+		b.mu.Unlock()
+	}()
+
+	t := time.NewTimer(b.cfg.QueueTimeout)
+	defer t.Stop()
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-t.C:
+		b.mu.Lock()
+		b.rejected++
+		b.mu.Unlock()
+		return ErrTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
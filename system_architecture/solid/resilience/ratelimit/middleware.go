@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// KeyFunc выбирает, по какому ключу считать лимит запроса.
+type KeyFunc func(r *http.Request) string
+
+// ByRemoteIP считает лимит по адресу клиента без порта.
+func ByRemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ByHeader считает лимит по заголовку, а без него - по fallback.
+func ByHeader(name string, fallback KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		if v := r.Header.Get(name); v != "" {
+			return name + ":" + v
+		}
+		return fallback(r)
+	}
+}
+
+// Middleware отвечает 429 с Retry-After, когда лимит key исчерпан. Если лимитер
+// недоступен, запрос пропускается, а ошибка уходит в onError: сервис не должен
+// падать вместе с Redis. onError может быть nil.
+func Middleware(l Limiter, key KeyFunc, onError func(r *http.Request, err error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, retry, err := l.Allow(r.Context(), key(r))
+			if err != nil {
+				if onError != nil {
+					onError(r, err)
+				}
+			} else if !ok {
+				w.Header().Set("Retry-After", retryAfter(retry))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(map[string]string{"error": "rate limit exceeded"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// retryAfter округляет ожидание вверх до целых секунд - Retry-After дробей не знает.
+func retryAfter(d time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(d.Seconds()))))
+}
@@ -0,0 +1,153 @@
+// Package ratelimit - ограничение частоты запросов по ключу (вызывающий, IP, API-ключ).
+// Token bucket допускает всплески до Burst, скользящее окно держит не больше Limit
+// запросов за любой промежуток Window. Обе схемы есть в памяти процесса и в Redis,
+// где лимит общий для всех экземпляров сервиса.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter решает, можно ли выполнить ещё один запрос от имени key. При отказе
+// возвращает время до появления следующего места.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (ok bool, retryAfter time.Duration, err error)
+}
+
+var (
+	_ Limiter = (*TokenBucket)(nil)
+	_ Limiter = (*SlidingWindow)(nil)
+	_ Limiter = (*Swap)(nil)
+)
+
+// TokenBucket - лимитер в памяти процесса: Rate токенов в секунду, не больше Burst про запас.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{rate: rate, burst: float64(burst), now: time.Now, buckets: make(map[string]*bucket)}
+}
+
+func (tb *TokenBucket) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	now := tb.now()
+	b, ok := tb.buckets[key]
+	if !ok {
+		b = &bucket{tokens: tb.burst, last: now}
+		tb.buckets[key] = b
+	}
+	b.tokens = math.Min(tb.burst, b.tokens+now.Sub(b.last).Seconds()*tb.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	if tb.rate <= 0 {
+		return false, time.Duration(math.MaxInt64), nil
+	}
+	wait := time.Duration((1 - b.tokens) / tb.rate * float64(time.Second))
+	return false, wait, nil
+}
+
+// SlidingWindow - скользящее окно в памяти процесса: не больше Limit запросов за Window.
+// Окно приближается двумя соседними интервалами: прошлый учитывается с весом той доли,
+// на которую он ещё попадает в окно. Памяти - два счётчика на ключ.
+type SlidingWindow struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+type window struct {
+	start      time.Time
+	prev, curr int
+}
+
+func NewSlidingWindow(limit int, per time.Duration) *SlidingWindow {
+	if limit < 1 {
+		limit = 1
+	}
+	if per <= 0 {
+		per = time.Second
+	}
+	return &SlidingWindow{limit: limit, window: per, now: time.Now, windows: make(map[string]*window)}
+}
+
+func (sw *SlidingWindow) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	now := sw.now()
+	start := now.Truncate(sw.window)
+	w, ok := sw.windows[key]
+	switch {
+	case !ok:
+		w = &window{start: start}
+		sw.windows[key] = w
+	case start.Sub(w.start) == sw.window:
+		w.start, w.prev, w.curr = start, w.curr, 0
+	case start.After(w.start):
+		w.start, w.prev, w.curr = start, 0, 0
+	}
+	ok, wait := slide(w.prev, w.curr, sw.limit, now.Sub(start), sw.window)
+	if ok {
+		w.curr++
+	}
+	return ok, wait, nil
+}
+
+// slide решает по счётчикам прошлого и текущего интервала, помещается ли ещё один запрос,
+// и если нет - сколько ждать. Та же формула работает в скрипте Redis.
+func slide(prev, curr, limit int, elapsed, per time.Duration) (bool, time.Duration) {
+	weight := 1 - float64(elapsed)/float64(per)
+	if float64(prev)*weight+float64(curr)+1 <= float64(limit) {
+		return true, 0
+	}
+	if curr+1 > limit || prev == 0 {
+		return false, per - elapsed
+	}
+	// Ждём, пока вес прошлого интервала упадёт настолько, что запрос поместится.
+	need := time.Duration((1 - float64(limit-1-curr)/float64(prev)) * float64(per))
+	return false, max(need-elapsed, time.Millisecond)
+}
+
+// Swap - Limiter, который можно заменить на ходу, например когда перезагрузка настроек
+// меняет лимит. Новый лимитер в памяти начинает счёт с нуля, лимитер в Redis
+// продолжает по тем же ключам.
+type Swap struct {
+	l atomic.Pointer[Limiter]
+}
+
+func NewSwap(l Limiter) *Swap {
+	s := &Swap{}
+	s.Store(l)
+	return s
+}
+
+// Store подменяет лимитер для следующих запросов.
+func (s *Swap) Store(l Limiter) { s.l.Store(&l) }
+
+func (s *Swap) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	return (*s.l.Load()).Allow(ctx, key)
+}
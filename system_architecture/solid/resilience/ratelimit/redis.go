@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisPrefix - префикс ключей лимитеров в Redis.
+const DefaultRedisPrefix = "ratelimit:"
+
+var (
+	_ Limiter = (*RedisTokenBucket)(nil)
+	_ Limiter = (*RedisSlidingWindow)(nil)
+)
+
+// tokenBucketScript атомарно пополняет и списывает токены на стороне Redis,
+// поэтому лимит общий для всех процессов. Возвращает {разрешено, ожидание в мс}.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + (now - ts) / 1000 * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call("HSET", KEYS[1], "tokens", tokens, "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, wait}
+`)
+
+// RedisTokenBucket - распределённый token bucket; взаимозаменяем с TokenBucket.
+type RedisTokenBucket struct {
+	client redis.UniversalClient
+	prefix string
+	rate   float64
+	burst  int
+}
+
+func NewRedisTokenBucket(client redis.UniversalClient, rate float64, burst int) *RedisTokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RedisTokenBucket{client: client, prefix: DefaultRedisPrefix, rate: rate, burst: burst}
+}
+
+func (l *RedisTokenBucket) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	now := time.Now().UnixMilli()
+	res, err := tokenBucketScript.Run(ctx, l.client, []string{l.prefix + key}, l.rate, l.burst, now).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("ratelimit: redis token bucket %q: %w", key, err)
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// slidingWindowScript - та же формула, что у SlidingWindow: KEYS[1] - счётчик текущего
+// интервала, KEYS[2] - прошлого. Возвращает {разрешено, ожидание в мс}.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local per = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+local curr = tonumber(redis.call("GET", KEYS[1]) or "0")
+local prev = tonumber(redis.call("GET", KEYS[2]) or "0")
+local weight = 1 - elapsed / per
+if prev * weight + curr + 1 <= limit then
+  redis.call("INCR", KEYS[1])
+  redis.call("PEXPIRE", KEYS[1], per * 2)
+  return {1, 0}
+end
+if curr + 1 > limit or prev == 0 then
+  return {0, per - elapsed}
+end
+local need = math.ceil((1 - (limit - 1 - curr) / prev) * per)
+return {0, math.max(need - elapsed, 1)}
+`)
+
+// RedisSlidingWindow - распределённое скользящее окно; взаимозаменяемо с SlidingWindow.
+type RedisSlidingWindow struct {
+	client redis.UniversalClient
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+func NewRedisSlidingWindow(client redis.UniversalClient, limit int, per time.Duration) *RedisSlidingWindow {
+	if limit < 1 {
+		limit = 1
+	}
+	if per < time.Millisecond {
+		per = time.Second
+	}
+	return &RedisSlidingWindow{client: client, prefix: DefaultRedisPrefix, limit: limit, window: per}
+}
+
+func (l *RedisSlidingWindow) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	per := l.window.Milliseconds()
+	now := time.Now().UnixMilli()
+	idx := now / per
+	// Фигурные скобки держат оба счётчика ключа в одном слоте Redis Cluster.
+	base := l.prefix + "{" + key + "}:"
+	keys := []string{base + strconv.FormatInt(idx, 10), base + strconv.FormatInt(idx-1, 10)}
+	res, err := slidingWindowScript.Run(ctx, l.client, keys, l.limit, per, now-idx*per).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("ratelimit: redis sliding window %q: %w", key, err)
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,68 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Hedge запускает fn и, если ответа нет дольше after, запускает ещё одну копию, всего
+// не больше copies. Побеждает первый успешный ответ, остальные копии отменяются через
+// ctx. Годится только для идемпотентных вызовов - чтений, GET. Отказавшую копию сразу
+// сменяет следующая; ошибка Permanent (например, 404) - окончательный ответ. Если все
+// копии завершились ошибкой, возвращается ошибка последней.
+func Hedge[T any](ctx context.Context, after time.Duration, copies int, fn func(ctx context.Context) (T, error)) (T, error) {
+	if copies < 1 {
+		copies = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		v   T
+		err error
+	}
+	results := make(chan result, copies)
+	launch := func() {
+		go func() {
+			v, err := fn(ctx)
+			results <- result{v, err}
+		}()
+	}
+	launch()
+	started, done := 1, 0
+	t := time.NewTimer(after)
+	defer t.Stop()
+	var last result
+	for {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				return r.v, nil
+			}
+			var perm permanent
+			if errors.As(r.err, &perm) {
+				return r.v, perm.err
+			}
+			last, done = r, done+1
+			if done == started {
+				if started == copies {
+					return last.v, last.err
+				}
+				// Все запущенные копии отказали - следующую запускаем сразу, не дожидаясь after.
+				launch()
+				started++
+				t.Reset(after)
+			}
+		case <-t.C:
+			if started < copies {
+				launch()
+				started++
+				t.Reset(after)
+			}
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
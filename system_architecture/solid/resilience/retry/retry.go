@@ -0,0 +1,160 @@
+// Package retry повторяет неудачные вызовы по политике: сколько попыток, какая пауза
+// между ними, с каким разбросом и в какой общий бюджет времени уложиться. Hedge
+// вместо ожидания отказа запускает запасной вызов, если первый отвечает слишком долго.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff возвращает паузу перед попыткой attempt+1 после неудачной попытки attempt (с 1).
+type Backoff func(attempt int) time.Duration
+
+// Constant - одна и та же пауза перед каждой попыткой.
+func Constant(d time.Duration) Backoff {
+	return func(int) time.Duration { return d }
+}
+
+// Exponential удваивает паузу начиная с base; limit > 0 ограничивает её сверху.
+func Exponential(base, limit time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < math.MaxInt64/2; i++ {
+			d *= 2
+		}
+		if limit > 0 {
+			return min(d, limit)
+		}
+		return d
+	}
+}
+
+// Jitter разбрасывает паузу, чтобы клиенты, упавшие одновременно, не повторяли тоже одновременно.
+type Jitter func(d time.Duration) time.Duration
+
+// NoJitter оставляет паузу как есть.
+func NoJitter(d time.Duration) time.Duration { return d }
+
+// FullJitter - случайная пауза от нуля до d.
+func FullJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d)
+}
+
+// EqualJitter - половина d плюс случайная добавка до второй половины.
+func EqualJitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d-d/2)
+}
+
+// Policy описывает повторы. Нулевые поля заменяются значениями по умолчанию.
+type Policy struct {
+	// MaxAttempts - сколько раз всего вызывается fn, по умолчанию 3.
+	MaxAttempts int
+	// Backoff по умолчанию - Exponential(100ms, 5s).
+	Backoff Backoff
+	// Jitter по умолчанию - FullJitter.
+	Jitter Jitter
+	// MaxElapsed - бюджет на все попытки вместе с паузами; 0 - без бюджета.
+	// Попытка, которая началась бы позже бюджета, не начинается.
+	MaxElapsed time.Duration
+	// Retryable решает, стоит ли повторять ошибку. По умолчанию повторяется всё,
+	// кроме Permanent и ошибок контекста.
+	Retryable func(error) bool
+	// OnRetry вызывается перед каждой паузой, например для журнала или метрик.
+	OnRetry func(attempt int, err error, delay time.Duration)
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.Backoff == nil {
+		p.Backoff = Exponential(100*time.Millisecond, 5*time.Second)
+	}
+	if p.Jitter == nil {
+		p.Jitter = FullJitter
+	}
+	if p.Retryable == nil {
+		p.Retryable = func(error) bool { return true }
+	}
+	return p
+}
+
+type permanent struct{ err error }
+
+func (p permanent) Error() string { return p.err.Error() }
+func (p permanent) Unwrap() error { return p.err }
+
+// Permanent помечает ошибку как неповторяемую; Do вернёт саму err.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanent{err}
+}
+
+// Do вызывает fn, пока она не завершится успешно, не вернёт неповторяемую ошибку
+// или не кончатся попытки и бюджет. Возвращает последнюю ошибку fn как есть.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	p = p.withDefaults()
+	start := time.Now()
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		var perm permanent
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || !p.Retryable(err) {
+			return err
+		}
+		if attempt >= p.MaxAttempts {
+			return err
+		}
+		delay := p.Jitter(p.Backoff(attempt))
+		if p.MaxElapsed > 0 && time.Since(start)+delay >= p.MaxElapsed {
+			return err
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err, delay)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// DoValue - то же, что Do, для функций с результатом.
+func DoValue[T any](ctx context.Context, p Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	var v T
+	err := Do(ctx, p, func(ctx context.Context) (err error) {
+		v, err = fn(ctx)
+		return err
+	})
+	return v, err
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,321 @@
+// Package scheduler запускает фоновые задачи по расписанию: выражению cron или
+// фиксированному интервалу, со случайным сдвигом (чтобы реплики не били в один
+// момент) и без наложения запусков одной задачи.
+//
+// Итог последнего запуска хранится в data.Storage под ключом scheduler/<задача>.
+// После перезапуска процесса расписание продолжается от него: пропущенный, пока
+// процесс не работал, запуск выполняется сразу, один раз, а сама задача получает
+// время прошлого успешного запуска и может обработать всё, что накопилось с тех пор.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"solid/data"
+)
+
+var (
+	ErrDuplicate  = errors.New("scheduler: duplicate job")
+	ErrUnknownJob = errors.New("scheduler: unknown job")
+	// ErrRunning - задача уже выполняется, второй запуск не начат.
+	ErrRunning = errors.New("scheduler: job is already running")
+)
+
+// Run - что знает запуск о себе.
+type Run struct {
+	// Scheduled - время по расписанию, без сдвига; Trigger - время вызова.
+	Scheduled time.Time
+	// Start - начало этого запуска; после успеха следующий получит его в Last, так что
+	// промежутки (Last, Start] соседних запусков не пересекаются.
+	Start time.Time
+	// Last - начало прошлого успешного запуска; нулевое, если его не было.
+	Last time.Time
+}
+
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Jitter - максимальный случайный сдвиг запуска после времени по расписанию.
+	Jitter time.Duration
+	// Timeout ограничивает запуск; 0 - без своего ограничения.
+	Timeout time.Duration
+	Run     func(ctx context.Context, run Run) error
+}
+
+// Status - состояние задачи для проверок и отладки.
+type Status struct {
+	Name    string    `json:"name"`
+	Next    time.Time `json:"next"`
+	Running bool      `json:"running"`
+	state
+}
+
+// state - то, что переживает перезапуск.
+type state struct {
+	LastRun     time.Time     `json:"last_run"`
+	LastSuccess time.Time     `json:"last_success"`
+	LastError   string        `json:"last_error,omitempty"`
+	Took        time.Duration `json:"took"`
+	Runs        int64         `json:"runs"`
+	// Skipped - времена по расписанию, пропущенные, пока шёл предыдущий запуск.
+	Skipped int64 `json:"skipped"`
+}
+
+type Config struct {
+	// Storage хранит итоги запусков; nil - только в памяти, и после перезапуска
+	// расписание начинается заново.
+	Storage data.Storage
+	// Logger получает по строке на запуск; nil - log.Default().
+	Logger *log.Logger
+	// Now - часы, по умолчанию time.Now.
+	Now func() time.Time
+}
+
+type Scheduler struct {
+	cfg Config
+
+	mu      sync.Mutex
+	jobs    []*job
+	started bool
+}
+
+type job struct {
+	Job
+	mu      sync.Mutex
+	running bool
+	next    time.Time
+	st      state
+}
+
+func New(cfg Config) *Scheduler {
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Scheduler{cfg: cfg}
+}
+
+// Add регистрирует задачу; после Run новые задачи не принимаются.
+func (s *Scheduler) Add(j Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return errors.New("scheduler: add after Run")
+	}
+	if j.Name == "" || j.Schedule == nil || j.Run == nil {
+		return fmt.Errorf("scheduler: job %q needs a name, a schedule and Run", j.Name)
+	}
+	if slices.ContainsFunc(s.jobs, func(o *job) bool { return o.Name == j.Name }) {
+		return fmt.Errorf("%w %q", ErrDuplicate, j.Name)
+	}
+	s.jobs = append(s.jobs, &job{Job: j})
+	return nil
+}
+
+// Run загружает итоги прошлых запусков и выполняет задачи по расписанию до отмены
+// ctx; идущие запуски получают отмену и дожидаются. Ошибка - только сбой чтения
+// итогов, ошибки задач пишутся в журнал и в Status.
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	s.started = true
+	jobs := s.jobs
+	s.mu.Unlock()
+	for _, j := range jobs {
+		if err := s.load(ctx, j); err != nil {
+			return err
+		}
+	}
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// Trigger запускает задачу вне расписания и ждёт её; ErrRunning, если она уже идёт.
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	j, err := s.job(name)
+	if err != nil {
+		return err
+	}
+	return s.run(ctx, j, s.cfg.Now())
+}
+
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	jobs := s.jobs
+	s.mu.Unlock()
+	list := make([]Status, len(jobs))
+	for i, j := range jobs {
+		j.mu.Lock()
+		list[i] = Status{Name: j.Name, Next: j.next, Running: j.running, state: j.st}
+		j.mu.Unlock()
+	}
+	return list
+}
+
+func (s *Scheduler) job(name string) (*job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.Name == name {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownJob, name)
+}
+
+func key(name string) string { return "scheduler/" + name }
+
+func (s *Scheduler) load(ctx context.Context, j *job) error {
+	var st state
+	if s.cfg.Storage != nil {
+		raw, err := s.cfg.Storage.Load(ctx, key(j.Name))
+		switch {
+		case errors.Is(err, data.ErrNotFound):
+		case err != nil:
+			return fmt.Errorf("scheduler: load %s: %w", j.Name, err)
+		default:
+			if err := json.Unmarshal([]byte(raw), &st); err != nil {
+				return fmt.Errorf("scheduler: decode %s: %w", j.Name, err)
+			}
+		}
+	}
+	now := s.cfg.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.st = st
+	if j.st.LastRun.IsZero() {
+		j.next = j.Schedule.Next(now)
+		return nil
+	}
+	// Пропущенный за время простоя запуск - сразу, но один, а не по числу пропусков.
+	j.next = j.Schedule.Next(j.st.LastRun)
+	if !j.next.IsZero() && j.next.Before(now) {
+		j.next = now
+	}
+	return nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	for {
+		j.mu.Lock()
+		next := j.next
+		j.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+		delay := next.Sub(s.cfg.Now())
+		if j.Jitter > 0 {
+			delay += rand.N(j.Jitter)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := s.run(ctx, j, next); errors.Is(err, ErrRunning) {
+			// Идёт запуск через Trigger: этот пропускаем.
+			s.skip(j, 1)
+		}
+		// Следующий запуск - по расписанию от текущего момента: времена, пришедшиеся
+		// на долгий запуск, пропускаются, а не выполняются подряд.
+		now := s.cfg.Now()
+		j.mu.Lock()
+		missed := int64(0)
+		t := j.Schedule.Next(next)
+		for !t.IsZero() && !t.After(now) {
+			missed++
+			t = j.Schedule.Next(t)
+		}
+		j.next = t
+		j.mu.Unlock()
+		if missed > 0 {
+			s.skip(j, missed)
+		}
+	}
+}
+
+func (s *Scheduler) skip(j *job, n int64) {
+	j.mu.Lock()
+	j.st.Skipped += n
+	j.mu.Unlock()
+	s.cfg.Logger.Printf("scheduler: %s skipped %d run(s) while the previous one was running", j.Name, n)
+}
+
+func (s *Scheduler) run(ctx context.Context, j *job, scheduled time.Time) error {
+	j.mu.Lock()
+	if j.running {
+		j.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrRunning, j.Name)
+	}
+	j.running = true
+	last := j.st.LastSuccess
+	j.mu.Unlock()
+
+	start := s.cfg.Now()
+	runCtx := ctx
+	if j.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, j.Timeout)
+		defer cancel()
+	}
+	err := j.call(runCtx, Run{Scheduled: scheduled, Start: start, Last: last})
+	took := s.cfg.Now().Sub(start)
+
+	j.mu.Lock()
+	j.running = false
+	j.st.LastRun, j.st.Took = start, took
+	j.st.Runs++
+	if err == nil {
+		j.st.LastSuccess, j.st.LastError = start, ""
+	} else {
+		j.st.LastError = err.Error()
+	}
+	st := j.st
+	j.mu.Unlock()
+
+	if err != nil {
+		s.cfg.Logger.Printf("scheduler: %s failed after %s: %v", j.Name, took.Round(time.Millisecond), err)
+	} else {
+		s.cfg.Logger.Printf("scheduler: %s done in %s", j.Name, took.Round(time.Millisecond))
+	}
+	if s.cfg.Storage != nil {
+		raw, _ := json.Marshal(st)
+		// Итог сохраняется и после отмены ctx, иначе остановка процесса теряла бы его.
+		if serr := s.cfg.Storage.Save(context.WithoutCancel(ctx), key(j.Name), string(raw)); serr != nil {
+			s.cfg.Logger.Printf("scheduler: save %s: %v", j.Name, serr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("scheduler: %s: %w", j.Name, err)
+	}
+	return nil
+}
+
+// call изолирует панику задачи от планировщика.
+func (j *job) call(ctx context.Context, run Run) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	return j.Run(ctx, run)
+}
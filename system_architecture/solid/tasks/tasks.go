@@ -0,0 +1,207 @@
+// Package tasks запускает именованные задачи поверх errgroup: с зависимостями
+// («B после A»), своим таймаутом у каждой и итогом по каждой.
+//
+// Задачи, которые не ждут друг друга, идут параллельно. Первая ошибка отменяет
+// контекст остальных, а задачи, чьи зависимости не завершились успешно, не
+// запускаются вовсе и получают ErrSkipped. Так собирается запуск процесса:
+// миграции, затем начальные данные, затем сервер.
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrDuplicate = errors.New("tasks: duplicate task")
+	// ErrUnknownDependency - After называет задачу, которой нет в группе.
+	ErrUnknownDependency = errors.New("tasks: unknown dependency")
+	ErrCycle             = errors.New("tasks: dependency cycle")
+	// ErrSkipped - задача не запускалась: зависимость не удалась или группу отменили.
+	ErrSkipped = errors.New("tasks: skipped")
+)
+
+type Task struct {
+	Name string
+	// After - задачи, которые должны успешно завершиться до запуска этой.
+	After []string
+	// Timeout ограничивает Run; 0 - без своего ограничения.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Result - итог задачи. У пропущенной Start нулевой, а Err оборачивает ErrSkipped.
+type Result struct {
+	Name  string
+	Start time.Time
+	Took  time.Duration
+	Err   error
+}
+
+func (r Result) Skipped() bool { return errors.Is(r.Err, ErrSkipped) }
+
+// Report - итоги в порядке добавления задач.
+type Report []Result
+
+// Get - итог задачи name.
+func (r Report) Get(name string) (Result, bool) {
+	for _, res := range r {
+		if res.Name == name {
+			return res, true
+		}
+	}
+	return Result{}, false
+}
+
+type Config struct {
+	// Limit - сколько задач выполняются одновременно; 0 - без ограничения. Задача,
+	// ждущая зависимостей, места не занимает.
+	Limit int
+	// Logger получает по строке на завершённую задачу; ошибки возвращает Run.
+	Logger *log.Logger
+}
+
+type Group struct {
+	cfg   Config
+	tasks []Task
+}
+
+func New(cfg Config) *Group {
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	return &Group{cfg: cfg}
+}
+
+// Add добавляет задачу; зависимости можно называть до того, как они добавлены.
+func (g *Group) Add(t Task) {
+	g.tasks = append(g.tasks, t)
+}
+
+// Run проверяет граф зависимостей и выполняет задачи. Ошибка - первая ошибка задачи
+// (с её именем) или ошибка графа, при которой ничего не запускалось; итоги всех
+// задач - в Report.
+func (g *Group) Run(ctx context.Context) (Report, error) {
+	index, err := g.validate()
+	if err != nil {
+		return nil, err
+	}
+	report := make(Report, len(g.tasks))
+	done := make([]chan struct{}, len(g.tasks))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	var slots chan struct{}
+	if g.cfg.Limit > 0 {
+		slots = make(chan struct{}, g.cfg.Limit)
+	}
+	eg, ctx := errgroup.WithContext(ctx)
+	for i, t := range g.tasks {
+		eg.Go(func() error {
+			defer close(done[i])
+			report[i] = Result{Name: t.Name}
+			// Итог зависимости читается после закрытия её done, когда он уже записан.
+			for _, dep := range t.After {
+				j := index[dep]
+				select {
+				case <-done[j]:
+				case <-ctx.Done():
+					report[i].Err = fmt.Errorf("%w: %v", ErrSkipped, ctx.Err())
+					return nil
+				}
+				if report[j].Err != nil {
+					report[i].Err = fmt.Errorf("%w: %s did not succeed", ErrSkipped, dep)
+					return nil
+				}
+			}
+			if slots != nil {
+				select {
+				case slots <- struct{}{}:
+					defer func() { <-slots }()
+				case <-ctx.Done():
+					report[i].Err = fmt.Errorf("%w: %v", ErrSkipped, ctx.Err())
+					return nil
+				}
+			}
+			report[i] = g.run(ctx, t)
+			return report[i].Err
+		})
+	}
+	return report, eg.Wait()
+}
+
+func (g *Group) run(ctx context.Context, t Task) Result {
+	res := Result{Name: t.Name, Start: time.Now()}
+	runCtx := ctx
+	if t.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, t.Timeout)
+		defer cancel()
+	}
+	err := t.Run(runCtx)
+	res.Took = time.Since(res.Start)
+	switch {
+	case err == nil:
+		g.cfg.Logger.Printf("tasks: %s done in %s", t.Name, res.Took.Round(time.Millisecond))
+	case errors.Is(err, context.DeadlineExceeded) && runCtx.Err() != nil && ctx.Err() == nil:
+		res.Err = fmt.Errorf("tasks: %s timed out after %s: %w", t.Name, t.Timeout, err)
+	default:
+		res.Err = fmt.Errorf("tasks: %s: %w", t.Name, err)
+	}
+	return res
+}
+
+// validate возвращает номера задач по именам, если у графа нет повторов, ссылок
+// на неизвестные задачи и циклов.
+func (g *Group) validate() (map[string]int, error) {
+	index := make(map[string]int, len(g.tasks))
+	for i, t := range g.tasks {
+		if _, ok := index[t.Name]; ok {
+			return nil, fmt.Errorf("%w %q", ErrDuplicate, t.Name)
+		}
+		index[t.Name] = i
+	}
+	for _, t := range g.tasks {
+		for _, dep := range t.After {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("%w: %s after %q", ErrUnknownDependency, t.Name, dep)
+			}
+		}
+	}
+	// Обход в глубину: задача «в пути» встретилась снова - цикл.
+	const (
+		unseen = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(g.tasks))
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		path = append(path, g.tasks[i].Name)
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("%w: %v", ErrCycle, path)
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		for _, dep := range g.tasks[i].After {
+			if err := visit(index[dep], path); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		return nil
+	}
+	for i := range g.tasks {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
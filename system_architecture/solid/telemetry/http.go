@@ -0,0 +1,98 @@
+package telemetry
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"solid/httpmw"
+)
+
+// HTTP начинает серверный спан на каждый запрос продолжением трассы из traceparent.
+// Спан называется по шаблону маршрута, если его нашёл ServeMux сразу за HTTP, иначе по
+// методу, поэтому в цепочке httpmw его место - последним; ответ 5xx - ошибка спана.
+func HTTP(tp trace.TracerProvider) httpmw.Middleware {
+	tracer := Tracer(tp)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(attribute.String("http.request.method", r.Method), attribute.String("url.path", r.URL.Path)))
+			defer span.End()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			r = r.WithContext(ctx)
+			next.ServeHTTP(rec, r)
+			if _, route, ok := strings.Cut(r.Pattern, " "); ok && route != "/" {
+				span.SetName(r.Method + " " + route)
+				span.SetAttributes(attribute.String("http.route", route))
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", rec.status))
+			if id := r.Header.Get(httpmw.RequestIDHeader); id != "" {
+				span.SetAttributes(attribute.String("http.request.id", id))
+			}
+			if rec.status >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(rec.status))
+			}
+		})
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wrote && status >= 200 {
+		r.status, r.wrote = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wrote = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+// Transport - клиентский RoundTripper: спан на каждый исходящий запрос и traceparent
+// в его заголовках. base nil - http.DefaultTransport.
+func Transport(tp trace.TracerProvider, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{tracer: Tracer(tp), base: base}
+}
+
+type transport struct {
+	tracer trace.Tracer
+	base   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(r.Context(), r.Method, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("http.request.method", r.Method), attribute.String("server.address", r.URL.Host),
+			attribute.String("url.full", r.URL.String())))
+	// RoundTripper не должен менять чужой запрос - заголовки пишутся в копию.
+	r = r.Clone(ctx)
+	Inject(ctx, propagation.HeaderCarrier(r.Header))
+	// Спан заканчивается с заголовками ответа: тело читает уже вызывающий.
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		End(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.SetStatus(codes.Error, fmt.Sprintf("status %d", resp.StatusCode))
+	}
+	span.End()
+	return resp, nil
+}
@@ -0,0 +1,125 @@
+// Package telemetry настраивает трассировку OpenTelemetry и переносит её контекст через
+// границы процессов: заголовки HTTP (traceparent), метаданные gRPC и заголовки
+// сообщений брокера. Так запрос, прошедший шлюз, сервис и очередь, складывается в одну
+// распределённую трассу.
+//
+// Как и в solid/data, провайдер передаётся явно и глобальное состояние otel не
+// трогается; формат переноса - W3C Trace Context и Baggage.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+const instrumentationName = "solid/telemetry"
+
+// Экспортёры для Config.Exporter.
+const (
+	None   = "none"
+	Stdout = "stdout"
+)
+
+type Config struct {
+	// Service - имя сервиса в трассах (service.name).
+	Service string
+	// Exporter - куда отправлять спаны: None (по умолчанию) или Stdout - по строке JSON
+	// на спан в Output.
+	Exporter string
+	// Output для Stdout; по умолчанию os.Stdout.
+	Output io.Writer
+	// Ratio - доля трасс, которые начинаются здесь и записываются; 0 - все. Трасса,
+	// пришедшая с решением вызывающего, следует ему.
+	Ratio float64
+}
+
+// Setup создаёт провайдер по cfg. shutdown дописывает накопленные спаны и останавливает
+// экспорт - его стоит вызвать при остановке процесса, последним.
+func Setup(cfg Config) (tp trace.TracerProvider, shutdown func(context.Context) error, err error) {
+	switch cfg.Exporter {
+	case "", None:
+		return tracenoop.NewTracerProvider(), func(context.Context) error { return nil }, nil
+	case Stdout:
+	default:
+		return nil, nil, fmt.Errorf("telemetry: unknown exporter %q, want %s or %s", cfg.Exporter, None, Stdout)
+	}
+	if cfg.Output == nil {
+		cfg.Output = os.Stdout
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(cfg.Output))
+	if err != nil {
+		return nil, nil, fmt.Errorf("telemetry: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.Service)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("telemetry: %w", err)
+	}
+	sampler := sdktrace.AlwaysSample()
+	if cfg.Ratio > 0 && cfg.Ratio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.Ratio)
+	}
+	p := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+	)
+	return p, p.Shutdown, nil
+}
+
+// Propagator переносит контекст трассы и baggage.
+var Propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Inject записывает контекст трассы из ctx в исходящие заголовки.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	Propagator.Inject(ctx, carrier)
+}
+
+// Extract достаёт контекст трассы вызывающего из входящих заголовков.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return Propagator.Extract(ctx, carrier)
+}
+
+// Tracer - трассировщик пакетов-адаптеров; nil tp - no-op.
+func Tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	return tp.Tracer(instrumentationName)
+}
+
+// StartSend начинает спан отправки сообщения в destination и кладёт контекст трассы
+// в его заголовки.
+func StartSend(ctx context.Context, tp trace.TracerProvider, system, destination string, carrier propagation.TextMapCarrier) (context.Context, trace.Span) {
+	ctx, span := Tracer(tp).Start(ctx, "publish "+destination, trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attribute.String("messaging.system", system), attribute.String("messaging.destination.name", destination)))
+	Inject(ctx, carrier)
+	return ctx, span
+}
+
+// StartReceive начинает спан обработки сообщения из source продолжением трассы
+// отправителя, если её контекст пришёл в заголовках.
+func StartReceive(ctx context.Context, tp trace.TracerProvider, system, source string, carrier propagation.TextMapCarrier) (context.Context, trace.Span) {
+	return Tracer(tp).Start(Extract(ctx, carrier), "process "+source, trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.String("messaging.system", system), attribute.String("messaging.destination.name", source)))
+}
+
+// End закрывает спан, отмечая err как ошибку.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,143 @@
+// Package uow - единица работы (Unit of Work) поверх database/sql: операции нескольких
+// репозиториев внутри Do выполняются в одной транзакции и фиксируются или откатываются
+// вместе. Репозитории не держат *sql.Tx сами, а берут соединение через From(ctx, db),
+// поэтому один и тот же репозиторий работает и внутри единицы работы, и без неё.
+//
+// Взаимная блокировка или сбой сериализации откатывают транзакцию, и Do повторяет fn
+// целиком в новой транзакции. Поэтому fn должна загружать всё, что меняет, сама
+// и не оставлять побочных эффектов вне базы - их место в AfterCommit.
+package uow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"solid/resilience/retry"
+)
+
+// Querier - общее у *sql.DB и *sql.Tx; его возвращает From.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+var (
+	_ Querier = (*sql.DB)(nil)
+	_ Querier = (*sql.Tx)(nil)
+)
+
+type Config struct {
+	// TxOptions - уровень изоляции и режим транзакций; nil - как принято у драйвера.
+	TxOptions *sql.TxOptions
+	// Retry - повтор единицы работы после конфликта транзакций: по умолчанию
+	// 3 попытки с паузой Exponential(20ms, 500ms). Retry.Retryable по умолчанию - IsConflict.
+	Retry retry.Policy
+}
+
+type UnitOfWork struct {
+	db  *sql.DB
+	cfg Config
+}
+
+func New(db *sql.DB, cfg Config) *UnitOfWork {
+	if cfg.Retry.Backoff == nil {
+		cfg.Retry.Backoff = retry.Exponential(20*time.Millisecond, 500*time.Millisecond)
+	}
+	if cfg.Retry.Retryable == nil {
+		cfg.Retry.Retryable = IsConflict
+	}
+	return &UnitOfWork{db: db, cfg: cfg}
+}
+
+type unitKey struct{}
+
+// unit - открытая транзакция и то, что выполнится после её фиксации.
+type unit struct {
+	db    *sql.DB
+	tx    *sql.Tx
+	after []func(ctx context.Context)
+}
+
+// Do выполняет fn в транзакции: nil фиксирует её, ошибка или паника - откатывают.
+// Если ctx уже внутри единицы работы над той же базой, fn присоединяется к ней,
+// а фиксирует внешний Do.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if cur, ok := ctx.Value(unitKey{}).(*unit); ok && cur.db == u.db {
+		return fn(ctx)
+	}
+	var after []func(ctx context.Context)
+	err := retry.Do(ctx, u.cfg.Retry, func(ctx context.Context) error {
+		var err error
+		after, err = u.once(ctx, fn)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range after {
+		f(ctx)
+	}
+	return nil
+}
+
+func (u *UnitOfWork) once(ctx context.Context, fn func(ctx context.Context) error) (after []func(ctx context.Context), err error) {
+	tx, err := u.db.BeginTx(ctx, u.cfg.TxOptions)
+	if err != nil {
+		return nil, fmt.Errorf("uow: begin: %w", err)
+	}
+	un := &unit{db: u.db, tx: tx}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+	if err := fn(context.WithValue(ctx, unitKey{}, un)); err != nil {
+		if rerr := tx.Rollback(); rerr != nil && !errors.Is(rerr, sql.ErrTxDone) {
+			err = errors.Join(err, fmt.Errorf("uow: rollback: %w", rerr))
+		}
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("uow: commit: %w", err)
+	}
+	return un.after, nil
+}
+
+// From возвращает транзакцию текущей единицы работы над db, а вне её - саму db.
+func From(ctx context.Context, db *sql.DB) Querier {
+	if cur, ok := ctx.Value(unitKey{}).(*unit); ok && cur.db == db {
+		return cur.tx
+	}
+	return db
+}
+
+// AfterCommit откладывает fn до фиксации текущей единицы работы - например,
+// публикацию событий. После отката и перед повтором отложенное отбрасывается;
+// вне единицы работы fn выполняется сразу.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if cur, ok := ctx.Value(unitKey{}).(*unit); ok {
+		cur.after = append(cur.after, fn)
+		return
+	}
+	fn(ctx)
+}
+
+// IsConflict - ошибка PostgreSQL, после которой транзакцию стоит повторить целиком:
+// deadlock_detected (40P01) или serialization_failure (40001). Код берётся у ошибки
+// драйвера с методом SQLState, как у pgconn.PgError.
+func IsConflict(err error) bool {
+	var coded interface{ SQLState() string }
+	if !errors.As(err, &coded) {
+		return false
+	}
+	switch coded.SQLState() {
+	case "40P01", "40001":
+		return true
+	}
+	return false
+}
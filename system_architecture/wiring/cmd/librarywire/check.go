@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+
+	"solid/di"
+
+	"wiring/internal/app"
+	"wiring/internal/wiregen"
+)
+
+// check собирает сервис обоими способами и сравнивает ответы, затем проверяет,
+// что wire_gen.go совпадает с генерацией и что ошибки графа видны до компиляции.
+func check(w io.Writer, dir string) error {
+	step := func(name string, err error) error {
+		if err != nil {
+			return fmt.Errorf("librarywire: %s: %w", name, err)
+		}
+		fmt.Fprintf(w, "ok   %s\n", name)
+		return nil
+	}
+	expect := func(name string, err, want error) error {
+		if !errors.Is(err, want) {
+			return fmt.Errorf("librarywire: %s: got %v, want %v", name, err, want)
+		}
+		fmt.Fprintf(w, "ok   %s (%v)\n", name, err)
+		return nil
+	}
+	logger := log.New(io.Discard, "", 0)
+	cfg := app.Config{Seed: true}
+
+	generated, cleanup, err := app.InitializeApp(cfg, logger)
+	if err := step("generated injector builds the graph", err); err != nil {
+		return err
+	}
+	defer cleanup()
+	c := app.NewContainer(cfg, logger)
+	defer c.Close()
+	runtime, err := di.Resolve[*app.App](c)
+	if err := step("runtime container builds the graph", err); err != nil {
+		return err
+	}
+	for _, target := range []string{"/books?limit=20", "/books?q=clean", "/stats"} {
+		a, b := get(generated.Handler, target), get(runtime.Handler, target)
+		if err := step("same response for GET "+target, equal(a, b)); err != nil {
+			return err
+		}
+	}
+	_, _, err = app.InitializeApp(app.Config{Search: "bogus"}, logger)
+	if err := step("configuration errors surface from the injector", want(err != nil, "no error for an unknown search backend")); err != nil {
+		return err
+	}
+
+	// Генерация по текущим исходникам должна совпасть с wire_gen.go в репозитории.
+	files, err := wiregen.ReadPackage(dir)
+	if err != nil {
+		return err
+	}
+	out, err := wiregen.Generate(files)
+	if err := step("wire_gen.go is up to date", errors.Join(err, want(bytes.Equal(out, files[wiregen.OutputFile]), "run go generate ./internal/app"))); err != nil {
+		return err
+	}
+	broken := copyFiles(files)
+	broken["wire.go"] = []byte(strings.Replace(string(files["wire.go"]), "\tNewStats,\n", "", 1))
+	_, err = wiregen.Generate(broken)
+	if err := expect("set without NewStats is rejected before compiling", err, wiregen.ErrNoProvider); err != nil {
+		return err
+	}
+	broken = copyFiles(files)
+	broken["extra.go"] = []byte("package app\n\nfunc NewLoansFromEngine() {}\n")
+	broken["wire.go"] = []byte(strings.Replace(string(files["wire.go"]), "\tNewLoans,\n", "\tNewLoansFromEngine,\n", 1))
+	_, err = wiregen.Generate(broken)
+	if err := expect("provider without a result is rejected", err, wiregen.ErrSignature); err != nil {
+		return err
+	}
+	return nil
+}
+
+// authorID случаен в каждой сборке, поэтому при сравнении ответов он скрывается.
+var authorID = regexp.MustCompile(`"author_id":"[0-9a-f]+"`)
+
+func get(h http.Handler, target string) string {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return fmt.Sprintf("%d %s", rec.Code, authorID.ReplaceAllString(rec.Body.String(), `"author_id":"-"`))
+}
+
+func equal(a, b string) error {
+	if a != b {
+		return fmt.Errorf("generated %q, runtime %q", a, b)
+	}
+	if !strings.HasPrefix(a, "200 ") {
+		return fmt.Errorf("unexpected response %q", a)
+	}
+	return nil
+}
+
+func want(ok bool, msg string) error {
+	if !ok {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func copyFiles(files map[string][]byte) map[string][]byte {
+	out := make(map[string][]byte, len(files))
+	for k, v := range files {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+// Команда librarywire поднимает тот же REST и GraphQL API каталога, что и solid/cmd/libraryd,
+// но граф объектов собирает сгенерированный инжектор app.InitializeApp; с -runtime - контейнер
+// solid/di из тех же провайдеров. check сравнивает оба способа и проверяет wire_gen.go.
+//
+//	librarywire serve [-addr :8083] [-seed] [-runtime] [-data file] [-copies file] [-search index|scan] [-events-dir dir]
+//	librarywire check [-dir internal/app]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	lifecycle "solid/app"
+	"solid/di"
+	"solid/health"
+
+	"wiring/internal/app"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "serve":
+		serve(os.Args[2:])
+	case "check":
+		fs := flag.NewFlagSet("check", flag.ExitOnError)
+		dir := fs.String("dir", "internal/app", "package with the injector, for comparing wire_gen.go")
+		fs.Parse(os.Args[2:])
+		if err := check(os.Stdout, *dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+}
+
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8083", "HTTP listen address")
+	runtime := fs.Bool("runtime", false, "assemble through the solid/di container instead of the generated injector")
+	var cfg app.Config
+	fs.StringVar(&cfg.DataFile, "data", "", "JSON file for the catalog (in-memory if empty)")
+	fs.StringVar(&cfg.CopiesFile, "copies", "", "JSON file for book copies (in-memory if empty)")
+	fs.BoolVar(&cfg.Seed, "seed", false, "load the embedded starter catalog on startup")
+	fs.StringVar(&cfg.Search, "search", "index", "search backend: index or scan")
+	fs.StringVar(&cfg.EventsDir, "events-dir", "", "directory for the event journal (events are not kept if empty)")
+	fs.Parse(args)
+	logger := log.Default()
+
+	var (
+		svc     *app.App
+		cleanup func()
+	)
+	if *runtime {
+		c := app.NewContainer(cfg, logger)
+		a, err := di.Resolve[*app.App](c)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		svc, cleanup = a, func() { c.Close() }
+	} else {
+		a, clean, err := app.InitializeApp(cfg, logger)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		svc, cleanup = a, clean
+	}
+	lc := lifecycle.New(lifecycle.Config{Logger: logger})
+	lc.Add("app", lifecycle.Closer(func() error { cleanup(); return nil }))
+	mux := http.NewServeMux()
+	mux.Handle("/", svc.Handler)
+	health.New(health.Config{}).Register(mux)
+	lc.HTTP("http", &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second})
+	logger.Printf("librarywire listening on %s", *addr)
+	if err := lc.Run(context.Background()); err != nil {
+		logger.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: librarywire serve [-addr addr] [-seed] [-runtime] [...] | check [-dir dir]")
+	os.Exit(2)
+}
@@ -0,0 +1,42 @@
+// Команда wiregen пишет wire_gen.go для пакета с инжекторами inject.Build. Обычно её
+// запускает go generate из самого пакета; -check только сверяет файл с генерацией.
+//
+//	wiregen [-check] [dir]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"wiring/internal/wiregen"
+)
+
+func main() {
+	check := flag.Bool("check", false, "fail if wire_gen.go is out of date instead of writing it")
+	flag.Parse()
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	out, err := wiregen.GenerateDir(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	path := filepath.Join(dir, wiregen.OutputFile)
+	if *check {
+		cur, err := os.ReadFile(path)
+		if err != nil || !bytes.Equal(cur, out) {
+			fmt.Fprintf(os.Stderr, "wiregen: %s is out of date, run go generate\n", path)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,17 @@
+// Package inject - разметка для генератора wiregen. Инжектор объявляется в файле с тегом
+// wireinject и состоит из одного вызова Build; wiregen заменяет его на wire_gen.go с
+// настоящими вызовами провайдеров. В обычной сборке эти функции не выполняются.
+package inject
+
+// Set - именованный набор провайдеров, который можно передать в Build или в другой NewSet.
+type Set struct{}
+
+func NewSet(providers ...any) Set {
+	return Set{}
+}
+
+// Build перечисляет провайдеры инжектора. Тело инжектора - panic(inject.Build(...)),
+// чтобы оно компилировалось без возвращаемых значений.
+func Build(providers ...any) string {
+	panic("inject: Build is only a marker for wiregen, run go generate")
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"log"
+
+	"github.com/graphql-go/graphql"
+
+	"solid/di"
+	"solid/eventbus"
+	"solid/library"
+	"solid/library/facade"
+	"solid/library/httpapi"
+	"solid/library/inventory"
+	"solid/library/lending"
+	"solid/library/reviews"
+	"solid/library/stats"
+	"solid/recommend"
+)
+
+// NewContainer регистрирует те же провайдеры в solid/di. Каждую зависимость приходится
+// достать вручную, а ошибка вроде забытой регистрации всплывает только в Resolve[*App] -
+// у InitializeApp её находит уже wiregen.
+func NewContainer(cfg Config, logger *log.Logger) *di.Container {
+	c := di.New()
+	di.Value(c, cfg)
+	di.Value(c, logger)
+	di.Provide(c, di.Singleton, func(r di.Resolver) (Catalog, error) {
+		return NewCatalog(di.MustResolve[Config](r), di.MustResolve[*log.Logger](r))
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*eventbus.Bus, error) {
+		// Освобождение берёт на себя Close контейнера: Bus сам умеет Close.
+		bus, _ := NewBus(di.MustResolve[Config](r), di.MustResolve[*log.Logger](r))
+		return bus, nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (library.Repository, error) {
+		return NewBooks(di.MustResolve[Catalog](r), di.MustResolve[*eventbus.Bus](r)), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (library.Searcher, error) {
+		return NewSearcher(di.MustResolve[Config](r), di.MustResolve[Catalog](r), di.MustResolve[*eventbus.Bus](r))
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*reviews.Service, error) {
+		return NewReviews(di.MustResolve[Catalog](r)), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (inventory.Store, error) {
+		return NewCopies(di.MustResolve[Config](r))
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*inventory.Service, error) {
+		return NewInventory(di.MustResolve[inventory.Store](r), di.MustResolve[Catalog](r)), nil
+	})
+	di.Provide(c, di.Singleton, func(di.Resolver) (*lending.MemoryStore, error) {
+		return NewLoans(), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*lending.Service, error) {
+		return NewLending(di.MustResolve[*lending.MemoryStore](r), di.MustResolve[inventory.Store](r), di.MustResolve[*eventbus.Bus](r)), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*stats.Projection, error) {
+		return NewStats(di.MustResolve[*eventbus.Bus](r))
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*recommend.Engine, error) {
+		return NewRecommender(di.MustResolve[*lending.MemoryStore](r)), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*facade.LibraryFacade, error) {
+		return NewFacade(di.MustResolve[library.Repository](r), di.MustResolve[library.Searcher](r),
+			di.MustResolve[*inventory.Service](r), di.MustResolve[*lending.Service](r), di.MustResolve[*log.Logger](r)), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*httpapi.Server, error) {
+		return NewServer(di.MustResolve[Catalog](r), di.MustResolve[library.Repository](r), di.MustResolve[library.Searcher](r),
+			di.MustResolve[*reviews.Service](r), di.MustResolve[*inventory.Service](r), di.MustResolve[*lending.Service](r),
+			di.MustResolve[*stats.Projection](r), di.MustResolve[*recommend.Engine](r), di.MustResolve[*facade.LibraryFacade](r),
+			di.MustResolve[*log.Logger](r)), nil
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (graphql.Schema, error) {
+		return NewSchema(di.MustResolve[Catalog](r), di.MustResolve[library.Repository](r), di.MustResolve[library.Searcher](r),
+			di.MustResolve[*reviews.Service](r), di.MustResolve[*lending.Service](r))
+	})
+	di.Provide(c, di.Singleton, func(r di.Resolver) (*App, error) {
+		return NewApp(di.MustResolve[*httpapi.Server](r), di.MustResolve[graphql.Schema](r), di.MustResolve[Catalog](r), di.MustResolve[*log.Logger](r)), nil
+	})
+	return c
+}
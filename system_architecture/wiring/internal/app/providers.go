@@ -0,0 +1,213 @@
+// Package app собирает сервис каталога книг из solid/library двумя способами. Провайдеры
+// ниже - обычные конструкторы, зависимости которых видны в параметрах. InitializeApp
+// (wire_gen.go) вызывает их в порядке, который wiregen вычислил при генерации, а
+// NewContainer регистрирует те же провайдеры в контейнере solid/di, и порядок
+// определяется уже во время работы.
+package app
+
+//go:generate go run wiring/cmd/wiregen
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+
+	"solid/data"
+	"solid/eventbus"
+	"solid/httpmw"
+	"solid/library"
+	"solid/library/dedup"
+	"solid/library/facade"
+	"solid/library/graphqlapi"
+	"solid/library/httpapi"
+	"solid/library/importer"
+	"solid/library/inventory"
+	"solid/library/lending"
+	"solid/library/reviews"
+	"solid/library/search"
+	"solid/library/seed"
+	"solid/library/stats"
+	"solid/recommend"
+)
+
+type Config struct {
+	// DataFile и CopiesFile - JSON-файлы каталога и экземпляров; пустые - в памяти.
+	DataFile   string
+	CopiesFile string
+	// Seed загружает стартовый каталог при сборке.
+	Seed bool
+	// Search - "index" (по умолчанию) или "scan".
+	Search string
+	// EventsDir - журнал событий; без него события не сохраняются.
+	EventsDir string
+}
+
+// Catalog - хранилище каталога со всеми возможностями, которые нужны HTTP и GraphQL.
+type Catalog interface {
+	library.Repository
+	library.Searcher
+	library.TagRepository
+	library.AuthorRepository
+}
+
+// App - собранный сервис.
+type App struct {
+	Handler http.Handler
+	Catalog Catalog
+}
+
+func NewCatalog(cfg Config, logger *log.Logger) (Catalog, error) {
+	var catalog Catalog = library.NewMemoryRepository()
+	if cfg.DataFile != "" {
+		repo, err := library.OpenFileRepository(cfg.DataFile)
+		if err != nil {
+			return nil, fmt.Errorf("open catalog: %w", err)
+		}
+		catalog = repo
+	}
+	if cfg.Seed {
+		added, err := seed.Seed(context.Background(), catalog)
+		if err != nil {
+			return nil, fmt.Errorf("seed catalog: %w", err)
+		}
+		logger.Printf("seeded %d books", added)
+	}
+	return catalog, nil
+}
+
+// NewBus возвращает и Close: провайдер с ресурсом отдаёт функцию его освобождения.
+func NewBus(cfg Config, logger *log.Logger) (*eventbus.Bus, func()) {
+	var journal data.Storage
+	if cfg.EventsDir != "" {
+		journal = data.NewFilesystem(cfg.EventsDir)
+	}
+	bus := eventbus.New(eventbus.Config{Journal: journal, OnError: func(f eventbus.Failure) {
+		logger.Print(f)
+	}})
+	return bus, bus.Close
+}
+
+// NewBooks - каталог, публикующий события об изменениях.
+func NewBooks(catalog Catalog, bus *eventbus.Bus) library.Repository {
+	return library.WithEvents(catalog, bus)
+}
+
+func NewSearcher(cfg Config, catalog Catalog, bus *eventbus.Bus) (library.Searcher, error) {
+	switch cfg.Search {
+	case "", "index":
+		index := search.NewIndex()
+		if err := index.Rebuild(context.Background(), catalog); err != nil {
+			return nil, fmt.Errorf("build search index: %w", err)
+		}
+		if err := index.Subscribe(context.Background(), bus); err != nil {
+			return nil, fmt.Errorf("subscribe search index: %w", err)
+		}
+		return index, nil
+	case "scan":
+		return catalog, nil
+	}
+	return nil, fmt.Errorf("unknown search backend %q", cfg.Search)
+}
+
+func NewReviews(catalog Catalog) *reviews.Service {
+	return reviews.NewService(reviews.NewMemoryRepository(), catalog)
+}
+
+func NewCopies(cfg Config) (inventory.Store, error) {
+	if cfg.CopiesFile == "" {
+		return inventory.NewMemoryStore(), nil
+	}
+	copies, err := inventory.OpenFileStore(cfg.CopiesFile)
+	if err != nil {
+		return nil, fmt.Errorf("open copies: %w", err)
+	}
+	return copies, nil
+}
+
+func NewInventory(copies inventory.Store, catalog Catalog) *inventory.Service {
+	return inventory.NewService(copies, catalog)
+}
+
+func NewLoans() *lending.MemoryStore {
+	return lending.NewMemoryStore()
+}
+
+func NewLending(loans *lending.MemoryStore, copies inventory.Store, bus *eventbus.Bus) *lending.Service {
+	return lending.NewService(loans, copies, bus)
+}
+
+func NewStats(bus *eventbus.Bus) (*stats.Projection, error) {
+	p, err := stats.Register(context.Background(), bus)
+	if err != nil {
+		return nil, fmt.Errorf("replay stats: %w", err)
+	}
+	return p, nil
+}
+
+func NewRecommender(loans *lending.MemoryStore) *recommend.Engine {
+	return recommend.NewEngine(loans, recommend.JaccardScorer{})
+}
+
+func NewFacade(books library.Repository, searcher library.Searcher, inv *inventory.Service, lend *lending.Service, logger *log.Logger) *facade.LibraryFacade {
+	return facade.New(facade.Config{
+		Books:     books,
+		Search:    searcher,
+		Inventory: inv,
+		Lending:   lend,
+		Notifier:  facade.LogNotifier{Logger: logger},
+		Logger:    logger,
+	})
+}
+
+func NewServer(
+	catalog Catalog,
+	books library.Repository,
+	searcher library.Searcher,
+	rev *reviews.Service,
+	inv *inventory.Service,
+	lend *lending.Service,
+	st *stats.Projection,
+	rec *recommend.Engine,
+	fac *facade.LibraryFacade,
+	logger *log.Logger,
+) *httpapi.Server {
+	return httpapi.NewServer(httpapi.Deps{
+		Books:     books,
+		Search:    searcher,
+		Tags:      catalog,
+		Authors:   catalog,
+		Reviews:   rev,
+		Inventory: inv,
+		Lending:   lend,
+		Stats:     st,
+		Dedup:     dedup.NewService(books, lend, rev, inv, dedup.TagMover{Tags: catalog}),
+		Recommend: rec,
+		Importer:  importer.New(books),
+		Facade:    fac,
+		Logger:    logger,
+	})
+}
+
+func NewSchema(catalog Catalog, books library.Repository, searcher library.Searcher, rev *reviews.Service, lend *lending.Service) (graphql.Schema, error) {
+	schema, err := graphqlapi.NewSchema(graphqlapi.Deps{
+		Books:   books,
+		Authors: catalog,
+		Search:  searcher,
+		Reviews: rev,
+		Lending: lend,
+	})
+	if err != nil {
+		return graphql.Schema{}, fmt.Errorf("build GraphQL schema: %w", err)
+	}
+	return schema, nil
+}
+
+func NewApp(srv *httpapi.Server, schema graphql.Schema, catalog Catalog, logger *log.Logger) *App {
+	mux := http.NewServeMux()
+	mux.Handle("/", srv.Handler())
+	mux.Handle("POST /graphql", httpmw.Chain(graphqlapi.Handler(schema), httpmw.RequestID, httpmw.Logging(logger), httpmw.Recover(logger), httpmw.Gzip))
+	return &App{Handler: mux, Catalog: catalog}
+}
@@ -0,0 +1,33 @@
+//go:build wireinject
+
+package app
+
+import (
+	"log"
+
+	"wiring/inject"
+)
+
+// Providers - все провайдеры сервиса; порядок не важен, его вычисляет wiregen.
+var Providers = inject.NewSet(
+	NewCatalog,
+	NewBus,
+	NewBooks,
+	NewSearcher,
+	NewReviews,
+	NewCopies,
+	NewInventory,
+	NewLoans,
+	NewLending,
+	NewStats,
+	NewRecommender,
+	NewFacade,
+	NewServer,
+	NewSchema,
+	NewApp,
+)
+
+// InitializeApp собирает сервис; вызовите cleanup после остановки.
+func InitializeApp(cfg Config, logger *log.Logger) (*App, func(), error) {
+	panic(inject.Build(Providers))
+}
@@ -0,0 +1,50 @@
+// Code generated by wiregen. DO NOT EDIT.
+
+//go:build !wireinject
+
+package app
+
+import (
+	"log"
+)
+
+// InitializeApp собирает сервис; вызовите cleanup после остановки.
+func InitializeApp(cfg Config, logger *log.Logger) (*App, func(), error) {
+	catalog, err := NewCatalog(cfg, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	bus, cleanup := NewBus(cfg, logger)
+	repository := NewBooks(catalog, bus)
+	searcher, err := NewSearcher(cfg, catalog, bus)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	service := NewReviews(catalog)
+	store, err := NewCopies(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	inventoryService := NewInventory(store, catalog)
+	memoryStore := NewLoans()
+	lendingService := NewLending(memoryStore, store, bus)
+	projection, err := NewStats(bus)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	engine := NewRecommender(memoryStore)
+	libraryFacade := NewFacade(repository, searcher, inventoryService, lendingService, logger)
+	server := NewServer(catalog, repository, searcher, service, inventoryService, lendingService, projection, engine, libraryFacade, logger)
+	schema, err := NewSchema(catalog, repository, searcher, service, lendingService)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	app := NewApp(server, schema, catalog, logger)
+	return app, func() {
+		cleanup()
+	}, nil
+}
@@ -0,0 +1,601 @@
+// Package wiregen - генератор инжекторов в духе google/wire. Провайдер - обычная функция
+// пакета: параметры - её зависимости, первый результат - то, что она даёт, за ним могут
+// идти func() для освобождения ресурса и error. Инжектор в файле с тегом wireinject
+// перечисляет провайдеры через inject.Build, а wiregen ещё до компиляции строит граф
+// и пишет wire_gen.go с вызовами по порядку - без reflect и без контейнера во время работы.
+//
+// Типы сравниваются по записи в исходнике (путь импорта + имя), без проверки типов,
+// поэтому провайдеры должны лежать в одном пакете с инжектором и возвращать ровно тот
+// тип, который ждут потребители: привязки интерфейса к реализации здесь нет.
+package wiregen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// InjectPath - пакет разметки, вызовы которого ищет генератор.
+	InjectPath = "wiring/inject"
+	OutputFile = "wire_gen.go"
+)
+
+var (
+	ErrNoProvider = errors.New("wiregen: no provider")
+	ErrDuplicate  = errors.New("wiregen: multiple providers")
+	ErrCycle      = errors.New("wiregen: dependency cycle")
+	ErrSignature  = errors.New("wiregen: unsupported signature")
+)
+
+// GenerateDir читает пакет в dir и возвращает содержимое wire_gen.go.
+func GenerateDir(dir string) ([]byte, error) {
+	files, err := ReadPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+	return Generate(files)
+}
+
+// ReadPackage читает .go-файлы dir для Generate.
+func ReadPackage(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files[e.Name()] = src
+	}
+	return files, nil
+}
+
+// Generate - то же по исходникам пакета в памяти: имя файла -> содержимое.
+// Прежний wire_gen.go и тесты пропускаются.
+func Generate(files map[string][]byte) ([]byte, error) {
+	p := &pkg{fset: token.NewFileSet(), funcs: map[string]*decl{}, sets: map[string]*set{}}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		if name != OutputFile && !strings.HasSuffix(name, "_test.go") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	var injectors []*decl
+	for _, name := range names {
+		f, err := parser.ParseFile(p.fset, name, files[name], parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if p.name == "" {
+			p.name = f.Name.Name
+		} else if p.name != f.Name.Name {
+			return nil, fmt.Errorf("wiregen: %s: package %s, want %s", name, f.Name.Name, p.name)
+		}
+		injectors = append(injectors, p.collect(f)...)
+	}
+	if len(injectors) == 0 {
+		return nil, fmt.Errorf("wiregen: no injectors: no function calls inject.Build")
+	}
+
+	g := &gen{pkg: p, imports: map[string]string{}}
+	for _, inj := range injectors {
+		if err := g.injector(inj); err != nil {
+			return nil, err
+		}
+	}
+	return g.file()
+}
+
+// decl - функция пакета вместе с файлом, по импортам которого читаются её типы.
+type decl struct {
+	fn   *ast.FuncDecl
+	file *ast.File
+}
+
+type set struct {
+	items []ast.Expr
+}
+
+type pkg struct {
+	fset  *token.FileSet
+	name  string
+	funcs map[string]*decl
+	sets  map[string]*set
+}
+
+// collect запоминает функции и наборы файла и возвращает его инжекторы.
+func (p *pkg) collect(f *ast.File) []*decl {
+	var injectors []*decl
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil {
+				continue
+			}
+			fd := &decl{fn: d, file: f}
+			if buildArgs(f, d.Body) != nil {
+				injectors = append(injectors, fd)
+				continue
+			}
+			p.funcs[d.Name.Name] = fd
+		case *ast.GenDecl:
+			if d.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range d.Specs {
+				vs := spec.(*ast.ValueSpec)
+				if len(vs.Names) != 1 || len(vs.Values) != 1 {
+					continue
+				}
+				if call, ok := vs.Values[0].(*ast.CallExpr); ok && isInject(f, call.Fun, "NewSet") {
+					p.sets[vs.Names[0].Name] = &set{items: call.Args}
+				}
+			}
+		}
+	}
+	return injectors
+}
+
+// buildArgs - аргументы вызова inject.Build в теле, nil если его нет.
+func buildArgs(f *ast.File, body *ast.BlockStmt) []ast.Expr {
+	if body == nil {
+		return nil
+	}
+	var args []ast.Expr
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && args == nil && isInject(f, call.Fun, "Build") {
+			args = append([]ast.Expr{}, call.Args...)
+			return false
+		}
+		return true
+	})
+	return args
+}
+
+func isInject(f *ast.File, fun ast.Expr, name string) bool {
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && importsOf(f)[x.Name] == InjectPath
+}
+
+// importsOf - имя пакета в файле -> путь импорта. Без явного имени берётся последний
+// элемент пути, а суффикс версии вроде /v9 пропускается.
+func importsOf(f *ast.File) map[string]string {
+	out := map[string]string{}
+	for _, spec := range f.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			out[spec.Name.Name] = p
+			continue
+		}
+		out[defaultName(p)] = p
+	}
+	return out
+}
+
+func defaultName(importPath string) string {
+	base := path.Base(importPath)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		base = path.Base(path.Dir(importPath))
+	}
+	return strings.TrimPrefix(base, "go-")
+}
+
+// typeKey - запись типа, не зависящая от имён импортов в конкретном файле.
+func (p *pkg) typeKey(f *ast.File, e ast.Expr) (string, error) {
+	switch t := e.(type) {
+	case *ast.Ident:
+		return t.Name, nil
+	case *ast.StarExpr:
+		k, err := p.typeKey(f, t.X)
+		return "*" + k, err
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		importPath, ok := importsOf(f)[x.Name]
+		if !ok {
+			return "", fmt.Errorf("wiregen: %s: unknown package %s", p.fset.Position(t.Pos()), x.Name)
+		}
+		return importPath + "." + t.Sel.Name, nil
+	case *ast.IndexExpr:
+		return p.typeKeyList(f, t.X, []ast.Expr{t.Index})
+	case *ast.IndexListExpr:
+		return p.typeKeyList(f, t.X, t.Indices)
+	}
+	return p.source(e), nil
+}
+
+func (p *pkg) typeKeyList(f *ast.File, base ast.Expr, args []ast.Expr) (string, error) {
+	k, err := p.typeKey(f, base)
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, len(args))
+	for i, a := range args {
+		if parts[i], err = p.typeKey(f, a); err != nil {
+			return "", err
+		}
+	}
+	return k + "[" + strings.Join(parts, ", ") + "]", nil
+}
+
+func (p *pkg) source(n any) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, p.fset, n)
+	return buf.String()
+}
+
+// provider - разобранная сигнатура провайдера.
+type provider struct {
+	name    string
+	params  []string
+	out     string
+	cleanup bool
+	err     bool
+}
+
+func (p *pkg) provider(d *decl) (*provider, error) {
+	ft := d.fn.Type
+	pr := &provider{name: d.fn.Name.Name}
+	if ft.TypeParams != nil {
+		return nil, fmt.Errorf("%w: %s is generic", ErrSignature, pr.name)
+	}
+	for _, field := range ft.Params.List {
+		k, err := p.typeKey(d.file, field.Type)
+		if err != nil {
+			return nil, err
+		}
+		for range max(1, len(field.Names)) {
+			pr.params = append(pr.params, k)
+		}
+	}
+	results := p.results(d)
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%w: %s returns nothing", ErrSignature, pr.name)
+	}
+	var err error
+	if pr.out, err = p.typeKey(d.file, results[0]); err != nil {
+		return nil, err
+	}
+	rest := results[1:]
+	if len(rest) > 0 && p.source(rest[0]) == "func()" {
+		pr.cleanup, rest = true, rest[1:]
+	}
+	if len(rest) > 0 && p.source(rest[0]) == "error" {
+		pr.err, rest = true, rest[1:]
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%w: %s must return (T), (T, error), (T, func()) or (T, func(), error)", ErrSignature, pr.name)
+	}
+	return pr, nil
+}
+
+func (p *pkg) results(d *decl) []ast.Expr {
+	var out []ast.Expr
+	if d.fn.Type.Results == nil {
+		return nil
+	}
+	for _, field := range d.fn.Type.Results.List {
+		for range max(1, len(field.Names)) {
+			out = append(out, field.Type)
+		}
+	}
+	return out
+}
+
+// expand раскрывает аргументы Build: имена провайдеров и наборов, в том числе вложенных.
+func (p *pkg) expand(args []ast.Expr, seen map[string]bool, out *[]*decl) error {
+	for _, a := range args {
+		id, ok := a.(*ast.Ident)
+		if !ok {
+			return fmt.Errorf("wiregen: %s: %s: only providers and sets of this package are supported", p.fset.Position(a.Pos()), p.source(a))
+		}
+		if seen[id.Name] {
+			continue
+		}
+		seen[id.Name] = true
+		if s, ok := p.sets[id.Name]; ok {
+			if err := p.expand(s.items, seen, out); err != nil {
+				return err
+			}
+			continue
+		}
+		d, ok := p.funcs[id.Name]
+		if !ok {
+			return fmt.Errorf("wiregen: %s: %s is neither a provider nor a set", p.fset.Position(id.Pos()), id.Name)
+		}
+		*out = append(*out, d)
+	}
+	return nil
+}
+
+type gen struct {
+	pkg     *pkg
+	imports map[string]string // имя -> путь, для сигнатур инжекторов
+	funcs   []string
+}
+
+// injector выводит тело одного инжектора.
+func (g *gen) injector(inj *decl) error {
+	p := g.pkg
+	var decls []*decl
+	if err := p.expand(buildArgs(inj.file, inj.fn.Body), map[string]bool{}, &decls); err != nil {
+		return err
+	}
+	providers := map[string]*provider{}
+	for _, d := range decls {
+		pr, err := p.provider(d)
+		if err != nil {
+			return err
+		}
+		if other, ok := providers[pr.out]; ok {
+			return fmt.Errorf("%w for %s: %s and %s", ErrDuplicate, pr.out, other.name, pr.name)
+		}
+		providers[pr.out] = pr
+	}
+
+	name := inj.fn.Name.Name
+	sig, err := p.provider(&decl{fn: &ast.FuncDecl{Name: inj.fn.Name, Type: inj.fn.Type}, file: inj.file})
+	if err != nil {
+		return fmt.Errorf("injector %s: %w", name, err)
+	}
+	w := &body{g: g, sig: sig, providers: providers, values: map[string]string{}, taken: map[string]bool{}}
+	for n := range importsOf(inj.file) {
+		w.taken[n] = true
+	}
+	for n := range p.funcs {
+		w.taken[n] = true
+	}
+	for _, field := range inj.fn.Type.Params.List {
+		if len(field.Names) == 0 {
+			return fmt.Errorf("%w: injector %s: parameters must be named", ErrSignature, name)
+		}
+		k, _ := p.typeKey(inj.file, field.Type)
+		for _, n := range field.Names {
+			if _, dup := w.values[k]; dup {
+				return fmt.Errorf("%w for %s: two parameters of injector %s", ErrDuplicate, k, name)
+			}
+			w.values[k] = n.Name
+			w.taken[n.Name] = true
+		}
+	}
+	result := p.results(inj)[0]
+	w.zero = "*new(" + p.source(result) + ")"
+	switch result.(type) {
+	case *ast.StarExpr, *ast.InterfaceType, *ast.MapType, *ast.FuncType, *ast.ChanType:
+		w.zero = "nil"
+	case *ast.ArrayType:
+		if result.(*ast.ArrayType).Len == nil {
+			w.zero = "nil"
+		}
+	}
+	out, err := w.resolve(sig.out, []string{name})
+	if err != nil {
+		return err
+	}
+	w.ret(out)
+
+	g.useImports(inj)
+	var fn bytes.Buffer
+	if inj.fn.Doc != nil {
+		for _, c := range inj.fn.Doc.List {
+			fn.WriteString(c.Text + "\n")
+		}
+	}
+	fn.WriteString(p.source(&ast.FuncDecl{Name: inj.fn.Name, Type: inj.fn.Type}))
+	fn.WriteString(" {\n")
+	fn.Write(w.buf.Bytes())
+	fn.WriteString("}\n")
+	g.funcs = append(g.funcs, fn.String())
+	return nil
+}
+
+// useImports добавляет импорты, которые встречаются в сигнатуре инжектора.
+func (g *gen) useImports(inj *decl) {
+	imports := importsOf(inj.file)
+	ast.Inspect(inj.fn.Type, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				if importPath, ok := imports[x.Name]; ok {
+					g.imports[x.Name] = importPath
+				}
+			}
+		}
+		return true
+	})
+}
+
+func (g *gen) file() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by wiregen. DO NOT EDIT.\n\n")
+	buf.WriteString("//go:build !wireinject\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg.name)
+	if len(g.imports) > 0 {
+		names := make([]string, 0, len(g.imports))
+		for n := range g.imports {
+			names = append(names, n)
+		}
+		slices.SortFunc(names, func(a, b string) int { return strings.Compare(g.imports[a], g.imports[b]) })
+		buf.WriteString("import (\n")
+		for _, n := range names {
+			if defaultName(g.imports[n]) == n {
+				fmt.Fprintf(&buf, "\t%q\n", g.imports[n])
+			} else {
+				fmt.Fprintf(&buf, "\t%s %q\n", n, g.imports[n])
+			}
+		}
+		buf.WriteString(")\n\n")
+	}
+	buf.WriteString(strings.Join(g.funcs, "\n"))
+	return format.Source(buf.Bytes())
+}
+
+// body - тело инжектора: вызовы провайдеров в порядке зависимостей.
+type body struct {
+	g         *gen
+	sig       *provider
+	providers map[string]*provider
+	values    map[string]string // тип -> переменная
+	taken     map[string]bool
+	cleanups  []string
+	zero      string
+	buf       bytes.Buffer
+}
+
+func (w *body) resolve(key string, path []string) (string, error) {
+	if v, ok := w.values[key]; ok {
+		return v, nil
+	}
+	pr, ok := w.providers[key]
+	if !ok {
+		return "", fmt.Errorf("%w for %s (needed by %s)", ErrNoProvider, key, strings.Join(path, " -> "))
+	}
+	if slices.Contains(path, pr.name) {
+		return "", fmt.Errorf("%w: %s -> %s", ErrCycle, strings.Join(path[slices.Index(path, pr.name):], " -> "), pr.name)
+	}
+	var args []string
+	for _, param := range pr.params {
+		v, err := w.resolve(param, append(path[:len(path):len(path)], pr.name))
+		if err != nil {
+			return "", err
+		}
+		args = append(args, v)
+	}
+	if pr.err && !w.sig.err {
+		return "", fmt.Errorf("%w: %s can fail, but injector %s returns no error", ErrSignature, pr.name, path[0])
+	}
+	if pr.cleanup && !w.sig.cleanup {
+		return "", fmt.Errorf("%w: %s needs cleanup, but injector %s returns no func()", ErrSignature, pr.name, path[0])
+	}
+
+	v := w.name(key)
+	lhs := []string{v}
+	var cleanup string
+	if pr.cleanup {
+		cleanup = w.fresh("cleanup")
+		lhs = append(lhs, cleanup)
+	}
+	if pr.err {
+		lhs = append(lhs, "err")
+	}
+	fmt.Fprintf(&w.buf, "\t%s := %s(%s)\n", strings.Join(lhs, ", "), pr.name, strings.Join(args, ", "))
+	if pr.err {
+		w.buf.WriteString("\tif err != nil {\n")
+		w.runCleanups("\t\t")
+		w.buf.WriteString("\t\treturn " + w.failure() + "\n\t}\n")
+	}
+	// Своё освобождение провайдер отдаёт только при успехе, поэтому оно
+	// попадает в список отката после проверки err.
+	if cleanup != "" {
+		w.cleanups = append(w.cleanups, cleanup)
+	}
+	w.values[key] = v
+	return v, nil
+}
+
+func (w *body) runCleanups(indent string) {
+	for i := len(w.cleanups) - 1; i >= 0; i-- {
+		w.buf.WriteString(indent + w.cleanups[i] + "()\n")
+	}
+}
+
+func (w *body) failure() string {
+	out := []string{w.zero}
+	if w.sig.cleanup {
+		out = append(out, "nil")
+	}
+	return strings.Join(append(out, "err"), ", ")
+}
+
+func (w *body) ret(v string) {
+	out := []string{v}
+	if w.sig.cleanup {
+		if len(w.cleanups) == 0 {
+			out = append(out, "func() {}")
+		} else {
+			var c bytes.Buffer
+			c.WriteString("func() {\n")
+			for i := len(w.cleanups) - 1; i >= 0; i-- {
+				c.WriteString("\t\t" + w.cleanups[i] + "()\n")
+			}
+			c.WriteString("\t}")
+			out = append(out, c.String())
+		}
+	}
+	if w.sig.err {
+		out = append(out, "nil")
+	}
+	w.buf.WriteString("\treturn " + strings.Join(out, ", ") + "\n")
+}
+
+// name - имя переменной по типу: *inventory.Service -> service, а при совпадении
+// inventoryService.
+func (w *body) name(key string) string {
+	key = strings.TrimLeft(key, "*")
+	if i := strings.Index(key, "["); i >= 0 {
+		key = key[:i]
+	}
+	pkgPath, typ := "", key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		pkgPath, typ = key[:i], key[i+1:]
+	}
+	v := lowerFirst(typ)
+	if !w.taken[v] && !token.IsKeyword(v) {
+		w.taken[v] = true
+		return v
+	}
+	if pkgPath != "" {
+		v = lowerFirst(defaultName(pkgPath)) + typ
+	}
+	return w.fresh(v)
+}
+
+func (w *body) fresh(base string) string {
+	v := base
+	for i := 2; w.taken[v] || token.IsKeyword(v); i++ {
+		v = base + strconv.Itoa(i)
+	}
+	w.taken[v] = true
+	return v
+}
+
+// lowerFirst опускает начальные заглавные: Bus -> bus, HTTPServer -> httpServer.
+func lowerFirst(s string) string {
+	r := []rune(s)
+	n := 0
+	for n < len(r) && unicode.IsUpper(r[n]) {
+		n++
+	}
+	if n > 1 && n < len(r) {
+		n--
+	}
+	for i := range n {
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}